@@ -53,6 +53,7 @@ func ConfigureYorcClientCommand(c *cobra.Command, v *viper.Viper, cfgFile *strin
 	v.BindEnv("key_file")
 	v.BindEnv("cert_file")
 	v.BindEnv("skip_tls_verify")
+	v.BindEnv("api_token")
 	v.SetDefault("yorc_api", "localhost:8800")
 	v.SetDefault("ssl_enabled", false)
 	v.SetDefault("skip_tls_verify", false)
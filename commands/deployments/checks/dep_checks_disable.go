@@ -0,0 +1,98 @@
+// Copyright 2021 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package checks
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/ystia/yorc/v4/commands/deployments"
+	"github.com/ystia/yorc/v4/commands/httputil"
+	"github.com/ystia/yorc/v4/rest"
+)
+
+func init() {
+	var until string
+	var checksDisableCmd = &cobra.Command{
+		Use:   "disable <DeploymentId> <NodeName> <InstanceId>",
+		Short: "Disable a monitoring check",
+		Long: `Temporarily disable the monitoring check of a given node instance.
+The check is disabled until it is enabled again or, if the --until flag is provided, until the given date.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 3 {
+				return errors.Errorf("Expecting a deployment id, a node name and an instance id (got %d parameters)", len(args))
+			}
+			disabledUntil, err := parseUntil(until, time.Now())
+			if err != nil {
+				return err
+			}
+			client, err := httputil.GetClient(deployments.ClientConfig)
+			if err != nil {
+				return err
+			}
+			return updateCheck(client, args[0], args[1], args[2], rest.CheckRequest{Enabled: new(bool), DisabledUntil: disabledUntil})
+		},
+	}
+	checksDisableCmd.Flags().StringVarP(&until, "until", "u", "", "Date (RFC3339) or duration (e.g. 2h30m) after which the check is automatically enabled again")
+	checksCmd.AddCommand(checksDisableCmd)
+}
+
+// parseUntil parses either an RFC3339 date or a duration relative to now
+func parseUntil(until string, now time.Time) (*time.Time, error) {
+	if until == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, until); err == nil {
+		return &t, nil
+	}
+	d, err := time.ParseDuration(until)
+	if err != nil {
+		return nil, errors.Errorf("invalid --until value %q, expecting a RFC3339 date or a duration", until)
+	}
+	t := now.Add(d)
+	return &t, nil
+}
+
+func updateCheck(client httputil.HTTPClient, deploymentID, nodeName, instance string, req rest.CheckRequest) error {
+	b, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	request, err := client.NewRequest("PATCH", path.Join("/deployments", deploymentID, "checks", nodeName, instance), bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	request.Header.Add("Content-Type", "application/json")
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	ids := deploymentID + "/" + nodeName + "/" + instance
+	httputil.HandleHTTPStatusCode(response, ids, "monitoring check", http.StatusOK)
+	state := "enabled"
+	if req.Enabled != nil && !*req.Enabled {
+		state = "disabled"
+	}
+	fmt.Printf("Monitoring check of %s instance %s %s\n", nodeName, instance, state)
+	return nil
+}
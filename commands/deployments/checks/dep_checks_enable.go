@@ -0,0 +1,44 @@
+// Copyright 2021 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package checks
+
+import (
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/ystia/yorc/v4/commands/deployments"
+	"github.com/ystia/yorc/v4/commands/httputil"
+	"github.com/ystia/yorc/v4/rest"
+)
+
+func init() {
+	var checksEnableCmd = &cobra.Command{
+		Use:   "enable <DeploymentId> <NodeName> <InstanceId>",
+		Short: "Enable a monitoring check",
+		Long:  `Enable again a previously disabled monitoring check of a given node instance.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 3 {
+				return errors.Errorf("Expecting a deployment id, a node name and an instance id (got %d parameters)", len(args))
+			}
+			client, err := httputil.GetClient(deployments.ClientConfig)
+			if err != nil {
+				return err
+			}
+			enabled := true
+			return updateCheck(client, args[0], args[1], args[2], rest.CheckRequest{Enabled: &enabled})
+		},
+	}
+	checksCmd.AddCommand(checksEnableCmd)
+}
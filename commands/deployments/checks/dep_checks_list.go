@@ -0,0 +1,118 @@
+// Copyright 2021 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package checks
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/ystia/yorc/v4/commands/deployments"
+	"github.com/ystia/yorc/v4/commands/httputil"
+	"github.com/ystia/yorc/v4/helper/tabutil"
+	"github.com/ystia/yorc/v4/rest"
+)
+
+func init() {
+	var nodeName, instance, status string
+	var checksListCmd = &cobra.Command{
+		Use:     "list [DeploymentId]",
+		Short:   "List monitoring checks",
+		Long:    `List monitoring checks reports of a given deployment or of all deployments if no deployment id is provided.`,
+		Aliases: []string{"ls"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) > 1 {
+				return errors.Errorf("Expecting at most one deployment id (got %d parameters)", len(args))
+			}
+			var deploymentID string
+			if len(args) == 1 {
+				deploymentID = args[0]
+			}
+			client, err := httputil.GetClient(deployments.ClientConfig)
+			if err != nil {
+				return err
+			}
+			return listChecks(client, deploymentID, nodeName, instance, status)
+		},
+	}
+	checksListCmd.Flags().StringVarP(&nodeName, "node", "n", "", "Only list checks of the given node")
+	checksListCmd.Flags().StringVarP(&instance, "instance", "i", "", "Only list checks of the given node instance")
+	checksListCmd.Flags().StringVarP(&status, "status", "s", "", "Only list checks with the given status (passing, warning or critical)")
+	checksCmd.AddCommand(checksListCmd)
+}
+
+func listChecks(client httputil.HTTPClient, deploymentID, nodeName, instance, status string) error {
+	checksPath := "/checks"
+	query := url.Values{}
+	if deploymentID != "" {
+		checksPath = path.Join("/deployments", deploymentID, "checks")
+	}
+	if nodeName != "" {
+		query.Set("node", nodeName)
+	}
+	if instance != "" {
+		query.Set("instance", instance)
+	}
+	if status != "" {
+		query.Set("status", status)
+	}
+	if len(query) > 0 {
+		checksPath += "?" + query.Encode()
+	}
+	request, err := client.NewRequest("GET", checksPath, nil)
+	if err != nil {
+		return err
+	}
+	request.Header.Add("Accept", "application/json")
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	httputil.HandleHTTPStatusCode(response, deploymentID, "monitoring checks", http.StatusOK)
+
+	var col rest.CheckReportsCollection
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return err
+	}
+	err = json.Unmarshal(body, &col)
+	if err != nil {
+		return err
+	}
+
+	checksTable := tabutil.NewTable()
+	checksTable.AddHeaders("Deployment", "Node", "Instance", "Status", "Disabled", "Disabled Until")
+	for _, c := range col.Checks {
+		checksTable.AddRow(c.DeploymentID, c.NodeName, c.Instance, c.Status, c.Disabled, formatTime(c.DisabledUntil))
+	}
+	fmt.Println("Monitoring checks:")
+	fmt.Println(checksTable.Render())
+	return nil
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return t.Format(time.RFC3339)
+}
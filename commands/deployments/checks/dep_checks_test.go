@@ -0,0 +1,148 @@
+// Copyright 2021 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package checks
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ystia/yorc/v4/rest"
+)
+
+type httpClientMockChecks struct {
+	requests []*http.Request
+	bodies   []string
+	fails    bool
+}
+
+func (c *httpClientMockChecks) Do(req *http.Request) (*http.Response, error) {
+	if c.fails {
+		return nil, errors.New("a failure occurs")
+	}
+	c.requests = append(c.requests, req)
+	body := ""
+	if req.Body != nil {
+		b, _ := ioutil.ReadAll(req.Body)
+		body = string(b)
+	}
+	c.bodies = append(c.bodies, body)
+
+	w := httptest.NewRecorder()
+	if req.Method == http.MethodGet {
+		col := rest.CheckReportsCollection{Checks: []rest.CheckReport{
+			{DeploymentID: "dep", NodeName: "Compute", Instance: "0", Status: "PASSING"},
+		}}
+		b, _ := json.Marshal(col)
+		w.Write(b)
+	} else {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.Result(), nil
+}
+
+func (c *httpClientMockChecks) NewRequest(method, path string, body io.Reader) (*http.Request, error) {
+	return http.NewRequest(method, path, body)
+}
+
+func (c *httpClientMockChecks) Get(path string) (*http.Response, error) {
+	return &http.Response{}, nil
+}
+
+func (c *httpClientMockChecks) Head(path string) (*http.Response, error) {
+	return &http.Response{}, nil
+}
+
+func (c *httpClientMockChecks) Post(path string, contentType string, body io.Reader) (*http.Response, error) {
+	return &http.Response{}, nil
+}
+
+func (c *httpClientMockChecks) PostForm(path string, data url.Values) (*http.Response, error) {
+	return &http.Response{}, nil
+}
+
+func TestListChecks(t *testing.T) {
+	client := &httpClientMockChecks{}
+	err := listChecks(client, "", "", "", "")
+	require.NoError(t, err)
+	err = listChecks(client, "dep", "Compute", "0", "critical")
+	require.NoError(t, err)
+	require.Len(t, client.requests, 2)
+	assert.Equal(t, "/checks", client.requests[0].URL.Path)
+	assert.Equal(t, "/deployments/dep/checks", client.requests[1].URL.Path)
+	assert.Equal(t, url.Values{"node": {"Compute"}, "instance": {"0"}, "status": {"critical"}}, client.requests[1].URL.Query())
+
+	err = listChecks(&httpClientMockChecks{fails: true}, "dep", "", "", "")
+	require.Error(t, err)
+}
+
+func TestUpdateCheck(t *testing.T) {
+	client := &httpClientMockChecks{}
+	until := time.Date(2021, time.June, 16, 2, 0, 0, 0, time.UTC)
+	err := updateCheck(client, "dep", "Compute", "0", rest.CheckRequest{Enabled: new(bool), DisabledUntil: &until})
+	require.NoError(t, err)
+	enabled := true
+	err = updateCheck(client, "dep", "Compute", "0", rest.CheckRequest{Enabled: &enabled})
+	require.NoError(t, err)
+	require.Len(t, client.requests, 2)
+	assert.Equal(t, http.MethodPatch, client.requests[0].Method)
+	assert.Equal(t, "/deployments/dep/checks/Compute/0", client.requests[0].URL.Path)
+	assert.JSONEq(t, `{"enabled":false,"disabled_until":"2021-06-16T02:00:00Z"}`, client.bodies[0])
+	assert.JSONEq(t, `{"enabled":true}`, client.bodies[1])
+}
+
+func TestParseUntil(t *testing.T) {
+	now := time.Date(2021, time.June, 16, 2, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		until   string
+		want    *time.Time
+		wantErr bool
+	}{
+		{"Empty", "", nil, false},
+		{"Duration", "2h30m", timePtr(now.Add(150 * time.Minute)), false},
+		{"Date", "2021-06-17T08:00:00Z", timePtr(time.Date(2021, time.June, 17, 8, 0, 0, 0, time.UTC)), false},
+		{"Invalid", "tomorrow", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseUntil(tt.until, now)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			if tt.want == nil {
+				assert.Nil(t, got)
+				return
+			}
+			require.NotNil(t, got)
+			assert.True(t, tt.want.Equal(*got), "expected %v got %v", tt.want, got)
+		})
+	}
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
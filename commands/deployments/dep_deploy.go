@@ -35,6 +35,9 @@ func init() {
 	var shouldStreamLogs bool
 	var shouldStreamEvents bool
 	var deploymentID string
+	var dryRun bool
+	var priority string
+	var rollbackOnFailure bool
 	var deployCmd = &cobra.Command{
 		Use:   "deploy <csar_path>",
 		Short: "Deploy an application",
@@ -47,7 +50,7 @@ func init() {
 			if err != nil {
 				return err
 			}
-			return deploy(client, args, shouldStreamLogs, shouldStreamEvents, deploymentID)
+			return deploy(client, args, shouldStreamLogs, shouldStreamEvents, deploymentID, dryRun, priority, rollbackOnFailure)
 		},
 	}
 	deployCmd.PersistentFlags().BoolVarP(&shouldStreamLogs, "stream-logs", "l", false, "Stream logs after deploying the CSAR. In this mode logs can't be filtered, to use this feature see the \"log\" command.")
@@ -55,53 +58,36 @@ func init() {
 	// Do not impose a max id length as it doesn't have a concrete impact for now
 	//deployCmd.PersistentFlags().StringVarP(&deploymentID, "id", "", "", fmt.Sprintf("Specify a id for this deployment. This id should not already exists, should respect the following format: %q and should be less than %d characters long", rest.YorcDeploymentIDPattern, rest.YorcDeploymentIDMaxLength))
 	deployCmd.PersistentFlags().StringVarP(&deploymentID, "id", "", "", fmt.Sprintf("Specify a id for this deployment. This id should not already exists, should respect the following format: %q", rest.YorcDeploymentIDPattern))
+	deployCmd.PersistentFlags().BoolVarP(&dryRun, "dry-run", "", false, "Print the execution plan of the install workflow instead of deploying the CSAR. Nothing is deployed in this mode.")
+	deployCmd.PersistentFlags().StringVarP(&priority, "priority", "", "", "Priority of the deployment task (low, normal or high), by default the priority depends on the task type.")
+	deployCmd.PersistentFlags().BoolVarP(&rollbackOnFailure, "rollback-on-failure", "", false, "If the install workflow fails, automatically run a rollback task reverting the completed steps.")
 	DeploymentsCmd.AddCommand(deployCmd)
 }
 
-func deploy(client httputil.HTTPClient, args []string, shouldStreamLogs, shouldStreamEvents bool, deploymentID string) error {
+func deploy(client httputil.HTTPClient, args []string, shouldStreamLogs, shouldStreamEvents bool, deploymentID string, dryRun bool, priority string, rollbackOnFailure bool) error {
 	if len(args) != 1 {
 		return errors.Errorf("Expecting a path to a file or directory (got %d parameters)", len(args))
 	}
-
-	absPath, err := filepath.Abs(args[0])
-	if err != nil {
-		return err
+	if dryRun && (shouldStreamLogs || shouldStreamEvents) {
+		return errors.Errorf("You can't provide stream-events or stream-logs flags in dry-run mode")
 	}
-	fileInfo, err := os.Stat(absPath)
+
+	csarZip, err := readCSAR(args[0])
 	if err != nil {
 		return err
 	}
-	var location = ""
-	if !fileInfo.IsDir() {
-		file, err := os.Open(absPath)
+	if dryRun {
+		plan, err := PlanCSAR(csarZip, client, deploymentID)
 		if err != nil {
 			return err
 		}
-		defer file.Close()
-
-		buff, err := ioutil.ReadAll(file)
-		if err != nil {
-			return err
-		}
-		fileType := http.DetectContentType(buff)
-		if fileType == "application/zip" {
-			location, err = SubmitCSAR(buff, client, deploymentID)
-			if err != nil {
-				return err
-			}
-		}
+		PrintPlan(plan, !NoColor)
+		return nil
 	}
 
-	if location == "" {
-		csarZip, err := ziputil.ZipPath(absPath)
-		if err != nil {
-			return err
-		}
-		location, err = SubmitCSAR(csarZip, client, deploymentID)
-
-		if err != nil {
-			return err
-		}
+	location, err := submitCSAR(csarZip, client, deploymentID, priority, rollbackOnFailure)
+	if err != nil {
+		return err
 	}
 	taskID := path.Base(location)
 	if deploymentID == "" {
@@ -119,8 +105,51 @@ func deploy(client httputil.HTTPClient, args []string, shouldStreamLogs, shouldS
 
 }
 
+// readCSAR returns the content of the zip archive pointed by csarPath
+//
+// If csarPath is not a zip archive it is zipped.
+func readCSAR(csarPath string) ([]byte, error) {
+	absPath, err := filepath.Abs(csarPath)
+	if err != nil {
+		return nil, err
+	}
+	fileInfo, err := os.Stat(absPath)
+	if err != nil {
+		return nil, err
+	}
+	if !fileInfo.IsDir() {
+		file, err := os.Open(absPath)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+
+		buff, err := ioutil.ReadAll(file)
+		if err != nil {
+			return nil, err
+		}
+		fileType := http.DetectContentType(buff)
+		if fileType == "application/zip" {
+			return buff, nil
+		}
+	}
+
+	return ziputil.ZipPath(absPath)
+}
+
 // SubmitCSAR submits the deployment of an archive
 func SubmitCSAR(csarZip []byte, client httputil.HTTPClient, deploymentID string) (string, error) {
+	return SubmitCSARWithPriority(csarZip, client, deploymentID, "")
+}
+
+// SubmitCSARWithPriority submits the deployment of an archive with the given task priority
+//
+// If priority is empty the default priority of deployment tasks is used.
+func SubmitCSARWithPriority(csarZip []byte, client httputil.HTTPClient, deploymentID, priority string) (string, error) {
+	return submitCSAR(csarZip, client, deploymentID, priority, false)
+}
+
+func submitCSAR(csarZip []byte, client httputil.HTTPClient, deploymentID, priority string, rollbackOnFailure bool) (string, error) {
 	var request *http.Request
 	var err error
 	if deploymentID != "" {
@@ -131,6 +160,14 @@ func SubmitCSAR(csarZip []byte, client httputil.HTTPClient, deploymentID string)
 	if err != nil {
 		return "", err
 	}
+	query := request.URL.Query()
+	if priority != "" {
+		query.Set("priority", priority)
+	}
+	if rollbackOnFailure {
+		query.Set("rollbackOnFailure", "true")
+	}
+	request.URL.RawQuery = query.Encode()
 	request.Header.Add("Content-Type", "application/zip")
 	response, err := client.Do(request)
 	if err != nil {
@@ -33,8 +33,14 @@ func (c *httpClientMockDeploy) Do(req *http.Request) (*http.Response, error) {
 	if strings.Contains(c.testID, "fails") {
 		return nil, errors.New("a failure occurs")
 	}
-	res := &httptest.ResponseRecorder{
-		Code: 200,
+	res := httptest.NewRecorder()
+	if req.URL.Query().Get("dryRun") == "true" {
+		if strings.Contains(c.testID, "badPlan") {
+			res.WriteString("not a plan")
+			return res.Result(), nil
+		}
+		res.WriteString(`{"deployment_id":"myDeploymentID","workflow_name":"install","steps":[{"name":"Compute_install","target":"Compute","instances":["0"],"activities":[{"type":"delegate","value":"install","executor":{"match":"yorc\\.nodes\\.openstack\\..*","origin":"builtin"},"resources":[{"kind":"terraform","name":"infra.tf.json","content":"{}"}]}]}]}`)
+		return res.Result(), nil
 	}
 	res.Header().Set("Location", "myLocation")
 	return res.Result(), nil
@@ -61,21 +67,36 @@ func (c *httpClientMockDeploy) PostForm(path string, data url.Values) (*http.Res
 }
 
 func TestDeploy(t *testing.T) {
-	err := deploy(&httpClientMockDeploy{}, []string{"./testdata/deployment.zip"}, false, false, "myDeploymentID")
+	err := deploy(&httpClientMockDeploy{}, []string{"./testdata/deployment.zip"}, false, false, "myDeploymentID", false, "", false)
 	require.NoError(t, err, "Failed to deploy")
 }
 
 func TestDeployWithoutFilePath(t *testing.T) {
-	err := deploy(&httpClientMockDeploy{}, []string{}, false, false, "myDeploymentID")
+	err := deploy(&httpClientMockDeploy{}, []string{}, false, false, "myDeploymentID", false, "", false)
 	require.Error(t, err, "Expect error as no file path has been provided")
 }
 
 func TestDeployWithBadFilePath(t *testing.T) {
-	err := deploy(&httpClientMockDeploy{}, []string{"fake.zip"}, false, false, "myDeploymentID")
+	err := deploy(&httpClientMockDeploy{}, []string{"fake.zip"}, false, false, "myDeploymentID", false, "", false)
 	require.Error(t, err, "Expect error as file doesn't exist")
 }
 
 func TestDeployWithHTTPFailure(t *testing.T) {
-	err := deploy(&httpClientMockDeploy{testID: "fails"}, []string{"./testdata/deployment.zip"}, false, false, "myDeploymentID")
+	err := deploy(&httpClientMockDeploy{testID: "fails"}, []string{"./testdata/deployment.zip"}, false, false, "myDeploymentID", false, "", false)
 	require.Error(t, err, "Expected error due to HTTP failure")
 }
+
+func TestDeployDryRun(t *testing.T) {
+	err := deploy(&httpClientMockDeploy{}, []string{"./testdata/deployment.zip"}, false, false, "myDeploymentID", true, "", false)
+	require.NoError(t, err, "Failed to plan deployment")
+}
+
+func TestDeployDryRunWithBadPlan(t *testing.T) {
+	err := deploy(&httpClientMockDeploy{testID: "badPlan"}, []string{"./testdata/deployment.zip"}, false, false, "myDeploymentID", true, "", false)
+	require.Error(t, err, "Expected error as the returned plan can't be decoded")
+}
+
+func TestDeployDryRunWithStreamLogs(t *testing.T) {
+	err := deploy(&httpClientMockDeploy{}, []string{"./testdata/deployment.zip"}, true, false, "myDeploymentID", true, "", false)
+	require.Error(t, err, "Expected error as logs can't be streamed in dry-run mode")
+}
@@ -34,6 +34,7 @@ import (
 func init() {
 	var fromBeginning bool
 	var noStream bool
+	var follow bool
 	var eventCmd = &cobra.Command{
 		Use:     "events [<DeploymentId>]",
 		Short:   "Stream events for a deployment or all deployments",
@@ -56,11 +57,19 @@ func init() {
 			}
 			colorize := !NoColor
 
+			if follow {
+				if noStream {
+					return errors.New("--follow and --no-stream flags are mutually exclusive")
+				}
+				FollowEvents(client, deploymentID, colorize, fromBeginning)
+				return nil
+			}
 			StreamsEvents(client, deploymentID, colorize, fromBeginning, noStream)
 			return nil
 		},
 	}
 	eventCmd.PersistentFlags().BoolVarP(&fromBeginning, "from-beginning", "b", false, "Show events from the beginning of deployments")
+	eventCmd.PersistentFlags().BoolVarP(&follow, "follow", "f", false, "Follow events pushed by Yorc over a single long-lived connection (server-sent events) instead of polling")
 	eventCmd.PersistentFlags().BoolVarP(&noStream, "no-stream", "n", false, "Show events then exit. Do not stream events. It implies --from-beginning")
 	DeploymentsCmd.AddCommand(eventCmd)
 }
@@ -198,6 +207,8 @@ func formatEvent(event json.RawMessage, colorize bool) string {
 			data[events.ETaskID.String()], data[events.ETaskExecutionID.String()], data[events.EWorkflowID.String()], data[events.EInstanceID.String()], data[events.EWorkflowStepID.String()], data[events.ENodeID.String()], data[events.EOperationName.String()], formatOptionalInfo(data), data[events.EStatus.String()])
 	case events.StatusChangeTypeAttributeValue:
 		ret = fmt.Sprintf("%s:\t Deployment: %s\t Node: %s\t Instance: %s\t Attribute: %s\t Value: %s\t Status: %s\t\n", ts, data[events.EDeploymentID.String()], data[events.ENodeID.String()], data[events.EInstanceID.String()], data[events.EAttributeName.String()], data[events.EAttributeValue.String()], data[events.EStatus.String()])
+	case events.StatusChangeTypeHostsPoolHost:
+		ret = fmt.Sprintf("%s:\t Deployment: %s\t Location: %s\t Host: %s\t Host Status: %s\n", ts, data[events.EDeploymentID.String()], data[events.ELocationName.String()], data[events.EHostname.String()], data[events.EStatus.String()])
 
 	}
 
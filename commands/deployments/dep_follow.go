@@ -0,0 +1,213 @@
+// Copyright 2021 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deployments
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/pkg/errors"
+
+	"github.com/ystia/yorc/v4/commands/httputil"
+	"github.com/ystia/yorc/v4/rest"
+)
+
+// maxServerSentEventSize is the maximum size of a single line of a server-sent event
+const maxServerSentEventSize = 10 * 1024 * 1024
+
+// Delays between reconnections to a stream closed by the server, the delay is doubled
+// each time a stream is closed without sending any event
+const (
+	minFollowReconnectDelay = 500 * time.Millisecond
+	maxFollowReconnectDelay = 10 * time.Second
+)
+
+// followReconnectWait waits before reconnecting to a stream, it can be overridden in tests
+var followReconnectWait = time.Sleep
+
+type serverSentEvent struct {
+	id    string
+	event string
+	data  []byte
+}
+
+// FollowEvents allows to follow events pushed by the Yorc server-sent events stream
+func FollowEvents(client httputil.HTTPClient, deploymentID string, colorize, fromBeginning bool) {
+	if colorize {
+		defer color.Unset()
+	}
+	err := followStream(client, deploymentID, "events", fromBeginning, func(data []byte) {
+		fmt.Printf("%s\n", formatEvent(json.RawMessage(data), colorize))
+	})
+	if err != nil {
+		httputil.ErrExit(err)
+	}
+}
+
+// FollowLogs allows to follow logs pushed by the Yorc server-sent events stream
+func FollowLogs(client httputil.HTTPClient, deploymentID string, colorize, fromBeginning bool) {
+	if colorize {
+		defer color.Unset()
+	}
+	err := followStream(client, deploymentID, "logs", fromBeginning, func(data []byte) {
+		if colorize {
+			fmt.Printf("%s\n", color.CyanString("%s", format(json.RawMessage(data))))
+		} else {
+			fmt.Printf("%s\n", format(json.RawMessage(data)))
+		}
+	})
+	if err != nil {
+		httputil.ErrExit(err)
+	}
+}
+
+// followStream reads the given resource (events or logs) stream and calls handler for each received message.
+//
+// When the server closes the connection, followStream waits a bit then reconnects and resumes the stream from the last
+// received event id.
+func followStream(client httputil.HTTPClient, deploymentID, resource string, fromBeginning bool, handler func(data []byte)) error {
+	resourcePath := "/" + resource
+	if deploymentID != "" {
+		resourcePath = "/deployments/" + deploymentID + resourcePath
+	}
+
+	var lastIdx uint64 = 1
+	if !fromBeginning {
+		response, err := client.Head(resourcePath)
+		if err != nil {
+			return errors.Wrap(err, httputil.YorcAPIDefaultErrorMsg)
+		}
+		if deploymentID != "" {
+			httputil.HandleHTTPStatusCode(response, deploymentID, "deployment", http.StatusOK)
+		}
+		if idxHd := response.Header.Get(rest.YorcIndexHeader); idxHd != "" {
+			lastIdx, err = strconv.ParseUint(idxHd, 10, 64)
+			if err != nil {
+				return errors.Wrapf(err, "invalid %s header", rest.YorcIndexHeader)
+			}
+			fmt.Printf("Streaming new %s...\n", resource)
+		} else {
+			fmt.Fprintf(os.Stderr, "Failed to get latest %s index from Yorc, %s will appear from the beginning.", resource, resource)
+		}
+	}
+
+	reconnectDelay := minFollowReconnectDelay
+	for {
+		request, err := client.NewRequest("GET", fmt.Sprintf("%s/stream?index=%d", resourcePath, lastIdx), nil)
+		if err != nil {
+			return errors.Wrap(err, httputil.YorcAPIDefaultErrorMsg)
+		}
+		request.Header.Add("Accept", "text/event-stream")
+		response, err := client.Do(request)
+		if err != nil {
+			return errors.Wrap(err, httputil.YorcAPIDefaultErrorMsg)
+		}
+		if deploymentID != "" {
+			httputil.HandleHTTPStatusCode(response, deploymentID, "deployment", http.StatusOK)
+		} else {
+			httputil.HandleHTTPStatusCode(response, resource, "stream", http.StatusOK)
+		}
+
+		var received bool
+		err = readServerSentEvents(response.Body, func(sse serverSentEvent) error {
+			if sse.event == rest.SSEEventTypeError {
+				return errors.Errorf("Yorc interrupted the %s stream: %s", resource, sse.data)
+			}
+			received = true
+			handler(sse.data)
+			if sse.id != "" {
+				idx, err := strconv.ParseUint(sse.id, 10, 64)
+				if err != nil {
+					return errors.Wrapf(err, "invalid event id %q", sse.id)
+				}
+				lastIdx = idx
+			}
+			return nil
+		})
+		response.Body.Close()
+		if err != nil {
+			return err
+		}
+		// Stream closed by the server, reconnect after a delay growing while no events are received
+		if received {
+			reconnectDelay = minFollowReconnectDelay
+		}
+		followReconnectWait(reconnectDelay)
+		reconnectDelay *= 2
+		if reconnectDelay > maxFollowReconnectDelay {
+			reconnectDelay = maxFollowReconnectDelay
+		}
+	}
+}
+
+// readServerSentEvents parses a text/event-stream and calls fn for each dispatched event.
+//
+// Comments and unknown fields are ignored as required by the specification.
+func readServerSentEvents(r io.Reader, fn func(serverSentEvent) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxServerSentEventSize)
+	var sse serverSentEvent
+	var data bytes.Buffer
+	var hasData bool
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			// Dispatch event
+			if hasData {
+				sse.data = bytes.TrimSuffix(data.Bytes(), []byte("\n"))
+				if err := fn(sse); err != nil {
+					return err
+				}
+			}
+			sse = serverSentEvent{}
+			data = bytes.Buffer{}
+			hasData = false
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			// comment
+			continue
+		}
+		field, value := line, ""
+		if i := strings.Index(line, ":"); i >= 0 {
+			field = line[:i]
+			value = strings.TrimPrefix(line[i+1:], " ")
+		}
+		switch field {
+		case "id":
+			sse.id = value
+		case "event":
+			sse.event = value
+		case "data":
+			data.WriteString(value)
+			data.WriteString("\n")
+			hasData = true
+		}
+	}
+	err := scanner.Err()
+	if err == io.ErrUnexpectedEOF {
+		return nil
+	}
+	return errors.Wrap(err, "failed to read events stream")
+}
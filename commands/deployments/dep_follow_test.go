@@ -0,0 +1,101 @@
+// Copyright 2021 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deployments
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_readServerSentEvents(t *testing.T) {
+	stream := ": keep-alive\n\n" +
+		"event: event\ndata: {\"a\":\"b\"}\n\n" +
+		"id: 42\nevent: event\ndata: {\ndata: \"c\":\"d\"\ndata: }\n\n" +
+		"retry: 1000\n\n" +
+		"data:no-space\n\n" +
+		"data: incomplete"
+
+	var got []serverSentEvent
+	err := readServerSentEvents(strings.NewReader(stream), func(sse serverSentEvent) error {
+		got = append(got, sse)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Len(t, got, 3)
+	assert.Equal(t, serverSentEvent{event: "event", data: []byte(`{"a":"b"}`)}, got[0])
+	assert.Equal(t, serverSentEvent{id: "42", event: "event", data: []byte("{\n\"c\":\"d\"\n}")}, got[1])
+	assert.Equal(t, serverSentEvent{data: []byte("no-space")}, got[2])
+}
+
+func Test_followStreamResumesAndStopsOnError(t *testing.T) {
+	var requests []string
+	streams := []string{
+		"event: log\ndata: {\"content\":\"one\"}\n\nid: 5\nevent: log\ndata: {\"content\":\"two\"}\n\n",
+		"id: 7\nevent: log\ndata: {\"content\":\"three\"}\n\nevent: error\ndata: {\"detail\":\"boom\"}\n\n",
+	}
+	mockClient := &mockHTTPClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			requests = append(requests, req.URL.String())
+			assert.Equal(t, "text/event-stream", req.Header.Get("Accept"))
+			res := &httptest.ResponseRecorder{
+				Code: 200,
+				Body: bytes.NewBufferString(streams[len(requests)-1]),
+			}
+			return res.Result(), nil
+		},
+	}
+	var delays []time.Duration
+	defer func(wait func(time.Duration)) { followReconnectWait = wait }(followReconnectWait)
+	followReconnectWait = func(d time.Duration) { delays = append(delays, d) }
+	var received []string
+	err := followStream(mockClient, "myDep", "logs", true, func(data []byte) {
+		received = append(received, string(data))
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "boom")
+	assert.Equal(t, []string{"/deployments/myDep/logs/stream?index=1", "/deployments/myDep/logs/stream?index=5"}, requests)
+	assert.Equal(t, []string{`{"content":"one"}`, `{"content":"two"}`, `{"content":"three"}`}, received)
+	assert.Equal(t, []time.Duration{minFollowReconnectDelay}, delays)
+}
+
+func Test_followStreamBacksOffOnEmptyStreams(t *testing.T) {
+	streams := []string{"", ": keep-alive\n\n", "", "", "", "", "", "id: 3\nevent: log\ndata: {}\n\n", "event: error\ndata: {}\n\n"}
+	var requests int
+	mockClient := &mockHTTPClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			requests++
+			res := &httptest.ResponseRecorder{
+				Code: 200,
+				Body: bytes.NewBufferString(streams[requests-1]),
+			}
+			return res.Result(), nil
+		},
+	}
+	var delays []time.Duration
+	defer func(wait func(time.Duration)) { followReconnectWait = wait }(followReconnectWait)
+	followReconnectWait = func(d time.Duration) { delays = append(delays, d) }
+	err := followStream(mockClient, "myDep", "events", true, func(data []byte) {})
+	require.Error(t, err)
+	assert.Equal(t, len(streams), requests)
+	ms := time.Millisecond
+	assert.Equal(t, []time.Duration{500 * ms, 1000 * ms, 2000 * ms, 4000 * ms, 8000 * ms, 10000 * ms, 10000 * ms, 500 * ms}, delays)
+}
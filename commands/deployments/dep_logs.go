@@ -34,6 +34,7 @@ import (
 func init() {
 	var fromBeginning bool
 	var noStream bool
+	var follow bool
 	var logCmd = &cobra.Command{
 		Use:     "logs [<DeploymentId>]",
 		Short:   "Stream logs for a deployment or all deployments",
@@ -56,11 +57,19 @@ func init() {
 			}
 			colorize := !NoColor
 
+			if follow {
+				if noStream {
+					return errors.New("--follow and --no-stream flags are mutually exclusive")
+				}
+				FollowLogs(client, deploymentID, colorize, fromBeginning)
+				return nil
+			}
 			StreamsLogs(client, deploymentID, colorize, fromBeginning, noStream)
 			return nil
 		},
 	}
 	logCmd.PersistentFlags().BoolVarP(&fromBeginning, "from-beginning", "b", false, "Show logs from the beginning of deployments")
+	logCmd.PersistentFlags().BoolVarP(&follow, "follow", "f", false, "Follow logs pushed by Yorc over a single long-lived connection (server-sent events) instead of polling")
 	logCmd.PersistentFlags().BoolVarP(&noStream, "no-stream", "n", false, "Show logs then exit. Do not stream logs. It implies --from-beginning")
 	DeploymentsCmd.AddCommand(logCmd)
 }
@@ -0,0 +1,138 @@
+// Copyright 2021 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deployments
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/pkg/errors"
+
+	"github.com/ystia/yorc/v4/commands/httputil"
+	"github.com/ystia/yorc/v4/tasks/workflow"
+)
+
+// PlanCSAR submits an archive in dry-run mode and returns the plan of its install workflow
+//
+// Nothing is deployed, the deployment definition is discarded by Yorc once the plan is computed.
+func PlanCSAR(csarZip []byte, client httputil.HTTPClient, deploymentID string) (*workflow.Plan, error) {
+	var request *http.Request
+	var err error
+	if deploymentID != "" {
+		request, err = client.NewRequest(http.MethodPut, path.Join("/deployments", deploymentID)+"?dryRun=true", bytes.NewReader(csarZip))
+	} else {
+		request, err = client.NewRequest(http.MethodPost, "/deployments?dryRun=true", bytes.NewReader(csarZip))
+	}
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Add("Content-Type", "application/zip")
+	request.Header.Add("Accept", "application/json")
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		// Try to get the reason
+		httputil.PrintErrors(response.Body)
+		return nil, errors.Errorf("Dry run failed: Expecting HTTP Status code 200, got %d, reason %q", response.StatusCode, response.Status)
+	}
+	return DecodePlan(response.Body)
+}
+
+// DecodePlan reads a workflow execution plan returned by Yorc
+func DecodePlan(r io.Reader) (*workflow.Plan, error) {
+	plan := new(workflow.Plan)
+	err := json.NewDecoder(r).Decode(plan)
+	return plan, errors.Wrap(err, "failed to decode execution plan")
+}
+
+// PrintPlan renders a workflow execution plan on the standard output
+func PrintPlan(plan *workflow.Plan, colorize bool) {
+	stepColor := fmt.Sprint
+	errColor := fmt.Sprint
+	if colorize {
+		stepColor = color.New(color.FgHiWhite, color.Bold).SprintFunc()
+		errColor = color.New(color.FgHiRed, color.Bold).SprintFunc()
+	}
+	fmt.Printf("Execution plan of workflow %q on deployment %q (nothing was executed):\n", plan.WorkflowName, plan.DeploymentID)
+	if len(plan.Steps) == 0 {
+		fmt.Println("  No steps to execute.")
+		return
+	}
+	for i, step := range plan.Steps {
+		fmt.Printf("\n%d. %s\n", i+1, stepColor(step.Name))
+		if step.Target != "" {
+			target := step.Target
+			if step.TargetRelationship != "" {
+				target += " (relationship " + step.TargetRelationship + ")"
+			}
+			fmt.Printf("   Target: %s\n", target)
+		}
+		if step.OperationHost != "" {
+			fmt.Printf("   Operation host: %s\n", step.OperationHost)
+		}
+		if len(step.Instances) > 0 {
+			fmt.Printf("   Instances: %s\n", strings.Join(step.Instances, ", "))
+		}
+		if step.OnFailurePath {
+			fmt.Println("   Only executed on failure")
+		} else if step.OnCancelPath {
+			fmt.Println("   Only executed on cancellation")
+		}
+		for _, activity := range step.Activities {
+			printPlannedActivity(activity, errColor)
+		}
+		if len(step.Next) > 0 {
+			fmt.Printf("   Next: %s\n", strings.Join(step.Next, ", "))
+		}
+	}
+}
+
+func printPlannedActivity(activity *workflow.PlannedActivity, errColor func(a ...interface{}) string) {
+	fmt.Printf("   - %s %s\n", activity.Type, activity.Value)
+	if activity.Executor != nil {
+		fmt.Printf("       Executor: %s (%s)\n", activity.Executor.Match, activity.Executor.Origin)
+	}
+	if activity.ImplementationArtifact != "" {
+		fmt.Printf("       Implementation artifact: %s\n", activity.ImplementationArtifact)
+	}
+	for _, input := range activity.Inputs {
+		name := input.Name
+		if input.Instance != "" {
+			name += "[" + input.Instance + "]"
+		}
+		fmt.Printf("       Input %s: %s\n", name, input.Value)
+	}
+	if activity.Note != "" {
+		fmt.Printf("       Note: %s\n", activity.Note)
+	}
+	if activity.Error != "" {
+		fmt.Printf("       %s %s\n", errColor("Error:"), activity.Error)
+	}
+	for _, resource := range activity.Resources {
+		fmt.Printf("       %s resource %s:\n", resource.Kind, resource.Name)
+		for _, line := range strings.Split(strings.TrimRight(resource.Content, "\n"), "\n") {
+			fmt.Printf("         %s\n", line)
+		}
+	}
+}
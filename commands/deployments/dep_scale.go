@@ -32,6 +32,7 @@ func init() {
 	var shouldStreamEvents bool
 	var nodeName string
 	var instancesDelta int32
+	var priority string
 	var scaleCmd = &cobra.Command{
 		Use:   "scale <id>",
 		Short: "Scale a node",
@@ -55,7 +56,7 @@ func init() {
 			}
 			deploymentID := args[0]
 
-			location, err := postScalingRequest(client, deploymentID, nodeName, instancesDelta)
+			location, err := postScalingRequest(client, deploymentID, nodeName, instancesDelta, priority)
 			if err != nil {
 				return err
 			}
@@ -73,12 +74,13 @@ func init() {
 	}
 	scaleCmd.PersistentFlags().StringVarP(&nodeName, "node", "n", "", "The name of the node that should be scaled.")
 	scaleCmd.PersistentFlags().Int32VarP(&instancesDelta, "delta", "d", 0, "The non-zero number of instance to add (if > 0) or remove (if < 0).")
+	scaleCmd.PersistentFlags().StringVarP(&priority, "priority", "", "", "Priority of the scaling task (low, normal or high), scaling tasks have a high priority by default.")
 	scaleCmd.PersistentFlags().BoolVarP(&shouldStreamLogs, "stream-logs", "l", false, "Stream logs after issuing the scaling request. In this mode logs can't be filtered, to use this feature see the \"log\" command.")
 	scaleCmd.PersistentFlags().BoolVarP(&shouldStreamEvents, "stream-events", "e", false, "Stream events after  issuing the scaling request.")
 	DeploymentsCmd.AddCommand(scaleCmd)
 }
 
-func postScalingRequest(client httputil.HTTPClient, deploymentID, nodeName string, instancesDelta int32) (string, error) {
+func postScalingRequest(client httputil.HTTPClient, deploymentID, nodeName string, instancesDelta int32, priority string) (string, error) {
 	request, err := client.NewRequest("POST", path.Join("/deployments", deploymentID, "scale", nodeName), nil)
 	if err != nil {
 		httputil.ErrExit(errors.Wrap(err, httputil.YorcAPIDefaultErrorMsg))
@@ -86,6 +88,9 @@ func postScalingRequest(client httputil.HTTPClient, deploymentID, nodeName strin
 
 	query := request.URL.Query()
 	query.Set("delta", strconv.Itoa(int(instancesDelta)))
+	if priority != "" {
+		query.Set("priority", priority)
+	}
 
 	request.URL.RawQuery = query.Encode()
 
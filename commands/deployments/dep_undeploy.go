@@ -30,6 +30,7 @@ func init() {
 	var shouldStreamLogs bool
 	var shouldStreamEvents bool
 	var stopOnError bool
+	var priority string
 	var undeployCmd = &cobra.Command{
 		Use:   "undeploy <DeploymentId>",
 		Short: "Undeploy an application",
@@ -52,6 +53,9 @@ func init() {
 			if stopOnError {
 				q.Add("stopOnError", "true")
 			}
+			if priority != "" {
+				q.Add("priority", priority)
+			}
 			request, err := client.NewRequest("DELETE", urlStr, nil)
 			if err != nil {
 				httputil.ErrExit(err)
@@ -82,6 +86,7 @@ func init() {
 	DeploymentsCmd.AddCommand(undeployCmd)
 	undeployCmd.PersistentFlags().BoolVarP(&purge, "purge", "p", false, "To use if you want to purge instead of undeploy")
 	undeployCmd.PersistentFlags().BoolVarP(&stopOnError, "stop-on-error", "", false, "By default if an error occurs during the undeployment, the error is bypassed and the undeployment continues. This flag allows to stop if an error occurs.")
+	undeployCmd.PersistentFlags().StringVarP(&priority, "priority", "", "", "Priority of the undeployment task (low, normal or high), by default the priority depends on the task type.")
 	undeployCmd.PersistentFlags().BoolVarP(&shouldStreamLogs, "stream-logs", "l", false, "Stream logs after undeploying the application. In this mode logs can't be filtered, to use this feature see the \"log\" command.")
 	undeployCmd.PersistentFlags().BoolVarP(&shouldStreamEvents, "stream-events", "e", false, "Stream events after undeploying the CSAR.")
 
@@ -0,0 +1,116 @@
+// Copyright 2021 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deployments
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"path"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/ystia/yorc/v4/commands/httputil"
+)
+
+func init() {
+	var shouldStreamLogs bool
+	var shouldStreamEvents bool
+	var priority string
+	var updateCmd = &cobra.Command{
+		Use:   "update <id> <csar_path>",
+		Short: "Update a deployment",
+		Long: `Update a deployment <id> with a new version of its CSAR pointed by <csar_path>
+	Nodes added to the topology are installed, nodes removed from the topology are uninstalled
+	and other changes (properties, workflows, operations) are applied to the deployment definition.
+	<csar_path> is handled as for the deploy command.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := httputil.GetClient(ClientConfig)
+			if err != nil {
+				return err
+			}
+			return update(client, args, shouldStreamLogs, shouldStreamEvents, priority)
+		},
+	}
+	updateCmd.PersistentFlags().BoolVarP(&shouldStreamLogs, "stream-logs", "l", false, "Stream logs after submitting the update. In this mode logs can't be filtered, to use this feature see the \"log\" command.")
+	updateCmd.PersistentFlags().BoolVarP(&shouldStreamEvents, "stream-events", "e", false, "Stream events after submitting the update.")
+	updateCmd.PersistentFlags().StringVarP(&priority, "priority", "", "", "Priority of the update tasks (low, normal or high), by default the priority depends on the task type.")
+	DeploymentsCmd.AddCommand(updateCmd)
+}
+
+func update(client httputil.HTTPClient, args []string, shouldStreamLogs, shouldStreamEvents bool, priority string) error {
+	if len(args) != 2 {
+		return errors.Errorf("Expecting a deployment id and a path to a file or directory (got %d parameters)", len(args))
+	}
+	if shouldStreamLogs && shouldStreamEvents {
+		return errors.Errorf("You can't provide stream-events and stream-logs flags at same time")
+	}
+	deploymentID := args[0]
+	csarZip, err := readCSAR(args[1])
+	if err != nil {
+		return err
+	}
+
+	location, err := submitUpdate(csarZip, client, deploymentID, priority)
+	if err != nil {
+		return err
+	}
+	if location == "" {
+		fmt.Printf("Deployment %s updated, there is no node to install or uninstall\n", deploymentID)
+		return nil
+	}
+	fmt.Printf("Deployment update submitted. Deployment Id: %s\t(Update Task Id: %s)\n", deploymentID, path.Base(location))
+	if shouldStreamLogs {
+		StreamsLogs(client, deploymentID, !NoColor, false, false)
+	} else if shouldStreamEvents {
+		StreamsEvents(client, deploymentID, !NoColor, false, false)
+	}
+	return nil
+}
+
+// submitUpdate submits an updated CSAR for a deployment
+//
+// It returns the location of the task handling the update or an empty string if the update was applied synchronously.
+func submitUpdate(csarZip []byte, client httputil.HTTPClient, deploymentID, priority string) (string, error) {
+	request, err := client.NewRequest(http.MethodPatch, path.Join("/deployments", deploymentID), bytes.NewReader(csarZip))
+	if err != nil {
+		return "", err
+	}
+	if priority != "" {
+		query := request.URL.Query()
+		query.Set("priority", priority)
+		request.URL.RawQuery = query.Encode()
+	}
+	request.Header.Add("Content-Type", "application/zip")
+	response, err := client.Do(request)
+	if err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+	switch response.StatusCode {
+	case http.StatusOK:
+		return "", nil
+	case http.StatusAccepted:
+		if location := response.Header.Get("Location"); location != "" {
+			return location, nil
+		}
+		return "", errors.New("No \"Location\" header returned in Yorc response")
+	default:
+		// Try to get the reason
+		httputil.PrintErrors(response.Body)
+		return "", errors.Errorf("PATCH failed: Expecting HTTP Status code 200 or 202, got %d, reason %q", response.StatusCode, response.Status)
+	}
+}
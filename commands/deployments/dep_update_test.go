@@ -0,0 +1,89 @@
+// Copyright 2021 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deployments
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type httpClientMockUpdate struct {
+	statusCode int
+	location   string
+}
+
+func (c *httpClientMockUpdate) Do(req *http.Request) (*http.Response, error) {
+	res := httptest.NewRecorder()
+	if req.Method != http.MethodPatch {
+		res.WriteHeader(http.StatusMethodNotAllowed)
+		return res.Result(), nil
+	}
+	if c.location != "" {
+		res.Header().Set("Location", c.location)
+	}
+	res.WriteHeader(c.statusCode)
+	return res.Result(), nil
+}
+
+func (c *httpClientMockUpdate) NewRequest(method, path string, body io.Reader) (*http.Request, error) {
+	return http.NewRequest(method, path, body)
+}
+
+func (c *httpClientMockUpdate) Get(path string) (*http.Response, error) {
+	return &http.Response{}, nil
+}
+
+func (c *httpClientMockUpdate) Head(path string) (*http.Response, error) {
+	return &http.Response{}, nil
+}
+
+func (c *httpClientMockUpdate) Post(path string, contentType string, body io.Reader) (*http.Response, error) {
+	return &http.Response{}, nil
+}
+
+func (c *httpClientMockUpdate) PostForm(path string, data url.Values) (*http.Response, error) {
+	return &http.Response{}, nil
+}
+
+func TestUpdate(t *testing.T) {
+	tests := []struct {
+		name    string
+		client  *httpClientMockUpdate
+		args    []string
+		wantErr bool
+	}{
+		{"UpdateWithTask", &httpClientMockUpdate{statusCode: http.StatusAccepted, location: "/deployments/myDeploymentID/tasks/myTask"}, []string{"myDeploymentID", "./testdata/deployment.zip"}, false},
+		{"UpdateWithoutTask", &httpClientMockUpdate{statusCode: http.StatusOK}, []string{"myDeploymentID", "./testdata/deployment.zip"}, false},
+		{"UpdateWithoutLocation", &httpClientMockUpdate{statusCode: http.StatusAccepted}, []string{"myDeploymentID", "./testdata/deployment.zip"}, true},
+		{"UpdateRejected", &httpClientMockUpdate{statusCode: http.StatusConflict}, []string{"myDeploymentID", "./testdata/deployment.zip"}, true},
+		{"UpdateWithoutFilePath", &httpClientMockUpdate{statusCode: http.StatusOK}, []string{"myDeploymentID"}, true},
+		{"UpdateWithBadFilePath", &httpClientMockUpdate{statusCode: http.StatusOK}, []string{"myDeploymentID", "fake.zip"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := update(tt.client, tt.args, false, false, "")
+			if tt.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
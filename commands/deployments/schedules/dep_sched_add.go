@@ -0,0 +1,101 @@
+// Copyright 2021 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schedules
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/ystia/yorc/v4/commands/deployments"
+	"github.com/ystia/yorc/v4/commands/httputil"
+	"github.com/ystia/yorc/v4/rest"
+)
+
+func init() {
+	var req rest.WorkflowScheduleRequest
+	var inputs map[string]string
+	var schedAddCmd = &cobra.Command{
+		Use:   "add <DeploymentId>",
+		Short: "Schedule recurring executions of a workflow",
+		Long: `Schedule recurring executions of a workflow of a given deployment.
+The cron expression is either a standard 5 fields expression (minute, hour, day of month, month, day of week)
+or one of the @yearly, @annually, @monthly, @weekly, @daily, @midnight and @hourly descriptors.`,
+		Example: `  yorc deployments schedules add myDeployment -w backup -c "0 2 * * *" -z Europe/Paris -i retention=7`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return errors.Errorf("Expecting a deployment id (got %d parameters)", len(args))
+			}
+			client, err := httputil.GetClient(deployments.ClientConfig)
+			if err != nil {
+				return err
+			}
+			req.Inputs = toRequestInputs(inputs)
+			return addSchedule(client, args[0], req)
+		},
+	}
+	addScheduleDefinitionFlags(schedAddCmd, &req, &inputs)
+	schedulesCmd.AddCommand(schedAddCmd)
+}
+
+func addScheduleDefinitionFlags(cmd *cobra.Command, req *rest.WorkflowScheduleRequest, inputs *map[string]string) {
+	cmd.Flags().StringVarP(&req.WorkflowName, "workflow-name", "w", "", "The name of the workflow to execute")
+	cmd.Flags().StringVarP(&req.Cron, "cron", "c", "", "The cron expression defining when the workflow is executed")
+	cmd.Flags().StringVarP(&req.TimeZone, "time-zone", "z", "", "The time zone name in which the cron expression is evaluated (UTC by default), for instance \"Europe/Paris\"")
+	cmd.Flags().BoolVarP(&req.ContinueOnError, "continue-on-error", "", false, "By default if an error occurs in a step of a workflow then other running steps are cancelled and the workflow is stopped. This flag allows to continue to the next steps even if an error occurs.")
+	cmd.Flags().StringToStringVarP(inputs, "input", "i", nil, "A workflow input value defined as name=value, this flag could be repeated")
+}
+
+func toRequestInputs(inputs map[string]string) map[string]interface{} {
+	if len(inputs) == 0 {
+		return nil
+	}
+	res := make(map[string]interface{}, len(inputs))
+	for k, v := range inputs {
+		res[k] = v
+	}
+	return res
+}
+
+func addSchedule(client httputil.HTTPClient, deploymentID string, req rest.WorkflowScheduleRequest) error {
+	if req.WorkflowName == "" {
+		return errors.New("Missing mandatory \"workflow-name\" parameter")
+	}
+	if req.Cron == "" {
+		return errors.New("Missing mandatory \"cron\" parameter")
+	}
+	b, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	request, err := client.NewRequest("POST", path.Join("/deployments", deploymentID, "schedules"), bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	request.Header.Add("Content-Type", "application/json")
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	httputil.HandleHTTPStatusCode(response, deploymentID, "deployment", http.StatusCreated)
+	fmt.Printf("Workflow %q scheduled with schedule id %s\n", req.WorkflowName, path.Base(response.Header.Get("Location")))
+	return nil
+}
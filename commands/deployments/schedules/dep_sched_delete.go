@@ -0,0 +1,60 @@
+// Copyright 2021 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schedules
+
+import (
+	"net/http"
+	"path"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/ystia/yorc/v4/commands/deployments"
+	"github.com/ystia/yorc/v4/commands/httputil"
+)
+
+func init() {
+	var schedDeleteCmd = &cobra.Command{
+		Use:     "delete <DeploymentId> <ScheduleId>",
+		Short:   "Delete a workflow schedule",
+		Long:    `Delete a workflow schedule of a given deployment, running workflows are not affected.`,
+		Aliases: []string{"rm"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 2 {
+				return errors.Errorf("Expecting a deployment id and a schedule id (got %d parameters)", len(args))
+			}
+			client, err := httputil.GetClient(deployments.ClientConfig)
+			if err != nil {
+				return err
+			}
+			return deleteSchedule(client, args[0], args[1])
+		},
+	}
+	schedulesCmd.AddCommand(schedDeleteCmd)
+}
+
+func deleteSchedule(client httputil.HTTPClient, deploymentID, scheduleID string) error {
+	request, err := client.NewRequest("DELETE", path.Join("/deployments", deploymentID, "schedules", scheduleID), nil)
+	if err != nil {
+		return err
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	httputil.HandleHTTPStatusCode(response, deploymentID+"/"+scheduleID, "deployment/schedule", http.StatusOK)
+	return nil
+}
@@ -0,0 +1,96 @@
+// Copyright 2021 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schedules
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"path"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/ystia/yorc/v4/commands/deployments"
+	"github.com/ystia/yorc/v4/commands/httputil"
+	"github.com/ystia/yorc/v4/helper/tabutil"
+	"github.com/ystia/yorc/v4/rest"
+)
+
+func init() {
+	var schedListCmd = &cobra.Command{
+		Use:     "list <DeploymentId>",
+		Short:   "List workflows schedules of a given deployment",
+		Long:    `List workflows schedules of a given deployment with their last and next runs.`,
+		Aliases: []string{"ls"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return errors.Errorf("Expecting a deployment id (got %d parameters)", len(args))
+			}
+			client, err := httputil.GetClient(deployments.ClientConfig)
+			if err != nil {
+				return err
+			}
+			return listSchedules(client, args[0])
+		},
+	}
+	schedulesCmd.AddCommand(schedListCmd)
+}
+
+func listSchedules(client httputil.HTTPClient, deploymentID string) error {
+	request, err := client.NewRequest("GET", path.Join("/deployments", deploymentID, "schedules"), nil)
+	if err != nil {
+		return err
+	}
+	request.Header.Add("Accept", "application/json")
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	httputil.HandleHTTPStatusCode(response, deploymentID, "deployment/schedules", http.StatusOK)
+
+	var col rest.WorkflowSchedulesCollection
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return err
+	}
+	err = json.Unmarshal(body, &col)
+	if err != nil {
+		return err
+	}
+
+	schedTable := tabutil.NewTable()
+	schedTable.AddHeaders("Id", "Workflow", "Cron", "Time Zone", "Next Run", "Last Run", "Last Task Id", "Last Error")
+	for _, s := range col.Schedules {
+		tz := s.TimeZone
+		if tz == "" {
+			tz = "UTC"
+		}
+		schedTable.AddRow(s.ID, s.WorkflowName, s.Cron, tz, formatTime(s.NextRun), formatTime(s.LastRun), s.LastTaskID, s.LastError)
+	}
+	fmt.Println("Workflows schedules:")
+	fmt.Println(schedTable.Render())
+	return nil
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return t.Format(time.RFC3339)
+}
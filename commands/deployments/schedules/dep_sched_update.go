@@ -0,0 +1,129 @@
+// Copyright 2021 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schedules
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"path"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/ystia/yorc/v4/commands/deployments"
+	"github.com/ystia/yorc/v4/commands/httputil"
+	"github.com/ystia/yorc/v4/rest"
+)
+
+func init() {
+	var req rest.WorkflowScheduleRequest
+	var inputs map[string]string
+	var schedUpdateCmd = &cobra.Command{
+		Use:   "update <DeploymentId> <ScheduleId>",
+		Short: "Update a workflow schedule",
+		Long: `Update a workflow schedule of a given deployment.
+Only provided flags are updated, if inputs are provided they replace all existing inputs.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 2 {
+				return errors.Errorf("Expecting a deployment id and a schedule id (got %d parameters)", len(args))
+			}
+			client, err := httputil.GetClient(deployments.ClientConfig)
+			if err != nil {
+				return err
+			}
+			changes := func(current *rest.WorkflowScheduleRequest) {
+				flags := cmd.Flags()
+				if flags.Changed("workflow-name") {
+					current.WorkflowName = req.WorkflowName
+				}
+				if flags.Changed("cron") {
+					current.Cron = req.Cron
+				}
+				if flags.Changed("time-zone") {
+					current.TimeZone = req.TimeZone
+				}
+				if flags.Changed("continue-on-error") {
+					current.ContinueOnError = req.ContinueOnError
+				}
+				if flags.Changed("input") {
+					current.Inputs = toRequestInputs(inputs)
+				}
+			}
+			return updateSchedule(client, args[0], args[1], changes)
+		},
+	}
+	addScheduleDefinitionFlags(schedUpdateCmd, &req, &inputs)
+	schedulesCmd.AddCommand(schedUpdateCmd)
+}
+
+func updateSchedule(client httputil.HTTPClient, deploymentID, scheduleID string, changes func(*rest.WorkflowScheduleRequest)) error {
+	schedulePath := path.Join("/deployments", deploymentID, "schedules", scheduleID)
+	ids := deploymentID + "/" + scheduleID
+	request, err := client.NewRequest("GET", schedulePath, nil)
+	if err != nil {
+		return err
+	}
+	request.Header.Add("Accept", "application/json")
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	httputil.HandleHTTPStatusCode(response, ids, "deployment/schedule", http.StatusOK)
+	var current rest.WorkflowSchedule
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return err
+	}
+	err = json.Unmarshal(body, &current)
+	if err != nil {
+		return err
+	}
+
+	req := rest.WorkflowScheduleRequest{
+		WorkflowName:    current.WorkflowName,
+		Cron:            current.Cron,
+		TimeZone:        current.TimeZone,
+		ContinueOnError: current.ContinueOnError,
+	}
+	if len(current.Inputs) > 0 {
+		req.Inputs = make(map[string]interface{}, len(current.Inputs))
+		for k, v := range current.Inputs {
+			req.Inputs[k] = v
+		}
+	}
+	changes(&req)
+
+	b, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	request, err = client.NewRequest("PUT", schedulePath, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	request.Header.Add("Content-Type", "application/json")
+	updateResponse, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer updateResponse.Body.Close()
+	httputil.HandleHTTPStatusCode(updateResponse, ids, "deployment/schedule", http.StatusOK)
+	fmt.Printf("Schedule %s updated\n", scheduleID)
+	return nil
+}
@@ -0,0 +1,147 @@
+// Copyright 2021 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schedules
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ystia/yorc/v4/prov/scheduling"
+	"github.com/ystia/yorc/v4/rest"
+)
+
+type httpClientMockSchedules struct {
+	requests []*http.Request
+	bodies   []string
+	fails    bool
+}
+
+func (c *httpClientMockSchedules) Do(req *http.Request) (*http.Response, error) {
+	if c.fails {
+		return nil, errors.New("a failure occurs")
+	}
+	c.requests = append(c.requests, req)
+	body := ""
+	if req.Body != nil {
+		b, _ := ioutil.ReadAll(req.Body)
+		body = string(b)
+	}
+	c.bodies = append(c.bodies, body)
+
+	w := httptest.NewRecorder()
+	switch req.Method {
+	case http.MethodPost:
+		w.Header().Set("Location", "/deployments/dep/schedules/sched1")
+		w.WriteHeader(http.StatusCreated)
+	case http.MethodGet:
+		next := time.Date(2021, time.June, 16, 2, 0, 0, 0, time.UTC)
+		schedule := rest.WorkflowSchedule{
+			WorkflowSchedule: scheduling.WorkflowSchedule{
+				ID:           "sched1",
+				DeploymentID: "dep",
+				WorkflowName: "backup",
+				Cron:         "0 2 * * *",
+				Inputs:       map[string]string{"retention": "7"},
+				LastTaskID:   "task1",
+			},
+			NextRun: &next,
+		}
+		var v interface{} = schedule
+		if req.URL.Path == "/deployments/dep/schedules" {
+			v = rest.WorkflowSchedulesCollection{Schedules: []rest.WorkflowSchedule{schedule}}
+		}
+		b, _ := json.Marshal(v)
+		w.Write(b)
+	default:
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.Result(), nil
+}
+
+func (c *httpClientMockSchedules) NewRequest(method, path string, body io.Reader) (*http.Request, error) {
+	return http.NewRequest(method, path, body)
+}
+
+func (c *httpClientMockSchedules) Get(path string) (*http.Response, error) {
+	return &http.Response{}, nil
+}
+
+func (c *httpClientMockSchedules) Head(path string) (*http.Response, error) {
+	return &http.Response{}, nil
+}
+
+func (c *httpClientMockSchedules) Post(path string, contentType string, body io.Reader) (*http.Response, error) {
+	return &http.Response{}, nil
+}
+
+func (c *httpClientMockSchedules) PostForm(path string, data url.Values) (*http.Response, error) {
+	return &http.Response{}, nil
+}
+
+func TestListSchedules(t *testing.T) {
+	client := &httpClientMockSchedules{}
+	err := listSchedules(client, "dep")
+	require.NoError(t, err)
+	require.Len(t, client.requests, 1)
+	assert.Equal(t, "/deployments/dep/schedules", client.requests[0].URL.Path)
+
+	err = listSchedules(&httpClientMockSchedules{fails: true}, "dep")
+	require.Error(t, err)
+}
+
+func TestAddSchedule(t *testing.T) {
+	err := addSchedule(&httpClientMockSchedules{}, "dep", rest.WorkflowScheduleRequest{Cron: "@daily"})
+	require.Error(t, err, "workflow name is mandatory")
+	err = addSchedule(&httpClientMockSchedules{}, "dep", rest.WorkflowScheduleRequest{WorkflowName: "backup"})
+	require.Error(t, err, "cron is mandatory")
+
+	client := &httpClientMockSchedules{}
+	err = addSchedule(client, "dep", rest.WorkflowScheduleRequest{WorkflowName: "backup", Cron: "@daily", TimeZone: "Europe/Paris", Inputs: toRequestInputs(map[string]string{"retention": "7"})})
+	require.NoError(t, err)
+	require.Len(t, client.requests, 1)
+	assert.Equal(t, http.MethodPost, client.requests[0].Method)
+	assert.JSONEq(t, `{"workflow_name":"backup","cron":"@daily","time_zone":"Europe/Paris","inputs":{"retention":"7"}}`, client.bodies[0])
+}
+
+func TestUpdateSchedule(t *testing.T) {
+	client := &httpClientMockSchedules{}
+	err := updateSchedule(client, "dep", "sched1", func(req *rest.WorkflowScheduleRequest) {
+		req.Cron = "30 3 * * *"
+	})
+	require.NoError(t, err)
+	require.Len(t, client.requests, 2)
+	assert.Equal(t, http.MethodPut, client.requests[1].Method)
+	assert.Equal(t, "/deployments/dep/schedules/sched1", client.requests[1].URL.Path)
+	assert.JSONEq(t, `{"workflow_name":"backup","cron":"30 3 * * *","inputs":{"retention":"7"}}`, client.bodies[1])
+}
+
+func TestDeleteSchedule(t *testing.T) {
+	client := &httpClientMockSchedules{}
+	err := deleteSchedule(client, "dep", "sched1")
+	require.NoError(t, err)
+	require.Len(t, client.requests, 1)
+	assert.Equal(t, http.MethodDelete, client.requests[0].Method)
+	assert.Equal(t, "/deployments/dep/schedules/sched1", client.requests[0].URL.Path)
+}
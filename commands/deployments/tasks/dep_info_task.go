@@ -21,6 +21,7 @@ import (
 	"net/http"
 	"os"
 	"path"
+	"strconv"
 	"strings"
 
 	"github.com/fatih/color"
@@ -97,11 +98,19 @@ func taskInfo(client httputil.HTTPClient, args []string, withSteps bool) error {
 	if task.ErrorMessage != "" {
 		fmt.Println("Task Error Message:", task.ErrorMessage)
 	}
+	if task.RollbackOf != "" {
+		fmt.Println("Rollback of task:", task.RollbackOf)
+	}
 
 	if withSteps {
 		displayStepTables(client, args)
 	}
 
+	if task.RollbackTaskID != "" {
+		fmt.Println()
+		fmt.Println("Rollback task:")
+		return taskInfo(client, []string{args[0], task.RollbackTaskID}, withSteps)
+	}
 	return nil
 }
 
@@ -135,10 +144,14 @@ func displayStepTables(client httputil.HTTPClient, args []string) {
 	}
 	fmt.Println("Steps:")
 	tasksTable := tabutil.NewTable()
-	tasksTable.AddHeaders("Name", "Status")
+	tasksTable.AddHeaders("Name", "Status", "Attempts")
 	errs := make([]error, 0)
 	for _, step := range steps {
-		tasksTable.AddRow(step.Name, getColoredTaskStepStatus(colorize, step.Status))
+		var attempts string
+		if step.Attempts > 0 {
+			attempts = strconv.Itoa(step.Attempts)
+		}
+		tasksTable.AddRow(step.Name, getColoredTaskStepStatus(colorize, step.Status), attempts)
 	}
 	fmt.Println(tasksTable.Render())
 	if len(errs) > 0 {
@@ -154,7 +167,7 @@ func getColoredTaskStepStatus(colorize bool, status string) string {
 		return status
 	}
 	switch strings.ToLower(status) {
-	case "error":
+	case "error", "timeout":
 		return color.New(color.FgHiRed, color.Bold).SprintFunc()(status)
 	case "canceled", "running":
 		return color.New(color.FgHiYellow, color.Bold).SprintFunc()(status)
@@ -64,6 +64,14 @@ func (c *httpClientMockInfo) Do(req *http.Request) (*http.Response, error) {
 		ErrorMessage: "my error message",
 		ResultSet:    nil,
 	}
+	if strings.Contains(req.URL.String(), "with_rollback") {
+		if strings.HasSuffix(req.URL.String(), "rollbackTaskID") {
+			task.ID = "rollbackTaskID"
+			task.RollbackOf = "taskID"
+		} else {
+			task.RollbackTaskID = "rollbackTaskID"
+		}
+	}
 	b, err := json.Marshal(task)
 	if err != nil {
 		return nil, errors.New("failed to build http client mock response")
@@ -128,3 +136,8 @@ func TestHostInfoWithJSONError(t *testing.T) {
 	err := taskInfo(&httpClientMockInfo{}, []string{"bad_json", "taskID"}, false)
 	require.Error(t, err, "Expected error due to JSON error")
 }
+
+func TestTaskInfoWithRollback(t *testing.T) {
+	err := taskInfo(&httpClientMockInfo{}, []string{"with_rollback", "taskID"}, true)
+	require.NoError(t, err, "Failed to get task info")
+}
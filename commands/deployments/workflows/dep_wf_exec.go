@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"net/http"
 	"path"
+	"strings"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
@@ -33,6 +34,9 @@ func init() {
 	var continueOnError bool
 	var workflowName string
 	var jsonParam string
+	var dryRun bool
+	var priority string
+	var rollbackOnFailure bool
 	var wfExecCmd = &cobra.Command{
 		Use:     "execute <id>",
 		Short:   "Trigger a custom workflow on deployment <id>",
@@ -48,9 +52,25 @@ func init() {
 			if workflowName == "" {
 				return errors.New("Missing mandatory \"workflow-name\" parameter")
 			}
+			if dryRun && (shouldStreamLogs || shouldStreamEvents) {
+				return errors.Errorf("You can't provide stream-events or stream-logs flags in dry-run mode")
+			}
 			url := fmt.Sprintf("/deployments/%s/workflows/%s", args[0], workflowName)
+			var query []string
 			if continueOnError {
-				url = url + "?continueOnError"
+				query = append(query, "continueOnError")
+			}
+			if dryRun {
+				query = append(query, "dryRun=true")
+			}
+			if priority != "" {
+				query = append(query, "priority="+priority)
+			}
+			if rollbackOnFailure {
+				query = append(query, "rollbackOnFailure=true")
+			}
+			if len(query) > 0 {
+				url = url + "?" + strings.Join(query, "&")
 			}
 			var request *http.Request
 			if len(jsonParam) == 0 {
@@ -68,6 +88,15 @@ func init() {
 			}
 			defer response.Body.Close()
 			ids := args[0] + "/" + workflowName
+			if dryRun {
+				httputil.HandleHTTPStatusCode(response, ids, "deployment/workflow", http.StatusOK)
+				plan, err := deployments.DecodePlan(response.Body)
+				if err != nil {
+					httputil.ErrExit(err)
+				}
+				deployments.PrintPlan(plan, !deployments.NoColor)
+				return nil
+			}
 			httputil.HandleHTTPStatusCode(response, ids, "deployment/workflow", http.StatusAccepted, http.StatusCreated)
 
 			fmt.Println("New task ", path.Base(response.Header.Get("Location")), " created to execute ", workflowName)
@@ -86,5 +115,8 @@ func init() {
 	wfExecCmd.PersistentFlags().StringVarP(&jsonParam, "data", "d", "", "Provide the JSON format for the node instances selection")
 	wfExecCmd.PersistentFlags().BoolVarP(&shouldStreamLogs, "stream-logs", "l", false, "Stream logs after triggering a workflow. In this mode logs can't be filtered, to use this feature see the \"log\" command.")
 	wfExecCmd.PersistentFlags().BoolVarP(&shouldStreamEvents, "stream-events", "e", false, "Stream events after triggering a workflow.")
+	wfExecCmd.PersistentFlags().BoolVarP(&dryRun, "dry-run", "", false, "Print the execution plan of the workflow instead of executing it. Nothing is executed in this mode.")
+	wfExecCmd.PersistentFlags().StringVarP(&priority, "priority", "", "", "Priority of the workflow task (low, normal or high), by default the priority depends on the task type.")
+	wfExecCmd.PersistentFlags().BoolVarP(&rollbackOnFailure, "rollback-on-failure", "", false, "If the workflow fails, automatically run a rollback task reverting the completed steps.")
 	workflowsCmd.AddCommand(wfExecCmd)
 }
@@ -36,6 +36,7 @@ func init() {
 	var password string
 	var user string
 	var host string
+	var hostKey string
 	var port uint64
 	var labels []string
 
@@ -48,7 +49,7 @@ func init() {
 			if err != nil {
 				return err
 			}
-			return addHost(client, args, location, jsonParam, privateKey, password, user, host, port, labels)
+			return addHost(client, args, location, jsonParam, privateKey, password, user, host, hostKey, port, labels)
 		},
 	}
 	addCmd.Flags().StringVarP(&location, "location", "l", "", "Need to provide the specified hosts pool location name")
@@ -56,6 +57,7 @@ func init() {
 	addCmd.Flags().StringVarP(&user, "user", "", "root", "User used to connect to the host")
 	addCmd.Flags().StringVarP(&host, "host", "", "", "Hostname or ip address used to connect to the host. (defaults to the hostname in the hosts pool)")
 	addCmd.Flags().Uint64VarP(&port, "port", "", 22, "Port used to connect to the host.")
+	addCmd.Flags().StringVarP(&hostKey, "host-key", "", "", "Expected SSH host key of the host in the authorized_keys format (ex: \"ssh-ed25519 AAAA...\"). If not set host key is checked according to the Yorc server configuration.")
 	addCmd.Flags().StringVarP(&privateKey, "key", "k", "", "Need to provide a private key or a password for the host pool")
 	addCmd.Flags().StringVarP(&password, "password", "p", "", "Need to provide a private key or a password for the host pool")
 	addCmd.Flags().StringSliceVarP(&labels, "label", "", nil, "Label in form 'key=value' to add to the host. May be specified several time.")
@@ -63,7 +65,7 @@ func init() {
 	hostsPoolCmd.AddCommand(addCmd)
 }
 
-func addHost(client httputil.HTTPClient, args []string, location, jsonParam, privateKey, password, user, host string, port uint64, labels []string) error {
+func addHost(client httputil.HTTPClient, args []string, location, jsonParam, privateKey, password, user, host, hostKey string, port uint64, labels []string) error {
 	if len(args) != 1 {
 		return errors.Errorf("Expecting a hostname (got %d parameters)", len(args))
 	}
@@ -81,6 +83,7 @@ func addHost(client httputil.HTTPClient, args []string, location, jsonParam, pri
 			Port:       port,
 			Password:   password,
 			PrivateKey: privateKey,
+			HostKey:    hostKey,
 		}
 		for _, l := range labels {
 			parts := strings.SplitN(l, "=", 2)
@@ -59,26 +59,26 @@ func (c *httpClientMockAdd) PostForm(path string, data url.Values) (*http.Respon
 }
 
 func TestAddHost(t *testing.T) {
-	err := addHost(&httpClientMockAdd{}, []string{"hostOne"}, "locationOne", "", "", "pass", "userOne", "1.2.3.1", 22, []string{"label1=value1", "label2=value2", "label3=value3"})
+	err := addHost(&httpClientMockAdd{}, []string{"hostOne"}, "locationOne", "", "", "pass", "userOne", "1.2.3.1", "", 22, []string{"label1=value1", "label2=value2", "label3=value3"})
 	require.NoError(t, err, "Failed to add host")
 }
 
 func TestAddHostWithoutHostname(t *testing.T) {
-	err := addHost(&httpClientMockAdd{}, []string{}, "locationOne", "", "", "pass", "userOne", "1.2.3.1", 22, []string{"label1=value1", "label2=value2", "label3=value3"})
+	err := addHost(&httpClientMockAdd{}, []string{}, "locationOne", "", "", "pass", "userOne", "1.2.3.1", "", 22, []string{"label1=value1", "label2=value2", "label3=value3"})
 	require.Error(t, err, "Expected error as no hostname has been provided")
 }
 
 func TestAddHostWithoutLocation(t *testing.T) {
-	err := addHost(&httpClientMockAdd{}, []string{"hostOne"}, "", "", "", "pass", "userOne", "1.2.3.1", 22, []string{"label1=value1", "label2=value2", "label3=value3"})
+	err := addHost(&httpClientMockAdd{}, []string{"hostOne"}, "", "", "", "pass", "userOne", "1.2.3.1", "", 22, []string{"label1=value1", "label2=value2", "label3=value3"})
 	require.Error(t, err, "Expected error as no location has been provided")
 }
 
 func TestAddHostWithoutPassword(t *testing.T) {
-	err := addHost(&httpClientMockAdd{}, []string{"hostOne"}, "locationOne", "", "", "", "userOne", "1.2.3.1", 22, []string{"label1=value1", "label2=value2", "label3=value3"})
+	err := addHost(&httpClientMockAdd{}, []string{"hostOne"}, "locationOne", "", "", "", "userOne", "1.2.3.1", "", 22, []string{"label1=value1", "label2=value2", "label3=value3"})
 	require.Error(t, err, "Expected error as no location has been provided")
 }
 
 func TestAddHostWithHTTPFailure(t *testing.T) {
-	err := addHost(&httpClientMockAdd{}, []string{"hostOne"}, "fails", "", "", "", "userOne", "1.2.3.1", 22, []string{"label1=value1", "label2=value2", "label3=value3"})
+	err := addHost(&httpClientMockAdd{}, []string{"hostOne"}, "fails", "", "", "", "userOne", "1.2.3.1", "", 22, []string{"label1=value1", "label2=value2", "label3=value3"})
 	require.Error(t, err, "Expected HTTP error")
 }
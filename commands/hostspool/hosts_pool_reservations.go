@@ -0,0 +1,229 @@
+// Copyright 2021 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hostspool
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/ystia/yorc/v4/commands/httputil"
+	"github.com/ystia/yorc/v4/helper/tabutil"
+	"github.com/ystia/yorc/v4/rest"
+)
+
+func init() {
+	var location string
+	var owner string
+	var hosts []string
+	var count int
+	var filters []string
+	var end string
+
+	var reservationsCmd = &cobra.Command{
+		Use:     "reservations",
+		Aliases: []string{"reservation", "res"},
+		Short:   "Perform commands on hosts pool reservations",
+		Long: `Allow to list, add and delete reservations of hosts of a specified location.
+Reserved hosts are held for an owner until the reservation expiry, they could only be allocated by nodes referencing the reservation.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			err := cmd.Help()
+			if err != nil {
+				fmt.Print(err)
+			}
+		},
+	}
+	reservationsCmd.PersistentFlags().StringVarP(&location, "location", "l", "", "Need to provide the specified hosts pool location name")
+
+	var listCmd = &cobra.Command{
+		Use:   "list -l <locationName>",
+		Short: "List reservations of a hosts pool location",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := httputil.GetClient(clientConfig)
+			if err != nil {
+				return err
+			}
+			return listReservations(client, location)
+		},
+	}
+
+	var addCmd = &cobra.Command{
+		Use:   "add -l <locationName> <reservationName>",
+		Short: "Reserve hosts of a hosts pool location",
+		Long: `Reserves hosts of the hosts pool of a specified location for an owner until the given end.
+Hosts to reserve are either explicitly provided, or the given number of free hosts matching filters are reserved.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := httputil.GetClient(clientConfig)
+			if err != nil {
+				return err
+			}
+			req, err := getReservationRequest(owner, hosts, count, filters, end)
+			if err != nil {
+				return err
+			}
+			return addReservation(client, args, location, req)
+		},
+	}
+	addCmd.Flags().StringVarP(&owner, "owner", "o", "", "Owner of the reservation")
+	addCmd.Flags().StringSliceVarP(&hosts, "host", "", nil, "Name of a host to reserve. May be specified several time.")
+	addCmd.Flags().IntVarP(&count, "count", "c", 0, "Number of free hosts to reserve, exclusive with the host flag.")
+	addCmd.Flags().StringSliceVarP(&filters, "filter", "f", nil, "Filter on hosts labels used with the count flag. May be specified several time.")
+	addCmd.Flags().StringVarP(&end, "end", "e", "", `Date (RFC3339 format, ex: "2021-06-01T18:00:00Z") or duration (ex: "48h") after which the reservation expires.`)
+
+	var deleteCmd = &cobra.Command{
+		Use:   "delete -l <locationName> <reservationName> [reservationName]...",
+		Short: "Delete reservations of a hosts pool location",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := httputil.GetClient(clientConfig)
+			if err != nil {
+				return err
+			}
+			return deleteReservations(client, args, location)
+		},
+	}
+
+	reservationsCmd.AddCommand(listCmd, addCmd, deleteCmd)
+	hostsPoolCmd.AddCommand(reservationsCmd)
+}
+
+func getReservationRequest(owner string, hosts []string, count int, filters []string, end string) (*rest.ReservationRequest, error) {
+	if owner == "" {
+		return nil, errors.New("Expecting a reservation owner")
+	}
+	if (len(hosts) == 0) == (count <= 0) {
+		return nil, errors.New(`Expecting either hosts or a number of hosts to reserve`)
+	}
+	if len(filters) > 0 && count <= 0 {
+		return nil, errors.New(`"filter" flag requires the "count" flag`)
+	}
+	if end == "" {
+		return nil, errors.New("Expecting a reservation end")
+	}
+	req := &rest.ReservationRequest{Owner: owner, Hosts: hosts, Count: count, Filters: filters}
+	expiry, err := time.Parse(time.RFC3339, end)
+	if err != nil {
+		if _, errDuration := time.ParseDuration(end); errDuration != nil {
+			return nil, errors.Errorf("invalid reservation end %q, expecting a RFC3339 date or a duration", end)
+		}
+		req.Duration = end
+	} else {
+		req.Expiry = &expiry
+	}
+	return req, nil
+}
+
+func listReservations(client httputil.HTTPClient, location string) error {
+	if location == "" {
+		return errors.Errorf("Expecting a hosts pool location name")
+	}
+	request, err := client.NewRequest("GET", "/hosts_pool/"+location+"/reservations", nil)
+	if err != nil {
+		return err
+	}
+	request.Header.Add("Accept", "application/json")
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	httputil.HandleHTTPStatusCode(response, location, "hosts pool reservations", http.StatusOK)
+
+	var coll rest.ReservationsCollection
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return err
+	}
+	err = json.Unmarshal(body, &coll)
+	if err != nil {
+		return err
+	}
+
+	reservationsTable := tabutil.NewTable()
+	reservationsTable.AddHeaders("Name", "Owner", "Hosts", "Expiry")
+	for _, res := range coll.Reservations {
+		reservationsTable.AddRow(res.Name, res.Owner, strings.Join(res.Hosts, ","), res.Expiry.Format(time.RFC3339))
+	}
+	fmt.Printf("Hosts pool reservations:\n")
+	fmt.Println(reservationsTable.Render())
+	return nil
+}
+
+func addReservation(client httputil.HTTPClient, args []string, location string, req *rest.ReservationRequest) error {
+	if len(args) != 1 {
+		return errors.Errorf("Expecting a reservation name (got %d parameters)", len(args))
+	}
+	if location == "" {
+		return errors.Errorf("Expecting a hosts pool location name")
+	}
+	data, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	request, err := client.NewRequest("PUT", "/hosts_pool/"+location+"/reservations/"+args[0], bytes.NewBuffer(data))
+	if err != nil {
+		return err
+	}
+	request.Header.Add("Content-Type", "application/json")
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	httputil.HandleHTTPStatusCode(response, args[0], "hosts pool reservation", http.StatusCreated)
+
+	var res rest.Reservation
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return err
+	}
+	err = json.Unmarshal(body, &res)
+	if err != nil {
+		return err
+	}
+	for _, warn := range res.Warnings {
+		fmt.Println("Warning :", warn)
+	}
+	fmt.Printf("Reserved hosts %s until %s\n", strings.Join(res.Hosts, ","), res.Expiry.Format(time.RFC3339))
+	return nil
+}
+
+func deleteReservations(client httputil.HTTPClient, args []string, location string) error {
+	if len(args) == 0 {
+		return errors.New("Expecting at least one reservation name")
+	}
+	if location == "" {
+		return errors.Errorf("Expecting a hosts pool location name")
+	}
+	for _, name := range args {
+		request, err := client.NewRequest("DELETE", "/hosts_pool/"+location+"/reservations/"+name, nil)
+		if err != nil {
+			return err
+		}
+		response, err := client.Do(request)
+		if err != nil {
+			return err
+		}
+		httputil.HandleHTTPStatusCode(response, name, "hosts pool reservation", http.StatusOK)
+		response.Body.Close()
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+// Copyright 2021 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hostspool
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ystia/yorc/v4/prov/hostspool"
+	"github.com/ystia/yorc/v4/rest"
+)
+
+type httpClientMockReservations struct {
+	testID string
+}
+
+func (c *httpClientMockReservations) Do(req *http.Request) (*http.Response, error) {
+	if strings.Contains(c.testID, "fails") {
+		return nil, errors.New("a failure occurs")
+	}
+
+	w := httptest.NewRecorder()
+	res := rest.Reservation{Reservation: hostspool.Reservation{Name: "training", Owner: "team1", Hosts: []string{"hostOne", "hostTwo"}, Expiry: time.Now().Add(time.Hour)}}
+	var b []byte
+	var err error
+	switch req.Method {
+	case http.MethodPut:
+		w.WriteHeader(http.StatusCreated)
+		b, err = json.Marshal(res)
+	case http.MethodGet:
+		b, err = json.Marshal(rest.ReservationsCollection{Reservations: []rest.Reservation{res}})
+	}
+	if err != nil {
+		return nil, errors.New("failed to build http client mock response")
+	}
+	if strings.Contains(c.testID, "bad_json") {
+		w.WriteString("This is not json !!!")
+	} else {
+		w.Write(b)
+	}
+	return w.Result(), nil
+}
+
+func (c *httpClientMockReservations) NewRequest(method, path string, body io.Reader) (*http.Request, error) {
+	return http.NewRequest(method, path, body)
+}
+
+func (c *httpClientMockReservations) Get(path string) (*http.Response, error) {
+	return &http.Response{}, nil
+}
+
+func (c *httpClientMockReservations) Head(path string) (*http.Response, error) {
+	return &http.Response{}, nil
+}
+
+func (c *httpClientMockReservations) Post(path string, contentType string, body io.Reader) (*http.Response, error) {
+	return &http.Response{}, nil
+}
+
+func (c *httpClientMockReservations) PostForm(path string, data url.Values) (*http.Response, error) {
+	return &http.Response{}, nil
+}
+
+func TestListReservations(t *testing.T) {
+	err := listReservations(&httpClientMockReservations{}, "locationOne")
+	require.NoError(t, err, "Failed to list reservations")
+	err = listReservations(&httpClientMockReservations{}, "")
+	require.Error(t, err, "Expected error as no location has been provided")
+	err = listReservations(&httpClientMockReservations{testID: "bad_json"}, "locationOne")
+	require.Error(t, err, "Expected error due to JSON error")
+}
+
+func TestAddReservation(t *testing.T) {
+	req, err := getReservationRequest("team1", nil, 2, []string{"rack=r1"}, "48h")
+	require.NoError(t, err)
+	err = addReservation(&httpClientMockReservations{}, []string{"training"}, "locationOne", req)
+	require.NoError(t, err, "Failed to add reservation")
+	err = addReservation(&httpClientMockReservations{}, []string{}, "locationOne", req)
+	require.Error(t, err, "Expected error as no reservation name has been provided")
+	err = addReservation(&httpClientMockReservations{testID: "fails"}, []string{"training"}, "locationOne", req)
+	require.Error(t, err, "Expected error due to HTTP failure")
+}
+
+func TestDeleteReservations(t *testing.T) {
+	err := deleteReservations(&httpClientMockReservations{}, []string{"training"}, "locationOne")
+	require.NoError(t, err, "Failed to delete reservation")
+	err = deleteReservations(&httpClientMockReservations{}, []string{}, "locationOne")
+	require.Error(t, err, "Expected error as no reservation name has been provided")
+}
+
+func TestGetReservationRequest(t *testing.T) {
+	req, err := getReservationRequest("team1", []string{"hostOne"}, 0, nil, "2021-06-01T18:00:00Z")
+	require.NoError(t, err)
+	require.NotNil(t, req.Expiry)
+	require.Equal(t, time.Date(2021, 6, 1, 18, 0, 0, 0, time.UTC), req.Expiry.UTC())
+	require.Empty(t, req.Duration)
+
+	req, err = getReservationRequest("team1", nil, 3, nil, "2h")
+	require.NoError(t, err)
+	require.Nil(t, req.Expiry)
+	require.Equal(t, "2h", req.Duration)
+	require.Equal(t, 3, req.Count)
+
+	_, err = getReservationRequest("", []string{"hostOne"}, 0, nil, "2h")
+	require.Error(t, err, "Expected error as no owner has been provided")
+	_, err = getReservationRequest("team1", []string{"hostOne"}, 2, nil, "2h")
+	require.Error(t, err, "Expected error as both hosts and count have been provided")
+	_, err = getReservationRequest("team1", []string{"hostOne"}, 0, []string{"rack=r1"}, "2h")
+	require.Error(t, err, "Expected error as filters are only allowed with count")
+	_, err = getReservationRequest("team1", []string{"hostOne"}, 0, nil, "tomorrow")
+	require.Error(t, err, "Expected error as end is invalid")
+}
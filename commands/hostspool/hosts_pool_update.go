@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
@@ -36,20 +37,43 @@ func init() {
 	var password string
 	var user string
 	var host string
+	var hostKey string
 	var port uint64
 	var labelsAdd []string
 	var labelsRemove []string
+	var maintenance bool
+	var maintenanceReason string
+	var maintenanceEnd string
+	var renewLease string
+	var allocation string
 
 	var updCmd = &cobra.Command{
 		Use:   "update -l <locationName> <hostname>",
 		Short: "Update host pool of a specified location",
-		Long:  `Update labels list or connection of a host of the hosts pool of a specified location managed by this Yorc cluster.`,
+		Long: `Update labels list or connection of a host of the hosts pool of a specified location managed by this Yorc cluster.
+Hosts could also be put in maintenance, in this case they are not considered for new allocations but keep their existing ones.
+Leases of allocations of a host could be renewed, either for a given allocation or for all of them.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			client, err := httputil.GetClient(clientConfig)
 			if err != nil {
 				return err
 			}
-			return updateHost(client, args, location, jsonParam, privateKey, password, user, host, port, labelsAdd, labelsRemove)
+			var hostMaintenance *rest.HostMaintenance
+			if cmd.Flags().Changed("maintenance") {
+				hostMaintenance, err = getHostMaintenance(maintenance, maintenanceReason, maintenanceEnd)
+				if err != nil {
+					return err
+				}
+			} else if maintenanceReason != "" || maintenanceEnd != "" {
+				return errors.New(`"maintenance-reason" and "maintenance-end" flags require the "maintenance" flag`)
+			}
+			var lease *rest.AllocationLease
+			if renewLease != "" {
+				lease = &rest.AllocationLease{Allocation: allocation, Duration: renewLease}
+			} else if allocation != "" {
+				return errors.New(`"allocation" flag requires the "renew-lease" flag`)
+			}
+			return updateHost(client, args, location, jsonParam, privateKey, password, user, host, hostKey, port, labelsAdd, labelsRemove, hostMaintenance, lease)
 		},
 	}
 	updCmd.Flags().StringVarP(&location, "location", "l", "", "Need to provide the specified hosts pool location name")
@@ -57,15 +81,21 @@ func init() {
 	updCmd.Flags().StringVarP(&user, "user", "", "", "User used to connect to the host")
 	updCmd.Flags().StringVarP(&host, "host", "", "", "Hostname or ip address used to connect to the host. (defaults to the hostname in the hosts pool)")
 	updCmd.Flags().Uint64VarP(&port, "port", "", 0, "Port used to connect to the host.")
+	updCmd.Flags().StringVarP(&hostKey, "host-key", "", "", `Expected SSH host key of the host in the authorized_keys format (ex: "ssh-ed25519 AAAA..."). To delete a registered host key use the "-" character.`)
 	updCmd.Flags().StringVarP(&privateKey, "key", "k", "", `At any time a host of the pool should have at least one of private key or password. To delete a registered password use the "-" character.`)
 	updCmd.Flags().StringVarP(&password, "password", "p", "", `At any time a host of the pool should have at least one of private key or password. To delete a registered private key use the "-" character.`)
 	updCmd.Flags().StringSliceVarP(&labelsAdd, "add-label", "", nil, "Add a label in form 'key=value' to the host. May be specified several time.")
 	updCmd.Flags().StringSliceVarP(&labelsRemove, "remove-label", "", nil, "Remove a label from the host. May be specified several time.")
+	updCmd.Flags().BoolVarP(&maintenance, "maintenance", "", false, `Put the host in maintenance, no new allocations will be done on it. Use "--maintenance=false" to return it to service.`)
+	updCmd.Flags().StringVarP(&maintenanceReason, "maintenance-reason", "", "", "Reason of the maintenance.")
+	updCmd.Flags().StringVarP(&maintenanceEnd, "maintenance-end", "", "", `Optional date (RFC3339 format, ex: "2021-06-01T18:00:00Z") or duration (ex: "2h30m") after which the host automatically returns to service.`)
+	updCmd.Flags().StringVarP(&renewLease, "renew-lease", "", "", `Renew the lease of allocations of the host for the given duration from now (ex: "12h").`)
+	updCmd.Flags().StringVarP(&allocation, "allocation", "", "", "ID of the allocation whose lease is renewed, all allocations of the host if not set.")
 
 	hostsPoolCmd.AddCommand(updCmd)
 }
 
-func updateHost(client httputil.HTTPClient, args []string, location, jsonParam, privateKey, password, user, host string, port uint64, labelsAdd, labelsRemove []string) error {
+func updateHost(client httputil.HTTPClient, args []string, location, jsonParam, privateKey, password, user, host, hostKey string, port uint64, labelsAdd, labelsRemove []string, maintenance *rest.HostMaintenance, lease *rest.AllocationLease) error {
 	if len(args) != 1 {
 		return errors.Errorf("Expecting a hostname (got %d parameters)", len(args))
 	}
@@ -80,6 +110,7 @@ func updateHost(client httputil.HTTPClient, args []string, location, jsonParam,
 			Port:       port,
 			Password:   password,
 			PrivateKey: privateKey,
+			HostKey:    hostKey,
 		}
 		for _, l := range labelsAdd {
 			parts := strings.SplitN(l, "=", 2)
@@ -92,6 +123,8 @@ func updateHost(client httputil.HTTPClient, args []string, location, jsonParam,
 		for _, l := range labelsRemove {
 			hostRequest.Labels = append(hostRequest.Labels, rest.MapEntry{Op: rest.MapEntryOperationRemove, Name: l})
 		}
+		hostRequest.Maintenance = maintenance
+		hostRequest.Lease = lease
 		tmp, err := json.Marshal(hostRequest)
 		if err != nil {
 			return err
@@ -118,3 +151,26 @@ func updateHost(client httputil.HTTPClient, args []string, location, jsonParam,
 	}
 	return nil
 }
+
+func getHostMaintenance(enabled bool, reason, end string) (*rest.HostMaintenance, error) {
+	hostMaintenance := &rest.HostMaintenance{Enabled: enabled, Reason: reason}
+	if !enabled {
+		if reason != "" || end != "" {
+			return nil, errors.New(`"maintenance-reason" and "maintenance-end" flags can't be used to end a maintenance`)
+		}
+		return hostMaintenance, nil
+	}
+	if end == "" {
+		return hostMaintenance, nil
+	}
+	endTime, err := time.Parse(time.RFC3339, end)
+	if err != nil {
+		d, errDuration := time.ParseDuration(end)
+		if errDuration != nil {
+			return nil, errors.Errorf("invalid maintenance end %q, expecting a RFC3339 date or a duration", end)
+		}
+		endTime = time.Now().Add(d)
+	}
+	hostMaintenance.End = &endTime
+	return hostMaintenance, nil
+}
@@ -15,31 +15,73 @@
 package hostspool
 
 import (
-	"github.com/stretchr/testify/require"
 	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ystia/yorc/v4/rest"
 )
 
 func TestUpdateHost(t *testing.T) {
-	err := updateHost(&httpClientMockDelete{}, []string{"hostOne"}, "locationOne", "", "", "pass", "userOne", "1.2.3.1", 22, []string{"label1=value1", "label2=value2", "label3=value3"}, []string{"label4=value4"})
+	err := updateHost(&httpClientMockDelete{}, []string{"hostOne"}, "locationOne", "", "", "pass", "userOne", "1.2.3.1", "", 22, []string{"label1=value1", "label2=value2", "label3=value3"}, []string{"label4=value4"}, nil, nil)
 	require.NoError(t, err, "Failed to add host")
 }
 
 func TestUpdateHostWithoutHostname(t *testing.T) {
-	err := updateHost(&httpClientMockDelete{}, []string{}, "locationOne", "", "", "pass", "userOne", "1.2.3.1", 22, []string{"label1=value1", "label2=value2", "label3=value3"}, []string{"label4=value4"})
+	err := updateHost(&httpClientMockDelete{}, []string{}, "locationOne", "", "", "pass", "userOne", "1.2.3.1", "", 22, []string{"label1=value1", "label2=value2", "label3=value3"}, []string{"label4=value4"}, nil, nil)
 	require.Error(t, err, "Expected error as no hostname has been provided")
 }
 
 func TestUpdateHostWithoutLocation(t *testing.T) {
-	err := updateHost(&httpClientMockDelete{}, []string{"hostOne"}, "", "", "", "pass", "userOne", "1.2.3.1", 22, []string{"label1=value1", "label2=value2", "label3=value3"}, []string{"label4=value4"})
+	err := updateHost(&httpClientMockDelete{}, []string{"hostOne"}, "", "", "", "pass", "userOne", "1.2.3.1", "", 22, []string{"label1=value1", "label2=value2", "label3=value3"}, []string{"label4=value4"}, nil, nil)
 	require.Error(t, err, "Expected error as no location has been provided")
 }
 
 func TestUpdateHostWithHTTPFailure(t *testing.T) {
-	err := updateHost(&httpClientMockDelete{testID: "fails"}, []string{}, "locationOne", "", "", "pass", "userOne", "1.2.3.1", 22, []string{"label1=value1", "label2=value2", "label3=value3"}, []string{"label4=value4"})
+	err := updateHost(&httpClientMockDelete{testID: "fails"}, []string{}, "locationOne", "", "", "pass", "userOne", "1.2.3.1", "", 22, []string{"label1=value1", "label2=value2", "label3=value3"}, []string{"label4=value4"}, nil, nil)
 	require.Error(t, err, "Expected error due to HTTP failure")
 }
 
 func TestUpdateHostWithJSONError(t *testing.T) {
-	err := updateHost(&httpClientMockDelete{testID: "bad_json"}, []string{}, "locationOne", "", "", "pass", "userOne", "1.2.3.1", 22, []string{"label1=value1", "label2=value2", "label3=value3"}, []string{"label4=value4"})
+	err := updateHost(&httpClientMockDelete{testID: "bad_json"}, []string{}, "locationOne", "", "", "pass", "userOne", "1.2.3.1", "", 22, []string{"label1=value1", "label2=value2", "label3=value3"}, []string{"label4=value4"}, nil, nil)
 	require.Error(t, err, "Expected error due to JSON error")
 }
+
+func TestUpdateHostMaintenance(t *testing.T) {
+	hostMaintenance, err := getHostMaintenance(true, "kernel upgrade", "")
+	require.NoError(t, err)
+	err = updateHost(&httpClientMockDelete{}, []string{"hostOne"}, "locationOne", "", "", "", "", "", "", 0, nil, nil, hostMaintenance, nil)
+	require.NoError(t, err, "Failed to put host in maintenance")
+}
+
+func TestGetHostMaintenance(t *testing.T) {
+	hostMaintenance, err := getHostMaintenance(true, "kernel upgrade", "2021-06-01T18:00:00Z")
+	require.NoError(t, err)
+	require.True(t, hostMaintenance.Enabled)
+	require.Equal(t, "kernel upgrade", hostMaintenance.Reason)
+	require.NotNil(t, hostMaintenance.End)
+	require.Equal(t, time.Date(2021, 6, 1, 18, 0, 0, 0, time.UTC), hostMaintenance.End.UTC())
+
+	before := time.Now()
+	hostMaintenance, err = getHostMaintenance(true, "", "2h")
+	require.NoError(t, err)
+	require.NotNil(t, hostMaintenance.End)
+	require.True(t, hostMaintenance.End.After(before.Add(2*time.Hour-time.Second)))
+
+	hostMaintenance, err = getHostMaintenance(false, "", "")
+	require.NoError(t, err)
+	require.False(t, hostMaintenance.Enabled)
+	require.Nil(t, hostMaintenance.End)
+
+	_, err = getHostMaintenance(true, "", "tomorrow")
+	require.Error(t, err, "Expected error as end is neither a date nor a duration")
+
+	_, err = getHostMaintenance(false, "reason", "")
+	require.Error(t, err, "Expected error as reason is set when ending a maintenance")
+}
+
+func TestUpdateHostRenewLease(t *testing.T) {
+	err := updateHost(&httpClientMockDelete{}, []string{"hostOne"}, "locationOne", "", "", "", "", "", "", 0, nil, nil, nil, &rest.AllocationLease{Duration: "12h"})
+	require.NoError(t, err, "Failed to renew lease")
+}
@@ -78,6 +78,32 @@ func (c *YorcClient) PostForm(path string, data url.Values) (*http.Response, err
 	return c.Client.PostForm(c.baseURL+path, data)
 }
 
+// bearerTokenTransport adds a bearer token to requests sent to the Yorc REST API
+type bearerTokenTransport struct {
+	token string
+	next  http.RoundTripper
+}
+
+// RoundTrip implements the http.RoundTripper interface
+func (t *bearerTokenTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("Authorization") == "" {
+		// RoundTrippers should not modify the original request
+		req = req.Clone(req.Context())
+		req.Header.Set("Authorization", "Bearer "+t.token)
+	}
+	return t.next.RoundTrip(req)
+}
+
+func withAPIToken(tr http.RoundTripper, token string) http.RoundTripper {
+	if token == "" {
+		return tr
+	}
+	if tr == nil {
+		tr = http.DefaultTransport
+	}
+	return &bearerTokenTransport{token: token, next: tr}
+}
+
 // GetClient returns a yorc HTTP Client
 func GetClient(cc config.Client) (HTTPClient, error) {
 	yorcAPI := cc.YorcAPI
@@ -123,13 +149,13 @@ func GetClient(cc config.Client) (HTTPClient, error) {
 		}
 		return &YorcClient{
 			baseURL: "https://" + yorcAPI,
-			Client:  &http.Client{Transport: tr},
+			Client:  &http.Client{Transport: withAPIToken(tr, cc.APIToken)},
 		}, nil
 	}
 
 	return &YorcClient{
 		baseURL: "http://" + yorcAPI,
-		Client:  &http.Client{},
+		Client:  &http.Client{Transport: withAPIToken(nil, cc.APIToken)},
 	}, nil
 
 }
@@ -258,6 +258,9 @@ func setConfig() {
 	serverCmd.PersistentFlags().Duration("ssh_connection_timeout", config.DefaultSSHConnectionTimeout, "Timeout to establish SSH connection from Yorc SSH client. If not set the default value will be used")
 	serverCmd.PersistentFlags().Duration("ssh_connection_retry_backoff", config.DefaultSSHConnectionRetryBackoff, "Backoff duration before retrying an ssh connection. This may be superseded by a location attribute if supported.")
 	serverCmd.PersistentFlags().Uint64("ssh_connection_max_retries", config.DefaultSSHConnectionMaxRetries, "Maximum number of retries (attempts are retries + 1) before giving-up to connect. This may be superseded by a location attribute if supported.")
+	serverCmd.PersistentFlags().String("ssh_known_hosts_file", "", "Path to a known_hosts file used to verify SSH host keys of remote hosts")
+	serverCmd.PersistentFlags().String("ssh_host_key_checking", config.DefaultSSHHostKeyChecking, "Mode used to verify SSH host keys: strict, tofu (trust on first use) or insecure")
+	serverCmd.PersistentFlags().String("ssh_vault_signing_path", "", "Vault path used to sign SSH keys and get a fresh OpenSSH certificate before connecting to hosts. This may be superseded by a location attribute or credentials if supported.")
 
 	serverCmd.PersistentFlags().Duration("tasks_dispatcher_long_poll_wait_time", config.DefaultTasksDispatcherLongPollWaitTime, "Wait time when long polling for executions tasks to dispatch to workers")
 	serverCmd.PersistentFlags().Duration("tasks_dispatcher_lock_wait_time", config.DefaultTasksDispatcherLockWaitTime, "Wait time for acquiring a lock for an execution task")
@@ -326,6 +329,9 @@ func setConfig() {
 	viper.BindPFlag("ssh_connection_timeout", serverCmd.PersistentFlags().Lookup("ssh_connection_timeout"))
 	viper.BindPFlag("ssh_connection_retry_backoff", serverCmd.PersistentFlags().Lookup("ssh_connection_retry_backoff"))
 	viper.BindPFlag("ssh_connection_max_retries", serverCmd.PersistentFlags().Lookup("ssh_connection_max_retries"))
+	viper.BindPFlag("ssh_known_hosts_file", serverCmd.PersistentFlags().Lookup("ssh_known_hosts_file"))
+	viper.BindPFlag("ssh_host_key_checking", serverCmd.PersistentFlags().Lookup("ssh_host_key_checking"))
+	viper.BindPFlag("ssh_vault_signing_path", serverCmd.PersistentFlags().Lookup("ssh_vault_signing_path"))
 
 	viper.BindPFlag("tasks.dispatcher.long_poll_wait_time", serverCmd.PersistentFlags().Lookup("tasks_dispatcher_long_poll_wait_time"))
 	viper.BindPFlag("tasks.dispatcher.lock_wait_time", serverCmd.PersistentFlags().Lookup("tasks_dispatcher_lock_wait_time"))
@@ -372,6 +378,9 @@ func setConfig() {
 	viper.BindEnv("ssh_connection_timeout")
 	viper.BindEnv("ssh_connection_retry_backoff")
 	viper.BindEnv("ssh_connection_max_retries")
+	viper.BindEnv("ssh_known_hosts_file")
+	viper.BindEnv("ssh_host_key_checking")
+	viper.BindEnv("ssh_vault_signing_path")
 
 	//Bind Consul environment variables flags
 	for key := range consulConfiguration {
@@ -383,6 +392,23 @@ func setConfig() {
 	viper.BindEnv("tasks.dispatcher.long_poll_wait_time")
 	viper.BindEnv("tasks.dispatcher.lock_wait_time")
 	viper.BindEnv("tasks.dispatcher.metrics_refresh_time")
+	viper.BindEnv("tasks.steps_retry_policy.max_attempts")
+	viper.BindEnv("tasks.steps_retry_policy.initial_backoff")
+	viper.BindEnv("tasks.steps_retry_policy.max_backoff")
+	viper.BindEnv("tasks.steps_retry_policy.backoff_multiplier")
+	viper.BindEnv("tasks.steps_retry_policy.retryable_errors")
+	viper.BindEnv("tasks.operations_timeout")
+	viper.BindEnv("notifications.queue_size")
+	viper.BindEnv("notifications.workers")
+	viper.BindEnv("notifications.request_timeout")
+	viper.BindEnv("notifications.max_attempts")
+	viper.BindEnv("notifications.initial_backoff")
+	viper.BindEnv("notifications.max_backoff")
+	viper.BindEnv("hosts_pool.health_checks.interval")
+	viper.BindEnv("hosts_pool.health_checks.timeout")
+	viper.BindEnv("hosts_pool.health_checks.command")
+	viper.BindEnv("hosts_pool.health_checks.concurrency")
+	viper.BindEnv("hosts_pool.reaper_interval")
 
 	//Bind Ansible environment variables flags
 	for key := range ansibleConfiguration {
@@ -410,10 +436,25 @@ func setConfig() {
 	viper.SetDefault("ssh_connection_timeout", config.DefaultSSHConnectionTimeout)
 	viper.SetDefault("ssh_connection_retry_backoff", config.DefaultSSHConnectionRetryBackoff)
 	viper.SetDefault("ssh_connection_max_retries", config.DefaultSSHConnectionMaxRetries)
+	viper.SetDefault("ssh_host_key_checking", config.DefaultSSHHostKeyChecking)
 
 	viper.SetDefault("tasks.dispatcher.long_poll_wait_time", config.DefaultTasksDispatcherLongPollWaitTime)
 	viper.SetDefault("tasks.dispatcher.lock_wait_time", config.DefaultTasksDispatcherLockWaitTime)
 	viper.SetDefault("tasks.dispatcher.metrics_refresh_time", config.DefaultTasksDispatcherMetricsRefreshTime)
+	viper.SetDefault("tasks.steps_retry_policy.max_attempts", config.DefaultStepsRetryMaxAttempts)
+	viper.SetDefault("tasks.steps_retry_policy.initial_backoff", config.DefaultStepsRetryInitialBackoff)
+	viper.SetDefault("tasks.steps_retry_policy.max_backoff", config.DefaultStepsRetryMaxBackoff)
+	viper.SetDefault("tasks.steps_retry_policy.backoff_multiplier", config.DefaultStepsRetryBackoffMultiplier)
+	viper.SetDefault("tasks.steps_retry_policy.retryable_errors", config.DefaultStepsRetryableErrors)
+	viper.SetDefault("notifications.queue_size", config.DefaultNotificationsQueueSize)
+	viper.SetDefault("notifications.workers", config.DefaultNotificationsWorkers)
+	viper.SetDefault("notifications.request_timeout", config.DefaultNotificationsRequestTimeout)
+	viper.SetDefault("notifications.max_attempts", config.DefaultNotificationsMaxAttempts)
+	viper.SetDefault("notifications.initial_backoff", config.DefaultNotificationsInitialBackoff)
+	viper.SetDefault("notifications.max_backoff", config.DefaultNotificationsMaxBackoff)
+	viper.SetDefault("hosts_pool.health_checks.timeout", config.DefaultHostsPoolHealthChecksTimeout)
+	viper.SetDefault("hosts_pool.health_checks.concurrency", config.DefaultHostsPoolHealthChecksConcurrency)
+	viper.SetDefault("hosts_pool.reaper_interval", config.DefaultHostsPoolReaperInterval)
 
 	// Consul configuration default settings
 	for key, value := range consulConfiguration {
@@ -0,0 +1,169 @@
+// Copyright 2021 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/ystia/yorc/v4/config"
+	"github.com/ystia/yorc/v4/helper/consulutil"
+	"github.com/ystia/yorc/v4/helper/tabutil"
+	"github.com/ystia/yorc/v4/storage"
+	"github.com/ystia/yorc/v4/storage/types"
+)
+
+var storageCmd = &cobra.Command{
+	Use:          "storage",
+	Short:        "Perform commands on Yorc storage",
+	Long:         `Perform offline operations on the stores used by Yorc servers for deployments, logs and events`,
+	SilenceUsage: true,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		// Consul flags are bound here as they are also bound to server command flags at init
+		for key := range consulConfiguration {
+			viper.BindPFlag(key, cmd.Flags().Lookup(toFlatKey(key)))
+		}
+		viper.BindPFlag("working_directory", cmd.Flags().Lookup("working_directory"))
+		initConfig()
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(storageCmd)
+
+	storageCmd.PersistentFlags().StringVarP(&cfgFile, "config", "c", "", "config file (default is /etc/yorc/config.yorc.json)")
+	storageCmd.PersistentFlags().StringP("working_directory", "w", "", "The name of the working directory of the Yorc server")
+	storageCmd.PersistentFlags().StringP("consul_address", "", "", "Address of the HTTP interface for Consul (format: <host>:<port>)")
+	storageCmd.PersistentFlags().StringP("consul_token", "t", "", "The token by default")
+	storageCmd.PersistentFlags().StringP("consul_datacenter", "d", "", "The datacenter of Consul node")
+	storageCmd.PersistentFlags().String("consul_key_file", "", "The key file to use for talking to Consul over TLS")
+	storageCmd.PersistentFlags().String("consul_cert_file", "", "The cert file to use for talking to Consul over TLS")
+	storageCmd.PersistentFlags().String("consul_ca_cert", "", "CA cert to use for talking to Consul over TLS")
+	storageCmd.PersistentFlags().String("consul_ca_path", "", "Path to a directory of CA certs to use for talking to Consul over TLS")
+	storageCmd.PersistentFlags().Bool("consul_ssl", false, "Whether or not to use HTTPS")
+	storageCmd.PersistentFlags().Bool("consul_ssl_verify", true, "Whether or not to disable certificate checking")
+	storageCmd.PersistentFlags().Int("consul_publisher_max_routines", config.DefaultConsulPubMaxRoutines, "Maximum number of parallelism used to store data in Consul. If set to 0 or less the default value will be used")
+	storageCmd.PersistentFlags().Duration("consul_tls_handshake_timeout", config.DefaultConsulTLSHandshakeTimeout, "Maximum duration to wait for a TLS handshake with Consul")
+
+	var opts storage.MigrationOptions
+	var storeTypes []string
+	migrateCmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Migrate data between stores",
+		Long: `Copy data of the given types from a configured store to another one.
+
+Yorc servers should be stopped during the migration.
+Stores are looked up by name in the stores configuration saved in Consul, in the Yorc configuration file and in the default stores.
+Data is copied by batches and an interrupted migration resumes from its last checkpoint, unless --restart is set.
+Once copied, key counts and checksums of both stores are compared.
+With --cut-over, the stores configuration saved in Consul is rewritten so that Yorc servers use the target store for migrated types once restarted.`,
+		Example: `  yorc storage migrate --from defaultConsulStore --to myElasticStore --types Log,Event`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var err error
+			opts.Types, err = parseStoreTypes(storeTypes)
+			if err != nil {
+				return err
+			}
+			cfg := GetConfig()
+			client, err := cfg.GetConsulClient()
+			if err != nil {
+				return errors.Wrap(err, "Can't connect to Consul")
+			}
+			maxConsulPubRoutines := cfg.Consul.PubMaxRoutines
+			if maxConsulPubRoutines <= 0 {
+				maxConsulPubRoutines = config.DefaultConsulPubMaxRoutines
+			}
+			consulutil.InitConsulPublisher(maxConsulPubRoutines, client.KV())
+
+			opts.Progress = func(p storage.MigrationProgress) {
+				fmt.Printf("%s: %d key-values migrated (%d/%d prefixes done)\n", p.Type, p.Done, p.PrefixesDone, p.Prefixes)
+			}
+			reports, err := storage.Migrate(context.Background(), cfg, opts)
+			if len(reports) > 0 {
+				fmt.Println(renderMigrationReports(reports))
+			}
+			if err != nil {
+				return err
+			}
+			if opts.CutOver {
+				fmt.Printf("Stores configuration updated: store %q is now used for types %v. Yorc servers have to be restarted.\n", opts.To, opts.Types)
+			}
+			return nil
+		},
+	}
+	migrateCmd.Flags().StringVar(&opts.From, "from", "", "Name of the store to migrate data from")
+	migrateCmd.Flags().StringVar(&opts.To, "to", "", "Name of the store to migrate data to")
+	migrateCmd.Flags().StringSliceVar(&storeTypes, "types", types.StoreTypeNames(), "Comma-separated list of store types to migrate")
+	migrateCmd.Flags().IntVar(&opts.BatchSize, "batch-size", 500, "Number of key-values stored at once in the target store")
+	migrateCmd.Flags().BoolVar(&opts.Restart, "restart", false, "Ignore checkpoints of a previous interrupted migration and copy all data again")
+	migrateCmd.Flags().BoolVar(&opts.CutOver, "cut-over", false, "Rewrite the stores configuration saved in Consul to use the target store for migrated types")
+	migrateCmd.MarkFlagRequired("from")
+	migrateCmd.MarkFlagRequired("to")
+	storageCmd.AddCommand(migrateCmd)
+}
+
+// parseStoreTypes converts case insensitive store type names into store types
+func parseStoreTypes(names []string) ([]types.StoreType, error) {
+	result := make([]types.StoreType, 0, len(names))
+	for _, name := range names {
+		st, err := parseStoreType(name)
+		if err != nil {
+			return nil, err
+		}
+		if !containsStoreType(result, st) {
+			result = append(result, st)
+		}
+	}
+	if len(result) == 0 {
+		return nil, errors.New("at least one store type is required")
+	}
+	return result, nil
+}
+
+func parseStoreType(name string) (types.StoreType, error) {
+	for _, typeName := range types.StoreTypeNames() {
+		if strings.EqualFold(strings.TrimSpace(name), typeName) {
+			return types.ParseStoreType(typeName)
+		}
+	}
+	return types.StoreType(0), errors.Errorf("unknown store type %q, possible values are: %s", name, strings.Join(types.StoreTypeNames(), ", "))
+}
+
+func containsStoreType(storeTypes []types.StoreType, st types.StoreType) bool {
+	for _, storeType := range storeTypes {
+		if storeType == st {
+			return true
+		}
+	}
+	return false
+}
+
+func renderMigrationReports(reports []storage.MigrationReport) string {
+	table := tabutil.NewTable()
+	table.AddHeaders("Type", "Copied", "Skipped", "Source Keys", "Target Keys", "Checksum")
+	for _, report := range reports {
+		checksum := report.TargetChecksum
+		if checksum == "" {
+			checksum = "not verified"
+		}
+		table.AddRow(report.Type.String(), report.Copied, report.Skipped, report.SourceKeys, report.TargetKeys, checksum)
+	}
+	return table.Render()
+}
@@ -0,0 +1,62 @@
+// Copyright 2021 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ystia/yorc/v4/storage"
+	"github.com/ystia/yorc/v4/storage/types"
+)
+
+func TestParseStoreTypes(t *testing.T) {
+	tests := []struct {
+		name    string
+		names   []string
+		want    []types.StoreType
+		wantErr bool
+	}{
+		{"AllTypes", []string{"Deployment", "Log", "Event"}, []types.StoreType{types.StoreTypeDeployment, types.StoreTypeLog, types.StoreTypeEvent}, false},
+		{"CaseInsensitive", []string{"log", " EVENT"}, []types.StoreType{types.StoreTypeLog, types.StoreTypeEvent}, false},
+		{"Duplicates", []string{"Log", "log"}, []types.StoreType{types.StoreTypeLog}, false},
+		{"UnknownType", []string{"Log", "Metric"}, nil, true},
+		{"NoType", []string{}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseStoreTypes(tt.names)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRenderMigrationReports(t *testing.T) {
+	out := renderMigrationReports([]storage.MigrationReport{
+		{Type: types.StoreTypeLog, Copied: 10, Skipped: 2, SourceKeys: 12, TargetKeys: 12, SourceChecksum: "abcd", TargetChecksum: "abcd"},
+		{Type: types.StoreTypeEvent, Copied: 3, SourceKeys: 3, TargetKeys: 3},
+	})
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	require.Len(t, lines, 6)
+	require.Contains(t, lines[3], "abcd")
+	require.Contains(t, lines[4], "not verified")
+}
@@ -81,6 +81,48 @@ const DefaultTasksDispatcherLockWaitTime = 50 * time.Millisecond
 // DefaultTasksDispatcherMetricsRefreshTime is the default refresh time for the Tasks dispatcher metrics
 const DefaultTasksDispatcherMetricsRefreshTime = 5 * time.Minute
 
+// DefaultStepsRetryMaxAttempts is the default maximum number of attempts of a workflow step activity, 1 means no retry
+const DefaultStepsRetryMaxAttempts = 1
+
+// DefaultStepsRetryInitialBackoff is the default wait time before the first retry of a failed workflow step activity
+const DefaultStepsRetryInitialBackoff = 10 * time.Second
+
+// DefaultStepsRetryMaxBackoff is the default maximum wait time between two attempts of a workflow step activity
+const DefaultStepsRetryMaxBackoff = 5 * time.Minute
+
+// DefaultStepsRetryBackoffMultiplier is the default factor applied to the wait time between two consecutive retries
+const DefaultStepsRetryBackoffMultiplier = 2.0
+
+// DefaultStepsRetryableErrors are the default classes of errors for which a workflow step activity is retried
+var DefaultStepsRetryableErrors = []string{"ssh_connection", "network", "timeout"}
+
+// DefaultNotificationsQueueSize is the default number of notifications waiting to be delivered to webhooks
+const DefaultNotificationsQueueSize = 1000
+
+// DefaultNotificationsWorkers is the default number of notifications delivered concurrently
+const DefaultNotificationsWorkers = 4
+
+// DefaultNotificationsRequestTimeout is the default timeout of a webhook HTTP request
+const DefaultNotificationsRequestTimeout = 10 * time.Second
+
+// DefaultNotificationsMaxAttempts is the default maximum number of attempts to deliver a notification to a webhook
+const DefaultNotificationsMaxAttempts = 5
+
+// DefaultNotificationsInitialBackoff is the default wait time before retrying to deliver a notification
+const DefaultNotificationsInitialBackoff = 5 * time.Second
+
+// DefaultNotificationsMaxBackoff is the default maximum wait time between two attempts to deliver a notification
+const DefaultNotificationsMaxBackoff = 5 * time.Minute
+
+// DefaultHostsPoolHealthChecksTimeout is the default maximum duration of the health check of a host of a hosts pool
+const DefaultHostsPoolHealthChecksTimeout = 1 * time.Minute
+
+// DefaultHostsPoolHealthChecksConcurrency is the default maximum number of hosts pools hosts checked at the same time
+const DefaultHostsPoolHealthChecksConcurrency = 10
+
+// DefaultHostsPoolReaperInterval is the default interval between two releases of expired hosts pools allocations and reservations
+const DefaultHostsPoolReaperInterval = 1 * time.Minute
+
 // DefaultUpgradesConcurrencyLimit is the default limit of concurrency used in Upgrade processes
 const DefaultUpgradesConcurrencyLimit = 1000
 
@@ -93,36 +135,45 @@ const DefaultSSHConnectionRetryBackoff = 1 * time.Second
 // DefaultSSHConnectionMaxRetries is the default maximum number of retries before giving up (number of attempts is number of retries + 1)
 const DefaultSSHConnectionMaxRetries uint64 = 3
 
+// DefaultSSHHostKeyChecking is the default mode used to verify SSH host keys
+const DefaultSSHHostKeyChecking = "tofu"
+
 // Configuration holds config information filled by Cobra and Viper (see commands package for more information)
 type Configuration struct {
-	Ansible                          Ansible       `yaml:"ansible,omitempty" mapstructure:"ansible"`
-	PluginsDirectory                 string        `yaml:"plugins_directory,omitempty" mapstructure:"plugins_directory"`
-	WorkingDirectory                 string        `yaml:"working_directory,omitempty" mapstructure:"working_directory"`
-	WorkersNumber                    int           `yaml:"workers_number,omitempty" mapstructure:"workers_number"`
-	ServerGracefulShutdownTimeout    time.Duration `yaml:"server_graceful_shutdown_timeout,omitempty" mapstructure:"server_graceful_shutdown_timeout"`
-	HTTPPort                         int           `yaml:"http_port,omitempty" mapstructure:"http_port"`
-	HTTPAddress                      string        `yaml:"http_address,omitempty" mapstructure:"http_address"`
-	KeyFile                          string        `yaml:"key_file,omitempty" mapstructure:"key_file"`
-	CertFile                         string        `yaml:"cert_file,omitempty" mapstructure:"cert_file"`
-	CAFile                           string        `yaml:"ca_file,omitempty" mapstructure:"ca_file"`
-	CAPath                           string        `yaml:"ca_path,omitempty" mapstructure:"ca_path"`
-	SSLVerify                        bool          `yaml:"ssl_verify,omitempty" mapstructure:"ssl_verify"`
-	ResourcesPrefix                  string        `yaml:"resources_prefix,omitempty" mapstructure:"resources_prefix"`
-	Consul                           Consul        `yaml:"consul,omitempty" mapstructure:"consul"`
-	Telemetry                        Telemetry     `yaml:"telemetry,omitempty" mapstructure:"telemetry"`
-	LocationsFilePath                string        `yaml:"locations_file_path,omitempty" mapstructure:"locations_file_path"`
-	Vault                            DynamicMap    `yaml:"vault,omitempty" mapstructure:"vault"`
-	WfStepGracefulTerminationTimeout time.Duration `yaml:"wf_step_graceful_termination_timeout,omitempty" mapstructure:"wf_step_graceful_termination_timeout"`
-	PurgedDeploymentsEvictionTimeout time.Duration `yaml:"purged_deployments_eviction_timeout,omitempty" mapstructure:"purged_deployments_eviction_timeout"`
-	ServerID                         string        `yaml:"server_id,omitempty" mapstructure:"server_id"`
-	Terraform                        Terraform     `yaml:"terraform,omitempty" mapstructure:"terraform"`
-	DisableSSHAgent                  bool          `yaml:"disable_ssh_agent,omitempty" mapstructure:"disable_ssh_agent"`
-	Tasks                            Tasks         `yaml:"tasks,omitempty" mapstructure:"tasks"`
-	Storage                          Storage       `yaml:"storage,omitempty" mapstructure:"storage"`
-	UpgradeConcurrencyLimit          int           `yaml:"concurrency_limit_for_upgrades,omitempty" mapstructure:"concurrency_limit_for_upgrades"`
-	SSHConnectionTimeout             time.Duration `yaml:"ssh_connection_timeout,omitempty" mapstructure:"ssh_connection_timeout"`
-	SSHConnectionRetryBackoff        time.Duration `yaml:"ssh_connection_retry_backoff,omitempty" mapstructure:"ssh_connection_retry_backoff"`
-	SSHConnectionMaxRetries          uint64        `yaml:"ssh_connection_max_retries,omitempty" mapstructure:"ssh_connection_max_retries"`
+	Ansible                          Ansible        `yaml:"ansible,omitempty" mapstructure:"ansible"`
+	PluginsDirectory                 string         `yaml:"plugins_directory,omitempty" mapstructure:"plugins_directory"`
+	WorkingDirectory                 string         `yaml:"working_directory,omitempty" mapstructure:"working_directory"`
+	WorkersNumber                    int            `yaml:"workers_number,omitempty" mapstructure:"workers_number"`
+	ServerGracefulShutdownTimeout    time.Duration  `yaml:"server_graceful_shutdown_timeout,omitempty" mapstructure:"server_graceful_shutdown_timeout"`
+	HTTPPort                         int            `yaml:"http_port,omitempty" mapstructure:"http_port"`
+	HTTPAddress                      string         `yaml:"http_address,omitempty" mapstructure:"http_address"`
+	KeyFile                          string         `yaml:"key_file,omitempty" mapstructure:"key_file"`
+	CertFile                         string         `yaml:"cert_file,omitempty" mapstructure:"cert_file"`
+	CAFile                           string         `yaml:"ca_file,omitempty" mapstructure:"ca_file"`
+	CAPath                           string         `yaml:"ca_path,omitempty" mapstructure:"ca_path"`
+	SSLVerify                        bool           `yaml:"ssl_verify,omitempty" mapstructure:"ssl_verify"`
+	ResourcesPrefix                  string         `yaml:"resources_prefix,omitempty" mapstructure:"resources_prefix"`
+	Consul                           Consul         `yaml:"consul,omitempty" mapstructure:"consul"`
+	Telemetry                        Telemetry      `yaml:"telemetry,omitempty" mapstructure:"telemetry"`
+	LocationsFilePath                string         `yaml:"locations_file_path,omitempty" mapstructure:"locations_file_path"`
+	Vault                            DynamicMap     `yaml:"vault,omitempty" mapstructure:"vault"`
+	WfStepGracefulTerminationTimeout time.Duration  `yaml:"wf_step_graceful_termination_timeout,omitempty" mapstructure:"wf_step_graceful_termination_timeout"`
+	PurgedDeploymentsEvictionTimeout time.Duration  `yaml:"purged_deployments_eviction_timeout,omitempty" mapstructure:"purged_deployments_eviction_timeout"`
+	ServerID                         string         `yaml:"server_id,omitempty" mapstructure:"server_id"`
+	Terraform                        Terraform      `yaml:"terraform,omitempty" mapstructure:"terraform"`
+	DisableSSHAgent                  bool           `yaml:"disable_ssh_agent,omitempty" mapstructure:"disable_ssh_agent"`
+	Tasks                            Tasks          `yaml:"tasks,omitempty" mapstructure:"tasks"`
+	Storage                          Storage        `yaml:"storage,omitempty" mapstructure:"storage"`
+	UpgradeConcurrencyLimit          int            `yaml:"concurrency_limit_for_upgrades,omitempty" mapstructure:"concurrency_limit_for_upgrades"`
+	SSHConnectionTimeout             time.Duration  `yaml:"ssh_connection_timeout,omitempty" mapstructure:"ssh_connection_timeout"`
+	SSHConnectionRetryBackoff        time.Duration  `yaml:"ssh_connection_retry_backoff,omitempty" mapstructure:"ssh_connection_retry_backoff"`
+	SSHConnectionMaxRetries          uint64         `yaml:"ssh_connection_max_retries,omitempty" mapstructure:"ssh_connection_max_retries"`
+	SSHKnownHostsFile                string         `yaml:"ssh_known_hosts_file,omitempty" mapstructure:"ssh_known_hosts_file"`
+	SSHHostKeyChecking               string         `yaml:"ssh_host_key_checking,omitempty" mapstructure:"ssh_host_key_checking"`
+	SSHVaultSigningPath              string         `yaml:"ssh_vault_signing_path,omitempty" mapstructure:"ssh_vault_signing_path"`
+	Authentication                   Authentication `yaml:"authentication,omitempty" mapstructure:"authentication"`
+	Notifications                    Notifications  `yaml:"notifications,omitempty" mapstructure:"notifications"`
+	HostsPool                        HostsPool      `yaml:"hosts_pool,omitempty" mapstructure:"hosts_pool"`
 }
 
 // DockerSandbox holds the configuration for a docker sandbox
@@ -201,7 +252,21 @@ type Terraform struct {
 
 // Tasks processing configuration
 type Tasks struct {
-	Dispatcher Dispatcher `yaml:"dispatcher,omitempty" mapstructure:"dispatcher" json:"dispatcher,omitempty"`
+	Dispatcher       Dispatcher  `yaml:"dispatcher,omitempty" mapstructure:"dispatcher" json:"dispatcher,omitempty"`
+	StepsRetryPolicy RetryPolicy `yaml:"steps_retry_policy,omitempty" mapstructure:"steps_retry_policy" json:"steps_retry_policy,omitempty"`
+	// OperationsTimeout is the default maximum duration of an operation, 0 means no timeout
+	OperationsTimeout time.Duration `yaml:"operations_timeout,omitempty" mapstructure:"operations_timeout" json:"operations_timeout,omitempty"`
+}
+
+// RetryPolicy defines how failed workflow steps activities are retried
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts including the first one
+	MaxAttempts       int           `yaml:"max_attempts,omitempty" mapstructure:"max_attempts" json:"max_attempts,omitempty"`
+	InitialBackoff    time.Duration `yaml:"initial_backoff,omitempty" mapstructure:"initial_backoff" json:"initial_backoff,omitempty"`
+	MaxBackoff        time.Duration `yaml:"max_backoff,omitempty" mapstructure:"max_backoff" json:"max_backoff,omitempty"`
+	BackoffMultiplier float64       `yaml:"backoff_multiplier,omitempty" mapstructure:"backoff_multiplier" json:"backoff_multiplier,omitempty"`
+	// RetryableErrors are the classes of errors that could be retried (ssh_connection, network, timeout or any)
+	RetryableErrors []string `yaml:"retryable_errors,omitempty" mapstructure:"retryable_errors" json:"retryable_errors,omitempty"`
 }
 
 // Dispatcher configuration
@@ -209,6 +274,82 @@ type Dispatcher struct {
 	LongPollWaitTime   time.Duration `yaml:"long_poll_wait_time,omitempty" mapstructure:"long_poll_wait_time" json:"long_poll_wait_time,omitempty"`
 	LockWaitTime       time.Duration `yaml:"lock_wait_time,omitempty" mapstructure:"lock_wait_time" json:"lock_wait_time,omitempty"`
 	MetricsRefreshTime time.Duration `yaml:"metrics_refresh_time,omitempty" mapstructure:"metrics_refresh_time" json:"metrics_refresh_time,omitempty"`
+	// MaxConcurrentStepsPerLocation limits, by location name, the number of workflow steps running at the same time across the cluster
+	MaxConcurrentStepsPerLocation map[string]int `yaml:"max_concurrent_steps_per_location,omitempty" mapstructure:"max_concurrent_steps_per_location" json:"max_concurrent_steps_per_location,omitempty"`
+	// MaxConcurrentStepsPerInfrastructure limits, by location type, the number of workflow steps running at the same time across the cluster
+	MaxConcurrentStepsPerInfrastructure map[string]int `yaml:"max_concurrent_steps_per_infrastructure,omitempty" mapstructure:"max_concurrent_steps_per_infrastructure" json:"max_concurrent_steps_per_infrastructure,omitempty"`
+}
+
+// Notifications holds the configuration of the deliveries of events to HTTP webhooks
+type Notifications struct {
+	// Webhooks are statically defined webhooks, others can be registered using the REST API
+	Webhooks       []Webhook     `yaml:"webhooks,omitempty" mapstructure:"webhooks" json:"webhooks,omitempty"`
+	QueueSize      int           `yaml:"queue_size,omitempty" mapstructure:"queue_size" json:"queue_size,omitempty"`
+	Workers        int           `yaml:"workers,omitempty" mapstructure:"workers" json:"workers,omitempty"`
+	RequestTimeout time.Duration `yaml:"request_timeout,omitempty" mapstructure:"request_timeout" json:"request_timeout,omitempty"`
+	// MaxAttempts is the maximum number of attempts to deliver a notification before recording it as a dead letter
+	MaxAttempts    int           `yaml:"max_attempts,omitempty" mapstructure:"max_attempts" json:"max_attempts,omitempty"`
+	InitialBackoff time.Duration `yaml:"initial_backoff,omitempty" mapstructure:"initial_backoff" json:"initial_backoff,omitempty"`
+	MaxBackoff     time.Duration `yaml:"max_backoff,omitempty" mapstructure:"max_backoff" json:"max_backoff,omitempty"`
+}
+
+// Webhook is an HTTP endpoint notified of events matching its filters
+type Webhook struct {
+	Name string `yaml:"name" mapstructure:"name" json:"name"`
+	URL  string `yaml:"url" mapstructure:"url" json:"url"`
+	// Secret is used to sign payloads with HMAC-SHA256
+	Secret  string            `yaml:"secret,omitempty" mapstructure:"secret" json:"-"`
+	Headers map[string]string `yaml:"headers,omitempty" mapstructure:"headers" json:"headers,omitempty"`
+	// Deployments, EventTypes and Statuses filter notified events, an empty filter matches any value
+	Deployments []string `yaml:"deployments,omitempty" mapstructure:"deployments" json:"deployments,omitempty"`
+	EventTypes  []string `yaml:"event_types,omitempty" mapstructure:"event_types" json:"event_types,omitempty"`
+	Statuses    []string `yaml:"statuses,omitempty" mapstructure:"statuses" json:"statuses,omitempty"`
+}
+
+// HostsPool holds the configuration of hosts pools locations
+type HostsPool struct {
+	HealthChecks HostsPoolHealthChecks `yaml:"health_checks,omitempty" mapstructure:"health_checks" json:"health_checks,omitempty"`
+	// ReaperInterval is the interval between two releases of expired allocations leases and reservations, 0 disables it
+	ReaperInterval time.Duration `yaml:"reaper_interval,omitempty" mapstructure:"reaper_interval" json:"reaper_interval,omitempty"`
+}
+
+// HostsPoolHealthChecks holds the configuration of the periodic health checks of hosts pools hosts
+type HostsPoolHealthChecks struct {
+	// Interval between two health checks of hosts, 0 disables periodic health checks
+	Interval time.Duration `yaml:"interval,omitempty" mapstructure:"interval" json:"interval,omitempty"`
+	// Timeout is the maximum duration of the health check of a host
+	Timeout time.Duration `yaml:"timeout,omitempty" mapstructure:"timeout" json:"timeout,omitempty"`
+	// Command is an optional probe command run on hosts once connected, a failure of this command puts the host in error
+	Command string `yaml:"command,omitempty" mapstructure:"command" json:"command,omitempty"`
+	// Concurrency is the maximum number of hosts checked at the same time
+	Concurrency int `yaml:"concurrency,omitempty" mapstructure:"concurrency" json:"concurrency,omitempty"`
+}
+
+// Authentication holds the REST API authentication configuration
+//
+// Authentication is enabled as soon as a static token or a JWKS file is configured.
+type Authentication struct {
+	StaticTokens []StaticToken `yaml:"static_tokens,omitempty" mapstructure:"static_tokens" json:"static_tokens,omitempty"`
+	JWT          JWT           `yaml:"jwt,omitempty" mapstructure:"jwt" json:"jwt,omitempty"`
+	// AnonymousRole is the role granted to requests without credentials, they are rejected if empty
+	AnonymousRole string `yaml:"anonymous_role,omitempty" mapstructure:"anonymous_role" json:"anonymous_role,omitempty"`
+}
+
+// StaticToken is a bearer token granting a role on the REST API
+type StaticToken struct {
+	Name  string `yaml:"name" mapstructure:"name" json:"name"`
+	Token string `yaml:"token" mapstructure:"token" json:"-"`
+	Role  string `yaml:"role" mapstructure:"role" json:"role"`
+}
+
+// JWT holds the configuration of JSON Web Tokens (typically issued by an OpenID Connect provider) validation
+type JWT struct {
+	JWKSFile      string        `yaml:"jwks_file,omitempty" mapstructure:"jwks_file" json:"jwks_file,omitempty"`
+	Issuer        string        `yaml:"issuer,omitempty" mapstructure:"issuer" json:"issuer,omitempty"`
+	Audience      string        `yaml:"audience,omitempty" mapstructure:"audience" json:"audience,omitempty"`
+	RolesClaim    string        `yaml:"roles_claim,omitempty" mapstructure:"roles_claim" json:"roles_claim,omitempty"`
+	UsernameClaim string        `yaml:"username_claim,omitempty" mapstructure:"username_claim" json:"username_claim,omitempty"`
+	Leeway        time.Duration `yaml:"leeway,omitempty" mapstructure:"leeway" json:"leeway,omitempty"`
 }
 
 // Storage configuration
@@ -432,4 +573,5 @@ type Client struct {
 	CertFile      string `mapstructure:"cert_file"`
 	CAFile        string `mapstructure:"ca_file"`
 	CAPath        string `mapstructure:"ca_path"`
+	APIToken      string `mapstructure:"api_token"`
 }
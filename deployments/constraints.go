@@ -0,0 +1,353 @@
+// Copyright 2021 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deployments
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/ystia/yorc/v4/log"
+	"github.com/ystia/yorc/v4/tosca"
+)
+
+type constraintsViolationsError struct {
+	violations []string
+}
+
+func (e constraintsViolationsError) Error() string {
+	return fmt.Sprintf("%d TOSCA constraints violations:\n%s", len(e.violations), strings.Join(e.violations, "\n"))
+}
+
+// IsConstraintsViolationsError checks if the given error is due to values not satisfying TOSCA constraints
+func IsConstraintsViolationsError(err error) bool {
+	cause := errors.Cause(err)
+	_, ok := cause.(constraintsViolationsError)
+	return ok
+}
+
+// dataTypeInfo holds the constraints definitions of a data type and its parents
+type dataTypeInfo struct {
+	// primitive is the builtin type the data type derives from if any
+	primitive   string
+	constraints []tosca.ConstraintClause
+	properties  map[string]tosca.PropertyDefinition
+}
+
+// constraintsChecker collects all the TOSCA constraints violations of a deployment
+type constraintsChecker struct {
+	ctx          context.Context
+	deploymentID string
+	dataTypes    map[string]*dataTypeInfo
+	violations   []string
+}
+
+func newConstraintsChecker(ctx context.Context, deploymentID string) *constraintsChecker {
+	return &constraintsChecker{ctx: ctx, deploymentID: deploymentID, dataTypes: make(map[string]*dataTypeInfo)}
+}
+
+func (c *constraintsChecker) err() error {
+	if len(c.violations) == 0 {
+		return nil
+	}
+	return constraintsViolationsError{violations: c.violations}
+}
+
+// checkTopologyConstraints checks that topology inputs, node templates properties and their capabilities properties
+// satisfy the constraints defined on their definitions and data types.
+//
+// All violations are reported in a single error that could be identified using IsConstraintsViolationsError.
+func checkTopologyConstraints(ctx context.Context, deploymentID string, nodes []string) error {
+	c := newConstraintsChecker(ctx, deploymentID)
+
+	inputs, err := GetTopologyInputsNames(ctx, deploymentID)
+	if err != nil {
+		return err
+	}
+	sort.Strings(inputs)
+	for _, input := range inputs {
+		found, def, err := GetTopologyInputParameter(ctx, deploymentID, input)
+		if err != nil {
+			return err
+		}
+		if !found {
+			continue
+		}
+		va := def.Value
+		if va == nil {
+			va = def.Default
+		}
+		err = c.checkValueAssignment(fmt.Sprintf("input %q", input), def.Type, def.Constraints, def.EntrySchema, va)
+		if err != nil {
+			return err
+		}
+	}
+
+	sortedNodes := append([]string(nil), nodes...)
+	sort.Strings(sortedNodes)
+	for _, nodeName := range sortedNodes {
+		err = c.checkNodeConstraints(nodeName)
+		if err != nil {
+			return err
+		}
+	}
+	return c.err()
+}
+
+// CheckWorkflowInputsConstraints checks that the given workflow inputs values satisfy the constraints of their definitions
+//
+// All violations are reported in a single error that could be identified using IsConstraintsViolationsError.
+func CheckWorkflowInputsConstraints(ctx context.Context, deploymentID, workflowName string, inputs map[string]interface{}) error {
+	wf, err := GetWorkflow(ctx, deploymentID, workflowName)
+	if err != nil {
+		return err
+	}
+	if wf == nil {
+		return errors.Errorf("Can't check inputs of workflow %q in deployment %q, workflow definition not found", workflowName, deploymentID)
+	}
+	c := newConstraintsChecker(ctx, deploymentID)
+	names := make([]string, 0, len(wf.Inputs))
+	for name := range wf.Inputs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		def := wf.Inputs[name]
+		location := fmt.Sprintf("workflow input %q", name)
+		if value, ok := inputs[name]; ok {
+			err = c.checkValue(location, def.Type, def.Constraints, def.EntrySchema, value)
+		} else {
+			err = c.checkValueAssignment(location, def.Type, def.Constraints, def.EntrySchema, def.Default)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return c.err()
+}
+
+func (c *constraintsChecker) checkNodeConstraints(nodeName string) error {
+	node, err := getNodeTemplate(c.ctx, c.deploymentID, nodeName)
+	if err != nil {
+		return err
+	}
+	nodeType, err := GetNodeType(c.ctx, c.deploymentID, nodeName)
+	if err != nil {
+		return err
+	}
+	propDefs, err := c.getPropertyDefinitions(nodeType)
+	if err != nil {
+		return err
+	}
+	for _, propName := range sortedPropertiesNames(propDefs) {
+		def := propDefs[propName]
+		va := node.Properties[propName]
+		if va == nil {
+			va = def.Default
+		}
+		err = c.checkValueAssignment(fmt.Sprintf("node %q property %q", nodeName, propName), def.Type, def.Constraints, def.EntrySchema, va)
+		if err != nil {
+			return err
+		}
+	}
+
+	capNames := make([]string, 0, len(node.Capabilities))
+	for capName := range node.Capabilities {
+		capNames = append(capNames, capName)
+	}
+	sort.Strings(capNames)
+	for _, capName := range capNames {
+		capType, err := GetNodeTypeCapabilityType(c.ctx, c.deploymentID, nodeType, capName)
+		if err != nil {
+			return err
+		}
+		if capType == "" {
+			continue
+		}
+		capPropDefs, err := c.getPropertyDefinitions(capType)
+		if err != nil {
+			return err
+		}
+		for _, propName := range sortedPropertiesNames(capPropDefs) {
+			va := node.Capabilities[capName].Properties[propName]
+			if va == nil {
+				// Capabilities defaults values are not checked
+				continue
+			}
+			def := capPropDefs[propName]
+			location := fmt.Sprintf("node %q capability %q property %q", nodeName, capName, propName)
+			err = c.checkValueAssignment(location, def.Type, def.Constraints, def.EntrySchema, va)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// getPropertyDefinitions returns the properties definitions of a type and its parents
+func (c *constraintsChecker) getPropertyDefinitions(typeName string) (map[string]tosca.PropertyDefinition, error) {
+	result := make(map[string]tosca.PropertyDefinition)
+	for typeName != "" {
+		propDefs, err := getTypePropertyDefinitions(c.ctx, c.deploymentID, typeName)
+		if err != nil {
+			return nil, err
+		}
+		for propName, def := range propDefs {
+			if _, ok := result[propName]; !ok {
+				result[propName] = def
+			}
+		}
+		typeName, err = GetParentType(c.ctx, c.deploymentID, typeName)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+func sortedPropertiesNames(propDefs map[string]tosca.PropertyDefinition) []string {
+	names := make([]string, 0, len(propDefs))
+	for name := range propDefs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// checkValueAssignment checks a value assignment, TOSCA functions are resolved at runtime so they are not checked
+func (c *constraintsChecker) checkValueAssignment(location, typeName string, constraints []tosca.ConstraintClause, entrySchema tosca.EntrySchema, va *tosca.ValueAssignment) error {
+	if va == nil || va.Value == nil || va.Type == tosca.ValueAssignmentFunction {
+		return nil
+	}
+	return c.checkValue(location, typeName, constraints, entrySchema, va.Value)
+}
+
+// checkValue checks a value against the given constraints and the ones of its data type,
+// lists and maps entries as well as complex data types properties are checked recursively
+func (c *constraintsChecker) checkValue(location, typeName string, constraints []tosca.ConstraintClause, entrySchema tosca.EntrySchema, value interface{}) error {
+	if value == nil || isFunctionValue(value) {
+		return nil
+	}
+	dt, err := c.getDataTypeInfo(typeName)
+	if err != nil {
+		return err
+	}
+	allConstraints := append(append([]tosca.ConstraintClause(nil), dt.constraints...), constraints...)
+	for _, constraint := range allConstraints {
+		if err := constraint.Validate(dt.primitive, value); err != nil {
+			c.violations = append(c.violations, fmt.Sprintf("%s: %v", location, err))
+		}
+	}
+
+	switch dt.primitive {
+	case "list", "map":
+		entryType := entrySchema.Type
+		if entryType == "" {
+			entryType = "string"
+		}
+		switch v := value.(type) {
+		case []interface{}:
+			for i, entry := range v {
+				err = c.checkValue(fmt.Sprintf("%s[%d]", location, i), entryType, entrySchema.Constraints, tosca.EntrySchema{}, entry)
+				if err != nil {
+					return err
+				}
+			}
+		case map[string]interface{}:
+			for _, key := range sortedKeys(v) {
+				err = c.checkValue(fmt.Sprintf("%s[%s]", location, key), entryType, entrySchema.Constraints, tosca.EntrySchema{}, v[key])
+				if err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+
+	m, ok := value.(map[string]interface{})
+	if !ok || len(dt.properties) == 0 {
+		return nil
+	}
+	for _, propName := range sortedPropertiesNames(dt.properties) {
+		def := dt.properties[propName]
+		propValue, ok := m[propName]
+		if !ok {
+			continue
+		}
+		err = c.checkValue(location+"."+propName, def.Type, def.Constraints, def.EntrySchema, propValue)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getDataTypeInfo returns the constraints and properties of a data type and its parents
+func (c *constraintsChecker) getDataTypeInfo(typeName string) (*dataTypeInfo, error) {
+	if dt, ok := c.dataTypes[typeName]; ok {
+		return dt, nil
+	}
+	dt := &dataTypeInfo{properties: make(map[string]tosca.PropertyDefinition)}
+	current := typeName
+	for current != "" && !tosca.IsBuiltinType(current) {
+		typ := new(tosca.DataType)
+		err := getExpectedTypeFromName(c.ctx, c.deploymentID, current, typ)
+		if err != nil {
+			if IsTypeMissingError(err) {
+				// Types consistency is not checked here, compare values as strings
+				log.Debugf("Can't check constraints of data type %q in deployment %q: %v", current, c.deploymentID, err)
+				current = ""
+				break
+			}
+			return nil, err
+		}
+		dt.constraints = append(dt.constraints, typ.Constraints...)
+		for propName, def := range typ.Properties {
+			if _, ok := dt.properties[propName]; !ok {
+				dt.properties[propName] = def
+			}
+		}
+		current = typ.DerivedFrom
+	}
+	// list and map types may be represented as list:<entry type>
+	dt.primitive = strings.SplitN(current, ":", 2)[0]
+	c.dataTypes[typeName] = dt
+	return dt, nil
+}
+
+// isFunctionValue checks if a value nested in a list or a map is a TOSCA function
+func isFunctionValue(value interface{}) bool {
+	m, ok := value.(map[string]interface{})
+	if !ok || len(m) != 1 {
+		return false
+	}
+	for k := range m {
+		return tosca.IsOperator(k)
+	}
+	return false
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
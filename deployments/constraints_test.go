@@ -0,0 +1,51 @@
+// Copyright 2021 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deployments
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ystia/yorc/v4/testutil"
+)
+
+func testConstraints(t *testing.T) {
+	deploymentID := testutil.BuildDeploymentID(t)
+	err := StoreDeploymentDefinition(context.Background(), deploymentID, "testdata/constraints.yaml")
+	require.Error(t, err)
+	require.True(t, IsConstraintsViolationsError(err), "unexpected error %+v", err)
+
+	msg := err.Error()
+	expected := []string{
+		`input "replicas"`,
+		`node "Invalid" property "port"`,
+		`node "Invalid" property "mode"`,
+		`node "Invalid" property "name"`,
+		`node "Invalid" property "heap"`,
+		`node "Invalid" property "tags"[0]`,
+		`node "Invalid" property "listener".protocol`,
+		`node "Invalid" property "listener".port`,
+	}
+	for _, e := range expected {
+		assert.Contains(t, msg, e)
+	}
+	assert.True(t, strings.HasPrefix(msg, "9 TOSCA constraints violations"), "unexpected error message %q", msg)
+	assert.NotContains(t, msg, `node "Valid"`)
+	assert.NotContains(t, msg, `node "WithFunction"`)
+}
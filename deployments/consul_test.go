@@ -110,6 +110,9 @@ func TestRunConsulDeploymentsPackageTests(t *testing.T) {
 		t.Run("testTopologyBadUpdate", func(t *testing.T) {
 			testTopologyBadUpdate(t)
 		})
+		t.Run("testTopologyUpdateConstraintsViolation", func(t *testing.T) {
+			testTopologyUpdateConstraintsViolation(t)
+		})
 		t.Run("testRepositories", func(t *testing.T) {
 			testRepositories(t)
 		})
@@ -132,6 +135,12 @@ func TestRunConsulDeploymentsPackageTests(t *testing.T) {
 		t.Run("testResolveAttributeMapping", func(t *testing.T) {
 			testResolveAttributeMapping(t)
 		})
+		t.Run("testConstraints", func(t *testing.T) {
+			testConstraints(t)
+		})
+		t.Run("testCheckAndSetDeploymentStatus", func(t *testing.T) {
+			testCheckAndSetDeploymentStatus(t)
+		})
 
 	})
 
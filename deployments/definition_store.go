@@ -89,6 +89,10 @@ func StoreDeploymentDefinition(ctx context.Context, deploymentID string, defPath
 	if err != nil {
 		return err
 	}
+	err = checkTopologyConstraints(ctx, deploymentID, nodes)
+	if err != nil {
+		return handleDeploymentStatus(ctx, deploymentID, err)
+	}
 	err = PostDeploymentDefinitionStorageProcess(ctx, deploymentID, nodes)
 	if err != nil {
 		return handleDeploymentStatus(ctx, deploymentID, err)
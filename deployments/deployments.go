@@ -170,6 +170,57 @@ RETRY:
 	return nil
 }
 
+// CheckAndSetDeploymentStatus atomically sets the deployment status to the given status if its current status
+// is one of the expected ones.
+//
+// It returns the current status and false if it is not an expected one, in this case the status is left unchanged.
+func CheckAndSetDeploymentStatus(ctx context.Context, deploymentID string, status DeploymentStatus, expected ...DeploymentStatus) (DeploymentStatus, bool, error) {
+	for {
+		select {
+		case <-ctx.Done():
+			return INITIAL, false, errors.Wrapf(ctx.Err(), "failed to update deployment %q status to %q", deploymentID, status.String())
+		default:
+		}
+
+		kvp, meta, err := consulutil.GetKV().Get(path.Join(consulutil.DeploymentKVPrefix, deploymentID, "status"), nil)
+		if err != nil {
+			return INITIAL, false, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+		}
+		if kvp == nil || len(kvp.Value) == 0 || meta == nil {
+			return INITIAL, false, errors.WithStack(&deploymentNotFound{deploymentID})
+		}
+		currentStatus, err := DeploymentStatusFromString(string(kvp.Value), true)
+		if err != nil {
+			return INITIAL, false, err
+		}
+		var isExpected bool
+		for _, e := range expected {
+			if currentStatus == e {
+				isExpected = true
+				break
+			}
+		}
+		if !isExpected {
+			return currentStatus, false, nil
+		}
+		if currentStatus == status {
+			return currentStatus, true, nil
+		}
+
+		kvp.Value = []byte(status.String())
+		kvp.ModifyIndex = meta.LastIndex
+		ok, _, err := consulutil.GetKV().CAS(kvp, nil)
+		if err != nil {
+			return currentStatus, false, errors.Wrapf(err, "Failed to set deployment status to %q for deploymentID:%q", status.String(), deploymentID)
+		}
+		if ok {
+			log.Debugf("Deployment status change for %s from %s to %s", deploymentID, currentStatus.String(), status.String())
+			events.PublishAndLogDeploymentStatusChange(ctx, deploymentID, strings.ToLower(status.String()))
+			return currentStatus, true, nil
+		}
+	}
+}
+
 // TagDeploymentAsPurged registers current purge time and emit a deployment status change event and a log for the given deployment
 //
 // The timestamp will be used to evict purged deployments after an given delay.
@@ -302,6 +353,10 @@ func GetDeploymentsIDs(ctx context.Context) ([]string, error) {
 	deps := make([]string, 0)
 	for _, depPath := range depPaths {
 		deploymentID := path.Base(depPath)
+		if strings.HasPrefix(deploymentID, ".") {
+			// Temporary topologies used to check deployments updates
+			continue
+		}
 		deps = append(deps, deploymentID)
 	}
 	return deps, nil
@@ -150,3 +150,30 @@ func testDeleteDeployment(t *testing.T) {
 	assert.True(t, exists)
 
 }
+
+func testCheckAndSetDeploymentStatus(t *testing.T) {
+	ctx := context.Background()
+	deploymentID := testutil.BuildDeploymentID(t)
+	defer consulutil.Delete(path.Join(consulutil.DeploymentKVPrefix, deploymentID), true)
+
+	_, _, err := CheckAndSetDeploymentStatus(ctx, deploymentID, UPDATE_IN_PROGRESS, DEPLOYED)
+	require.True(t, IsDeploymentNotFoundError(err), "unexpected error %v", err)
+
+	require.NoError(t, SetDeploymentStatus(ctx, deploymentID, INITIAL))
+	require.NoError(t, SetDeploymentStatus(ctx, deploymentID, DEPLOYED))
+
+	previous, ok, err := CheckAndSetDeploymentStatus(ctx, deploymentID, UPDATE_IN_PROGRESS, DEPLOYED, UPDATED)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, DEPLOYED, previous)
+
+	// A concurrent update is rejected
+	current, ok, err := CheckAndSetDeploymentStatus(ctx, deploymentID, UPDATE_IN_PROGRESS, DEPLOYED, UPDATED)
+	require.NoError(t, err)
+	require.False(t, ok)
+	require.Equal(t, UPDATE_IN_PROGRESS, current)
+
+	status, err := GetDeploymentStatus(ctx, deploymentID)
+	require.NoError(t, err)
+	require.Equal(t, UPDATE_IN_PROGRESS, status)
+}
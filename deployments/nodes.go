@@ -858,6 +858,15 @@ func GetNodeMetadata(ctx context.Context, deploymentID, nodeName, key string) (b
 	return true, value, nil
 }
 
+// GetNodeMetadataMap retrieves all the metadata defined on a node template
+func GetNodeMetadataMap(ctx context.Context, deploymentID, nodeName string) (map[string]string, error) {
+	node, err := getNodeTemplate(ctx, deploymentID, nodeName)
+	if err != nil {
+		return nil, err
+	}
+	return node.Metadata, nil
+}
+
 // NodeHasAttribute returns true if the node type has an attribute named attributeName defined
 //
 // exploreParents switch enable attribute check on parent types
@@ -17,6 +17,8 @@ package deployments
 import (
 	"context"
 	"fmt"
+	"path"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -41,6 +43,9 @@ const funcKeywordTARGET string = "TARGET"
 const funcKeywordRTARGET string = "R_TARGET"
 const funcKeywordREQTARGET string = "REQ_TARGET"
 
+// getArtifactLocalFileLocation is the get_artifact location keyword meaning that the artifact path is left unchanged
+const getArtifactLocalFileLocation string = "LOCAL_FILE"
+
 // functionResolver is used to resolve TOSCA functions
 type functionResolver struct {
 	deploymentID     string
@@ -121,6 +126,15 @@ func (fr *functionResolver) resolveFunction(ctx context.Context, fn *tosca.Funct
 	switch fn.Operator {
 	case tosca.ConcatOperator:
 		return &TOSCAValue{Value: strings.Join(operands, ""), IsSecret: hasSecret}, nil
+	case tosca.TokenOperator:
+		res, err := fr.resolveToken(operands)
+		return &TOSCAValue{Value: res, IsSecret: hasSecret}, err
+	case tosca.GetNodesOfTypeOperator:
+		res, err := fr.resolveGetNodesOfType(ctx, operands)
+		return &TOSCAValue{Value: res}, err
+	case tosca.GetArtifactOperator:
+		res, err := fr.resolveGetArtifact(ctx, operands)
+		return &TOSCAValue{Value: res}, err
 	case tosca.GetInputOperator:
 		res, err := fr.resolveGetInput(ctx, operands)
 		return &TOSCAValue{Value: res}, err
@@ -154,6 +168,108 @@ func (fr *functionResolver) resolveGetInput(ctx context.Context, operands []stri
 	return GetInputValue(ctx, fr.inputs, fr.deploymentID, args[0], args[1:]...)
 }
 
+func (fr *functionResolver) resolveToken(operands []string) (string, error) {
+	if len(operands) != 3 {
+		return "", errors.Errorf("expecting exactly three parameters for a token function")
+	}
+	if operands[1] == "" {
+		return "", errors.Errorf(`Can't resolve "token: [%s]" without token characters`, strings.Join(operands, ", "))
+	}
+	index, err := strconv.Atoi(operands[2])
+	if err != nil {
+		return "", errors.Wrapf(err, `Can't resolve "token: [%s]" substring index should be an integer`, strings.Join(operands, ", "))
+	}
+	tokens := splitOnAnyOf(operands[0], operands[1])
+	if index < 0 || index >= len(tokens) {
+		return "", errors.Errorf(`Can't resolve "token: [%s]" substring index %d out of range, %q contains %d tokens`, strings.Join(operands, ", "), index, operands[0], len(tokens))
+	}
+	return tokens[index], nil
+}
+
+// splitOnAnyOf splits s around each instance of any of the characters in chars.
+//
+// Contrary to strings.FieldsFunc, empty tokens are kept so indexes remain stable.
+func splitOnAnyOf(s, chars string) []string {
+	tokens := make([]string, 0)
+	start := 0
+	for i, r := range s {
+		if strings.ContainsRune(chars, r) {
+			tokens = append(tokens, s[start:i])
+			start = i + len(string(r))
+		}
+	}
+	return append(tokens, s[start:])
+}
+
+func (fr *functionResolver) resolveGetNodesOfType(ctx context.Context, operands []string) ([]string, error) {
+	if len(operands) != 1 {
+		return nil, errors.Errorf("expecting exactly one parameter for a get_nodes_of_type function")
+	}
+	nodes, err := GetNodes(ctx, fr.deploymentID)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]string, 0)
+	for _, node := range nodes {
+		ok, err := IsNodeDerivedFrom(ctx, fr.deploymentID, node, operands[0])
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			result = append(result, node)
+		}
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+func (fr *functionResolver) resolveGetArtifact(ctx context.Context, operands []string) (string, error) {
+	funcString := fmt.Sprintf("get_artifact: [%s]", strings.Join(operands, ", "))
+	if len(operands) < 2 || len(operands) > 4 {
+		return "", errors.Errorf("expecting between two and four parameters for a get_artifact function (%s)", funcString)
+	}
+	entity := operands[0]
+	if entity == funcKeywordHOST && fr.requirementIndex != "" {
+		return "", errors.Errorf(`Can't resolve %q %s keyword is not supported in the context of a relationship`, funcString, funcKeywordHOST)
+	} else if fr.requirementIndex == "" && (entity == funcKeywordSOURCE || entity == funcKeywordTARGET || entity == funcKeywordRTARGET) {
+		return "", errors.Errorf(`Can't resolve %q %s keyword is supported only in the context of a relationship`, funcString, entity)
+	}
+
+	var err error
+	var actualNode string
+	switch entity {
+	case funcKeywordSELF, funcKeywordSOURCE:
+		actualNode = fr.nodeName
+	case funcKeywordHOST:
+		actualNode, err = GetHostedOnNode(ctx, fr.deploymentID, fr.nodeName)
+	case funcKeywordTARGET, funcKeywordRTARGET:
+		actualNode, err = GetTargetNodeForRequirement(ctx, fr.deploymentID, fr.nodeName, fr.requirementIndex)
+	default:
+		actualNode = entity
+	}
+	if err != nil {
+		return "", err
+	}
+	if actualNode == "" {
+		return "", errors.Errorf(`Can't resolve %q without a specified node name`, funcString)
+	}
+
+	artifacts, err := GetFileArtifactsForNode(ctx, fr.deploymentID, actualNode)
+	if err != nil {
+		return "", err
+	}
+	artifactPath, ok := artifacts[operands[1]]
+	if !ok {
+		return "", errors.Errorf(`Can't resolve %q artifact %q not found for node %q`, funcString, operands[1], actualNode)
+	}
+	// The optional remove parameter is only meaningful for orchestrators copying
+	// artifacts on demand, artifacts are managed by operation executors in Yorc
+	if len(operands) > 2 && operands[2] != "" && operands[2] != getArtifactLocalFileLocation {
+		return path.Join(operands[2], path.Base(artifactPath)), nil
+	}
+	return artifactPath, nil
+}
+
 func (fr *functionResolver) resolveGetOperationOutput(ctx context.Context, operands []string) (string, error) {
 	if len(operands) != 4 {
 		return "", errors.Errorf("expecting exactly four parameters for a get_operation_output function")
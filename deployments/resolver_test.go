@@ -44,6 +44,10 @@ func testResolver(t *testing.T) {
 		testResolveComplex(t)
 	})
 
+	t.Run("deployments/resolver/testResolveTokenNodesOfTypeAndArtifact", func(t *testing.T) {
+		testResolveTokenNodesOfTypeAndArtifact(t)
+	})
+
 	t.Run("TestResolveSecret", func(t *testing.T) {
 		testResolveSecret(t)
 	})
@@ -182,6 +186,66 @@ func testResolveComplex(t *testing.T) {
 	}
 }
 
+func testResolveTokenNodesOfTypeAndArtifact(t *testing.T) {
+	ctx := context.Background()
+	deploymentID := testutil.BuildDeploymentID(t)
+	err := StoreDeploymentDefinition(context.Background(), deploymentID, "testdata/token_artifact_functions.yaml")
+	require.Nil(t, err, "Failed to parse testdata/token_artifact_functions.yaml definition: %+v", err)
+	r := resolver(deploymentID)
+
+	type data struct {
+		nodeName         string
+		instanceName     string
+		requirementIndex string
+	}
+	type args struct {
+		functionAsString string
+	}
+	resolverTests := []struct {
+		name    string
+		data    data
+		args    args
+		wantErr bool
+		want    string
+	}{
+		{"ResolveTokenHost", data{"Endpoint1", "", ""}, args{`{token: [get_property: [SELF, endpoint], ":", 0]}`}, false, `10.0.0.1`},
+		{"ResolveTokenPort", data{"Endpoint1", "", ""}, args{`{token: [get_property: [SELF, endpoint], ":", 1]}`}, false, `8800`},
+		{"ResolveTokenSeveralChars", data{"Endpoint1", "", ""}, args{`{token: [get_property: [SELF, url], ":/", 5]}`}, false, `path`},
+		{"ResolveTokenKeepsEmptyTokens", data{"Endpoint2", "", ""}, args{`{token: [get_property: [SELF, endpoint], ":", 2]}`}, false, `1`},
+		{"ResolveTokenLiteral", data{"Endpoint1", "", ""}, args{`{token: ["a,b;c", ",;", 2]}`}, false, `c`},
+		{"ResolveTokenInConcat", data{"Endpoint1", "", ""}, args{`{concat: ["port-", token: [get_property: [SELF, endpoint], ":", 1]]}`}, false, `port-8800`},
+		{"ResolveTokenOutOfRange", data{"Endpoint1", "", ""}, args{`{token: [get_property: [SELF, endpoint], ":", 2]}`}, true, ``},
+		{"ResolveTokenInvalidIndex", data{"Endpoint1", "", ""}, args{`{token: [get_property: [SELF, endpoint], ":", one]}`}, true, ``},
+		{"ResolveTokenMissingParams", data{"Endpoint1", "", ""}, args{`{token: [get_property: [SELF, endpoint], ":"]}`}, true, ``},
+		{"ResolveGetNodesOfType", data{"Endpoint1", "", ""}, args{`{get_nodes_of_type: yorc.tests.nodes.Endpoint}`}, false, `["Endpoint1","Endpoint2"]`},
+		{"ResolveGetNodesOfTypeDerived", data{"Endpoint1", "", ""}, args{`{get_nodes_of_type: yorc.tests.nodes.SubEndpoint}`}, false, `["Endpoint2"]`},
+		{"ResolveGetNodesOfTypeNormative", data{"Endpoint1", "", ""}, args{`{get_nodes_of_type: tosca.nodes.Root}`}, false, `["Compute","Endpoint1","Endpoint2"]`},
+		{"ResolveGetNodesOfTypeNone", data{"Endpoint1", "", ""}, args{`{get_nodes_of_type: tosca.nodes.BlockStorage}`}, false, `[]`},
+		{"ResolveGetArtifactSelf", data{"Endpoint1", "", ""}, args{`{get_artifact: [SELF, config]}`}, false, `config/endpoint.conf`},
+		{"ResolveGetArtifactFromType", data{"Endpoint2", "", ""}, args{`{get_artifact: [SELF, scripts]}`}, false, `scripts/type_scripts.tar.gz`},
+		{"ResolveGetArtifactNamedNode", data{"Endpoint2", "", ""}, args{`{get_artifact: [Endpoint1, config]}`}, false, `config/endpoint.conf`},
+		{"ResolveGetArtifactLocalFile", data{"Endpoint1", "", ""}, args{`{get_artifact: [SELF, config, LOCAL_FILE]}`}, false, `config/endpoint.conf`},
+		{"ResolveGetArtifactLocation", data{"Endpoint1", "", ""}, args{`{get_artifact: [SELF, config, /etc/endpoint, false]}`}, false, `/etc/endpoint/endpoint.conf`},
+		{"ResolveGetArtifactInConcat", data{"Endpoint1", "", ""}, args{`{concat: ["file://", get_artifact: [SELF, config]]}`}, false, `file://config/endpoint.conf`},
+		{"ResolveGetArtifactRelationshipTarget", data{"Endpoint1", "0", "0"}, args{`{get_artifact: [TARGET, config]}`}, true, ``},
+		{"ResolveGetArtifactAbsent", data{"Endpoint1", "", ""}, args{`{get_artifact: [SELF, absent]}`}, true, ``},
+		{"ResolveGetArtifactTargetOutsideRelationship", data{"Endpoint1", "", ""}, args{`{get_artifact: [TARGET, config]}`}, true, ``},
+	}
+	for _, tt := range resolverTests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := generateToscaValueAssignmentFromString(t, tt.args.functionAsString)
+			got, err := r.context(withNodeName(tt.data.nodeName), withInstanceName(tt.data.instanceName), withRequirementIndex(tt.data.requirementIndex)).resolveFunction(ctx, f)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("resolveFunction() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err == nil && got.RawString() != tt.want {
+				t.Errorf("resolveFunction() = %q, want %q", got.RawString(), tt.want)
+			}
+		})
+	}
+}
+
 type vaultClientMock struct {
 	id              string
 	result          string
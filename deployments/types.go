@@ -279,6 +279,17 @@ func GetParentType(ctx context.Context, deploymentID, typeName string) (string,
 	return typ.DerivedFrom, nil
 }
 
+// GetTypeMetadata returns the metadata defined on a given type
+//
+// Metadata of parent types are not returned
+func GetTypeMetadata(ctx context.Context, deploymentID, typeName string) (map[string]string, error) {
+	typ, err := getTypeBaseInfo(ctx, deploymentID, typeName, "")
+	if err != nil {
+		return nil, err
+	}
+	return typ.Metadata, nil
+}
+
 // IsTypeDerivedFrom traverses 'derived_from' to check if type derives from another type
 func IsTypeDerivedFrom(ctx context.Context, deploymentID, nodeType, derives string) (bool, error) {
 	if nodeType == derives {
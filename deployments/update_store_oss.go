@@ -0,0 +1,245 @@
+// Copyright 2021 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build !premium
+
+package deployments
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"sort"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+
+	"github.com/ystia/yorc/v4/deployments/store"
+	"github.com/ystia/yorc/v4/helper/collections"
+	"github.com/ystia/yorc/v4/helper/consulutil"
+	"github.com/ystia/yorc/v4/log"
+	"github.com/ystia/yorc/v4/tosca"
+)
+
+// TopologyUpdate describes the differences between the stored topology of a deployment
+// and a new definition of this topology
+type TopologyUpdate struct {
+	AddedNodes       []string `json:"added_nodes,omitempty"`
+	RemovedNodes     []string `json:"removed_nodes,omitempty"`
+	UpdatedNodes     []string `json:"updated_nodes,omitempty"`
+	AddedWorkflows   []string `json:"added_workflows,omitempty"`
+	RemovedWorkflows []string `json:"removed_workflows,omitempty"`
+}
+
+// ComputeTopologyUpdate returns the differences between the stored topology of the given deployment
+// and the TOSCA definition located at defPath.
+//
+// The TOSCA constraints of the updated definition are checked, violations are reported in an error
+// that could be identified using IsConstraintsViolationsError.
+// The stored topology of the deployment is not modified by this function.
+func ComputeTopologyUpdate(ctx context.Context, deploymentID, defPath string) (*TopologyUpdate, error) {
+	topology, err := readTopologyDefinition(defPath)
+	if err != nil {
+		return nil, err
+	}
+	update, err := computeTopologyUpdate(ctx, deploymentID, topology)
+	if err != nil {
+		return nil, err
+	}
+	return update, checkUpdatedTopologyConstraints(ctx, deploymentID, topology, defPath)
+}
+
+// UpdateDeploymentDefinition replaces the stored topology of the given deployment by the TOSCA definition
+// located at defPath.
+//
+// Removed nodes are deleted with their instances, removed workflows are deleted and instances
+// are created for added nodes. Instances of other nodes are kept as is.
+// It is up to the caller to run the uninstall workflow on removed nodes before calling this function
+// and the install workflow on added nodes after.
+func UpdateDeploymentDefinition(ctx context.Context, deploymentID, defPath string) (*TopologyUpdate, error) {
+	topology, err := readTopologyDefinition(defPath)
+	if err != nil {
+		return nil, err
+	}
+	update, err := computeTopologyUpdate(ctx, deploymentID, topology)
+	if err != nil {
+		return nil, err
+	}
+	// Constraints are checked before any modification to not leave a half-updated deployment
+	err = checkUpdatedTopologyConstraints(ctx, deploymentID, topology, defPath)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, nodeName := range update.RemovedNodes {
+		err = deleteNodeAndInstances(ctx, deploymentID, nodeName)
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, wfName := range update.RemovedWorkflows {
+		err = DeleteWorkflow(ctx, deploymentID, wfName)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = store.Deployment(ctx, topology, deploymentID, filepath.Dir(defPath))
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to store updated TOSCA Definition for deployment with id %q, (file path %q)", deploymentID, defPath)
+	}
+
+	nodes, err := GetNodes(ctx, deploymentID)
+	if err != nil {
+		return nil, err
+	}
+	err = PostDeploymentDefinitionStorageProcess(ctx, deploymentID, nodes)
+	if err != nil {
+		return nil, err
+	}
+	// Only new nodes get instances, existing ones keep their state
+	err = enhanceTopology(ctx, deploymentID, update.AddedNodes)
+	if err != nil {
+		return nil, err
+	}
+	// Requirements of updated nodes may target new nodes
+	_, errGroup, consulStore := consulutil.WithContext(ctx)
+	for _, nodeName := range update.UpdatedNodes {
+		err = createRelationshipInstances(ctx, consulStore, deploymentID, nodeName)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return update, errGroup.Wait()
+}
+
+// updateCheckDeploymentID returns the ID under which an updated topology is stored to check its constraints.
+//
+// Deployments IDs can't contain dots so this can't conflict with an existing deployment.
+func updateCheckDeploymentID(deploymentID string) string {
+	return "." + deploymentID + ".update"
+}
+
+// checkUpdatedTopologyConstraints checks the TOSCA constraints of an updated topology without modifying the
+// stored topology of the deployment.
+//
+// Constraints checks rely on stored definitions (including imports), so the updated topology is stored
+// under a temporary deployment ID which is removed once checked.
+func checkUpdatedTopologyConstraints(ctx context.Context, deploymentID string, topology tosca.Topology, defPath string) error {
+	checkID := updateCheckDeploymentID(deploymentID)
+	defer func() {
+		if err := DeleteDeployment(ctx, checkID); err != nil {
+			log.Printf("[WARNING] failed to remove topology stored to check update of deployment %q: %v", deploymentID, err)
+		}
+	}()
+	err := store.Deployment(ctx, topology, checkID, filepath.Dir(defPath))
+	if err != nil {
+		return errors.Wrapf(err, "Failed to store updated TOSCA Definition for deployment with id %q, (file path %q)", deploymentID, defPath)
+	}
+	nodes, err := GetNodes(ctx, checkID)
+	if err != nil {
+		return err
+	}
+	return checkTopologyConstraints(ctx, checkID, nodes)
+}
+
+func readTopologyDefinition(defPath string) (tosca.Topology, error) {
+	topology := tosca.Topology{}
+	defBytes, err := ioutil.ReadFile(defPath)
+	if err != nil {
+		return topology, errors.Wrapf(err, "Failed to open definition file %q", defPath)
+	}
+	err = yaml.Unmarshal(defBytes, &topology)
+	return topology, errors.Wrapf(err, "Failed to unmarshal yaml definition for file %q", defPath)
+}
+
+func computeTopologyUpdate(ctx context.Context, deploymentID string, topology tosca.Topology) (*TopologyUpdate, error) {
+	update := new(TopologyUpdate)
+	storedNodes, err := GetNodes(ctx, deploymentID)
+	if err != nil {
+		return nil, err
+	}
+	for nodeName, node := range topology.TopologyTemplate.NodeTemplates {
+		if !collections.ContainsString(storedNodes, nodeName) {
+			update.AddedNodes = append(update.AddedNodes, nodeName)
+			continue
+		}
+		storedNode, err := getNodeTemplate(ctx, deploymentID, nodeName)
+		if err != nil {
+			return nil, err
+		}
+		equal, err := equalNodeTemplates(storedNode, &node)
+		if err != nil {
+			return nil, err
+		}
+		if !equal {
+			update.UpdatedNodes = append(update.UpdatedNodes, nodeName)
+		}
+	}
+	for _, nodeName := range storedNodes {
+		if _, ok := topology.TopologyTemplate.NodeTemplates[nodeName]; !ok {
+			update.RemovedNodes = append(update.RemovedNodes, nodeName)
+		}
+	}
+
+	storedWorkflows, err := GetWorkflows(ctx, deploymentID)
+	if err != nil {
+		return nil, err
+	}
+	for wfName, wf := range topology.TopologyTemplate.Workflows {
+		// empty workflows are not stored
+		if wf.Steps != nil && !collections.ContainsString(storedWorkflows, wfName) {
+			update.AddedWorkflows = append(update.AddedWorkflows, wfName)
+		}
+	}
+	for _, wfName := range storedWorkflows {
+		if wf, ok := topology.TopologyTemplate.Workflows[wfName]; !ok || wf.Steps == nil {
+			update.RemovedWorkflows = append(update.RemovedWorkflows, wfName)
+		}
+	}
+
+	sort.Strings(update.AddedNodes)
+	sort.Strings(update.RemovedNodes)
+	sort.Strings(update.UpdatedNodes)
+	sort.Strings(update.AddedWorkflows)
+	sort.Strings(update.RemovedWorkflows)
+	return update, nil
+}
+
+// equalNodeTemplates compares node templates as they are stored
+func equalNodeTemplates(n1, n2 *tosca.NodeTemplate) (bool, error) {
+	b1, err := json.Marshal(n1)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to marshal node template")
+	}
+	b2, err := json.Marshal(n2)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to marshal node template")
+	}
+	return string(b1) == string(b2), nil
+}
+
+func deleteNodeAndInstances(ctx context.Context, deploymentID, nodeName string) error {
+	err := consulutil.Delete(path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology/relationship_instances", nodeName)+"/", true)
+	if err != nil {
+		return err
+	}
+	err = DeleteAllInstances(ctx, deploymentID, nodeName)
+	if err != nil {
+		return err
+	}
+	return DeleteNode(ctx, deploymentID, nodeName)
+}
@@ -17,17 +17,118 @@
 package deployments
 
 import (
+	"context"
+	"path"
+	"strings"
 	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ystia/yorc/v4/helper/consulutil"
+	"github.com/ystia/yorc/v4/tosca"
 )
 
-// Testing topology template metadata
+// Testing the update of a deployed topology
 func testTopologyUpdate(t *testing.T) {
+	ctx := context.Background()
+	deploymentID := strings.Replace(t.Name(), "/", "_", -1)
+	err := StoreDeploymentDefinition(ctx, deploymentID, "testdata/test_topology.yml")
+	require.NoError(t, err, "Failed to store topology")
+
+	update, err := ComputeTopologyUpdate(ctx, deploymentID, "testdata/test_topology_updated.yml")
+	require.NoError(t, err)
+	require.Equal(t, &TopologyUpdate{AddedWorkflows: []string{"newworkflow"}}, update)
+
+	update, err = UpdateDeploymentDefinition(ctx, deploymentID, "testdata/test_topology_updated.yml")
+	require.NoError(t, err)
+	require.Equal(t, &TopologyUpdate{AddedWorkflows: []string{"newworkflow"}}, update)
+	exist, value, err := consulutil.GetStringValue(path.Join(consulutil.DeploymentKVPrefix, deploymentID, "topology/metadata/template_version"))
+	require.NoError(t, err)
+	require.True(t, exist)
+	require.Equal(t, "0.1.0-update-SNAPSHOT", value)
+	wf, err := GetWorkflow(ctx, deploymentID, "newworkflow")
+	require.NoError(t, err)
+	require.NotNil(t, wf)
+
+	// Set a state on an existing instance to check it is kept
+	err = SetInstanceStateWithContextualLogs(ctx, deploymentID, "TestCompute", "0", tosca.NodeStateStarted)
+	require.NoError(t, err)
+
+	update, err = UpdateDeploymentDefinition(ctx, deploymentID, "testdata/test_topology_nodes_updated.yml")
+	require.NoError(t, err)
+	require.Equal(t, &TopologyUpdate{
+		AddedNodes:       []string{"TestCompute2"},
+		UpdatedNodes:     []string{"TestContainer"},
+		RemovedWorkflows: []string{"newworkflow"},
+	}, update)
+	instances, err := GetNodeInstancesIds(ctx, deploymentID, "TestCompute2")
+	require.NoError(t, err)
+	require.Equal(t, []string{"0"}, instances)
+	state, err := GetInstanceState(ctx, deploymentID, "TestCompute", "0")
+	require.NoError(t, err)
+	require.Equal(t, tosca.NodeStateStarted, state)
+	port, err := GetNodePropertyValue(ctx, deploymentID, "TestContainer", "port")
+	require.NoError(t, err)
+	require.NotNil(t, port)
+	require.Equal(t, "8080", port.RawString())
+	wf, err = GetWorkflow(ctx, deploymentID, "newworkflow")
+	require.NoError(t, err)
+	require.Nil(t, wf)
 
-	// Topology update is a premium feature
+	update, err = UpdateDeploymentDefinition(ctx, deploymentID, "testdata/test_topology.yml")
+	require.NoError(t, err)
+	require.Equal(t, &TopologyUpdate{
+		RemovedNodes: []string{"TestCompute2"},
+		UpdatedNodes: []string{"TestContainer"},
+	}, update)
+	nodes, err := GetNodes(ctx, deploymentID)
+	require.NoError(t, err)
+	require.NotContains(t, nodes, "TestCompute2")
+	instances, err = GetNodeInstancesIds(ctx, deploymentID, "TestCompute2")
+	require.NoError(t, err)
+	require.Len(t, instances, 0)
 }
 
-// Testing topology template metadata
+// Testing the update of a deployed topology with a missing definition
 func testTopologyBadUpdate(t *testing.T) {
+	ctx := context.Background()
+	deploymentID := strings.Replace(t.Name(), "/", "_", -1)
+	err := StoreDeploymentDefinition(ctx, deploymentID, "testdata/test_topology.yml")
+	require.NoError(t, err, "Failed to store topology")
+
+	_, err = ComputeTopologyUpdate(ctx, deploymentID, "testdata/does_not_exist.yml")
+	require.Error(t, err)
+	_, err = UpdateDeploymentDefinition(ctx, deploymentID, "testdata/does_not_exist.yml")
+	require.Error(t, err)
+
+	nodes, err := GetNodes(ctx, deploymentID)
+	require.NoError(t, err)
+	require.Len(t, nodes, 5)
+}
+
+// Testing that an update violating TOSCA constraints is rejected before modifying the stored topology
+func testTopologyUpdateConstraintsViolation(t *testing.T) {
+	ctx := context.Background()
+	deploymentID := strings.Replace(t.Name(), "/", "_", -1)
+	err := StoreDeploymentDefinition(ctx, deploymentID, "testdata/test_topology.yml")
+	require.NoError(t, err, "Failed to store topology")
+
+	_, err = ComputeTopologyUpdate(ctx, deploymentID, "testdata/test_topology_constraints_violation.yml")
+	require.Error(t, err)
+	require.True(t, IsConstraintsViolationsError(err), "unexpected error %v", err)
+	_, err = UpdateDeploymentDefinition(ctx, deploymentID, "testdata/test_topology_constraints_violation.yml")
+	require.Error(t, err)
+	require.True(t, IsConstraintsViolationsError(err), "unexpected error %v", err)
+
+	port, err := GetNodePropertyValue(ctx, deploymentID, "TestContainer", "port")
+	require.NoError(t, err)
+	require.NotNil(t, port)
+	require.Equal(t, "80", port.RawString())
 
-	// Topology update is a premium feature
+	deploymentsIDs, err := GetDeploymentsIDs(ctx)
+	require.NoError(t, err)
+	require.NotContains(t, deploymentsIDs, updateCheckDeploymentID(deploymentID))
+	nodes, err := GetNodes(ctx, updateCheckDeploymentID(deploymentID))
+	require.NoError(t, err)
+	require.Len(t, nodes, 0, "temporary topology should have been removed")
 }
@@ -64,6 +64,11 @@ func GetWorkflow(ctx context.Context, deploymentID, workflowName string) (*tosca
 	return wf, nil
 }
 
+// StoreWorkflow stores the given workflow definition for a deployment
+func StoreWorkflow(ctx context.Context, deploymentID, workflowName string, workflow *tosca.Workflow) error {
+	return internal.StoreWorkflow(ctx, deploymentID, workflowName, workflow)
+}
+
 // DeleteWorkflow deletes the given workflow from the Consul store
 func DeleteWorkflow(ctx context.Context, deploymentID, workflowName string) error {
 	return storage.GetStore(types.StoreTypeDeployment).Delete(ctx, path.Join(consulutil.DeploymentKVPrefix, deploymentID,
@@ -78,6 +78,9 @@ func TestRunConsulEventsPackageTests(t *testing.T) {
 		t.Run("TestAttributeValueChange", func(t *testing.T) {
 			testconsulAttributeValueChange(t)
 		})
+		t.Run("TestMutedDeployment", func(t *testing.T) {
+			testMutedDeployment(t)
+		})
 		t.Run("TestPurgeDeploymentEvents", func(t *testing.T) {
 			testPurgeDeploymentEvents(t)
 		})
@@ -227,6 +227,38 @@ func PublishAndLogWorkflowStatusChange(ctx context.Context, deploymentID, taskID
 	return id, nil
 }
 
+// PublishAndLogHostsPoolHostStatusChange publishes a status change for a host of a hosts pool location allocated to a given deployment
+// and log this change into the log API
+//
+// PublishAndLogHostsPoolHostStatusChange returns the published event id
+func PublishAndLogHostsPoolHostStatusChange(ctx context.Context, deploymentID, locationName, hostname, status, message string) (string, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	info := buildInfoFromContext(ctx)
+	info[ELocationName] = locationName
+	info[EHostname] = hostname
+	info[EMessage] = message
+	e, err := newStatusChange(ctx, StatusChangeTypeHostsPoolHost, info, deploymentID, strings.ToLower(status))
+	if err != nil {
+		return "", err
+	}
+	id, err := e.register()
+	if err != nil {
+		return "", err
+	}
+	logLevel := LogLevelINFO
+	if strings.ToLower(status) == "error" {
+		logLevel = LogLevelWARN
+	}
+	if message != "" {
+		WithContextOptionalFields(ctx).NewLogEntry(logLevel, deploymentID).Registerf("Status for host %q of hosts pool location %q changed to %q: %s", hostname, locationName, status, message)
+	} else {
+		WithContextOptionalFields(ctx).NewLogEntry(logLevel, deploymentID).Registerf("Status for host %q of hosts pool location %q changed to %q", hostname, locationName, status)
+	}
+	return id, nil
+}
+
 func getLogsOrEvents(ctx context.Context, deploymentID string, waitIndex uint64, timeout time.Duration, isEvents bool) ([]json.RawMessage, uint64, error) {
 	logsOrEvents := make([]json.RawMessage, 0)
 
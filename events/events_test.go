@@ -199,6 +199,35 @@ func testConsulPubSubNewNodeEvents(t *testing.T) {
 
 }
 
+func testMutedDeployment(t *testing.T) {
+	ctx := context.Background()
+	deploymentID := testutil.BuildDeploymentID(t)
+	var notified []StatusChange
+	RegisterStatusChangeListener(t.Name(), func(event StatusChange) {
+		if event.DeploymentID == deploymentID {
+			notified = append(notified, event)
+		}
+	})
+	defer UnregisterStatusChangeListener(t.Name())
+
+	MuteDeployment(deploymentID)
+	_, err := PublishAndLogDeploymentStatusChange(ctx, deploymentID, "initial")
+	require.NoError(t, err)
+	UnmuteDeployment(deploymentID)
+
+	kvps, _, err := consulutil.GetKV().List(path.Join(consulutil.EventsPrefix, deploymentID), nil)
+	require.NoError(t, err)
+	assert.Len(t, kvps, 0)
+	kvps, _, err = consulutil.GetKV().List(path.Join(consulutil.LogsPrefix, deploymentID), nil)
+	require.NoError(t, err)
+	assert.Len(t, kvps, 0)
+	assert.Len(t, notified, 0)
+
+	_, err = PublishAndLogDeploymentStatusChange(ctx, deploymentID, "deployed")
+	require.NoError(t, err)
+	assert.Len(t, notified, 1)
+}
+
 func testconsulDeploymentStatusChange(t *testing.T) {
 	t.Parallel()
 	ctx := context.Background()
@@ -0,0 +1,80 @@
+// Copyright 2021 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"encoding/json"
+	"sync"
+)
+
+// StatusChange is a published status change event
+type StatusChange struct {
+	Type         StatusChangeType
+	DeploymentID string
+	Status       string
+	// Payload is the JSON representation of the event as returned by the events API
+	Payload json.RawMessage
+}
+
+// StatusChangeListener is called each time a status change event is published
+//
+// Listeners are called synchronously by the publisher so they should not block.
+type StatusChangeListener func(event StatusChange)
+
+var statusChangeListeners = make(map[string]StatusChangeListener)
+var statusChangeListenersLock sync.RWMutex
+
+// RegisterStatusChangeListener registers a listener under the given name, replacing any listener
+// previously registered with the same name
+func RegisterStatusChangeListener(name string, listener StatusChangeListener) {
+	statusChangeListenersLock.Lock()
+	defer statusChangeListenersLock.Unlock()
+	statusChangeListeners[name] = listener
+}
+
+// UnregisterStatusChangeListener removes the listener registered under the given name
+func UnregisterStatusChangeListener(name string) {
+	statusChangeListenersLock.Lock()
+	defer statusChangeListenersLock.Unlock()
+	delete(statusChangeListeners, name)
+}
+
+// mutedDeployments are deployments for which events and logs are not published
+var mutedDeployments sync.Map
+
+// MuteDeployment disables the publication of events and logs of a deployment until UnmuteDeployment is called
+//
+// This allows to store a deployment only to analyze it, like for a dry run, without notifying anyone.
+func MuteDeployment(deploymentID string) {
+	mutedDeployments.Store(deploymentID, struct{}{})
+}
+
+// UnmuteDeployment enables again the publication of events and logs of a deployment
+func UnmuteDeployment(deploymentID string) {
+	mutedDeployments.Delete(deploymentID)
+}
+
+func isMutedDeployment(deploymentID string) bool {
+	_, muted := mutedDeployments.Load(deploymentID)
+	return muted
+}
+
+func notifyStatusChangeListeners(event StatusChange) {
+	statusChangeListenersLock.RLock()
+	defer statusChangeListenersLock.RUnlock()
+	for _, listener := range statusChangeListeners {
+		listener(event)
+	}
+}
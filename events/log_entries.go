@@ -155,6 +155,9 @@ func (e LogEntry) Register(content []byte) {
 	if e.deploymentID == "" {
 		log.Panic("The deploymentID parameter must be filled")
 	}
+	if isMutedDeployment(e.deploymentID) {
+		return
+	}
 	e.content = content
 
 	// Get the timestamp
@@ -42,6 +42,7 @@ Workflow
 WorkflowStep
 AlienTask
 AttributeValue
+HostsPoolHost
 )
 */
 type StatusChangeType int
@@ -83,6 +84,12 @@ const (
 	EAttributeName
 	// EAttributeValue is event information related to attribute value
 	EAttributeValue
+	// ELocationName is event information related to location name
+	ELocationName
+	// EHostname is event information related to a host of a hosts pool
+	EHostname
+	// EMessage is event information related to a message explaining the status
+	EMessage
 )
 
 func (i InfoType) String() string {
@@ -121,6 +128,12 @@ func (i InfoType) String() string {
 		return "attribute"
 	case EAttributeValue:
 		return "value"
+	case ELocationName:
+		return "location"
+	case EHostname:
+		return "hostname"
+	case EMessage:
+		return "message"
 	}
 	return ""
 }
@@ -152,6 +165,9 @@ type WorkflowStepInfo struct {
 // The content is JSON format
 func (e *statusChange) register() (string, error) {
 	e.timestamp = time.Now().Format(time.RFC3339Nano)
+	if isMutedDeployment(e.deploymentID) {
+		return e.timestamp, nil
+	}
 	eventsPrefix := path.Join(consulutil.EventsPrefix, e.deploymentID)
 
 	// For presentation purpose, each field is in flat json object
@@ -165,6 +181,7 @@ func (e *statusChange) register() (string, error) {
 	if err != nil {
 		return "", err
 	}
+	notifyStatusChangeListeners(StatusChange{Type: e.eventType, DeploymentID: e.deploymentID, Status: e.status, Payload: val})
 	return e.timestamp, nil
 }
 
@@ -208,6 +225,7 @@ func (e *statusChange) check() error {
 		StatusChangeTypeWorkflow:       {ETaskID},
 		StatusChangeTypeWorkflowStep:   {ETaskID, EWorkflowID, ENodeID, EWorkflowStepID, EInstanceID},
 		StatusChangeTypeAlienTask:      {ETaskID, EWorkflowID, ENodeID, EWorkflowStepID, EInstanceID, ETaskExecutionID},
+		StatusChangeTypeHostsPoolHost:  {ELocationName, EHostname},
 	}
 	// Check mandatory info in function of status change type
 	if mandatoryInfos, is := mandatoryMap[e.eventType]; is {
@@ -39,9 +39,11 @@ const (
 	StatusChangeTypeAlienTask
 	// StatusChangeTypeAttributeValue is a StatusChangeType of type AttributeValue
 	StatusChangeTypeAttributeValue
+	// StatusChangeTypeHostsPoolHost is a StatusChangeType of type HostsPoolHost
+	StatusChangeTypeHostsPoolHost
 )
 
-const _StatusChangeTypeName = "InstanceDeploymentCustomCommandScalingWorkflowWorkflowStepAlienTaskAttributeValue"
+const _StatusChangeTypeName = "InstanceDeploymentCustomCommandScalingWorkflowWorkflowStepAlienTaskAttributeValueHostsPoolHost"
 
 var _StatusChangeTypeMap = map[StatusChangeType]string{
 	0: _StatusChangeTypeName[0:8],
@@ -52,6 +54,7 @@ var _StatusChangeTypeMap = map[StatusChangeType]string{
 	5: _StatusChangeTypeName[46:58],
 	6: _StatusChangeTypeName[58:67],
 	7: _StatusChangeTypeName[67:81],
+	8: _StatusChangeTypeName[81:94],
 }
 
 // String implements the Stringer interface.
@@ -79,6 +82,8 @@ var _StatusChangeTypeValue = map[string]StatusChangeType{
 	strings.ToLower(_StatusChangeTypeName[58:67]): 6,
 	_StatusChangeTypeName[67:81]:                  7,
 	strings.ToLower(_StatusChangeTypeName[67:81]): 7,
+	_StatusChangeTypeName[81:94]:                  8,
+	strings.ToLower(_StatusChangeTypeName[81:94]): 8,
 }
 
 // ParseStatusChangeType attempts to convert a string to a StatusChangeType
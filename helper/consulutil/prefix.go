@@ -61,3 +61,9 @@ const PurgedDeploymentKVPrefix string = yorcPrefix + "/purged"
 
 // StoresPrefix is the prefix in Consul KV store for stores
 const StoresPrefix string = yorcPrefix + "/stores"
+
+// NotificationsKVPrefix is the prefix in Consul KV store for notifications
+const NotificationsKVPrefix string = yorcPrefix + "/notifications"
+
+// SSHHostKeysKVPrefix is the prefix in Consul KV store for SSH host keys recorded on first use
+const SSHHostKeysKVPrefix string = yorcPrefix + "/ssh_host_keys"
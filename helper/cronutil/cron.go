@@ -0,0 +1,224 @@
+// Copyright 2021 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cronutil allows to parse cron expressions and to compute their activation times
+//
+// Standard 5 fields expressions (minute, hour, day of month, month and day of week) are supported
+// as well as the @yearly, @annually, @monthly, @weekly, @daily, @midnight and @hourly descriptors.
+package cronutil
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Schedule is a parsed cron expression
+type Schedule struct {
+	minute, hour, dom, month, dow uint64
+	// domStar and dowStar are used to apply the standard cron behavior:
+	// when both day of month and day of week are restricted a day matches if any of them matches.
+	domStar, dowStar bool
+}
+
+type field struct {
+	name     string
+	min, max uint
+	names    map[string]uint
+}
+
+var (
+	minutes = field{name: "minute", min: 0, max: 59}
+	hours   = field{name: "hour", min: 0, max: 23}
+	doms    = field{name: "day of month", min: 1, max: 31}
+	months  = field{name: "month", min: 1, max: 12, names: map[string]uint{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	// 7 is an alias for sunday
+	dows = field{name: "day of week", min: 0, max: 7, names: map[string]uint{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+var descriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// Parse parses a cron expression
+func Parse(expr string) (*Schedule, error) {
+	spec := strings.TrimSpace(expr)
+	if strings.HasPrefix(spec, "@") {
+		d, ok := descriptors[strings.ToLower(spec)]
+		if !ok {
+			return nil, errors.Errorf("unsupported cron descriptor %q", spec)
+		}
+		spec = d
+	}
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, errors.Errorf("invalid cron expression %q: expecting 5 fields (minute, hour, day of month, month, day of week) got %d", expr, len(fields))
+	}
+	s := new(Schedule)
+	var err error
+	if s.minute, _, err = parseField(fields[0], minutes); err != nil {
+		return nil, errors.Wrapf(err, "invalid cron expression %q", expr)
+	}
+	if s.hour, _, err = parseField(fields[1], hours); err != nil {
+		return nil, errors.Wrapf(err, "invalid cron expression %q", expr)
+	}
+	if s.dom, s.domStar, err = parseField(fields[2], doms); err != nil {
+		return nil, errors.Wrapf(err, "invalid cron expression %q", expr)
+	}
+	if s.month, _, err = parseField(fields[3], months); err != nil {
+		return nil, errors.Wrapf(err, "invalid cron expression %q", expr)
+	}
+	if s.dow, s.dowStar, err = parseField(fields[4], dows); err != nil {
+		return nil, errors.Wrapf(err, "invalid cron expression %q", expr)
+	}
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1
+	}
+	return s, nil
+}
+
+// parseField returns the bits set of the values matched by a cron field and if this field
+// matches any value
+func parseField(value string, f field) (uint64, bool, error) {
+	var bits uint64
+	star := false
+	for _, part := range strings.Split(value, ",") {
+		b, isStar, err := parseRange(part, f)
+		if err != nil {
+			return 0, false, err
+		}
+		bits |= b
+		star = star || isStar
+	}
+	return bits, star, nil
+}
+
+func parseRange(expr string, f field) (uint64, bool, error) {
+	rangeAndStep := strings.Split(expr, "/")
+	if len(rangeAndStep) > 2 {
+		return 0, false, errors.Errorf("invalid %s value %q", f.name, expr)
+	}
+	var start, end uint
+	star := false
+	var err error
+	switch {
+	case rangeAndStep[0] == "*" || rangeAndStep[0] == "?":
+		start, end = f.min, f.max
+		star = true
+	default:
+		bounds := strings.Split(rangeAndStep[0], "-")
+		if len(bounds) > 2 {
+			return 0, false, errors.Errorf("invalid %s range %q", f.name, expr)
+		}
+		if start, err = parseValue(bounds[0], f); err != nil {
+			return 0, false, err
+		}
+		end = start
+		if len(bounds) == 2 {
+			if end, err = parseValue(bounds[1], f); err != nil {
+				return 0, false, err
+			}
+		}
+	}
+	step := uint(1)
+	if len(rangeAndStep) == 2 {
+		s, err := strconv.ParseUint(rangeAndStep[1], 10, 8)
+		if err != nil || s == 0 {
+			return 0, false, errors.Errorf("invalid %s step %q", f.name, expr)
+		}
+		step = uint(s)
+		if !star && !strings.Contains(rangeAndStep[0], "-") {
+			// "N/step" means from N to the end of the range
+			end = f.max
+		}
+		// A stepped wildcard does not match any value
+		star = false
+	}
+	if start > end {
+		return 0, false, errors.Errorf("invalid %s range %q: start is after end", f.name, expr)
+	}
+	var bits uint64
+	for i := start; i <= end; i += step {
+		bits |= 1 << i
+	}
+	return bits, star, nil
+}
+
+func parseValue(value string, f field) (uint, error) {
+	if v, ok := f.names[strings.ToLower(value)]; ok {
+		return v, nil
+	}
+	v, err := strconv.ParseUint(value, 10, 8)
+	if err != nil {
+		return 0, errors.Errorf("invalid %s value %q", f.name, value)
+	}
+	if uint(v) < f.min || uint(v) > f.max {
+		return 0, errors.Errorf("%s value %d out of range [%d-%d]", f.name, v, f.min, f.max)
+	}
+	return uint(v), nil
+}
+
+// Next returns the first activation time of the schedule strictly after the given time
+//
+// Times are computed in the location of the given time, activation times that do not exist
+// due to a daylight saving time change are skipped. A zero time is returned if the schedule
+// could never be activated (for instance on the 30th of February).
+func (s *Schedule) Next(t time.Time) time.Time {
+	loc := t.Location()
+	t = t.Add(time.Minute - time.Duration(t.Second())*time.Second - time.Duration(t.Nanosecond()))
+	yearLimit := t.Year() + 5
+
+	for t.Year() <= yearLimit {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+func (s *Schedule) dayMatches(t time.Time) bool {
+	domMatch := s.dom&(1<<uint(t.Day())) != 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domStar || s.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
@@ -0,0 +1,90 @@
+// Copyright 2021 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cronutil
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		expr string
+	}{
+		{"Empty", ""},
+		{"TooFewFields", "* * * *"},
+		{"TooManyFields", "0 * * * * *"},
+		{"UnknownDescriptor", "@every5m"},
+		{"MinuteOutOfRange", "60 * * * *"},
+		{"HourOutOfRange", "0 24 * * *"},
+		{"DayOfMonthZero", "0 0 0 * *"},
+		{"BadMonthName", "0 0 1 foo *"},
+		{"ReversedRange", "0 10-5 * * *"},
+		{"ZeroStep", "*/0 * * * *"},
+		{"BadStep", "*/a * * * *"},
+		{"NegativeValue", "-1 * * * *"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.expr)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestScheduleNext(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	require.NoError(t, err)
+	from := time.Date(2021, time.June, 15, 10, 30, 45, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		expr string
+		from time.Time
+		want time.Time
+	}{
+		{"EveryMinute", "* * * * *", from, time.Date(2021, time.June, 15, 10, 31, 0, 0, time.UTC)},
+		{"EveryFiveMinutes", "*/5 * * * *", from, time.Date(2021, time.June, 15, 10, 35, 0, 0, time.UTC)},
+		{"Nightly", "0 2 * * *", from, time.Date(2021, time.June, 16, 2, 0, 0, 0, time.UTC)},
+		{"Daily", "@daily", from, time.Date(2021, time.June, 16, 0, 0, 0, 0, time.UTC)},
+		{"Hourly", "@HOURLY", from, time.Date(2021, time.June, 15, 11, 0, 0, 0, time.UTC)},
+		{"StrictlyAfter", "30 10 * * *", time.Date(2021, time.June, 15, 10, 30, 0, 0, time.UTC), time.Date(2021, time.June, 16, 10, 30, 0, 0, time.UTC)},
+		{"List", "0 8,12,18 * * *", from, time.Date(2021, time.June, 15, 12, 0, 0, 0, time.UTC)},
+		{"RangeWithStep", "0 0-12/6 * * *", from, time.Date(2021, time.June, 15, 12, 0, 0, 0, time.UTC)},
+		{"ValueWithStep", "0 20/2 * * *", from, time.Date(2021, time.June, 15, 20, 0, 0, 0, time.UTC)},
+		{"WeekDays", "0 9 * * MON-FRI", time.Date(2021, time.June, 18, 10, 0, 0, 0, time.UTC), time.Date(2021, time.June, 21, 9, 0, 0, 0, time.UTC)},
+		{"SundayAsSeven", "0 0 * * 7", from, time.Date(2021, time.June, 20, 0, 0, 0, 0, time.UTC)},
+		{"Monthly", "@monthly", from, time.Date(2021, time.July, 1, 0, 0, 0, 0, time.UTC)},
+		{"MonthName", "0 0 1 jan *", from, time.Date(2022, time.January, 1, 0, 0, 0, 0, time.UTC)},
+		{"LeapDay", "0 0 29 2 *", from, time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		{"DayOfMonthOrDayOfWeek", "0 0 1 * MON", from, time.Date(2021, time.June, 21, 0, 0, 0, 0, time.UTC)},
+		{"DayOfMonthAndStarDayOfWeek", "0 0 1 * ?", from, time.Date(2021, time.July, 1, 0, 0, 0, 0, time.UTC)},
+		{"Never", "0 0 30 2 *", from, time.Time{}},
+		{"TimeZone", "0 2 * * *", from.In(paris), time.Date(2021, time.June, 16, 2, 0, 0, 0, paris)},
+		{"SkippedByDaylightSavingTime", "30 2 * * *", time.Date(2021, time.March, 27, 12, 0, 0, 0, paris), time.Date(2021, time.March, 29, 2, 30, 0, 0, paris)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := Parse(tt.expr)
+			require.NoError(t, err)
+			got := s.Next(tt.from)
+			assert.True(t, tt.want.Equal(got), "expected %v got %v", tt.want, got)
+		})
+	}
+}
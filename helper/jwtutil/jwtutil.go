@@ -0,0 +1,328 @@
+// Copyright 2021 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package jwtutil allows to validate JSON Web Tokens signed by keys of a JSON Web Key Set (JWKS)
+//
+// Only asymmetric algorithms (RS256, RS384, RS512, ES256, ES384 and ES512) are supported
+// as this package is intended to validate tokens issued by an OpenID Connect provider.
+package jwtutil
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	// Register hash functions used by supported algorithms
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Claims are the claims of a JSON Web Token payload
+type Claims map[string]interface{}
+
+// GetString returns the value of a string claim or an empty string if it doesn't exist or is not a string
+func (c Claims) GetString(name string) string {
+	s, _ := c[name].(string)
+	return s
+}
+
+// GetStringSlice returns the value of a claim that could be either a single string or an array of strings
+func (c Claims) GetStringSlice(name string) []string {
+	switch v := c[name].(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		res := make([]string, 0, len(v))
+		for _, i := range v {
+			if s, ok := i.(string); ok {
+				res = append(res, s)
+			}
+		}
+		return res
+	}
+	return nil
+}
+
+func (c Claims) getTime(name string) (time.Time, bool) {
+	f, ok := c[name].(float64)
+	if !ok {
+		return time.Time{}, false
+	}
+	return time.Unix(int64(f), 0), true
+}
+
+// ValidationOptions are the optional registered claims checks performed when validating a token
+type ValidationOptions struct {
+	// Issuer if not empty should match the "iss" claim
+	Issuer string
+	// Audience if not empty should be contained in the "aud" claim
+	Audience string
+	// Leeway is the allowed clock skew when checking "exp" and "nbf" claims
+	Leeway time.Duration
+	// Now allows to override the current time, time.Now is used if nil
+	Now func() time.Time
+}
+
+// KeySet is a set of public keys indexed by their key id
+type KeySet struct {
+	keys map[string]crypto.PublicKey
+}
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+type jsonWebKeySet struct {
+	Keys []jsonWebKey `json:"keys"`
+}
+
+type header struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	Typ string `json:"typ"`
+}
+
+// LoadKeySetFile reads a JSON Web Key Set from a file
+func LoadKeySetFile(path string) (*KeySet, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read JWKS file %q", path)
+	}
+	ks, err := ParseKeySet(b)
+	return ks, errors.Wrapf(err, "invalid JWKS file %q", path)
+}
+
+// ParseKeySet parses a JSON Web Key Set.
+//
+// Keys that are not intended to verify signatures or use an unsupported key type are ignored.
+func ParseKeySet(b []byte) (*KeySet, error) {
+	var jwks jsonWebKeySet
+	if err := json.Unmarshal(b, &jwks); err != nil {
+		return nil, errors.Wrap(err, "failed to parse JSON Web Key Set")
+	}
+	ks := &KeySet{keys: make(map[string]crypto.PublicKey)}
+	for _, jwk := range jwks.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		var key crypto.PublicKey
+		var err error
+		switch jwk.Kty {
+		case "RSA":
+			key, err = jwk.rsaPublicKey()
+		case "EC":
+			key, err = jwk.ecdsaPublicKey()
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid key %q", jwk.Kid)
+		}
+		ks.keys[jwk.Kid] = key
+	}
+	if len(ks.keys) == 0 {
+		return nil, errors.New("no signature verification key found in JSON Web Key Set")
+	}
+	return ks, nil
+}
+
+func (jwk jsonWebKey) rsaPublicKey() (*rsa.PublicKey, error) {
+	n, err := decodeBigInt(jwk.N)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid modulus")
+	}
+	e, err := decodeBigInt(jwk.E)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid exponent")
+	}
+	if !e.IsInt64() {
+		return nil, errors.New("exponent too large")
+	}
+	return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+}
+
+func (jwk jsonWebKey) ecdsaPublicKey() (*ecdsa.PublicKey, error) {
+	var curve elliptic.Curve
+	switch jwk.Crv {
+	case "P-256":
+		curve = elliptic.P256()
+	case "P-384":
+		curve = elliptic.P384()
+	case "P-521":
+		curve = elliptic.P521()
+	default:
+		return nil, errors.Errorf("unsupported curve %q", jwk.Crv)
+	}
+	x, err := decodeBigInt(jwk.X)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid x coordinate")
+	}
+	y, err := decodeBigInt(jwk.Y)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid y coordinate")
+	}
+	if !curve.IsOnCurve(x, y) {
+		return nil, errors.New("point is not on curve")
+	}
+	return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, errors.New("empty value")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+// Validate checks the signature of a compact serialized JSON Web Token against the keys of this set
+// and its registered claims ("exp", "nbf", "iss" and "aud"), then returns its claims.
+func (ks *KeySet) Validate(token string, opts ValidationOptions) (Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("malformed token")
+	}
+	var h header
+	if err := decodeSegment(parts[0], &h); err != nil {
+		return nil, errors.Wrap(err, "malformed token header")
+	}
+	key, err := ks.lookupKey(h.Kid)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, errors.Wrap(err, "malformed token signature")
+	}
+	if err = verifySignature(h.Alg, key, []byte(parts[0]+"."+parts[1]), signature); err != nil {
+		return nil, err
+	}
+
+	var claims Claims
+	if err = decodeSegment(parts[1], &claims); err != nil {
+		return nil, errors.Wrap(err, "malformed token payload")
+	}
+	if err = claims.validate(opts); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+func (ks *KeySet) lookupKey(kid string) (crypto.PublicKey, error) {
+	if key, ok := ks.keys[kid]; ok {
+		return key, nil
+	}
+	if kid == "" && len(ks.keys) == 1 {
+		for _, key := range ks.keys {
+			return key, nil
+		}
+	}
+	return nil, errors.Errorf("unknown signing key %q", kid)
+}
+
+func decodeSegment(seg string, v interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+func verifySignature(alg string, key crypto.PublicKey, signed, signature []byte) error {
+	if len(alg) != 5 {
+		return errors.Errorf("unsupported signing algorithm %q", alg)
+	}
+	var hash crypto.Hash
+	switch alg[2:] {
+	case "256":
+		hash = crypto.SHA256
+	case "384":
+		hash = crypto.SHA384
+	case "512":
+		hash = crypto.SHA512
+	default:
+		return errors.Errorf("unsupported signing algorithm %q", alg)
+	}
+	h := hash.New()
+	h.Write(signed)
+	digest := h.Sum(nil)
+
+	switch {
+	case strings.HasPrefix(alg, "RS"):
+		rsaKey, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return errors.Errorf("signing algorithm %q doesn't match key type", alg)
+		}
+		return errors.Wrap(rsa.VerifyPKCS1v15(rsaKey, hash, digest, signature), "invalid token signature")
+	case strings.HasPrefix(alg, "ES"):
+		ecKey, ok := key.(*ecdsa.PublicKey)
+		if !ok {
+			return errors.Errorf("signing algorithm %q doesn't match key type", alg)
+		}
+		keySize := (ecKey.Curve.Params().BitSize + 7) / 8
+		if len(signature) != 2*keySize {
+			return errors.New("invalid token signature")
+		}
+		r := new(big.Int).SetBytes(signature[:keySize])
+		s := new(big.Int).SetBytes(signature[keySize:])
+		if !ecdsa.Verify(ecKey, digest, r, s) {
+			return errors.New("invalid token signature")
+		}
+		return nil
+	}
+	return errors.Errorf("unsupported signing algorithm %q", alg)
+}
+
+func (c Claims) validate(opts ValidationOptions) error {
+	now := time.Now()
+	if opts.Now != nil {
+		now = opts.Now()
+	}
+	if exp, ok := c.getTime("exp"); ok && now.After(exp.Add(opts.Leeway)) {
+		return errors.New("token is expired")
+	}
+	if nbf, ok := c.getTime("nbf"); ok && now.Add(opts.Leeway).Before(nbf) {
+		return errors.New("token is not valid yet")
+	}
+	if opts.Issuer != "" && c.GetString("iss") != opts.Issuer {
+		return errors.Errorf("unexpected token issuer %q", c.GetString("iss"))
+	}
+	if opts.Audience != "" {
+		for _, aud := range c.GetStringSlice("aud") {
+			if aud == opts.Audience {
+				return nil
+			}
+		}
+		return errors.Errorf("token audience doesn't contain %q", opts.Audience)
+	}
+	return nil
+}
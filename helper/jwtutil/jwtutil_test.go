@@ -0,0 +1,160 @@
+// Copyright 2021 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jwtutil
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func encodeSegment(t *testing.T, v interface{}) string {
+	b, err := json.Marshal(v)
+	require.NoError(t, err)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func signRS256(t *testing.T, key *rsa.PrivateKey, kid string, claims map[string]interface{}) string {
+	signed := encodeSegment(t, map[string]string{"alg": "RS256", "kid": kid, "typ": "JWT"}) + "." + encodeSegment(t, claims)
+	h := crypto.SHA256.New()
+	h.Write([]byte(signed))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, h.Sum(nil))
+	require.NoError(t, err)
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func signES256(t *testing.T, key *ecdsa.PrivateKey, kid string, claims map[string]interface{}) string {
+	signed := encodeSegment(t, map[string]string{"alg": "ES256", "kid": kid}) + "." + encodeSegment(t, claims)
+	h := crypto.SHA256.New()
+	h.Write([]byte(signed))
+	r, s, err := ecdsa.Sign(rand.Reader, key, h.Sum(nil))
+	require.NoError(t, err)
+	sig := make([]byte, 64)
+	r.FillBytes(sig[:32])
+	s.FillBytes(sig[32:])
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func generateKeySet(t *testing.T) (*rsa.PrivateKey, *ecdsa.PrivateKey, []byte) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	jwks := map[string]interface{}{
+		"keys": []map[string]string{
+			{
+				"kty": "RSA",
+				"kid": "rsa1",
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(rsaKey.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(rsaKey.E)).Bytes()),
+			},
+			{
+				"kty": "EC",
+				"kid": "ec1",
+				"crv": "P-256",
+				"x":   base64.RawURLEncoding.EncodeToString(ecKey.X.Bytes()),
+				"y":   base64.RawURLEncoding.EncodeToString(ecKey.Y.Bytes()),
+			},
+			{
+				"kty": "RSA",
+				"kid": "enc1",
+				"use": "enc",
+			},
+		},
+	}
+	b, err := json.Marshal(jwks)
+	require.NoError(t, err)
+	return rsaKey, ecKey, b
+}
+
+func TestKeySetValidate(t *testing.T) {
+	rsaKey, ecKey, jwks := generateKeySet(t)
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ks, err := ParseKeySet(jwks)
+	require.NoError(t, err)
+
+	now := time.Now()
+	validClaims := map[string]interface{}{
+		"sub":   "jdoe",
+		"iss":   "https://idp.example.com",
+		"aud":   []string{"yorc", "other"},
+		"exp":   now.Add(time.Hour).Unix(),
+		"nbf":   now.Add(-time.Minute).Unix(),
+		"roles": []string{"operator"},
+	}
+	withClaim := func(name string, value interface{}) map[string]interface{} {
+		c := make(map[string]interface{})
+		for k, v := range validClaims {
+			c[k] = v
+		}
+		c[name] = value
+		return c
+	}
+	opts := ValidationOptions{Issuer: "https://idp.example.com", Audience: "yorc", Leeway: 30 * time.Second}
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr bool
+	}{
+		{"ValidRS256", signRS256(t, rsaKey, "rsa1", validClaims), false},
+		{"ValidES256", signES256(t, ecKey, "ec1", validClaims), false},
+		{"UnknownKid", signRS256(t, rsaKey, "rsa2", validClaims), true},
+		{"WrongKey", signRS256(t, otherKey, "rsa1", validClaims), true},
+		{"AlgKeyMismatch", signRS256(t, rsaKey, "ec1", validClaims), true},
+		{"Expired", signRS256(t, rsaKey, "rsa1", withClaim("exp", now.Add(-time.Minute).Unix())), true},
+		{"ExpiredWithinLeeway", signRS256(t, rsaKey, "rsa1", withClaim("exp", now.Add(-10*time.Second).Unix())), false},
+		{"NotYetValid", signRS256(t, rsaKey, "rsa1", withClaim("nbf", now.Add(time.Hour).Unix())), true},
+		{"WrongIssuer", signRS256(t, rsaKey, "rsa1", withClaim("iss", "https://evil.example.com")), true},
+		{"WrongAudience", signRS256(t, rsaKey, "rsa1", withClaim("aud", "other")), true},
+		{"SingleAudience", signRS256(t, rsaKey, "rsa1", withClaim("aud", "yorc")), false},
+		{"Malformed", "not.a.token", true},
+		{"NotEnoughParts", "abc", true},
+		{"NoneAlg", encodeSegment(t, map[string]string{"alg": "none", "kid": "rsa1"}) + "." + encodeSegment(t, validClaims) + ".", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := ks.Validate(tt.token, opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("KeySet.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil {
+				assert.Equal(t, "jdoe", claims.GetString("sub"))
+				assert.Equal(t, []string{"operator"}, claims.GetStringSlice("roles"))
+			}
+		})
+	}
+}
+
+func TestParseKeySetErrors(t *testing.T) {
+	_, err := ParseKeySet([]byte(`{"keys": []}`))
+	assert.Error(t, err)
+	_, err = ParseKeySet([]byte(`not json`))
+	assert.Error(t, err)
+	_, err = ParseKeySet([]byte(`{"keys": [{"kty": "EC", "kid": "k", "crv": "P-256", "x": "AQ", "y": "AQ"}]}`))
+	assert.Error(t, err)
+}
@@ -0,0 +1,160 @@
+// Copyright 2021 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sshutil
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/mitchellh/go-homedir"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/ssh"
+
+	"github.com/ystia/yorc/v4/deployments"
+	"github.com/ystia/yorc/v4/vault"
+)
+
+// certificateFileSuffix is the suffix added by OpenSSH to a private key file path to find its certificate
+const certificateFileSuffix = "-cert.pub"
+
+// ParseCertificate parses an OpenSSH certificate
+//
+// The argument is :
+// - either a path to the certificate file,
+// - or the content or this certificate file (in the authorized_keys format)
+func ParseCertificate(pathOrContent string) (*ssh.Certificate, []byte, error) {
+	content := []byte(pathOrContent)
+	p, err := homedir.Expand(pathOrContent)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to read certificate file, error in fs home expansion")
+	}
+	if isFilePath(p) {
+		content, err = ioutil.ReadFile(p)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "failed to read certificate file %q", p)
+		}
+	}
+	content = bytes.TrimSpace(content)
+	pub, _, _, _, err := ssh.ParseAuthorizedKey(content)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to parse SSH certificate")
+	}
+	cert, ok := pub.(*ssh.Certificate)
+	if !ok {
+		return nil, nil, errors.Errorf("expecting an OpenSSH certificate but got a %q public key", pub.Type())
+	}
+	if cert.CertType != ssh.UserCert {
+		return nil, nil, errors.New("expecting an OpenSSH user certificate but got a host certificate")
+	}
+	return cert, content, nil
+}
+
+// SetCertificate associates an OpenSSH user certificate to this key.
+//
+// The argument is either a path to the certificate file or the content or this certificate file.
+// An error is returned if the certificate was not issued for this key.
+func (pk *PrivateKey) SetCertificate(pathOrContent string) error {
+	cert, content, err := ParseCertificate(pathOrContent)
+	if err != nil {
+		return err
+	}
+	signer, err := ssh.ParsePrivateKey(pk.Content)
+	if err != nil {
+		return errors.Wrap(err, "failed to parse private key")
+	}
+	if !bytes.Equal(cert.Key.Marshal(), signer.PublicKey().Marshal()) {
+		return errors.New("SSH certificate was not issued for this private key")
+	}
+	pk.Certificate = content
+	return nil
+}
+
+// Signer returns a signer for this key.
+//
+// If a certificate is associated to this key, the signer will authenticate using this certificate.
+func (pk *PrivateKey) Signer() (ssh.Signer, error) {
+	signer, err := ssh.ParsePrivateKey(pk.Content)
+	if err != nil {
+		return nil, err
+	}
+	if len(pk.Certificate) == 0 {
+		return signer, nil
+	}
+	cert, _, err := ParseCertificate(string(pk.Certificate))
+	if err != nil {
+		return nil, err
+	}
+	if cert.ValidBefore != ssh.CertTimeInfinity && time.Now().After(time.Unix(int64(cert.ValidBefore), 0)) {
+		return nil, errors.Errorf("SSH certificate %q expired on %s", cert.KeyId, time.Unix(int64(cert.ValidBefore), 0).Format(time.RFC3339))
+	}
+	return ssh.NewCertSigner(cert, signer)
+}
+
+// loadDefaultCertificate associates to a key read from a file the certificate stored next to it if any,
+// following the OpenSSH naming convention (ex: ~/.ssh/id_rsa-cert.pub for ~/.ssh/id_rsa)
+func (pk *PrivateKey) loadDefaultCertificate() error {
+	if pk.Path == "" {
+		return nil
+	}
+	certPath := pk.Path + certificateFileSuffix
+	if _, err := os.Stat(certPath); err != nil {
+		return nil
+	}
+	return errors.Wrapf(pk.SetCertificate(certPath), "invalid SSH certificate %q", certPath)
+}
+
+// SignWithVault obtains a freshly signed OpenSSH certificate for this key using the default Vault client.
+//
+// The Vault client should implement the vault.SSHCertificateSigner interface. The given user is requested
+// as valid principal of the certificate.
+func (pk *PrivateKey) SignWithVault(signingPath, user string) error {
+	if deployments.DefaultVaultClient == nil {
+		return errors.Errorf("can't sign SSH key using %q as no Vault is configured", signingPath)
+	}
+	signer, ok := deployments.DefaultVaultClient.(vault.SSHCertificateSigner)
+	if !ok {
+		return errors.Errorf("can't sign SSH key using %q as the configured Vault does not support it", signingPath)
+	}
+	s, err := ssh.ParsePrivateKey(pk.Content)
+	if err != nil {
+		return errors.Wrap(err, "failed to parse private key")
+	}
+	var options []string
+	if user != "" {
+		options = append(options, "valid_principals="+user)
+	}
+	cert, err := signer.SignSSHPublicKey(signingPath, string(ssh.MarshalAuthorizedKey(s.PublicKey())), options...)
+	if err != nil {
+		return err
+	}
+	return pk.SetCertificate(cert)
+}
+
+// SignKeysWithVault obtains freshly signed OpenSSH certificates for all given keys using the default Vault client.
+//
+// It does nothing if signingPath is empty.
+func SignKeysWithVault(keys map[string]*PrivateKey, signingPath, user string) error {
+	if signingPath == "" {
+		return nil
+	}
+	for keyName, key := range keys {
+		if err := key.SignWithVault(signingPath, user); err != nil {
+			return errors.Wrapf(err, "failed to sign key %q", keyName)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,171 @@
+// Copyright 2021 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sshutil
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+
+	"github.com/ystia/yorc/v4/deployments"
+	"github.com/ystia/yorc/v4/vault"
+)
+
+func generateCertificate(t *testing.T, ca ssh.Signer, pub ssh.PublicKey, validBefore time.Time) string {
+	t.Helper()
+	cert := &ssh.Certificate{
+		Key:             pub,
+		KeyId:           "test",
+		CertType:        ssh.UserCert,
+		ValidPrincipals: []string{"test"},
+		ValidBefore:     uint64(validBefore.Unix()),
+	}
+	require.NoError(t, cert.SignCert(rand.Reader, ca))
+	return string(ssh.MarshalAuthorizedKey(cert))
+}
+
+func generateCA(t *testing.T) ssh.Signer {
+	t.Helper()
+	_, caKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	ca, err := ssh.NewSignerFromKey(caKey)
+	require.NoError(t, err)
+	return ca
+}
+
+func TestPrivateKeyCertificate(t *testing.T) {
+	ca := generateCA(t)
+	key, err := GetPrivateKey(string(expectedKey))
+	require.NoError(t, err)
+	signer, err := ssh.ParsePrivateKey(expectedKey)
+	require.NoError(t, err)
+	otherKey := generateHostKey(t)
+
+	validCert := generateCertificate(t, ca, signer.PublicKey(), time.Now().Add(time.Hour))
+	expiredCert := generateCertificate(t, ca, signer.PublicKey(), time.Now().Add(-time.Hour))
+	otherCert := generateCertificate(t, ca, otherKey, time.Now().Add(time.Hour))
+
+	tests := []struct {
+		name          string
+		certificate   string
+		wantSetErr    bool
+		wantSignerErr bool
+	}{
+		{"ValidCertificate", validCert, false, false},
+		{"ExpiredCertificate", expiredCert, false, true},
+		{"CertificateForAnotherKey", otherCert, true, false},
+		{"NotACertificate", string(ssh.MarshalAuthorizedKey(otherKey)), true, false},
+		{"InvalidContent", "not a certificate", true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			k := &PrivateKey{Content: key.Content}
+			err := k.SetCertificate(tt.certificate)
+			if tt.wantSetErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			s, err := k.Signer()
+			if tt.wantSignerErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			_, isCert := s.PublicKey().(*ssh.Certificate)
+			assert.True(t, isCert, "expecting a certificate signer")
+		})
+	}
+}
+
+func TestGetPrivateKeyWithDefaultCertificate(t *testing.T) {
+	ca := generateCA(t)
+	signer, err := ssh.ParsePrivateKey(expectedKey)
+	require.NoError(t, err)
+
+	tmpDir, err := ioutil.TempDir("", "certs")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+	keyPath := filepath.Join(tmpDir, "id_rsa")
+	require.NoError(t, ioutil.WriteFile(keyPath, expectedKey, 0600))
+
+	key, err := GetPrivateKey(keyPath)
+	require.NoError(t, err)
+	assert.Empty(t, key.Certificate)
+
+	cert := generateCertificate(t, ca, signer.PublicKey(), time.Now().Add(time.Hour))
+	require.NoError(t, ioutil.WriteFile(keyPath+"-cert.pub", []byte(cert), 0644))
+	key, err = GetPrivateKey(keyPath)
+	require.NoError(t, err)
+	assert.Equal(t, strings.TrimSpace(cert), string(key.Certificate))
+}
+
+type sshSignerVaultClient struct {
+	ca  ssh.Signer
+	t   *testing.T
+	err error
+}
+
+func (c *sshSignerVaultClient) GetSecret(id string, options ...string) (vault.Secret, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (c *sshSignerVaultClient) Shutdown() error {
+	return nil
+}
+
+func (c *sshSignerVaultClient) SignSSHPublicKey(signingPath, publicKey string, options ...string) (string, error) {
+	if c.err != nil {
+		return "", c.err
+	}
+	assert.Equal(c.t, "ssh-client-signer/sign/test", signingPath)
+	assert.Equal(c.t, []string{"valid_principals=test"}, options)
+	pub, _, _, _, err := ssh.ParseAuthorizedKey([]byte(publicKey))
+	require.NoError(c.t, err)
+	return generateCertificate(c.t, c.ca, pub, time.Now().Add(time.Minute)), nil
+}
+
+func TestSignKeysWithVault(t *testing.T) {
+	defer func(vc vault.Client) {
+		deployments.DefaultVaultClient = vc
+	}(deployments.DefaultVaultClient)
+
+	keys := map[string]*PrivateKey{"0": &PrivateKey{Content: expectedKey}}
+
+	deployments.DefaultVaultClient = nil
+	assert.NoError(t, SignKeysWithVault(keys, "", "test"), "no signing path should be a no-op")
+	assert.Error(t, SignKeysWithVault(keys, "ssh-client-signer/sign/test", "test"), "expecting an error without Vault")
+
+	deployments.DefaultVaultClient = &sshSignerVaultClient{t: t, err: errors.New("permission denied")}
+	assert.Error(t, SignKeysWithVault(keys, "ssh-client-signer/sign/test", "test"))
+
+	deployments.DefaultVaultClient = &sshSignerVaultClient{t: t, ca: generateCA(t)}
+	require.NoError(t, SignKeysWithVault(keys, "ssh-client-signer/sign/test", "test"))
+	assert.NotEmpty(t, keys["0"].Certificate)
+	s, err := keys["0"].Signer()
+	require.NoError(t, err)
+	_, isCert := s.PublicKey().(*ssh.Certificate)
+	assert.True(t, isCert, "expecting a certificate signer")
+}
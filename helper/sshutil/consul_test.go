@@ -36,6 +36,9 @@ func TestRunConsulSSHUtilPackageTests(t *testing.T) {
 	t.Run("KeysTests", func(t *testing.T) {
 		testGetKeysFromCredentialsAttribute(t)
 	})
+	t.Run("HostKeysTests", func(t *testing.T) {
+		testHostKeyRecordedOnFirstUse(t)
+	})
 }
 
 func loadTestYaml(t *testing.T, yamlName string) string {
@@ -0,0 +1,291 @@
+// Copyright 2021 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sshutil
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"fmt"
+	"net"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+
+	"github.com/ystia/yorc/v4/helper/consulutil"
+	"github.com/ystia/yorc/v4/log"
+)
+
+const (
+	// HostKeyCheckingStrict only accepts host keys that are pinned or listed in the known_hosts file or previously recorded
+	HostKeyCheckingStrict = "strict"
+	// HostKeyCheckingTOFU accepts and records the host key the first time a host is contacted (Trust On First Use)
+	HostKeyCheckingTOFU = "tofu"
+	// HostKeyCheckingInsecure disables host key verification
+	HostKeyCheckingInsecure = "insecure"
+)
+
+// HostKeyOptions holds the parameters used to verify the host key of a remote SSH server
+type HostKeyOptions struct {
+	// Mode is one of HostKeyCheckingStrict, HostKeyCheckingTOFU or HostKeyCheckingInsecure.
+	// It defaults to HostKeyCheckingTOFU if empty.
+	Mode string
+	// KnownHostsFile is an optional path to a known_hosts file
+	KnownHostsFile string
+	// HostKey is an optional pinned host key in the authorized_keys format (ex: "ssh-ed25519 AAAA...")
+	HostKey string
+	// Scope is the path under which host keys are recorded in Consul (ex: "hostspool/<location>").
+	// Keys are neither recorded nor looked up in Consul if empty.
+	Scope string
+}
+
+type hostKeyChangedError struct {
+	address  string
+	source   string
+	expected []string
+	actual   string
+}
+
+func (e *hostKeyChangedError) Error() string {
+	return fmt.Sprintf("SSH host key of %q has changed: server presented %s while %s expects %s. This may be a man-in-the-middle attack, if the change is legitimate update %s",
+		e.address, e.actual, e.source, strings.Join(e.expected, " or "), e.source)
+}
+
+// IsHostKeyChangedError checks if an error is due to a remote host presenting an unexpected SSH host key
+func IsHostKeyChangedError(err error) bool {
+	_, ok := errors.Cause(err).(*hostKeyChangedError)
+	return ok
+}
+
+// ConfigureHostKeyVerification sets the host key callback and the accepted host key algorithms
+// of the given SSH client configuration in order to verify the host key of the server at host:port.
+//
+// The presented key is checked against the pinned key first, then against the known_hosts file and finally
+// against the key recorded in Consul. When the host is unknown the connection is rejected in strict mode
+// and the key is recorded in TOFU mode. onKeyChanged, if not nil, is called when the host key doesn't match
+// the expected one as the SSH library does not preserve errors types.
+func ConfigureHostKeyVerification(conf *ssh.ClientConfig, host string, port int, opts HostKeyOptions, onKeyChanged func(err error)) error {
+	mode := opts.Mode
+	if mode == "" {
+		mode = HostKeyCheckingTOFU
+	}
+	if mode == HostKeyCheckingInsecure {
+		conf.HostKeyCallback = ssh.InsecureIgnoreHostKey()
+		return nil
+	}
+	if mode != HostKeyCheckingStrict && mode != HostKeyCheckingTOFU {
+		return errors.Errorf("unsupported SSH host key checking mode %q, expecting one of %q, %q or %q", mode, HostKeyCheckingStrict, HostKeyCheckingTOFU, HostKeyCheckingInsecure)
+	}
+
+	address := net.JoinHostPort(host, strconv.Itoa(port))
+	v := &hostKeyVerifier{address: address, mode: mode}
+
+	if opts.HostKey != "" {
+		pinned, _, _, _, err := ssh.ParseAuthorizedKey([]byte(opts.HostKey))
+		if err != nil {
+			return errors.Wrapf(err, "failed to parse pinned SSH host key for %q", address)
+		}
+		v.pinned = pinned
+	}
+	if opts.KnownHostsFile != "" {
+		khCallback, err := knownhosts.New(opts.KnownHostsFile)
+		if err != nil {
+			return errors.Wrapf(err, "failed to read SSH known hosts file %q", opts.KnownHostsFile)
+		}
+		v.knownHosts = khCallback
+	}
+	if opts.Scope != "" {
+		v.consulKey = path.Join(consulutil.SSHHostKeysKVPrefix, opts.Scope, knownhosts.Normalize(address))
+	}
+
+	conf.HostKeyCallback = func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		err := v.check(key)
+		if err != nil && onKeyChanged != nil && IsHostKeyChangedError(err) {
+			onKeyChanged(err)
+		}
+		return err
+	}
+	conf.HostKeyAlgorithms = v.algorithms()
+	return nil
+}
+
+type hostKeyVerifier struct {
+	address    string
+	mode       string
+	pinned     ssh.PublicKey
+	knownHosts ssh.HostKeyCallback
+	consulKey  string
+}
+
+// knownHostsKeys returns keys registered in the known_hosts file for the verified address
+func (v *hostKeyVerifier) knownHostsKeys() []ssh.PublicKey {
+	if v.knownHosts == nil {
+		return nil
+	}
+	// There is no lookup function in knownhosts so we check a key that can't match to get expected ones
+	probe, err := ssh.NewPublicKey(ed25519.PublicKey(make([]byte, ed25519.PublicKeySize)))
+	if err != nil {
+		return nil
+	}
+	var keyErr *knownhosts.KeyError
+	if !errors.As(v.knownHosts(v.address, v.remoteAddr(), probe), &keyErr) {
+		return nil
+	}
+	keys := make([]ssh.PublicKey, 0, len(keyErr.Want))
+	for _, k := range keyErr.Want {
+		keys = append(keys, k.Key)
+	}
+	return keys
+}
+
+func (v *hostKeyVerifier) remoteAddr() net.Addr {
+	// knownhosts gives preference to the address, remote address is only required to be a TCP one
+	host, port, _ := net.SplitHostPort(v.address)
+	p, _ := strconv.Atoi(port)
+	ip := net.ParseIP(host)
+	if ip == nil {
+		ip = net.IPv4zero
+	}
+	return &net.TCPAddr{IP: ip, Port: p}
+}
+
+// defaultHostKeyAlgorithms are the host key algorithms supported by the SSH library in its preference order
+var defaultHostKeyAlgorithms = []string{
+	ssh.CertAlgoRSAv01, ssh.CertAlgoDSAv01, ssh.CertAlgoECDSA256v01,
+	ssh.CertAlgoECDSA384v01, ssh.CertAlgoECDSA521v01, ssh.CertAlgoED25519v01,
+	ssh.KeyAlgoECDSA256, ssh.KeyAlgoECDSA384, ssh.KeyAlgoECDSA521,
+	ssh.KeyAlgoRSA, ssh.KeyAlgoDSA,
+	ssh.KeyAlgoED25519,
+}
+
+// algorithms returns the host key algorithms matching pinned or known keys so the server presents a key we are able to check.
+//
+// Otherwise the algorithm of the key recorded on first use, if any, is put first followed by the default ones, so the server
+// presents the recorded key rather than its preferred one while a key of another type is still reported as a changed key.
+// It returns nil to let the SSH library use its defaults if there is no expected key.
+func (v *hostKeyVerifier) algorithms() []string {
+	var keys []ssh.PublicKey
+	if v.pinned != nil {
+		keys = []ssh.PublicKey{v.pinned}
+	} else {
+		keys = v.knownHostsKeys()
+	}
+	var algos []string
+	for _, k := range keys {
+		algos = append(algos, k.Type())
+	}
+	if len(algos) > 0 {
+		return algos
+	}
+
+	recorded, err := v.recordedKey()
+	if err != nil {
+		// The error will be reported when checking the presented key
+		log.Debugf("%v", err)
+		return nil
+	}
+	if recorded == nil {
+		return nil
+	}
+	algos = append(algos, recorded.Type())
+	for _, a := range defaultHostKeyAlgorithms {
+		if a != recorded.Type() {
+			algos = append(algos, a)
+		}
+	}
+	return algos
+}
+
+func (v *hostKeyVerifier) check(key ssh.PublicKey) error {
+	if v.pinned != nil {
+		if keysEqual(v.pinned, key) {
+			return nil
+		}
+		return v.changedError("the pinned host_key", key, v.pinned)
+	}
+
+	if v.knownHosts != nil {
+		err := v.knownHosts(v.address, v.remoteAddr(), key)
+		if err == nil {
+			return nil
+		}
+		var keyErr *knownhosts.KeyError
+		if !errors.As(err, &keyErr) {
+			return errors.Wrapf(err, "SSH host key verification failed for %q", v.address)
+		}
+		if len(keyErr.Want) > 0 {
+			expected := make([]ssh.PublicKey, 0, len(keyErr.Want))
+			for _, k := range keyErr.Want {
+				expected = append(expected, k.Key)
+			}
+			return v.changedError("the known_hosts file", key, expected...)
+		}
+		// Unknown host in the known_hosts file, fallback to recorded keys
+	}
+
+	recorded, err := v.recordedKey()
+	if err != nil {
+		return err
+	}
+	if recorded != nil {
+		if keysEqual(recorded, key) {
+			return nil
+		}
+		return v.changedError("the key recorded in Consul on first use", key, recorded)
+	}
+
+	if v.mode == HostKeyCheckingStrict {
+		return errors.Errorf("SSH host key verification failed for %q: unknown host key %s and host key checking mode is %q", v.address, ssh.FingerprintSHA256(key), HostKeyCheckingStrict)
+	}
+	if v.consulKey != "" {
+		log.Printf("Recording SSH host key %s for %q on first use", ssh.FingerprintSHA256(key), v.address)
+		err = consulutil.StoreConsulKey(v.consulKey, bytes.TrimSpace(ssh.MarshalAuthorizedKey(key)))
+		if err != nil {
+			return errors.Wrapf(err, "failed to record SSH host key for %q", v.address)
+		}
+	}
+	return nil
+}
+
+// recordedKey returns the host key recorded in Consul on first use if any
+func (v *hostKeyVerifier) recordedKey() (ssh.PublicKey, error) {
+	if v.consulKey == "" {
+		return nil, nil
+	}
+	exist, value, err := consulutil.GetValue(v.consulKey)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to retrieve recorded SSH host key for %q", v.address)
+	}
+	if !exist || len(value) == 0 {
+		return nil, nil
+	}
+	key, _, _, _, err := ssh.ParseAuthorizedKey(value)
+	return key, errors.Wrapf(err, "failed to parse recorded SSH host key for %q", v.address)
+}
+
+func (v *hostKeyVerifier) changedError(source string, actual ssh.PublicKey, expected ...ssh.PublicKey) error {
+	e := &hostKeyChangedError{address: v.address, source: source, actual: ssh.FingerprintSHA256(actual)}
+	for _, k := range expected {
+		e.expected = append(e.expected, ssh.FingerprintSHA256(k))
+	}
+	return e
+}
+
+func keysEqual(a, b ssh.PublicKey) bool {
+	return bytes.Equal(a.Marshal(), b.Marshal())
+}
@@ -0,0 +1,133 @@
+// Copyright 2021 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sshutil
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"io/ioutil"
+	"net"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+
+	"github.com/ystia/yorc/v4/helper/consulutil"
+)
+
+func generateHostKey(t *testing.T) ssh.PublicKey {
+	t.Helper()
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	key, err := ssh.NewPublicKey(pub)
+	require.NoError(t, err)
+	return key
+}
+
+func TestConfigureHostKeyVerification(t *testing.T) {
+	hostKey := generateHostKey(t)
+	otherKey := generateHostKey(t)
+	remote := &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 22}
+
+	tmpDir, err := ioutil.TempDir("", "known_hosts")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+	knownHostsFile := filepath.Join(tmpDir, "known_hosts")
+	err = ioutil.WriteFile(knownHostsFile, []byte(knownhosts.Line([]string{"10.0.0.1"}, hostKey)+"\n"), 0600)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name          string
+		host          string
+		opts          HostKeyOptions
+		presentedKey  ssh.PublicKey
+		wantConfErr   bool
+		wantErr       bool
+		wantChanged   bool
+		wantAlgorithm []string
+	}{
+		{"Insecure", "10.0.0.1", HostKeyOptions{Mode: HostKeyCheckingInsecure, HostKey: string(ssh.MarshalAuthorizedKey(hostKey))}, otherKey, false, false, false, nil},
+		{"UnsupportedMode", "10.0.0.1", HostKeyOptions{Mode: "none"}, hostKey, true, false, false, nil},
+		{"InvalidPinnedKey", "10.0.0.1", HostKeyOptions{HostKey: "ssh-ed25519 notakey"}, hostKey, true, false, false, nil},
+		{"MissingKnownHostsFile", "10.0.0.1", HostKeyOptions{KnownHostsFile: filepath.Join(tmpDir, "missing")}, hostKey, true, false, false, nil},
+		{"PinnedKeyMatch", "10.0.0.1", HostKeyOptions{Mode: HostKeyCheckingStrict, HostKey: string(ssh.MarshalAuthorizedKey(hostKey))}, hostKey, false, false, false, []string{ssh.KeyAlgoED25519}},
+		{"PinnedKeyMismatch", "10.0.0.1", HostKeyOptions{HostKey: string(ssh.MarshalAuthorizedKey(hostKey))}, otherKey, false, true, true, []string{ssh.KeyAlgoED25519}},
+		{"KnownHostsMatch", "10.0.0.1", HostKeyOptions{Mode: HostKeyCheckingStrict, KnownHostsFile: knownHostsFile}, hostKey, false, false, false, []string{ssh.KeyAlgoED25519}},
+		{"KnownHostsMismatch", "10.0.0.1", HostKeyOptions{KnownHostsFile: knownHostsFile}, otherKey, false, true, true, []string{ssh.KeyAlgoED25519}},
+		{"KnownHostsUnknownStrict", "10.0.0.2", HostKeyOptions{Mode: HostKeyCheckingStrict, KnownHostsFile: knownHostsFile}, hostKey, false, true, false, nil},
+		{"KnownHostsUnknownTOFU", "10.0.0.2", HostKeyOptions{KnownHostsFile: knownHostsFile}, hostKey, false, false, false, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf := &ssh.ClientConfig{}
+			var changedErr error
+			err := ConfigureHostKeyVerification(conf, tt.host, 22, tt.opts, func(err error) {
+				changedErr = err
+			})
+			if tt.wantConfErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantAlgorithm, conf.HostKeyAlgorithms)
+			err = conf.HostKeyCallback(tt.host+":22", remote, tt.presentedKey)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantChanged, IsHostKeyChangedError(err))
+			assert.Equal(t, tt.wantChanged, changedErr != nil)
+		})
+	}
+}
+
+func testHostKeyRecordedOnFirstUse(t *testing.T) {
+	hostKey := generateHostKey(t)
+	otherKey := generateHostKey(t)
+	remote := &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 22}
+	opts := HostKeyOptions{Mode: HostKeyCheckingTOFU, Scope: path.Join("tests", t.Name())}
+
+	conf := &ssh.ClientConfig{}
+	err := ConfigureHostKeyVerification(conf, "10.0.0.1", 22, opts, nil)
+	require.NoError(t, err)
+	assert.Nil(t, conf.HostKeyAlgorithms)
+	require.NoError(t, conf.HostKeyCallback("10.0.0.1:22", remote, hostKey))
+
+	exist, value, err := consulutil.GetStringValue(path.Join(consulutil.SSHHostKeysKVPrefix, opts.Scope, "10.0.0.1"))
+	require.NoError(t, err)
+	require.True(t, exist)
+	assert.Equal(t, strings.TrimSpace(string(ssh.MarshalAuthorizedKey(hostKey))), value)
+
+	// Key is now recorded and should be enforced even in strict mode
+	opts.Mode = HostKeyCheckingStrict
+	conf = &ssh.ClientConfig{}
+	err = ConfigureHostKeyVerification(conf, "10.0.0.1", 22, opts, nil)
+	require.NoError(t, err)
+	// The algorithm of the recorded key is negotiated first
+	require.NotEmpty(t, conf.HostKeyAlgorithms)
+	assert.Equal(t, ssh.KeyAlgoED25519, conf.HostKeyAlgorithms[0])
+	assert.Len(t, conf.HostKeyAlgorithms, len(defaultHostKeyAlgorithms))
+	assert.NoError(t, conf.HostKeyCallback("10.0.0.1:22", remote, hostKey))
+	err = conf.HostKeyCallback("10.0.0.1:22", remote, otherKey)
+	assert.Error(t, err)
+	assert.True(t, IsHostKeyChangedError(err))
+}
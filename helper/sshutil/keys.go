@@ -34,10 +34,14 @@ import (
 const DefaultSSHPrivateKeyFilePath = "~/.ssh/yorc.pem"
 
 // PrivateKey represent a parsed ssh Private Key.
-// Content is always set but Path is populated only if the key content was read from a filesystem path (not provided directly)
+// Content is always set but Path is populated only if the key content was read from a filesystem path (not provided directly).
+// Certificate is populated if an OpenSSH certificate was provided for this key or if a certificate file following the OpenSSH
+// naming convention (<Path>-cert.pub) exists next to the key file.
 type PrivateKey struct {
 	Content []byte
 	Path    string
+	// Certificate is an optional OpenSSH user certificate issued for this key
+	Certificate []byte
 }
 
 // ReadPrivateKey returns an authentication method relying on private/public key pairs
@@ -58,7 +62,7 @@ func ReadPrivateKey(pk string) (ssh.AuthMethod, error) {
 
 // ReadSSHPrivateKey returns an authentication method relying on private/public key pairs
 func ReadSSHPrivateKey(pk *PrivateKey) (ssh.AuthMethod, error) {
-	signer, err := ssh.ParsePrivateKey(pk.Content)
+	signer, err := pk.Signer()
 	if err != nil {
 		p := pk.Path
 		if p == "" {
@@ -123,6 +127,9 @@ func GetPrivateKey(pathOrContent string) (*PrivateKey, error) {
 		// not a valid key
 		return nil, errors.New(`invalid key content`)
 	}
+	if err = k.loadDefaultCertificate(); err != nil {
+		return nil, err
+	}
 	return k, nil
 }
 
@@ -145,6 +152,12 @@ func GetKeysFromCredentialsDataType(creds *types.Credential) (map[string]*Privat
 		if err != nil {
 			return nil, errors.Wrapf(err, "failed to parse key %q", keyName)
 		}
+		if cert := creds.Certificates[keyName]; cert != "" {
+			cert = config.DefaultConfigTemplateResolver.ResolveValueWithTemplates("credentials.certificate", cert).(string)
+			if err = k.SetCertificate(cert); err != nil {
+				return nil, errors.Wrapf(err, "failed to parse certificate of key %q", keyName)
+			}
+		}
 		keys[keyName] = k
 	}
 
@@ -157,7 +157,7 @@ func (client *SSHClient) RunCommand(cmd string) (string, error) {
 
 	retryRunCommand := client.makeRetryFunc(func(ctx context.Context) error {
 		var rerr error
-		res, rerr = client.runCommand(ctx, cmd)
+		res, rerr = client.runCommand(ctx, context.Background(), cmd)
 		if rerr == nil {
 			return nil
 		}
@@ -171,13 +171,71 @@ func (client *SSHClient) RunCommand(cmd string) (string, error) {
 	return res, errors.WithStack(err)
 }
 
-func (client *SSHClient) runCommand(ctx context.Context, cmd string) (string, error) {
-	session, err := client.newSession(ctx)
+// RunCommandWithContext runs a command using the given client and stops waiting for it when the given context is cancelled
+//
+// When the client is a SSHClient the remote process is killed on cancellation.
+func RunCommandWithContext(ctx context.Context, client Client, cmd string) (string, error) {
+	if sshClient, ok := client.(*SSHClient); ok {
+		return sshClient.runCommandWithContext(ctx, cmd)
+	}
+	type result struct {
+		out string
+		err error
+	}
+	chRes := make(chan result, 1)
+	go func() {
+		out, err := client.RunCommand(cmd)
+		chRes <- result{out, err}
+	}()
+	select {
+	case <-ctx.Done():
+		return "", errors.Wrapf(ctx.Err(), "command %q interrupted", cmd)
+	case res := <-chRes:
+		return res.out, res.err
+	}
+}
+
+func (client *SSHClient) runCommandWithContext(cmdCtx context.Context, cmd string) (string, error) {
+	var res string
+	retryRunCommand := client.makeRetryFunc(func(ctx context.Context) error {
+		var rerr error
+		res, rerr = client.runCommand(ctx, cmdCtx, cmd)
+		if rerr == nil {
+			return nil
+		}
+		var eerr *ssh.ExitError
+		if goerr.As(rerr, &eerr) || cmdCtx.Err() != nil {
+			return rerr
+		}
+		return retry.RetryableError(rerr)
+	})
+	err := retryRunCommand()
+	if cmdCtx.Err() != nil {
+		return res, errors.Wrapf(cmdCtx.Err(), "command %q interrupted", cmd)
+	}
+	return res, errors.WithStack(err)
+}
+
+// runCommand runs a command in a new session opened using sessionCtx, the remote process is killed if cmdCtx is cancelled
+func (client *SSHClient) runCommand(sessionCtx, cmdCtx context.Context, cmd string) (string, error) {
+	session, err := client.newSession(sessionCtx)
 	if err != nil {
 		return "", errors.Wrap(err, "Unable to create new session")
 	}
 	defer session.Close()
 
+	chDone := make(chan struct{})
+	defer close(chDone)
+	go func() {
+		select {
+		case <-cmdCtx.Done():
+			log.Debug("[SSHSession] Cancellation has been sent: a sigkill signal is sent to remote process")
+			session.Signal(ssh.SIGKILL)
+			session.Session.Close()
+		case <-chDone:
+		}
+	}()
+
 	log.Debugf("[SSHSession] cmd: %q", cmd)
 	stdOutErrBytes, err := session.CombinedOutput(cmd)
 	stdOutErrStr := strings.Trim(string(stdOutErrBytes[:]), "\x00")
@@ -349,7 +407,7 @@ func NewSSHAgent(ctx context.Context) (*SSHAgent, error) {
 	}, nil
 }
 
-func (sa *SSHAgent) addKey(privateKey []byte, lifeTime uint32) error {
+func (sa *SSHAgent) addKey(privateKey, certificate []byte, lifeTime uint32) error {
 	rawKey, err := ssh.ParseRawPrivateKey(privateKey)
 	if err != nil {
 		return errors.Wrapf(err, "failed to parse raw private key")
@@ -359,6 +417,17 @@ func (sa *SSHAgent) addKey(privateKey []byte, lifeTime uint32) error {
 		PrivateKey:   rawKey,
 		LifetimeSecs: lifeTime,
 	}
+	if len(certificate) > 0 {
+		addedKey.Certificate, _, err = ParseCertificate(string(certificate))
+		if err != nil {
+			return err
+		}
+		// Also add the key alone as servers may not trust the certificate authority
+		if err = sa.agent.Add(*addedKey); err != nil {
+			return err
+		}
+		addedKey.Certificate = nil
+	}
 	return sa.agent.Add(*addedKey)
 }
 
@@ -369,13 +438,13 @@ func (sa *SSHAgent) AddKey(privateKey string, lifeTime uint32) error {
 	if err != nil {
 		return errors.Wrapf(err, "failed to retrieve private key content")
 	}
-	return sa.addKey(keyContent, lifeTime)
+	return sa.addKey(keyContent, nil, lifeTime)
 }
 
 // AddPrivateKey allows to add a key into ssh-agent keys list
 func (sa *SSHAgent) AddPrivateKey(privateKey *PrivateKey, lifeTime uint32) error {
 	log.Debugf("Add key for SSH-AGENT")
-	return sa.addKey(privateKey.Content, lifeTime)
+	return sa.addKey(privateKey.Content, privateKey.Certificate, lifeTime)
 }
 
 // RemoveKey allows to remove a key into ssh-agent keys list
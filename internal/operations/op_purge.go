@@ -29,6 +29,7 @@ import (
 	"github.com/ystia/yorc/v4/events"
 	"github.com/ystia/yorc/v4/helper/collections"
 	"github.com/ystia/yorc/v4/helper/consulutil"
+	"github.com/ystia/yorc/v4/prov/scheduling"
 	"github.com/ystia/yorc/v4/tasks"
 )
 
@@ -133,6 +134,14 @@ func performPurgeDeployment(ctx context.Context, finalError *multierror.Error, d
 		return err
 	}
 
+	// Stop scheduling workflows of this deployment
+	err = handleError(finalError, continueOnError, func() error {
+		return scheduling.UnregisterDeploymentWorkflowSchedules(deploymentID)
+	})
+	if err != nil {
+		return err
+	}
+
 	// Delete events tree corresponding to the deployment TaskExecution
 	err = handleError(finalError, continueOnError, func() error {
 		return events.PurgeDeploymentEvents(ctx, deploymentID)
@@ -156,6 +165,12 @@ func performPurgeDeployment(ctx context.Context, finalError *multierror.Error, d
 	if err != nil {
 		return err
 	}
+	err = handleError(finalError, continueOnError, func() error {
+		return RemoveDeploymentOverlayBackup(deploymentID, filepathWorkingDirectory)
+	})
+	if err != nil {
+		return err
+	}
 
 	err = handleError(finalError, continueOnError, func() error {
 		return deployments.DeleteDeployment(ctx, deploymentID)
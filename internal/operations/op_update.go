@@ -0,0 +1,182 @@
+// Copyright 2021 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operations
+
+import (
+	"context"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/ystia/yorc/v4/deployments"
+	"github.com/ystia/yorc/v4/log"
+	"github.com/ystia/yorc/v4/tasks"
+	"github.com/ystia/yorc/v4/tasks/collector"
+)
+
+// deploymentBackupDir returns the directory where the overlay of a deployment is saved during an update
+//
+// This should be kept consistent with prov/operations.GetOverlayPath
+func deploymentBackupDir(workingDirectory, deploymentID string) string {
+	return filepath.Join(workingDirectory, "deployments", "."+deploymentID)
+}
+
+// HasDeploymentOverlayBackup checks if an overlay saved by BackupDeploymentOverlay exists for a deployment
+func HasDeploymentOverlayBackup(deploymentID, workingDirectory string) (bool, error) {
+	_, err := os.Stat(deploymentBackupDir(workingDirectory, deploymentID))
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to check overlay backup of deployment %q", deploymentID)
+	}
+	return true, nil
+}
+
+// BackupDeploymentOverlay moves the overlay of a deployment aside before replacing it by the content of an updated CSAR
+//
+// The backup is used by tasks removing nodes as artifacts of removed nodes are not part of the updated CSAR.
+// An existing backup belongs to an update not yet completed nor restored, so it is never replaced and an error is returned.
+func BackupDeploymentOverlay(deploymentID, workingDirectory string) error {
+	exists, err := HasDeploymentOverlayBackup(deploymentID, workingDirectory)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return errors.Errorf("an overlay backup of a previous update of deployment %q already exists", deploymentID)
+	}
+	backupDir := deploymentBackupDir(workingDirectory, deploymentID)
+	if err = os.MkdirAll(backupDir, 0775); err != nil {
+		return errors.Wrapf(err, "failed to create backup directory of deployment %q", deploymentID)
+	}
+	overlayPath := filepath.Join(workingDirectory, "deployments", deploymentID, "overlay")
+	err = os.Rename(overlayPath, filepath.Join(backupDir, "overlay"))
+	if os.IsNotExist(err) {
+		// Nothing to backup
+		return nil
+	}
+	return errors.Wrapf(err, "failed to backup overlay of deployment %q", deploymentID)
+}
+
+// RestoreDeploymentOverlay replaces the overlay of a deployment by the one saved by BackupDeploymentOverlay
+//
+// This is a no-op if there is no backup, so it is safe to call it several times.
+func RestoreDeploymentOverlay(deploymentID, workingDirectory string) error {
+	backupDir := deploymentBackupDir(workingDirectory, deploymentID)
+	if _, err := os.Stat(backupDir); os.IsNotExist(err) {
+		return nil
+	}
+	overlayPath := filepath.Join(workingDirectory, "deployments", deploymentID, "overlay")
+	err := os.RemoveAll(overlayPath)
+	if err != nil {
+		return errors.Wrapf(err, "failed to remove overlay of deployment %q", deploymentID)
+	}
+	err = os.Rename(filepath.Join(backupDir, "overlay"), overlayPath)
+	if err != nil && !os.IsNotExist(err) {
+		return errors.Wrapf(err, "failed to restore overlay of deployment %q", deploymentID)
+	}
+	return RemoveDeploymentOverlayBackup(deploymentID, workingDirectory)
+}
+
+// RemoveDeploymentOverlayBackup removes the overlay saved by BackupDeploymentOverlay if any
+func RemoveDeploymentOverlayBackup(deploymentID, workingDirectory string) error {
+	err := os.RemoveAll(deploymentBackupDir(workingDirectory, deploymentID))
+	return errors.Wrapf(err, "failed to remove overlay backup of deployment %q", deploymentID)
+}
+
+// StartDeploymentUpdate starts to apply the given update of a deployment and returns the ID of the registered task if any.
+//
+// If nodes are removed from the topology a task running the uninstall workflow on those nodes is registered,
+// the updated definition located at defPath will be stored at the end of this task.
+// Otherwise the updated definition is stored right now (see ApplyDeploymentUpdate).
+//
+// The update is expected to be computed and checked using deployments.ComputeTopologyUpdate and the deployment
+// status to be atomically set to UPDATE_IN_PROGRESS by the caller (see deployments.CheckAndSetDeploymentStatus).
+// On error, the deployment status is set to UPDATE_FAILURE, restoring the previous overlay is left to the caller.
+func StartDeploymentUpdate(ctx context.Context, tc *collector.Collector, deploymentID, defPath, workingDirectory string, update *deployments.TopologyUpdate, priority tasks.TaskPriority) (string, error) {
+	if len(update.RemovedNodes) == 0 {
+		return ApplyDeploymentUpdate(ctx, tc, deploymentID, defPath, workingDirectory, priority)
+	}
+	data, err := nodesInstancesTaskData(ctx, deploymentID, update.RemovedNodes)
+	if err != nil {
+		deployments.SetDeploymentStatus(ctx, deploymentID, deployments.UPDATE_FAILURE)
+		return "", err
+	}
+	data["workflowName"] = "uninstall"
+	data[tasks.TaskDataUpdateDefinition] = defPath
+	taskID, err := tc.RegisterTaskWithPriority(deploymentID, tasks.TaskTypeRemoveNodes, data, priority)
+	if err != nil {
+		deployments.SetDeploymentStatus(ctx, deploymentID, deployments.UPDATE_FAILURE)
+	}
+	return taskID, err
+}
+
+// ApplyDeploymentUpdate stores the updated definition of a deployment located at defPath and registers
+// a task running the install workflow on added nodes.
+//
+// If there is no node to install, no task is registered, an empty task ID is returned and
+// the deployment status is set to UPDATED.
+//
+// If the updated definition can't be stored, the deployment status is set to UPDATE_FAILURE and the previous overlay is restored.
+func ApplyDeploymentUpdate(ctx context.Context, tc *collector.Collector, deploymentID, defPath, workingDirectory string, priority tasks.TaskPriority) (string, error) {
+	update, err := deployments.UpdateDeploymentDefinition(ctx, deploymentID, defPath)
+	if err != nil {
+		return "", failDeploymentUpdate(ctx, deploymentID, workingDirectory, err)
+	}
+	if len(update.AddedNodes) == 0 {
+		err = RemoveDeploymentOverlayBackup(deploymentID, workingDirectory)
+		if err != nil {
+			return "", err
+		}
+		return "", deployments.SetDeploymentStatus(ctx, deploymentID, deployments.UPDATED)
+	}
+	data, err := nodesInstancesTaskData(ctx, deploymentID, update.AddedNodes)
+	if err != nil {
+		deployments.SetDeploymentStatus(ctx, deploymentID, deployments.UPDATE_FAILURE)
+		return "", err
+	}
+	data["workflowName"] = "install"
+	taskID, err := tc.RegisterTaskWithPriority(deploymentID, tasks.TaskTypeAddNodes, data, priority)
+	if err != nil {
+		deployments.SetDeploymentStatus(ctx, deploymentID, deployments.UPDATE_FAILURE)
+	}
+	return taskID, err
+}
+
+// failDeploymentUpdate sets the status of a deployment to UPDATE_FAILURE and puts back its previous overlay
+// when the updated definition could not be stored
+func failDeploymentUpdate(ctx context.Context, deploymentID, workingDirectory string, err error) error {
+	deployments.SetDeploymentStatus(ctx, deploymentID, deployments.UPDATE_FAILURE)
+	if restoreErr := RestoreDeploymentOverlay(deploymentID, workingDirectory); restoreErr != nil {
+		log.Printf("[WARNING] %v", restoreErr)
+	}
+	return err
+}
+
+// nodesInstancesTaskData returns task data declaring all instances of the given nodes as related to a task
+func nodesInstancesTaskData(ctx context.Context, deploymentID string, nodes []string) (map[string]string, error) {
+	data := make(map[string]string)
+	for _, nodeName := range nodes {
+		instances, err := deployments.GetNodeInstancesIds(ctx, deploymentID, nodeName)
+		if err != nil {
+			return nil, err
+		}
+		data[path.Join("nodes", nodeName)] = strings.Join(instances, ",")
+	}
+	return data, nil
+}
@@ -0,0 +1,74 @@
+// Copyright 2021 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operations
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeploymentOverlayBackup(t *testing.T) {
+	workDir, err := ioutil.TempDir("", "yorc-overlay-backup")
+	require.NoError(t, err)
+	defer os.RemoveAll(workDir)
+
+	overlay := filepath.Join(workDir, "deployments", "dep", "overlay")
+	require.NoError(t, os.MkdirAll(overlay, 0775))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(overlay, "topology.yml"), []byte("v1"), 0664))
+
+	require.NoError(t, BackupDeploymentOverlay("dep", workDir))
+	_, err = os.Stat(overlay)
+	require.True(t, os.IsNotExist(err), "overlay should have been moved")
+	content, err := ioutil.ReadFile(filepath.Join(workDir, "deployments", ".dep", "overlay", "topology.yml"))
+	require.NoError(t, err)
+	require.Equal(t, "v1", string(content))
+
+	// Simulate the extraction of an updated CSAR then restore the previous one
+	require.NoError(t, os.MkdirAll(overlay, 0775))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(overlay, "topology.yml"), []byte("v2"), 0664))
+
+	// An existing backup is never replaced
+	hasBackup, err := HasDeploymentOverlayBackup("dep", workDir)
+	require.NoError(t, err)
+	require.True(t, hasBackup)
+	require.Error(t, BackupDeploymentOverlay("dep", workDir))
+	content, err = ioutil.ReadFile(filepath.Join(workDir, "deployments", ".dep", "overlay", "topology.yml"))
+	require.NoError(t, err)
+	require.Equal(t, "v1", string(content))
+
+	require.NoError(t, RestoreDeploymentOverlay("dep", workDir))
+	content, err = ioutil.ReadFile(filepath.Join(overlay, "topology.yml"))
+	require.NoError(t, err)
+	require.Equal(t, "v1", string(content))
+	_, err = os.Stat(filepath.Join(workDir, "deployments", ".dep"))
+	require.True(t, os.IsNotExist(err), "backup should have been removed")
+	hasBackup, err = HasDeploymentOverlayBackup("dep", workDir)
+	require.NoError(t, err)
+	require.False(t, hasBackup)
+
+	// Restoring again should not remove the restored overlay
+	require.NoError(t, RestoreDeploymentOverlay("dep", workDir))
+	content, err = ioutil.ReadFile(filepath.Join(overlay, "topology.yml"))
+	require.NoError(t, err)
+	require.Equal(t, "v1", string(content))
+
+	// Nothing to backup
+	require.NoError(t, BackupDeploymentOverlay("other", workDir))
+	require.NoError(t, RemoveDeploymentOverlayBackup("other", workDir))
+}
@@ -55,6 +55,7 @@ type Manager interface {
 	GetLocations() ([]LocationConfiguration, error)
 	GetLocationProperties(locationName, locationType string) (config.DynamicMap, error)
 	GetLocationPropertiesForNode(ctx context.Context, deploymentID, nodeName, locationType string) (config.DynamicMap, error)
+	GetLocationNameForNode(ctx context.Context, deploymentID, nodeName, locationType string) (string, error)
 	GetPropertiesForFirstLocationOfType(locationType string) (config.DynamicMap, error)
 
 	Cleanup() error
@@ -223,6 +224,26 @@ func (mgr *locationManager) GetLocationPropertiesForNode(ctx context.Context, de
 
 }
 
+// GetLocationNameForNode returns the name of the location of a node, the one
+// set in the node template metadata or the first location of the given type
+func (mgr *locationManager) GetLocationNameForNode(ctx context.Context, deploymentID, nodeName, locationType string) (string, error) {
+	found, locationName, err := deployments.GetNodeMetadata(ctx, deploymentID, nodeName, tosca.MetadataLocationNameKey)
+	if err != nil || found {
+		return locationName, err
+	}
+
+	locations, err := mgr.GetLocations()
+	if err != nil {
+		return "", err
+	}
+	for _, loc := range locations {
+		if loc.Type == locationType {
+			return loc.Name, nil
+		}
+	}
+	return "", errors.Errorf("Found no location of type %q", locationType)
+}
+
 // GetPropertiesForFirstLocationOfType returns properties for the first location
 // of a given infrastructure type.
 // Returns an error if there is no location of such type
@@ -357,6 +357,13 @@ func testLocationsFromConfig(t *testing.T, srv1 *testutil.TestServer, cc *api.Cl
 	// Check an openstack-specific confiugration value is provided in result
 	assert.Equal(t, "RegionOne", props["region"])
 
+	name, err := mgr.GetLocationNameForNode(ctx, deploymentID, "Compute1", "openstack")
+	require.NoError(t, err, "Unexpected error attempting to get location name for Compute1")
+	assert.Equal(t, "myLocation2", name)
+	name, err = mgr.GetLocationNameForNode(ctx, deploymentID, "Compute2", "slurm")
+	require.NoError(t, err, "Unexpected error attempting to get location name for Compute2")
+	assert.Equal(t, "myLocation3", name)
+
 	props, err = mgr.GetPropertiesForFirstLocationOfType("slurm")
 	require.NoError(t, err, "Unexpected error attempting to get slurm location")
 	assert.Equal(t, "slurmuser3", props["user_name"], "Wrong user name in %+v", props)
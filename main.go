@@ -18,6 +18,8 @@ import (
 	"github.com/ystia/yorc/v4/commands"
 	_ "github.com/ystia/yorc/v4/commands/bootstrap"
 	_ "github.com/ystia/yorc/v4/commands/deployments"
+	_ "github.com/ystia/yorc/v4/commands/deployments/checks"
+	_ "github.com/ystia/yorc/v4/commands/deployments/schedules"
 	_ "github.com/ystia/yorc/v4/commands/deployments/tasks"
 	_ "github.com/ystia/yorc/v4/commands/deployments/workflows"
 	_ "github.com/ystia/yorc/v4/commands/hostspool"
@@ -0,0 +1,75 @@
+// Copyright 2021 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notifications
+
+import (
+	"encoding/json"
+	"path"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/ystia/yorc/v4/helper/consulutil"
+	"github.com/ystia/yorc/v4/log"
+)
+
+// DeadLettersKVPrefix is the prefix in Consul KV store for notifications that could not be delivered
+var DeadLettersKVPrefix = path.Join(consulutil.NotificationsKVPrefix, "dead_letters")
+
+// DeadLetter is a notification that could not be delivered to a webhook
+type DeadLetter struct {
+	// ID is the delivery ID sent to the webhook in the X-Yorc-Delivery header
+	ID           string          `json:"id"`
+	WebhookID    string          `json:"webhook_id"`
+	DeploymentID string          `json:"deployment_id"`
+	EventType    string          `json:"event_type"`
+	Payload      json.RawMessage `json:"payload"`
+	Attempts     int             `json:"attempts"`
+	LastError    string          `json:"last_error"`
+	Time         time.Time       `json:"time"`
+}
+
+// StoreDeadLetter records a notification that could not be delivered
+func StoreDeadLetter(deadLetter *DeadLetter) error {
+	return errors.Wrapf(consulutil.StoreConsulKeyWithJSONValue(path.Join(DeadLettersKVPrefix, deadLetter.WebhookID, deadLetter.ID), deadLetter),
+		"Failed to store dead letter %q of webhook %q", deadLetter.ID, deadLetter.WebhookID)
+}
+
+// ListDeadLetters returns the notifications that could not be delivered to a webhook sorted by time
+func ListDeadLetters(webhookID string) ([]*DeadLetter, error) {
+	kvps, _, err := consulutil.GetKV().List(path.Join(DeadLettersKVPrefix, webhookID)+"/", nil)
+	if err != nil {
+		return nil, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+	}
+	deadLetters := make([]*DeadLetter, 0, len(kvps))
+	for _, kvp := range kvps {
+		deadLetter := new(DeadLetter)
+		if err = json.Unmarshal(kvp.Value, deadLetter); err != nil {
+			log.Printf("[WARN] Ignoring invalid dead letter %q: %v", kvp.Key, err)
+			continue
+		}
+		deadLetters = append(deadLetters, deadLetter)
+	}
+	sort.Slice(deadLetters, func(i, j int) bool {
+		return deadLetters[i].Time.Before(deadLetters[j].Time)
+	})
+	return deadLetters, nil
+}
+
+// DeleteDeadLetters removes all the dead letters of a webhook
+func DeleteDeadLetters(webhookID string) error {
+	return errors.Wrapf(consulutil.Delete(path.Join(DeadLettersKVPrefix, webhookID)+"/", true), "Failed to delete dead letters of webhook %q", webhookID)
+}
@@ -0,0 +1,377 @@
+// Copyright 2021 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/armon/go-metrics"
+	"github.com/hashicorp/consul/api"
+	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+
+	"github.com/ystia/yorc/v4/config"
+	"github.com/ystia/yorc/v4/events"
+	"github.com/ystia/yorc/v4/helper/metricsutil"
+	"github.com/ystia/yorc/v4/log"
+)
+
+const (
+	listenerName = "notifications"
+
+	// SignatureHeader is the HTTP header containing the HMAC-SHA256 signature of the payload
+	SignatureHeader = "X-Yorc-Signature"
+	// EventHeader is the HTTP header containing the type of the notified event
+	EventHeader = "X-Yorc-Event"
+	// DeliveryHeader is the HTTP header containing the unique ID of a delivery, it is the same for all attempts
+	DeliveryHeader = "X-Yorc-Delivery"
+)
+
+var defaultDispatcher *dispatcher
+
+type delivery struct {
+	id      string
+	webhook *Webhook
+	event   events.StatusChange
+	// attempts is the number of failed attempts
+	attempts int
+	// lastErr is the error of the last failed attempt
+	lastErr error
+}
+
+type dispatcher struct {
+	cfg    config.Notifications
+	client *http.Client
+	static []*Webhook
+	// webhooks are the webhooks registered using the REST API
+	webhooks     []*Webhook
+	webhooksLock sync.RWMutex
+	queue        chan *delivery
+	chStop       chan struct{}
+	wg           sync.WaitGroup
+	// retries are the deliveries waiting for their next attempt, it is set to nil on stop
+	retries     map[*delivery]*time.Timer
+	retriesLock sync.Mutex
+	// recordDeadLetter allows to replace the dead letters storage in tests
+	recordDeadLetter func(*DeadLetter) error
+}
+
+func newDispatcher(cfg config.Notifications) *dispatcher {
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = config.DefaultNotificationsQueueSize
+	}
+	if cfg.Workers <= 0 {
+		cfg.Workers = config.DefaultNotificationsWorkers
+	}
+	if cfg.RequestTimeout <= 0 {
+		cfg.RequestTimeout = config.DefaultNotificationsRequestTimeout
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = config.DefaultNotificationsMaxAttempts
+	}
+	d := &dispatcher{
+		cfg:              cfg,
+		client:           &http.Client{Timeout: cfg.RequestTimeout},
+		queue:            make(chan *delivery, cfg.QueueSize),
+		chStop:           make(chan struct{}),
+		retries:          make(map[*delivery]*time.Timer),
+		recordDeadLetter: StoreDeadLetter,
+	}
+	for _, whCfg := range cfg.Webhooks {
+		webhook := newStaticWebhook(whCfg)
+		if webhook.ID == "" {
+			log.Printf("[WARN] Ignoring webhook with url %q defined in configuration without name", webhook.URL)
+			continue
+		}
+		if err := webhook.Validate(); err != nil {
+			log.Printf("[WARN] Ignoring webhook %q defined in configuration: %v", webhook.ID, err)
+			continue
+		}
+		d.static = append(d.static, webhook)
+	}
+	return d
+}
+
+// Start allows to start delivering events to webhooks
+//
+// Webhooks registered using the REST API are watched until shutdownCh is closed or Stop is called.
+func Start(cfg config.Configuration, cc *api.Client, shutdownCh chan struct{}) {
+	defaultDispatcher = newDispatcher(cfg.Notifications)
+	defaultDispatcher.start()
+	defaultDispatcher.wg.Add(1)
+	go defaultDispatcher.watchWebhooks(shutdownCh)
+	events.RegisterStatusChangeListener(listenerName, defaultDispatcher.notify)
+}
+
+// Stop allows to stop delivering events to webhooks
+//
+// Notifications waiting for a retry are recorded as dead letters.
+func Stop() {
+	events.UnregisterStatusChangeListener(listenerName)
+	defaultDispatcher.stop()
+}
+
+// GetStaticWebhooks returns the webhooks defined in the server configuration
+func GetStaticWebhooks() []*Webhook {
+	if defaultDispatcher == nil {
+		return nil
+	}
+	return defaultDispatcher.static
+}
+
+func (d *dispatcher) start() {
+	for i := 0; i < d.cfg.Workers; i++ {
+		d.wg.Add(1)
+		go d.work()
+	}
+}
+
+func (d *dispatcher) stop() {
+	d.retriesLock.Lock()
+	retries := d.retries
+	d.retries = nil
+	d.retriesLock.Unlock()
+	for dl, timer := range retries {
+		timer.Stop()
+		d.deadLetter(dl, dl.attempts, errors.Wrap(dl.lastErr, "server shutdown before next attempt"))
+	}
+	close(d.chStop)
+	d.wg.Wait()
+}
+
+// watchWebhooks watches webhooks registered using the REST API until shutdownCh or the dispatcher stop channel is closed
+func (d *dispatcher) watchWebhooks(shutdownCh chan struct{}) {
+	defer d.wg.Done()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		// Interrupts the pending blocking query
+		select {
+		case <-shutdownCh:
+		case <-d.chStop:
+		case <-ctx.Done():
+		}
+		cancel()
+	}()
+
+	var waitIndex uint64
+	for {
+		webhooks, rMeta, err := ListWebhooksWithOptions((&api.QueryOptions{WaitIndex: waitIndex}).WithContext(ctx))
+		if ctx.Err() != nil {
+			log.Debugf("Stop watching notification webhooks")
+			return
+		}
+		if err != nil {
+			log.Printf("[WARN] Failed to list notification webhooks: %v", err)
+			// Avoid to loop too quickly if Consul is unavailable
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(time.Second):
+			}
+			continue
+		}
+		if waitIndex == rMeta.LastIndex {
+			// long pool ended due to a timeout
+			continue
+		}
+		waitIndex = rMeta.LastIndex
+		d.setWebhooks(webhooks)
+	}
+}
+
+func (d *dispatcher) setWebhooks(webhooks []*Webhook) {
+	d.webhooksLock.Lock()
+	defer d.webhooksLock.Unlock()
+	d.webhooks = webhooks
+}
+
+// notify queues deliveries of an event to the webhooks matching it
+func (d *dispatcher) notify(event events.StatusChange) {
+	d.webhooksLock.RLock()
+	webhooks := append(append([]*Webhook(nil), d.static...), d.webhooks...)
+	d.webhooksLock.RUnlock()
+
+	for _, webhook := range webhooks {
+		if !webhook.Matches(event) {
+			continue
+		}
+		dl := &delivery{id: uuid.NewV4().String(), webhook: webhook, event: event}
+		select {
+		case d.queue <- dl:
+		default:
+			// Do not block events publishers
+			go d.deadLetter(dl, 0, errors.New("notifications queue is full"))
+		}
+	}
+}
+
+func (d *dispatcher) work() {
+	defer d.wg.Done()
+	for {
+		select {
+		case <-d.chStop:
+			d.drain()
+			return
+		case dl := <-d.queue:
+			d.deliver(dl)
+		}
+	}
+}
+
+// drain records remaining deliveries as dead letters on shutdown
+func (d *dispatcher) drain() {
+	for {
+		select {
+		case dl := <-d.queue:
+			d.deadLetter(dl, 0, errors.New("server shutdown"))
+		default:
+			return
+		}
+	}
+}
+
+// deliver performs an attempt to send a notification to a webhook
+//
+// On a retryable failure the next attempt is scheduled after an exponential backoff, outside of the
+// workers, so a failing webhook does not delay deliveries to other webhooks.
+func (d *dispatcher) deliver(dl *delivery) {
+	labels := []metrics.Label{{Name: "Webhook", Value: dl.webhook.ID}}
+	retryable, err := d.send(dl)
+	if err == nil {
+		metrics.IncrCounterWithLabels(metricsutil.CleanupMetricKey([]string{"notifications", "deliveries", "successes"}), 1, labels)
+		return
+	}
+	dl.attempts++
+	dl.lastErr = err
+	metrics.IncrCounterWithLabels(metricsutil.CleanupMetricKey([]string{"notifications", "deliveries", "failures"}), 1, labels)
+	log.Debugf("Attempt %d to deliver notification %q to webhook %q failed: %v", dl.attempts, dl.id, dl.webhook.ID, err)
+	if !retryable || dl.attempts >= d.cfg.MaxAttempts {
+		d.deadLetter(dl, dl.attempts, err)
+		return
+	}
+	d.scheduleRetry(dl)
+}
+
+// scheduleRetry queues again a delivery once the backoff of its last failed attempt is elapsed
+func (d *dispatcher) scheduleRetry(dl *delivery) {
+	d.retriesLock.Lock()
+	if d.retries == nil {
+		d.retriesLock.Unlock()
+		d.deadLetter(dl, dl.attempts, errors.Wrap(dl.lastErr, "server shutdown before next attempt"))
+		return
+	}
+	d.retries[dl] = time.AfterFunc(d.backoff(dl.attempts), func() { d.retry(dl) })
+	d.retriesLock.Unlock()
+}
+
+// retry queues a delivery which backoff is elapsed unless it was already handled on stop
+func (d *dispatcher) retry(dl *delivery) {
+	d.retriesLock.Lock()
+	if _, ok := d.retries[dl]; !ok {
+		d.retriesLock.Unlock()
+		return
+	}
+	delete(d.retries, dl)
+	// Queued while holding the lock so stop either finds the delivery in retries or drains it from the queue
+	var queued bool
+	select {
+	case d.queue <- dl:
+		queued = true
+	default:
+	}
+	d.retriesLock.Unlock()
+	if !queued {
+		d.deadLetter(dl, dl.attempts, errors.Wrap(dl.lastErr, "notifications queue is full"))
+	}
+}
+
+// backoff returns the wait time after the given failed attempt
+func (d *dispatcher) backoff(attempt int) time.Duration {
+	b := d.cfg.InitialBackoff
+	for i := 1; i < attempt && (d.cfg.MaxBackoff <= 0 || b < d.cfg.MaxBackoff); i++ {
+		b *= 2
+	}
+	if d.cfg.MaxBackoff > 0 && b > d.cfg.MaxBackoff {
+		return d.cfg.MaxBackoff
+	}
+	return b
+}
+
+// send performs a single delivery attempt and returns if the error, if any, could be retried
+func (d *dispatcher) send(dl *delivery) (bool, error) {
+	req, err := http.NewRequest(http.MethodPost, dl.webhook.URL, bytes.NewReader(dl.event.Payload))
+	if err != nil {
+		return false, errors.Wrap(err, "failed to create webhook request")
+	}
+	for k, v := range dl.webhook.Headers {
+		req.Header.Set(k, v)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, dl.event.Type.String())
+	req.Header.Set(DeliveryHeader, dl.id)
+	if dl.webhook.Secret != "" {
+		req.Header.Set(SignatureHeader, Sign(dl.webhook.Secret, dl.event.Payload))
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, resp.Body)
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	err = errors.Errorf("webhook responded with status %q", resp.Status)
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500, err
+}
+
+func (d *dispatcher) deadLetter(dl *delivery, attempts int, cause error) {
+	metrics.IncrCounterWithLabels(metricsutil.CleanupMetricKey([]string{"notifications", "deliveries", "deadLetters"}), 1,
+		[]metrics.Label{{Name: "Webhook", Value: dl.webhook.ID}})
+	log.Printf("[WARN] Failed to deliver notification %q of deployment %q to webhook %q after %d attempt(s): %v", dl.id, dl.event.DeploymentID, dl.webhook.ID, attempts, cause)
+	err := d.recordDeadLetter(&DeadLetter{
+		ID:           dl.id,
+		WebhookID:    dl.webhook.ID,
+		DeploymentID: dl.event.DeploymentID,
+		EventType:    dl.event.Type.String(),
+		Payload:      dl.event.Payload,
+		Attempts:     attempts,
+		LastError:    cause.Error(),
+		Time:         time.Now(),
+	})
+	if err != nil {
+		log.Printf("[ERROR] %v", err)
+	}
+}
+
+// Sign returns the value of the signature header of a payload: "sha256=" followed by the hex encoded
+// HMAC-SHA256 of the payload using the given secret
+func Sign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return fmt.Sprintf("sha256=%s", hex.EncodeToString(mac.Sum(nil)))
+}
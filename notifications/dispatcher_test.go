@@ -0,0 +1,229 @@
+// Copyright 2021 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notifications
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ystia/yorc/v4/config"
+	"github.com/ystia/yorc/v4/events"
+)
+
+func newTestDispatcher(maxAttempts int) (*dispatcher, *[]*DeadLetter) {
+	d := newDispatcher(config.Notifications{MaxAttempts: maxAttempts, InitialBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond})
+	var deadLetters []*DeadLetter
+	var lock sync.Mutex
+	d.recordDeadLetter = func(dl *DeadLetter) error {
+		lock.Lock()
+		defer lock.Unlock()
+		deadLetters = append(deadLetters, dl)
+		return nil
+	}
+	return d, &deadLetters
+}
+
+func testEvent() events.StatusChange {
+	return events.StatusChange{
+		Type:         events.StatusChangeTypeDeployment,
+		DeploymentID: "myApp",
+		Status:       "deployed",
+		Payload:      []byte(`{"deploymentId":"myApp","status":"deployed","type":"Deployment"}`),
+	}
+}
+
+func TestSign(t *testing.T) {
+	assert.Equal(t, "sha256=b82fcb791acec57859b989b430a826488ce2e479fdf92326bd0a2e8375a42ba4", Sign("secret", []byte("payload")))
+	assert.NotEqual(t, Sign("secret", []byte("payload")), Sign("other", []byte("payload")))
+}
+
+func TestDispatcherBackoff(t *testing.T) {
+	d := newDispatcher(config.Notifications{InitialBackoff: time.Second, MaxBackoff: 5 * time.Second})
+	assert.Equal(t, time.Second, d.backoff(1))
+	assert.Equal(t, 2*time.Second, d.backoff(2))
+	assert.Equal(t, 4*time.Second, d.backoff(3))
+	assert.Equal(t, 5*time.Second, d.backoff(4))
+	assert.Equal(t, 5*time.Second, d.backoff(100))
+}
+
+func TestDispatcherDeliver(t *testing.T) {
+	event := testEvent()
+
+	t.Run("SignedDelivery", func(t *testing.T) {
+		var received *http.Request
+		var body []byte
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			received = r
+			body, _ = ioutil.ReadAll(r.Body)
+		}))
+		defer srv.Close()
+
+		d, deadLetters := newTestDispatcher(3)
+		d.deliver(&delivery{id: "d1", webhook: &Webhook{ID: "wh", URL: srv.URL, Secret: "s3cr3t", Headers: map[string]string{"X-Custom": "value"}}, event: event})
+		require.NotNil(t, received)
+		assert.Len(t, *deadLetters, 0)
+		assert.Equal(t, string(event.Payload), string(body))
+		assert.Equal(t, "application/json", received.Header.Get("Content-Type"))
+		assert.Equal(t, "Deployment", received.Header.Get(EventHeader))
+		assert.Equal(t, "d1", received.Header.Get(DeliveryHeader))
+		assert.Equal(t, "value", received.Header.Get("X-Custom"))
+		assert.Equal(t, Sign("s3cr3t", event.Payload), received.Header.Get(SignatureHeader))
+	})
+
+	t.Run("RetryOnServerError", func(t *testing.T) {
+		var calls int32
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Empty(t, r.Header.Get(SignatureHeader))
+			if atomic.AddInt32(&calls, 1) < 3 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}
+		}))
+		defer srv.Close()
+
+		d, deadLetters := newTestDispatcher(3)
+		d.start()
+		d.queue <- &delivery{id: "d2", webhook: &Webhook{ID: "wh", URL: srv.URL}, event: event}
+		require.Eventually(t, func() bool { return atomic.LoadInt32(&calls) == 3 }, 5*time.Second, time.Millisecond)
+		d.stop()
+		assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+		assert.Len(t, *deadLetters, 0)
+	})
+
+	t.Run("DeadLetterAfterMaxAttempts", func(t *testing.T) {
+		var calls int32
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&calls, 1)
+			w.WriteHeader(http.StatusTooManyRequests)
+		}))
+		defer srv.Close()
+
+		d, deadLetters := newTestDispatcher(2)
+		d.start()
+		d.queue <- &delivery{id: "d3", webhook: &Webhook{ID: "wh", URL: srv.URL}, event: event}
+		require.Eventually(t, func() bool { return atomic.LoadInt32(&calls) == 2 }, 5*time.Second, time.Millisecond)
+		d.stop()
+		assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+		require.Len(t, *deadLetters, 1)
+		dl := (*deadLetters)[0]
+		assert.Equal(t, "d3", dl.ID)
+		assert.Equal(t, "wh", dl.WebhookID)
+		assert.Equal(t, "myApp", dl.DeploymentID)
+		assert.Equal(t, 2, dl.Attempts)
+		assert.Contains(t, dl.LastError, "429")
+		assert.Equal(t, string(event.Payload), string(dl.Payload))
+	})
+
+	t.Run("NoRetryOnClientError", func(t *testing.T) {
+		calls := 0
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		defer srv.Close()
+
+		d, deadLetters := newTestDispatcher(5)
+		d.deliver(&delivery{id: "d4", webhook: &Webhook{ID: "wh", URL: srv.URL}, event: event})
+		assert.Equal(t, 1, calls)
+		require.Len(t, *deadLetters, 1)
+		assert.Equal(t, 1, (*deadLetters)[0].Attempts)
+	})
+}
+
+func TestDispatcherRetriesDoNotBlockWorkers(t *testing.T) {
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer failing.Close()
+	delivered := make(chan struct{})
+	working := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(delivered)
+	}))
+	defer working.Close()
+
+	d := newDispatcher(config.Notifications{Workers: 1, MaxAttempts: 3, InitialBackoff: time.Hour})
+	var deadLetters []*DeadLetter
+	var lock sync.Mutex
+	d.recordDeadLetter = func(dl *DeadLetter) error {
+		lock.Lock()
+		defer lock.Unlock()
+		deadLetters = append(deadLetters, dl)
+		return nil
+	}
+	d.start()
+	d.queue <- &delivery{id: "failing", webhook: &Webhook{ID: "failing", URL: failing.URL}, event: testEvent()}
+	d.queue <- &delivery{id: "working", webhook: &Webhook{ID: "working", URL: working.URL}, event: testEvent()}
+	select {
+	case <-delivered:
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "delivery to the working webhook was blocked by the retries of the failing one")
+	}
+
+	// The pending retry is recorded as a dead letter on stop
+	d.stop()
+	require.Len(t, deadLetters, 1)
+	assert.Equal(t, "failing", deadLetters[0].ID)
+	assert.Equal(t, 1, deadLetters[0].Attempts)
+	assert.Contains(t, deadLetters[0].LastError, "server shutdown")
+}
+
+func TestDispatcherNotify(t *testing.T) {
+	d, deadLetters := newTestDispatcher(1)
+	d.static = []*Webhook{
+		{ID: "all", URL: "http://localhost"},
+		{ID: "failures", URL: "http://localhost", Statuses: []string{"deployment_failed"}},
+	}
+	d.setWebhooks([]*Webhook{{ID: "myApp", URL: "http://localhost", Deployments: []string{"myApp"}}})
+
+	d.notify(testEvent())
+	require.Len(t, d.queue, 2)
+	assert.Equal(t, "all", (<-d.queue).webhook.ID)
+	assert.Equal(t, "myApp", (<-d.queue).webhook.ID)
+	assert.Len(t, *deadLetters, 0)
+}
+
+func testWatchWebhooksShutdown(t *testing.T) {
+	d, _ := newTestDispatcher(1)
+	shutdownCh := make(chan struct{})
+	done := make(chan struct{})
+	d.wg.Add(1)
+	go func() {
+		d.watchWebhooks(shutdownCh)
+		close(done)
+	}()
+
+	id, err := RegisterWebhook(&Webhook{URL: "http://hooks.example.com/yorc"})
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		d.webhooksLock.RLock()
+		defer d.webhooksLock.RUnlock()
+		return len(d.webhooks) == 1 && d.webhooks[0].ID == id
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// The watcher is blocked in a Consul long poll and should return as soon as the server shuts down
+	close(shutdownCh)
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "webhooks watcher did not return on server shutdown")
+	}
+}
@@ -0,0 +1,185 @@
+// Copyright 2021 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package notifications delivers deployments events to HTTP webhooks
+//
+// Deployments, tasks, workflow steps and instances status changes published by the events package are
+// sent as JSON payloads to the webhooks which filters match them. Deliveries are retried with an exponential
+// backoff and notifications that could not be delivered are recorded as dead letters.
+package notifications
+
+import (
+	"encoding/json"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+
+	"github.com/ystia/yorc/v4/config"
+	"github.com/ystia/yorc/v4/events"
+	"github.com/ystia/yorc/v4/helper/collections"
+	"github.com/ystia/yorc/v4/helper/consulutil"
+	"github.com/ystia/yorc/v4/log"
+)
+
+// WebhooksKVPrefix is the prefix in Consul KV store for webhooks registered using the REST API
+var WebhooksKVPrefix = path.Join(consulutil.NotificationsKVPrefix, "webhooks")
+
+// Webhook is an HTTP endpoint notified of events matching its filters
+type Webhook struct {
+	ID   string `json:"id"`
+	Name string `json:"name,omitempty"`
+	URL  string `json:"url"`
+	// Secret is used to sign payloads with HMAC-SHA256
+	Secret  string            `json:"secret,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+	// Deployments, EventTypes and Statuses filter notified events, an empty filter matches any value
+	Deployments []string `json:"deployments,omitempty"`
+	EventTypes  []string `json:"event_types,omitempty"`
+	Statuses    []string `json:"statuses,omitempty"`
+	// Static webhooks are defined in the server configuration and can't be modified using the REST API
+	Static bool `json:"static,omitempty"`
+}
+
+// Validate checks that the webhook definition is valid
+func (wh *Webhook) Validate() error {
+	u, err := url.Parse(wh.URL)
+	if err != nil {
+		return errors.Wrapf(err, "invalid webhook url %q", wh.URL)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.Errorf("invalid webhook url %q: expecting an absolute http or https url", wh.URL)
+	}
+	for _, t := range wh.EventTypes {
+		if _, err := events.ParseStatusChangeType(strings.ToLower(t)); err != nil {
+			return errors.Errorf("unknown event type %q", t)
+		}
+	}
+	return nil
+}
+
+// Matches checks if an event matches the webhook filters
+func (wh *Webhook) Matches(event events.StatusChange) bool {
+	if len(wh.Deployments) > 0 && !collections.ContainsString(wh.Deployments, event.DeploymentID) {
+		return false
+	}
+	if len(wh.EventTypes) > 0 && !containsStringIgnoreCase(wh.EventTypes, event.Type.String()) {
+		return false
+	}
+	return len(wh.Statuses) == 0 || containsStringIgnoreCase(wh.Statuses, event.Status)
+}
+
+func containsStringIgnoreCase(values []string, s string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// newStaticWebhook builds a webhook from its definition in the server configuration, its name is used as ID
+func newStaticWebhook(cfg config.Webhook) *Webhook {
+	return &Webhook{
+		ID:          cfg.Name,
+		Name:        cfg.Name,
+		URL:         cfg.URL,
+		Secret:      cfg.Secret,
+		Headers:     cfg.Headers,
+		Deployments: cfg.Deployments,
+		EventTypes:  cfg.EventTypes,
+		Statuses:    cfg.Statuses,
+		Static:      true,
+	}
+}
+
+// RegisterWebhook allows to register a webhook and returns its ID
+func RegisterWebhook(webhook *Webhook) (string, error) {
+	if err := webhook.Validate(); err != nil {
+		return "", err
+	}
+	webhook.ID = uuid.NewV4().String()
+	webhook.Static = false
+	log.Debugf("Registering webhook %q with url %q", webhook.ID, webhook.URL)
+	return webhook.ID, storeWebhook(webhook)
+}
+
+// UpdateWebhook allows to update the definition of an existing webhook
+func UpdateWebhook(webhook *Webhook) error {
+	if err := webhook.Validate(); err != nil {
+		return err
+	}
+	existing, err := GetWebhook(webhook.ID)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return errors.Errorf("Webhook with ID %q does not exist", webhook.ID)
+	}
+	webhook.Static = false
+	return storeWebhook(webhook)
+}
+
+func storeWebhook(webhook *Webhook) error {
+	return errors.Wrapf(consulutil.StoreConsulKeyWithJSONValue(path.Join(WebhooksKVPrefix, webhook.ID), webhook), "Failed to store webhook %q", webhook.ID)
+}
+
+// UnregisterWebhook allows to remove a webhook and its dead letters
+func UnregisterWebhook(id string) error {
+	log.Debugf("Unregister webhook with id:%q", id)
+	err := consulutil.Delete(path.Join(WebhooksKVPrefix, id), false)
+	if err != nil {
+		return errors.Wrapf(err, "Failed to delete webhook with id:%q", id)
+	}
+	return DeleteDeadLetters(id)
+}
+
+// GetWebhook returns a webhook registered using the REST API or nil if it does not exist
+func GetWebhook(id string) (*Webhook, error) {
+	exist, value, err := consulutil.GetValue(path.Join(WebhooksKVPrefix, id))
+	if err != nil || !exist {
+		return nil, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+	}
+	webhook := new(Webhook)
+	err = json.Unmarshal(value, webhook)
+	return webhook, errors.Wrapf(err, "Failed to decode webhook %q", id)
+}
+
+// ListWebhooks returns the webhooks registered using the REST API, static webhooks are not returned
+func ListWebhooks() ([]*Webhook, error) {
+	webhooks, _, err := ListWebhooksWithOptions(nil)
+	return webhooks, err
+}
+
+// ListWebhooksWithOptions returns the webhooks registered using the REST API with the given query options
+// (allowing to perform blocking queries)
+func ListWebhooksWithOptions(q *api.QueryOptions) ([]*Webhook, *api.QueryMeta, error) {
+	kvps, meta, err := consulutil.GetKV().List(WebhooksKVPrefix+"/", q)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+	}
+	webhooks := make([]*Webhook, 0, len(kvps))
+	for _, kvp := range kvps {
+		webhook := new(Webhook)
+		if err = json.Unmarshal(kvp.Value, webhook); err != nil {
+			log.Printf("[WARN] Ignoring invalid webhook definition %q: %v", kvp.Key, err)
+			continue
+		}
+		webhooks = append(webhooks, webhook)
+	}
+	return webhooks, meta, nil
+}
@@ -0,0 +1,70 @@
+// Copyright 2021 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notifications
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ystia/yorc/v4/events"
+)
+
+func TestWebhookValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		webhook Webhook
+		wantErr bool
+	}{
+		{"Valid", Webhook{URL: "https://hooks.example.com/yorc", EventTypes: []string{"deployment", "WorkflowStep"}}, false},
+		{"MissingURL", Webhook{}, true},
+		{"RelativeURL", Webhook{URL: "/yorc"}, true},
+		{"UnsupportedScheme", Webhook{URL: "ftp://hooks.example.com/yorc"}, true},
+		{"UnknownEventType", Webhook{URL: "http://hooks.example.com", EventTypes: []string{"foo"}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.webhook.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestWebhookMatches(t *testing.T) {
+	event := events.StatusChange{Type: events.StatusChangeTypeDeployment, DeploymentID: "myApp", Status: "deployment_failed"}
+	tests := []struct {
+		name    string
+		webhook Webhook
+		want    bool
+	}{
+		{"NoFilters", Webhook{}, true},
+		{"Deployment", Webhook{Deployments: []string{"other", "myApp"}}, true},
+		{"OtherDeployment", Webhook{Deployments: []string{"other"}}, false},
+		{"EventType", Webhook{EventTypes: []string{"deployment"}}, true},
+		{"OtherEventType", Webhook{EventTypes: []string{"instance", "workflow"}}, false},
+		{"Status", Webhook{Statuses: []string{"DEPLOYMENT_FAILED"}}, true},
+		{"OtherStatus", Webhook{Statuses: []string{"deployed"}}, false},
+		{"AllFilters", Webhook{Deployments: []string{"myApp"}, EventTypes: []string{"Deployment"}, Statuses: []string{"deployment_failed"}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.webhook.Matches(event))
+		})
+	}
+}
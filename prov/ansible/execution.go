@@ -123,6 +123,8 @@ type hostConnection struct {
 	password    string
 	bastion     *sshutil.BastionHostConfig
 	osType      string
+	// vaultSSHSigningPath supersedes the server configuration to sign private keys before connecting
+	vaultSSHSigningPath string
 }
 
 type sshCredentials struct {
@@ -371,6 +373,7 @@ func (e *executionCommon) setHostConnection(ctx context.Context, host, instanceI
 		if err != nil {
 			return err
 		}
+		conn.vaultSSHSigningPath = credentials.VaultSSHSigningPath
 
 		port, err := deployments.GetInstanceCapabilityAttributeValue(ctx, e.deploymentID, host, instanceID, "endpoint", "port")
 		if err != nil {
@@ -838,12 +841,20 @@ func (e *executionCommon) getSSHCredentials(ctx context.Context, host *hostConne
 		host.privateKeys = sshPrivateKeys
 		events.WithContextOptionalFields(ctx).NewLogEntry(events.LogLevelWARN, e.deploymentID).RegisterAsString("Ansible provisioning: Missing ssh password or private key information, trying to use default private key ~/.ssh/yorc.pem.")
 	}
+	// Get fresh certificates before each execution as they are typically short-lived
+	signingPath := host.vaultSSHSigningPath
+	if signingPath == "" {
+		signingPath = e.cfg.SSHVaultSigningPath
+	}
+	if err := sshutil.SignKeysWithVault(sshPrivateKeys, signingPath, sshUser); err != nil {
+		return creds, err
+	}
 	creds.privateKeys = sshPrivateKeys
 	creds.password = sshPassword
 	return creds, nil
 }
 
-func (e *executionCommon) generateHostConnection(ctx context.Context, buffer *bytes.Buffer, host *hostConnection) error {
+func (e *executionCommon) generateHostConnection(ctx context.Context, buffer *bytes.Buffer, ansibleRecipePath string, host *hostConnection) error {
 	buffer.WriteString(host.host)
 
 	if host.bastion != nil {
@@ -877,6 +888,13 @@ func (e *executionCommon) generateHostConnection(ctx context.Context, buffer *by
 				return errors.Errorf("%d private keys provided (may include the default key %q) but none are stored on disk. As ssh-agent is disabled by configuration we can't use direct key content.", len(sshCredentials.privateKeys), sshutil.DefaultSSHPrivateKeyFilePath)
 			}
 			buffer.WriteString(fmt.Sprintf(" ansible_ssh_private_key_file=%s", key.Path))
+			if len(key.Certificate) > 0 {
+				certPath := filepath.Join(ansibleRecipePath, host.host+"-cert.pub")
+				if err = ioutil.WriteFile(certPath, key.Certificate, 0644); err != nil {
+					return errors.Wrapf(err, "failed to write SSH certificate for host %q", host.host)
+				}
+				buffer.WriteString(fmt.Sprintf(" ansible_ssh_extra_args='-o CertificateFile=%s'", certPath))
+			}
 		} else if sshCredentials.password != "" {
 			// TODO use ansible vault
 			buffer.WriteString(fmt.Sprintf(" ansible_ssh_pass=%s", sshCredentials.password))
@@ -957,7 +975,7 @@ func (e *executionCommon) executeWithCurrentInstance(ctx context.Context, retry
 	buffer.WriteString(fmt.Sprintf("[%s]\n", emptySectionHeader))
 	buffer.WriteString(fmt.Sprintf("[%s]\n", header))
 	for instanceName, host := range e.hosts {
-		err = e.generateHostConnection(ctx, &buffer, host)
+		err = e.generateHostConnection(ctx, &buffer, ansibleRecipePath, host)
 		if err != nil {
 			return err
 		}
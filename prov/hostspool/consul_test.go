@@ -116,6 +116,18 @@ func TestRunConsulHostsPoolPackageTests(t *testing.T) {
 	t.Run("testConsulManagerAddLabelsWithAllocation", func(t *testing.T) {
 		testConsulManagerAddLabelsWithAllocation(t, client, cfg)
 	})
+	t.Run("testConsulManagerMaintenance", func(t *testing.T) {
+		testConsulManagerMaintenance(t, client, cfg)
+	})
+	t.Run("testConsulManagerHealthChecks", func(t *testing.T) {
+		testConsulManagerHealthChecks(t, client, cfg)
+	})
+	t.Run("testConsulManagerReservations", func(t *testing.T) {
+		testConsulManagerReservations(t, client, cfg)
+	})
+	t.Run("testConsulManagerLeases", func(t *testing.T) {
+		testConsulManagerLeases(t, client, cfg)
+	})
 	t.Run("testCreateFiltersFromComputeCapabilities", func(t *testing.T) {
 		testCreateFiltersFromComputeCapabilities(t, deploymentID)
 	})
@@ -68,6 +68,30 @@ func IsNoMatchingHostFoundError(err error) bool {
 	return ok
 }
 
+type allocationNotFoundError struct{}
+
+func (e allocationNotFoundError) Error() string {
+	return "allocation not found on host"
+}
+
+// IsAllocationNotFoundError checks if an error is an "allocation not found" error
+func IsAllocationNotFoundError(err error) bool {
+	_, ok := errors.Cause(err).(allocationNotFoundError)
+	return ok
+}
+
+type reservationNotFoundError struct{}
+
+func (e reservationNotFoundError) Error() string {
+	return "reservation not found in pool"
+}
+
+// IsReservationNotFoundError checks if an error is a "reservation not found" error
+func IsReservationNotFoundError(err error) bool {
+	_, ok := errors.Cause(err).(reservationNotFoundError)
+	return ok
+}
+
 type hostConnectionError struct {
 	message string
 }
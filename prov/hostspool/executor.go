@@ -17,10 +17,12 @@ package hostspool
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"github.com/ystia/yorc/v4/helper/collections"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/hashicorp/consul/api"
 	"github.com/hashicorp/go-multierror"
@@ -184,38 +186,158 @@ func (e *defaultExecutor) hostsPoolCreate(ctx context.Context,
 		return err
 	}
 
+	lease, err := getLeaseOptions(ctx, op.deploymentID, op.nodeName)
+	if err != nil {
+		return err
+	}
+
 	instances, err := tasks.GetInstances(ctx, op.taskID, op.deploymentID, op.nodeName)
 	if err != nil {
 		return err
 	}
 
-	return e.allocateHostsToInstances(ctx, instances, shareable, filters, op, allocatedResources, placement, genericResources)
+	return e.allocateHostsToInstances(ctx, instances, shareable, filters, op, allocatedResources, placement, lease, genericResources)
 }
 
-func (e *defaultExecutor) getPlacementPolicy(ctx context.Context, op operationParameters, target string) (string, error) {
-	placementPolicies, err := deployments.GetPoliciesForTypeAndNode(ctx, op.deploymentID, placementPolicy, target)
+// leaseOptions are the reservation and lease duration of the allocations of a node
+type leaseOptions struct {
+	reservation string
+	duration    time.Duration
+}
+
+func getLeaseOptions(ctx context.Context, deploymentID, nodeName string) (leaseOptions, error) {
+	var lease leaseOptions
+	reservation, err := deployments.GetNodePropertyValue(ctx, deploymentID, nodeName, "reservation")
 	if err != nil {
-		return "", err
+		return lease, err
+	}
+	if reservation != nil {
+		lease.reservation = reservation.RawString()
 	}
+	duration, err := deployments.GetNodePropertyValue(ctx, deploymentID, nodeName, "lease_duration")
+	if err != nil {
+		return lease, err
+	}
+	if duration != nil && duration.RawString() != "" {
+		lease.duration, err = time.ParseDuration(duration.RawString())
+		if err != nil {
+			return lease, errors.Wrapf(err, `failed to parse property "lease_duration" for node %q`, nodeName)
+		}
+	}
+	return lease, nil
+}
+
+// placementOptions gathers the placement policies applying to a node
+type placementOptions struct {
+	policy     string
+	capacity   *CapacityPlacement
+	affinities []AffinityRule
+}
 
-	if len(placementPolicies) == 0 {
-		return "", nil
+func (e *defaultExecutor) getPlacementPolicy(ctx context.Context, op operationParameters, target string) (placementOptions, error) {
+	var placement placementOptions
+	placementPolicies, err := deployments.GetPoliciesForTypeAndNode(ctx, op.deploymentID, placementPolicy, target)
+	if err != nil {
+		return placement, err
 	}
 
 	if len(placementPolicies) > 1 {
-		return "", errors.Errorf("Found more than one placement policy to apply to node name:%q", target)
+		return placement, errors.Errorf("Found more than one placement policy to apply to node name:%q", target)
 	}
 
-	policyType, err := deployments.GetPolicyType(ctx, op.deploymentID, placementPolicies[0])
-	if err != nil {
-		return "", err
+	if len(placementPolicies) == 1 {
+		placement.policy, err = deployments.GetPolicyType(ctx, op.deploymentID, placementPolicies[0])
+		if err != nil {
+			return placement, err
+		}
+
+		if err = op.hpManager.CheckPlacementPolicy(placement.policy); err != nil {
+			return placement, err
+		}
+
+		if placement.policy == capacityPlacement {
+			placement.capacity, err = getCapacityPlacement(ctx, op.deploymentID, placementPolicies[0])
+			if err != nil {
+				return placement, err
+			}
+		}
 	}
 
-	if err = op.hpManager.CheckPlacementPolicy(policyType); err != nil {
-		return "", err
+	placement.affinities, err = getAffinityRules(ctx, op.deploymentID, target)
+	return placement, err
+}
+
+func getCapacityPlacement(ctx context.Context, deploymentID, policyName string) (*CapacityPlacement, error) {
+	capacity := &CapacityPlacement{Strategy: packCapacityStrategy}
+	strategyValue, err := deployments.GetPolicyPropertyValue(ctx, deploymentID, policyName, "strategy")
+	if err != nil {
+		return nil, err
+	}
+	if strategyValue != nil && strategyValue.RawString() != "" {
+		capacity.Strategy = strategyValue.RawString()
 	}
+	resourcesValue, err := deployments.GetPolicyPropertyValue(ctx, deploymentID, policyName, "resources")
+	if err != nil {
+		return nil, err
+	}
+	if resourcesValue != nil && resourcesValue.RawString() != "" {
+		list, ok := resourcesValue.Value.([]interface{})
+		if !ok {
+			return nil, errors.Errorf("failed to retrieve resources list of placement policy %q: not expected type", policyName)
+		}
+		for _, r := range list {
+			capacity.Resources = append(capacity.Resources, fmt.Sprint(r))
+		}
+	}
+	return capacity, CheckCapacityPlacement(capacity)
+}
 
-	return policyType, nil
+func getAffinityRules(ctx context.Context, deploymentID, target string) ([]AffinityRule, error) {
+	policies, err := deployments.GetPoliciesForTypeAndNode(ctx, deploymentID, affinityPolicy, target)
+	if err != nil {
+		return nil, err
+	}
+	rules := make([]AffinityRule, 0, len(policies))
+	for _, policyName := range policies {
+		policyType, err := deployments.GetPolicyType(ctx, deploymentID, policyName)
+		if err != nil {
+			return nil, err
+		}
+		rule := AffinityRule{
+			AntiAffinity: policyType == antiAffinityPlacement,
+			Scope:        nodeAffinityScope,
+			Required:     true,
+		}
+		scopeValue, err := deployments.GetPolicyPropertyValue(ctx, deploymentID, policyName, "scope")
+		if err != nil {
+			return nil, err
+		}
+		if scopeValue != nil && scopeValue.RawString() != "" {
+			rule.Scope = scopeValue.RawString()
+		}
+		labelValue, err := deployments.GetPolicyPropertyValue(ctx, deploymentID, policyName, "topology_label")
+		if err != nil {
+			return nil, err
+		}
+		if labelValue != nil {
+			rule.TopologyLabel = labelValue.RawString()
+		}
+		requiredValue, err := deployments.GetPolicyPropertyValue(ctx, deploymentID, policyName, "required")
+		if err != nil {
+			return nil, err
+		}
+		if requiredValue != nil && requiredValue.RawString() != "" {
+			rule.Required, err = strconv.ParseBool(requiredValue.RawString())
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid required property value for affinity policy %q", policyName)
+			}
+		}
+		if err = CheckAffinityRule(rule); err != nil {
+			return nil, errors.Wrapf(err, "invalid affinity policy %q", policyName)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
 }
 
 func (e *defaultExecutor) allocateHostsToInstances(
@@ -225,7 +347,8 @@ func (e *defaultExecutor) allocateHostsToInstances(
 	filters []labelsutil.Filter,
 	op operationParameters,
 	allocatedResources map[string]string,
-	placement string,
+	placement placementOptions,
+	lease leaseOptions,
 	genericResources []*GenericResource) error {
 
 	for _, instance := range instances {
@@ -240,13 +363,17 @@ func (e *defaultExecutor) allocateHostsToInstances(
 		instanceFilters = append(filters, genericResourcesFilters...)
 
 		allocation := &Allocation{
-			NodeName:         op.nodeName,
-			Instance:         instance,
-			DeploymentID:     op.deploymentID,
-			Shareable:        shareable,
-			Resources:        allocatedResources,
-			PlacementPolicy:  placement,
-			GenericResources: genericResources,
+			NodeName:          op.nodeName,
+			Instance:          instance,
+			DeploymentID:      op.deploymentID,
+			Shareable:         shareable,
+			Resources:         allocatedResources,
+			PlacementPolicy:   placement.policy,
+			CapacityPlacement: placement.capacity,
+			Affinities:        placement.affinities,
+			Reservation:       lease.reservation,
+			LeaseDuration:     lease.duration,
+			GenericResources:  genericResources,
 		}
 
 		// Protecting the allocation and update of resources labels by a mutex, to
@@ -317,6 +444,10 @@ func (e *defaultExecutor) updateConnectionSettings(ctx context.Context, op opera
 			"0": host.Connection.PrivateKey,
 		},
 	}
+	if host.Connection.Certificate != "" {
+		credentials.Certificates = map[string]string{"0": host.Connection.Certificate}
+	}
+	credentials.VaultSSHSigningPath = host.Connection.VaultSSHSigningPath
 
 	var credentialsMap map[string]interface{}
 	err = mapstructure.Decode(credentials, &credentialsMap)
@@ -439,7 +570,14 @@ func (e *defaultExecutor) hostsPoolDelete(originalCtx context.Context, cc *api.C
 		}
 		allocation, err := op.hpManager.Release(op.location, hostname.RawString(), op.deploymentID, op.nodeName, instance)
 		if err != nil {
-			errs = multierror.Append(errs, err)
+			if IsAllocationNotFoundError(err) {
+				events.WithContextOptionalFields(ctx).NewLogEntry(events.LogLevelWARN, op.deploymentID).Registerf(
+					"host %q of instance %q of node %q was already released. This may be due to an expired lease.",
+					hostname.RawString(), instance, op.nodeName)
+			} else {
+				errs = multierror.Append(errs, err)
+			}
+			continue
 		}
 		err = op.hpManager.UpdateResourcesLabels(op.location, hostname.RawString(), allocatedResources, add, updateResourcesLabels, allocation.GenericResources, addElements, updateGenericResourcesLabels)
 		if err != nil {
@@ -0,0 +1,271 @@
+// Copyright 2021 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hostspool
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/armon/go-metrics"
+	"github.com/hashicorp/consul/api"
+	"github.com/pkg/errors"
+
+	"github.com/ystia/yorc/v4/config"
+	"github.com/ystia/yorc/v4/events"
+	"github.com/ystia/yorc/v4/helper/metricsutil"
+	"github.com/ystia/yorc/v4/log"
+)
+
+const healthCheckErrorMessage = "health check command failed"
+
+var defaultHealthChecker *leaderTask
+
+// StartHealthChecks starts the periodic health checks of hosts pools hosts if they are enabled in the configuration.
+// Only the leader of the health checks service runs checks.
+func StartHealthChecks(cfg config.Configuration, cc *api.Client) {
+	if cfg.HostsPool.HealthChecks.Interval <= 0 {
+		log.Debugf("Hosts pools periodic health checks are disabled")
+		return
+	}
+	hcCfg := cfg.HostsPool.HealthChecks
+	if hcCfg.Timeout <= 0 {
+		hcCfg.Timeout = config.DefaultHostsPoolHealthChecksTimeout
+	}
+	if hcCfg.Concurrency <= 0 {
+		hcCfg.Concurrency = config.DefaultHostsPoolHealthChecksConcurrency
+	}
+	cm := NewManager(cc, cfg).(*consulManager)
+	defaultHealthChecker = newLeaderTask("health checks", "hosts_pool_health_checks", hcCfg.Interval, func(ctx context.Context) error {
+		return cm.checkLocationsHealth(ctx, hcCfg)
+	})
+	defaultHealthChecker.watchLeadership(cc)
+}
+
+// StopHealthChecks stops the periodic health checks of hosts pools hosts
+func StopHealthChecks() {
+	if defaultHealthChecker == nil {
+		return
+	}
+	defaultHealthChecker.shutdown()
+}
+
+// checkLocationsHealth checks the health of every host of all hosts pools locations
+// and publishes the number of hosts by status of each location
+//
+// At most hcCfg.Concurrency hosts are checked at the same time. Checks are interrupted when
+// the given context is cancelled, interrupted checks don't change hosts statuses.
+func (cm *consulManager) checkLocationsHealth(ctx context.Context, hcCfg config.HostsPoolHealthChecks) error {
+	concurrency := hcCfg.Concurrency
+	if concurrency <= 0 {
+		concurrency = config.DefaultHostsPoolHealthChecksConcurrency
+	}
+	locations, err := cm.ListLocations()
+	if err != nil {
+		return err
+	}
+	for _, location := range locations {
+		hostnames, _, _, err := cm.List(location)
+		if err != nil {
+			return err
+		}
+		hostnamesCh := make(chan string)
+		var waitGroup sync.WaitGroup
+		for i := 0; i < concurrency && i < len(hostnames); i++ {
+			waitGroup.Add(1)
+			go func() {
+				defer waitGroup.Done()
+				for hostname := range hostnamesCh {
+					cm.checkHostHealth(ctx, location, hostname, hcCfg)
+				}
+			}()
+		}
+	dispatch:
+		for _, hostname := range hostnames {
+			select {
+			case hostnamesCh <- hostname:
+			case <-ctx.Done():
+				break dispatch
+			}
+		}
+		close(hostnamesCh)
+		waitGroup.Wait()
+		if ctx.Err() != nil {
+			return errors.Wrap(ctx.Err(), "hosts pools health checks interrupted")
+		}
+
+		cm.publishHostsStatusesMetrics(location, hostnames)
+	}
+	return nil
+}
+
+// checkHostHealth checks the connection to a host and runs the probe command if any.
+// A host failing its health check is put in error, a host in error passing its health check
+// returns to its status before the failure.
+func (cm *consulManager) checkHostHealth(ctx context.Context, locationName, hostname string, hcCfg config.HostsPoolHealthChecks) {
+	status, err := cm.GetHostStatus(locationName, hostname)
+	if err != nil {
+		// No such host anymore
+		return
+	}
+
+	message, checkErr := cm.runHealthCheck(ctx, locationName, hostname, hcCfg)
+	if ctx.Err() != nil {
+		log.Debugf("Health check of host %q on location %q interrupted", hostname, locationName)
+		return
+	}
+	metricsLabels := []metrics.Label{metrics.Label{Name: "Location", Value: locationName}}
+	if checkErr != nil {
+		metrics.IncrCounterWithLabels(metricsutil.CleanupMetricKey([]string{"hostspool", "healthChecks", "failures"}), 1, metricsLabels)
+		log.Debugf("Health check of host %q on location %q failed: %v", hostname, locationName, checkErr)
+	} else {
+		metrics.IncrCounterWithLabels(metricsutil.CleanupMetricKey([]string{"hostspool", "healthChecks", "successes"}), 1, metricsLabels)
+	}
+	if checkErr == nil && status != HostStatusError || checkErr != nil && status == HostStatusError {
+		// Nothing changed
+		return
+	}
+
+	_, cleanupFn, err := cm.lockKey(locationName, hostname, "health check", maxWaitTimeSeconds*time.Second)
+	if err != nil {
+		log.Printf("[WARN] Failed to update status of host %q on location %q after its health check: %v", hostname, locationName, err)
+		return
+	}
+	defer cleanupFn()
+	// Status may have been updated during the check
+	status, err = cm.GetHostStatus(locationName, hostname)
+	if err != nil {
+		return
+	}
+	if checkErr != nil && status != HostStatusError {
+		err = cm.backupHostStatus(locationName, hostname)
+		if err == nil {
+			err = cm.setHostStatusWithMessage(locationName, hostname, HostStatusError, message)
+		}
+		if err == nil {
+			log.Printf("[WARN] Host %q on location %q failed its health check, it is now in error: %s", hostname, locationName, message)
+			cm.publishHostStatusChange(locationName, hostname, HostStatusError, message)
+		}
+	} else if checkErr == nil && status == HostStatusError {
+		err = cm.restoreHostStatus(locationName, hostname)
+		if err == nil {
+			var newStatus HostStatus
+			newStatus, err = cm.GetHostStatus(locationName, hostname)
+			if err == nil {
+				log.Printf("Host %q on location %q passed its health check, it is now %s", hostname, locationName, newStatus)
+				cm.publishHostStatusChange(locationName, hostname, newStatus, "")
+			}
+		}
+	}
+	if err != nil {
+		log.Printf("[WARN] Failed to update status of host %q on location %q after its health check: %v", hostname, locationName, err)
+	}
+}
+
+// runHealthCheck checks the connection to a host then runs the probe command if any, it returns the message to set on the host
+// and the error of the check if it failed.
+// Checks lasting more than the configured timeout are considered as failed, the check is abandoned if the context is cancelled.
+func (cm *consulManager) runHealthCheck(ctx context.Context, locationName, hostname string, hcCfg config.HostsPoolHealthChecks) (string, error) {
+	type result struct {
+		err     error
+		message string
+	}
+	resultCh := make(chan result, 1)
+	go func() {
+		err := cm.checkConnection(locationName, hostname)
+		if err != nil {
+			resultCh <- result{err, connectionErrorMessage(err)}
+			return
+		}
+		if hcCfg.Command == "" {
+			resultCh <- result{}
+			return
+		}
+		err = cm.runHealthCheckCommand(locationName, hostname, hcCfg.Command)
+		if err != nil {
+			resultCh <- result{err, fmt.Sprintf("%s: %v", healthCheckErrorMessage, errors.Cause(err))}
+			return
+		}
+		resultCh <- result{}
+	}()
+
+	var timeout <-chan time.Time
+	if hcCfg.Timeout > 0 {
+		timer := time.NewTimer(hcCfg.Timeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	select {
+	case r := <-resultCh:
+		return r.message, r.err
+	case <-timeout:
+		return fmt.Sprintf("health check timed out after %v", hcCfg.Timeout), errors.Errorf("health check of host %q timed out after %v", hostname, hcCfg.Timeout)
+	case <-ctx.Done():
+		return "", errors.Wrapf(ctx.Err(), "health check of host %q interrupted", hostname)
+	}
+}
+
+// runHealthCheckCommand runs the health check probe command on a host
+func (cm *consulManager) runHealthCheckCommand(locationName, hostname, command string) error {
+	conn, err := cm.GetHostConnection(locationName, hostname)
+	if err != nil {
+		return err
+	}
+	conf, err := getSSHConfig(cm.cfg, locationName, conn, nil)
+	if err != nil {
+		return err
+	}
+	out, err := cm.getSSHClient(conf, conn).RunCommand(command)
+	return errors.Wrapf(err, "output: %q", out)
+}
+
+// publishHostStatusChange publishes a status change of a host for each deployment having allocations on this host
+func (cm *consulManager) publishHostStatusChange(locationName, hostname string, status HostStatus, message string) {
+	allocations, err := cm.getAllocations(locationName, hostname)
+	if err != nil {
+		log.Printf("[WARN] Failed to publish status change of host %q on location %q: %v", hostname, locationName, err)
+		return
+	}
+	deploymentIDs := make(map[string]struct{})
+	for _, alloc := range allocations {
+		if _, done := deploymentIDs[alloc.DeploymentID]; done || alloc.DeploymentID == "" {
+			continue
+		}
+		deploymentIDs[alloc.DeploymentID] = struct{}{}
+		_, err = events.PublishAndLogHostsPoolHostStatusChange(context.Background(), alloc.DeploymentID, locationName, hostname, status.String(), message)
+		if err != nil {
+			log.Printf("[WARN] Failed to publish status change of host %q on location %q for deployment %q: %v", hostname, locationName, alloc.DeploymentID, err)
+		}
+	}
+}
+
+// publishHostsStatusesMetrics publishes the number of hosts by status of a location
+func (cm *consulManager) publishHostsStatusesMetrics(locationName string, hostnames []string) {
+	counts := make(map[HostStatus]int)
+	for _, hostname := range hostnames {
+		status, err := cm.GetHostStatus(locationName, hostname)
+		if err != nil {
+			continue
+		}
+		counts[status]++
+	}
+	for _, status := range []HostStatus{HostStatusFree, HostStatusAllocated, HostStatusError, HostStatusMaintenance} {
+		metrics.SetGaugeWithLabels(metricsutil.CleanupMetricKey([]string{"hostspool", "hosts"}), float32(counts[status]), []metrics.Label{
+			metrics.Label{Name: "Location", Value: locationName},
+			metrics.Label{Name: "Status", Value: status.String()},
+		})
+	}
+}
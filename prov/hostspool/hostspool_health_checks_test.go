@@ -0,0 +1,139 @@
+// Copyright 2021 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hostspool
+
+import (
+	"context"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+
+	"github.com/ystia/yorc/v4/config"
+	"github.com/ystia/yorc/v4/events"
+	"github.com/ystia/yorc/v4/helper/consulutil"
+	"github.com/ystia/yorc/v4/helper/sshutil"
+)
+
+func testConsulManagerHealthChecks(t *testing.T, cc *api.Client, cfg config.Configuration) {
+	location := "healthChecksLocation"
+	cleanupHostsPool(t, cc)
+	cm := &consulManager{cc, cfg, func(config *ssh.ClientConfig, conn Connection) sshutil.Client {
+		return &sshutil.MockSSHClient{
+			MockRunCommand: func(cmd string) (string, error) {
+				if config != nil && config.User == "fail" {
+					return "", errors.Errorf("Failed to connect")
+				}
+				switch cmd {
+				case "exit 1":
+					return "disk full", errors.Errorf("Process exited with status 1")
+				case "sleep 1":
+					time.Sleep(time.Second)
+				}
+				return "ok", nil
+			},
+		}
+	}}
+
+	var checkpoint uint64
+	err := cm.Apply(location, createHosts(2), &checkpoint)
+	require.NoError(t, err)
+	deploymentID := strings.Replace(t.Name(), "/", "_", -1)
+	hostname, _, err := cm.Allocate(location, &Allocation{NodeName: "node_test", Instance: "0", DeploymentID: deploymentID})
+	require.NoError(t, err)
+
+	userKey := path.Join(consulutil.HostsPoolPrefix, location, hostname, "connection", "user")
+	setUser := func(user string) {
+		t.Helper()
+		_, err := cc.KV().Put(&api.KVPair{Key: userKey, Value: []byte(user)}, nil)
+		require.NoError(t, err)
+	}
+	checkHost := func(hcCfg config.HostsPoolHealthChecks, expectedStatus HostStatus, expectedMessage string) {
+		t.Helper()
+		cm.checkHostHealth(context.Background(), location, hostname, hcCfg)
+		host, err := cm.GetHost(location, hostname)
+		require.NoError(t, err)
+		assert.Equal(t, expectedStatus, host.Status)
+		assert.True(t, strings.HasPrefix(host.Message, expectedMessage), "unexpected message %q", host.Message)
+	}
+
+	hcCfg := config.HostsPoolHealthChecks{Interval: time.Minute, Timeout: time.Minute}
+	checkHost(hcCfg, HostStatusAllocated, "allocated for node instance")
+
+	// Host goes down
+	setUser("fail")
+	checkHost(hcCfg, HostStatusError, hostConnectionErrorMessage)
+	checkHost(hcCfg, HostStatusError, hostConnectionErrorMessage)
+
+	evts, _, err := events.StatusEvents(context.Background(), deploymentID, 0, time.Millisecond)
+	require.NoError(t, err)
+	require.Len(t, evts, 1)
+	assert.Contains(t, string(evts[0]), `"hostname":"`+hostname+`"`)
+	assert.Contains(t, string(evts[0]), `"status":"error"`)
+
+	// Host recovers
+	setUser("ok")
+	checkHost(hcCfg, HostStatusAllocated, "allocated for node instance")
+	evts, _, err = events.StatusEvents(context.Background(), deploymentID, 0, time.Millisecond)
+	require.NoError(t, err)
+	require.Len(t, evts, 2)
+
+	// Probe command failure
+	hcCfg.Command = "exit 1"
+	checkHost(hcCfg, HostStatusError, healthCheckErrorMessage)
+	hcCfg.Command = "true"
+	checkHost(hcCfg, HostStatusAllocated, "allocated for node instance")
+
+	// Probe command too long
+	hcCfg.Command = "sleep 1"
+	hcCfg.Timeout = 10 * time.Millisecond
+	checkHost(hcCfg, HostStatusError, "health check timed out")
+
+	// Interrupted checks don't change the host status
+	hcCfg.Timeout = time.Minute
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+	setUser("ok")
+	cm.checkHostHealth(ctx, location, hostname, hcCfg)
+	status, err := cm.GetHostStatus(location, hostname)
+	require.NoError(t, err)
+	assert.Equal(t, HostStatusError, status)
+	err = cm.checkLocationsHealth(ctx, hcCfg)
+	require.Error(t, err)
+
+	// All hosts of all locations
+	hcCfg.Command = ""
+	hcCfg.Concurrency = 1
+	setUser("fail")
+	err = cm.checkLocationsHealth(context.Background(), hcCfg)
+	require.NoError(t, err)
+	hostnames, _, _, err := cm.List(location)
+	require.NoError(t, err)
+	for _, h := range hostnames {
+		status, err := cm.GetHostStatus(location, h)
+		require.NoError(t, err)
+		if h == hostname {
+			assert.Equal(t, HostStatusError, status)
+		} else {
+			assert.Equal(t, HostStatusFree, status)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+// Copyright 2021 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hostspool
+
+import (
+	"context"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/consul/api"
+
+	"github.com/ystia/yorc/v4/helper/consulutil"
+	"github.com/ystia/yorc/v4/log"
+)
+
+// leaderTask periodically runs a function on all hosts pools locations.
+// Only the leader of the related service runs it, the context given to the function is cancelled
+// when the task is stopped.
+type leaderTask struct {
+	// name is used in logs
+	name        string
+	interval    time.Duration
+	serviceKey  string
+	runFn       func(ctx context.Context) error
+	chShutdown  chan struct{}
+	chStop      chan struct{}
+	isRunning   bool
+	isRunningMu sync.Mutex
+}
+
+func newLeaderTask(name, serviceName string, interval time.Duration, runFn func(ctx context.Context) error) *leaderTask {
+	return &leaderTask{
+		name:       name,
+		interval:   interval,
+		serviceKey: path.Join(consulutil.YorcServicePrefix, serviceName, "leader"),
+		runFn:      runFn,
+		chShutdown: make(chan struct{}),
+	}
+}
+
+// watchLeadership starts or stops running the task according to the leadership of this server
+func (t *leaderTask) watchLeadership(cc *api.Client) {
+	go consulutil.WatchLeaderElection(cc, t.serviceKey, t.chShutdown, t.start, t.stop)
+}
+
+// shutdown stops running the task and watching the leadership
+func (t *leaderTask) shutdown() {
+	t.stop()
+	close(t.chShutdown)
+}
+
+func (t *leaderTask) start() {
+	t.isRunningMu.Lock()
+	defer t.isRunningMu.Unlock()
+	if t.isRunning {
+		log.Debugf("Hosts pools %s already running", t.name)
+		return
+	}
+	log.Printf("Starting hosts pools %s every %v", t.name, t.interval)
+	t.isRunning = true
+	t.chStop = make(chan struct{})
+	go t.run(t.chStop)
+}
+
+func (t *leaderTask) stop() {
+	t.isRunningMu.Lock()
+	defer t.isRunningMu.Unlock()
+	if !t.isRunning {
+		return
+	}
+	log.Debugf("Stopping hosts pools %s", t.name)
+	close(t.chStop)
+	t.isRunning = false
+}
+
+func (t *leaderTask) run(chStop chan struct{}) {
+	ticker := time.NewTicker(t.interval)
+	defer ticker.Stop()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		// Interrupts the running function
+		select {
+		case <-chStop:
+		case <-t.chShutdown:
+		case <-ctx.Done():
+		}
+		cancel()
+	}()
+	for {
+		select {
+		case <-chStop:
+			log.Debugf("Ending hosts pools %s has been requested: stop it now.", t.name)
+			return
+		case <-t.chShutdown:
+			log.Debugf("Shutdown has been sent: stop hosts pools %s now.", t.name)
+			return
+		case <-ticker.C:
+			err := t.runFn(ctx)
+			if err != nil {
+				log.Printf("[WARN] Hosts pools %s failed: %v", t.name, err)
+				log.Debugf("%+v", err)
+			}
+		}
+	}
+}
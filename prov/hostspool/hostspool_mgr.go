@@ -66,6 +66,13 @@ type Manager interface {
 	ListLocations() ([]string, error)
 	RemoveLocation(locationName string) error
 	CheckPlacementPolicy(placementPolicy string) error
+	StartMaintenance(locationName, hostname, reason string, end time.Time) error
+	EndMaintenance(locationName, hostname string) error
+	Reserve(locationName string, reservation *Reservation, count int, filters ...labelsutil.Filter) ([]labelsutil.Warning, error)
+	ListReservations(locationName string) ([]Reservation, error)
+	GetReservation(locationName, name string) (*Reservation, error)
+	CancelReservation(locationName, name string) error
+	RenewLease(locationName, hostname, allocationID string, duration time.Duration) (time.Time, error)
 }
 
 // SSHClientFactory is a that could be called to customize the client used to check the connection.
@@ -136,7 +143,7 @@ func (cm *consulManager) addWait(locationName, hostname string, conn Connection,
 
 	err = cm.checkConnection(locationName, hostname)
 	if err != nil {
-		cm.setHostStatusWithMessage(locationName, hostname, HostStatusError, hostConnectionErrorMessage)
+		cm.setHostStatusWithMessage(locationName, hostname, HostStatusError, connectionErrorMessage(err))
 		return errors.WithStack(hostConnectionError{message: err.Error()})
 	}
 	return nil
@@ -158,10 +165,16 @@ func (cm *consulManager) getAddOperations(
 	if hostname == "" {
 		return nil, errors.WithStack(badRequestError{`"hostname" missing`})
 	}
+	if hostname == ReservationsHostname {
+		return nil, errors.WithStack(badRequestError{fmt.Sprintf("%q is a reserved name that can't be used for hosts", hostname)})
+	}
 
 	if conn.Password == "" && conn.PrivateKey == "" {
 		return nil, errors.WithStack(badRequestError{`at least "password" or "private_key" is required for a host pool connection`})
 	}
+	if err := checkHostKey(conn.HostKey); err != nil {
+		return nil, err
+	}
 
 	user := conn.User
 	if user == "" {
@@ -212,6 +225,21 @@ func (cm *consulManager) getAddOperations(
 			Key:   path.Join(hostKVPrefix, "connection", "port"),
 			Value: []byte(strconv.FormatUint(port, 10)),
 		},
+		&api.KVTxnOp{
+			Verb:  api.KVSet,
+			Key:   path.Join(hostKVPrefix, "connection", "host_key"),
+			Value: []byte(conn.HostKey),
+		},
+		&api.KVTxnOp{
+			Verb:  api.KVSet,
+			Key:   path.Join(hostKVPrefix, "connection", "certificate"),
+			Value: []byte(conn.Certificate),
+		},
+		&api.KVTxnOp{
+			Verb:  api.KVSet,
+			Key:   path.Join(hostKVPrefix, "connection", "vault_ssh_signing_path"),
+			Value: []byte(conn.VaultSSHSigningPath),
+		},
 	}
 
 	if message != "" {
@@ -298,6 +326,14 @@ func (cm *consulManager) getRemoveOperations(locationName, hostname string, chec
 		switch status {
 		case HostStatusFree, HostStatusError:
 			// Ok go ahead
+		case HostStatusMaintenance:
+			allocations, err := cm.getAllocations(locationName, hostname)
+			if err != nil {
+				return nil, err
+			}
+			if len(allocations) > 0 {
+				return nil, errors.WithStack(badRequestError{fmt.Sprintf("can't delete host %q for location %q in maintenance with %d allocations", hostname, locationName, len(allocations))})
+			}
 		default:
 			return nil, errors.WithStack(badRequestError{fmt.Sprintf("can't delete host %q for location %q with status %q", hostname, locationName, status.String())})
 		}
@@ -514,6 +550,9 @@ func (cm *consulManager) getStatus(locationName, hostname string, backup bool) (
 	if err != nil {
 		return HostStatus(0), errors.Wrapf(err, "failed to retrieve %s for host: %q and location: %q", keyname, hostname, locationName)
 	}
+	if status == HostStatusMaintenance && !backup {
+		return cm.checkMaintenanceEnd(locationName, hostname, kvp.ModifyIndex)
+	}
 
 	return status, nil
 }
@@ -592,20 +631,54 @@ func (cm *consulManager) GetHost(locationName, hostname string) (Host, error) {
 	if err != nil {
 		return host, err
 	}
+	if host.Status == HostStatusMaintenance {
+		host.MaintenanceEnd, err = cm.getMaintenanceEnd(locationName, hostname)
+		if err != nil {
+			return host, err
+		}
+	}
 
 	host.Labels, err = cm.GetHostLabels(locationName, hostname)
 	return host, err
 }
 
-func getSSHConfig(cfg config.Configuration, conn Connection) (*ssh.ClientConfig, error) {
+func getSSHConfig(cfg config.Configuration, locationName string, conn Connection, onHostKeyChanged func(err error)) (*ssh.ClientConfig, error) {
 	conf := &ssh.ClientConfig{
-		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
-		User:            conn.User,
-		Timeout:         cfg.SSHConnectionTimeout,
+		User:    conn.User,
+		Timeout: cfg.SSHConnectionTimeout,
+	}
+
+	hostKeyOpts := sshutil.HostKeyOptions{
+		Mode:           cfg.SSHHostKeyChecking,
+		KnownHostsFile: cfg.SSHKnownHostsFile,
+		HostKey:        conn.HostKey,
+		Scope:          path.Join("hostspool", locationName),
+	}
+	err := sshutil.ConfigureHostKeyVerification(conf, conn.Host, int(conn.Port), hostKeyOpts, onHostKeyChanged)
+	if err != nil {
+		return nil, err
 	}
 
 	if conn.PrivateKey != "" {
-		keyAuth, err := sshutil.ReadPrivateKey(conn.PrivateKey)
+		key, err := sshutil.GetPrivateKey(conn.PrivateKey)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse private key %q", conn.PrivateKey)
+		}
+		if conn.Certificate != "" {
+			if err = key.SetCertificate(conn.Certificate); err != nil {
+				return nil, err
+			}
+		}
+		signingPath := conn.VaultSSHSigningPath
+		if signingPath == "" {
+			signingPath = cfg.SSHVaultSigningPath
+		}
+		if signingPath != "" {
+			if err = key.SignWithVault(signingPath, conn.User); err != nil {
+				return nil, err
+			}
+		}
+		keyAuth, err := sshutil.ReadSSHPrivateKey(key)
 		if err != nil {
 			return nil, err
 		}
@@ -618,6 +691,17 @@ func getSSHConfig(cfg config.Configuration, conn Connection) (*ssh.ClientConfig,
 	return conf, nil
 }
 
+// checkHostKey checks that a pinned host key, if any, is in the authorized_keys format
+func checkHostKey(hostKey string) error {
+	if hostKey == "" || hostKey == "-" {
+		return nil
+	}
+	if _, _, _, _, err := ssh.ParseAuthorizedKey([]byte(hostKey)); err != nil {
+		return errors.WithStack(badRequestError{fmt.Sprintf("invalid host_key %q, expecting a public key in the authorized_keys format: %v", hostKey, err)})
+	}
+	return nil
+}
+
 // Apply a Hosts Pool configuration.
 // If checkpoint is not nil, it should point to a value returned by a previous
 // call to the List() function described above. A checkpoint verification will
@@ -745,6 +829,16 @@ func (cm *consulManager) applyWait(
 				return err
 			}
 			addOps = append(addOps, ops...)
+			// Keep the scheduled end of maintenance if any
+			maintenanceEnd, err := cm.getMaintenanceEnd(locationName, host.Name)
+			if err != nil {
+				return err
+			}
+			if maintenanceEnd != nil {
+				addOps = append(addOps, getKVTxnOp(api.KVSet,
+					path.Join(consulutil.HostsPoolPrefix, locationName, host.Name, maintenanceEndKeyName),
+					[]byte(maintenanceEnd.UTC().Format(time.RFC3339))))
+			}
 		} else {
 			// Host is new, creating it
 			hostChanged = append(hostChanged, host.Name)
@@ -833,5 +927,9 @@ func (cm *consulManager) RemoveLocation(locationName string) error {
 	if err != nil {
 		return errors.Wrapf(err, "failed to remove hosts pool location %s", locationName)
 	}
+	_, err = cm.cc.KV().DeleteTree(path.Join(reservationsKVPrefix, locationName)+"/", nil)
+	if err != nil {
+		return errors.Wrapf(err, "failed to remove reservations of hosts pool location %s", locationName)
+	}
 	return err
 }
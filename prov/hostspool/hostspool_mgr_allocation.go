@@ -41,6 +41,9 @@ const (
 type hostCandidate struct {
 	name        string
 	allocations int
+	// labels and allocs are only retrieved when required by the placement policy
+	labels map[string]string
+	allocs []Allocation
 }
 
 func (cm *consulManager) Allocate(locationName string, allocation *Allocation, filters ...labelsutil.Filter) (string, []labelsutil.Warning, error) {
@@ -62,6 +65,14 @@ func (cm *consulManager) allocateWait(locationName string, maxWaitTime time.Dura
 	if err != nil {
 		return "", warnings, err
 	}
+	reservations, err := cm.ListReservations(locationName)
+	if err != nil {
+		return "", warnings, err
+	}
+	hosts, err = filterReservedHosts(allocation, hosts, reservations)
+	if err != nil {
+		return "", warnings, err
+	}
 	// define host candidates in only free or allocated hosts in case of shareable allocation
 	candidates := make([]hostCandidate, 0)
 	var lastErr error
@@ -98,6 +109,7 @@ func (cm *consulManager) allocateWait(locationName string, maxWaitTime time.Dura
 				candidates = append(candidates, hostCandidate{
 					name:        h,
 					allocations: len(allocations),
+					allocs:      allocations,
 				})
 			}
 		}
@@ -110,8 +122,21 @@ func (cm *consulManager) allocateWait(locationName string, maxWaitTime time.Dura
 		return "", warnings, errors.WithStack(noMatchingHostFoundError{})
 	}
 
+	if allocation.needsCandidatesDetails() {
+		if err = cm.loadCandidatesDetails(locationName, candidates); err != nil {
+			return "", warnings, err
+		}
+		candidates, err = cm.applyAffinities(locationName, allocation, candidates)
+		if err != nil {
+			return "", warnings, err
+		}
+	}
+
 	// Apply the policy placement
 	hostname := cm.electHostFromCandidates(locationName, allocation, candidates)
+	if hostname == "" {
+		return "", warnings, errors.WithStack(noMatchingHostFoundError{})
+	}
 	select {
 	case <-lockCh:
 		return "", warnings, errors.New("admin lock lost on hosts pool during host allocation")
@@ -129,6 +154,9 @@ func (cm *consulManager) electHostFromCandidates(locationName string, allocation
 	case weightBalancedPlacement:
 		log.Printf("Applying weight-balanced placement policy for location:%s, deployment:%s, node name:%s, instance:%s", locationName, allocation.DeploymentID, allocation.NodeName, allocation.Instance)
 		return weightBalanced(candidates)
+	case capacityPlacement:
+		log.Printf("Applying capacity placement policy for location:%s, deployment:%s, node name:%s, instance:%s", locationName, allocation.DeploymentID, allocation.NodeName, allocation.Instance)
+		return capacityBased(allocation, candidates)
 	case binPackingPlacement:
 		log.Printf("Applying bin packing placement policy for location:%s, deployment:%s, node name:%s, instance:%s", locationName, allocation.DeploymentID, allocation.NodeName, allocation.Instance)
 		return binPacking(candidates)
@@ -173,8 +201,12 @@ func (cm *consulManager) releaseWait(locationName, hostname, deploymentID, nodeN
 	}
 	defer cleanupFn()
 
+	allocationID := buildAllocationID(deploymentID, nodeName, instance)
+	if err = cm.checkAllocationExists(locationName, hostname, allocationID); err != nil {
+		return nil, err
+	}
 	// Need to retrieve complete information about allocation for resources updates
-	allocation, err := cm.getAllocation(locationName, hostname, buildAllocationID(deploymentID, nodeName, instance))
+	allocation, err := cm.getAllocation(locationName, hostname, allocationID)
 	if err != nil {
 		return nil, err
 	}
@@ -187,7 +219,8 @@ func (cm *consulManager) releaseWait(locationName, hostname, deploymentID, nodeN
 		return nil, err
 	}
 	// Set the host status to free only for host with no allocations
-	if len(host.Allocations) == 0 {
+	// Hosts in maintenance stay in maintenance
+	if len(host.Allocations) == 0 && host.Status != HostStatusMaintenance {
 		if err = cm.setHostStatus(locationName, hostname, HostStatusFree); err != nil {
 			return nil, err
 		}
@@ -195,7 +228,7 @@ func (cm *consulManager) releaseWait(locationName, hostname, deploymentID, nodeN
 	err = cm.checkConnection(locationName, hostname)
 	if err != nil {
 		cm.backupHostStatus(locationName, hostname)
-		cm.setHostStatusWithMessage(locationName, hostname, HostStatusError, hostConnectionErrorMessage)
+		cm.setHostStatusWithMessage(locationName, hostname, HostStatusError, connectionErrorMessage(err))
 	}
 	return allocation, nil
 }
@@ -222,6 +255,12 @@ func getAddAllocationsOperation(locationName, hostname string, allocations []All
 				getKVTxnOp(api.KVSet, path.Join(allocKVPrefix, "shareable"), []byte(strconv.FormatBool(alloc.Shareable))),
 				getKVTxnOp(api.KVSet, path.Join(allocKVPrefix, "placement_policy"), []byte(alloc.PlacementPolicy)),
 			}
+			if alloc.Reservation != "" {
+				allocOps = append(allocOps, getKVTxnOp(api.KVSet, path.Join(allocKVPrefix, "reservation"), []byte(alloc.Reservation)))
+			}
+			if alloc.LeaseExpiry != nil {
+				allocOps = append(allocOps, getKVTxnOp(api.KVSet, path.Join(allocKVPrefix, leaseExpiryKeyName), []byte(alloc.LeaseExpiry.UTC().Format(time.RFC3339))))
+			}
 
 			for k, v := range alloc.Resources {
 				k = url.PathEscape(k)
@@ -249,6 +288,11 @@ func (cm *consulManager) addAllocation(locationName, hostname string, allocation
 	var allocOps api.KVTxnOps
 	var err error
 
+	if allocation.LeaseDuration > 0 {
+		leaseExpiry := time.Now().Add(allocation.LeaseDuration)
+		allocation.LeaseExpiry = &leaseExpiry
+	}
+
 	if allocation.GenericResources != nil {
 		if err = cm.allocateGenericResources(locationName, hostname, allocation); err != nil {
 			return err
@@ -344,6 +388,17 @@ func (cm *consulManager) removeAllocation(locationName, hostname string, allocat
 	return errors.Wrap(err, consulutil.ConsulGenericErrMsg)
 }
 
+func (cm *consulManager) checkAllocationExists(locationName, hostname, allocationID string) error {
+	kvp, _, err := cm.cc.KV().Get(path.Join(consulutil.HostsPoolPrefix, locationName, hostname, "allocations", allocationID), nil)
+	if err != nil {
+		return errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+	}
+	if kvp == nil {
+		return errors.WithStack(allocationNotFoundError{})
+	}
+	return nil
+}
+
 func exist(allocations []Allocation, ID string) bool {
 	for _, alloc := range allocations {
 		if alloc.ID == ID {
@@ -387,6 +442,7 @@ func (cm *consulManager) getAllocation(locationName, hostname, allocationID stri
 		{"instance", &alloc.Instance},
 		{"deployment_id", &alloc.DeploymentID},
 		{"placement_policy", &alloc.PlacementPolicy},
+		{"reservation", &alloc.Reservation},
 	}
 
 	key := path.Join(consulutil.HostsPoolPrefix, locationName, hostname, "allocations", allocationID)
@@ -410,6 +466,10 @@ func (cm *consulManager) getAllocation(locationName, hostname, allocationID stri
 			return nil, errors.Wrapf(err, "failed to parse boolean from value:%q", string(kvp.Value))
 		}
 	}
+	alloc.LeaseExpiry, err = cm.getLeaseExpiry(locationName, hostname, allocationID)
+	if err != nil {
+		return nil, err
+	}
 	// Retrieve resources
 	alloc.Resources, err = cm.getResourcesForAllocation(locationName, hostname, allocationID)
 	if err != nil {
@@ -464,7 +524,7 @@ func (cm *consulManager) CheckPlacementPolicy(placementPolicy string) error {
 	}
 
 	switch placementPolicy {
-	case weightBalancedPlacement, binPackingPlacement:
+	case weightBalancedPlacement, binPackingPlacement, capacityPlacement:
 		return nil
 	default:
 		return errors.Errorf("placement policy:%q is not actually supported", placementPolicy)
@@ -15,6 +15,7 @@
 package hostspool
 
 import (
+	"context"
 	"path"
 	"strconv"
 	"strings"
@@ -25,7 +26,10 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/ystia/yorc/v4/config"
+	"github.com/ystia/yorc/v4/events"
 	"github.com/ystia/yorc/v4/helper/consulutil"
+	"github.com/ystia/yorc/v4/helper/sshutil"
+	"github.com/ystia/yorc/v4/log"
 )
 
 const hostConnectionErrorMessage = "failed to connect to host"
@@ -105,6 +109,41 @@ func (cm *consulManager) updateConnectionWait(locationName, hostname string, con
 		})
 	}
 
+	if conn.HostKey != "" {
+		if err := checkHostKey(conn.HostKey); err != nil {
+			return err
+		}
+		if conn.HostKey == "-" {
+			conn.HostKey = ""
+		}
+		ops = append(ops, &api.KVTxnOp{
+			Verb:  api.KVSet,
+			Key:   path.Join(hostKVPrefix, "connection", "host_key"),
+			Value: []byte(conn.HostKey),
+		})
+	}
+
+	if conn.Certificate != "" {
+		if conn.Certificate == "-" {
+			conn.Certificate = ""
+		}
+		ops = append(ops, &api.KVTxnOp{
+			Verb:  api.KVSet,
+			Key:   path.Join(hostKVPrefix, "connection", "certificate"),
+			Value: []byte(conn.Certificate),
+		})
+	}
+	if conn.VaultSSHSigningPath != "" {
+		if conn.VaultSSHSigningPath == "-" {
+			conn.VaultSSHSigningPath = ""
+		}
+		ops = append(ops, &api.KVTxnOp{
+			Verb:  api.KVSet,
+			Key:   path.Join(hostKVPrefix, "connection", "vault_ssh_signing_path"),
+			Value: []byte(conn.VaultSSHSigningPath),
+		})
+	}
+
 	_, cleanupFn, err := cm.lockKey(locationName, hostname, "update", maxWaitTime)
 	if err != nil {
 		return err
@@ -128,7 +167,7 @@ func (cm *consulManager) updateConnectionWait(locationName, hostname string, con
 	if err != nil {
 		if status != HostStatusError {
 			cm.backupHostStatus(locationName, hostname)
-			cm.setHostStatusWithMessage(locationName, hostname, HostStatusError, hostConnectionErrorMessage)
+			cm.setHostStatusWithMessage(locationName, hostname, HostStatusError, connectionErrorMessage(err))
 		}
 		return errors.WithStack(hostConnectionError{message: err.Error()})
 	}
@@ -197,6 +236,28 @@ func (cm *consulManager) GetHostConnection(locationName, hostname string) (Conne
 		conn.PrivateKey = string(kvp.Value)
 		conn.PrivateKey = config.DefaultConfigTemplateResolver.ResolveValueWithTemplates("Connection.PrivateKey", conn.PrivateKey).(string)
 	}
+	kvp, _, err = kv.Get(path.Join(connKVPrefix, "host_key"), nil)
+	if err != nil {
+		return conn, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+	}
+	if kvp != nil {
+		conn.HostKey = string(kvp.Value)
+	}
+	kvp, _, err = kv.Get(path.Join(connKVPrefix, "certificate"), nil)
+	if err != nil {
+		return conn, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+	}
+	if kvp != nil {
+		conn.Certificate = string(kvp.Value)
+		conn.Certificate = config.DefaultConfigTemplateResolver.ResolveValueWithTemplates("Connection.Certificate", conn.Certificate).(string)
+	}
+	kvp, _, err = kv.Get(path.Join(connKVPrefix, "vault_ssh_signing_path"), nil)
+	if err != nil {
+		return conn, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+	}
+	if kvp != nil {
+		conn.VaultSSHSigningPath = string(kvp.Value)
+	}
 	kvp, _, err = kv.Get(path.Join(connKVPrefix, "port"), nil)
 	if err != nil {
 		return conn, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
@@ -217,16 +278,47 @@ func (cm *consulManager) checkConnection(locationName, hostname string) error {
 	if err != nil {
 		return errors.Wrapf(err, "failed to connect to host %q", hostname)
 	}
-	conf, err := getSSHConfig(cm.cfg, conn)
+	// The SSH library does not preserve the host key callback error type so we keep it aside
+	var hostKeyErr error
+	conf, err := getSSHConfig(cm.cfg, locationName, conn, func(err error) {
+		hostKeyErr = err
+	})
 	if err != nil {
 		return errors.Wrapf(err, "failed to connect to host %q", hostname)
 	}
 
 	client := cm.getSSHClient(conf, conn)
 	_, err = client.RunCommand(`echo "Connected!"`)
+	if hostKeyErr != nil {
+		log.Printf("[ERROR] Host %q on location %q: %v", hostname, locationName, hostKeyErr)
+		return errors.Wrapf(hostKeyErr, "failed to connect to host %q", hostname)
+	}
 	return errors.Wrapf(err, "failed to connect to host %q", hostname)
 }
 
+// connectionErrorMessage returns the message set on a host when a connection check fails
+func connectionErrorMessage(err error) string {
+	if sshutil.IsHostKeyChangedError(err) {
+		return errors.Cause(err).Error()
+	}
+	return hostConnectionErrorMessage
+}
+
+// publishHostKeyChangedEvents publishes an error event for each deployment having an allocation on a host
+// presenting an unexpected SSH host key
+func (cm *consulManager) publishHostKeyChangedEvents(locationName, hostname string, err error) {
+	allocations, allocErr := cm.getAllocations(locationName, hostname)
+	if allocErr != nil {
+		log.Printf("[WARNING] failed to get allocations of host %q on location %q: %v", hostname, locationName, allocErr)
+		return
+	}
+	for _, alloc := range allocations {
+		ctx := events.AddLogOptionalFields(context.Background(), events.LogOptionalFields{events.NodeID: alloc.NodeName, events.InstanceID: alloc.Instance})
+		events.WithContextOptionalFields(ctx).NewLogEntry(events.LogLevelERROR, alloc.DeploymentID).Registerf(
+			"Host %q of hosts pool location %q is not reachable anymore as its SSH host key changed: %v", hostname, locationName, errors.Cause(err))
+	}
+}
+
 // Go routine checking a Host connection and updating the Host status
 func (cm *consulManager) updateConnectionStatus(locationName, name string, waitGroup *sync.WaitGroup) {
 	defer waitGroup.Done()
@@ -240,7 +332,10 @@ func (cm *consulManager) updateConnectionStatus(locationName, name string, waitG
 	if err != nil {
 		if status != HostStatusError {
 			cm.backupHostStatus(locationName, name)
-			cm.setHostStatusWithMessage(locationName, name, HostStatusError, hostConnectionErrorMessage)
+			cm.setHostStatusWithMessage(locationName, name, HostStatusError, connectionErrorMessage(err))
+			if sshutil.IsHostKeyChangedError(err) {
+				cm.publishHostKeyChangedEvents(locationName, name, err)
+			}
 		}
 		return
 	}
@@ -0,0 +1,184 @@
+// Copyright 2021 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hostspool
+
+import (
+	"fmt"
+	"path"
+	"time"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/pkg/errors"
+
+	"github.com/ystia/yorc/v4/helper/consulutil"
+	"github.com/ystia/yorc/v4/log"
+)
+
+const maintenanceEndKeyName = "maintenance_end"
+
+func (cm *consulManager) StartMaintenance(locationName, hostname, reason string, end time.Time) error {
+	return cm.startMaintenanceWait(locationName, hostname, reason, end, maxWaitTimeSeconds*time.Second)
+}
+
+func (cm *consulManager) startMaintenanceWait(locationName, hostname, reason string, end time.Time, maxWaitTime time.Duration) error {
+	if !end.IsZero() && end.Before(time.Now()) {
+		return errors.WithStack(badRequestError{fmt.Sprintf("maintenance end time %s is in the past", end.Format(time.RFC3339))})
+	}
+	_, cleanupFn, err := cm.lockKey(locationName, hostname, "maintenance", maxWaitTime)
+	if err != nil {
+		return err
+	}
+	defer cleanupFn()
+
+	status, err := cm.GetHostStatus(locationName, hostname)
+	if err != nil {
+		return err
+	}
+
+	hostKVPrefix := path.Join(consulutil.HostsPoolPrefix, locationName, hostname)
+	statusKey, messageKey := "status", "message"
+	if status == HostStatusError {
+		// The host is unreachable, it will be in maintenance once the connection is restored
+		statusKey, messageKey = ".statusBackup", ".messageBackup"
+	}
+	ops := api.KVTxnOps{
+		getKVTxnOp(api.KVSet, path.Join(hostKVPrefix, statusKey), []byte(HostStatusMaintenance.String())),
+		getKVTxnOp(api.KVSet, path.Join(hostKVPrefix, messageKey), []byte(reason)),
+	}
+	if end.IsZero() {
+		ops = append(ops, getKVTxnOp(api.KVDelete, path.Join(hostKVPrefix, maintenanceEndKeyName), nil))
+	} else {
+		ops = append(ops, getKVTxnOp(api.KVSet, path.Join(hostKVPrefix, maintenanceEndKeyName), []byte(end.UTC().Format(time.RFC3339))))
+	}
+	return cm.executeMaintenanceTxn(locationName, hostname, ops)
+}
+
+func (cm *consulManager) EndMaintenance(locationName, hostname string) error {
+	return cm.endMaintenanceWait(locationName, hostname, maxWaitTimeSeconds*time.Second)
+}
+
+func (cm *consulManager) endMaintenanceWait(locationName, hostname string, maxWaitTime time.Duration) error {
+	_, cleanupFn, err := cm.lockKey(locationName, hostname, "maintenance end", maxWaitTime)
+	if err != nil {
+		return err
+	}
+	defer cleanupFn()
+
+	status, err := cm.GetHostStatus(locationName, hostname)
+	if err != nil {
+		return err
+	}
+
+	backup := status == HostStatusError
+	if backup {
+		status, err = cm.getStatus(locationName, hostname, true)
+		if err != nil && !IsHostNotFoundError(err) {
+			return err
+		}
+	}
+	if status != HostStatusMaintenance {
+		return errors.WithStack(badRequestError{fmt.Sprintf("host %q of location %q is not in maintenance", hostname, locationName)})
+	}
+	ops, _, err := cm.getEndMaintenanceOperations(locationName, hostname, backup)
+	if err != nil {
+		return err
+	}
+	return cm.executeMaintenanceTxn(locationName, hostname, ops)
+}
+
+// getEndMaintenanceOperations returns operations allowing a host in maintenance to return in service.
+// If backup is true the backup status is updated instead of the current one.
+// The status operation is the first one of returned operations.
+func (cm *consulManager) getEndMaintenanceOperations(locationName, hostname string, backup bool) (api.KVTxnOps, HostStatus, error) {
+	allocations, err := cm.getAllocations(locationName, hostname)
+	if err != nil {
+		return nil, HostStatusMaintenance, err
+	}
+	// Allocations done before the maintenance are kept during the maintenance
+	newStatus := HostStatusFree
+	if len(allocations) > 0 {
+		newStatus = HostStatusAllocated
+	}
+	hostKVPrefix := path.Join(consulutil.HostsPoolPrefix, locationName, hostname)
+	statusKey, messageKey := "status", "message"
+	if backup {
+		statusKey, messageKey = ".statusBackup", ".messageBackup"
+	}
+	return api.KVTxnOps{
+		getKVTxnOp(api.KVSet, path.Join(hostKVPrefix, statusKey), []byte(newStatus.String())),
+		getKVTxnOp(api.KVSet, path.Join(hostKVPrefix, messageKey), nil),
+		getKVTxnOp(api.KVDelete, path.Join(hostKVPrefix, maintenanceEndKeyName), nil),
+	}, newStatus, nil
+}
+
+func (cm *consulManager) executeMaintenanceTxn(locationName, hostname string, ops api.KVTxnOps) error {
+	ok, response, _, err := cm.cc.KV().Txn(ops, nil)
+	if err != nil {
+		return errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+	}
+	if !ok {
+		errs := make([]string, 0)
+		for _, e := range response.Errors {
+			errs = append(errs, e.What)
+		}
+		return errors.Errorf("Failed to update maintenance of host %q for location:%q: %v", hostname, locationName, errs)
+	}
+	return nil
+}
+
+// getMaintenanceEnd returns the scheduled end of the maintenance of a host,
+// a nil value is returned if no end is scheduled
+func (cm *consulManager) getMaintenanceEnd(locationName, hostname string) (*time.Time, error) {
+	kvp, _, err := cm.cc.KV().Get(path.Join(consulutil.HostsPoolPrefix, locationName, hostname, maintenanceEndKeyName), nil)
+	if err != nil {
+		return nil, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+	}
+	if kvp == nil || len(kvp.Value) == 0 {
+		return nil, nil
+	}
+	end, err := time.Parse(time.RFC3339, string(kvp.Value))
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid maintenance end for host: %q and location: %q", hostname, locationName)
+	}
+	return &end, nil
+}
+
+// checkMaintenanceEnd makes a host in maintenance return in service if the scheduled end of
+// its maintenance is reached.
+// statusIndex is the modify index of the status key, the update is done only if
+// the status was not modified in the meantime. The returned status is the up to date host status.
+func (cm *consulManager) checkMaintenanceEnd(locationName, hostname string, statusIndex uint64) (HostStatus, error) {
+	end, err := cm.getMaintenanceEnd(locationName, hostname)
+	if err != nil || end == nil || time.Now().Before(*end) {
+		return HostStatusMaintenance, err
+	}
+	ops, newStatus, err := cm.getEndMaintenanceOperations(locationName, hostname, false)
+	if err != nil {
+		return HostStatusMaintenance, err
+	}
+	// Check and set the status key
+	ops[0].Verb = api.KVCAS
+	ops[0].Index = statusIndex
+	ok, _, _, err := cm.cc.KV().Txn(ops, nil)
+	if err != nil {
+		return HostStatusMaintenance, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+	}
+	if !ok {
+		// Status updated concurrently, read it again
+		return cm.GetHostStatus(locationName, hostname)
+	}
+	log.Printf("Scheduled end of maintenance reached for host %q on location %q, host is now %s", hostname, locationName, newStatus)
+	return newStatus, nil
+}
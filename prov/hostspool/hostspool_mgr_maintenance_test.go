@@ -0,0 +1,143 @@
+// Copyright 2021 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hostspool
+
+import (
+	"path"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ystia/yorc/v4/config"
+	"github.com/ystia/yorc/v4/helper/consulutil"
+)
+
+func testConsulManagerMaintenance(t *testing.T, cc *api.Client, cfg config.Configuration) {
+	location := "myLocation1"
+	cleanupHostsPool(t, cc)
+	cm := &consulManager{cc, cfg, mockSSHClientFactory}
+
+	var checkpoint uint64
+	hostpool := createHosts(2)
+	err := cm.Apply(location, hostpool, &checkpoint)
+	require.NoError(t, err, "Unexpected failure applying host pool configuration")
+
+	// Allocate the first host then put it in maintenance
+	alloc1 := &Allocation{NodeName: "node_test1", Instance: "0", DeploymentID: "test1", Shareable: true}
+	allocatedName, _, err := cm.Allocate(location, alloc1)
+	require.NoError(t, err)
+	err = cm.StartMaintenance(location, allocatedName, "kernel upgrade", time.Time{})
+	require.NoError(t, err)
+	host, err := cm.GetHost(location, allocatedName)
+	require.NoError(t, err)
+	assert.Equal(t, HostStatusMaintenance, host.Status)
+	assert.Equal(t, "kernel upgrade", host.Message)
+	assert.Nil(t, host.MaintenanceEnd)
+	assert.Len(t, host.Allocations, 1, "existing allocations should be kept during maintenance")
+
+	// Shareable allocations should not go on the host in maintenance
+	alloc2 := &Allocation{NodeName: "node_test2", Instance: "0", DeploymentID: "test2", Shareable: true}
+	otherHost, _, err := cm.Allocate(location, alloc2)
+	require.NoError(t, err)
+	assert.NotEqual(t, allocatedName, otherHost)
+
+	// Releasing an allocation keeps the host in maintenance
+	_, err = cm.Release(location, allocatedName, "test1", "node_test1", "0")
+	require.NoError(t, err)
+	status, err := cm.GetHostStatus(location, allocatedName)
+	require.NoError(t, err)
+	assert.Equal(t, HostStatusMaintenance, status)
+
+	// No more host available
+	alloc3 := &Allocation{NodeName: "node_test3", Instance: "0", DeploymentID: "test3"}
+	_, _, err = cm.Allocate(location, alloc3)
+	require.Error(t, err)
+	assert.True(t, IsNoMatchingHostFoundError(err), "unexpected error %v", err)
+
+	// End the maintenance
+	err = cm.EndMaintenance(location, allocatedName)
+	require.NoError(t, err)
+	host, err = cm.GetHost(location, allocatedName)
+	require.NoError(t, err)
+	assert.Equal(t, HostStatusFree, host.Status)
+	assert.Equal(t, "", host.Message)
+	err = cm.EndMaintenance(location, allocatedName)
+	require.Error(t, err)
+	assert.True(t, IsBadRequestError(err), "unexpected error %v", err)
+
+	// Scheduled end of maintenance
+	err = cm.StartMaintenance(location, otherHost, "disk replacement", time.Now().Add(-time.Minute))
+	require.Error(t, err, "expecting an error for an end of maintenance in the past")
+	assert.True(t, IsBadRequestError(err), "unexpected error %v", err)
+	end := time.Now().Add(time.Hour).Truncate(time.Second)
+	err = cm.StartMaintenance(location, otherHost, "disk replacement", end)
+	require.NoError(t, err)
+	host, err = cm.GetHost(location, otherHost)
+	require.NoError(t, err)
+	assert.Equal(t, HostStatusMaintenance, host.Status)
+	require.NotNil(t, host.MaintenanceEnd)
+	assert.True(t, end.Equal(*host.MaintenanceEnd))
+
+	// The scheduled end is kept when applying a new configuration
+	hostpool[0].Labels["newlabel"] = "value"
+	hostpool[1].Labels["newlabel"] = "value"
+	err = cm.Apply(location, hostpool, &checkpoint)
+	require.NoError(t, err)
+	host, err = cm.GetHost(location, otherHost)
+	require.NoError(t, err)
+	assert.Equal(t, HostStatusMaintenance, host.Status)
+	assert.Equal(t, "disk replacement", host.Message)
+	require.NotNil(t, host.MaintenanceEnd)
+
+	// Simulate the end of maintenance is reached, the host returns to service with its allocation
+	_, err = cc.KV().Put(&api.KVPair{
+		Key:   path.Join(consulutil.HostsPoolPrefix, location, otherHost, maintenanceEndKeyName),
+		Value: []byte(time.Now().Add(-time.Second).UTC().Format(time.RFC3339)),
+	}, nil)
+	require.NoError(t, err)
+	host, err = cm.GetHost(location, otherHost)
+	require.NoError(t, err)
+	assert.Equal(t, HostStatusAllocated, host.Status)
+	assert.Equal(t, "", host.Message)
+	assert.Nil(t, host.MaintenanceEnd)
+
+	// Maintenance of hosts in error is applied once the connection is restored
+	err = cm.setHostStatusWithMessage(location, allocatedName, HostStatusFree, "")
+	require.NoError(t, err)
+	err = cm.backupHostStatus(location, allocatedName)
+	require.NoError(t, err)
+	err = cm.setHostStatusWithMessage(location, allocatedName, HostStatusError, "connection failure")
+	require.NoError(t, err)
+	err = cm.StartMaintenance(location, allocatedName, "network issue", time.Time{})
+	require.NoError(t, err)
+	status, err = cm.GetHostStatus(location, allocatedName)
+	require.NoError(t, err)
+	assert.Equal(t, HostStatusError, status)
+	err = cm.restoreHostStatus(location, allocatedName)
+	require.NoError(t, err)
+	host, err = cm.GetHost(location, allocatedName)
+	require.NoError(t, err)
+	assert.Equal(t, HostStatusMaintenance, host.Status)
+	assert.Equal(t, "network issue", host.Message)
+
+	// Hosts in maintenance without allocations can be removed
+	err = cm.Remove(location, allocatedName)
+	require.NoError(t, err)
+	_, err = cm.GetHostStatus(location, allocatedName)
+	assert.True(t, IsHostNotFoundError(err), "unexpected error %v", err)
+}
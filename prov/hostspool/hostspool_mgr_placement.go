@@ -0,0 +1,262 @@
+// Copyright 2021 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hostspool
+
+import (
+	"strconv"
+
+	"github.com/dustin/go-humanize"
+	"github.com/pkg/errors"
+
+	"github.com/ystia/yorc/v4/log"
+)
+
+const (
+	capacityPlacement       = "yorc.policies.hostspool.CapacityPlacement"
+	affinityPolicy          = "yorc.policies.hostspool.Affinity"
+	affinityPlacement       = "yorc.policies.hostspool.AffinityPlacement"
+	antiAffinityPlacement   = "yorc.policies.hostspool.AntiAffinityPlacement"
+	packCapacityStrategy    = "pack"
+	spreadCapacityStrategy  = "spread"
+	nodeAffinityScope       = "node"
+	deploymentAffinityScope = "deployment"
+)
+
+// consumableResources are the resources labels decremented on hosts when allocated
+var consumableResources = []string{"host.num_cpus", "host.mem_size", "host.disk_size"}
+
+// CheckCapacityPlacement checks that the capacity-aware placement options are supported
+func CheckCapacityPlacement(capacity *CapacityPlacement) error {
+	if capacity == nil {
+		return nil
+	}
+	switch capacity.Strategy {
+	case packCapacityStrategy, spreadCapacityStrategy:
+	default:
+		return errors.Errorf("capacity placement strategy %q is not supported, expecting one of %q or %q", capacity.Strategy, packCapacityStrategy, spreadCapacityStrategy)
+	}
+	for _, r := range capacity.Resources {
+		if !isConsumableResource(r) {
+			return errors.Errorf("capacity placement resource %q is not supported, expecting one of %q", r, consumableResources)
+		}
+	}
+	return nil
+}
+
+// CheckAffinityRule checks that an affinity rule is supported
+func CheckAffinityRule(rule AffinityRule) error {
+	switch rule.Scope {
+	case nodeAffinityScope, deploymentAffinityScope:
+		return nil
+	default:
+		return errors.Errorf("affinity scope %q is not supported, expecting one of %q or %q", rule.Scope, nodeAffinityScope, deploymentAffinityScope)
+	}
+}
+
+func isConsumableResource(name string) bool {
+	for _, r := range consumableResources {
+		if r == name {
+			return true
+		}
+	}
+	return false
+}
+
+// needsCandidatesDetails returns true if host labels and allocations are required to elect a host
+func (alloc *Allocation) needsCandidatesDetails() bool {
+	return len(alloc.Affinities) > 0 || alloc.PlacementPolicy == capacityPlacement
+}
+
+// loadCandidatesDetails retrieves labels and allocations of candidates
+func (cm *consulManager) loadCandidatesDetails(locationName string, candidates []hostCandidate) error {
+	for i := range candidates {
+		labels, err := cm.GetHostLabels(locationName, candidates[i].name)
+		if err != nil {
+			return err
+		}
+		candidates[i].labels = labels
+		if candidates[i].allocations > 0 && candidates[i].allocs == nil {
+			candidates[i].allocs, err = cm.getAllocations(locationName, candidates[i].name)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// applyAffinities filters candidates according to the affinity rules of the allocation
+func (cm *consulManager) applyAffinities(locationName string, allocation *Allocation, candidates []hostCandidate) ([]hostCandidate, error) {
+	if len(allocation.Affinities) == 0 {
+		return candidates, nil
+	}
+	// Peers allocations may be on hosts that are not candidates so we need to look at the whole location
+	hostnames, _, _, err := cm.List(locationName)
+	if err != nil {
+		return nil, err
+	}
+	hosts := make([]hostCandidate, 0, len(hostnames))
+	for _, h := range hostnames {
+		labels, err := cm.GetHostLabels(locationName, h)
+		if err != nil {
+			return nil, err
+		}
+		allocs, err := cm.getAllocations(locationName, h)
+		if err != nil {
+			return nil, err
+		}
+		hosts = append(hosts, hostCandidate{name: h, allocations: len(allocs), labels: labels, allocs: allocs})
+	}
+
+	for _, rule := range allocation.Affinities {
+		filtered := filterCandidatesByAffinity(allocation, rule, hosts, candidates)
+		if len(filtered) == 0 {
+			if rule.Required {
+				return nil, errors.WithStack(noMatchingHostFoundError{})
+			}
+			log.Debugf("Affinity rule %+v can't be satisfied for deployment:%s, node name:%s, instance:%s, ignoring it as it is not required", rule, allocation.DeploymentID, allocation.NodeName, allocation.Instance)
+			continue
+		}
+		candidates = filtered
+	}
+	return candidates, nil
+}
+
+// filterCandidatesByAffinity returns candidates satisfying the given affinity rule regarding allocations of peers on hosts
+func filterCandidatesByAffinity(allocation *Allocation, rule AffinityRule, hosts, candidates []hostCandidate) []hostCandidate {
+	occupied := make(map[string]bool)
+	for _, h := range hosts {
+		domain, ok := topologyDomain(rule, h)
+		if !ok {
+			continue
+		}
+		for _, alloc := range h.allocs {
+			if isAffinityPeer(allocation, rule, alloc) {
+				occupied[domain] = true
+				break
+			}
+		}
+	}
+
+	filtered := make([]hostCandidate, 0, len(candidates))
+	for _, c := range candidates {
+		domain, ok := topologyDomain(rule, c)
+		if !ok {
+			// Hosts outside of any topology domain can't satisfy the rule
+			continue
+		}
+		if rule.AntiAffinity {
+			if !occupied[domain] {
+				filtered = append(filtered, c)
+			}
+		} else if len(occupied) == 0 || occupied[domain] {
+			// The first instance could be placed anywhere
+			filtered = append(filtered, c)
+		}
+	}
+	return filtered
+}
+
+func topologyDomain(rule AffinityRule, h hostCandidate) (string, bool) {
+	if rule.TopologyLabel == "" {
+		return h.name, true
+	}
+	domain, ok := h.labels[rule.TopologyLabel]
+	return domain, ok && domain != ""
+}
+
+func isAffinityPeer(allocation *Allocation, rule AffinityRule, other Allocation) bool {
+	if other.ID == allocation.ID || other.DeploymentID != allocation.DeploymentID {
+		return false
+	}
+	return rule.Scope == deploymentAffinityScope || other.NodeName == allocation.NodeName
+}
+
+// capacityBased elects the candidate according to the remaining capacity it would have after the allocation
+// It returns an empty string if no candidate fits
+func capacityBased(allocation *Allocation, candidates []hostCandidate) string {
+	capacity := allocation.CapacityPlacement
+	if capacity == nil {
+		capacity = &CapacityPlacement{Strategy: packCapacityStrategy}
+	}
+	resources := capacity.Resources
+	if len(resources) == 0 {
+		resources = consumableResources
+	}
+
+	var hostname string
+	var bestScore float64
+	for _, c := range candidates {
+		score, ok := capacityScore(allocation, resources, c)
+		if !ok {
+			continue
+		}
+		if hostname == "" ||
+			(capacity.Strategy == spreadCapacityStrategy && score > bestScore) ||
+			(capacity.Strategy != spreadCapacityStrategy && score < bestScore) {
+			hostname = c.name
+			bestScore = score
+		}
+	}
+	return hostname
+}
+
+// capacityScore returns the mean ratio of capacity left on the candidate after the allocation
+// It returns false if the candidate doesn't have enough remaining capacity
+func capacityScore(allocation *Allocation, resources []string, c hostCandidate) (float64, bool) {
+	var sum float64
+	var count int
+	for _, r := range resources {
+		remaining, err := parseCapacity(r, c.labels[r])
+		if err != nil {
+			// No capacity information on this host for this resource
+			continue
+		}
+		requested, err := parseCapacity(r, allocation.Resources[r])
+		if err != nil {
+			requested = 0
+		}
+		left := remaining - requested
+		if left < 0 {
+			return 0, false
+		}
+		total := remaining
+		for _, alloc := range c.allocs {
+			if allocated, err := parseCapacity(r, alloc.Resources[r]); err == nil {
+				total += allocated
+			}
+		}
+		if total > 0 {
+			sum += left / total
+			count++
+		}
+	}
+	if count == 0 {
+		// Hosts without any capacity information are considered as fully available
+		return 1, true
+	}
+	return sum / float64(count), true
+}
+
+func parseCapacity(resource, value string) (float64, error) {
+	if value == "" {
+		return 0, errors.Errorf("no value for resource %q", resource)
+	}
+	if resource == "host.num_cpus" {
+		return strconv.ParseFloat(value, 64)
+	}
+	b, err := humanize.ParseBytes(value)
+	return float64(b), err
+}
@@ -0,0 +1,99 @@
+// Copyright 2021 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hostspool
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCapacityBased(t *testing.T) {
+	candidates := []hostCandidate{
+		{name: "small", labels: map[string]string{"host.num_cpus": "2", "host.mem_size": "4 GB"}},
+		{name: "big", labels: map[string]string{"host.num_cpus": "16", "host.mem_size": "64 GB"}},
+		{name: "busy", allocations: 1, labels: map[string]string{"host.num_cpus": "4", "host.mem_size": "8 GB"},
+			allocs: []Allocation{{ID: "a1", Resources: map[string]string{"host.num_cpus": "12", "host.mem_size": "56 GB"}}}},
+	}
+	tests := []struct {
+		name      string
+		capacity  *CapacityPlacement
+		resources map[string]string
+		want      string
+	}{
+		{"PackElectsFullest", &CapacityPlacement{Strategy: packCapacityStrategy}, map[string]string{"host.num_cpus": "2"}, "busy"},
+		{"PackCpusOnly", &CapacityPlacement{Strategy: packCapacityStrategy, Resources: []string{"host.num_cpus"}}, map[string]string{"host.num_cpus": "2"}, "small"},
+		{"PackSkipsTooSmall", &CapacityPlacement{Strategy: packCapacityStrategy}, map[string]string{"host.num_cpus": "3"}, "busy"},
+		{"SpreadElectsEmptiest", &CapacityPlacement{Strategy: spreadCapacityStrategy}, map[string]string{"host.num_cpus": "1"}, "big"},
+		{"MemoryOnly", &CapacityPlacement{Strategy: packCapacityStrategy, Resources: []string{"host.mem_size"}}, map[string]string{"host.mem_size": "6 GB"}, "busy"},
+		{"DefaultIsPack", nil, map[string]string{"host.num_cpus": "1"}, "busy"},
+		{"NoHostFits", &CapacityPlacement{Strategy: spreadCapacityStrategy}, map[string]string{"host.num_cpus": "32"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			allocation := &Allocation{PlacementPolicy: capacityPlacement, CapacityPlacement: tt.capacity, Resources: tt.resources}
+			assert.Equal(t, tt.want, capacityBased(allocation, candidates))
+		})
+	}
+}
+
+func TestFilterCandidatesByAffinity(t *testing.T) {
+	hosts := []hostCandidate{
+		{name: "host1", labels: map[string]string{"rack": "r1"}, allocs: []Allocation{{ID: "dep1-node1-0", DeploymentID: "dep1", NodeName: "node1"}}},
+		{name: "host2", labels: map[string]string{"rack": "r1"}},
+		{name: "host3", labels: map[string]string{"rack": "r2"}, allocs: []Allocation{{ID: "dep1-node2-0", DeploymentID: "dep1", NodeName: "node2"}}},
+		{name: "host4", labels: map[string]string{"rack": "r3"}},
+		{name: "host5"},
+	}
+	allocation := &Allocation{ID: "dep1-node1-1", DeploymentID: "dep1", NodeName: "node1"}
+	names := func(candidates []hostCandidate) []string {
+		res := make([]string, 0)
+		for _, c := range candidates {
+			res = append(res, c.name)
+		}
+		return res
+	}
+	tests := []struct {
+		name string
+		rule AffinityRule
+		want []string
+	}{
+		{"AntiAffinityNodeRack", AffinityRule{AntiAffinity: true, Scope: nodeAffinityScope, TopologyLabel: "rack"}, []string{"host3", "host4"}},
+		{"AntiAffinityDeploymentRack", AffinityRule{AntiAffinity: true, Scope: deploymentAffinityScope, TopologyLabel: "rack"}, []string{"host4"}},
+		{"AntiAffinityNodeHost", AffinityRule{AntiAffinity: true, Scope: nodeAffinityScope}, []string{"host2", "host3", "host4", "host5"}},
+		{"AffinityNodeRack", AffinityRule{Scope: nodeAffinityScope, TopologyLabel: "rack"}, []string{"host1", "host2"}},
+		{"AffinityDeploymentRack", AffinityRule{Scope: deploymentAffinityScope, TopologyLabel: "rack"}, []string{"host1", "host2", "host3"}},
+		{"AffinityWithoutPeers", AffinityRule{Scope: nodeAffinityScope, TopologyLabel: "zone"}, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, names(filterCandidatesByAffinity(allocation, tt.rule, hosts, hosts)))
+		})
+	}
+
+	// Without any peer allocation, affinity allows any host of a topology domain
+	first := &Allocation{ID: "dep2-node1-0", DeploymentID: "dep2", NodeName: "node1"}
+	got := filterCandidatesByAffinity(first, AffinityRule{Scope: nodeAffinityScope, TopologyLabel: "rack"}, hosts, hosts)
+	assert.Equal(t, []string{"host1", "host2", "host3", "host4"}, names(got))
+}
+
+func TestCheckPlacementOptions(t *testing.T) {
+	assert.NoError(t, CheckCapacityPlacement(nil))
+	assert.NoError(t, CheckCapacityPlacement(&CapacityPlacement{Strategy: spreadCapacityStrategy, Resources: []string{"host.num_cpus"}}))
+	assert.Error(t, CheckCapacityPlacement(&CapacityPlacement{Strategy: "random"}))
+	assert.Error(t, CheckCapacityPlacement(&CapacityPlacement{Strategy: packCapacityStrategy, Resources: []string{"host.resource.gpu"}}))
+	assert.NoError(t, CheckAffinityRule(AffinityRule{Scope: deploymentAffinityScope}))
+	assert.Error(t, CheckAffinityRule(AffinityRule{Scope: "location"}))
+}
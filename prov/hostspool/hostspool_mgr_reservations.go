@@ -0,0 +1,260 @@
+// Copyright 2021 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hostspool
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/ystia/yorc/v4/helper/consulutil"
+	"github.com/ystia/yorc/v4/helper/labelsutil"
+)
+
+const (
+	// ReservationsHostname is a name that can't be used for hosts as it is used to manage reservations in the REST API
+	ReservationsHostname = "reservations"
+	leaseExpiryKeyName   = "lease_expiry"
+	// Reservations are not stored under HostsPoolPrefix as they would be considered as hosts
+	reservationsKVPrefix = consulutil.YorcManagementPrefix + "/hosts_pool_reservations"
+)
+
+func (cm *consulManager) Reserve(locationName string, reservation *Reservation, count int, filters ...labelsutil.Filter) ([]labelsutil.Warning, error) {
+	return cm.reserveWait(locationName, reservation, count, maxWaitTimeSeconds*time.Second, filters...)
+}
+
+func (cm *consulManager) reserveWait(locationName string, reservation *Reservation, count int, maxWaitTime time.Duration, filters ...labelsutil.Filter) ([]labelsutil.Warning, error) {
+	if locationName == "" {
+		return nil, errors.WithStack(badRequestError{`"locationName" missing`})
+	}
+	if reservation.Name == "" {
+		return nil, errors.WithStack(badRequestError{`"name" missing`})
+	}
+	if reservation.Owner == "" {
+		return nil, errors.WithStack(badRequestError{`"owner" missing`})
+	}
+	if reservation.IsExpired() {
+		return nil, errors.WithStack(badRequestError{fmt.Sprintf("reservation expiry %s is in the past", reservation.Expiry.Format(time.RFC3339))})
+	}
+	if len(reservation.Hosts) == 0 && count <= 0 {
+		return nil, errors.WithStack(badRequestError{"either hosts or a number of hosts to reserve should be provided"})
+	}
+	if len(reservation.Hosts) > 0 && count > 0 {
+		return nil, errors.WithStack(badRequestError{"hosts and a number of hosts to reserve can't be provided together"})
+	}
+
+	// Use the same lock than allocations to ensure reserved hosts are not allocated meanwhile
+	_, cleanupFn, err := cm.lockKey(locationName, "", "reservation", maxWaitTime)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanupFn()
+
+	reservations, err := cm.ListReservations(locationName)
+	if err != nil {
+		return nil, err
+	}
+	reservedHosts := make(map[string]string)
+	for _, r := range reservations {
+		if r.IsExpired() {
+			continue
+		}
+		if r.Name == reservation.Name {
+			return nil, errors.WithStack(badRequestError{fmt.Sprintf("reservation %q already exists", reservation.Name)})
+		}
+		for _, h := range r.Hosts {
+			reservedHosts[h] = r.Name
+		}
+	}
+
+	var warnings []labelsutil.Warning
+	if len(reservation.Hosts) > 0 {
+		for _, h := range reservation.Hosts {
+			if _, err = cm.GetHostStatus(locationName, h); err != nil {
+				return nil, err
+			}
+			if owner, ok := reservedHosts[h]; ok {
+				return nil, errors.WithStack(badRequestError{fmt.Sprintf("host %q is already held by reservation %q", h, owner)})
+			}
+		}
+	} else {
+		var hosts []string
+		hosts, warnings, _, err = cm.List(locationName, filters...)
+		if err != nil {
+			return warnings, err
+		}
+		for _, h := range hosts {
+			if len(reservation.Hosts) == count {
+				break
+			}
+			if _, ok := reservedHosts[h]; ok {
+				continue
+			}
+			status, err := cm.GetHostStatus(locationName, h)
+			if err != nil {
+				return warnings, err
+			}
+			if status == HostStatusFree {
+				reservation.Hosts = append(reservation.Hosts, h)
+			}
+		}
+		if nbHosts := len(reservation.Hosts); nbHosts < count {
+			reservation.Hosts = nil
+			return warnings, errors.Wrapf(noMatchingHostFoundError{}, "only %d free hosts could be reserved out of %d requested", nbHosts, count)
+		}
+	}
+
+	data, err := json.Marshal(reservation)
+	if err != nil {
+		return warnings, errors.Wrapf(err, "failed to marshal reservation %q", reservation.Name)
+	}
+	return warnings, consulutil.StoreConsulKey(getReservationKey(locationName, reservation.Name), data)
+}
+
+// ListReservations returns reservations of a location sorted by name, including expired ones not yet released
+func (cm *consulManager) ListReservations(locationName string) ([]Reservation, error) {
+	kvps, _, err := cm.cc.KV().List(path.Join(reservationsKVPrefix, locationName)+"/", nil)
+	if err != nil {
+		return nil, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+	}
+	reservations := make([]Reservation, 0, len(kvps))
+	for _, kvp := range kvps {
+		var r Reservation
+		if err = json.Unmarshal(kvp.Value, &r); err != nil {
+			return nil, errors.Wrapf(err, "failed to unmarshal reservation from key %q", kvp.Key)
+		}
+		reservations = append(reservations, r)
+	}
+	sort.Slice(reservations, func(i, j int) bool {
+		return reservations[i].Name < reservations[j].Name
+	})
+	return reservations, nil
+}
+
+func (cm *consulManager) GetReservation(locationName, name string) (*Reservation, error) {
+	kvp, _, err := cm.cc.KV().Get(getReservationKey(locationName, name), nil)
+	if err != nil {
+		return nil, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+	}
+	if kvp == nil {
+		return nil, errors.WithStack(reservationNotFoundError{})
+	}
+	r := new(Reservation)
+	err = json.Unmarshal(kvp.Value, r)
+	return r, errors.Wrapf(err, "failed to unmarshal reservation %q", name)
+}
+
+func (cm *consulManager) CancelReservation(locationName, name string) error {
+	_, cleanupFn, err := cm.lockKey(locationName, "", "reservation cancellation", maxWaitTimeSeconds*time.Second)
+	if err != nil {
+		return err
+	}
+	defer cleanupFn()
+
+	if _, err = cm.GetReservation(locationName, name); err != nil {
+		return err
+	}
+	_, err = cm.cc.KV().Delete(getReservationKey(locationName, name), nil)
+	return errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+}
+
+func getReservationKey(locationName, name string) string {
+	return path.Join(reservationsKVPrefix, locationName, url.PathEscape(name))
+}
+
+// filterReservedHosts keeps the hosts held by the reservation of the allocation if any,
+// otherwise it removes the hosts held by any active reservation
+func filterReservedHosts(allocation *Allocation, hosts []string, reservations []Reservation) ([]string, error) {
+	reserved := make(map[string]bool)
+	found := false
+	for _, r := range reservations {
+		if r.IsExpired() {
+			continue
+		}
+		if allocation.Reservation != "" && r.Name != allocation.Reservation {
+			continue
+		}
+		found = found || r.Name == allocation.Reservation
+		for _, h := range r.Hosts {
+			reserved[h] = true
+		}
+	}
+	if allocation.Reservation != "" && !found {
+		return nil, errors.Wrapf(reservationNotFoundError{}, "no active reservation %q", allocation.Reservation)
+	}
+
+	results := make([]string, 0, len(hosts))
+	for _, h := range hosts {
+		// Allocations with a reservation are restricted to its hosts, other ones can't use reserved hosts
+		if reserved[h] == (allocation.Reservation != "") {
+			results = append(results, h)
+		}
+	}
+	return results, nil
+}
+
+func (cm *consulManager) RenewLease(locationName, hostname, allocationID string, duration time.Duration) (time.Time, error) {
+	return cm.renewLeaseWait(locationName, hostname, allocationID, duration, maxWaitTimeSeconds*time.Second)
+}
+
+func (cm *consulManager) renewLeaseWait(locationName, hostname, allocationID string, duration time.Duration, maxWaitTime time.Duration) (time.Time, error) {
+	var expiry time.Time
+	if duration <= 0 {
+		return expiry, errors.WithStack(badRequestError{"lease duration should be positive"})
+	}
+	_, cleanupFn, err := cm.lockKey(locationName, hostname, "lease renewal", maxWaitTime)
+	if err != nil {
+		return expiry, err
+	}
+	defer cleanupFn()
+
+	if _, err = cm.GetHostStatus(locationName, hostname); err != nil {
+		return expiry, err
+	}
+	if err = cm.checkAllocationExists(locationName, hostname, allocationID); err != nil {
+		return expiry, err
+	}
+	// Renewing an allocation without lease would make it expire
+	currentExpiry, err := cm.getLeaseExpiry(locationName, hostname, allocationID)
+	if err != nil {
+		return expiry, err
+	}
+	if currentExpiry == nil {
+		return expiry, errors.WithStack(badRequestError{fmt.Sprintf("allocation %q of host %q has no lease to renew", allocationID, hostname)})
+	}
+	expiry = time.Now().Add(duration).UTC()
+	key := path.Join(consulutil.HostsPoolPrefix, locationName, hostname, "allocations", allocationID, leaseExpiryKeyName)
+	return expiry, consulutil.StoreConsulKeyAsString(key, expiry.Format(time.RFC3339))
+}
+
+func (cm *consulManager) getLeaseExpiry(locationName, hostname, allocationID string) (*time.Time, error) {
+	kvp, _, err := cm.cc.KV().Get(path.Join(consulutil.HostsPoolPrefix, locationName, hostname, "allocations", allocationID, leaseExpiryKeyName), nil)
+	if err != nil {
+		return nil, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+	}
+	if kvp == nil || len(kvp.Value) == 0 {
+		return nil, nil
+	}
+	expiry, err := time.Parse(time.RFC3339, string(kvp.Value))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse lease expiry of allocation %q on host %q", allocationID, hostname)
+	}
+	return &expiry, nil
+}
@@ -0,0 +1,183 @@
+// Copyright 2021 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hostspool
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ystia/yorc/v4/config"
+	"github.com/ystia/yorc/v4/helper/labelsutil"
+)
+
+func cleanupReservations(t *testing.T, cc *api.Client) {
+	t.Helper()
+	_, err := cc.KV().DeleteTree(reservationsKVPrefix, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func testConsulManagerReservations(t *testing.T, cc *api.Client, cfg config.Configuration) {
+	location := "myLocation1"
+	cleanupHostsPool(t, cc)
+	cleanupReservations(t, cc)
+	cm := &consulManager{cc, cfg, mockSSHClientFactory}
+
+	var checkpoint uint64
+	hostpool := createHosts(3)
+	err := cm.Apply(location, hostpool, &checkpoint)
+	require.NoError(t, err, "Unexpected failure applying host pool configuration")
+
+	// Reserve a given number of hosts matching filters
+	filter, err := labelsutil.CreateFilter("label1 != value10")
+	require.NoError(t, err)
+	training := &Reservation{Name: "training", Owner: "team1", Expiry: time.Now().Add(time.Hour)}
+	_, err = cm.Reserve(location, training, 2, filter)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"host1", "host2"}, training.Hosts)
+
+	// Hosts can't be reserved twice
+	_, err = cm.Reserve(location, &Reservation{Name: "other", Owner: "team2", Hosts: []string{"host1"}, Expiry: time.Now().Add(time.Hour)}, 0)
+	require.Error(t, err)
+	assert.True(t, IsBadRequestError(err), "unexpected error %v", err)
+	_, err = cm.Reserve(location, &Reservation{Name: "other", Owner: "team2", Expiry: time.Now().Add(time.Hour)}, 2)
+	require.Error(t, err)
+	assert.True(t, IsNoMatchingHostFoundError(err), "unexpected error %v", err)
+
+	reservations, err := cm.ListReservations(location)
+	require.NoError(t, err)
+	require.Len(t, reservations, 1)
+	assert.Equal(t, "team1", reservations[0].Owner)
+
+	// Allocations without reservation can't use reserved hosts
+	alloc1 := &Allocation{NodeName: "node_test1", Instance: "0", DeploymentID: "test1"}
+	hostname, _, err := cm.Allocate(location, alloc1)
+	require.NoError(t, err)
+	assert.Equal(t, "host0", hostname)
+	alloc2 := &Allocation{NodeName: "node_test1", Instance: "1", DeploymentID: "test1"}
+	_, _, err = cm.Allocate(location, alloc2)
+	require.Error(t, err)
+	assert.True(t, IsNoMatchingHostFoundError(err), "unexpected error %v", err)
+
+	// Allocations with a reservation use its hosts
+	alloc3 := &Allocation{NodeName: "node_test2", Instance: "0", DeploymentID: "test2", Reservation: "training"}
+	hostname, _, err = cm.Allocate(location, alloc3)
+	require.NoError(t, err)
+	assert.Contains(t, training.Hosts, hostname)
+	host, err := cm.GetHost(location, hostname)
+	require.NoError(t, err)
+	require.Len(t, host.Allocations, 1)
+	assert.Equal(t, "training", host.Allocations[0].Reservation)
+
+	alloc4 := &Allocation{NodeName: "node_test2", Instance: "1", DeploymentID: "test2", Reservation: "unknown"}
+	_, _, err = cm.Allocate(location, alloc4)
+	require.Error(t, err)
+	assert.True(t, IsReservationNotFoundError(err), "unexpected error %v", err)
+
+	// Expired reservations are released by the reaper
+	err = cm.reapLocations(time.Now().Add(2 * time.Hour))
+	require.NoError(t, err)
+	_, err = cm.GetReservation(location, "training")
+	require.Error(t, err)
+	assert.True(t, IsReservationNotFoundError(err), "unexpected error %v", err)
+
+	err = cm.CancelReservation(location, "training")
+	assert.True(t, IsReservationNotFoundError(err), "unexpected error %v", err)
+}
+
+func testConsulManagerLeases(t *testing.T, cc *api.Client, cfg config.Configuration) {
+	location := "myLocation1"
+	cleanupHostsPool(t, cc)
+	cleanupReservations(t, cc)
+	cm := &consulManager{cc, cfg, mockSSHClientFactory}
+
+	var checkpoint uint64
+	hostpool := createHostsWithLabels(1, map[string]string{"host.num_cpus": "8"})
+	err := cm.Apply(location, hostpool, &checkpoint)
+	require.NoError(t, err, "Unexpected failure applying host pool configuration")
+
+	alloc := &Allocation{NodeName: "node_test1", Instance: "0", DeploymentID: "test1", Shareable: true,
+		Resources: map[string]string{"host.num_cpus": "2"}, LeaseDuration: time.Hour}
+	hostname, _, err := cm.Allocate(location, alloc)
+	require.NoError(t, err)
+	err = cm.UpdateResourcesLabels(location, hostname, alloc.Resources, subtract, updateResourcesLabels, nil, removeElements, updateGenericResourcesLabels)
+	require.NoError(t, err)
+	host, err := cm.GetHost(location, hostname)
+	require.NoError(t, err)
+	require.Len(t, host.Allocations, 1)
+	require.NotNil(t, host.Allocations[0].LeaseExpiry)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), *host.Allocations[0].LeaseExpiry, time.Minute)
+
+	// Renew the lease
+	expiry, err := cm.RenewLease(location, hostname, alloc.ID, 3*time.Hour)
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(3*time.Hour), expiry, time.Minute)
+	_, err = cm.RenewLease(location, hostname, "unknown", time.Hour)
+	assert.True(t, IsAllocationNotFoundError(err), "unexpected error %v", err)
+
+	// Allocations without lease can't be renewed
+	noLeaseAlloc := &Allocation{NodeName: "node_test2", Instance: "0", DeploymentID: "test1", Shareable: true,
+		Resources: map[string]string{"host.num_cpus": "2"}}
+	noLeaseHostname, _, err := cm.Allocate(location, noLeaseAlloc)
+	require.NoError(t, err)
+	require.Equal(t, hostname, noLeaseHostname)
+	_, err = cm.RenewLease(location, hostname, noLeaseAlloc.ID, time.Hour)
+	assert.True(t, IsBadRequestError(err), "unexpected error %v", err)
+	_, err = cm.Release(location, hostname, noLeaseAlloc.DeploymentID, noLeaseAlloc.NodeName, noLeaseAlloc.Instance)
+	require.NoError(t, err)
+
+	// Not yet expired
+	err = cm.reapLocations(time.Now().Add(2 * time.Hour))
+	require.NoError(t, err)
+	host, err = cm.GetHost(location, hostname)
+	require.NoError(t, err)
+	require.Len(t, host.Allocations, 1)
+
+	// Expired allocations are released and their resources restored
+	err = cm.reapLocations(time.Now().Add(4 * time.Hour))
+	require.NoError(t, err)
+	host, err = cm.GetHost(location, hostname)
+	require.NoError(t, err)
+	assert.Len(t, host.Allocations, 0)
+	assert.Equal(t, HostStatusFree, host.Status)
+	assert.Equal(t, "8", host.Labels["host.num_cpus"])
+
+	_, err = cm.Release(location, hostname, "test1", "node_test1", "0")
+	assert.True(t, IsAllocationNotFoundError(err), "unexpected error %v", err)
+}
+
+func TestFilterReservedHosts(t *testing.T) {
+	reservations := []Reservation{
+		{Name: "active", Owner: "team1", Hosts: []string{"host1", "host2"}, Expiry: time.Now().Add(time.Hour)},
+		{Name: "expired", Owner: "team2", Hosts: []string{"host3"}, Expiry: time.Now().Add(-time.Hour)},
+	}
+	hosts := []string{"host0", "host1", "host2", "host3"}
+
+	got, err := filterReservedHosts(&Allocation{}, hosts, reservations)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"host0", "host3"}, got)
+
+	got, err = filterReservedHosts(&Allocation{Reservation: "active"}, hosts, reservations)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"host1", "host2"}, got)
+
+	_, err = filterReservedHosts(&Allocation{Reservation: "expired"}, hosts, reservations)
+	assert.True(t, IsReservationNotFoundError(err), "unexpected error %v", err)
+}
@@ -0,0 +1,130 @@
+// Copyright 2021 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hostspool
+
+import (
+	"context"
+	"time"
+
+	"github.com/armon/go-metrics"
+	"github.com/hashicorp/consul/api"
+
+	"github.com/ystia/yorc/v4/config"
+	"github.com/ystia/yorc/v4/events"
+	"github.com/ystia/yorc/v4/helper/metricsutil"
+	"github.com/ystia/yorc/v4/log"
+)
+
+var defaultReaper *leaderTask
+
+// StartReaper starts the periodic release of expired hosts pools allocations and reservations if enabled in the configuration.
+// Only the leader of the reaper service releases them.
+func StartReaper(cfg config.Configuration, cc *api.Client) {
+	if cfg.HostsPool.ReaperInterval <= 0 {
+		log.Debugf("Hosts pools expired allocations and reservations release is disabled")
+		return
+	}
+	cm := NewManager(cc, cfg).(*consulManager)
+	defaultReaper = newLeaderTask("reaper", "hosts_pool_reaper", cfg.HostsPool.ReaperInterval, func(ctx context.Context) error {
+		return cm.reapLocations(time.Now())
+	})
+	defaultReaper.watchLeadership(cc)
+}
+
+// StopReaper stops the periodic release of expired hosts pools allocations and reservations
+func StopReaper() {
+	if defaultReaper == nil {
+		return
+	}
+	defaultReaper.shutdown()
+}
+
+// reapLocations releases allocations whose lease expired before the given time and removes expired reservations
+// of all hosts pools locations
+func (cm *consulManager) reapLocations(now time.Time) error {
+	locations, err := cm.ListLocations()
+	if err != nil {
+		return err
+	}
+	for _, location := range locations {
+		if err = cm.reapExpiredReservations(location, now); err != nil {
+			return err
+		}
+		hostnames, _, _, err := cm.List(location)
+		if err != nil {
+			return err
+		}
+		for _, hostname := range hostnames {
+			if err = cm.reapExpiredAllocations(location, hostname, now); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (cm *consulManager) reapExpiredReservations(locationName string, now time.Time) error {
+	reservations, err := cm.ListReservations(locationName)
+	if err != nil {
+		return err
+	}
+	for _, r := range reservations {
+		if r.Expiry.After(now) {
+			continue
+		}
+		err = cm.CancelReservation(locationName, r.Name)
+		if err != nil && !IsReservationNotFoundError(err) {
+			return err
+		}
+		log.Printf("Reservation %q of %q on hosts pool location %q expired on %s and has been released", r.Name, r.Owner, locationName, r.Expiry.Format(time.RFC3339))
+		metrics.IncrCounterWithLabels(metricsutil.CleanupMetricKey([]string{"hostspool", "reservations", "expired"}), 1, []metrics.Label{
+			metrics.Label{Name: "Location", Value: locationName},
+		})
+	}
+	return nil
+}
+
+func (cm *consulManager) reapExpiredAllocations(locationName, hostname string, now time.Time) error {
+	allocations, err := cm.getAllocations(locationName, hostname)
+	if err != nil {
+		return err
+	}
+	for _, alloc := range allocations {
+		if alloc.LeaseExpiry == nil || alloc.LeaseExpiry.After(now) {
+			continue
+		}
+		released, err := cm.Release(locationName, hostname, alloc.DeploymentID, alloc.NodeName, alloc.Instance)
+		if err != nil {
+			if IsAllocationNotFoundError(err) {
+				// Already released meanwhile
+				continue
+			}
+			return err
+		}
+		err = cm.UpdateResourcesLabels(locationName, hostname, released.Resources, add, updateResourcesLabels, released.GenericResources, addElements, updateGenericResourcesLabels)
+		if err != nil {
+			return err
+		}
+
+		log.Printf("Allocation %q of host %q on hosts pool location %q expired on %s and has been released", alloc.ID, hostname, locationName, alloc.LeaseExpiry.Format(time.RFC3339))
+		ctx := events.AddLogOptionalFields(context.Background(), events.LogOptionalFields{events.NodeID: alloc.NodeName, events.InstanceID: alloc.Instance})
+		events.WithContextOptionalFields(ctx).NewLogEntry(events.LogLevelWARN, alloc.DeploymentID).Registerf(
+			"Lease of host %q of hosts pool location %q expired on %s, the host has been released", hostname, locationName, alloc.LeaseExpiry.Format(time.RFC3339))
+		metrics.IncrCounterWithLabels(metricsutil.CleanupMetricKey([]string{"hostspool", "leases", "expired"}), 1, []metrics.Label{
+			metrics.Label{Name: "Location", Value: locationName},
+		})
+	}
+	return nil
+}
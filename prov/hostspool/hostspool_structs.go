@@ -23,6 +23,7 @@ import (
 	"net/url"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 
@@ -42,6 +43,7 @@ const (
 free
 allocated
 error
+maintenance
 )
 */
 type HostStatus int
@@ -80,6 +82,15 @@ type Connection struct {
 	Host string `json:"host,omitempty" yaml:"host,omitempty"`
 	// The Port to connect to. Defaults to 22 if set to 0.
 	Port uint64 `json:"port,omitempty" yaml:"port,omitempty"`
+	// The HostKey is the expected SSH host key of the host in the authorized_keys format (ex: "ssh-ed25519 AAAA...").
+	// If not set, host key is checked according to the Yorc server configuration.
+	// The mapstructure tag is needed for viper unmarshalling
+	HostKey string `json:"host_key,omitempty" yaml:"host_key,omitempty" mapstructure:"host_key"`
+	// The Certificate is an optional OpenSSH user certificate issued for the PrivateKey (path or content)
+	Certificate string `json:"certificate,omitempty" yaml:"certificate,omitempty"`
+	// The VaultSSHSigningPath is an optional Vault path used to get a freshly signed certificate for the PrivateKey before each connection.
+	// The mapstructure tag is needed for viper unmarshalling
+	VaultSSHSigningPath string `json:"vault_ssh_signing_path,omitempty" yaml:"vault_ssh_signing_path,omitempty" mapstructure:"vault_ssh_signing_path"`
 }
 
 // String allows to stringify a connection
@@ -92,7 +103,18 @@ func (conn Connection) String() string {
 		key = "private key: " + conn.PrivateKey + ", "
 	}
 
-	return "user: " + conn.User + ", " + pass + key + "host: " + conn.Host + ", " + "port: " + strconv.FormatUint(conn.Port, 10)
+	var hostKey string
+	if conn.HostKey != "" {
+		hostKey = ", host key: " + conn.HostKey
+	}
+	if conn.Certificate != "" {
+		key += "certificate: " + conn.Certificate + ", "
+	}
+	if conn.VaultSSHSigningPath != "" {
+		key += "vault ssh signing path: " + conn.VaultSSHSigningPath + ", "
+	}
+
+	return "user: " + conn.User + ", " + pass + key + "host: " + conn.Host + ", " + "port: " + strconv.FormatUint(conn.Port, 10) + hostKey
 }
 
 // A Pool holds information on a hosts pool
@@ -113,6 +135,8 @@ type Host struct {
 	Message     string            `json:"reason,omitempty"`
 	Labels      map[string]string `json:"labels,omitempty"`
 	Allocations []Allocation      `json:"allocations,omitempty"`
+	// MaintenanceEnd is the optional time after which a host in maintenance automatically returns in service
+	MaintenanceEnd *time.Time `json:"maintenance_end,omitempty"`
 }
 
 // An HostConfig holds information on an Host basic configuration
@@ -133,6 +157,49 @@ type Allocation struct {
 	Resources        map[string]string  `json:"resource_labels,omitempty"`
 	GenericResources []*GenericResource `json:"gres_labels,omitempty"`
 	PlacementPolicy  string             `json:"placement_policy"`
+	// CapacityPlacement holds the options of the capacity-aware placement policy if any
+	CapacityPlacement *CapacityPlacement `json:"capacity_placement,omitempty"`
+	// Affinities are affinity or anti-affinity rules between this allocation and other ones
+	Affinities []AffinityRule `json:"affinities,omitempty"`
+	// Reservation is the name of the reservation holding the hosts the allocation should be done on
+	Reservation string `json:"reservation,omitempty"`
+	// LeaseDuration is the duration of the allocation lease, the allocation never expires if not set
+	LeaseDuration time.Duration `json:"-"`
+	// LeaseExpiry is the time after which the allocation is released if its lease is not renewed
+	LeaseExpiry *time.Time `json:"lease_expiry,omitempty"`
+}
+
+// A Reservation holds hosts of a hosts pool for a given owner until its expiry.
+// Reserved hosts could only be allocated by allocations referencing the reservation.
+type Reservation struct {
+	Name   string    `json:"name"`
+	Owner  string    `json:"owner"`
+	Hosts  []string  `json:"hosts"`
+	Expiry time.Time `json:"expiry"`
+}
+
+// IsExpired returns true if the reservation expiry is passed
+func (r *Reservation) IsExpired() bool {
+	return !r.Expiry.After(time.Now())
+}
+
+// CapacityPlacement holds the options of a placement based on the remaining numeric capacities of hosts
+type CapacityPlacement struct {
+	// Strategy is either "pack" to prefer hosts with the less remaining capacity or "spread" to prefer the ones with the more remaining capacity
+	Strategy string `json:"strategy"`
+	// Resources are the consumable resources labels taken into account
+	Resources []string `json:"resources"`
+}
+
+// AffinityRule is an affinity or anti-affinity rule between allocations
+type AffinityRule struct {
+	AntiAffinity bool `json:"anti_affinity"`
+	// Scope is either "node" for instances of the same node of a deployment or "deployment" for all nodes of a deployment
+	Scope string `json:"scope"`
+	// TopologyLabel is the host label defining the topology domain (a rack for instance), each host is a domain if empty
+	TopologyLabel string `json:"topology_label,omitempty"`
+	// Required rules make the allocation fail if they can't be satisfied, other ones are best effort
+	Required bool `json:"required"`
 }
 
 func (alloc *Allocation) String() string {
@@ -155,6 +222,13 @@ func (alloc *Allocation) String() string {
 		}
 	}
 
+	if alloc.Reservation != "" {
+		allocStr += "|reservation: " + alloc.Reservation
+	}
+	if alloc.LeaseExpiry != nil {
+		allocStr += "|lease expiry: " + alloc.LeaseExpiry.Format(time.RFC3339)
+	}
+
 	return allocStr
 }
 
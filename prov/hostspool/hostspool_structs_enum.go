@@ -29,14 +29,17 @@ const (
 	HostStatusAllocated
 	// HostStatusError is a HostStatus of type Error
 	HostStatusError
+	// HostStatusMaintenance is a HostStatus of type Maintenance
+	HostStatusMaintenance
 )
 
-const _HostStatusName = "freeallocatederror"
+const _HostStatusName = "freeallocatederrormaintenance"
 
 var _HostStatusMap = map[HostStatus]string{
 	0: _HostStatusName[0:4],
 	1: _HostStatusName[4:13],
 	2: _HostStatusName[13:18],
+	3: _HostStatusName[18:29],
 }
 
 // String implements the Stringer interface.
@@ -54,6 +57,8 @@ var _HostStatusValue = map[string]HostStatus{
 	strings.ToLower(_HostStatusName[4:13]):  1,
 	_HostStatusName[13:18]:                  2,
 	strings.ToLower(_HostStatusName[13:18]): 2,
+	_HostStatusName[18:29]:                  3,
+	strings.ToLower(_HostStatusName[18:29]): 3,
 }
 
 // ParseHostStatus attempts to convert a string to a HostStatus
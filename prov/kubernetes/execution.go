@@ -20,6 +20,7 @@ import (
 	"strings"
 
 	"github.com/pkg/errors"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 
 	"github.com/ystia/yorc/v4/config"
@@ -33,6 +34,8 @@ const k8sDeploymentResourceType string = "yorc.nodes.kubernetes.api.types.Deploy
 const k8sStatefulsetResourceType string = "yorc.nodes.kubernetes.api.types.StatefulSetResource"
 const k8sServiceResourceType string = "yorc.nodes.kubernetes.api.types.ServiceResource"
 const k8sSimpleRessourceType string = "yorc.nodes.kubernetes.api.types.SimpleResource"
+const k8sGenericResourceType string = "yorc.nodes.kubernetes.api.types.GenericResource"
+const k8sHelmReleaseType string = "yorc.nodes.kubernetes.api.types.HelmRelease"
 
 type k8sResourceOperation int
 
@@ -50,6 +53,10 @@ type execution struct {
 	nodeName     string
 	operation    prov.Operation
 	nodeType     string
+	// dynamicClient is used to manage generic resources
+	dynamicClient dynamic.Interface
+	// locationProps are used to give access to the cluster to external tools like helm
+	locationProps config.DynamicMap
 }
 
 const namespaceCreatedMessage string = "K8's Namespace %s created"
@@ -84,6 +91,10 @@ func (e *execution) execute(ctx context.Context, clientset kubernetes.Interface)
 		return e.executeJobOperation(ctx, clientset)
 	}
 
+	if e.nodeType == k8sHelmReleaseType {
+		return e.executeHelmOperation(ctx, clientset)
+	}
+
 	// TODO is there any reason for recreating a new generator for each execution?
 	generator := newGenerator(e.cfg)
 
@@ -132,6 +143,8 @@ func (e *execution) getYorcK8sObject(ctx context.Context, clientset kubernetes.I
 		K8sObj = &yorcK8sStatefulSet{}
 	case k8sServiceResourceType:
 		K8sObj = &yorcK8sService{}
+	case k8sGenericResourceType:
+		K8sObj = &yorcK8sGenericResource{dynamicClient: e.dynamicClient}
 	case k8sSimpleRessourceType:
 		rType, err := e.getResourceType(ctx)
 		if err != nil {
@@ -198,6 +211,10 @@ func (e *execution) manageKubernetesResource(ctx context.Context, clientset kube
 	switch OPtype
 	*/
 	namespaceName, namespaceProvided := getNamespace(e.deploymentID, k8sObject.getObjectMeta())
+	if genericResource, ok := k8sObject.(*yorcK8sGenericResource); ok && !genericResource.namespaced {
+		// Cluster-scoped resources do not belong to any namespace
+		namespaceName, namespaceProvided = "", true
+	}
 	switch operationType {
 	case k8sCreateOperation:
 		/*
@@ -0,0 +1,502 @@
+// Copyright 2021 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/ystia/yorc/v4/config"
+	"github.com/ystia/yorc/v4/deployments"
+	"github.com/ystia/yorc/v4/events"
+	"github.com/ystia/yorc/v4/helper/executil"
+	"github.com/ystia/yorc/v4/helper/stringutil"
+	"github.com/ystia/yorc/v4/log"
+	"github.com/ystia/yorc/v4/prov/operations"
+)
+
+const helmReleaseUpgradeOperationName = "yorc.interfaces.kubernetes.helmrelease.upgrade"
+
+// helmChartArtifactName is the name of the node artifact providing a chart archive or directory
+const helmChartArtifactName = "chart"
+
+// helmSetValueReplacer escapes backslashes, value separators and list delimiters of Helm --set values
+var helmSetValueReplacer = strings.NewReplacer(`\`, `\\`, ",", `\,`, "{", `\{`, "}", `\}`)
+
+// helmRelease is the Yorc representation of a Helm release deployed by a node
+type helmRelease struct {
+	name              string
+	namespace         string
+	namespaceProvided bool
+	// chart is either a chart reference (repo/name, name with a repository, oci:// URL)
+	// or the path of a chart archive or directory
+	chart      string
+	repository string
+	version    string
+	// values is the content of a YAML values file
+	values    string
+	setValues map[string]string
+	timeout   string
+}
+
+// helmReleaseStatus is the subset of the "helm status -o json" result used by Yorc
+type helmReleaseStatus struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Version   int    `json:"version"`
+	Info      struct {
+		Status string `json:"status"`
+		Notes  string `json:"notes"`
+	} `json:"info"`
+}
+
+// runHelm executes a helm command and returns its standard output. Standard error is logged in
+// deployment logs as well as standard output when logOutput is true.
+func runHelm(ctx context.Context, deploymentID string, env []string, logOutput bool, args ...string) ([]byte, error) {
+	cmd := executil.Command(ctx, "helm", args...)
+	cmd.Env = append(os.Environ(), env...)
+	quit := make(chan bool)
+	defer close(quit)
+	errbuf := events.NewBufferedLogEntryWriter()
+	cmd.Stderr = errbuf
+	events.WithContextOptionalFields(ctx).NewLogEntry(events.LogLevelERROR, deploymentID).RunBufferedRegistration(errbuf, quit)
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	if logOutput {
+		out := events.NewBufferedLogEntryWriter()
+		cmd.Stdout = io.MultiWriter(&stdout, out)
+		events.WithContextOptionalFields(ctx).NewLogEntry(events.LogLevelINFO, deploymentID).RunBufferedRegistration(out, quit)
+	}
+	err := cmd.Run()
+	return stdout.Bytes(), errors.Wrapf(err, "helm %s command failed", args[0])
+}
+
+func (e *execution) executeHelmOperation(ctx context.Context, clientset kubernetes.Interface) error {
+	release, err := e.getHelmRelease(ctx)
+	if err != nil {
+		return err
+	}
+
+	kubeArgs, env, cleanup, err := helmKubeConfig(e.locationProps)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	operationName := strings.TrimPrefix(strings.ToLower(e.operation.Name), "tosca.interfaces.node.lifecycle.")
+	switch operationName {
+	case "standard.create", helmReleaseUpgradeOperationName:
+		return e.installOrUpgradeHelmRelease(ctx, clientset, release, kubeArgs, env)
+	case "standard.delete":
+		return e.uninstallHelmRelease(ctx, clientset, release, kubeArgs, env)
+	default:
+		return errors.Errorf("unsupported operation %q for node %q", e.operation.Name, e.nodeName)
+	}
+}
+
+func (e *execution) getHelmRelease(ctx context.Context) (*helmRelease, error) {
+	release := &helmRelease{}
+	var err error
+	release.name, err = e.getStringProperty(ctx, "release_name")
+	if err != nil {
+		return nil, err
+	}
+	if release.name == "" {
+		release.name = strings.ToLower(strings.Replace(e.nodeName, "_", "-", -1))
+	}
+	release.namespace, err = e.getStringProperty(ctx, "namespace")
+	if err != nil {
+		return nil, err
+	}
+	release.namespace, release.namespaceProvided = getNamespace(e.deploymentID, metav1.ObjectMeta{Namespace: release.namespace})
+
+	release.chart, err = e.getHelmChartArtifactPath(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if release.chart == "" {
+		release.chart, err = e.getStringProperty(ctx, "chart")
+		if err != nil {
+			return nil, err
+		}
+		release.repository, err = e.getStringProperty(ctx, "repository")
+		if err != nil {
+			return nil, err
+		}
+	}
+	if release.chart == "" {
+		return nil, errors.Errorf("neither a %q artifact nor a chart property are defined for node %q", helmChartArtifactName, e.nodeName)
+	}
+
+	release.version, err = e.getStringProperty(ctx, "version")
+	if err != nil {
+		return nil, err
+	}
+	release.values, err = e.getStringProperty(ctx, "values")
+	if err != nil {
+		return nil, err
+	}
+	release.timeout, err = e.getStringProperty(ctx, "timeout")
+	if err != nil {
+		return nil, err
+	}
+
+	setValues, err := deployments.GetNodePropertyValue(ctx, e.deploymentID, e.nodeName, "set_values")
+	if err != nil {
+		return nil, err
+	}
+	if setValues != nil && setValues.RawString() != "" {
+		d, ok := setValues.Value.(map[string]interface{})
+		if !ok {
+			return nil, errors.New("failed to retrieve set_values map from Tosca Value: not expected type")
+		}
+		release.setValues = make(map[string]string, len(d))
+		for k, v := range d {
+			release.setValues[k] = fmt.Sprint(v)
+		}
+	}
+	return release, nil
+}
+
+func (e *execution) getStringProperty(ctx context.Context, propertyName string) (string, error) {
+	value, err := deployments.GetNodePropertyValue(ctx, e.deploymentID, e.nodeName, propertyName)
+	if err != nil || value == nil {
+		return "", err
+	}
+	return value.RawString(), nil
+}
+
+// getHelmChartArtifactPath returns the absolute path of the chart artifact of the node if any
+func (e *execution) getHelmChartArtifactPath(ctx context.Context) (string, error) {
+	artifacts, err := deployments.GetFileArtifactsForNode(ctx, e.deploymentID, e.nodeName)
+	if err != nil {
+		return "", err
+	}
+	artifactPath, ok := artifacts[helmChartArtifactName]
+	if !ok || artifactPath == "" {
+		return "", nil
+	}
+	overlayPath, err := operations.GetOverlayPath(e.cfg, e.taskID, e.deploymentID)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(overlayPath, artifactPath), nil
+}
+
+// helmInstallArgs returns arguments of the helm command allowing to install a release
+// or to upgrade it if it already exists
+func helmInstallArgs(release *helmRelease, valuesFile string) []string {
+	args := []string{"upgrade", release.name, release.chart, "--install", "--namespace", release.namespace, "--wait"}
+	if release.repository != "" {
+		args = append(args, "--repo", release.repository)
+	}
+	if release.version != "" {
+		args = append(args, "--version", release.version)
+	}
+	if release.timeout != "" {
+		args = append(args, "--timeout", release.timeout)
+	}
+	if valuesFile != "" {
+		args = append(args, "--values", valuesFile)
+	}
+	keys := make([]string, 0, len(release.setValues))
+	for k := range release.setValues {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		args = append(args, "--set-string", k+"="+escapeHelmSetValue(release.setValues[k]))
+	}
+	return args
+}
+
+// escapeHelmSetValue escapes characters having a special meaning in Helm --set values (separators and lists)
+// so the value is set as is
+func escapeHelmSetValue(value string) string {
+	return helmSetValueReplacer.Replace(value)
+}
+
+func (e *execution) installOrUpgradeHelmRelease(ctx context.Context, clientset kubernetes.Interface, release *helmRelease, kubeArgs, env []string) error {
+	if !release.namespaceProvided {
+		err := createNamespaceIfMissing(release.namespace, clientset)
+		if err != nil {
+			return err
+		}
+		events.WithContextOptionalFields(ctx).NewLogEntry(events.LogLevelINFO, e.deploymentID).Registerf(namespaceCreatedMessage, release.namespace)
+	}
+
+	var valuesFile string
+	if release.values != "" {
+		f, err := ioutil.TempFile("", "yorc-helm-values-*.yaml")
+		if err != nil {
+			return errors.Wrap(err, "failed to create Helm values file")
+		}
+		valuesFile = f.Name()
+		defer os.Remove(valuesFile)
+		_, err = f.WriteString(release.values)
+		f.Close()
+		if err != nil {
+			return errors.Wrap(err, "failed to write Helm values file")
+		}
+	}
+
+	events.WithContextOptionalFields(ctx).NewLogEntry(events.LogLevelINFO, e.deploymentID).Registerf("Installing or upgrading Helm release %q of chart %q in namespace %q", release.name, release.chart, release.namespace)
+	_, err := runHelm(ctx, e.deploymentID, env, true, append(helmInstallArgs(release, valuesFile), kubeArgs...)...)
+	if err != nil {
+		return err
+	}
+	return e.setHelmReleaseAttributes(ctx, clientset, release, kubeArgs, env)
+}
+
+func (e *execution) setHelmReleaseAttributes(ctx context.Context, clientset kubernetes.Interface, release *helmRelease, kubeArgs, env []string) error {
+	out, err := runHelm(ctx, e.deploymentID, env, false, append([]string{"status", release.name, "--namespace", release.namespace, "--output", "json"}, kubeArgs...)...)
+	if err != nil {
+		return err
+	}
+	var status helmReleaseStatus
+	err = json.Unmarshal(out, &status)
+	if err != nil {
+		return errors.Wrapf(err, "failed to parse status of Helm release %q", release.name)
+	}
+	attributes := map[string]string{
+		"release_name":     release.name,
+		"release_status":   status.Info.Status,
+		"release_revision": strconv.Itoa(status.Version),
+		"release_notes":    status.Info.Notes,
+	}
+	for k, v := range attributes {
+		err = deployments.SetAttributeForAllInstances(ctx, e.deploymentID, e.nodeName, k, v)
+		if err != nil {
+			return errors.Wrap(err, "Failed to set attribute")
+		}
+	}
+
+	manifest, err := runHelm(ctx, e.deploymentID, env, false, append([]string{"get", "manifest", release.name, "--namespace", release.namespace}, kubeArgs...)...)
+	if err != nil {
+		return err
+	}
+	endpoints, err := getHelmReleaseServicesEndpoints(clientset, release.namespace, manifest)
+	if err != nil {
+		return err
+	}
+	return deployments.SetAttributeComplexForAllInstances(ctx, e.deploymentID, e.nodeName, "service_endpoints", endpoints)
+}
+
+// getHelmReleaseServicesEndpoints returns a map of Services rendered by a release to their endpoints.
+// Endpoints are a comma-separated list of host:port, load balancers ingresses are listed first
+// followed by the cluster IP.
+func getHelmReleaseServicesEndpoints(clientset kubernetes.Interface, namespace string, manifest []byte) (map[string]interface{}, error) {
+	endpoints := make(map[string]interface{})
+	decoder := yaml.NewDecoder(bytes.NewReader(manifest))
+	for {
+		var resource struct {
+			Kind     string `yaml:"kind"`
+			Metadata struct {
+				Name      string `yaml:"name"`
+				Namespace string `yaml:"namespace"`
+			} `yaml:"metadata"`
+		}
+		err := decoder.Decode(&resource)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse Helm release manifest")
+		}
+		if resource.Kind != "Service" {
+			continue
+		}
+		ns := resource.Metadata.Namespace
+		if ns == "" {
+			ns = namespace
+		}
+		svc, err := clientset.CoreV1().Services(ns).Get(resource.Metadata.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get Service %q of Helm release", resource.Metadata.Name)
+		}
+		endpoints[svc.Name] = strings.Join(serviceEndpoints(svc), ",")
+	}
+	return endpoints, nil
+}
+
+func serviceEndpoints(svc *corev1.Service) []string {
+	var hosts []string
+	for _, ingress := range svc.Status.LoadBalancer.Ingress {
+		if ingress.Hostname != "" {
+			hosts = append(hosts, ingress.Hostname)
+		} else if ingress.IP != "" {
+			hosts = append(hosts, ingress.IP)
+		}
+	}
+	if svc.Spec.ClusterIP != "" && svc.Spec.ClusterIP != corev1.ClusterIPNone {
+		hosts = append(hosts, svc.Spec.ClusterIP)
+	}
+	var endpoints []string
+	for _, host := range hosts {
+		for _, port := range svc.Spec.Ports {
+			endpoints = append(endpoints, fmt.Sprintf("%s:%d", host, port.Port))
+		}
+	}
+	return endpoints
+}
+
+func (e *execution) uninstallHelmRelease(ctx context.Context, clientset kubernetes.Interface, release *helmRelease, kubeArgs, env []string) error {
+	events.WithContextOptionalFields(ctx).NewLogEntry(events.LogLevelINFO, e.deploymentID).Registerf("Uninstalling Helm release %q from namespace %q", release.name, release.namespace)
+	args := []string{"uninstall", release.name, "--namespace", release.namespace}
+	if release.timeout != "" {
+		args = append(args, "--timeout", release.timeout)
+	}
+	_, err := runHelm(ctx, e.deploymentID, env, true, append(args, kubeArgs...)...)
+	if err != nil {
+		return err
+	}
+	err = deployments.SetAttributeForAllInstances(ctx, e.deploymentID, e.nodeName, "release_status", "uninstalled")
+	if err != nil {
+		return errors.Wrap(err, "Failed to set attribute")
+	}
+	if release.namespaceProvided {
+		return nil
+	}
+	// Check if other deployments exist in the namespace
+	// In that case nothing to do
+	nbControllers, err := podControllersInNamespace(clientset, release.namespace)
+	if err != nil {
+		return err
+	}
+	if nbControllers > 0 {
+		events.WithContextOptionalFields(ctx).NewLogEntry(events.LogLevelINFO, e.deploymentID).Registerf("Do not delete %s namespace as %d deployments exist", release.namespace, nbControllers)
+		return nil
+	}
+	err = deleteNamespace(release.namespace, clientset)
+	if err != nil {
+		events.WithContextOptionalFields(ctx).NewLogEntry(events.LogLevelINFO, e.deploymentID).Registerf(namespaceDeletionFailedMessage, release.namespace)
+		return err
+	}
+	events.WithContextOptionalFields(ctx).NewLogEntry(events.LogLevelDEBUG, e.deploymentID).Registerf("Namespace %s deleted", release.namespace)
+	return nil
+}
+
+// helmKubeConfig returns the helm command arguments and environment variables allowing
+// to connect to the Kubernetes cluster defined in the location properties.
+// The returned cleanup function should be called once helm commands are executed to remove
+// temporary files.
+func helmKubeConfig(kubConf config.DynamicMap) ([]string, []string, func(), error) {
+	var args, env []string
+	var filesToRemove []string
+	cleanup := func() {
+		for _, f := range filesToRemove {
+			os.Remove(f)
+		}
+	}
+
+	kubeMasterIP := kubConf.GetString("master_url")
+	kubeConfigPathOrContent := kubConf.GetString("kubeconfig")
+	if kubeConfigPathOrContent == "" && kubeMasterIP == "" {
+		// Running within the Kubernetes cluster, helm uses the in-cluster configuration
+		log.Debugf("No Kubernetes cluster specified in configuration, helm will use the in-cluster configuration")
+		return args, env, cleanup, nil
+	}
+
+	if kubeConfigPathOrContent != "" {
+		kubeConfigPath, wasPath, err := stringutil.GetFilePath(kubeConfigPathOrContent)
+		if err != nil {
+			return nil, nil, cleanup, errors.Wrap(err, "Failed to get Kubernetes config file")
+		}
+		if !wasPath {
+			filesToRemove = append(filesToRemove, kubeConfigPath)
+		}
+		args = append(args, "--kubeconfig", kubeConfigPath)
+		if kubeMasterIP != "" {
+			args = append(args, "--kube-apiserver", kubeMasterIP)
+		}
+	} else {
+		kubeConfigPath, err := writeKubeConfig(kubeMasterIP, kubConf)
+		if kubeConfigPath != "" {
+			filesToRemove = append(filesToRemove, kubeConfigPath)
+		}
+		if err != nil {
+			return nil, nil, cleanup, err
+		}
+		args = append(args, "--kubeconfig", kubeConfigPath)
+	}
+
+	applicationCredsPathOrContent := kubConf.GetString("application_credentials")
+	if applicationCredsPathOrContent != "" {
+		applicationCredsPath, wasPath, err := stringutil.GetFilePath(applicationCredsPathOrContent)
+		if err != nil {
+			return nil, nil, cleanup, errors.Wrap(err, "Failed to get application credentials file")
+		}
+		if !wasPath {
+			filesToRemove = append(filesToRemove, applicationCredsPath)
+		}
+		env = append(env, "GOOGLE_APPLICATION_CREDENTIALS="+applicationCredsPath)
+	}
+	return args, env, cleanup, nil
+}
+
+// writeKubeConfig writes a temporary kubeconfig file from the location properties
+// and returns its path
+func writeKubeConfig(kubeMasterIP string, kubConf config.DynamicMap) (string, error) {
+	cluster := map[string]interface{}{
+		"server": kubeMasterIP,
+	}
+	if kubConf.GetBool("insecure") {
+		cluster["insecure-skip-tls-verify"] = true
+	}
+	if caFile := kubConf.GetString("ca_file"); caFile != "" {
+		cluster["certificate-authority"] = caFile
+	}
+	user := make(map[string]interface{})
+	if certFile := kubConf.GetString("cert_file"); certFile != "" {
+		user["client-certificate"] = certFile
+	}
+	if keyFile := kubConf.GetString("key_file"); keyFile != "" {
+		user["client-key"] = keyFile
+	}
+	kubeConfig := map[string]interface{}{
+		"apiVersion":      "v1",
+		"kind":            "Config",
+		"clusters":        []interface{}{map[string]interface{}{"name": "yorc", "cluster": cluster}},
+		"users":           []interface{}{map[string]interface{}{"name": "yorc", "user": user}},
+		"contexts":        []interface{}{map[string]interface{}{"name": "yorc", "context": map[string]interface{}{"cluster": "yorc", "user": "yorc"}}},
+		"current-context": "yorc",
+	}
+	content, err := yaml.Marshal(kubeConfig)
+	if err != nil {
+		return "", errors.Wrap(err, "Failed to generate Kubernetes config file")
+	}
+	f, err := ioutil.TempFile("", "yorc-kubeconfig-")
+	if err != nil {
+		return "", errors.Wrap(err, "Failed to create Kubernetes config file")
+	}
+	defer f.Close()
+	_, err = f.Write(content)
+	return f.Name(), errors.Wrap(err, "Failed to write Kubernetes config file")
+}
@@ -0,0 +1,141 @@
+// Copyright 2021 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/ystia/yorc/v4/config"
+)
+
+func Test_helmInstallArgs(t *testing.T) {
+	tests := []struct {
+		name       string
+		release    *helmRelease
+		valuesFile string
+		want       []string
+	}{
+		{"MinimalRelease", &helmRelease{name: "rel", namespace: "ns", chart: "/tmp/overlay/charts/mychart.tgz"}, "",
+			[]string{"upgrade", "rel", "/tmp/overlay/charts/mychart.tgz", "--install", "--namespace", "ns", "--wait"}},
+		{"RepositoryRelease", &helmRelease{name: "rel", namespace: "ns", chart: "nginx", repository: "https://charts.example.com", version: "1.2.3", timeout: "10m"}, "",
+			[]string{"upgrade", "rel", "nginx", "--install", "--namespace", "ns", "--wait", "--repo", "https://charts.example.com", "--version", "1.2.3", "--timeout", "10m"}},
+		{"ReleaseWithValues", &helmRelease{name: "rel", namespace: "ns", chart: "repo/nginx", setValues: map[string]string{"service.type": "NodePort", "replicaCount": "2"}}, "/tmp/values.yaml",
+			[]string{"upgrade", "rel", "repo/nginx", "--install", "--namespace", "ns", "--wait", "--values", "/tmp/values.yaml", "--set-string", "replicaCount=2", "--set-string", "service.type=NodePort"}},
+		{"ReleaseWithSpecialValues", &helmRelease{name: "rel", namespace: "ns", chart: "repo/nginx", setValues: map[string]string{"hosts": "a.example.com,b.example.com", "list": "{x,y}", "path": `C:\dir`, "expr": "a=b"}}, "",
+			[]string{"upgrade", "rel", "repo/nginx", "--install", "--namespace", "ns", "--wait", "--set-string", "expr=a=b", "--set-string", `hosts=a.example.com\,b.example.com`, "--set-string", `list=\{x\,y\}`, "--set-string", `path=C:\\dir`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, helmInstallArgs(tt.release, tt.valuesFile))
+		})
+	}
+}
+
+func Test_getHelmReleaseServicesEndpoints(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "ns"},
+			Spec: corev1.ServiceSpec{
+				ClusterIP: "10.0.0.10",
+				Ports:     []corev1.ServicePort{{Port: 80}, {Port: 443}},
+			},
+			Status: corev1.ServiceStatus{LoadBalancer: corev1.LoadBalancerStatus{Ingress: []corev1.LoadBalancerIngress{{IP: "1.2.3.4"}}}},
+		},
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "headless", Namespace: "other"},
+			Spec: corev1.ServiceSpec{
+				ClusterIP: corev1.ClusterIPNone,
+				Ports:     []corev1.ServicePort{{Port: 5432}},
+			},
+		},
+	)
+	manifest := `---
+# Source: chart/templates/configmap.yaml
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+---
+# Source: chart/templates/service.yaml
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: headless
+  namespace: other
+`
+	endpoints, err := getHelmReleaseServicesEndpoints(clientset, "ns", []byte(manifest))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"web":      "1.2.3.4:80,1.2.3.4:443,10.0.0.10:80,10.0.0.10:443",
+		"headless": "",
+	}, endpoints)
+
+	_, err = getHelmReleaseServicesEndpoints(clientset, "ns", []byte("kind: Service\nmetadata:\n  name: missing\n"))
+	assert.Error(t, err)
+}
+
+func Test_helmKubeConfig(t *testing.T) {
+	// In cluster configuration
+	args, env, cleanup, err := helmKubeConfig(config.DynamicMap{})
+	require.NoError(t, err)
+	cleanup()
+	assert.Len(t, args, 0)
+	assert.Len(t, env, 0)
+
+	// Configuration generated from location properties
+	args, _, cleanup, err = helmKubeConfig(config.DynamicMap{"master_url": "https://10.0.0.1:6443", "insecure": true, "cert_file": "/etc/cert.pem", "key_file": "/etc/key.pem"})
+	require.NoError(t, err)
+	require.Len(t, args, 2)
+	assert.Equal(t, "--kubeconfig", args[0])
+	kubeConfig, err := clientcmd.LoadFromFile(args[1])
+	require.NoError(t, err)
+	require.Contains(t, kubeConfig.Clusters, kubeConfig.Contexts[kubeConfig.CurrentContext].Cluster)
+	cluster := kubeConfig.Clusters[kubeConfig.Contexts[kubeConfig.CurrentContext].Cluster]
+	assert.Equal(t, "https://10.0.0.1:6443", cluster.Server)
+	assert.True(t, cluster.InsecureSkipTLSVerify)
+	authInfo := kubeConfig.AuthInfos[kubeConfig.Contexts[kubeConfig.CurrentContext].AuthInfo]
+	assert.Equal(t, "/etc/cert.pem", authInfo.ClientCertificate)
+	assert.Equal(t, "/etc/key.pem", authInfo.ClientKey)
+	cleanup()
+	_, err = os.Stat(args[1])
+	assert.True(t, os.IsNotExist(err), "generated kubeconfig should be removed")
+
+	// Kubeconfig content and application credentials
+	args, env, cleanup, err = helmKubeConfig(config.DynamicMap{"kubeconfig": "apiVersion: v1\nkind: Config\n", "application_credentials": `{"type": "service_account"}`})
+	require.NoError(t, err)
+	require.Len(t, args, 2)
+	content, err := ioutil.ReadFile(args[1])
+	require.NoError(t, err)
+	assert.Equal(t, "apiVersion: v1\nkind: Config\n", string(content))
+	require.Len(t, env, 1)
+	assert.Contains(t, env[0], "GOOGLE_APPLICATION_CREDENTIALS=")
+	cleanup()
+	_, err = os.Stat(args[1])
+	assert.True(t, os.IsNotExist(err), "kubeconfig file should be removed")
+}
@@ -22,6 +22,7 @@ import (
 	"time"
 
 	"github.com/pkg/errors"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
@@ -89,10 +90,12 @@ func (e *defaultExecutor) ExecOperation(ctx context.Context, conf config.Configu
 		return err
 	}
 
-	clientSet, err := getClientSet(locationProps)
+	clientSet, dynamicClient, err := getClients(locationProps)
 	if err != nil {
 		return err
 	}
+	exec.dynamicClient = dynamicClient
+	exec.locationProps = locationProps
 
 	return exec.execute(ctx, clientSet)
 }
@@ -102,6 +105,13 @@ func isNilValue(i interface{}) bool {
 }
 
 func getClientSet(kubConf config.DynamicMap) (*kubernetes.Clientset, error) {
+	clientset, _, err := getClients(kubConf)
+	return clientset, err
+}
+
+// getClients returns both a typed clientset and a dynamic client, this last one
+// allowing to manage any kind of Kubernetes resource
+func getClients(kubConf config.DynamicMap) (*kubernetes.Clientset, dynamic.Interface, error) {
 
 	var conf *rest.Config
 	var err error
@@ -118,7 +128,7 @@ func getClientSet(kubConf config.DynamicMap) (*kubernetes.Clientset, error) {
 		log.Debugf("No Kubernetes cluster specified in configuration, attempting to authenticate inside the cluster")
 		conf, err = rest.InClusterConfig()
 		if err != nil {
-			return nil, errors.Wrap(err, "Failed to build kubernetes InClusterConfig")
+			return nil, nil, errors.Wrap(err, "Failed to build kubernetes InClusterConfig")
 		}
 	} else {
 
@@ -129,12 +139,12 @@ func getClientSet(kubConf config.DynamicMap) (*kubernetes.Clientset, error) {
 		var wasPath bool
 		if kubeConfigPathOrContent != "" {
 			if kubeConfigPath, wasPath, err = stringutil.GetFilePath(kubeConfigPathOrContent); err != nil {
-				return nil, errors.Wrap(err, "Failed to get Kubernetes config file")
+				return nil, nil, errors.Wrap(err, "Failed to get Kubernetes config file")
 			}
 			if !wasPath {
 				// check if content contains required K8s information
 				if !strings.Contains(kubeConfigPathOrContent, "apiVersion") || !strings.Contains(kubeConfigPathOrContent, "kind") {
-					return nil, errors.Errorf("Bad \"kubeconfig\" path/content provided in Yorc configuration (%q)", kubeConfigPathOrContent)
+					return nil, nil, errors.Errorf("Bad \"kubeconfig\" path/content provided in Yorc configuration (%q)", kubeConfigPathOrContent)
 				}
 				defer os.Remove(kubeConfigPath)
 			}
@@ -154,7 +164,7 @@ func getClientSet(kubConf config.DynamicMap) (*kubernetes.Clientset, error) {
 
 			applicationCredsPath, wasPath, err := stringutil.GetFilePath(applicationCredsPathOrContent)
 			if err != nil {
-				return nil, errors.Wrap(err, "Failed to get application credentials file")
+				return nil, nil, errors.Wrap(err, "Failed to get application credentials file")
 			}
 			if !wasPath {
 				defer os.Remove(applicationCredsPath)
@@ -166,7 +176,7 @@ func getClientSet(kubConf config.DynamicMap) (*kubernetes.Clientset, error) {
 
 		conf, err = clientcmd.BuildConfigFromFlags(kubeMasterIP, kubeConfigPath)
 		if err != nil {
-			return nil, errors.Wrap(err, "Failed to build kubernetes config")
+			return nil, nil, errors.Wrap(err, "Failed to build kubernetes config")
 		}
 
 		if kubeConfigPath == "" {
@@ -183,5 +193,9 @@ func getClientSet(kubConf config.DynamicMap) (*kubernetes.Clientset, error) {
 	}
 
 	clientset, err := kubernetes.NewForConfig(conf)
-	return clientset, errors.Wrap(err, "Failed to create kubernetes clientset from config")
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "Failed to create kubernetes clientset from config")
+	}
+	dynamicClient, err := dynamic.NewForConfig(conf)
+	return clientset, dynamicClient, errors.Wrap(err, "Failed to create kubernetes dynamic client from config")
 }
@@ -0,0 +1,340 @@
+// Copyright 2021 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/jsonpath"
+
+	"github.com/ystia/yorc/v4/deployments"
+	"github.com/ystia/yorc/v4/events"
+	"github.com/ystia/yorc/v4/helper/collections"
+	"github.com/ystia/yorc/v4/log"
+)
+
+// Conditions types commonly used by Kubernetes resources to tell that they are ready
+var readyConditionTypes = []string{"Ready", "Available", "Established", "Complete"}
+
+// Phases (status.phase) of Kubernetes resources telling that they are ready or failed
+var readyPhases = []string{"Active", "Bound", "Running", "Succeeded"}
+var failedPhases = []string{"Failed", "Lost"}
+
+// yorcK8sGenericResource allows to manage any kind of Kubernetes resource
+// (ConfigMaps, Secrets, Ingresses, DaemonSets, CronJobs, custom resources...)
+// using its unstructured representation and the dynamic client.
+//
+// Resources are created if they don't exist or updated by merging the specification
+// into the existing resource, server-side apply is not available with the Kubernetes
+// API client used by Yorc.
+type yorcK8sGenericResource struct {
+	unstructured.Unstructured
+	dynamicClient dynamic.Interface
+	gvr           schema.GroupVersionResource
+	namespaced    bool
+	// map of attribute names to JSONPath expressions
+	attributesPaths map[string]string
+	// last known state of the resource on the cluster
+	current *unstructured.Unstructured
+}
+
+func (yorcGen *yorcK8sGenericResource) unmarshalResource(ctx context.Context, e *execution, deploymentID string, clientset kubernetes.Interface, rSpec string) error {
+	err := yorcGen.UnmarshalJSON([]byte(rSpec))
+	if err != nil {
+		return err
+	}
+	if yorcGen.GetName() == "" {
+		return errors.Errorf("Missing metadata.name in resource specification of node %q", e.nodeName)
+	}
+	if yorcGen.dynamicClient == nil {
+		return errors.Errorf("No Kubernetes dynamic client available to manage node %q", e.nodeName)
+	}
+	yorcGen.gvr, yorcGen.namespaced, err = discoverResource(clientset, yorcGen.GroupVersionKind())
+	if err != nil {
+		return err
+	}
+	yorcGen.attributesPaths, err = getAttributesPaths(ctx, deploymentID, e.nodeName)
+	return err
+}
+
+// discoverResource uses the Kubernetes discovery API to get the resource matching the given kind
+// and to know if this resource is namespaced or not
+func discoverResource(clientset kubernetes.Interface, gvk schema.GroupVersionKind) (schema.GroupVersionResource, bool, error) {
+	gv := gvk.GroupVersion()
+	if gvk.Kind == "" || gvk.Version == "" {
+		return schema.GroupVersionResource{}, false, errors.New("apiVersion and kind are required in resource specification")
+	}
+	resources, err := clientset.Discovery().ServerResourcesForGroupVersion(gv.String())
+	if err != nil {
+		return schema.GroupVersionResource{}, false, errors.Wrapf(err, "failed to discover Kubernetes resources for API version %q", gv.String())
+	}
+	for _, r := range resources.APIResources {
+		// Skip sub-resources like deployments/scale
+		if r.Kind == gvk.Kind && !strings.Contains(r.Name, "/") {
+			return gv.WithResource(r.Name), r.Namespaced, nil
+		}
+	}
+	return schema.GroupVersionResource{}, false, errors.Errorf("Kubernetes resource kind %q not found in API version %q", gvk.Kind, gv.String())
+}
+
+func getAttributesPaths(ctx context.Context, deploymentID, nodeName string) (map[string]string, error) {
+	attrsVal, err := deployments.GetNodePropertyValue(ctx, deploymentID, nodeName, "attributes_jsonpath")
+	if err != nil || attrsVal == nil || attrsVal.RawString() == "" {
+		return nil, err
+	}
+	d, ok := attrsVal.Value.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("failed to retrieve attributes_jsonpath map from Tosca Value: not expected type")
+	}
+	paths := make(map[string]string, len(d))
+	for k, v := range d {
+		path, ok := v.(string)
+		if !ok {
+			return nil, errors.Errorf("failed to retrieve JSONPath expression of attribute %q: not expected type", k)
+		}
+		paths[k] = path
+	}
+	return paths, nil
+}
+
+func (yorcGen *yorcK8sGenericResource) getObjectMeta() metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Name:        yorcGen.GetName(),
+		Namespace:   yorcGen.GetNamespace(),
+		Labels:      yorcGen.GetLabels(),
+		Annotations: yorcGen.GetAnnotations(),
+	}
+}
+
+func (yorcGen *yorcK8sGenericResource) resourceInterface(namespace string) dynamic.ResourceInterface {
+	if !yorcGen.namespaced {
+		return yorcGen.dynamicClient.Resource(yorcGen.gvr)
+	}
+	return yorcGen.dynamicClient.Resource(yorcGen.gvr).Namespace(namespace)
+}
+
+func (yorcGen *yorcK8sGenericResource) createResource(ctx context.Context, deploymentID string, clientset kubernetes.Interface, namespace string) error {
+	obj := yorcGen.DeepCopy()
+	if yorcGen.namespaced {
+		obj.SetNamespace(namespace)
+	}
+	ri := yorcGen.resourceInterface(namespace)
+	existing, err := ri.Get(obj.GetName(), metav1.GetOptions{})
+	if k8serrors.IsNotFound(err) {
+		yorcGen.current, err = ri.Create(obj)
+		return errors.Wrapf(err, "failed to create Kubernetes %s %q", obj.GetKind(), obj.GetName())
+	}
+	if err != nil {
+		return err
+	}
+	// The resource already exists, apply the specification on it keeping
+	// fields managed by the cluster
+	log.Debugf("Kubernetes %s %q already exists in deployment %q, updating it", obj.GetKind(), obj.GetName(), deploymentID)
+	mergeObjects(existing.Object, obj.Object)
+	yorcGen.current, err = ri.Update(existing)
+	return errors.Wrapf(err, "failed to update Kubernetes %s %q", obj.GetKind(), obj.GetName())
+}
+
+// mergeObjects merges src into dst following the JSON merge patch semantics:
+// maps are merged recursively, null values remove fields and other values
+// (including lists) are replaced
+func mergeObjects(dst, src map[string]interface{}) {
+	for k, v := range src {
+		if v == nil {
+			delete(dst, k)
+			continue
+		}
+		srcMap, srcIsMap := v.(map[string]interface{})
+		dstMap, dstIsMap := dst[k].(map[string]interface{})
+		if srcIsMap && dstIsMap {
+			mergeObjects(dstMap, srcMap)
+			continue
+		}
+		dst[k] = v
+	}
+}
+
+func (yorcGen *yorcK8sGenericResource) deleteResource(ctx context.Context, deploymentID string, clientset kubernetes.Interface, namespace string) error {
+	deletePolicy := metav1.DeletePropagationForeground
+	return yorcGen.resourceInterface(namespace).Delete(yorcGen.GetName(), &metav1.DeleteOptions{PropagationPolicy: &deletePolicy})
+}
+
+func (yorcGen *yorcK8sGenericResource) scaleResource(ctx context.Context, e *execution, clientset kubernetes.Interface, namespace string) error {
+	return errors.New("Scale operation is not supported by generic resources")
+}
+
+func (yorcGen *yorcK8sGenericResource) setAttributes(ctx context.Context, e *execution) error {
+	obj := yorcGen.current
+	if obj == nil {
+		obj = &yorcGen.Unstructured
+	}
+	for attr, path := range yorcGen.attributesPaths {
+		value, err := evalJSONPath(obj.Object, path)
+		if err != nil {
+			return errors.Wrapf(err, "failed to compute attribute %q of node %q", attr, e.nodeName)
+		}
+		err = deployments.SetAttributeForAllInstances(ctx, e.deploymentID, e.nodeName, attr, value)
+		if err != nil {
+			return errors.Wrap(err, "Failed to set attribute")
+		}
+	}
+	return nil
+}
+
+// evalJSONPath evaluates a JSONPath expression like the ones used by kubectl (with or without
+// surrounding braces) on the given object. Non scalar results are returned as JSON.
+func evalJSONPath(obj map[string]interface{}, path string) (string, error) {
+	if !strings.HasPrefix(path, "{") {
+		path = "{" + path + "}"
+	}
+	jp := jsonpath.New("attribute").AllowMissingKeys(true)
+	err := jp.Parse(path)
+	if err != nil {
+		return "", errors.Wrapf(err, "invalid JSONPath expression %q", path)
+	}
+	results, err := jp.FindResults(obj)
+	if err != nil {
+		return "", err
+	}
+	values := make([]string, 0)
+	for _, r := range results {
+		for _, v := range r {
+			if !v.IsValid() || !v.CanInterface() {
+				continue
+			}
+			switch value := v.Interface().(type) {
+			case string:
+				values = append(values, value)
+			case nil:
+			default:
+				b, err := json.Marshal(value)
+				if err != nil {
+					return "", err
+				}
+				values = append(values, string(b))
+			}
+		}
+	}
+	return strings.Join(values, " "), nil
+}
+
+func (yorcGen *yorcK8sGenericResource) isSuccessfullyDeployed(ctx context.Context, deploymentID string, clientset kubernetes.Interface, namespace string) (bool, error) {
+	obj, err := yorcGen.resourceInterface(namespace).Get(yorcGen.GetName(), metav1.GetOptions{})
+	if err != nil {
+		return false, err
+	}
+	yorcGen.current = obj
+	ready, failureMsg := isResourceReady(obj)
+	if failureMsg != "" {
+		events.WithContextOptionalFields(ctx).NewLogEntry(events.LogLevelERROR, deploymentID).Registerf("Kubernetes %s %q failed: %s", obj.GetKind(), obj.GetName(), failureMsg)
+		return false, errors.Errorf("Kubernetes %s %q: %s", obj.GetKind(), obj.GetName(), failureMsg)
+	}
+	return ready, nil
+}
+
+// isResourceReady checks the status of a resource to know if it is ready.
+// It relies on conventions followed by most of Kubernetes resources: observed generation,
+// conditions, phase and replicas counters. Resources without status are considered as ready.
+// A non-empty failure message is returned if the resource is in a failed state.
+func isResourceReady(obj *unstructured.Unstructured) (bool, string) {
+	status, ok := obj.Object["status"].(map[string]interface{})
+	if !ok || len(status) == 0 {
+		return true, ""
+	}
+
+	if observed, found, _ := unstructured.NestedInt64(status, "observedGeneration"); found && observed < obj.GetGeneration() {
+		return false, ""
+	}
+
+	ready := true
+	conditions, _, _ := unstructured.NestedSlice(status, "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		cType, _, _ := unstructured.NestedString(condition, "type")
+		cStatus, _, _ := unstructured.NestedString(condition, "status")
+		if cType == "Failed" && cStatus == string(corev1.ConditionTrue) {
+			msg, _, _ := unstructured.NestedString(condition, "message")
+			if msg == "" {
+				msg, _, _ = unstructured.NestedString(condition, "reason")
+			}
+			return false, fmt.Sprintf("condition %q is true: %s", cType, msg)
+		}
+		if collections.ContainsString(readyConditionTypes, cType) && cStatus != string(corev1.ConditionTrue) {
+			ready = false
+		}
+	}
+
+	if phase, found, _ := unstructured.NestedString(status, "phase"); found {
+		if collections.ContainsString(failedPhases, phase) {
+			return false, fmt.Sprintf("resource is in phase %q", phase)
+		}
+		if !collections.ContainsString(readyPhases, phase) {
+			ready = false
+		}
+	}
+
+	// Workloads controllers like Deployments, ReplicaSets or StatefulSets
+	if replicas, found, _ := unstructured.NestedInt64(obj.Object, "spec", "replicas"); found {
+		readyReplicas, _, _ := unstructured.NestedInt64(status, "readyReplicas")
+		if readyReplicas < replicas {
+			ready = false
+		}
+	}
+	// DaemonSets
+	if desired, found, _ := unstructured.NestedInt64(status, "desiredNumberScheduled"); found {
+		numberReady, _, _ := unstructured.NestedInt64(status, "numberReady")
+		if numberReady < desired {
+			ready = false
+		}
+	}
+	return ready, ""
+}
+
+func (yorcGen *yorcK8sGenericResource) isSuccessfullyDeleted(ctx context.Context, deploymentID string, clientset kubernetes.Interface, namespace string) (bool, error) {
+	_, err := yorcGen.resourceInterface(namespace).Get(yorcGen.GetName(), metav1.GetOptions{})
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, err
+	}
+	return false, nil
+}
+
+func (yorcGen *yorcK8sGenericResource) String() string {
+	return "YorcGenericResource"
+}
+
+func (yorcGen *yorcK8sGenericResource) getObjectRuntime() runtime.Object {
+	return &yorcGen.Unstructured
+}
+
+func (yorcGen *yorcK8sGenericResource) streamLogs(ctx context.Context, deploymentID string, clientset kubernetes.Interface) {
+}
@@ -0,0 +1,260 @@
+// Copyright 2021 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newTestDiscoveryClientset() *fake.Clientset {
+	clientset := fake.NewSimpleClientset()
+	clientset.Discovery().(*fakediscovery.FakeDiscovery).Resources = []*metav1.APIResourceList{
+		{
+			GroupVersion: "v1",
+			APIResources: []metav1.APIResource{
+				{Name: "configmaps", Kind: "ConfigMap", Namespaced: true},
+				{Name: "secrets", Kind: "Secret", Namespaced: true},
+			},
+		},
+		{
+			GroupVersion: "apps/v1",
+			APIResources: []metav1.APIResource{
+				{Name: "daemonsets", Kind: "DaemonSet", Namespaced: true},
+				{Name: "deployments/scale", Kind: "Scale", Namespaced: true},
+			},
+		},
+		{
+			GroupVersion: "apiextensions.k8s.io/v1beta1",
+			APIResources: []metav1.APIResource{
+				{Name: "customresourcedefinitions", Kind: "CustomResourceDefinition", Namespaced: false},
+			},
+		},
+	}
+	return clientset
+}
+
+func newTestUnstructured(apiVersion, kind, name string, content map[string]interface{}) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{Object: content}
+	if u.Object == nil {
+		u.Object = make(map[string]interface{})
+	}
+	u.SetAPIVersion(apiVersion)
+	u.SetKind(kind)
+	u.SetName(name)
+	return u
+}
+
+func Test_discoverResource(t *testing.T) {
+	clientset := newTestDiscoveryClientset()
+	tests := []struct {
+		name           string
+		gvk            schema.GroupVersionKind
+		wantGVR        schema.GroupVersionResource
+		wantNamespaced bool
+		wantErr        bool
+	}{
+		{"ConfigMap", schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}, schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}, true, false},
+		{"DaemonSet", schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "DaemonSet"}, schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "daemonsets"}, true, false},
+		{"CRD", schema.GroupVersionKind{Group: "apiextensions.k8s.io", Version: "v1beta1", Kind: "CustomResourceDefinition"}, schema.GroupVersionResource{Group: "apiextensions.k8s.io", Version: "v1beta1", Resource: "customresourcedefinitions"}, false, false},
+		{"SubResourceIgnored", schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Scale"}, schema.GroupVersionResource{}, false, true},
+		{"UnknownKind", schema.GroupVersionKind{Version: "v1", Kind: "Unknown"}, schema.GroupVersionResource{}, false, true},
+		{"UnknownGroupVersion", schema.GroupVersionKind{Group: "unknown", Version: "v1", Kind: "Unknown"}, schema.GroupVersionResource{}, false, true},
+		{"MissingKind", schema.GroupVersionKind{Version: "v1"}, schema.GroupVersionResource{}, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gvr, namespaced, err := discoverResource(clientset, tt.gvk)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantGVR, gvr)
+			assert.Equal(t, tt.wantNamespaced, namespaced)
+		})
+	}
+}
+
+func Test_isResourceReady(t *testing.T) {
+	tests := []struct {
+		name        string
+		obj         *unstructured.Unstructured
+		wantReady   bool
+		wantFailure bool
+	}{
+		{"NoStatus", newTestUnstructured("v1", "ConfigMap", "cm", map[string]interface{}{"data": map[string]interface{}{"k": "v"}}), true, false},
+		{"ObservedGenerationLate", newTestUnstructured("apps/v1", "Deployment", "dep", map[string]interface{}{
+			"metadata": map[string]interface{}{"generation": int64(2)},
+			"status":   map[string]interface{}{"observedGeneration": int64(1)},
+		}), false, false},
+		{"ConditionAvailable", newTestUnstructured("apps/v1", "Deployment", "dep", map[string]interface{}{
+			"spec": map[string]interface{}{"replicas": int64(2)},
+			"status": map[string]interface{}{"readyReplicas": int64(2), "conditions": []interface{}{
+				map[string]interface{}{"type": "Available", "status": "True"},
+				map[string]interface{}{"type": "Progressing", "status": "True"},
+			}},
+		}), true, false},
+		{"MissingReplicas", newTestUnstructured("apps/v1", "Deployment", "dep", map[string]interface{}{
+			"spec": map[string]interface{}{"replicas": int64(2)},
+			"status": map[string]interface{}{"readyReplicas": int64(1), "conditions": []interface{}{
+				map[string]interface{}{"type": "Available", "status": "True"},
+			}},
+		}), false, false},
+		{"ConditionNotEstablished", newTestUnstructured("apiextensions.k8s.io/v1beta1", "CustomResourceDefinition", "crd", map[string]interface{}{
+			"status": map[string]interface{}{"conditions": []interface{}{
+				map[string]interface{}{"type": "NamesAccepted", "status": "True"},
+				map[string]interface{}{"type": "Established", "status": "False"},
+			}},
+		}), false, false},
+		{"ConditionFailed", newTestUnstructured("batch/v1", "Job", "job", map[string]interface{}{
+			"status": map[string]interface{}{"conditions": []interface{}{
+				map[string]interface{}{"type": "Failed", "status": "True", "message": "BackoffLimitExceeded"},
+			}},
+		}), false, true},
+		{"DaemonSetNotReady", newTestUnstructured("apps/v1", "DaemonSet", "ds", map[string]interface{}{
+			"status": map[string]interface{}{"desiredNumberScheduled": int64(3), "numberReady": int64(2)},
+		}), false, false},
+		{"DaemonSetReady", newTestUnstructured("apps/v1", "DaemonSet", "ds", map[string]interface{}{
+			"status": map[string]interface{}{"desiredNumberScheduled": int64(3), "numberReady": int64(3)},
+		}), true, false},
+		{"PhasePending", newTestUnstructured("v1", "PersistentVolumeClaim", "pvc", map[string]interface{}{
+			"status": map[string]interface{}{"phase": "Pending"},
+		}), false, false},
+		{"PhaseBound", newTestUnstructured("v1", "PersistentVolumeClaim", "pvc", map[string]interface{}{
+			"status": map[string]interface{}{"phase": "Bound"},
+		}), true, false},
+		{"PhaseLost", newTestUnstructured("v1", "PersistentVolumeClaim", "pvc", map[string]interface{}{
+			"status": map[string]interface{}{"phase": "Lost"},
+		}), false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ready, failureMsg := isResourceReady(tt.obj)
+			assert.Equal(t, tt.wantReady, ready)
+			assert.Equal(t, tt.wantFailure, failureMsg != "", "unexpected failure message %q", failureMsg)
+		})
+	}
+}
+
+func Test_evalJSONPath(t *testing.T) {
+	obj := map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "ing"},
+		"status": map[string]interface{}{
+			"loadBalancer": map[string]interface{}{
+				"ingress": []interface{}{
+					map[string]interface{}{"ip": "10.0.0.1"},
+					map[string]interface{}{"ip": "10.0.0.2"},
+				},
+			},
+			"count": int64(2),
+		},
+	}
+	tests := []struct {
+		name    string
+		path    string
+		want    string
+		wantErr bool
+	}{
+		{"WithBraces", "{.metadata.name}", "ing", false},
+		{"WithoutBraces", ".status.loadBalancer.ingress[0].ip", "10.0.0.1", false},
+		{"Wildcard", "{.status.loadBalancer.ingress[*].ip}", "10.0.0.1 10.0.0.2", false},
+		{"Integer", "{.status.count}", "2", false},
+		{"Object", "{.status.loadBalancer.ingress[1]}", `{"ip":"10.0.0.2"}`, false},
+		{"MissingKey", "{.status.notHere}", "", false},
+		{"InvalidExpression", "{.status[}", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := evalJSONPath(obj, tt.path)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_genericResourceLifecycle(t *testing.T) {
+	ctx := context.Background()
+	clientset := newTestDiscoveryClientset()
+	dynamicClient := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme())
+
+	spec := `{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "my-config"}, "data": {"key": "value"}}`
+	genericResource := &yorcK8sGenericResource{dynamicClient: dynamicClient}
+	require.NoError(t, genericResource.UnmarshalJSON([]byte(spec)))
+	var err error
+	genericResource.gvr, genericResource.namespaced, err = discoverResource(clientset, genericResource.GroupVersionKind())
+	require.NoError(t, err)
+
+	// Creation
+	err = genericResource.createResource(ctx, "dep-id", clientset, "ns")
+	require.NoError(t, err)
+	ok, err := genericResource.isSuccessfullyDeployed(ctx, "dep-id", clientset, "ns")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	value, err := evalJSONPath(genericResource.current.Object, "{.data.key}")
+	require.NoError(t, err)
+	assert.Equal(t, "value", value)
+
+	// Applying a modified specification updates the existing resource
+	spec = `{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "my-config"}, "data": {"key": "other"}}`
+	require.NoError(t, genericResource.UnmarshalJSON([]byte(spec)))
+	err = genericResource.createResource(ctx, "dep-id", clientset, "ns")
+	require.NoError(t, err)
+	cm, err := dynamicClient.Resource(genericResource.gvr).Namespace("ns").Get("my-config", metav1.GetOptions{})
+	require.NoError(t, err)
+	value, err = evalJSONPath(cm.Object, "{.data.key}")
+	require.NoError(t, err)
+	assert.Equal(t, "other", value)
+
+	// Deletion
+	ok, err = genericResource.isSuccessfullyDeleted(ctx, "dep-id", clientset, "ns")
+	require.NoError(t, err)
+	assert.False(t, ok)
+	err = genericResource.deleteResource(ctx, "dep-id", clientset, "ns")
+	require.NoError(t, err)
+	ok, err = genericResource.isSuccessfullyDeleted(ctx, "dep-id", clientset, "ns")
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func Test_mergeObjects(t *testing.T) {
+	dst := map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "svc", "resourceVersion": "12"},
+		"spec":     map[string]interface{}{"clusterIP": "10.0.0.1", "ports": []interface{}{"80"}, "type": "ClusterIP"},
+	}
+	src := map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "svc", "labels": map[string]interface{}{"app": "test"}},
+		"spec":     map[string]interface{}{"ports": []interface{}{"8080"}, "type": nil},
+	}
+	mergeObjects(dst, src)
+	assert.Equal(t, map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "svc", "resourceVersion": "12", "labels": map[string]interface{}{"app": "test"}},
+		"spec":     map[string]interface{}{"clusterIP": "10.0.0.1", "ports": []interface{}{"8080"}},
+	}, dst)
+}
@@ -0,0 +1,55 @@
+// Copyright 2021 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+	"encoding/json"
+	"path"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/ystia/yorc/v4/config"
+	"github.com/ystia/yorc/v4/deployments"
+	"github.com/ystia/yorc/v4/prov"
+)
+
+// PlanOperation describes the Kubernetes object managed by an operation without contacting the cluster.
+//
+// As the cluster is not contacted, services IPs referenced by the service_dependency_lookups property are not resolved.
+func (e *defaultExecutor) PlanOperation(ctx context.Context, conf config.Configuration, deploymentID, nodeName string, operation prov.Operation) ([]prov.PlannedResource, error) {
+	nodeType, err := deployments.GetNodeType(ctx, deploymentID, nodeName)
+	if err != nil {
+		return nil, err
+	}
+	exec := &execution{cfg: conf, deploymentID: deploymentID, nodeName: nodeName, operation: operation, nodeType: nodeType}
+	rSpec, err := exec.getResourceSpec(ctx)
+	if err != nil || rSpec == "" {
+		return nil, err
+	}
+	var obj struct {
+		Kind     string            `json:"kind"`
+		Metadata metav1.ObjectMeta `json:"metadata"`
+	}
+	if err = json.Unmarshal([]byte(rSpec), &obj); err != nil {
+		return nil, errors.Wrapf(err, "The resource_spec JSON unmarshaling failed for node %s", nodeName)
+	}
+	if obj.Kind == "" {
+		obj.Kind = nodeType
+	}
+	namespace, _ := getNamespace(deploymentID, obj.Metadata)
+	return []prov.PlannedResource{{Kind: obj.Kind, Name: path.Join(namespace, obj.Metadata.Name), Content: rSpec}}, nil
+}
@@ -64,7 +64,7 @@ func (c *Check) Start() {
 	c.stop = false
 
 	// check if initially the node can be monitored according to its node state
-	if c.isNodeStateOKForMonitoring(ctx) {
+	if c.isNodeStateOKForMonitoring(ctx) && !c.isDisabled() {
 		c.enable()
 	}
 
@@ -79,7 +79,7 @@ func (c *Check) Start() {
 				c.disable()
 				return
 			case <-ticker.C:
-				if !c.isNodeStateOKForMonitoring(ctx) {
+				if !c.isNodeStateOKForMonitoring(ctx) || c.isDisabled() {
 					// Disable check
 					c.disable()
 					continue
@@ -143,6 +143,7 @@ func (c *Check) run() {
 			return
 		case <-ticker.C:
 			status, mess := c.execution.execute(c.timeout)
+			c.recordExecution(status)
 			c.updateStatus(status, mess)
 		}
 	}
@@ -15,12 +15,20 @@
 package monitoring
 
 import (
+	"context"
 	"crypto/tls"
 	"crypto/x509"
+	goerr "errors"
 	"fmt"
 	"github.com/hashicorp/go-cleanhttp"
 	"github.com/pkg/errors"
+	"github.com/ystia/yorc/v4/helper/sshutil"
 	"github.com/ystia/yorc/v4/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
 	"net"
 	"net/http"
 	"strings"
@@ -41,6 +49,23 @@ type httpCheckExecution struct {
 	header     http.Header
 }
 
+type commandCheckExecution struct {
+	client  sshutil.Client
+	command string
+}
+
+type grpcCheckExecution struct {
+	address  string
+	service  string
+	dialOpts []grpc.DialOption
+}
+
+// exitStatusError is implemented by errors returned by a remote command exiting with a non-zero status
+type exitStatusError interface {
+	error
+	ExitStatus() int
+}
+
 func newTCPCheckExecution(address string, port int) *tcpCheckExecution {
 	tcpAddr := fmt.Sprintf("%s:%d", address, port)
 	return &tcpCheckExecution{
@@ -124,6 +149,82 @@ func (ce *httpCheckExecution) execute(timeout time.Duration) (CheckStatus, strin
 	}
 }
 
+func newCommandCheckExecution(client sshutil.Client, command string) *commandCheckExecution {
+	return &commandCheckExecution{
+		client:  client,
+		command: command,
+	}
+}
+
+// execute runs the command on the remote host and maps its exit code to a check status
+// the same way Nagios plugins do: 0 is passing, 1 is warning and any other code is critical.
+func (ce *commandCheckExecution) execute(timeout time.Duration) (CheckStatus, string) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	out, err := sshutil.RunCommandWithContext(ctx, ce.client, ce.command)
+	if err == nil {
+		return CheckStatusPASSING, ""
+	}
+	var exitErr exitStatusError
+	if !goerr.As(err, &exitErr) {
+		log.Debugf("[WARN] check command execution failed for command:%q due to error:%v", ce.command, err)
+		return CheckStatusCRITICAL, fmt.Sprintf("[WARN] check command execution failed for command:%q due to error:%v", ce.command, err)
+	}
+	log.Debugf("[WARN] check command execution failed for command:%q with exit code:%d", ce.command, exitErr.ExitStatus())
+	mess := fmt.Sprintf("[WARN] check command execution failed for command:%q with exit code:%d", ce.command, exitErr.ExitStatus())
+	if out = strings.TrimSpace(out); out != "" {
+		mess = fmt.Sprintf("%s and output:%q", mess, out)
+	}
+	if exitErr.ExitStatus() == 1 {
+		return CheckStatusWARNING, mess
+	}
+	return CheckStatusCRITICAL, mess
+}
+
+func newGRPCCheckExecution(address string, port int, service string, useTLS bool, tlsConfig map[string]string) (*grpcCheckExecution, error) {
+	execution := &grpcCheckExecution{
+		address: fmt.Sprintf("%s:%d", address, port),
+		service: service,
+	}
+	if !useTLS {
+		execution.dialOpts = []grpc.DialOption{grpc.WithInsecure()}
+		return execution, nil
+	}
+	tlsConf, err := buildTLSClientConfig(address, tlsConfig)
+	if err != nil {
+		return nil, err
+	}
+	execution.dialOpts = []grpc.DialOption{grpc.WithTransportCredentials(credentials.NewTLS(tlsConf))}
+	return execution, nil
+}
+
+// execute calls the standard gRPC health checking service (grpc.health.v1.Health/Check)
+func (ce *grpcCheckExecution) execute(timeout time.Duration) (CheckStatus, string) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	conn, err := grpc.DialContext(ctx, ce.address, append(ce.dialOpts, grpc.WithBlock())...)
+	if err != nil {
+		log.Debugf("[WARN] check gRPC execution failed connecting to address:%q due to error:%v", ce.address, err)
+		return CheckStatusCRITICAL, fmt.Sprintf("[WARN] check gRPC execution failed for address:%q due to error:%v", ce.address, err)
+	}
+	defer conn.Close()
+
+	resp, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{Service: ce.service})
+	if err != nil {
+		if status.Code(err) == codes.Unimplemented {
+			log.Debugf("[WARN] check gRPC execution failed for address:%q: server doesn't implement the health checking service", ce.address)
+			return CheckStatusCRITICAL, fmt.Sprintf("[WARN] check gRPC execution failed for address:%q: server doesn't implement the health checking service", ce.address)
+		}
+		log.Debugf("[WARN] check gRPC execution failed for address:%q and service:%q due to error:%v", ce.address, ce.service, err)
+		return CheckStatusCRITICAL, fmt.Sprintf("[WARN] check gRPC execution failed for address:%q and service:%q due to error:%v", ce.address, ce.service, err)
+	}
+	if resp.Status == healthpb.HealthCheckResponse_SERVING {
+		return CheckStatusPASSING, ""
+	}
+	log.Debugf("[WARN] check gRPC execution failed for address:%q and service:%q with serving status:%s", ce.address, ce.service, resp.Status)
+	return CheckStatusCRITICAL, fmt.Sprintf("[WARN] check gRPC execution failed for address:%q and service:%q with serving status:%s", ce.address, ce.service, resp.Status)
+}
+
 func buildTLSClientConfig(address string, tlsConfigMap map[string]string) (*tls.Config, error) {
 	if tlsConfigMap == nil || len(tlsConfigMap) == 0 {
 		return &tls.Config{
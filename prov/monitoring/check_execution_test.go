@@ -0,0 +1,201 @@
+// Copyright 2021 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package monitoring
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/binary"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/ystia/yorc/v4/helper/sshutil"
+)
+
+// newStandInSSHServer starts an SSH server running commands by calling the given handler.
+// It returns the server listener which should be closed by the caller.
+func newStandInSSHServer(t *testing.T, handler func(cmd string) (string, uint32)) net.Listener {
+	conf := &ssh.ServerConfig{
+		PasswordCallback: func(c ssh.ConnMetadata, pass []byte) (*ssh.Permissions, error) {
+			return nil, nil
+		},
+	}
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	signer, err := ssh.NewSignerFromKey(private)
+	require.NoError(t, err)
+	conf.AddHostKey(signer)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() {
+		for {
+			nConn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				conn, chans, reqs, err := ssh.NewServerConn(nConn, conf)
+				if err != nil {
+					return
+				}
+				defer conn.Close()
+				go ssh.DiscardRequests(reqs)
+				for newChannel := range chans {
+					if newChannel.ChannelType() != "session" {
+						newChannel.Reject(ssh.UnknownChannelType, "unknown channel type")
+						continue
+					}
+					channel, requests, err := newChannel.Accept()
+					if err != nil {
+						return
+					}
+					go func(channel ssh.Channel, requests <-chan *ssh.Request) {
+						for req := range requests {
+							if req.Type != "exec" {
+								req.Reply(false, nil)
+								continue
+							}
+							req.Reply(true, nil)
+							payload := struct{ Command string }{}
+							ssh.Unmarshal(req.Payload, &payload)
+							out, status := handler(payload.Command)
+							channel.Write([]byte(out))
+							b := make([]byte, 4)
+							binary.BigEndian.PutUint32(b, status)
+							channel.SendRequest("exit-status", false, b)
+							channel.Close()
+						}
+					}(channel, requests)
+				}
+			}()
+		}
+	}()
+	return listener
+}
+
+func TestCommandCheckExecution(t *testing.T) {
+	listener := newStandInSSHServer(t, func(cmd string) (string, uint32) {
+		switch cmd {
+		case "check ok":
+			return "all good", 0
+		case "check degraded":
+			return "disk almost full", 1
+		case "check slow":
+			time.Sleep(2 * time.Second)
+			return "", 0
+		default:
+			return "service is down", 2
+		}
+	})
+	defer listener.Close()
+	client := &sshutil.SSHClient{
+		Config: &ssh.ClientConfig{
+			User:            "test",
+			Auth:            []ssh.AuthMethod{ssh.Password("test")},
+			HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+			Timeout:         time.Second,
+		},
+		Host: "127.0.0.1",
+		Port: listener.Addr().(*net.TCPAddr).Port,
+	}
+
+	tests := []struct {
+		name           string
+		command        string
+		wantStatus     CheckStatus
+		wantMsgContain string
+	}{
+		{"Passing", "check ok", CheckStatusPASSING, ""},
+		{"Warning", "check degraded", CheckStatusWARNING, "disk almost full"},
+		{"Critical", "check down", CheckStatusCRITICAL, "exit code:2"},
+		{"Timeout", "check slow", CheckStatusCRITICAL, "interrupted"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, msg := newCommandCheckExecution(client, tt.command).execute(500 * time.Millisecond)
+			require.Equal(t, tt.wantStatus, status, "unexpected status with message %q", msg)
+			if tt.wantMsgContain == "" {
+				require.Empty(t, msg)
+			} else {
+				require.True(t, strings.Contains(msg, tt.wantMsgContain), "message %q doesn't contain %q", msg, tt.wantMsgContain)
+			}
+		})
+	}
+}
+
+func TestGRPCCheckExecution(t *testing.T) {
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+	healthServer.SetServingStatus("serving", healthpb.HealthCheckResponse_SERVING)
+	healthServer.SetServingStatus("stopped", healthpb.HealthCheckResponse_NOT_SERVING)
+	server := grpc.NewServer()
+	healthpb.RegisterHealthServer(server, healthServer)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go server.Serve(listener)
+	defer server.Stop()
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	// A gRPC server without the health checking service
+	bareServer := grpc.NewServer()
+	bareListener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go bareServer.Serve(bareListener)
+	defer bareServer.Stop()
+	barePort := bareListener.Addr().(*net.TCPAddr).Port
+
+	// A closed port
+	closedListener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	closedPort := closedListener.Addr().(*net.TCPAddr).Port
+	closedListener.Close()
+
+	tests := []struct {
+		name           string
+		port           int
+		service        string
+		wantStatus     CheckStatus
+		wantMsgContain string
+	}{
+		{"ServerServing", port, "", CheckStatusPASSING, ""},
+		{"ServiceServing", port, "serving", CheckStatusPASSING, ""},
+		{"ServiceNotServing", port, "stopped", CheckStatusCRITICAL, "NOT_SERVING"},
+		{"UnknownService", port, "unknown", CheckStatusCRITICAL, "unknown"},
+		{"HealthNotImplemented", barePort, "", CheckStatusCRITICAL, "doesn't implement the health checking service"},
+		{"ServerDown", closedPort, "", CheckStatusCRITICAL, "check gRPC execution failed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			execution, err := newGRPCCheckExecution("127.0.0.1", tt.port, tt.service, false, nil)
+			require.NoError(t, err)
+			status, msg := execution.execute(500 * time.Millisecond)
+			require.Equal(t, tt.wantStatus, status, "unexpected status with message %q", msg)
+			if tt.wantMsgContain == "" {
+				require.Empty(t, msg)
+			} else {
+				require.True(t, strings.Contains(msg, tt.wantMsgContain), "message %q doesn't contain %q", msg, tt.wantMsgContain)
+			}
+		})
+	}
+}
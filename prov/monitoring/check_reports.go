@@ -0,0 +1,209 @@
+// Copyright 2021 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package monitoring
+
+import (
+	"context"
+	"path"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/pkg/errors"
+
+	"github.com/ystia/yorc/v4/events"
+	"github.com/ystia/yorc/v4/helper/consulutil"
+	"github.com/ystia/yorc/v4/log"
+)
+
+// ListCheckReports returns the reports of registered monitoring checks sorted by deployment, node and instance.
+//
+// Only reports matching the given filter function are returned, if not nil.
+func ListCheckReports(f CheckFilterFunc) ([]CheckReport, error) {
+	reports, err := listCheckReports(consulutil.GetKV(), f)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(reports, func(i, j int) bool {
+		return buildID(reports[i].DeploymentID, reports[i].NodeName, reports[i].Instance) < buildID(reports[j].DeploymentID, reports[j].NodeName, reports[j].Instance)
+	})
+	return reports, nil
+}
+
+// GetCheckReport returns the report of the monitoring check of a given node instance or nil if there is no such check
+func GetCheckReport(deploymentID, nodeName, instance string) (*CheckReport, error) {
+	check := NewCheck(deploymentID, nodeName, instance)
+	kv := consulutil.GetKV()
+	kvp, _, err := kv.Get(path.Join(consulutil.MonitoringKVPrefix, "reports", check.ID, "status"), nil)
+	if err != nil {
+		return nil, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+	}
+	if kvp == nil || len(kvp.Value) == 0 {
+		return nil, nil
+	}
+	err = readCheckReport(kv, path.Join(consulutil.MonitoringKVPrefix, "reports", check.ID), &check.Report)
+	if err != nil {
+		return nil, err
+	}
+	return &check.Report, nil
+}
+
+// DisableCheck temporarily disables the monitoring check of a given node instance, during a maintenance for instance.
+//
+// If until is not nil, the check is automatically enabled again after this date.
+func DisableCheck(ctx context.Context, deploymentID, nodeName, instance string, until *time.Time) error {
+	reportPath := path.Join(consulutil.MonitoringKVPrefix, "reports", buildID(deploymentID, nodeName, instance))
+	ops := api.KVTxnOps{
+		&api.KVTxnOp{
+			Verb:  api.KVSet,
+			Key:   path.Join(reportPath, "disabled"),
+			Value: []byte("true"),
+		},
+		&api.KVTxnOp{
+			Verb: api.KVDelete,
+			Key:  path.Join(reportPath, "disabled_until"),
+		},
+	}
+	if until != nil {
+		ops[1] = &api.KVTxnOp{
+			Verb:  api.KVSet,
+			Key:   path.Join(reportPath, "disabled_until"),
+			Value: []byte(until.Format(time.RFC3339Nano)),
+		}
+	}
+	err := executeTxn(ops)
+	if err != nil {
+		return errors.Wrapf(err, "failed to disable monitoring check of node %q instance %q", nodeName, instance)
+	}
+	mess := "Monitoring check disabled for node (%s-%s)"
+	args := []interface{}{nodeName, instance}
+	if until != nil {
+		mess += " until %s"
+		args = append(args, until.Format(time.RFC3339))
+	}
+	events.WithContextOptionalFields(ctx).NewLogEntry(events.LogLevelINFO, deploymentID).Registerf(mess, args...)
+	return nil
+}
+
+// EnableCheck enables again the monitoring check of a given node instance
+func EnableCheck(ctx context.Context, deploymentID, nodeName, instance string) error {
+	err := enableCheck(buildID(deploymentID, nodeName, instance))
+	if err != nil {
+		return errors.Wrapf(err, "failed to enable monitoring check of node %q instance %q", nodeName, instance)
+	}
+	events.WithContextOptionalFields(ctx).NewLogEntry(events.LogLevelINFO, deploymentID).Registerf("Monitoring check enabled for node (%s-%s)", nodeName, instance)
+	return nil
+}
+
+func enableCheck(id string) error {
+	reportPath := path.Join(consulutil.MonitoringKVPrefix, "reports", id)
+	return executeTxn(api.KVTxnOps{
+		&api.KVTxnOp{
+			Verb: api.KVDelete,
+			Key:  path.Join(reportPath, "disabled"),
+		},
+		&api.KVTxnOp{
+			Verb: api.KVDelete,
+			Key:  path.Join(reportPath, "disabled_until"),
+		},
+	})
+}
+
+// isDisabled checks if the check has been disabled, a check disabled until a past date is enabled again
+func (c *Check) isDisabled() bool {
+	var report CheckReport
+	err := readCheckReport(consulutil.GetKV(), path.Join(consulutil.MonitoringKVPrefix, "reports", c.ID), &report)
+	if err != nil {
+		log.Printf("[WARN] Failed to check if check with id:%q is disabled due to error:%+v", c.ID, err)
+		return false
+	}
+	if !report.Disabled {
+		return false
+	}
+	if report.DisabledUntil == nil || time.Now().Before(*report.DisabledUntil) {
+		return true
+	}
+	if err = enableCheck(c.ID); err != nil {
+		log.Printf("[WARN] Failed to enable check with id:%q due to error:%+v", c.ID, err)
+		return true
+	}
+	events.WithContextOptionalFields(c.ctx).NewLogEntry(events.LogLevelINFO, c.Report.DeploymentID).Registerf("Monitoring check enabled again for node (%s-%s) at the end of its disabling period", c.Report.NodeName, c.Report.Instance)
+	return false
+}
+
+func listCheckReports(kv *api.KV, f CheckFilterFunc) ([]CheckReport, error) {
+	log.Debugf("List check reports")
+	keys, _, err := kv.Keys(path.Join(consulutil.MonitoringKVPrefix, "reports")+"/", "/", nil)
+	if err != nil {
+		return nil, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+	}
+	checkReports := make([]CheckReport, 0)
+	for _, key := range keys {
+		id := path.Base(key)
+		check, err := NewCheckFromID(id)
+		if err != nil {
+			return nil, err
+		}
+		err = readCheckReport(kv, key, &check.Report)
+		if err != nil {
+			return nil, err
+		}
+		checkReports = append(checkReports, check.Report)
+	}
+	return filter(checkReports, f), nil
+}
+
+// readCheckReport reads the status and the disabling state of a check report stored under the given path
+func readCheckReport(kv *api.KV, reportPath string, report *CheckReport) error {
+	kvps, _, err := kv.List(reportPath+"/", nil)
+	if err != nil {
+		return errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+	}
+	for _, kvp := range kvps {
+		if len(kvp.Value) == 0 {
+			continue
+		}
+		switch path.Base(kvp.Key) {
+		case "status":
+			report.Status, err = ParseCheckStatus(string(kvp.Value))
+		case "disabled":
+			report.Disabled, err = strconv.ParseBool(string(kvp.Value))
+		case "disabled_until":
+			var until time.Time
+			until, err = time.Parse(time.RFC3339Nano, string(kvp.Value))
+			report.DisabledUntil = &until
+		}
+		if err != nil {
+			return errors.Wrapf(err, "invalid check report value for key %q", kvp.Key)
+		}
+	}
+	return nil
+}
+
+func executeTxn(ops api.KVTxnOps) error {
+	ok, response, _, err := consulutil.GetKV().Txn(ops, nil)
+	if err != nil {
+		return errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+	}
+	if !ok {
+		errs := make([]string, 0)
+		for _, e := range response.Errors {
+			errs = append(errs, e.What)
+		}
+		return errors.Errorf("transaction failed due to:%v", errs)
+	}
+	return nil
+}
@@ -16,6 +16,7 @@ package monitoring
 
 import (
 	"context"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -24,6 +25,9 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 
 	"github.com/ystia/yorc/v4/helper/consulutil"
 	"github.com/ystia/yorc/v4/storage"
@@ -100,6 +104,65 @@ func TestRunConsulMonitoringPackageTests(t *testing.T) {
 	err = storage.GetStore(types.StoreTypeDeployment).Set(ctx, consulutil.DeploymentKVPrefix+"/monitoring3/topology/policies/HTTPMonitoring", policy3)
 	require.Nil(t, err)
 
+	// gRPC server exposing the health checking service
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus("myservice", healthpb.HealthCheckResponse_SERVING)
+	grpcServer := grpc.NewServer()
+	healthpb.RegisterHealthServer(grpcServer, healthServer)
+	grpcListener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go grpcServer.Serve(grpcListener)
+	defer grpcServer.Stop()
+
+	policy4 := tosca.Policy{
+		Type:    "yorc.policies.monitoring.GRPCMonitoring",
+		Targets: []string{"App"},
+		Properties: map[string]*tosca.ValueAssignment{
+			"port":          &tosca.ValueAssignment{Type: 0, Value: grpcListener.Addr().(*net.TCPAddr).Port},
+			"service":       &tosca.ValueAssignment{Type: 0, Value: "myservice"},
+			"time_interval": &tosca.ValueAssignment{Type: 0, Value: "1s"},
+		},
+	}
+	err = storage.GetStore(types.StoreTypeDeployment).Set(ctx, consulutil.DeploymentKVPrefix+"/monitoring6/topology/policies/GRPCMonitoring", policy4)
+	require.Nil(t, err)
+	err = storage.GetStore(types.StoreTypeDeployment).Set(ctx, consulutil.DeploymentKVPrefix+"/monitoring6/topology/nodes/App", tosca.NodeTemplate{Type: "tosca.nodes.SoftwareComponent"})
+	require.Nil(t, err)
+
+	healingPolicies := map[string]tosca.Policy{
+		"HealApp": {
+			Type:    "yorc.policies.monitoring.Healing",
+			Targets: []string{"App"},
+			Properties: map[string]*tosca.ValueAssignment{
+				"failure_threshold": &tosca.ValueAssignment{Type: 0, Value: 2},
+				"max_attempts":      &tosca.ValueAssignment{Type: 0, Value: 4},
+				"success_threshold": &tosca.ValueAssignment{Type: 0, Value: 5},
+				"min_interval":      &tosca.ValueAssignment{Type: 0, Value: "10m"},
+				"operation":         &tosca.ValueAssignment{Type: 0, Value: "tosca.interfaces.node.lifecycle.Standard.start"},
+			},
+		},
+		"HealCompute": {
+			Type:    "yorc.policies.monitoring.Healing",
+			Targets: []string{"Compute"},
+			Properties: map[string]*tosca.ValueAssignment{
+				"workflow": &tosca.ValueAssignment{Type: 0, Value: "restart"},
+			},
+		},
+		"HealInvalid": {
+			Type:    "yorc.policies.monitoring.Healing",
+			Targets: []string{"Invalid"},
+			Properties: map[string]*tosca.ValueAssignment{
+				"workflow":  &tosca.ValueAssignment{Type: 0, Value: "restart"},
+				"operation": &tosca.ValueAssignment{Type: 0, Value: "restart"},
+			},
+		},
+	}
+	for name, policy := range healingPolicies {
+		err = storage.GetStore(types.StoreTypeDeployment).Set(ctx, consulutil.DeploymentKVPrefix+"/monitoring8/topology/policies/"+name, policy)
+		require.Nil(t, err)
+		err = storage.GetStore(types.StoreTypeDeployment).Set(ctx, consulutil.DeploymentKVPrefix+"/monitoring8/topology/nodes/"+policy.Targets[0], tosca.NodeTemplate{Type: "tosca.nodes.SoftwareComponent"})
+		require.Nil(t, err)
+	}
+
 	nodeCompute := tosca.NodeTemplate{
 		Type: "yorc.nodes.openstack.Compute",
 	}
@@ -121,6 +184,8 @@ func TestRunConsulMonitoringPackageTests(t *testing.T) {
 		consulutil.DeploymentKVPrefix + "/monitoring1/topology/instances/Compute2/0/attributes/state":      []byte("started"),
 		consulutil.DeploymentKVPrefix + "/monitoring5/topology/instances/Compute1/0/attributes/ip_address": []byte("1.2.3.4"),
 		consulutil.DeploymentKVPrefix + "/monitoring5/topology/instances/Compute1/0/attributes/state":      []byte("started"),
+		consulutil.DeploymentKVPrefix + "/monitoring6/topology/instances/App/0/attributes/ip_address":      []byte("127.0.0.1"),
+		consulutil.DeploymentKVPrefix + "/monitoring6/topology/instances/App/0/attributes/state":           []byte("started"),
 	})
 
 	t.Run("groupMonitoring", func(t *testing.T) {
@@ -136,5 +201,14 @@ func TestRunConsulMonitoringPackageTests(t *testing.T) {
 		t.Run("testAddAndRemoveCheck", func(t *testing.T) {
 			testAddAndRemoveCheck(t, client)
 		})
+		t.Run("testGRPCMonitoringHook", func(t *testing.T) {
+			testGRPCMonitoringHook(t, client, cfg)
+		})
+		t.Run("testCommandCheck", func(t *testing.T) {
+			testCommandCheck(t, client)
+		})
+		t.Run("testLoadHealingPolicy", func(t *testing.T) {
+			testLoadHealingPolicy(t, client)
+		})
 	})
 }
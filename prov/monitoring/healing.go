@@ -0,0 +1,311 @@
+// Copyright 2021 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package monitoring
+
+import (
+	"context"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/armon/go-metrics"
+	"github.com/pkg/errors"
+
+	"github.com/ystia/yorc/v4/deployments"
+	"github.com/ystia/yorc/v4/events"
+	"github.com/ystia/yorc/v4/helper/consulutil"
+	"github.com/ystia/yorc/v4/helper/metricsutil"
+	"github.com/ystia/yorc/v4/log"
+	"github.com/ystia/yorc/v4/tasks"
+	"github.com/ystia/yorc/v4/tasks/collector"
+)
+
+const healingPolicyType = "yorc.policies.monitoring.Healing"
+
+// healingPolicy defines how a node instance is healed when its monitoring check fails
+type healingPolicy struct {
+	name string
+	// failureThreshold is the number of consecutive critical check executions triggering a healing decision
+	failureThreshold int
+	// workflow is the custom workflow to launch, exclusive with operation
+	workflow string
+	// interfaceName and operation are the operation to run on the instance, exclusive with workflow
+	interfaceName string
+	operation     string
+	// maxAttempts is the maximum number of healing attempts until the check is passing again
+	maxAttempts int
+	// successThreshold is the number of consecutive passing check executions resetting healing attempts
+	successThreshold int
+	// minInterval is the minimum delay between two healing attempts
+	minInterval time.Duration
+}
+
+type healingAction int
+
+const (
+	healingActionHeal healingAction = iota
+	healingActionDefer
+	healingActionGiveUp
+	healingActionNone
+)
+
+// decideHealing returns the action to take according to the previous healing attempts on an instance
+func decideHealing(h *healingPolicy, attempts int, lastAttempt, now time.Time) healingAction {
+	switch {
+	case attempts > h.maxAttempts:
+		// Already gave up
+		return healingActionNone
+	case attempts == h.maxAttempts:
+		return healingActionGiveUp
+	case !lastAttempt.IsZero() && now.Sub(lastAttempt) < h.minInterval:
+		return healingActionDefer
+	}
+	return healingActionHeal
+}
+
+// loadHealingPolicy returns the healing policy applying to a given node or nil if there is none
+func loadHealingPolicy(ctx context.Context, deploymentID, nodeName string) (*healingPolicy, error) {
+	policies, err := deployments.GetPoliciesForTypeAndNode(ctx, deploymentID, healingPolicyType, nodeName)
+	if err != nil {
+		return nil, err
+	}
+	if len(policies) == 0 {
+		return nil, nil
+	}
+	if len(policies) > 1 {
+		return nil, errors.Errorf("Found more than one healing policy to apply to node name:%q. No healing policy will be applied", nodeName)
+	}
+	h := &healingPolicy{name: policies[0]}
+
+	h.failureThreshold, err = getIntPolicyProperty(ctx, deploymentID, h.name, "failure_threshold", 3)
+	if err != nil {
+		return nil, err
+	}
+	h.maxAttempts, err = getIntPolicyProperty(ctx, deploymentID, h.name, "max_attempts", 3)
+	if err != nil {
+		return nil, err
+	}
+	h.successThreshold, err = getIntPolicyProperty(ctx, deploymentID, h.name, "success_threshold", 3)
+	if err != nil {
+		return nil, err
+	}
+	if h.failureThreshold < 1 || h.maxAttempts < 1 || h.successThreshold < 1 {
+		return nil, errors.Errorf("failure_threshold, max_attempts and success_threshold of healing policy:%q should be greater than 0", h.name)
+	}
+
+	h.minInterval = 5 * time.Minute
+	v, err := deployments.GetPolicyPropertyValue(ctx, deploymentID, h.name, "min_interval")
+	if err != nil {
+		return nil, err
+	}
+	if v != nil && v.RawString() != "" {
+		h.minInterval, err = time.ParseDuration(v.RawString())
+		if err != nil {
+			return nil, errors.Wrapf(err, "Failed to retrieve min_interval as correct duration for healing policy:%q", h.name)
+		}
+	}
+
+	v, err = deployments.GetPolicyPropertyValue(ctx, deploymentID, h.name, "workflow")
+	if err != nil {
+		return nil, err
+	}
+	if v != nil {
+		h.workflow = v.RawString()
+	}
+	v, err = deployments.GetPolicyPropertyValue(ctx, deploymentID, h.name, "operation")
+	if err != nil {
+		return nil, err
+	}
+	if v != nil && v.RawString() != "" {
+		// Operations are given as <interface>.<operation>, the custom interface is used by default
+		op := strings.ToLower(v.RawString())
+		h.interfaceName = "custom"
+		h.operation = op
+		if i := strings.LastIndex(op, "."); i >= 0 {
+			h.interfaceName = op[:i]
+			h.operation = op[i+1:]
+		}
+	}
+	if (h.workflow == "") == (h.operation == "") {
+		return nil, errors.Errorf("Exactly one of workflow or operation should be defined for healing policy:%q", h.name)
+	}
+	return h, nil
+}
+
+func getIntPolicyProperty(ctx context.Context, deploymentID, policyName, propertyName string, defaultValue int) (int, error) {
+	v, err := deployments.GetPolicyPropertyValue(ctx, deploymentID, policyName, propertyName)
+	if err != nil {
+		return 0, err
+	}
+	if v == nil || v.RawString() == "" {
+		return defaultValue, nil
+	}
+	i, err := strconv.Atoi(v.RawString())
+	if err != nil {
+		return 0, errors.Wrapf(err, "Failed to retrieve %s as correct integer for healing policy:%q", propertyName, policyName)
+	}
+	return i, nil
+}
+
+// countExecution updates the consecutive failures and successes counters of a check and returns
+// whether the instance should be healed or its healing attempts reset.
+//
+// Attempts are reset only once the check passed successThreshold consecutive times, so a flapping check
+// doesn't reset them and max_attempts still applies.
+func (c *Check) countExecution(status CheckStatus) (heal, reset bool) {
+	if status != CheckStatusCRITICAL {
+		c.consecutiveFailures = 0
+		if status != CheckStatusPASSING {
+			c.consecutiveSuccesses = 0
+			return false, false
+		}
+		c.consecutiveSuccesses++
+		return false, c.healing != nil && c.consecutiveSuccesses == c.healing.successThreshold
+	}
+	c.consecutiveSuccesses = 0
+	c.consecutiveFailures++
+	if c.healing == nil || c.consecutiveFailures < c.healing.failureThreshold {
+		return false, false
+	}
+	// A decision is taken each time the threshold is reached
+	c.consecutiveFailures = 0
+	return true, false
+}
+
+// recordExecution updates the consecutive failures counter of a check and heals the instance when
+// the failure threshold of its healing policy is reached
+func (c *Check) recordExecution(status CheckStatus) {
+	heal, reset := c.countExecution(status)
+	if reset {
+		c.resetHealing()
+	}
+	if !heal {
+		return
+	}
+	if err := c.heal(); err != nil {
+		log.Printf("[WARN] Failed to heal node instance for check ID:%q due to error:%+v", c.ID, err)
+	}
+}
+
+func (c *Check) heal() error {
+	h := c.healing
+	healingPath := path.Join(consulutil.MonitoringKVPrefix, "reports", c.ID, "healing")
+	attempts, lastAttempt, err := c.getHealingState(healingPath)
+	if err != nil {
+		return err
+	}
+
+	logger := events.WithContextOptionalFields(c.ctx)
+	switch decideHealing(h, attempts, lastAttempt, time.Now()) {
+	case healingActionNone:
+		return nil
+	case healingActionGiveUp:
+		logger.NewLogEntry(events.LogLevelERROR, c.Report.DeploymentID).Registerf(
+			"Healing policy %q: maximum number of healing attempts (%d) reached for node (%s-%s), giving up until its monitoring check is back to normal",
+			h.name, h.maxAttempts, c.Report.NodeName, c.Report.Instance)
+		// Do not notify it again
+		return consulutil.StoreConsulKeyAsString(path.Join(healingPath, "attempts"), strconv.Itoa(attempts+1))
+	case healingActionDefer:
+		logger.NewLogEntry(events.LogLevelWARN, c.Report.DeploymentID).Registerf(
+			"Healing policy %q: healing of node (%s-%s) deferred as the last attempt occurred less than %s ago",
+			h.name, c.Report.NodeName, c.Report.Instance, h.minInterval)
+		return nil
+	}
+
+	taskID, err := c.registerHealingTask()
+	if err != nil {
+		logger.NewLogEntry(events.LogLevelWARN, c.Report.DeploymentID).Registerf(
+			"Healing policy %q: healing of node (%s-%s) skipped: %v", h.name, c.Report.NodeName, c.Report.Instance, err)
+		return nil
+	}
+	action := "workflow " + h.workflow
+	if h.operation != "" {
+		action = "operation " + h.interfaceName + "." + h.operation
+	}
+	logger.NewLogEntry(events.LogLevelINFO, c.Report.DeploymentID).Registerf(
+		"Healing policy %q: %s launched with task ID %q to heal node (%s-%s) after %d consecutive monitoring failures (attempt %d/%d)",
+		h.name, action, taskID, c.Report.NodeName, c.Report.Instance, h.failureThreshold, attempts+1, h.maxAttempts)
+	metrics.IncrCounterWithLabels(metricsutil.CleanupMetricKey([]string{"monitoring", "healing", "attempts"}), 1, []metrics.Label{
+		metrics.Label{Name: "Deployment", Value: c.Report.DeploymentID},
+		metrics.Label{Name: "Node", Value: c.Report.NodeName},
+	})
+
+	err = consulutil.StoreConsulKeyAsString(path.Join(healingPath, "attempts"), strconv.Itoa(attempts+1))
+	if err != nil {
+		return err
+	}
+	return consulutil.StoreConsulKeyAsString(path.Join(healingPath, "last_attempt"), time.Now().Format(time.RFC3339Nano))
+}
+
+func (c *Check) registerHealingTask() (string, error) {
+	status, err := deployments.GetDeploymentStatus(c.ctx, c.Report.DeploymentID)
+	if err != nil {
+		return "", err
+	}
+	if status != deployments.DEPLOYED && status != deployments.UPDATED {
+		return "", errors.Errorf("deployment status is %q", status.String())
+	}
+
+	h := c.healing
+	data := map[string]string{
+		path.Join("nodes", c.Report.NodeName): c.Report.Instance,
+	}
+	if h.workflow != "" {
+		data["workflowName"] = h.workflow
+		data["continueOnError"] = strconv.FormatBool(false)
+		return collector.NewCollector(defaultMonManager.cc).RegisterTaskWithData(c.Report.DeploymentID, tasks.TaskTypeCustomWorkflow, data)
+	}
+	data["interfaceName"] = h.interfaceName
+	data["commandName"] = h.operation
+	return collector.NewCollector(defaultMonManager.cc).RegisterTaskWithData(c.Report.DeploymentID, tasks.TaskTypeCustomCommand, data)
+}
+
+func (c *Check) getHealingState(healingPath string) (int, time.Time, error) {
+	var attempts int
+	var lastAttempt time.Time
+	kvp, _, err := defaultMonManager.cc.KV().Get(path.Join(healingPath, "attempts"), nil)
+	if err != nil {
+		return 0, lastAttempt, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+	}
+	if kvp != nil && len(kvp.Value) > 0 {
+		attempts, err = strconv.Atoi(string(kvp.Value))
+		if err != nil {
+			return 0, lastAttempt, errors.Wrapf(err, "invalid healing attempts for check ID:%q", c.ID)
+		}
+	}
+	kvp, _, err = defaultMonManager.cc.KV().Get(path.Join(healingPath, "last_attempt"), nil)
+	if err != nil {
+		return 0, lastAttempt, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+	}
+	if kvp != nil && len(kvp.Value) > 0 {
+		lastAttempt, err = time.Parse(time.RFC3339Nano, string(kvp.Value))
+		if err != nil {
+			return 0, lastAttempt, errors.Wrapf(err, "invalid last healing attempt for check ID:%q", c.ID)
+		}
+	}
+	return attempts, lastAttempt, nil
+}
+
+// resetHealing resets healing attempts once the check is passing again for a sustained period
+func (c *Check) resetHealing() {
+	if c.healing == nil {
+		return
+	}
+	_, err := defaultMonManager.cc.KV().DeleteTree(path.Join(consulutil.MonitoringKVPrefix, "reports", c.ID, "healing")+"/", nil)
+	if err != nil {
+		log.Printf("[WARN] Failed to reset healing attempts for check ID:%q due to error:%+v", c.ID, err)
+	}
+}
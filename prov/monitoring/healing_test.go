@@ -0,0 +1,104 @@
+// Copyright 2021 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package monitoring
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecideHealing(t *testing.T) {
+	now := time.Now()
+	h := &healingPolicy{failureThreshold: 3, maxAttempts: 2, minInterval: 5 * time.Minute}
+	tests := []struct {
+		name        string
+		attempts    int
+		lastAttempt time.Time
+		want        healingAction
+	}{
+		{"FirstAttempt", 0, time.Time{}, healingActionHeal},
+		{"AttemptAfterMinInterval", 1, now.Add(-6 * time.Minute), healingActionHeal},
+		{"AttemptRateLimited", 1, now.Add(-1 * time.Minute), healingActionDefer},
+		{"MaxAttemptsReached", 2, now.Add(-6 * time.Minute), healingActionGiveUp},
+		{"MaxAttemptsReachedRateLimited", 2, now.Add(-1 * time.Minute), healingActionGiveUp},
+		{"AlreadyGaveUp", 3, now.Add(-6 * time.Minute), healingActionNone},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, decideHealing(h, tt.attempts, tt.lastAttempt, now))
+		})
+	}
+}
+
+func TestCheckCountExecution(t *testing.T) {
+	c := &Check{healing: &healingPolicy{failureThreshold: 2, successThreshold: 3}}
+	count := func(status CheckStatus, wantHeal, wantReset bool) {
+		t.Helper()
+		heal, reset := c.countExecution(status)
+		require.Equal(t, wantHeal, heal, "unexpected heal decision")
+		require.Equal(t, wantReset, reset, "unexpected reset decision")
+	}
+
+	count(CheckStatusCRITICAL, false, false)
+	count(CheckStatusCRITICAL, true, false)
+	// A flapping check doesn't reset healing attempts
+	count(CheckStatusPASSING, false, false)
+	count(CheckStatusCRITICAL, false, false)
+	count(CheckStatusPASSING, false, false)
+	count(CheckStatusPASSING, false, false)
+	count(CheckStatusWARNING, false, false)
+	count(CheckStatusPASSING, false, false)
+	count(CheckStatusPASSING, false, false)
+	// Attempts are reset once after a sustained passing window
+	count(CheckStatusPASSING, false, true)
+	count(CheckStatusPASSING, false, false)
+
+	c = &Check{}
+	count(CheckStatusCRITICAL, false, false)
+	count(CheckStatusPASSING, false, false)
+}
+
+func testLoadHealingPolicy(t *testing.T, client *api.Client) {
+	ctx := context.Background()
+
+	h, err := loadHealingPolicy(ctx, "monitoring1", "Compute1")
+	require.NoError(t, err)
+	require.Nil(t, h, "no healing policy expected")
+
+	h, err = loadHealingPolicy(ctx, "monitoring8", "App")
+	require.NoError(t, err)
+	require.NotNil(t, h)
+	require.Equal(t, "HealApp", h.name)
+	require.Equal(t, 2, h.failureThreshold)
+	require.Equal(t, 4, h.maxAttempts)
+	require.Equal(t, 5, h.successThreshold)
+	require.Equal(t, 10*time.Minute, h.minInterval)
+	require.Equal(t, "", h.workflow)
+	require.Equal(t, "tosca.interfaces.node.lifecycle.standard", h.interfaceName)
+	require.Equal(t, "start", h.operation)
+
+	h, err = loadHealingPolicy(ctx, "monitoring8", "Compute")
+	require.NoError(t, err)
+	require.NotNil(t, h)
+	require.Equal(t, "restart", h.workflow)
+	require.Equal(t, "", h.operation)
+
+	_, err = loadHealingPolicy(ctx, "monitoring8", "Invalid")
+	require.Error(t, err, "workflow and operation are exclusive")
+}
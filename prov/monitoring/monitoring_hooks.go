@@ -16,15 +16,18 @@ package monitoring
 
 import (
 	"context"
+	"github.com/mitchellh/mapstructure"
 	"github.com/pkg/errors"
 	"github.com/ystia/yorc/v4/config"
 	"github.com/ystia/yorc/v4/deployments"
 	"github.com/ystia/yorc/v4/events"
+	"github.com/ystia/yorc/v4/helper/sshutil"
 	"github.com/ystia/yorc/v4/log"
 	"github.com/ystia/yorc/v4/tasks"
 	"github.com/ystia/yorc/v4/tasks/workflow"
 	"github.com/ystia/yorc/v4/tasks/workflow/builder"
 	"github.com/ystia/yorc/v4/tosca"
+	"github.com/ystia/yorc/v4/tosca/types"
 	"strconv"
 	"strings"
 	"time"
@@ -36,9 +39,11 @@ func init() {
 }
 
 const (
-	httpMonitoring = "yorc.policies.monitoring.HTTPMonitoring"
-	tcpMonitoring  = "yorc.policies.monitoring.TCPMonitoring"
-	baseMonitoring = "yorc.policies.Monitoring"
+	httpMonitoring    = "yorc.policies.monitoring.HTTPMonitoring"
+	tcpMonitoring     = "yorc.policies.monitoring.TCPMonitoring"
+	commandMonitoring = "yorc.policies.monitoring.CommandMonitoring"
+	grpcMonitoring    = "yorc.policies.monitoring.GRPCMonitoring"
+	baseMonitoring    = "yorc.policies.Monitoring"
 )
 
 func addMonitoringHook(ctx context.Context, cfg config.Configuration, taskID, deploymentID, target string, activity builder.Activity) {
@@ -136,6 +141,16 @@ func addMonitoringPolicyForTarget(ctx context.Context, taskID, deploymentID, tar
 	if err != nil {
 		return errors.Errorf("Failed to retrieve time_interval as correct duration for monitoring policy:%q due to: %v", policyName, err)
 	}
+	instances, err := tasks.GetInstances(ctx, taskID, deploymentID, target)
+	if err != nil {
+		return err
+	}
+
+	// Command checks use the SSH port of the node instance
+	if policyType == commandMonitoring {
+		return applyCommandMonitoringPolicy(ctx, policyName, deploymentID, target, timeInterval, instances)
+	}
+
 	portValue, err := deployments.GetPolicyPropertyValue(ctx, deploymentID, policyName, "port")
 	if err != nil || portValue == nil || portValue.RawString() == "" {
 		return errors.Errorf("Failed to retrieve port for monitoring policy:%q due to: %v", policyName, err)
@@ -144,16 +159,14 @@ func addMonitoringPolicyForTarget(ctx context.Context, taskID, deploymentID, tar
 	if err != nil {
 		return errors.Errorf("Failed to retrieve port as correct integer for monitoring policy:%q due to: %v", policyName, err)
 	}
-	instances, err := tasks.GetInstances(ctx, taskID, deploymentID, target)
-	if err != nil {
-		return err
-	}
 
 	switch policyType {
 	case httpMonitoring:
 		return applyHTTPMonitoringPolicy(ctx, policyName, deploymentID, target, timeInterval, port, instances)
 	case tcpMonitoring:
 		return applyTCPMonitoringPolicy(ctx, deploymentID, target, timeInterval, port, instances)
+	case grpcMonitoring:
+		return applyGRPCMonitoringPolicy(ctx, policyName, deploymentID, target, timeInterval, port, instances)
 	default:
 		return errors.Errorf("Unsupported policy type:%q for policy:%q", policyType, policyName)
 	}
@@ -222,6 +235,138 @@ func applyHTTPMonitoringPolicy(ctx context.Context, policyName, deploymentID, ta
 	return nil
 }
 
+func applyCommandMonitoringPolicy(ctx context.Context, policyName, deploymentID, target string, timeInterval time.Duration, instances []string) error {
+	commandValue, err := deployments.GetPolicyPropertyValue(ctx, deploymentID, policyName, "command")
+	if err != nil || commandValue == nil || commandValue.RawString() == "" {
+		return errors.Errorf("Failed to retrieve command for monitoring policy:%q due to: %v", policyName, err)
+	}
+	for _, instance := range instances {
+		// The command is run on the compute hosting the node instance
+		host, hostInstance, err := retrieveComputeInstance(ctx, deploymentID, target, instance)
+		if err != nil {
+			return err
+		}
+		ipAddress, err := retrieveIPAddress(ctx, deploymentID, host, hostInstance)
+		if err != nil {
+			return err
+		}
+		// Credentials are resolved here to fail early but only the port is stored with the check
+		conn, err := retrieveSSHConnection(ctx, deploymentID, host, hostInstance)
+		if err != nil {
+			return err
+		}
+		if err := defaultMonManager.registerCommandCheck(deploymentID, target, instance, ipAddress, commandValue.RawString(), host, hostInstance, conn.port, timeInterval); err != nil {
+			return errors.Errorf("Failed to register command check for node name:%q due to: %v", target, err)
+		}
+	}
+	return nil
+}
+
+func applyGRPCMonitoringPolicy(ctx context.Context, policyName, deploymentID, target string, timeInterval time.Duration, port int, instances []string) error {
+	var service string
+	serviceValue, err := deployments.GetPolicyPropertyValue(ctx, deploymentID, policyName, "service")
+	if err != nil {
+		return errors.Errorf("Failed to retrieve service for monitoring policy:%q due to: %v", policyName, err)
+	}
+	if serviceValue != nil {
+		service = serviceValue.RawString()
+	}
+	var useTLS bool
+	tlsValue, err := deployments.GetPolicyPropertyValue(ctx, deploymentID, policyName, "tls")
+	if err != nil {
+		return errors.Errorf("Failed to retrieve tls for monitoring policy:%q due to: %v", policyName, err)
+	}
+	if tlsValue != nil && tlsValue.RawString() != "" {
+		useTLS, err = strconv.ParseBool(tlsValue.RawString())
+		if err != nil {
+			return errors.Errorf("Failed to retrieve tls as correct boolean for monitoring policy:%q due to: %v", policyName, err)
+		}
+	}
+	var tlsClientConfig map[string]string
+	if useTLS {
+		tlsClientConfig, err = retrieveTLSClientConfig(ctx, policyName, deploymentID)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, instance := range instances {
+		ipAddress, err := retrieveIPAddress(ctx, deploymentID, target, instance)
+		if err != nil {
+			return err
+		}
+		if err := defaultMonManager.registerGRPCCheck(deploymentID, target, instance, ipAddress, service, port, useTLS, tlsClientConfig, timeInterval); err != nil {
+			return errors.Errorf("Failed to register gRPC check for node name:%q due to: %v", target, err)
+		}
+	}
+	return nil
+}
+
+// retrieveComputeInstance returns the node and instance at the bottom of the HostedOn hierarchy of the given node instance
+func retrieveComputeInstance(ctx context.Context, deploymentID, nodeName, instance string) (string, string, error) {
+	for {
+		host, hostInstance, err := deployments.GetHostedOnNodeInstance(ctx, deploymentID, nodeName, instance)
+		if err != nil {
+			return "", "", errors.Errorf("Failed to retrieve host of node name:%q, instance:%q due to: %v", nodeName, instance, err)
+		}
+		if host == "" {
+			return nodeName, instance, nil
+		}
+		nodeName, instance = host, hostInstance
+	}
+}
+
+// retrieveSSHConnection returns the SSH connection parameters defined by the endpoint capability of a compute instance
+func retrieveSSHConnection(ctx context.Context, deploymentID, nodeName, instance string) (sshConnection, error) {
+	conn := sshConnection{port: 22}
+	credentialsValue, err := deployments.GetInstanceCapabilityAttributeValue(ctx, deploymentID, nodeName, instance, tosca.ComputeNodeEndpointCapabilityName, "credentials")
+	if err != nil {
+		return conn, errors.Errorf("Failed to retrieve endpoint credentials for node name:%q due to: %v", nodeName, err)
+	}
+	credentials := new(types.Credential)
+	if credentialsValue != nil && credentialsValue.RawString() != "" {
+		err = mapstructure.Decode(credentialsValue.Value, credentials)
+		if err != nil {
+			return conn, errors.Wrapf(err, "failed to decode credentials for node %q", nodeName)
+		}
+	}
+	if credentials.User != "" {
+		conn.user = config.DefaultConfigTemplateResolver.ResolveValueWithTemplates("host.user", credentials.User).(string)
+	}
+	if credentials.Token != "" {
+		conn.password = config.DefaultConfigTemplateResolver.ResolveValueWithTemplates("host.password", credentials.Token).(string)
+	}
+	keys, err := sshutil.GetKeysFromCredentialsDataType(credentials)
+	if err != nil {
+		return conn, err
+	}
+	conn.privateKeys = make(map[string]string, len(keys))
+	conn.certificates = make(map[string]string)
+	for name, key := range keys {
+		// Prefer paths to avoid storing keys contents
+		if key.Path != "" {
+			conn.privateKeys[name] = key.Path
+		} else {
+			conn.privateKeys[name] = string(key.Content)
+		}
+		if len(key.Certificate) > 0 {
+			conn.certificates[name] = string(key.Certificate)
+		}
+	}
+
+	portValue, err := deployments.GetInstanceCapabilityAttributeValue(ctx, deploymentID, nodeName, instance, tosca.ComputeNodeEndpointCapabilityName, "port")
+	if err != nil {
+		return conn, errors.Errorf("Failed to retrieve endpoint port for node name:%q due to: %v", nodeName, err)
+	}
+	if portValue != nil && portValue.RawString() != "" {
+		conn.port, err = strconv.Atoi(portValue.RawString())
+		if err != nil {
+			return conn, errors.Errorf("Failed to retrieve endpoint port as correct integer for node name:%q due to: %v", nodeName, err)
+		}
+	}
+	return conn, nil
+}
+
 func retrieveTLSClientConfig(ctx context.Context, policyName, deploymentID string) (map[string]string, error) {
 	tlsClientConfig := make(map[string]string, 0)
 	props := []string{"ca_cert", "ca_path", "client_cert", "client_key", "skip_verify"}
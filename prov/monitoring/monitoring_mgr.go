@@ -12,11 +12,12 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-// Package monitoring is responsible for handling node monitoring (tcp and http checks) especially for tosca.nodes.Compute and tosca.nodes.SoftwareComponent node templates
+// Package monitoring is responsible for handling node monitoring (tcp, http, command and gRPC checks) especially for tosca.nodes.Compute and tosca.nodes.SoftwareComponent node templates
 // Present limitation : only one monitoring check by node instance is allowed
 package monitoring
 
 import (
+	"context"
 	"path"
 	"strconv"
 	"strings"
@@ -25,9 +26,11 @@ import (
 
 	"github.com/hashicorp/consul/api"
 	"github.com/pkg/errors"
+	"golang.org/x/crypto/ssh"
 
 	"github.com/ystia/yorc/v4/config"
 	"github.com/ystia/yorc/v4/helper/consulutil"
+	"github.com/ystia/yorc/v4/helper/sshutil"
 	"github.com/ystia/yorc/v4/log"
 )
 
@@ -217,6 +220,18 @@ func (mgr *monitoringMgr) startMonitoring() {
 						handleError(err)
 						continue
 					}
+				case CheckTypeCOMMAND:
+					check.execution, err = mgr.buildCommandExecution(key, check.Report.DeploymentID, address, port)
+					if err != nil {
+						handleError(err)
+						continue
+					}
+				case CheckTypeGRPC:
+					check.execution, err = mgr.buildGRPCExecution(key, address, port)
+					if err != nil {
+						handleError(err)
+						continue
+					}
 				}
 
 				reportPath := path.Join(consulutil.MonitoringKVPrefix, "reports", id)
@@ -236,6 +251,11 @@ func (mgr *monitoringMgr) startMonitoring() {
 				// Store the check if not already present and start it
 				_, is := mgr.checks[id]
 				if !is {
+					check.healing, err = loadHealingPolicy(context.Background(), check.Report.DeploymentID, check.Report.NodeName)
+					if err != nil {
+						// Monitoring is still done without healing
+						handleError(err)
+					}
 					mgr.checks[check.ID] = check
 					check.Start()
 				}
@@ -288,6 +308,138 @@ func (mgr *monitoringMgr) buildHTTPExecution(key string, address string, port in
 	return newHTTPCheckExecution(address, port, scheme, urlPath, headersMap, tlsConf)
 }
 
+func (mgr *monitoringMgr) buildCommandExecution(key, deploymentID, address string, port int) (*commandCheckExecution, error) {
+	kvp, _, err := mgr.cc.KV().Get(path.Join(key, "command"), nil)
+	if err != nil {
+		return nil, err
+	}
+	if kvp == nil || len(kvp.Value) == 0 {
+		return nil, errors.Errorf("Missing mandatory field \"command\" for check with key path:%q", key)
+	}
+	command := string(kvp.Value)
+
+	kvp, _, err = mgr.cc.KV().Get(path.Join(key, "host"), nil)
+	if err != nil {
+		return nil, err
+	}
+	if kvp == nil || len(kvp.Value) == 0 {
+		return nil, errors.Errorf("Missing mandatory field \"host\" for check with key path:%q", key)
+	}
+	host := string(kvp.Value)
+	kvp, _, err = mgr.cc.KV().Get(path.Join(key, "hostInstance"), nil)
+	if err != nil {
+		return nil, err
+	}
+	if kvp == nil || len(kvp.Value) == 0 {
+		return nil, errors.Errorf("Missing mandatory field \"hostInstance\" for check with key path:%q", key)
+	}
+	hostInstance := string(kvp.Value)
+
+	// Credentials are never stored with the check, they are resolved from the deployment
+	conn, err := resolveSSHConnection(context.Background(), deploymentID, host, hostInstance)
+	if err != nil {
+		return nil, err
+	}
+
+	conf := &ssh.ClientConfig{
+		User:    conn.user,
+		Timeout: mgr.cfg.SSHConnectionTimeout,
+	}
+
+	hostKeyOpts := sshutil.HostKeyOptions{
+		Mode:           mgr.cfg.SSHHostKeyChecking,
+		KnownHostsFile: mgr.cfg.SSHKnownHostsFile,
+		Scope:          "monitoring",
+	}
+	err = sshutil.ConfigureHostKeyVerification(conf, address, port, hostKeyOpts, func(err error) {
+		log.Printf("[ERROR] Monitoring: %v", err)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Keys are either paths or contents
+	for keyName, keyValue := range conn.privateKeys {
+		if keyValue == "" {
+			continue
+		}
+		pk, err := sshutil.GetPrivateKey(keyValue)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse key %q for check with key path:%q", keyName, key)
+		}
+		if cert := conn.certificates[keyName]; cert != "" {
+			if err = pk.SetCertificate(cert); err != nil {
+				return nil, errors.Wrapf(err, "failed to parse certificate of key %q for check with key path:%q", keyName, key)
+			}
+		}
+		keyAuth, err := sshutil.ReadSSHPrivateKey(pk)
+		if err != nil {
+			return nil, err
+		}
+		conf.Auth = append(conf.Auth, keyAuth)
+	}
+
+	if conn.password != "" {
+		conf.Auth = append(conf.Auth, ssh.Password(conn.password))
+	}
+
+	if len(conf.Auth) == 0 {
+		// Fallback to the default Yorc key as done for operations executions
+		pk, err := sshutil.GetDefaultKey()
+		if err != nil {
+			return nil, err
+		}
+		keyAuth, err := sshutil.ReadSSHPrivateKey(pk)
+		if err != nil {
+			return nil, err
+		}
+		conf.Auth = append(conf.Auth, keyAuth)
+	}
+
+	client := &sshutil.SSHClient{
+		Config: conf,
+		Host:   address,
+		Port:   port,
+	}
+	return newCommandCheckExecution(client, command), nil
+}
+
+func (mgr *monitoringMgr) buildGRPCExecution(key string, address string, port int) (*grpcCheckExecution, error) {
+	var service string
+	kvp, _, err := mgr.cc.KV().Get(path.Join(key, "service"), nil)
+	if err != nil {
+		return nil, err
+	}
+	if kvp != nil {
+		service = string(kvp.Value)
+	}
+
+	var useTLS bool
+	kvp, _, err = mgr.cc.KV().Get(path.Join(key, "tls"), nil)
+	if err != nil {
+		return nil, err
+	}
+	if kvp != nil && len(kvp.Value) > 0 {
+		useTLS, err = strconv.ParseBool(string(kvp.Value))
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid field \"tls\" for check with key path:%q", key)
+		}
+	}
+
+	// Appending a final "/" here is not necessary as there is no other keys starting with "tlsClient" prefix
+	kvps, _, err := mgr.cc.KV().List(path.Join(key, "tlsClient"), nil)
+	if err != nil {
+		return nil, err
+	}
+	tlsConf := make(map[string]string, len(kvps))
+	for _, kvp := range kvps {
+		if kvp.Value != nil {
+			tlsConf[path.Base(kvp.Key)] = string(kvp.Value)
+		}
+	}
+	return newGRPCCheckExecution(address, port, service, useTLS, tlsConf)
+}
+
 // registerTCPCheck allows to register a TCP check
 func (mgr *monitoringMgr) registerTCPCheck(deploymentID, nodeName, instance, ipAddress string, port int, interval time.Duration) error {
 	id := buildID(deploymentID, nodeName, instance)
@@ -434,6 +586,134 @@ func (mgr *monitoringMgr) registerHTTPCheck(deploymentID, nodeName, instance, ip
 	return nil
 }
 
+// sshConnection holds the parameters used by command checks to connect to a node instance over SSH.
+// It is resolved from the deployment each time a check execution is built and never stored in Consul.
+type sshConnection struct {
+	user     string
+	password string
+	port     int
+	// privateKeys are private keys paths or contents indexed by key name
+	privateKeys map[string]string
+	// certificates are OpenSSH certificates indexed by key name
+	certificates map[string]string
+}
+
+// resolveSSHConnection retrieves the SSH connection parameters of a compute instance from the deployment
+var resolveSSHConnection = retrieveSSHConnection
+
+// registerCommandCheck allows to register a check running a command on a node instance over SSH
+//
+// Only a reference to the compute instance hosting the node instance is stored with the check,
+// credentials are resolved from the deployment when the check execution is built.
+func (mgr *monitoringMgr) registerCommandCheck(deploymentID, nodeName, instance, ipAddress, command, host, hostInstance string, port int, interval time.Duration) error {
+	id := buildID(deploymentID, nodeName, instance)
+	log.Debugf("Register command check with id:%q, iPAddress:%q, port:%d, interval:%d", id, ipAddress, port, interval)
+
+	checkOps := api.KVTxnOps{
+		&api.KVTxnOp{
+			Verb:  api.KVSet,
+			Key:   path.Join(consulutil.MonitoringKVPrefix, "checks", id, "command"),
+			Value: []byte(command),
+		},
+		&api.KVTxnOp{
+			Verb:  api.KVSet,
+			Key:   path.Join(consulutil.MonitoringKVPrefix, "checks", id, "host"),
+			Value: []byte(host),
+		},
+		&api.KVTxnOp{
+			Verb:  api.KVSet,
+			Key:   path.Join(consulutil.MonitoringKVPrefix, "checks", id, "hostInstance"),
+			Value: []byte(hostInstance),
+		},
+	}
+	return mgr.registerCheck(id, CheckTypeCOMMAND, ipAddress, port, interval, checkOps)
+}
+
+// registerGRPCCheck allows to register a gRPC health check
+func (mgr *monitoringMgr) registerGRPCCheck(deploymentID, nodeName, instance, ipAddress, service string, port int, useTLS bool, tlsClientConfig map[string]string, interval time.Duration) error {
+	id := buildID(deploymentID, nodeName, instance)
+	log.Debugf("Register gRPC check with id:%q, iPAddress:%q, port:%d, interval:%d", id, ipAddress, port, interval)
+
+	checkOps := api.KVTxnOps{
+		&api.KVTxnOp{
+			Verb:  api.KVSet,
+			Key:   path.Join(consulutil.MonitoringKVPrefix, "checks", id, "service"),
+			Value: []byte(service),
+		},
+		&api.KVTxnOp{
+			Verb:  api.KVSet,
+			Key:   path.Join(consulutil.MonitoringKVPrefix, "checks", id, "tls"),
+			Value: []byte(strconv.FormatBool(useTLS)),
+		},
+	}
+	for k, v := range tlsClientConfig {
+		checkOps = append(checkOps, &api.KVTxnOp{
+			Verb:  api.KVSet,
+			Key:   path.Join(consulutil.MonitoringKVPrefix, "checks", id, "tlsClient", k),
+			Value: []byte(v),
+		})
+	}
+	return mgr.registerCheck(id, CheckTypeGRPC, ipAddress, port, interval, checkOps)
+}
+
+// registerCheck stores in a transaction the common fields of a check and its specific ones given as additional operations
+func (mgr *monitoringMgr) registerCheck(id string, checkType CheckType, ipAddress string, port int, interval time.Duration, additionalOps api.KVTxnOps) error {
+	checkPath := path.Join(consulutil.MonitoringKVPrefix, "checks", id) + "/"
+	checkReportPath := path.Join(consulutil.MonitoringKVPrefix, "reports", id) + "/"
+
+	kvps, _, err := mgr.cc.KV().List(checkPath, nil)
+	if err != nil {
+		return err
+	}
+	if kvps != nil {
+		log.Debugf("%s check with id:%q is already registered: nothing to do", checkType, id)
+		return nil
+	}
+
+	checkOps := api.KVTxnOps{
+		&api.KVTxnOp{
+			Verb:  api.KVSet,
+			Key:   path.Join(checkPath, "type"),
+			Value: []byte(strings.ToLower(checkType.String())),
+		},
+		&api.KVTxnOp{
+			Verb:  api.KVSet,
+			Key:   path.Join(checkPath, "address"),
+			Value: []byte(ipAddress),
+		},
+		&api.KVTxnOp{
+			Verb:  api.KVSet,
+			Key:   path.Join(checkPath, "port"),
+			Value: []byte(strconv.Itoa(port)),
+		},
+		&api.KVTxnOp{
+			Verb:  api.KVSet,
+			Key:   path.Join(checkPath, "interval"),
+			Value: []byte(interval.String()),
+		},
+		&api.KVTxnOp{
+			Verb:  api.KVSet,
+			Key:   path.Join(checkReportPath, "status"),
+			Value: []byte(CheckStatusINITIAL.String()),
+		},
+	}
+	checkOps = append(checkOps, additionalOps...)
+
+	ok, response, _, err := mgr.cc.KV().Txn(checkOps, nil)
+	if err != nil {
+		return errors.Wrapf(err, "Failed to add %s check with id:%q", checkType, id)
+	}
+	if !ok {
+		// Check the response
+		errs := make([]string, 0)
+		for _, e := range response.Errors {
+			errs = append(errs, e.What)
+		}
+		return errors.Errorf("Failed to add %s check with id:%q due to:%s", checkType, id, strings.Join(errs, ", "))
+	}
+	return nil
+}
+
 // flagCheckForRemoval allows to remove a check report and flag a check in order to remove it
 func (mgr *monitoringMgr) flagCheckForRemoval(deploymentID, nodeName, instance string) error {
 	id := buildID(deploymentID, nodeName, instance)
@@ -486,32 +766,7 @@ type CheckFilterFunc func(CheckReport) bool
 
 // listCheckReports can return a filtered checks reports list if defined filter function. Otherwise, it returns the full check reports.
 func (mgr *monitoringMgr) listCheckReports(f CheckFilterFunc) ([]CheckReport, error) {
-	log.Debugf("List check reports")
-	keys, _, err := mgr.cc.KV().Keys(path.Join(consulutil.MonitoringKVPrefix, "reports")+"/", "/", nil)
-	if err != nil {
-		return nil, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
-	}
-	checkReports := make([]CheckReport, 0)
-	for _, key := range keys {
-		id := path.Base(key)
-		check, err := NewCheckFromID(id)
-		if err != nil {
-			return nil, err
-		}
-
-		kvp, _, err := mgr.cc.KV().Get(path.Join(key, "status"), nil)
-		if err != nil {
-			return nil, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
-		}
-		if kvp != nil && len(kvp.Value) > 0 {
-			check.Report.Status, err = ParseCheckStatus(string(kvp.Value))
-			if err != nil {
-				return nil, err
-			}
-		}
-		checkReports = append(checkReports, check.Report)
-	}
-	return filter(checkReports, f), nil
+	return listCheckReports(mgr.cc.KV(), f)
 }
 
 func filter(tab []CheckReport, f CheckFilterFunc) []CheckReport {
@@ -16,6 +16,8 @@ package monitoring
 
 import (
 	"context"
+	"net"
+	"path"
 	"testing"
 	"time"
 
@@ -24,6 +26,7 @@ import (
 
 	"github.com/ystia/yorc/v4/config"
 	"github.com/ystia/yorc/v4/deployments"
+	"github.com/ystia/yorc/v4/helper/consulutil"
 	"github.com/ystia/yorc/v4/log"
 	"github.com/ystia/yorc/v4/tasks/workflow/builder"
 	"github.com/ystia/yorc/v4/tosca"
@@ -173,3 +176,81 @@ func testAddAndRemoveCheck(t *testing.T, client *api.Client) {
 	require.Len(t, checkReports, 1, "1 check is expected")
 	require.Len(t, defaultMonManager.checks, 1, "0 check is expected in work map")
 }
+
+func testGRPCMonitoringHook(t *testing.T, client *api.Client, cfg config.Configuration) {
+	ctx := context.Background()
+	dep := "monitoring6"
+	node := "App"
+
+	activity := &mockActivity{t: builder.ActivityTypeDelegate, v: "install"}
+	addMonitoringHook(ctx, cfg, "", dep, node, activity)
+	time.Sleep(2 * time.Second)
+
+	checkReports, err := defaultMonManager.listCheckReports(func(cr CheckReport) bool {
+		return cr.DeploymentID == dep
+	})
+	require.Nil(t, err, "Unexpected error while getting check reports list")
+	require.Len(t, checkReports, 1, "1 check is expected")
+	require.Equal(t, node, checkReports[0].NodeName, "unexpected node name")
+	require.Equal(t, CheckStatusPASSING, checkReports[0].Status, "unexpected status")
+
+	state, err := deployments.GetInstanceState(ctx, dep, node, "0")
+	require.Nil(t, err, "Unexpected error while node state")
+	require.Equal(t, tosca.NodeStateStarted, state)
+
+	activity = &mockActivity{t: builder.ActivityTypeDelegate, v: "uninstall"}
+	removeMonitoringHook(ctx, cfg, "", dep, node, activity)
+	time.Sleep(1 * time.Second)
+	checkReports, err = defaultMonManager.listCheckReports(func(cr CheckReport) bool {
+		return cr.DeploymentID == dep
+	})
+	require.Nil(t, err, "Unexpected error while getting check reports list")
+	require.Len(t, checkReports, 0, "0 check is expected")
+}
+
+func testCommandCheck(t *testing.T, client *api.Client) {
+	listener := newStandInSSHServer(t, func(cmd string) (string, uint32) {
+		if cmd == "systemctl is-active myservice" {
+			return "failed", 3
+		}
+		return "", 0
+	})
+	defer listener.Close()
+
+	dep := "monitoring7"
+	node := "Compute"
+	conn := sshConnection{user: "test", password: "test", port: listener.Addr().(*net.TCPAddr).Port}
+	oldResolve := resolveSSHConnection
+	defer func() { resolveSSHConnection = oldResolve }()
+	resolveSSHConnection = func(ctx context.Context, deploymentID, nodeName, instance string) (sshConnection, error) {
+		require.Equal(t, dep, deploymentID)
+		require.Equal(t, node, nodeName)
+		require.Equal(t, "0", instance)
+		return conn, nil
+	}
+	err := defaultMonManager.registerCommandCheck(dep, node, "0", "127.0.0.1", "systemctl is-active myservice", node, "0", conn.port, 1*time.Second)
+	require.Nil(t, err, "Unexpected error while adding check")
+
+	checkPath := path.Join(consulutil.MonitoringKVPrefix, "checks", buildID(dep, node, "0"))
+	kvp, _, err := client.KV().Get(path.Join(checkPath, "type"), nil)
+	require.Nil(t, err)
+	require.NotNil(t, kvp)
+	require.Equal(t, "command", string(kvp.Value))
+
+	// Credentials must not be stored with the check
+	kvps, _, err := client.KV().List(checkPath+"/", nil)
+	require.Nil(t, err)
+	for _, kvp := range kvps {
+		require.NotContains(t, []string{"user", "password", "keys", "certificates"}, path.Base(path.Dir(kvp.Key)), "unexpected key %q", kvp.Key)
+		require.NotContains(t, []string{"user", "password"}, path.Base(kvp.Key), "unexpected key %q", kvp.Key)
+	}
+
+	execution, err := defaultMonManager.buildCommandExecution(checkPath, dep, "127.0.0.1", conn.port)
+	require.Nil(t, err, "Unexpected error while building command execution")
+	status, msg := execution.execute(time.Second)
+	require.Equal(t, CheckStatusCRITICAL, status, "unexpected status with message %q", msg)
+	require.Contains(t, msg, "exit code:3")
+
+	err = defaultMonManager.flagCheckForRemoval(dep, node, "0")
+	require.Nil(t, err, "Unexpected error while removing check")
+}
@@ -38,6 +38,8 @@ type CheckStatus int
 ENUM(
 TCP
 HTTP
+COMMAND
+GRPC
 )
 */
 type CheckType int
@@ -58,6 +60,10 @@ type Check struct {
 	timeout     time.Duration
 	ctx         context.Context
 	execution   checkExecution
+
+	healing              *healingPolicy
+	consecutiveFailures  int
+	consecutiveSuccesses int
 }
 
 // CheckReport represents a node check report including its status
@@ -66,4 +72,8 @@ type CheckReport struct {
 	NodeName     string
 	Instance     string
 	Status       CheckStatus
+	// Disabled is true when the check has been temporarily disabled
+	Disabled bool
+	// DisabledUntil is the optional date after which a disabled check is automatically enabled again
+	DisabledUntil *time.Time
 }
@@ -74,13 +74,19 @@ const (
 	CheckTypeTCP CheckType = iota
 	// CheckTypeHTTP is a CheckType of type HTTP
 	CheckTypeHTTP
+	// CheckTypeCOMMAND is a CheckType of type COMMAND
+	CheckTypeCOMMAND
+	// CheckTypeGRPC is a CheckType of type GRPC
+	CheckTypeGRPC
 )
 
-const _CheckTypeName = "TCPHTTP"
+const _CheckTypeName = "TCPHTTPCOMMANDGRPC"
 
 var _CheckTypeMap = map[CheckType]string{
 	0: _CheckTypeName[0:3],
 	1: _CheckTypeName[3:7],
+	2: _CheckTypeName[7:14],
+	3: _CheckTypeName[14:18],
 }
 
 // String implements the Stringer interface.
@@ -92,10 +98,14 @@ func (x CheckType) String() string {
 }
 
 var _CheckTypeValue = map[string]CheckType{
-	_CheckTypeName[0:3]:                  0,
-	strings.ToLower(_CheckTypeName[0:3]): 0,
-	_CheckTypeName[3:7]:                  1,
-	strings.ToLower(_CheckTypeName[3:7]): 1,
+	_CheckTypeName[0:3]:                    0,
+	strings.ToLower(_CheckTypeName[0:3]):   0,
+	_CheckTypeName[3:7]:                    1,
+	strings.ToLower(_CheckTypeName[3:7]):   1,
+	_CheckTypeName[7:14]:                   2,
+	strings.ToLower(_CheckTypeName[7:14]):  2,
+	_CheckTypeName[14:18]:                  3,
+	strings.ToLower(_CheckTypeName[14:18]): 3,
 }
 
 // ParseCheckType attempts to convert a string to a CheckType
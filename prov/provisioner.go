@@ -91,6 +91,32 @@ type OperationExecutor interface {
 	ExecAsyncOperation(ctx context.Context, conf config.Configuration, taskID, deploymentID, nodeName string, operation Operation, stepName string) (*Action, time.Duration, error)
 }
 
+// PlannedResource is an infrastructure resource that an executor would generate to execute an operation
+type PlannedResource struct {
+	// Kind of resource, for instance "terraform" for a Terraform file or a Kubernetes object kind
+	Kind string `json:"kind"`
+	// Name of the resource
+	Name string `json:"name"`
+	// Content is the resource definition
+	Content string `json:"content,omitempty"`
+}
+
+// DelegatePlanner is an optional interface that a DelegateExecutor may implement to describe
+// the infrastructure resources it would generate for a delegate operation.
+//
+// PlanDelegate should neither apply these resources nor modify the deployment state.
+type DelegatePlanner interface {
+	PlanDelegate(ctx context.Context, conf config.Configuration, deploymentID, nodeName, delegateOperation string) ([]PlannedResource, error)
+}
+
+// OperationPlanner is an optional interface that an OperationExecutor may implement to describe
+// the infrastructure resources it would generate for an operation.
+//
+// PlanOperation should neither apply these resources nor modify the deployment state.
+type OperationPlanner interface {
+	PlanOperation(ctx context.Context, conf config.Configuration, deploymentID, nodeName string, operation Operation) ([]PlannedResource, error)
+}
+
 // InfraUsageCollector is the interface for collecting information about infrastructure usage for a defined location
 //
 // GetUsageInfo returns data about infrastructure usage for defined infrastructure and location
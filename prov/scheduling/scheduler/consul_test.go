@@ -74,5 +74,8 @@ func TestRunConsulSchedulingPackageTests(t *testing.T) {
 		t.Run("testUpdateActionData", func(t *testing.T) {
 			testUpdateActionData(t, client)
 		})
+		t.Run("testWorkflowSchedules", func(t *testing.T) {
+			testWorkflowSchedules(t, client)
+		})
 	})
 }
@@ -0,0 +1,126 @@
+// Copyright 2021 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scheduler
+
+import (
+	"context"
+	"path"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/armon/go-metrics"
+	"github.com/pkg/errors"
+
+	"github.com/ystia/yorc/v4/deployments"
+	"github.com/ystia/yorc/v4/events"
+	"github.com/ystia/yorc/v4/helper/metricsutil"
+	"github.com/ystia/yorc/v4/log"
+	"github.com/ystia/yorc/v4/prov/scheduling"
+	"github.com/ystia/yorc/v4/tasks"
+)
+
+type scheduledWorkflow struct {
+	*scheduling.WorkflowSchedule
+
+	stopScheduling     bool
+	stopSchedulingLock sync.Mutex
+	chStop             chan struct{}
+}
+
+func (sw *scheduledWorkflow) start() {
+	sw.stopSchedulingLock.Lock()
+	defer sw.stopSchedulingLock.Unlock()
+
+	sw.chStop = make(chan struct{})
+	sw.stopScheduling = false
+	go sw.schedule()
+}
+
+func (sw *scheduledWorkflow) stop() {
+	sw.stopSchedulingLock.Lock()
+	defer sw.stopSchedulingLock.Unlock()
+
+	if !sw.stopScheduling {
+		sw.stopScheduling = true
+		close(sw.chStop)
+	}
+}
+
+func (sw *scheduledWorkflow) schedule() {
+	log.Debugf("Scheduling workflow %q of deployment %q with schedule ID:%q", sw.WorkflowName, sw.DeploymentID, sw.ID)
+	for {
+		next, err := sw.NextRun(time.Now())
+		if err != nil {
+			// Should not happen as schedules are validated at registration
+			log.Printf("[WARN] Failed to compute next execution of workflow schedule %q: %v", sw.ID, err)
+			return
+		}
+		if next.IsZero() {
+			log.Printf("[WARN] Workflow schedule %q with cron expression %q will never be triggered", sw.ID, sw.Cron)
+			return
+		}
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-sw.chStop:
+			log.Debugf("Stop scheduling workflow with schedule ID:%q", sw.ID)
+			timer.Stop()
+			return
+		case <-timer.C:
+			err = sw.proceed(next)
+			if err != nil {
+				log.Printf("[WARN] Failed to store execution status of workflow schedule %q: %v", sw.ID, err)
+			}
+		}
+	}
+}
+
+func (sw *scheduledWorkflow) proceed(triggerTime time.Time) error {
+	labels := []metrics.Label{
+		metrics.Label{Name: "DeploymentID", Value: sw.DeploymentID},
+		metrics.Label{Name: "WorkflowName", Value: sw.WorkflowName},
+	}
+	metrics.IncrCounterWithLabels(metricsutil.CleanupMetricKey([]string{"scheduling", "workflows", "ticks"}), 1, labels)
+
+	ctx := events.AddLogOptionalFields(context.Background(), events.LogOptionalFields{events.WorkFlowID: sw.WorkflowName})
+	taskID, err := sw.registerTask(ctx)
+	if err != nil {
+		events.WithContextOptionalFields(ctx).NewLogEntry(events.LogLevelWARN, sw.DeploymentID).Registerf("Scheduled execution of workflow %q (schedule ID %q) skipped: %v", sw.WorkflowName, sw.ID, err)
+		metrics.IncrCounterWithLabels(metricsutil.CleanupMetricKey([]string{"scheduling", "workflows", "misses"}), 1, labels)
+		return scheduling.UpdateWorkflowScheduleLastRun(sw.ID, triggerTime, "", err.Error())
+	}
+	events.WithContextOptionalFields(ctx).NewLogEntry(events.LogLevelINFO, sw.DeploymentID).Registerf("Scheduled execution of workflow %q (schedule ID %q) submitted with task ID %q", sw.WorkflowName, sw.ID, taskID)
+	return scheduling.UpdateWorkflowScheduleLastRun(sw.ID, triggerTime, taskID, "")
+}
+
+func (sw *scheduledWorkflow) registerTask(ctx context.Context) (string, error) {
+	status, err := deployments.GetDeploymentStatus(ctx, sw.DeploymentID)
+	if err != nil {
+		return "", err
+	}
+	if status != deployments.DEPLOYED && status != deployments.UPDATED {
+		return "", errors.Errorf("deployment status is %q", status.String())
+	}
+
+	data := map[string]string{
+		"workflowName":    sw.WorkflowName,
+		"continueOnError": strconv.FormatBool(sw.ContinueOnError),
+		"scheduleID":      sw.ID,
+	}
+	for k, v := range sw.Inputs {
+		data[path.Join("inputs", k)] = v
+	}
+	return defaultScheduler.collector.RegisterTaskWithData(sw.DeploymentID, tasks.TaskTypeCustomWorkflow, data)
+}
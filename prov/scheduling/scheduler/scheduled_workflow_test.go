@@ -0,0 +1,95 @@
+// Copyright 2021 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ystia/yorc/v4/prov/scheduling"
+)
+
+func testWorkflowSchedules(t *testing.T, client *api.Client) {
+	deploymentID := "dep-" + t.Name()
+	schedule := &scheduling.WorkflowSchedule{
+		DeploymentID: deploymentID,
+		WorkflowName: "backup",
+		Cron:         "0 2 * * *",
+		TimeZone:     "Europe/Paris",
+		Inputs:       map[string]string{"retention": "7"},
+	}
+	_, err := scheduling.RegisterWorkflowSchedule(&scheduling.WorkflowSchedule{DeploymentID: deploymentID, WorkflowName: "backup", Cron: "every day"})
+	require.Error(t, err, "expecting an error for an invalid cron expression")
+	_, err = scheduling.RegisterWorkflowSchedule(&scheduling.WorkflowSchedule{DeploymentID: deploymentID, WorkflowName: "backup", Cron: "@daily", TimeZone: "Nowhere/Unknown"})
+	require.Error(t, err, "expecting an error for an invalid time zone")
+
+	id, err := scheduling.RegisterWorkflowSchedule(schedule)
+	require.NoError(t, err)
+	require.NotEmpty(t, id)
+
+	schedules, err := scheduling.ListWorkflowSchedules(deploymentID)
+	require.NoError(t, err)
+	require.Len(t, schedules, 1)
+	assert.True(t, schedules[0].SameDefinition(schedule))
+
+	// Wait for the scheduler to take it into account
+	require.Eventually(t, func() bool {
+		defaultScheduler.workflowsLock.Lock()
+		defer defaultScheduler.workflowsLock.Unlock()
+		_, ok := defaultScheduler.workflows[id]
+		return ok
+	}, 5*time.Second, 50*time.Millisecond)
+
+	lastRun := time.Now().UTC().Truncate(time.Second)
+	err = scheduling.UpdateWorkflowScheduleLastRun(id, lastRun, "task1", "")
+	require.NoError(t, err)
+	schedule.Cron = "30 3 * * MON-FRI"
+	schedule.Inputs = nil
+	err = scheduling.UpdateWorkflowSchedule(schedule)
+	require.NoError(t, err)
+
+	actual, err := scheduling.GetWorkflowSchedule(id)
+	require.NoError(t, err)
+	require.NotNil(t, actual)
+	assert.True(t, actual.SameDefinition(schedule))
+	assert.Equal(t, "task1", actual.LastTaskID)
+	require.NotNil(t, actual.LastRun)
+	assert.True(t, lastRun.Equal(*actual.LastRun))
+
+	err = scheduling.UpdateWorkflowSchedule(&scheduling.WorkflowSchedule{ID: id, DeploymentID: "other", WorkflowName: "backup", Cron: "@daily"})
+	require.Error(t, err, "expecting an error updating a schedule of another deployment")
+
+	err = scheduling.UnregisterDeploymentWorkflowSchedules(deploymentID)
+	require.NoError(t, err)
+	actual, err = scheduling.GetWorkflowSchedule(id)
+	require.NoError(t, err)
+	assert.Nil(t, actual)
+	err = scheduling.UpdateWorkflowScheduleLastRun(id, lastRun, "task2", "")
+	require.Error(t, err, "expecting an error updating the last run of a removed schedule")
+	actual, err = scheduling.GetWorkflowSchedule(id)
+	require.NoError(t, err)
+	assert.Nil(t, actual, "last run update should not recreate a removed schedule")
+
+	require.Eventually(t, func() bool {
+		defaultScheduler.workflowsLock.Lock()
+		defer defaultScheduler.workflowsLock.Unlock()
+		_, ok := defaultScheduler.workflows[id]
+		return !ok
+	}, 5*time.Second, 50*time.Millisecond)
+}
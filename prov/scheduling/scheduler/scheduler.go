@@ -27,6 +27,7 @@ import (
 	"github.com/ystia/yorc/v4/config"
 	"github.com/ystia/yorc/v4/helper/consulutil"
 	"github.com/ystia/yorc/v4/log"
+	"github.com/ystia/yorc/v4/prov/scheduling"
 	"github.com/ystia/yorc/v4/tasks/collector"
 )
 
@@ -42,6 +43,8 @@ type scheduler struct {
 	isActiveLock     sync.Mutex
 	cfg              config.Configuration
 	actions          map[string]*scheduledAction
+	workflows        map[string]*scheduledWorkflow
+	workflowsLock    sync.Mutex
 }
 
 // unregisterAction allows to unregister a scheduled action
@@ -94,6 +97,10 @@ func (sc *scheduler) startScheduling() {
 	sc.isActiveLock.Unlock()
 	sc.chStopScheduling = make(chan struct{})
 	sc.actions = make(map[string]*scheduledAction)
+	sc.workflowsLock.Lock()
+	sc.workflows = make(map[string]*scheduledWorkflow)
+	sc.workflowsLock.Unlock()
+	go sc.watchWorkflowSchedules(sc.chStopScheduling)
 	var waitIndex uint64
 	go func() {
 		for {
@@ -172,9 +179,83 @@ func (sc *scheduler) stopScheduling() {
 		for _, action := range defaultScheduler.actions {
 			action.stop()
 		}
+		defaultScheduler.stopWorkflows()
 	}
 }
 
+// watchWorkflowSchedules polls for workflows schedules and (re)schedules them according to their definition
+func (sc *scheduler) watchWorkflowSchedules(chStop chan struct{}) {
+	var waitIndex uint64
+	for {
+		select {
+		case <-chStop:
+			return
+		case <-sc.chShutdown:
+			return
+		default:
+		}
+
+		q := &api.QueryOptions{WaitIndex: waitIndex}
+		schedules, rMeta, err := scheduling.ListWorkflowSchedulesWithOptions("", q)
+		if err != nil {
+			handleError(err)
+			// Avoid to loop too quickly if Consul is unavailable
+			time.Sleep(time.Second)
+			continue
+		}
+		if waitIndex == rMeta.LastIndex {
+			// long pool ended due to a timeout
+			continue
+		}
+		waitIndex = rMeta.LastIndex
+
+		select {
+		case <-chStop:
+			return
+		default:
+		}
+		sc.reconcileWorkflows(schedules)
+	}
+}
+
+// reconcileWorkflows starts new workflows schedules, restarts updated ones and stops removed ones
+func (sc *scheduler) reconcileWorkflows(schedules []*scheduling.WorkflowSchedule) {
+	sc.workflowsLock.Lock()
+	defer sc.workflowsLock.Unlock()
+
+	known := make(map[string]bool, len(schedules))
+	for _, schedule := range schedules {
+		known[schedule.ID] = true
+		sw, is := sc.workflows[schedule.ID]
+		if is {
+			if sw.SameDefinition(schedule) {
+				continue
+			}
+			log.Debugf("Workflow schedule with id:%q has been updated, restarting it", schedule.ID)
+			sw.stop()
+		}
+		sw = &scheduledWorkflow{WorkflowSchedule: schedule}
+		sc.workflows[schedule.ID] = sw
+		sw.start()
+	}
+	for id, sw := range sc.workflows {
+		if !known[id] {
+			log.Debugf("Workflow schedule with id:%q has been removed, stop scheduling it", id)
+			sw.stop()
+			delete(sc.workflows, id)
+		}
+	}
+}
+
+func (sc *scheduler) stopWorkflows() {
+	sc.workflowsLock.Lock()
+	defer sc.workflowsLock.Unlock()
+	for _, sw := range sc.workflows {
+		sw.stop()
+	}
+	sc.workflows = make(map[string]*scheduledWorkflow)
+}
+
 func (sc *scheduler) buildScheduledAction(id string) (*scheduledAction, error) {
 	sca := &scheduledAction{}
 	sca.ID = id
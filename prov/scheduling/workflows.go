@@ -0,0 +1,304 @@
+// Copyright 2021 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scheduling
+
+import (
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+
+	"github.com/ystia/yorc/v4/helper/consulutil"
+	"github.com/ystia/yorc/v4/helper/cronutil"
+	"github.com/ystia/yorc/v4/log"
+)
+
+// WorkflowSchedulesKVPrefix is the prefix in Consul KV store for workflows schedules
+var WorkflowSchedulesKVPrefix = path.Join(consulutil.SchedulingKVPrefix, "workflows")
+
+// WorkflowSchedule is the definition of a recurring execution of a deployment workflow
+type WorkflowSchedule struct {
+	ID           string `json:"id"`
+	DeploymentID string `json:"deployment_id"`
+	WorkflowName string `json:"workflow_name"`
+	// Cron is a standard 5 fields cron expression or a descriptor like @daily
+	Cron string `json:"cron"`
+	// TimeZone is the IANA time zone name in which the cron expression is evaluated, UTC by default
+	TimeZone        string            `json:"time_zone,omitempty"`
+	ContinueOnError bool              `json:"continue_on_error,omitempty"`
+	Inputs          map[string]string `json:"inputs,omitempty"`
+	// LastRun is the time of the latest triggered execution
+	LastRun *time.Time `json:"last_run,omitempty"`
+	// LastTaskID is the ID of the task created by the latest triggered execution
+	LastTaskID string `json:"last_task_id,omitempty"`
+	// LastError is the reason why the latest triggered execution could not be submitted
+	LastError string `json:"last_error,omitempty"`
+}
+
+// Location returns the time zone in which the schedule cron expression is evaluated
+func (ws *WorkflowSchedule) Location() (*time.Location, error) {
+	if ws.TimeZone == "" {
+		return time.UTC, nil
+	}
+	loc, err := time.LoadLocation(ws.TimeZone)
+	return loc, errors.Wrapf(err, "invalid time zone %q", ws.TimeZone)
+}
+
+// Validate checks that the schedule definition is valid
+func (ws *WorkflowSchedule) Validate() error {
+	if ws.DeploymentID == "" {
+		return errors.New("deploymentID is mandatory parameter to schedule a workflow")
+	}
+	if ws.WorkflowName == "" {
+		return errors.New("workflow name is mandatory parameter to schedule a workflow")
+	}
+	if _, err := cronutil.Parse(ws.Cron); err != nil {
+		return err
+	}
+	_, err := ws.Location()
+	return err
+}
+
+// NextRun returns the next execution time of the schedule after the given time
+//
+// A zero time is returned if the schedule will never be triggered.
+func (ws *WorkflowSchedule) NextRun(from time.Time) (time.Time, error) {
+	loc, err := ws.Location()
+	if err != nil {
+		return time.Time{}, err
+	}
+	s, err := cronutil.Parse(ws.Cron)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return s.Next(from.In(loc)), nil
+}
+
+// SameDefinition checks if two schedules define the same workflow executions, the latest run status is ignored
+func (ws *WorkflowSchedule) SameDefinition(other *WorkflowSchedule) bool {
+	if ws.DeploymentID != other.DeploymentID || ws.WorkflowName != other.WorkflowName || ws.Cron != other.Cron ||
+		ws.TimeZone != other.TimeZone || ws.ContinueOnError != other.ContinueOnError || len(ws.Inputs) != len(other.Inputs) {
+		return false
+	}
+	for k, v := range ws.Inputs {
+		if ov, ok := other.Inputs[k]; !ok || ov != v {
+			return false
+		}
+	}
+	return true
+}
+
+func workflowScheduleDefinitionOps(schedule *WorkflowSchedule) api.KVTxnOps {
+	schPath := path.Join(WorkflowSchedulesKVPrefix, schedule.ID)
+	ops := api.KVTxnOps{
+		&api.KVTxnOp{Verb: api.KVSet, Key: path.Join(schPath, "deploymentID"), Value: []byte(schedule.DeploymentID)},
+		&api.KVTxnOp{Verb: api.KVSet, Key: path.Join(schPath, "workflowName"), Value: []byte(schedule.WorkflowName)},
+		&api.KVTxnOp{Verb: api.KVSet, Key: path.Join(schPath, "cron"), Value: []byte(schedule.Cron)},
+		&api.KVTxnOp{Verb: api.KVSet, Key: path.Join(schPath, "timeZone"), Value: []byte(schedule.TimeZone)},
+		&api.KVTxnOp{Verb: api.KVSet, Key: path.Join(schPath, "continueOnError"), Value: []byte(strconv.FormatBool(schedule.ContinueOnError))},
+		// Inputs are replaced
+		&api.KVTxnOp{Verb: api.KVDeleteTree, Key: path.Join(schPath, "inputs") + "/"},
+	}
+	for k, v := range schedule.Inputs {
+		ops = append(ops, &api.KVTxnOp{Verb: api.KVSet, Key: path.Join(schPath, "inputs", k), Value: []byte(v)})
+	}
+	return ops
+}
+
+func executeTxn(ops api.KVTxnOps, msg string) error {
+	ok, response, _, err := consulutil.GetKV().Txn(ops, nil)
+	if err != nil {
+		return errors.Wrap(err, msg)
+	}
+	if !ok {
+		// Check the response
+		errs := make([]string, 0)
+		for _, e := range response.Errors {
+			errs = append(errs, e.What)
+		}
+		return errors.Errorf("%s due to:%s", msg, strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+// RegisterWorkflowSchedule allows to register a workflow schedule and returns its ID
+//
+// The workflow is then executed by the scheduler each time the schedule cron expression is triggered.
+func RegisterWorkflowSchedule(schedule *WorkflowSchedule) (string, error) {
+	if err := schedule.Validate(); err != nil {
+		return "", err
+	}
+	schedule.ID = uuid.NewV4().String()
+	log.Debugf("Workflow %q of deployment %q has been requested to be scheduled with [id:%q, cron:%q, timeZone:%q]", schedule.WorkflowName, schedule.DeploymentID, schedule.ID, schedule.Cron, schedule.TimeZone)
+	err := executeTxn(workflowScheduleDefinitionOps(schedule), "Failed to register workflow schedule for deploymentID:"+schedule.DeploymentID)
+	if err != nil {
+		return "", err
+	}
+	return schedule.ID, nil
+}
+
+// UpdateWorkflowSchedule allows to update the definition of an existing workflow schedule
+func UpdateWorkflowSchedule(schedule *WorkflowSchedule) error {
+	if err := schedule.Validate(); err != nil {
+		return err
+	}
+	ops := workflowScheduleDefinitionOps(schedule)
+	// Ensure that the schedule was not removed in the meantime
+	kvp, _, err := consulutil.GetKV().Get(path.Join(WorkflowSchedulesKVPrefix, schedule.ID, "deploymentID"), nil)
+	if err != nil {
+		return errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+	}
+	if kvp == nil || string(kvp.Value) != schedule.DeploymentID {
+		return errors.Errorf("Workflow schedule with ID %q does not exist for deployment %q", schedule.ID, schedule.DeploymentID)
+	}
+	ops = append(api.KVTxnOps{&api.KVTxnOp{Verb: api.KVCheckIndex, Key: kvp.Key, Index: kvp.ModifyIndex}}, ops...)
+	return executeTxn(ops, "Failed to update workflow schedule "+schedule.ID)
+}
+
+// UnregisterWorkflowSchedule allows to remove a workflow schedule and to stop scheduling it
+func UnregisterWorkflowSchedule(id string) error {
+	log.Debugf("Unregister workflow schedule with id:%q", id)
+	_, err := consulutil.GetKV().DeleteTree(path.Join(WorkflowSchedulesKVPrefix, id)+"/", nil)
+	return errors.Wrapf(err, "Failed to delete workflow schedule with id:%q", id)
+}
+
+// UnregisterDeploymentWorkflowSchedules allows to remove all workflow schedules of a given deployment
+func UnregisterDeploymentWorkflowSchedules(deploymentID string) error {
+	schedules, err := ListWorkflowSchedules(deploymentID)
+	if err != nil {
+		return err
+	}
+	for _, schedule := range schedules {
+		err = UnregisterWorkflowSchedule(schedule.ID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UpdateWorkflowScheduleLastRun stores the status of the latest triggered execution of a workflow schedule
+func UpdateWorkflowScheduleLastRun(id string, lastRun time.Time, taskID, errMessage string) error {
+	schPath := path.Join(WorkflowSchedulesKVPrefix, id)
+	ops := api.KVTxnOps{
+		// Fails if the schedule was removed in the meantime, so it is not partially recreated
+		&api.KVTxnOp{Verb: api.KVGet, Key: path.Join(schPath, "deploymentID")},
+		&api.KVTxnOp{Verb: api.KVSet, Key: path.Join(schPath, "lastRun"), Value: []byte(lastRun.Format(time.RFC3339Nano))},
+		&api.KVTxnOp{Verb: api.KVSet, Key: path.Join(schPath, "lastTaskID"), Value: []byte(taskID)},
+		&api.KVTxnOp{Verb: api.KVSet, Key: path.Join(schPath, "lastError"), Value: []byte(errMessage)},
+	}
+	return executeTxn(ops, "Failed to update latest run of workflow schedule "+id)
+}
+
+// GetWorkflowSchedule returns a workflow schedule or nil if it does not exist
+func GetWorkflowSchedule(id string) (*WorkflowSchedule, error) {
+	kvps, _, err := consulutil.GetKV().List(path.Join(WorkflowSchedulesKVPrefix, id)+"/", nil)
+	if err != nil {
+		return nil, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+	}
+	schedules := workflowSchedulesFromKVPairs(kvps)
+	if len(schedules) == 0 {
+		return nil, nil
+	}
+	return schedules[0], nil
+}
+
+// ListWorkflowSchedules returns the workflow schedules of a given deployment
+//
+// If deploymentID is empty, schedules of all deployments are returned.
+func ListWorkflowSchedules(deploymentID string) ([]*WorkflowSchedule, error) {
+	schedules, _, err := ListWorkflowSchedulesWithOptions(deploymentID, nil)
+	return schedules, err
+}
+
+// ListWorkflowSchedulesWithOptions returns the workflow schedules of a given deployment using the given
+// query options (allowing to perform blocking queries)
+//
+// If deploymentID is empty, schedules of all deployments are returned.
+func ListWorkflowSchedulesWithOptions(deploymentID string, q *api.QueryOptions) ([]*WorkflowSchedule, *api.QueryMeta, error) {
+	kvps, meta, err := consulutil.GetKV().List(WorkflowSchedulesKVPrefix+"/", q)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+	}
+	schedules := workflowSchedulesFromKVPairs(kvps)
+	if deploymentID == "" {
+		return schedules, meta, nil
+	}
+	res := schedules[:0]
+	for _, s := range schedules {
+		if s.DeploymentID == deploymentID {
+			res = append(res, s)
+		}
+	}
+	return res, meta, nil
+}
+
+// workflowSchedulesFromKVPairs builds schedules from their keys, schedules are sorted by ID
+func workflowSchedulesFromKVPairs(kvps api.KVPairs) []*WorkflowSchedule {
+	var schedules []*WorkflowSchedule
+	byID := make(map[string]*WorkflowSchedule)
+	for _, kvp := range kvps {
+		relPath := strings.Split(strings.TrimPrefix(kvp.Key, WorkflowSchedulesKVPrefix+"/"), "/")
+		if len(relPath) < 2 {
+			continue
+		}
+		s, ok := byID[relPath[0]]
+		if !ok {
+			s = &WorkflowSchedule{ID: relPath[0]}
+			byID[s.ID] = s
+			schedules = append(schedules, s)
+		}
+		value := string(kvp.Value)
+		switch relPath[1] {
+		case "deploymentID":
+			s.DeploymentID = value
+		case "workflowName":
+			s.WorkflowName = value
+		case "cron":
+			s.Cron = value
+		case "timeZone":
+			s.TimeZone = value
+		case "continueOnError":
+			s.ContinueOnError, _ = strconv.ParseBool(value)
+		case "lastRun":
+			if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+				s.LastRun = &t
+			}
+		case "lastTaskID":
+			s.LastTaskID = value
+		case "lastError":
+			s.LastError = value
+		case "inputs":
+			if len(relPath) == 3 {
+				if s.Inputs == nil {
+					s.Inputs = make(map[string]string)
+				}
+				s.Inputs[relPath[2]] = value
+			}
+		}
+	}
+	// Ignore partially removed schedules
+	res := schedules[:0]
+	for _, s := range schedules {
+		if s.DeploymentID != "" {
+			res = append(res, s)
+		}
+	}
+	return res
+}
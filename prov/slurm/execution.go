@@ -127,7 +127,11 @@ func newExecution(ctx context.Context, cfg config.Configuration, taskID, deploym
 		return nil, err
 	}
 	// Create sshClient using user credentials from credentials property if the are provided, or from yorc config otherwise
-	execCommon.client, err = getSSHClient(cfg, creds, locationProps)
+	locationName, err := getLocationName(ctx, cfg, deploymentID, nodeName)
+	if err != nil {
+		return nil, err
+	}
+	execCommon.client, err = getSSHClient(ctx, cfg, deploymentID, locationName, creds, locationProps)
 	if err != nil {
 		return nil, err
 	}
@@ -529,7 +533,7 @@ func (e *executionCommon) buildEnvVars() string {
 
 func (e *executionCommon) submitJob(ctx context.Context, cmd string) error {
 	events.WithContextOptionalFields(ctx).NewLogEntry(events.LogLevelDEBUG, e.deploymentID).RegisterAsString(fmt.Sprintf("Run the command: %s", cmd))
-	out, err := e.client.RunCommand(cmd)
+	out, err := sshutil.RunCommandWithContext(ctx, e.client, cmd)
 	if err != nil {
 		log.Debugf("stderr:%q", out)
 		return errors.Wrap(err, out)
@@ -182,7 +182,12 @@ func (e *defaultExecutor) createNodeAllocation(ctx context.Context, cfg config.C
 
 	// Return an sshClient configured using the user credentials provided in the yorc.nodes.slurm.Compute node definition,
 	// or if not provided, the user credentials specified in the Yorc configuration
-	sshClient, err := getSSHClient(cfg, nodeAlloc.credentials, locationProps)
+	locationName, err := getLocationName(ctx, cfg, deploymentID, nodeName)
+	if err != nil {
+		events.WithContextOptionalFields(ctx).NewLogEntry(events.LogLevelERROR, deploymentID).RegisterAsString(err.Error())
+		return err
+	}
+	sshClient, err := getSSHClient(ctx, cfg, deploymentID, locationName, nodeAlloc.credentials, locationProps)
 	if err != nil {
 		events.WithContextOptionalFields(ctx).NewLogEntry(events.LogLevelERROR, deploymentID).RegisterAsString(err.Error())
 		return err
@@ -340,7 +345,12 @@ func (e *defaultExecutor) destroyNodeAllocation(ctx context.Context, cfg config.
 
 	// Return an sshClient configured using the user credentials provided in the yorc.nodes.slurm.Compute node definition,
 	// or if not provided, the user credentials specified in the Yorc configuration
-	sshClient, err := getSSHClient(cfg, nodeAlloc.credentials, locationProps)
+	locationName, err := getLocationName(ctx, cfg, deploymentID, nodeName)
+	if err != nil {
+		events.WithContextOptionalFields(ctx).NewLogEntry(events.LogLevelERROR, deploymentID).RegisterAsString(err.Error())
+		return err
+	}
+	sshClient, err := getSSHClient(ctx, cfg, deploymentID, locationName, nodeAlloc.credentials, locationProps)
 	if err != nil {
 		events.WithContextOptionalFields(ctx).NewLogEntry(events.LogLevelERROR, deploymentID).RegisterAsString(err.Error())
 		return err
@@ -19,6 +19,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"path"
 	"regexp"
 	"strconv"
 	"strings"
@@ -32,6 +33,7 @@ import (
 	"github.com/ystia/yorc/v4/deployments"
 	"github.com/ystia/yorc/v4/events"
 	"github.com/ystia/yorc/v4/helper/sshutil"
+	"github.com/ystia/yorc/v4/locations"
 	"github.com/ystia/yorc/v4/log"
 	"github.com/ystia/yorc/v4/tosca/types"
 )
@@ -42,9 +44,21 @@ const errMsgInvalidJob = "Invalid job id specified"
 
 const errMsgAccountingDisabled = "Slurm accounting storage is disabled"
 
+// getLocationName returns the name of the slurm location of a node
+func getLocationName(ctx context.Context, cfg config.Configuration, deploymentID, nodeName string) (string, error) {
+	locationMgr, err := locations.GetManager(cfg)
+	if err != nil {
+		return "", err
+	}
+	return locationMgr.GetLocationNameForNode(ctx, deploymentID, nodeName, infrastructureType)
+}
+
 // getSSHClient returns a SSH client with slurm credentials from node or job configuration provided by the deployment,
 // or by the yorc slurm configuration
-func getSSHClient(cfg config.Configuration, credentials *types.Credential, locationProps config.DynamicMap) (*sshutil.SSHClient, error) {
+//
+// Host keys recorded on first use are scoped by location name.
+// An error event is published for the given deployment if the slurm host presents an unexpected host key.
+func getSSHClient(ctx context.Context, cfg config.Configuration, deploymentID, locationName string, credentials *types.Credential, locationProps config.DynamicMap) (*sshutil.SSHClient, error) {
 	// Check manadatory slurm configuration
 	if err := checkLocationConfig(locationProps); err != nil {
 		log.Printf("Unable to provide SSH client due to:%+v", err)
@@ -57,12 +71,20 @@ func getSSHClient(cfg config.Configuration, credentials *types.Credential, locat
 	if err != nil {
 		return nil, err
 	}
+	// Credentials provided by the deployment take precedence over the location configuration
+	signingPath := credentials.VaultSSHSigningPath
+	if signingPath == "" {
+		signingPath = locationProps.GetStringOrDefault("vault_ssh_signing_path", cfg.SSHVaultSigningPath)
+	}
+	err = sshutil.SignKeysWithVault(keys, signingPath, credentials.User)
+	if err != nil {
+		return nil, err
+	}
 
 	// Get SSH client
 	SSHConfig := &ssh.ClientConfig{
-		User:            credentials.User,
-		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
-		Timeout:         locationProps.GetDurationOrDefault("ssh_connection_timeout", cfg.SSHConnectionTimeout),
+		User:    credentials.User,
+		Timeout: locationProps.GetDurationOrDefault("ssh_connection_timeout", cfg.SSHConnectionTimeout),
 	}
 
 	// Set an authentication method. At least one authentication method
@@ -88,6 +110,20 @@ func getSSHClient(cfg config.Configuration, credentials *types.Credential, locat
 		return nil, err
 	}
 
+	hostKeyOpts := sshutil.HostKeyOptions{
+		Mode:           cfg.SSHHostKeyChecking,
+		KnownHostsFile: cfg.SSHKnownHostsFile,
+		HostKey:        locationProps.GetString("host_key"),
+		Scope:          path.Join("slurm", locationName),
+	}
+	err = sshutil.ConfigureHostKeyVerification(SSHConfig, locationProps.GetString("url"), port, hostKeyOpts, func(err error) {
+		log.Printf("[ERROR] Slurm: %v", err)
+		events.WithContextOptionalFields(ctx).NewLogEntry(events.LogLevelERROR, deploymentID).Registerf("Refusing to connect to slurm host: %v", err)
+	})
+	if err != nil {
+		return nil, err
+	}
+
 	return &sshutil.SSHClient{
 		Config:       SSHConfig,
 		Host:         locationProps.GetString("url"),
@@ -138,6 +174,11 @@ func getUserCredentials(ctx context.Context, locationProps config.DynamicMap, de
 				creds.Keys = make(map[string]string)
 			}
 			creds.Keys["default"] = privateKey
+			certificate := strings.Trim(locationProps.GetString("certificate"), "")
+			if certificate != "" {
+				certificate = config.DefaultConfigTemplateResolver.ResolveValueWithTemplates("slurm.certificate", certificate).(string)
+				creds.Certificates = map[string]string{"default": certificate}
+			}
 		}
 		creds.Token = strings.Trim(locationProps.GetString("password"), "")
 		creds.Token = config.DefaultConfigTemplateResolver.ResolveValueWithTemplates("slurm.password", creds.Token).(string)
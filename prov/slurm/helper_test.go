@@ -249,7 +249,7 @@ func TestPrivateKey(t *testing.T) {
 	}
 	err = checkLocationUserConfig(locationProps)
 	assert.NoError(t, err, "Unexpected error parsing a configuration with private key")
-	_, err = getSSHClient(cfg, &types.Credential{User: "jdoe", Keys: map[string]string{"0": privateKeyContent}}, locationProps)
+	_, err = getSSHClient(context.Background(), cfg, "", "", &types.Credential{User: "jdoe", Keys: map[string]string{"0": privateKeyContent}}, locationProps)
 	assert.NoError(t, err, "Unexpected error getting a ssh client using provided properties with private key")
 
 	// Remove the private key.
@@ -257,9 +257,9 @@ func TestPrivateKey(t *testing.T) {
 	locationProps.Set("private_key", "")
 	err = checkLocationUserConfig(locationProps)
 	assert.Error(t, err, "Expected an error parsing a wrong configuration with no private key and no password defined")
-	_, err = getSSHClient(cfg, &types.Credential{}, locationProps)
+	_, err = getSSHClient(context.Background(), cfg, "", "", &types.Credential{}, locationProps)
 	assert.Error(t, err, "Expected an error getting a ssh client using a configuration with no private key and no password defined")
-	_, err = getSSHClient(cfg, &types.Credential{User: "jdoe"}, locationProps)
+	_, err = getSSHClient(context.Background(), cfg, "", "", &types.Credential{User: "jdoe"}, locationProps)
 	assert.Error(t, err, "Expected an error getting a ssh client using a provided user name property but no private key and no password provided")
 
 	// Setting a wrong private key path
@@ -267,9 +267,9 @@ func TestPrivateKey(t *testing.T) {
 	locationProps.Set("private_key", "invalid_path_to_key.pem")
 	err = checkLocationUserConfig(locationProps)
 	assert.NoError(t, err, "Unexpected error parsing a configuration with private key")
-	_, err = getSSHClient(cfg, &types.Credential{}, locationProps)
+	_, err = getSSHClient(context.Background(), cfg, "", "", &types.Credential{}, locationProps)
 	assert.Error(t, err, "Expected an error getting a ssh client using a configuration with bad private key and no password defined")
-	_, err = getSSHClient(cfg, &types.Credential{User: "jdoe", Keys: map[string]string{"0": "invalid_path_to_key.pem"}}, locationProps)
+	_, err = getSSHClient(context.Background(), cfg, "", "", &types.Credential{User: "jdoe", Keys: map[string]string{"0": "invalid_path_to_key.pem"}}, locationProps)
 	assert.Error(t, err, "Expected an error getting a ssh client using provided credentials with bad private key and no password defined")
 
 	// Slurm Configuration with no private key but a password, the config should be valid
@@ -282,8 +282,17 @@ func TestPrivateKey(t *testing.T) {
 
 	err = checkLocationUserConfig(locationProps)
 	assert.NoError(t, err, "Unexpected error parsing a configuration with password")
-	_, err = getSSHClient(cfg, &types.Credential{User: "jdoe", Token: "test"}, locationProps)
+	_, err = getSSHClient(context.Background(), cfg, "", "", &types.Credential{User: "jdoe", Token: "test"}, locationProps)
 	assert.NoError(t, err, "Unexpected error getting a ssh client using provided credentials with password")
+
+	// Pinned host key
+	locationProps.Set("host_key", "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAILwXtoVy3vlD2F8YkOX8+n42+S2LUbkxkmubgTjtMshj")
+	client, err := getSSHClient(context.Background(), cfg, "", "", &types.Credential{User: "jdoe", Token: "test"}, locationProps)
+	require.NoError(t, err, "Unexpected error getting a ssh client using a pinned host key")
+	assert.Equal(t, []string{"ssh-ed25519"}, client.Config.HostKeyAlgorithms)
+	locationProps.Set("host_key", "not a key")
+	_, err = getSSHClient(context.Background(), cfg, "", "", &types.Credential{User: "jdoe", Token: "test"}, locationProps)
+	assert.Error(t, err, "Expected an error getting a ssh client using an invalid pinned host key")
 }
 
 func TestParseJobIDFromSbatchOut(t *testing.T) {
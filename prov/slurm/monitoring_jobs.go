@@ -246,7 +246,11 @@ func (o *actionOperator) monitorJob(ctx context.Context, cfg config.Configuratio
 		return true, err
 	}
 	// Get a sshClient to connect to slurm client node, and execute slurm commands such as squeue, or system commands such as cp, mv, mkdir, etc.
-	sshClient, err := getSSHClient(cfg, credentials, locationProps)
+	locationName, err := getLocationName(ctx, cfg, deploymentID, nodeName)
+	if err != nil {
+		return true, err
+	}
+	sshClient, err := getSSHClient(ctx, cfg, deploymentID, locationName, credentials, locationProps)
 	if err != nil {
 		return true, err
 	}
@@ -79,6 +79,44 @@ func (e *defaultExecutor) ExecDelegate(ctx context.Context, cfg config.Configura
 	return err
 }
 
+// PlanDelegate generates the Terraform infrastructure of a node in a temporary directory without applying it
+func (e *defaultExecutor) PlanDelegate(ctx context.Context, cfg config.Configuration, deploymentID, nodeName, delegateOperation string) ([]prov.PlannedResource, error) {
+	op := strings.ToLower(delegateOperation)
+	if op != "install" && op != "uninstall" {
+		return nil, errors.Errorf("Unsupported operation %q", delegateOperation)
+	}
+	infrastructurePath, err := ioutil.TempDir("", "yorc-plan-")
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to create infrastructure plan directory")
+	}
+	defer os.RemoveAll(infrastructurePath)
+
+	infraGenerated, _, _, cb, err := e.generator.GenerateTerraformInfraForNode(ctx, cfg, deploymentID, nodeName, infrastructurePath)
+	if cb != nil {
+		defer cb()
+	}
+	if err != nil || !infraGenerated {
+		return nil, err
+	}
+
+	files, err := ioutil.ReadDir(infrastructurePath)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to read generated infrastructure for node %q", nodeName)
+	}
+	resources := make([]prov.PlannedResource, 0, len(files))
+	for _, f := range files {
+		if f.IsDir() || (!strings.HasSuffix(f.Name(), ".tf") && !strings.HasSuffix(f.Name(), ".tf.json")) {
+			continue
+		}
+		content, err := ioutil.ReadFile(filepath.Join(infrastructurePath, f.Name()))
+		if err != nil {
+			return nil, errors.Wrapf(err, "Failed to read generated infrastructure file %q for node %q", f.Name(), nodeName)
+		}
+		resources = append(resources, prov.PlannedResource{Kind: "terraform", Name: f.Name(), Content: string(content)})
+	}
+	return resources, nil
+}
+
 func (e *defaultExecutor) installNode(ctx context.Context, cfg config.Configuration, deploymentID, nodeName, infrastructurePath string, instances []string) error {
 	for _, instance := range instances {
 		err := deployments.SetInstanceStateWithContextualLogs(events.AddLogOptionalFields(ctx, events.LogOptionalFields{events.InstanceID: instance}), deploymentID, nodeName, instance, tosca.NodeStateCreating)
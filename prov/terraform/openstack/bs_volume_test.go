@@ -218,15 +218,13 @@ func testGenerateOSBSVolumeCheckOptionalValues(t *testing.T, srv1 *testutil.Test
 func testComputeBootVolumeWrongSize(t *testing.T, srv1 *testutil.TestServer) {
 	t.Parallel()
 	log.SetDebug(true)
-	ctx := context.Background()
 
 	depID := path.Base(t.Name())
 	yamlName := "testdata/BootVolumeWrongSize.yaml"
+	// The boot volume size doesn't satisfy the data type constraints
 	err := deployments.StoreDeploymentDefinition(context.Background(), depID, yamlName)
-	require.Nil(t, err, "Failed to parse "+yamlName+" definition")
-
-	_, err = computeBootVolume(ctx, depID, "Compute")
-	require.Error(t, err, "Expected a failure to parse %s boot volume definition", yamlName)
+	require.Error(t, err, "Expected a failure to store %s definition", yamlName)
+	require.True(t, deployments.IsConstraintsViolationsError(err), "unexpected error %+v", err)
 }
 
 func testComputeBootVolumeWrongType(t *testing.T, srv1 *testutil.TestServer) {
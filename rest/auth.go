@@ -0,0 +1,233 @@
+// Copyright 2021 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rest
+
+//go:generate go-enum -f=auth.go --lower
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/ystia/yorc/v4/config"
+	"github.com/ystia/yorc/v4/helper/jwtutil"
+	"github.com/ystia/yorc/v4/log"
+)
+
+// Role is an enumeration of the authorization levels on the REST API
+//
+// Roles are ordered, a role grants all the permissions of the previous ones.
+// Viewers are allowed to read resources, operators are also allowed to
+// deploy, undeploy, purge deployments and run workflows, admins are also
+// allowed to manage hosts pools and locations.
+/*
+ENUM(
+viewer
+operator
+admin
+)
+*/
+type Role int
+
+// Identity is the authenticated author of a request
+type Identity struct {
+	Name string
+	Role Role
+}
+
+// An Authenticator authenticates HTTP requests
+type Authenticator interface {
+	// Authenticate returns the Identity corresponding to the given bearer token.
+	//
+	// A nil Identity and a nil error should be returned if the token is not handled by this Authenticator
+	// so the next configured Authenticator could be tried.
+	Authenticate(ctx context.Context, token string) (*Identity, error)
+}
+
+const identityLookupKey contextKey = 2
+
+// IdentityFromContext returns the authenticated Identity of a request if any
+func IdentityFromContext(ctx context.Context) (*Identity, bool) {
+	id, ok := ctx.Value(identityLookupKey).(*Identity)
+	return id, ok
+}
+
+type staticTokensAuthenticator struct {
+	tokens []config.StaticToken
+	roles  []Role
+}
+
+func newStaticTokensAuthenticator(tokens []config.StaticToken) (*staticTokensAuthenticator, error) {
+	a := &staticTokensAuthenticator{tokens: tokens, roles: make([]Role, len(tokens))}
+	for i, t := range tokens {
+		if t.Token == "" {
+			return nil, errors.Errorf("empty token for static token %q", t.Name)
+		}
+		r, err := ParseRole(t.Role)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid role for static token %q", t.Name)
+		}
+		a.roles[i] = r
+	}
+	return a, nil
+}
+
+func (a *staticTokensAuthenticator) Authenticate(ctx context.Context, token string) (*Identity, error) {
+	for i, t := range a.tokens {
+		if subtle.ConstantTimeCompare([]byte(t.Token), []byte(token)) == 1 {
+			return &Identity{Name: t.Name, Role: a.roles[i]}, nil
+		}
+	}
+	return nil, nil
+}
+
+type jwtAuthenticator struct {
+	keySet        *jwtutil.KeySet
+	opts          jwtutil.ValidationOptions
+	rolesClaim    string
+	usernameClaim string
+}
+
+func newJWTAuthenticator(cfg config.JWT) (*jwtAuthenticator, error) {
+	ks, err := jwtutil.LoadKeySetFile(cfg.JWKSFile)
+	if err != nil {
+		return nil, err
+	}
+	a := &jwtAuthenticator{
+		keySet:        ks,
+		opts:          jwtutil.ValidationOptions{Issuer: cfg.Issuer, Audience: cfg.Audience, Leeway: cfg.Leeway},
+		rolesClaim:    cfg.RolesClaim,
+		usernameClaim: cfg.UsernameClaim,
+	}
+	if a.rolesClaim == "" {
+		a.rolesClaim = "roles"
+	}
+	if a.usernameClaim == "" {
+		a.usernameClaim = "sub"
+	}
+	return a, nil
+}
+
+func (a *jwtAuthenticator) Authenticate(ctx context.Context, token string) (*Identity, error) {
+	if strings.Count(token, ".") != 2 {
+		// Not a JWT
+		return nil, nil
+	}
+	claims, err := a.keySet.Validate(token, a.opts)
+	if err != nil {
+		return nil, err
+	}
+	id := &Identity{Name: claims.GetString(a.usernameClaim), Role: -1}
+	// Take the highest known role
+	for _, r := range claims.GetStringSlice(a.rolesClaim) {
+		if role, err := ParseRole(r); err == nil && role > id.Role {
+			id.Role = role
+		}
+	}
+	if id.Role < 0 {
+		return nil, errors.Errorf("no known role found in %q claim of token for %q", a.rolesClaim, id.Name)
+	}
+	return id, nil
+}
+
+// authorizer checks that requests are authenticated and have the required role
+type authorizer struct {
+	authenticators []Authenticator
+	anonymousRole  *Role
+}
+
+func newAuthorizer(cfg config.Authentication) (*authorizer, error) {
+	az := &authorizer{}
+	if len(cfg.StaticTokens) > 0 {
+		a, err := newStaticTokensAuthenticator(cfg.StaticTokens)
+		if err != nil {
+			return nil, err
+		}
+		az.authenticators = append(az.authenticators, a)
+	}
+	if cfg.JWT.JWKSFile != "" {
+		a, err := newJWTAuthenticator(cfg.JWT)
+		if err != nil {
+			return nil, err
+		}
+		az.authenticators = append(az.authenticators, a)
+	}
+	if cfg.AnonymousRole != "" {
+		r, err := ParseRole(cfg.AnonymousRole)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid anonymous role")
+		}
+		az.anonymousRole = &r
+	}
+	return az, nil
+}
+
+func (az *authorizer) enabled() bool {
+	return az != nil && len(az.authenticators) > 0
+}
+
+// handler returns a middleware checking that requests are authenticated with at least the given role
+func (az *authorizer) handler(required Role) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			if !az.enabled() {
+				next.ServeHTTP(w, r)
+				return
+			}
+			id, restErr := az.authenticate(r)
+			if restErr != nil {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="yorc"`)
+				writeError(w, r, restErr)
+				return
+			}
+			if id.Role < required {
+				writeError(w, r, newForbiddenRequest(fmt.Sprintf("Role %q is required for this operation.", required)))
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), identityLookupKey, id)))
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
+func (az *authorizer) authenticate(r *http.Request) (*Identity, *Error) {
+	authz := r.Header.Get("Authorization")
+	if authz == "" {
+		if az.anonymousRole != nil {
+			return &Identity{Name: "anonymous", Role: *az.anonymousRole}, nil
+		}
+		return nil, newUnauthorizedError("Missing bearer token.")
+	}
+	const prefix = "bearer "
+	if len(authz) <= len(prefix) || !strings.EqualFold(authz[:len(prefix)], prefix) {
+		return nil, newUnauthorizedError("Only bearer tokens are supported.")
+	}
+	token := strings.TrimSpace(authz[len(prefix):])
+	for _, a := range az.authenticators {
+		id, err := a.Authenticate(r.Context(), token)
+		if err != nil {
+			log.Debugf("Authentication failure on request [%s] %q: %v", r.Method, r.URL.Path, err)
+			return nil, newUnauthorizedError("Invalid bearer token.")
+		}
+		if id != nil {
+			return id, nil
+		}
+	}
+	return nil, newUnauthorizedError("Invalid bearer token.")
+}
@@ -0,0 +1,65 @@
+// Copyright 2021 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by go-enum
+// DO NOT EDIT!
+
+package rest
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// RoleViewer is a Role of type Viewer
+	RoleViewer Role = iota
+	// RoleOperator is a Role of type Operator
+	RoleOperator
+	// RoleAdmin is a Role of type Admin
+	RoleAdmin
+)
+
+const _RoleName = "vieweroperatoradmin"
+
+var _RoleMap = map[Role]string{
+	0: _RoleName[0:6],
+	1: _RoleName[6:14],
+	2: _RoleName[14:19],
+}
+
+// String implements the Stringer interface.
+func (x Role) String() string {
+	if str, ok := _RoleMap[x]; ok {
+		return str
+	}
+	return fmt.Sprintf("Role(%d)", x)
+}
+
+var _RoleValue = map[string]Role{
+	_RoleName[0:6]:                    0,
+	strings.ToLower(_RoleName[0:6]):   0,
+	_RoleName[6:14]:                   1,
+	strings.ToLower(_RoleName[6:14]):  1,
+	_RoleName[14:19]:                  2,
+	strings.ToLower(_RoleName[14:19]): 2,
+}
+
+// ParseRole attempts to convert a string to a Role
+func ParseRole(name string) (Role, error) {
+	if x, ok := _RoleValue[name]; ok {
+		return x, nil
+	}
+	return Role(0), fmt.Errorf("%s is not a valid Role", name)
+}
@@ -0,0 +1,163 @@
+// Copyright 2021 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rest
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ystia/yorc/v4/config"
+)
+
+func encodeJWTSegment(t *testing.T, v interface{}) string {
+	b, err := json.Marshal(v)
+	require.NoError(t, err)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func signTestJWT(t *testing.T, key *rsa.PrivateKey, claims map[string]interface{}) string {
+	signed := encodeJWTSegment(t, map[string]string{"alg": "RS256", "kid": "test"}) + "." + encodeJWTSegment(t, claims)
+	h := crypto.SHA256.New()
+	h.Write([]byte(signed))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, h.Sum(nil))
+	require.NoError(t, err)
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func writeTestJWKS(t *testing.T, key *rsa.PrivateKey) string {
+	jwks := map[string]interface{}{
+		"keys": []map[string]string{
+			{
+				"kty": "RSA",
+				"kid": "test",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			},
+		},
+	}
+	b, err := json.Marshal(jwks)
+	require.NoError(t, err)
+	p := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, ioutil.WriteFile(p, b, 0600))
+	return p
+}
+
+func TestNewAuthorizerErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.Authentication
+	}{
+		{"EmptyToken", config.Authentication{StaticTokens: []config.StaticToken{{Name: "ci", Role: "admin"}}}},
+		{"InvalidTokenRole", config.Authentication{StaticTokens: []config.StaticToken{{Name: "ci", Token: "secret", Role: "root"}}}},
+		{"InvalidAnonymousRole", config.Authentication{StaticTokens: []config.StaticToken{{Name: "ci", Token: "secret", Role: "admin"}}, AnonymousRole: "guest"}},
+		{"MissingJWKSFile", config.Authentication{JWT: config.JWT{JWKSFile: filepath.Join(t.TempDir(), "missing.json")}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newAuthorizer(tt.cfg)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestAuthorizerHandler(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	jwksFile := writeTestJWKS(t, key)
+	now := time.Now()
+	jwtClaims := func(roles ...string) map[string]interface{} {
+		return map[string]interface{}{"sub": "jdoe", "exp": now.Add(time.Hour).Unix(), "groups": roles}
+	}
+
+	authCfg := config.Authentication{
+		StaticTokens: []config.StaticToken{
+			{Name: "ci", Token: "operator-secret", Role: "operator"},
+			{Name: "admin", Token: "admin-secret", Role: "admin"},
+		},
+		JWT: config.JWT{JWKSFile: jwksFile, RolesClaim: "groups"},
+	}
+	anonymousCfg := authCfg
+	anonymousCfg.AnonymousRole = "viewer"
+
+	tests := []struct {
+		name          string
+		cfg           config.Authentication
+		required      Role
+		authorization string
+		wantStatus    int
+		wantIdentity  *Identity
+	}{
+		{"Disabled", config.Authentication{}, RoleAdmin, "", http.StatusOK, nil},
+		{"MissingToken", authCfg, RoleViewer, "", http.StatusUnauthorized, nil},
+		{"AnonymousAllowed", anonymousCfg, RoleViewer, "", http.StatusOK, &Identity{Name: "anonymous", Role: RoleViewer}},
+		{"AnonymousForbidden", anonymousCfg, RoleOperator, "", http.StatusForbidden, nil},
+		{"BasicAuth", authCfg, RoleViewer, "Basic Zm9vOmJhcg==", http.StatusUnauthorized, nil},
+		{"UnknownToken", authCfg, RoleViewer, "Bearer unknown", http.StatusUnauthorized, nil},
+		{"StaticToken", authCfg, RoleOperator, "Bearer operator-secret", http.StatusOK, &Identity{Name: "ci", Role: RoleOperator}},
+		{"StaticTokenLowerCaseScheme", authCfg, RoleViewer, "bearer operator-secret", http.StatusOK, &Identity{Name: "ci", Role: RoleOperator}},
+		{"StaticTokenInsufficientRole", authCfg, RoleAdmin, "Bearer operator-secret", http.StatusForbidden, nil},
+		{"StaticTokenAdmin", authCfg, RoleAdmin, "Bearer admin-secret", http.StatusOK, &Identity{Name: "admin", Role: RoleAdmin}},
+		{"JWTHighestRole", authCfg, RoleAdmin, "Bearer " + signTestJWT(t, key, jwtClaims("viewer", "admin", "unknown")), http.StatusOK, &Identity{Name: "jdoe", Role: RoleAdmin}},
+		{"JWTInsufficientRole", authCfg, RoleOperator, "Bearer " + signTestJWT(t, key, jwtClaims("viewer")), http.StatusForbidden, nil},
+		{"JWTNoKnownRole", authCfg, RoleViewer, "Bearer " + signTestJWT(t, key, jwtClaims("unknown")), http.StatusUnauthorized, nil},
+		{"JWTExpired", authCfg, RoleViewer, "Bearer " + signTestJWT(t, key, map[string]interface{}{"sub": "jdoe", "exp": now.Add(-time.Hour).Unix(), "groups": "admin"}), http.StatusUnauthorized, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			az, err := newAuthorizer(tt.cfg)
+			require.NoError(t, err)
+			var gotIdentity *Identity
+			h := az.handler(tt.required)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotIdentity, _ = IdentityFromContext(r.Context())
+				w.WriteHeader(http.StatusOK)
+			}))
+			req := httptest.NewRequest("GET", "/deployments", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			assert.Equal(t, tt.wantIdentity, gotIdentity)
+			if tt.wantStatus == http.StatusUnauthorized {
+				assert.Equal(t, `Bearer realm="yorc"`, rec.Header().Get("WWW-Authenticate"))
+			}
+		})
+	}
+}
+
+func TestParseRole(t *testing.T) {
+	for _, r := range []Role{RoleViewer, RoleOperator, RoleAdmin} {
+		parsed, err := ParseRole(r.String())
+		require.NoError(t, err)
+		assert.Equal(t, r, parsed)
+	}
+	_, err := ParseRole("root")
+	assert.Error(t, err)
+	assert.True(t, RoleViewer < RoleOperator && RoleOperator < RoleAdmin, "roles should be ordered")
+}
@@ -0,0 +1,162 @@
+// Copyright 2021 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rest
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"path"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/pkg/errors"
+
+	"github.com/ystia/yorc/v4/log"
+	"github.com/ystia/yorc/v4/prov/monitoring"
+)
+
+func (s *Server) listChecksHandler(w http.ResponseWriter, r *http.Request) {
+	s.listChecks(w, r, r.URL.Query().Get("deployment"))
+}
+
+func (s *Server) listDeploymentChecksHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	params := ctx.Value(paramsLookupKey).(httprouter.Params)
+	deploymentID := params.ByName("id")
+
+	if !checkDeploymentExists(ctx, w, r, deploymentID) {
+		return
+	}
+	s.listChecks(w, r, deploymentID)
+}
+
+func (s *Server) listChecks(w http.ResponseWriter, r *http.Request, deploymentID string) {
+	filter, ok := getCheckReportsFilter(w, r, deploymentID)
+	if !ok {
+		return
+	}
+	reports, err := monitoring.ListCheckReports(filter)
+	if err != nil {
+		log.Panic(err)
+	}
+	if len(reports) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	col := CheckReportsCollection{Checks: make([]CheckReport, len(reports))}
+	for i := range reports {
+		col.Checks[i] = newCheckReportRepresentation(&reports[i])
+	}
+	encodeJSONResponse(w, r, col)
+}
+
+func (s *Server) getCheckHandler(w http.ResponseWriter, r *http.Request) {
+	report := getNodeInstanceCheckReport(w, r)
+	if report == nil {
+		return
+	}
+	encodeJSONResponse(w, r, newCheckReportRepresentation(report))
+}
+
+func (s *Server) updateCheckHandler(w http.ResponseWriter, r *http.Request) {
+	report := getNodeInstanceCheckReport(w, r)
+	if report == nil {
+		return
+	}
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		log.Panic(err)
+	}
+	var req CheckRequest
+	if err = json.Unmarshal(body, &req); err != nil {
+		writeError(w, r, newBadRequestError(err))
+		return
+	}
+	if req.Enabled == nil {
+		writeError(w, r, newBadRequestMessage(`"enabled" is required`))
+		return
+	}
+	if *req.Enabled {
+		if req.DisabledUntil != nil {
+			writeError(w, r, newBadRequestMessage(`"disabled_until" can't be set when enabling a check`))
+			return
+		}
+		err = monitoring.EnableCheck(r.Context(), report.DeploymentID, report.NodeName, report.Instance)
+	} else {
+		if req.DisabledUntil != nil && !req.DisabledUntil.After(time.Now()) {
+			writeError(w, r, newBadRequestMessage(`"disabled_until" should be in the future`))
+			return
+		}
+		err = monitoring.DisableCheck(r.Context(), report.DeploymentID, report.NodeName, report.Instance, req.DisabledUntil)
+	}
+	if err != nil {
+		log.Panic(err)
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// getNodeInstanceCheckReport returns the report of the check of the node instance given in the request path
+// if it exists otherwise it writes a not found error and returns nil
+func getNodeInstanceCheckReport(w http.ResponseWriter, r *http.Request) *monitoring.CheckReport {
+	params := r.Context().Value(paramsLookupKey).(httprouter.Params)
+	report, err := monitoring.GetCheckReport(params.ByName("id"), params.ByName("nodeName"), params.ByName("instanceId"))
+	if err != nil {
+		log.Panic(err)
+	}
+	if report == nil {
+		writeError(w, r, errNotFound)
+	}
+	return report
+}
+
+// getCheckReportsFilter returns a filter on checks reports according to the node, instance and status query parameters
+func getCheckReportsFilter(w http.ResponseWriter, r *http.Request, deploymentID string) (monitoring.CheckFilterFunc, bool) {
+	values := r.URL.Query()
+	nodeName := values.Get("node")
+	instance := values.Get("instance")
+	var status *monitoring.CheckStatus
+	if s := values.Get("status"); s != "" {
+		st, err := monitoring.ParseCheckStatus(s)
+		if err != nil {
+			writeError(w, r, newBadRequestParameter("status", errors.Wrap(err, "invalid check status")))
+			return nil, false
+		}
+		status = &st
+	}
+	return func(cr monitoring.CheckReport) bool {
+		return (deploymentID == "" || cr.DeploymentID == deploymentID) &&
+			(nodeName == "" || cr.NodeName == nodeName) &&
+			(instance == "" || cr.Instance == instance) &&
+			(status == nil || cr.Status == *status)
+	}, true
+}
+
+func newCheckReportRepresentation(report *monitoring.CheckReport) CheckReport {
+	instancePath := path.Join("/deployments", report.DeploymentID, "nodes", report.NodeName, "instances", report.Instance)
+	return CheckReport{
+		DeploymentID:  report.DeploymentID,
+		NodeName:      report.NodeName,
+		Instance:      report.Instance,
+		Status:        report.Status.String(),
+		Disabled:      report.Disabled,
+		DisabledUntil: report.DisabledUntil,
+		Links: []AtomLink{
+			newAtomLink(LinkRelSelf, path.Join("/deployments", report.DeploymentID, "checks", report.NodeName, report.Instance)),
+			newAtomLink(LinkRelInstance, instancePath),
+		},
+	}
+}
@@ -0,0 +1,126 @@
+// Copyright 2021 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rest
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/hashicorp/consul/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ystia/yorc/v4/config"
+	"github.com/ystia/yorc/v4/helper/consulutil"
+)
+
+func testCheckHandlers(t *testing.T, client *api.Client, cfg config.Configuration, srv *testutil.TestServer) {
+	reportsPath := path.Join(consulutil.MonitoringKVPrefix, "reports")
+	srv.PopulateKV(t, map[string][]byte{
+		path.Join(reportsPath, "checksDep:Compute:0", "status"):      []byte("PASSING"),
+		path.Join(reportsPath, "checksDep:Compute:1", "status"):      []byte("CRITICAL"),
+		path.Join(reportsPath, "checksOtherDep:Compute:0", "status"): []byte("PASSING"),
+	})
+
+	listChecks := func(t *testing.T, url string) (int, CheckReportsCollection) {
+		req := httptest.NewRequest("GET", url, nil)
+		req.Header.Add("Accept", mimeTypeApplicationJSON)
+		resp := newTestHTTPRouter(client, cfg, req)
+		var col CheckReportsCollection
+		if resp.StatusCode == http.StatusOK {
+			body, err := ioutil.ReadAll(resp.Body)
+			require.NoError(t, err)
+			require.NoError(t, json.Unmarshal(body, &col))
+		}
+		return resp.StatusCode, col
+	}
+	patchCheck := func(t *testing.T, url string, body string) int {
+		req := httptest.NewRequest("PATCH", url, bytes.NewBufferString(body))
+		req.Header.Add("Content-Type", mimeTypeApplicationJSON)
+		return newTestHTTPRouter(client, cfg, req).StatusCode
+	}
+
+	t.Run("testListChecks", func(t *testing.T) {
+		status, col := listChecks(t, "/checks")
+		require.Equal(t, http.StatusOK, status)
+		require.Len(t, col.Checks, 3)
+
+		status, col = listChecks(t, "/deployments/checksDep/checks")
+		require.Equal(t, http.StatusOK, status)
+		require.Len(t, col.Checks, 2)
+		assert.Equal(t, "0", col.Checks[0].Instance)
+		assert.Equal(t, "/deployments/checksDep/checks/Compute/0", col.Checks[0].Links[0].Href)
+
+		status, col = listChecks(t, "/checks?deployment=checksDep&status=critical")
+		require.Equal(t, http.StatusOK, status)
+		require.Len(t, col.Checks, 1)
+		assert.Equal(t, "1", col.Checks[0].Instance)
+
+		status, _ = listChecks(t, "/deployments/checksDep/checks?node=Other")
+		assert.Equal(t, http.StatusNoContent, status)
+
+		status, _ = listChecks(t, "/checks?status=unknown")
+		assert.Equal(t, http.StatusBadRequest, status)
+	})
+
+	t.Run("testGetCheck", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/deployments/checksDep/checks/Compute/1", nil)
+		req.Header.Add("Accept", mimeTypeApplicationJSON)
+		resp := newTestHTTPRouter(client, cfg, req)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var report CheckReport
+		body, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(body, &report))
+		assert.Equal(t, "CRITICAL", report.Status)
+		assert.False(t, report.Disabled)
+
+		req = httptest.NewRequest("GET", "/deployments/checksDep/checks/Compute/5", nil)
+		req.Header.Add("Accept", mimeTypeApplicationJSON)
+		resp = newTestHTTPRouter(client, cfg, req)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("testUpdateCheck", func(t *testing.T) {
+		checkURL := "/deployments/checksDep/checks/Compute/0"
+		assert.Equal(t, http.StatusBadRequest, patchCheck(t, checkURL, `{}`))
+		assert.Equal(t, http.StatusBadRequest, patchCheck(t, checkURL, `{"enabled":true,"disabled_until":"2100-01-01T00:00:00Z"}`))
+		assert.Equal(t, http.StatusBadRequest, patchCheck(t, checkURL, `{"enabled":false,"disabled_until":"2001-01-01T00:00:00Z"}`))
+		assert.Equal(t, http.StatusNotFound, patchCheck(t, "/deployments/checksDep/checks/Compute/5", `{"enabled":false}`))
+
+		until := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+		untilJSON, err := json.Marshal(until)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, patchCheck(t, checkURL, `{"enabled":false,"disabled_until":`+string(untilJSON)+`}`))
+		_, col := listChecks(t, "/deployments/checksDep/checks?instance=0")
+		require.Len(t, col.Checks, 1)
+		assert.True(t, col.Checks[0].Disabled)
+		require.NotNil(t, col.Checks[0].DisabledUntil)
+		assert.True(t, until.Equal(*col.Checks[0].DisabledUntil))
+
+		require.Equal(t, http.StatusOK, patchCheck(t, checkURL, `{"enabled":true}`))
+		_, col = listChecks(t, "/deployments/checksDep/checks?instance=0")
+		require.Len(t, col.Checks, 1)
+		assert.False(t, col.Checks[0].Disabled)
+		assert.Nil(t, col.Checks[0].DisabledUntil)
+	})
+}
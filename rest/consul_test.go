@@ -94,5 +94,8 @@ func TestRunConsulRestPackageTests(t *testing.T) {
 		t.Run("testDeploymentTaskHandlers", func(t *testing.T) {
 			testDeploymentTaskHandlers(t, client, cfg, srv)
 		})
+		t.Run("testCheckHandlers", func(t *testing.T) {
+			testCheckHandlers(t, client, cfg, srv)
+		})
 	})
 }
@@ -19,8 +19,10 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"strings"
 
 	"github.com/ystia/yorc/v4/deployments"
+	"github.com/ystia/yorc/v4/tasks"
 )
 
 func checkBlockingOperationOnDeployment(ctx context.Context, deploymentID string, w http.ResponseWriter, r *http.Request) bool {
@@ -34,3 +36,32 @@ func checkBlockingOperationOnDeployment(ctx context.Context, deploymentID string
 	}
 	return true
 }
+
+// getTaskPriority returns the priority requested for a new task of the given type using the "priority" query parameter
+// or the default priority of this type of task.
+//
+// It writes a bad request error and returns false if the requested priority is invalid.
+func getTaskPriority(w http.ResponseWriter, r *http.Request, taskType tasks.TaskType) (tasks.TaskPriority, bool) {
+	value := r.URL.Query().Get("priority")
+	if value == "" {
+		return tasks.DefaultTaskPriority(taskType), true
+	}
+	priority, err := tasks.ParseTaskPriority(strings.ToUpper(value))
+	if err != nil {
+		writeError(w, r, newBadRequestParameter("priority", fmt.Errorf("priority query parameter must be one of low, normal or high, got %q", value)))
+		return priority, false
+	}
+	return priority, true
+}
+
+// isRollbackOnFailure returns true if the "rollbackOnFailure" query parameter requests to rollback a workflow on failure.
+//
+// It writes a bad request error and returns false as second value if the parameter is not a boolean.
+func isRollbackOnFailure(w http.ResponseWriter, r *http.Request) (bool, bool) {
+	rollback, err := getBoolQueryParam(r, tasks.TaskDataRollbackOnFailure)
+	if err != nil {
+		writeError(w, r, newBadRequestMessage("rollbackOnFailure query parameter must be a boolean value"))
+		return false, false
+	}
+	return rollback, true
+}
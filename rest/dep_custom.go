@@ -53,6 +53,11 @@ func (s *Server) newCustomCommandHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	priority, ok := getTaskPriority(w, r, tasks.TaskTypeCustomCommand)
+	if !ok {
+		return
+	}
+
 	body, err := ioutil.ReadAll(r.Body)
 	if err != nil {
 		log.Panic(err)
@@ -109,7 +114,7 @@ func (s *Server) newCustomCommandHandler(w http.ResponseWriter, r *http.Request)
 		data[path.Join("inputs", name)] = ccRequest.Inputs[name].String()
 	}
 
-	taskID, err := s.tasksCollector.RegisterTaskWithData(id, tasks.TaskTypeCustomCommand, data)
+	taskID, err := s.tasksCollector.RegisterTaskWithPriority(id, tasks.TaskTypeCustomCommand, data, priority)
 	if err != nil {
 		if ok, _ := tasks.IsAnotherLivingTaskAlreadyExistsError(err); ok {
 			writeError(w, r, newBadRequestError(err))
@@ -66,6 +66,14 @@ func (s *Server) scaleHandler(w http.ResponseWriter, r *http.Request) {
 		writeError(w, r, newBadRequestError(errors.New("You need to provide a 'delta' parameter")))
 		return
 	}
+	taskType := tasks.TaskTypeScaleOut
+	if instancesDelta < 0 {
+		taskType = tasks.TaskTypeScaleIn
+	}
+	priority, ok := getTaskPriority(w, r, taskType)
+	if !ok {
+		return
+	}
 
 	exists, err := deployments.DoesNodeExist(ctx, id, nodeName)
 	if err != nil {
@@ -75,7 +83,6 @@ func (s *Server) scaleHandler(w http.ResponseWriter, r *http.Request) {
 		writeError(w, r, errNotFound)
 		return
 	}
-	var ok bool
 	if ok, err = deployments.HasScalableCapability(ctx, id, nodeName); err != nil {
 		log.Panic(err)
 	} else if !ok {
@@ -86,9 +93,9 @@ func (s *Server) scaleHandler(w http.ResponseWriter, r *http.Request) {
 	log.Debugf("Scaling %d instances of node %q", instancesDelta, nodeName)
 	var taskID string
 	if instancesDelta > 0 {
-		taskID, err = s.scaleOut(ctx, id, nodeName, uint32(instancesDelta))
+		taskID, err = s.scaleOut(ctx, id, nodeName, uint32(instancesDelta), priority)
 	} else {
-		taskID, err = s.scaleIn(ctx, id, nodeName, uint32(-instancesDelta))
+		taskID, err = s.scaleIn(ctx, id, nodeName, uint32(-instancesDelta), priority)
 	}
 	if err != nil {
 		if ok, _ := tasks.IsAnotherLivingTaskAlreadyExistsError(err); ok {
@@ -105,7 +112,7 @@ func (s *Server) scaleHandler(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusAccepted)
 }
 
-func (s *Server) scaleOut(ctx context.Context, id, nodeName string, instancesDelta uint32) (string, error) {
+func (s *Server) scaleOut(ctx context.Context, id, nodeName string, instancesDelta uint32, priority tasks.TaskPriority) (string, error) {
 	maxInstances, err := deployments.GetMaxNbInstancesForNode(ctx, id, nodeName)
 	if err != nil {
 		return "", err
@@ -128,10 +135,10 @@ func (s *Server) scaleOut(ctx context.Context, id, nodeName string, instancesDel
 	data["instancesDelta"] = strconv.Itoa(int(instancesDelta))
 	data["workflowName"] = "install"
 	data["nodeName"] = nodeName
-	return s.tasksCollector.RegisterTaskWithData(id, tasks.TaskTypeScaleOut, data)
+	return s.tasksCollector.RegisterTaskWithPriority(id, tasks.TaskTypeScaleOut, data, priority)
 }
 
-func (s *Server) scaleIn(ctx context.Context, id, nodeName string, instancesDelta uint32) (string, error) {
+func (s *Server) scaleIn(ctx context.Context, id, nodeName string, instancesDelta uint32, priority tasks.TaskPriority) (string, error) {
 	minInstances, err := deployments.GetMinNbInstancesForNode(ctx, id, nodeName)
 	if err != nil {
 		return "", err
@@ -161,6 +168,6 @@ func (s *Server) scaleIn(ctx context.Context, id, nodeName string, instancesDelt
 	// Add related workflow
 	data["workflowName"] = "uninstall"
 
-	return s.tasksCollector.RegisterTaskWithData(id, tasks.TaskTypeScaleIn, data)
+	return s.tasksCollector.RegisterTaskWithPriority(id, tasks.TaskTypeScaleIn, data, priority)
 
 }
@@ -113,6 +113,15 @@ func (s *Server) getTaskHandler(w http.ResponseWriter, r *http.Request) {
 		log.Panic(err)
 	}
 	task.ErrorMessage = taskErrorMessage
+
+	task.RollbackTaskID, err = tasks.GetRollbackTaskID(taskID)
+	if err != nil {
+		log.Panic(err)
+	}
+	task.RollbackOf, err = tasks.GetRolledBackTaskID(taskID)
+	if err != nil {
+		log.Panic(err)
+	}
 	encodeJSONResponse(w, r, task)
 }
 
@@ -208,6 +217,12 @@ func (s *Server) resumeTaskHandler(w http.ResponseWriter, r *http.Request) {
 		writeError(w, r, newBadRequestError(errors.Errorf("Cannot resume a task with status %q. Only task in %q status can be resumed.", taskStatus.String(), tasks.TaskStatusFAILED.String())))
 		return
 	}
+	if rolledBackTaskID, err := tasks.GetRolledBackTaskID(taskID); err != nil {
+		log.Panic(err)
+	} else if rolledBackTaskID != "" {
+		writeError(w, r, newBadRequestError(errors.Errorf("Cannot resume task %q as it is a rollback task, its workflow is removed once it ended.", taskID)))
+		return
+	}
 
 	if err := s.tasksCollector.ResumeTask(ctx, taskID); err != nil {
 		log.Panic(err)
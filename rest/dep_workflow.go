@@ -15,6 +15,7 @@
 package rest
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -29,6 +30,7 @@ import (
 	"github.com/ystia/yorc/v4/helper/collections"
 	"github.com/ystia/yorc/v4/log"
 	"github.com/ystia/yorc/v4/tasks"
+	"github.com/ystia/yorc/v4/tasks/workflow"
 )
 
 func (s *Server) newWorkflowHandler(w http.ResponseWriter, r *http.Request) {
@@ -38,6 +40,19 @@ func (s *Server) newWorkflowHandler(w http.ResponseWriter, r *http.Request) {
 	deploymentID := params.ByName("id")
 	workflowName := params.ByName("workflowName")
 
+	dryRun, ok := isDryRun(w, r)
+	if !ok {
+		return
+	}
+	priority, ok := getTaskPriority(w, r, tasks.TaskTypeCustomWorkflow)
+	if !ok {
+		return
+	}
+	rollbackOnFailure, ok := isRollbackOnFailure(w, r)
+	if !ok {
+		return
+	}
+
 	dExits, err := deployments.DoesDeploymentExists(ctx, deploymentID)
 	if err != nil {
 		log.Panicf("%v", err)
@@ -71,12 +86,16 @@ func (s *Server) newWorkflowHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	data := make(map[string]string)
+	planOpts := workflow.PlanOptions{NodesInstances: make(map[string][]string), Inputs: make(map[string]string)}
 	data["workflowName"] = workflowName
 	if _, ok := r.URL.Query()["continueOnError"]; ok {
 		data["continueOnError"] = strconv.FormatBool(true)
 	} else {
 		data["continueOnError"] = strconv.FormatBool(false)
 	}
+	if rollbackOnFailure {
+		data[tasks.TaskDataRollbackOnFailure] = strconv.FormatBool(true)
+	}
 	// Get instances selection if provided in the request body
 	body, err := ioutil.ReadAll(r.Body)
 	if err != nil {
@@ -108,36 +127,32 @@ func (s *Server) newWorkflowHandler(w http.ResponseWriter, r *http.Request) {
 			}
 			instances := strings.Join(nodeInstances.Instances, ",")
 			data["nodes/"+nodeName] = instances
+			planOpts.NodesInstances[nodeName] = nodeInstances.Instances
 		}
 
 		// Adding workflow inputs in task data
 		for inputName, inputValue := range wfRequest.Inputs {
 			data[path.Join("inputs", inputName)] = fmt.Sprintf("%v", inputValue)
+			planOpts.Inputs[inputName] = fmt.Sprintf("%v", inputValue)
 		}
 
 		// Check all workflow required input parameters have a value
-		wf, err := deployments.GetWorkflow(ctx, deploymentID, workflowName)
-		if err != nil {
-			log.Panic(err)
-		}
-		if wf == nil {
-			log.Panic(errors.Errorf("Can't check inputs of workflow %q in deployment %q, workflow definition not found", workflowName, deploymentID))
+		if missingInput := getMissingWorkflowInput(ctx, deploymentID, workflowName, wfRequest.Inputs); missingInput != "" {
+			writeError(w, r, newBadRequestParameter("inputs", errors.Errorf("Missing value for required workflow input parameter %s", missingInput)))
+			return
 		}
-		for inputName, def := range wf.Inputs {
-			// A property is considered as required by default, unless def.Required
-			// is set to false
-			if (def.Required == nil || *def.Required) && def.Default == nil {
-				_, found := wfRequest.Inputs[inputName]
-				if !found {
-					writeError(w, r, newBadRequestParameter("inputs", errors.Errorf("Missing value for required workflow input parameter %s", inputName)))
-					return
-				}
-			}
+		if !checkWorkflowInputsConstraints(ctx, w, r, deploymentID, workflowName, wfRequest.Inputs) {
+			return
 		}
 
 	}
 
-	taskID, err := s.tasksCollector.RegisterTaskWithData(deploymentID, tasks.TaskTypeCustomWorkflow, data)
+	if dryRun {
+		s.planWorkflow(ctx, w, r, deploymentID, workflowName, planOpts)
+		return
+	}
+
+	taskID, err := s.tasksCollector.RegisterTaskWithPriority(deploymentID, tasks.TaskTypeCustomWorkflow, data, priority)
 	if err != nil {
 		if ok, _ := tasks.IsAnotherLivingTaskAlreadyExistsError(err); ok {
 			writeError(w, r, newBadRequestError(err))
@@ -212,3 +227,39 @@ func (s *Server) getWorkflowHandler(w http.ResponseWriter, r *http.Request) {
 	}
 	encodeJSONResponse(w, r, Workflow{Name: workflowName, Workflow: *wf})
 }
+
+// getMissingWorkflowInput returns the name of a required workflow input parameter that has no value
+// in the given inputs or an empty string if all required inputs have a value
+func getMissingWorkflowInput(ctx context.Context, deploymentID, workflowName string, inputs map[string]interface{}) string {
+	wf, err := deployments.GetWorkflow(ctx, deploymentID, workflowName)
+	if err != nil {
+		log.Panic(err)
+	}
+	if wf == nil {
+		log.Panic(errors.Errorf("Can't check inputs of workflow %q in deployment %q, workflow definition not found", workflowName, deploymentID))
+	}
+	for inputName, def := range wf.Inputs {
+		// A property is considered as required by default, unless def.Required
+		// is set to false
+		if (def.Required == nil || *def.Required) && def.Default == nil {
+			if _, found := inputs[inputName]; !found {
+				return inputName
+			}
+		}
+	}
+	return ""
+}
+
+// checkWorkflowInputsConstraints checks that workflow inputs satisfy the constraints of their definitions
+// otherwise it writes a bad request error listing all violations and returns false
+func checkWorkflowInputsConstraints(ctx context.Context, w http.ResponseWriter, r *http.Request, deploymentID, workflowName string, inputs map[string]interface{}) bool {
+	err := deployments.CheckWorkflowInputsConstraints(ctx, deploymentID, workflowName, inputs)
+	if err != nil {
+		if deployments.IsConstraintsViolationsError(err) {
+			writeError(w, r, newBadRequestParameter("inputs", err))
+			return false
+		}
+		log.Panic(err)
+	}
+	return true
+}
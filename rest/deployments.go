@@ -129,6 +129,19 @@ func unzipArchiveGetTopology(workingDir, deploymentID string, r *http.Request) (
 
 func (s *Server) newDeploymentHandler(w http.ResponseWriter, r *http.Request) {
 
+	dryRun, ok := isDryRun(w, r)
+	if !ok {
+		return
+	}
+	priority, ok := getTaskPriority(w, r, tasks.TaskTypeDeploy)
+	if !ok {
+		return
+	}
+	rollbackOnFailure, ok := isRollbackOnFailure(w, r)
+	if !ok {
+		return
+	}
+
 	var uid string
 	if r.Method == http.MethodPut {
 		var params httprouter.Params
@@ -166,6 +179,10 @@ func (s *Server) newDeploymentHandler(w http.ResponseWriter, r *http.Request) {
 	} else {
 		uid = fmt.Sprint(uuid.NewV4())
 	}
+	if dryRun {
+		s.planDeployment(w, r, uid)
+		return
+	}
 	log.Printf("Analyzing deployment %s\n", uid)
 
 	yamlFile, archiveErr := unzipArchiveGetTopology(s.config.WorkingDirectory, uid, r)
@@ -196,12 +213,23 @@ func (s *Server) newDeploymentHandler(w http.ResponseWriter, r *http.Request) {
 
 	if err := deployments.StoreDeploymentDefinition(r.Context(), uid, yamlFile); err != nil {
 		log.Debugf("ERROR: %+v", err)
+		if deployments.IsConstraintsViolationsError(err) {
+			// The deployment is rejected, remove what was stored
+			if purgeErr := operations.PurgeDeployment(ctx, uid, s.config.WorkingDirectory, true, true); purgeErr != nil {
+				log.Printf("[WARNING] failed to purge rejected deployment %q: %v", uid, purgeErr)
+			}
+			writeError(w, r, newBadRequestError(err))
+			return
+		}
 		log.Panic(err)
 	}
 	data := map[string]string{
 		"workflowName": "install",
 	}
-	taskID, err := s.tasksCollector.RegisterTaskWithData(uid, tasks.TaskTypeDeploy, data)
+	if rollbackOnFailure {
+		data[tasks.TaskDataRollbackOnFailure] = strconv.FormatBool(true)
+	}
+	taskID, err := s.tasksCollector.RegisterTaskWithPriority(uid, tasks.TaskTypeDeploy, data, priority)
 	if err != nil {
 		if ok, _ := tasks.IsAnotherLivingTaskAlreadyExistsError(err); ok {
 			writeError(w, r, newBadRequestError(err))
@@ -277,6 +305,10 @@ func (s *Server) deleteDeploymentHandler(w http.ResponseWriter, r *http.Request)
 	} else {
 		taskType = tasks.TaskTypeUnDeploy
 	}
+	priority, ok := getTaskPriority(w, r, taskType)
+	if !ok {
+		return
+	}
 
 	if taskType == tasks.TaskTypeUnDeploy {
 		status, err := deployments.GetDeploymentStatus(ctx, id)
@@ -298,7 +330,7 @@ func (s *Server) deleteDeploymentHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 	data["continueOnError"] = strconv.FormatBool(!stopOnError)
-	if taskID, err := s.tasksCollector.RegisterTaskWithData(id, taskType, data); err != nil {
+	if taskID, err := s.tasksCollector.RegisterTaskWithPriority(id, taskType, data, priority); err != nil {
 		log.Debugf("register task has returned an err:%q", err.Error())
 		if ok, _ := tasks.IsAnotherLivingTaskAlreadyExistsError(err); ok {
 			log.Debugln("another task is living")
@@ -17,11 +17,14 @@
 package rest
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"github.com/ystia/yorc/v4/config"
 	"reflect"
 
+	"io"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
@@ -31,6 +34,9 @@ import (
 	"github.com/hashicorp/consul/api"
 	"github.com/hashicorp/consul/testutil"
 	"github.com/stretchr/testify/require"
+	"github.com/ystia/yorc/v4/deployments"
+	"github.com/ystia/yorc/v4/helper/consulutil"
+	"github.com/ystia/yorc/v4/helper/ziputil"
 	ytestutil "github.com/ystia/yorc/v4/testutil"
 )
 
@@ -40,38 +46,60 @@ func testUpdateDeployments(t *testing.T, client *api.Client, cfg config.Configur
 	type result struct {
 		statusCode int
 		errors     *Errors
+		status     deployments.DeploymentStatus
 	}
 
+	sameTopology, err := ziputil.ZipPath("testdata/testSimpleTopology.yaml")
+	require.NoError(t, err)
+	noTopology, err := ziputil.ZipPath("testdata/ca-cert.pem")
+	require.NoError(t, err)
+
 	tests := []struct {
 		name         string
 		deploymentID string
+		status       string
+		csar         []byte
 		want         *result
 	}{
-		{"updateExistingDep", depID, &result{statusCode: http.StatusForbidden, errors: &Errors{[]*Error{newForbiddenRequest(fmt.Sprintf("Trying to update deployment %q on an open source version. Updates are supported only on premium versions.", depID))}}}},
-		{"updateNotExistingDep", "noDeployment", &result{statusCode: http.StatusNotFound, errors: &Errors{[]*Error{errNotFound}}}},
+		{"updateExistingDep", depID + "-1", "DEPLOYED", sameTopology, &result{statusCode: http.StatusOK, status: deployments.UPDATED}},
+		{"updateFailedUpdate", depID + "-2", "UPDATE_FAILURE", sameTopology, &result{statusCode: http.StatusOK, status: deployments.UPDATED}},
+		{"updateNotDeployedDep", depID + "-3", "DEPLOYMENT_IN_PROGRESS", sameTopology, &result{statusCode: http.StatusConflict, errors: &Errors{[]*Error{newConflictRequest(fmt.Sprintf("Deployment %q can't be updated in status %q, it should be deployed first", depID+"-3", "DEPLOYMENT_IN_PROGRESS"))}}, status: deployments.DEPLOYMENT_IN_PROGRESS}},
+		{"updateWithoutTopology", depID + "-4", "DEPLOYED", noTopology, &result{statusCode: http.StatusBadRequest, status: deployments.DEPLOYED}},
+		{"updateNotExistingDep", "noDeployment", "", sameTopology, &result{statusCode: http.StatusNotFound, errors: &Errors{[]*Error{errNotFound}}}},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			prepareTest(t, tt.deploymentID, client, srv)
+			if tt.status != "" {
+				srv.PopulateKV(t, map[string][]byte{
+					consulutil.DeploymentKVPrefix + "/" + tt.deploymentID + "/status": []byte(tt.status),
+				})
+			}
 
-			req := httptest.NewRequest("PATCH", "/deployments/"+tt.deploymentID, nil)
+			var body io.Reader = bytes.NewReader(tt.csar)
+			req := httptest.NewRequest("PATCH", "/deployments/"+tt.deploymentID, body)
 			req.Header.Set("Content-Type", mimeTypeApplicationZip)
 			resp := newTestHTTPRouter(client, cfg, req)
 			require.NotNil(t, resp, "unexpected nil response")
 			require.Equal(t, tt.want.statusCode, resp.StatusCode, "unexpected status code %d instead of %d", resp.StatusCode, tt.want.statusCode)
 
-			body, err := ioutil.ReadAll(resp.Body)
+			respBody, err := ioutil.ReadAll(resp.Body)
 			require.Nil(t, err, "unexpected error reading body response")
 
 			if tt.want.errors != nil {
 				var errorsFound Errors
-				err := json.Unmarshal(body, &errorsFound)
+				err := json.Unmarshal(respBody, &errorsFound)
 				require.Nil(t, err, "unexpected error unmarshalling json body")
 				if !reflect.DeepEqual(errorsFound, *tt.want.errors) {
 					t.Errorf("errors = %v, want %v", errorsFound, *tt.want.errors)
 				}
 			}
+			if tt.status != "" {
+				status, err := deployments.GetDeploymentStatus(context.Background(), tt.deploymentID)
+				require.NoError(t, err)
+				require.Equal(t, tt.want.status, status)
+			}
 			cleanTest(tt.deploymentID, "")
 		})
 	}
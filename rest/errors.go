@@ -81,3 +81,7 @@ func newConflictRequest(message string) *Error {
 func newForbiddenRequest(message string) *Error {
 	return &Error{"forbidden", http.StatusForbidden, "Forbidden", message}
 }
+
+func newUnauthorizedError(message string) *Error {
+	return &Error{"unauthorized", http.StatusUnauthorized, "Unauthorized", message}
+}
@@ -0,0 +1,188 @@
+// Copyright 2021 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/pkg/errors"
+
+	"github.com/ystia/yorc/v4/deployments"
+	"github.com/ystia/yorc/v4/events"
+	"github.com/ystia/yorc/v4/log"
+)
+
+// LastEventIDHeader is the header sent by Server-Sent Events clients when reconnecting to a stream
+const LastEventIDHeader = "Last-Event-ID"
+
+const (
+	// SSEEventTypeEvent is the Server-Sent Event type used for deployments status change events
+	SSEEventTypeEvent = "event"
+	// SSEEventTypeLog is the Server-Sent Event type used for deployments logs
+	SSEEventTypeLog = "log"
+	// SSEEventTypeError is the Server-Sent Event type used to report an error before closing a stream
+	SSEEventTypeError = "error"
+)
+
+// sseKeepAliveInterval is the maximum duration of a blocking query on the store
+// before sending a keep-alive comment to the client
+var sseKeepAliveInterval = 30 * time.Second
+
+type eventsFetcher func(ctx context.Context, deploymentID string, waitIndex uint64, timeout time.Duration) ([]json.RawMessage, uint64, error)
+
+func (s *Server) streamEvents(w http.ResponseWriter, r *http.Request) {
+	s.streamEventsOrLogs(w, r, SSEEventTypeEvent, events.StatusEvents)
+}
+
+func (s *Server) streamLogs(w http.ResponseWriter, r *http.Request) {
+	s.streamEventsOrLogs(w, r, SSEEventTypeLog, events.LogsEvents)
+}
+
+func (s *Server) streamEventsOrLogs(w http.ResponseWriter, r *http.Request, sseEventType string, fetch eventsFetcher) {
+	var params httprouter.Params
+	ctx := r.Context()
+	params = ctx.Value(paramsLookupKey).(httprouter.Params)
+	id := params.ByName("id")
+	if id != "" {
+		if depExist, err := deployments.DoesDeploymentExists(ctx, id); err != nil {
+			log.Panic(err)
+		} else if !depExist {
+			writeError(w, r, errNotFound)
+			return
+		}
+	}
+
+	waitIndex, restErr := getStreamStartIndex(r)
+	if restErr != nil {
+		writeError(w, r, restErr)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		log.Panic("Streaming is not supported by the underlying http.ResponseWriter")
+	}
+
+	w.Header().Set("Content-Type", mimeTypeTextEventStream)
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	// Prevents reverse proxies like nginx to buffer the stream
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		// If id parameter not set (id == ""), fetch returns events or logs for all the deployments
+		msgs, lastIdx, err := fetch(ctx, id, waitIndex, sseKeepAliveInterval)
+		if err != nil {
+			if ctx.Err() != nil {
+				// Client went away
+				return
+			}
+			// Headers were already sent so we can't use writeError
+			log.Printf("Failed to stream %ss for deployment %q: %+v", sseEventType, id, err)
+			errMsg, _ := json.Marshal(newInternalServerError(err))
+			writeServerSentEvent(w, "", SSEEventTypeError, errMsg)
+			flusher.Flush()
+			return
+		}
+
+		if len(msgs) == 0 || lastIdx == waitIndex {
+			err = writeServerSentComment(w, "keep-alive")
+		} else {
+			err = writeServerSentEvents(w, lastIdx, sseEventType, msgs)
+		}
+		if err != nil {
+			log.Debugf("Stopping %ss stream for deployment %q: %v", sseEventType, id, err)
+			return
+		}
+		flusher.Flush()
+		waitIndex = lastIdx
+	}
+}
+
+// getStreamStartIndex returns the index from which a stream should start.
+//
+// The Last-Event-ID header sent by clients on reconnection takes precedence over the index query parameter.
+func getStreamStartIndex(r *http.Request) (uint64, *Error) {
+	var waitIndex uint64 = 1
+	var err error
+	if lastEventID := r.Header.Get(LastEventIDHeader); lastEventID != "" {
+		if waitIndex, err = strconv.ParseUint(lastEventID, 10, 64); err != nil {
+			return 0, newBadRequestMessage(fmt.Sprintf("Invalid %s header: %v", LastEventIDHeader, err))
+		}
+		return waitIndex, nil
+	}
+	if idx := r.URL.Query().Get("index"); idx != "" {
+		if waitIndex, err = strconv.ParseUint(idx, 10, 64); err != nil {
+			return 0, newBadRequestParameter("index", err)
+		}
+	}
+	return waitIndex, nil
+}
+
+// writeServerSentEvents writes a batch of messages retrieved at the same index.
+//
+// Only the last message of the batch holds the index as its id so clients resuming
+// after an interrupted batch will receive the whole batch again rather than losing messages.
+func writeServerSentEvents(w io.Writer, lastIndex uint64, sseEventType string, msgs []json.RawMessage) error {
+	for i, msg := range msgs {
+		var id string
+		if i == len(msgs)-1 {
+			id = strconv.FormatUint(lastIndex, 10)
+		}
+		if err := writeServerSentEvent(w, id, sseEventType, msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeServerSentEvent(w io.Writer, id, sseEventType string, data []byte) error {
+	var b bytes.Buffer
+	if id != "" {
+		fmt.Fprintf(&b, "id: %s\n", id)
+	}
+	if sseEventType != "" {
+		fmt.Fprintf(&b, "event: %s\n", sseEventType)
+	}
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		b.WriteString("data: ")
+		b.Write(line)
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+	_, err := w.Write(b.Bytes())
+	return errors.Wrap(err, "failed to write server-sent event")
+}
+
+func writeServerSentComment(w io.Writer, comment string) error {
+	_, err := fmt.Fprintf(w, ": %s\n\n", comment)
+	return errors.Wrap(err, "failed to write server-sent comment")
+}
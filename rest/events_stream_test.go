@@ -0,0 +1,82 @@
+// Copyright 2021 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rest
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteServerSentEvents(t *testing.T) {
+	tests := []struct {
+		name      string
+		lastIndex uint64
+		msgs      []json.RawMessage
+		want      string
+	}{
+		{"SingleMessage", 12, []json.RawMessage{json.RawMessage(`{"a":"b"}`)}, "id: 12\nevent: event\ndata: {\"a\":\"b\"}\n\n"},
+		{"SeveralMessages", 42, []json.RawMessage{json.RawMessage(`{"a":"b"}`), json.RawMessage(`{"c":"d"}`)}, "event: event\ndata: {\"a\":\"b\"}\n\nid: 42\nevent: event\ndata: {\"c\":\"d\"}\n\n"},
+		{"MultiLinesMessage", 3, []json.RawMessage{json.RawMessage("{\n\"a\":\"b\"\n}")}, "id: 3\nevent: event\ndata: {\ndata: \"a\":\"b\"\ndata: }\n\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b bytes.Buffer
+			err := writeServerSentEvents(&b, tt.lastIndex, SSEEventTypeEvent, tt.msgs)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, b.String())
+		})
+	}
+}
+
+func TestWriteServerSentComment(t *testing.T) {
+	var b bytes.Buffer
+	err := writeServerSentComment(&b, "keep-alive")
+	require.NoError(t, err)
+	assert.Equal(t, ": keep-alive\n\n", b.String())
+}
+
+func TestGetStreamStartIndex(t *testing.T) {
+	tests := []struct {
+		name        string
+		url         string
+		lastEventID string
+		want        uint64
+		wantErr     bool
+	}{
+		{"Default", "/events/stream", "", 1, false},
+		{"IndexParam", "/events/stream?index=10", "", 10, false},
+		{"LastEventIDPrecedence", "/events/stream?index=10", "25", 25, false},
+		{"InvalidIndexParam", "/events/stream?index=ten", "", 0, true},
+		{"InvalidLastEventID", "/events/stream", "ten", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.url, nil)
+			if tt.lastEventID != "" {
+				req.Header.Set(LastEventIDHeader, tt.lastEventID)
+			}
+			got, err := getStreamStartIndex(req)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("getStreamStartIndex() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"io/ioutil"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/julienschmidt/httprouter"
 	"github.com/pkg/errors"
@@ -172,9 +173,82 @@ func (s *Server) updateHostInPool(w http.ResponseWriter, r *http.Request) {
 			log.Panic(err)
 		}
 	}
+	if host.Lease != nil {
+		duration, err := time.ParseDuration(host.Lease.Duration)
+		if err != nil {
+			writeError(w, r, newBadRequestMessage(fmt.Sprintf("invalid lease duration %q: %v", host.Lease.Duration, err)))
+			return
+		}
+		err = s.renewHostLeases(location, hostname, host.Lease.Allocation, duration)
+		if err != nil {
+			if hostspool.IsBadRequestError(err) {
+				writeError(w, r, newBadRequestError(err))
+				return
+			}
+			if hostspool.IsHostNotFoundError(err) || hostspool.IsAllocationNotFoundError(err) {
+				writeError(w, r, errNotFound)
+				return
+			}
+			log.Panic(err)
+		}
+	}
+	if host.Maintenance != nil {
+		if host.Maintenance.Enabled {
+			var end time.Time
+			if host.Maintenance.End != nil {
+				end = *host.Maintenance.End
+			}
+			err = s.hostsPoolMgr.StartMaintenance(location, hostname, host.Maintenance.Reason, end)
+		} else {
+			err = s.hostsPoolMgr.EndMaintenance(location, hostname)
+		}
+		if err != nil {
+			if hostspool.IsBadRequestError(err) {
+				writeError(w, r, newBadRequestError(err))
+				return
+			}
+			if hostspool.IsHostNotFoundError(err) {
+				writeError(w, r, errNotFound)
+				return
+			}
+			log.Panic(err)
+		}
+	}
 	w.WriteHeader(http.StatusOK)
 }
 
+// renewHostLeases renews the lease of the given allocation of a host or of all its allocations having a lease
+// if allocationID is empty
+func (s *Server) renewHostLeases(location, hostname, allocationID string, duration time.Duration) error {
+	if allocationID != "" {
+		_, err := s.hostsPoolMgr.RenewLease(location, hostname, allocationID, duration)
+		return err
+	}
+	host, err := s.hostsPoolMgr.GetHost(location, hostname)
+	if err != nil {
+		return err
+	}
+	for _, alloc := range host.Allocations {
+		if alloc.LeaseExpiry == nil {
+			continue
+		}
+		_, err = s.hostsPoolMgr.RenewLease(location, hostname, alloc.ID, duration)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Server) getHostInPoolOrReservations(w http.ResponseWriter, r *http.Request) {
+	params := r.Context().Value(paramsLookupKey).(httprouter.Params)
+	if params.ByName("host") == hostspool.ReservationsHostname {
+		s.listReservations(w, r)
+		return
+	}
+	s.getHostInPool(w, r)
+}
+
 func (s *Server) getHostInPool(w http.ResponseWriter, r *http.Request) {
 	var params httprouter.Params
 	ctx := r.Context()
@@ -0,0 +1,163 @@
+// Copyright 2021 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+
+	"github.com/ystia/yorc/v4/helper/labelsutil"
+	"github.com/ystia/yorc/v4/log"
+	"github.com/ystia/yorc/v4/prov/hostspool"
+)
+
+func (s *Server) listReservations(w http.ResponseWriter, r *http.Request) {
+	params := r.Context().Value(paramsLookupKey).(httprouter.Params)
+	location := params.ByName("location")
+
+	reservations, err := s.hostsPoolMgr.ListReservations(location)
+	if err != nil {
+		log.Panic(err)
+	}
+	if len(reservations) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	coll := ReservationsCollection{Reservations: make([]Reservation, len(reservations))}
+	for i, res := range reservations {
+		coll.Reservations[i] = newReservation(location, res, nil)
+	}
+	encodeJSONResponse(w, r, coll)
+}
+
+func (s *Server) newReservation(w http.ResponseWriter, r *http.Request) {
+	params := r.Context().Value(paramsLookupKey).(httprouter.Params)
+	if params.ByName("host") != hostspool.ReservationsHostname {
+		writeError(w, r, errNotFound)
+		return
+	}
+	location := params.ByName("location")
+	name := params.ByName("name")
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		log.Panic(err)
+	}
+	var req ReservationRequest
+	err = json.Unmarshal(body, &req)
+	if err != nil {
+		writeError(w, r, newBadRequestError(err))
+		return
+	}
+
+	reservation := &hostspool.Reservation{Name: name, Owner: req.Owner, Hosts: req.Hosts}
+	switch {
+	case req.Expiry != nil && req.Duration != "":
+		writeError(w, r, newBadRequestMessage(`"expiry" and "duration" can't be provided together`))
+		return
+	case req.Expiry != nil:
+		reservation.Expiry = *req.Expiry
+	case req.Duration != "":
+		d, err := time.ParseDuration(req.Duration)
+		if err != nil {
+			writeError(w, r, newBadRequestMessage(fmt.Sprintf("invalid reservation duration %q: %v", req.Duration, err)))
+			return
+		}
+		reservation.Expiry = time.Now().Add(d).UTC()
+	default:
+		writeError(w, r, newBadRequestMessage(`either "expiry" or "duration" is required`))
+		return
+	}
+
+	filters := make([]labelsutil.Filter, len(req.Filters))
+	for i := range req.Filters {
+		filters[i], err = labelsutil.CreateFilter(req.Filters[i])
+		if err != nil {
+			writeError(w, r, newBadRequestError(err))
+			return
+		}
+	}
+
+	warnings, err := s.hostsPoolMgr.Reserve(location, reservation, req.Count, filters...)
+	if err != nil {
+		if hostspool.IsBadRequestError(err) || hostspool.IsNoMatchingHostFoundError(err) {
+			writeError(w, r, newBadRequestError(err))
+			return
+		}
+		if hostspool.IsHostNotFoundError(err) {
+			writeError(w, r, errNotFound)
+			return
+		}
+		log.Panic(err)
+	}
+
+	res := newReservation(location, *reservation, warnings)
+	w.Header().Set("Location", res.Links[0].Href)
+	w.Header().Set("Content-Type", mimeTypeApplicationJSON)
+	w.WriteHeader(http.StatusCreated)
+	encodeJSONResponse(w, r, res)
+}
+
+func (s *Server) getReservation(w http.ResponseWriter, r *http.Request) {
+	params := r.Context().Value(paramsLookupKey).(httprouter.Params)
+	if params.ByName("host") != hostspool.ReservationsHostname {
+		writeError(w, r, errNotFound)
+		return
+	}
+	location := params.ByName("location")
+
+	reservation, err := s.hostsPoolMgr.GetReservation(location, params.ByName("name"))
+	if err != nil {
+		if hostspool.IsReservationNotFoundError(err) {
+			writeError(w, r, errNotFound)
+			return
+		}
+		log.Panic(err)
+	}
+	encodeJSONResponse(w, r, newReservation(location, *reservation, nil))
+}
+
+func (s *Server) deleteReservation(w http.ResponseWriter, r *http.Request) {
+	params := r.Context().Value(paramsLookupKey).(httprouter.Params)
+	if params.ByName("host") != hostspool.ReservationsHostname {
+		writeError(w, r, errNotFound)
+		return
+	}
+
+	err := s.hostsPoolMgr.CancelReservation(params.ByName("location"), params.ByName("name"))
+	if err != nil {
+		if hostspool.IsReservationNotFoundError(err) {
+			writeError(w, r, errNotFound)
+			return
+		}
+		log.Panic(err)
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func newReservation(location string, reservation hostspool.Reservation, warnings []labelsutil.Warning) Reservation {
+	res := Reservation{Reservation: reservation, Links: make([]AtomLink, 1)}
+	res.Links[0] = newAtomLink(LinkRelSelf, fmt.Sprintf("/hosts_pool/%s/%s/%s", location, hostspool.ReservationsHostname, reservation.Name))
+	for _, warn := range warnings {
+		res.Warnings = append(res.Warnings, warn.Error())
+	}
+	return res
+}
@@ -25,6 +25,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/ystia/yorc/v4/helper/consulutil"
 	"github.com/ystia/yorc/v4/log"
@@ -65,6 +66,12 @@ func testHostsPoolHandlers(t *testing.T, client *api.Client, cfg config.Configur
 	t.Run("testUpdateHostInPoolWithConnectionError", func(t *testing.T) {
 		testUpdateHostInPoolWithConnectionError(t, client, cfg, srv)
 	})
+	t.Run("testUpdateHostInPoolMaintenance", func(t *testing.T) {
+		testUpdateHostInPoolMaintenance(t, client, cfg, srv)
+	})
+	t.Run("testHostsPoolReservations", func(t *testing.T) {
+		testHostsPoolReservations(t, client, cfg, srv)
+	})
 	t.Run("testGetHostInPool", func(t *testing.T) {
 		testGetHostInPool(t, client, cfg, srv)
 	})
@@ -320,6 +327,105 @@ func testUpdateHostInPoolWithConnectionError(t *testing.T, client *api.Client, c
 	client.KV().DeleteTree(consulutil.HostsPoolPrefix+"/myHostsPoolLocationTest/host113", nil)
 }
 
+func testUpdateHostInPoolMaintenance(t *testing.T, client *api.Client, cfg config.Configuration, srv *testutil.TestServer) {
+	t.Parallel()
+
+	srv.PopulateKV(t, map[string][]byte{
+		consulutil.HostsPoolPrefix + "/myHostsPoolLocationTest/host114/status": []byte("free"),
+	})
+
+	sendRequest := func(hostRequest HostRequest) *http.Response {
+		tmp, err := json.Marshal(hostRequest)
+		require.Nil(t, err, "unexpected error marshalling data to provide body request")
+		req := httptest.NewRequest("PATCH", "/hosts_pool/myHostsPoolLocationTest/host114", bytes.NewBuffer(tmp))
+		req.Header.Add("Content-Type", mimeTypeApplicationJSON)
+		resp := newTestHTTPRouter(client, cfg, req)
+		require.NotNil(t, resp, "unexpected nil response")
+		return resp
+	}
+
+	// Ending maintenance of a host not in maintenance is a bad request
+	resp := sendRequest(HostRequest{Maintenance: &HostMaintenance{Enabled: false}})
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode, "unexpected status code %d instead of %d", resp.StatusCode, http.StatusBadRequest)
+
+	end := time.Now().Add(time.Hour)
+	resp = sendRequest(HostRequest{Maintenance: &HostMaintenance{Enabled: true, Reason: "hardware upgrade", End: &end}})
+	require.Equal(t, http.StatusOK, resp.StatusCode, "unexpected status code %d instead of %d", resp.StatusCode, http.StatusOK)
+	kvp, _, err := client.KV().Get(consulutil.HostsPoolPrefix+"/myHostsPoolLocationTest/host114/status", nil)
+	require.Nil(t, err)
+	require.NotNil(t, kvp)
+	require.Equal(t, "maintenance", string(kvp.Value))
+
+	resp = sendRequest(HostRequest{Maintenance: &HostMaintenance{Enabled: false}})
+	require.Equal(t, http.StatusOK, resp.StatusCode, "unexpected status code %d instead of %d", resp.StatusCode, http.StatusOK)
+	kvp, _, err = client.KV().Get(consulutil.HostsPoolPrefix+"/myHostsPoolLocationTest/host114/status", nil)
+	require.Nil(t, err)
+	require.NotNil(t, kvp)
+	require.Equal(t, "free", string(kvp.Value))
+
+	client.KV().DeleteTree(consulutil.HostsPoolPrefix+"/myHostsPoolLocationTest/host114", nil)
+}
+
+func testHostsPoolReservations(t *testing.T, client *api.Client, cfg config.Configuration, srv *testutil.TestServer) {
+	t.Parallel()
+
+	srv.PopulateKV(t, map[string][]byte{
+		consulutil.HostsPoolPrefix + "/myHostsPoolLocationTest/host115/status": []byte("free"),
+	})
+
+	putReservation := func(reservationRequest ReservationRequest) *http.Response {
+		tmp, err := json.Marshal(reservationRequest)
+		require.Nil(t, err, "unexpected error marshalling data to provide body request")
+		req := httptest.NewRequest("PUT", "/hosts_pool/myHostsPoolLocationTest/reservations/training", bytes.NewBuffer(tmp))
+		req.Header.Add("Content-Type", mimeTypeApplicationJSON)
+		resp := newTestHTTPRouter(client, cfg, req)
+		require.NotNil(t, resp, "unexpected nil response")
+		return resp
+	}
+
+	resp := putReservation(ReservationRequest{Owner: "team1", Hosts: []string{"host115"}})
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode, "unexpected status code %d instead of %d", resp.StatusCode, http.StatusBadRequest)
+
+	resp = putReservation(ReservationRequest{Owner: "team1", Hosts: []string{"host115"}, Duration: "1h"})
+	require.Equal(t, http.StatusCreated, resp.StatusCode, "unexpected status code %d instead of %d", resp.StatusCode, http.StatusCreated)
+	require.Equal(t, "/hosts_pool/myHostsPoolLocationTest/reservations/training", resp.Header.Get("Location"))
+
+	resp = putReservation(ReservationRequest{Owner: "team2", Hosts: []string{"host115"}, Duration: "1h"})
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode, "unexpected status code %d instead of %d", resp.StatusCode, http.StatusBadRequest)
+
+	req := httptest.NewRequest("GET", "/hosts_pool/myHostsPoolLocationTest/reservations", nil)
+	req.Header.Add("Accept", mimeTypeApplicationJSON)
+	resp = newTestHTTPRouter(client, cfg, req)
+	require.Equal(t, http.StatusOK, resp.StatusCode, "unexpected status code %d instead of %d", resp.StatusCode, http.StatusOK)
+	var coll ReservationsCollection
+	body, err := ioutil.ReadAll(resp.Body)
+	require.Nil(t, err, "unexpected error reading body response")
+	err = json.Unmarshal(body, &coll)
+	require.Nil(t, err, "unexpected error unmarshalling json body")
+	require.Len(t, coll.Reservations, 1)
+	require.Equal(t, "team1", coll.Reservations[0].Owner)
+	require.Equal(t, []string{"host115"}, coll.Reservations[0].Hosts)
+
+	req = httptest.NewRequest("DELETE", "/hosts_pool/myHostsPoolLocationTest/reservations/training", nil)
+	resp = newTestHTTPRouter(client, cfg, req)
+	require.Equal(t, http.StatusOK, resp.StatusCode, "unexpected status code %d instead of %d", resp.StatusCode, http.StatusOK)
+
+	req = httptest.NewRequest("GET", "/hosts_pool/myHostsPoolLocationTest/reservations/training", nil)
+	req.Header.Add("Accept", mimeTypeApplicationJSON)
+	resp = newTestHTTPRouter(client, cfg, req)
+	require.Equal(t, http.StatusNotFound, resp.StatusCode, "unexpected status code %d instead of %d", resp.StatusCode, http.StatusNotFound)
+
+	client.KV().DeleteTree(consulutil.HostsPoolPrefix+"/myHostsPoolLocationTest/host115", nil)
+
+	// "reservations" can't be used as a host name as it is used for reservations routes
+	tmp, err := json.Marshal(HostRequest{Connection: &hostspool.Connection{User: "test", Password: "test", Host: "127.0.0.1"}})
+	require.Nil(t, err, "unexpected error marshalling data to provide body request")
+	req = httptest.NewRequest("PUT", "/hosts_pool/myHostsPoolLocationTest/reservations", bytes.NewBuffer(tmp))
+	req.Header.Add("Content-Type", mimeTypeApplicationJSON)
+	resp = newTestHTTPRouter(client, cfg, req)
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode, "unexpected status code %d instead of %d", resp.StatusCode, http.StatusBadRequest)
+}
+
 func testGetHostInPool(t *testing.T, client *api.Client, cfg config.Configuration, srv *testutil.TestServer) {
 	t.Parallel()
 
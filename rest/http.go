@@ -43,6 +43,7 @@ const LOCATIONURI = LOCATIONS + "/:locationName"
 const (
 	mimeTypeApplicationZip  = "application/zip"
 	mimeTypeApplicationJSON = "application/json"
+	mimeTypeTextEventStream = "text/event-stream"
 )
 
 type router struct {
@@ -97,6 +98,7 @@ type Server struct {
 	config         config.Configuration
 	hostsPoolMgr   hostspool.Manager
 	locationMgr    locations.Manager
+	authorizer     *authorizer
 }
 
 // Shutdown stops the HTTP server
@@ -116,6 +118,10 @@ func NewServer(configuration config.Configuration, client *api.Client, shutdownC
 	if err != nil {
 		return nil, err
 	}
+	authz, err := newAuthorizer(configuration.Authentication)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid REST API authentication configuration")
+	}
 	listener, err := net.Listen(addr.Network(), addr.String())
 	if err != nil {
 		return nil, errors.Wrapf(err, "Failed to bind on %s", addr)
@@ -136,6 +142,7 @@ func NewServer(configuration config.Configuration, client *api.Client, shutdownC
 		config:         configuration,
 		hostsPoolMgr:   hostspool.NewManager(client, configuration),
 		locationMgr:    locations.NewManager(client, configuration),
+		authorizer:     authz,
 	}
 
 	httpServer.registerHandlers()
@@ -157,68 +164,97 @@ func NewServer(configuration config.Configuration, client *api.Client, shutdownC
 
 func (s *Server) registerHandlers() {
 	commonHandlers := alice.New(telemetryHandler, loggingHandler, recoverHandler)
-	s.router.Get("/server/info", commonHandlers.Append(acceptHandler(mimeTypeApplicationJSON)).ThenFunc(s.getInfoHandler))
+	viewerHandlers := commonHandlers.Append(s.authorizer.handler(RoleViewer))
+	operatorHandlers := commonHandlers.Append(s.authorizer.handler(RoleOperator))
+	adminHandlers := commonHandlers.Append(s.authorizer.handler(RoleAdmin))
+	s.router.Get("/server/info", viewerHandlers.Append(acceptHandler(mimeTypeApplicationJSON)).ThenFunc(s.getInfoHandler))
 	s.router.Get("/server/health", commonHandlers.Append(acceptHandler(mimeTypeApplicationJSON)).ThenFunc(s.getHealthHandler))
-	s.router.Post("/deployments", commonHandlers.Append(contentTypeHandler(mimeTypeApplicationZip)).ThenFunc(s.newDeploymentHandler))
-	s.router.Put("/deployments/:id", commonHandlers.Append(contentTypeHandler(mimeTypeApplicationZip)).ThenFunc(s.newDeploymentHandler))
-	s.router.Patch("/deployments/:id", commonHandlers.Append(contentTypeHandler(mimeTypeApplicationZip)).ThenFunc(s.updateDeploymentHandler))
-	s.router.Delete("/deployments/:id", commonHandlers.ThenFunc(s.deleteDeploymentHandler))
-	s.router.Get("/deployments/:id", commonHandlers.Append(acceptHandler(mimeTypeApplicationJSON)).ThenFunc(s.getDeploymentHandler))
-	s.router.Get("/deployments", commonHandlers.Append(acceptHandler(mimeTypeApplicationJSON)).ThenFunc(s.listDeploymentsHandler))
-	s.router.Get("/deployments/:id/events", commonHandlers.Append(acceptHandler(mimeTypeApplicationJSON)).ThenFunc(s.pollEvents))
-	s.router.Get("/events", commonHandlers.Append(acceptHandler(mimeTypeApplicationJSON)).ThenFunc(s.pollEvents))
-	s.router.Head("/deployments/:id/events", commonHandlers.ThenFunc(s.headEventsIndex))
-	s.router.Head("/events", commonHandlers.ThenFunc(s.headEventsIndex))
-	s.router.Get("/deployments/:id/logs", commonHandlers.Append(acceptHandler(mimeTypeApplicationJSON)).ThenFunc(s.pollLogs))
-	s.router.Get("/logs", commonHandlers.Append(acceptHandler(mimeTypeApplicationJSON)).ThenFunc(s.pollLogs))
-	s.router.Head("/deployments/:id/logs", commonHandlers.ThenFunc(s.headLogsEventsIndex))
-	s.router.Head("/logs", commonHandlers.ThenFunc(s.headLogsEventsIndex))
-	s.router.Get("/deployments/:id/nodes/:nodeName", commonHandlers.Append(acceptHandler(mimeTypeApplicationJSON)).ThenFunc(s.getNodeHandler))
-	s.router.Get("/deployments/:id/nodes/:nodeName/instances/:instanceId", commonHandlers.Append(acceptHandler(mimeTypeApplicationJSON)).ThenFunc(s.getNodeInstanceHandler))
-	s.router.Get("/deployments/:id/outputs", commonHandlers.Append(acceptHandler(mimeTypeApplicationJSON)).ThenFunc(s.listOutputsHandler))
-	s.router.Get("/deployments/:id/outputs/:opt", commonHandlers.Append(acceptHandler(mimeTypeApplicationJSON)).ThenFunc(s.getOutputHandler))
-	s.router.Get("/deployments/:id/tasks/:taskId", commonHandlers.Append(acceptHandler(mimeTypeApplicationJSON)).ThenFunc(s.getTaskHandler))
-	s.router.Get("/deployments/:id/tasks/:taskId/steps", commonHandlers.Append(acceptHandler(mimeTypeApplicationJSON)).ThenFunc(s.getTaskStepsHandler))
-	s.router.Delete("/deployments/:id/tasks/:taskId", commonHandlers.ThenFunc(s.cancelTaskHandler))
-	s.router.Put("/deployments/:id/tasks/:taskId", commonHandlers.ThenFunc(s.resumeTaskHandler))
-	s.router.Put("/deployments/:id/tasks/:taskId/steps/:stepId", commonHandlers.Append(contentTypeHandler(mimeTypeApplicationJSON)).ThenFunc(s.updateTaskStepStatusHandler))
-	s.router.Post("/deployments/:id/scale/:nodeName", commonHandlers.ThenFunc(s.scaleHandler))
-	s.router.Get("/deployments/:id/nodes/:nodeName/instances/:instanceId/attributes", commonHandlers.Append(acceptHandler(mimeTypeApplicationJSON)).ThenFunc(s.getNodeInstanceAttributesListHandler))
-	s.router.Get("/deployments/:id/nodes/:nodeName/instances/:instanceId/attributes/:attributeName", commonHandlers.Append(acceptHandler(mimeTypeApplicationJSON)).ThenFunc(s.getNodeInstanceAttributeHandler))
-	s.router.Post("/deployments/:id/custom", commonHandlers.Append(contentTypeHandler(mimeTypeApplicationJSON)).ThenFunc(s.newCustomCommandHandler))
-	s.router.Post("/deployments/:id/workflows/:workflowName", commonHandlers.ThenFunc(s.newWorkflowHandler))
-	s.router.Get("/deployments/:id/workflows/:workflowName", commonHandlers.Append(acceptHandler(mimeTypeApplicationJSON)).ThenFunc(s.getWorkflowHandler))
-	s.router.Get("/deployments/:id/workflows", commonHandlers.Append(acceptHandler(mimeTypeApplicationJSON)).ThenFunc(s.listWorkflowsHandler))
-	s.router.Post("/deployments/:id/purge", commonHandlers.Append(acceptHandler(mimeTypeApplicationJSON)).ThenFunc(s.purgeDeploymentHandler))
-
-	s.router.Get("/registry/delegates", commonHandlers.Append(acceptHandler(mimeTypeApplicationJSON)).ThenFunc(s.listRegistryDelegatesHandler))
-	s.router.Get("/registry/implementations", commonHandlers.Append(acceptHandler(mimeTypeApplicationJSON)).ThenFunc(s.listRegistryImplementationsHandler))
-	s.router.Get("/registry/definitions", commonHandlers.Append(acceptHandler(mimeTypeApplicationJSON)).ThenFunc(s.listRegistryDefinitionsHandler))
-	s.router.Get("/registry/vaults", commonHandlers.Append(acceptHandler(mimeTypeApplicationJSON)).ThenFunc(s.listVaultsBuilderHandler))
-	s.router.Get("/registry/infra_usage_collectors", commonHandlers.Append(acceptHandler(mimeTypeApplicationJSON)).ThenFunc(s.listInfraHandler))
-
-	s.router.Post("/infra_usage/:infraName/:locationName", commonHandlers.Append(contentTypeHandler(mimeTypeApplicationJSON)).ThenFunc(s.postInfraUsageHandler))
-	s.router.Get("/infra_usage/:infraName/:locationName/tasks/:taskId", commonHandlers.Append(acceptHandler(mimeTypeApplicationJSON)).ThenFunc(s.getTaskQueryHandler))
-	s.router.Delete("/infra_usage/:infraName/:locationName/tasks/:taskId", commonHandlers.ThenFunc(s.deleteTaskQueryHandler))
-	s.router.Get("/infra_usage", commonHandlers.Append(acceptHandler(mimeTypeApplicationJSON)).ThenFunc(s.listTaskQueryHandler))
-
-	s.router.Put("/hosts_pool/:location/:host", commonHandlers.Append(contentTypeHandler(mimeTypeApplicationJSON)).ThenFunc(s.newHostInPool))
-	s.router.Patch("/hosts_pool/:location/:host", commonHandlers.Append(contentTypeHandler(mimeTypeApplicationJSON)).ThenFunc(s.updateHostInPool))
-	s.router.Delete("/hosts_pool/:location/:host", commonHandlers.ThenFunc(s.deleteHostInPool))
-	s.router.Post("/hosts_pool/:location", commonHandlers.Append(contentTypeHandler(mimeTypeApplicationJSON)).ThenFunc(s.applyHostsPool))
-	s.router.Put("/hosts_pool/:location", commonHandlers.Append(contentTypeHandler(mimeTypeApplicationJSON)).ThenFunc(s.applyHostsPool))
-	s.router.Get("/hosts_pool/:location", commonHandlers.Append(acceptHandler(mimeTypeApplicationJSON)).ThenFunc(s.listHostsInPool))
-	s.router.Get("/hosts_pool/:location/:host", commonHandlers.Append(acceptHandler(mimeTypeApplicationJSON)).ThenFunc(s.getHostInPool))
-	s.router.Get("/hosts_pool", commonHandlers.Append(acceptHandler(mimeTypeApplicationJSON)).ThenFunc(s.listHostsPoolLocations))
-
-	s.router.Get(LOCATIONS, commonHandlers.Append(acceptHandler("application/json")).ThenFunc(s.listLocationsHandler))
-	s.router.Get(LOCATIONURI, commonHandlers.Append(acceptHandler("application/json")).ThenFunc(s.getLocationHandler))
-	s.router.Put(LOCATIONURI, commonHandlers.Append(contentTypeHandler("application/json")).ThenFunc(s.createLocationHandler))
-	s.router.Patch(LOCATIONURI, commonHandlers.Append(contentTypeHandler("application/json")).ThenFunc(s.updateLocationHandler))
-	s.router.Delete(LOCATIONURI, commonHandlers.ThenFunc(s.deleteLocationHandler))
+	s.router.Post("/deployments", operatorHandlers.Append(contentTypeHandler(mimeTypeApplicationZip)).ThenFunc(s.newDeploymentHandler))
+	s.router.Put("/deployments/:id", operatorHandlers.Append(contentTypeHandler(mimeTypeApplicationZip)).ThenFunc(s.newDeploymentHandler))
+	s.router.Patch("/deployments/:id", operatorHandlers.Append(contentTypeHandler(mimeTypeApplicationZip)).ThenFunc(s.updateDeploymentHandler))
+	s.router.Delete("/deployments/:id", operatorHandlers.ThenFunc(s.deleteDeploymentHandler))
+	s.router.Get("/deployments/:id", viewerHandlers.Append(acceptHandler(mimeTypeApplicationJSON)).ThenFunc(s.getDeploymentHandler))
+	s.router.Get("/deployments", viewerHandlers.Append(acceptHandler(mimeTypeApplicationJSON)).ThenFunc(s.listDeploymentsHandler))
+	s.router.Get("/deployments/:id/events", viewerHandlers.Append(acceptHandler(mimeTypeApplicationJSON)).ThenFunc(s.pollEvents))
+	s.router.Get("/events", viewerHandlers.Append(acceptHandler(mimeTypeApplicationJSON)).ThenFunc(s.pollEvents))
+	s.router.Head("/deployments/:id/events", viewerHandlers.ThenFunc(s.headEventsIndex))
+	s.router.Head("/events", viewerHandlers.ThenFunc(s.headEventsIndex))
+	s.router.Get("/deployments/:id/logs", viewerHandlers.Append(acceptHandler(mimeTypeApplicationJSON)).ThenFunc(s.pollLogs))
+	s.router.Get("/logs", viewerHandlers.Append(acceptHandler(mimeTypeApplicationJSON)).ThenFunc(s.pollLogs))
+	s.router.Head("/deployments/:id/logs", viewerHandlers.ThenFunc(s.headLogsEventsIndex))
+	s.router.Head("/logs", viewerHandlers.ThenFunc(s.headLogsEventsIndex))
+	s.router.Get("/deployments/:id/events/stream", viewerHandlers.Append(acceptHandler(mimeTypeTextEventStream)).ThenFunc(s.streamEvents))
+	s.router.Get("/events/stream", viewerHandlers.Append(acceptHandler(mimeTypeTextEventStream)).ThenFunc(s.streamEvents))
+	s.router.Get("/deployments/:id/logs/stream", viewerHandlers.Append(acceptHandler(mimeTypeTextEventStream)).ThenFunc(s.streamLogs))
+	s.router.Get("/logs/stream", viewerHandlers.Append(acceptHandler(mimeTypeTextEventStream)).ThenFunc(s.streamLogs))
+	s.router.Get("/deployments/:id/nodes/:nodeName", viewerHandlers.Append(acceptHandler(mimeTypeApplicationJSON)).ThenFunc(s.getNodeHandler))
+	s.router.Get("/deployments/:id/nodes/:nodeName/instances/:instanceId", viewerHandlers.Append(acceptHandler(mimeTypeApplicationJSON)).ThenFunc(s.getNodeInstanceHandler))
+	s.router.Get("/deployments/:id/outputs", viewerHandlers.Append(acceptHandler(mimeTypeApplicationJSON)).ThenFunc(s.listOutputsHandler))
+	s.router.Get("/deployments/:id/outputs/:opt", viewerHandlers.Append(acceptHandler(mimeTypeApplicationJSON)).ThenFunc(s.getOutputHandler))
+	s.router.Get("/deployments/:id/tasks/:taskId", viewerHandlers.Append(acceptHandler(mimeTypeApplicationJSON)).ThenFunc(s.getTaskHandler))
+	s.router.Get("/deployments/:id/tasks/:taskId/steps", viewerHandlers.Append(acceptHandler(mimeTypeApplicationJSON)).ThenFunc(s.getTaskStepsHandler))
+	s.router.Delete("/deployments/:id/tasks/:taskId", operatorHandlers.ThenFunc(s.cancelTaskHandler))
+	s.router.Put("/deployments/:id/tasks/:taskId", operatorHandlers.ThenFunc(s.resumeTaskHandler))
+	s.router.Put("/deployments/:id/tasks/:taskId/steps/:stepId", operatorHandlers.Append(contentTypeHandler(mimeTypeApplicationJSON)).ThenFunc(s.updateTaskStepStatusHandler))
+	s.router.Post("/deployments/:id/scale/:nodeName", operatorHandlers.ThenFunc(s.scaleHandler))
+	s.router.Get("/deployments/:id/nodes/:nodeName/instances/:instanceId/attributes", viewerHandlers.Append(acceptHandler(mimeTypeApplicationJSON)).ThenFunc(s.getNodeInstanceAttributesListHandler))
+	s.router.Get("/deployments/:id/nodes/:nodeName/instances/:instanceId/attributes/:attributeName", viewerHandlers.Append(acceptHandler(mimeTypeApplicationJSON)).ThenFunc(s.getNodeInstanceAttributeHandler))
+	s.router.Post("/deployments/:id/custom", operatorHandlers.Append(contentTypeHandler(mimeTypeApplicationJSON)).ThenFunc(s.newCustomCommandHandler))
+	s.router.Post("/deployments/:id/workflows/:workflowName", operatorHandlers.ThenFunc(s.newWorkflowHandler))
+	s.router.Get("/deployments/:id/workflows/:workflowName", viewerHandlers.Append(acceptHandler(mimeTypeApplicationJSON)).ThenFunc(s.getWorkflowHandler))
+	s.router.Get("/deployments/:id/workflows", viewerHandlers.Append(acceptHandler(mimeTypeApplicationJSON)).ThenFunc(s.listWorkflowsHandler))
+	s.router.Post("/deployments/:id/schedules", operatorHandlers.Append(contentTypeHandler(mimeTypeApplicationJSON)).ThenFunc(s.newWorkflowScheduleHandler))
+	s.router.Get("/deployments/:id/schedules", viewerHandlers.Append(acceptHandler(mimeTypeApplicationJSON)).ThenFunc(s.listWorkflowSchedulesHandler))
+	s.router.Get("/deployments/:id/schedules/:scheduleId", viewerHandlers.Append(acceptHandler(mimeTypeApplicationJSON)).ThenFunc(s.getWorkflowScheduleHandler))
+	s.router.Put("/deployments/:id/schedules/:scheduleId", operatorHandlers.Append(contentTypeHandler(mimeTypeApplicationJSON)).ThenFunc(s.updateWorkflowScheduleHandler))
+	s.router.Delete("/deployments/:id/schedules/:scheduleId", operatorHandlers.ThenFunc(s.deleteWorkflowScheduleHandler))
+	s.router.Post("/deployments/:id/purge", operatorHandlers.Append(acceptHandler(mimeTypeApplicationJSON)).ThenFunc(s.purgeDeploymentHandler))
+	s.router.Get("/deployments/:id/checks", viewerHandlers.Append(acceptHandler(mimeTypeApplicationJSON)).ThenFunc(s.listDeploymentChecksHandler))
+	s.router.Get("/deployments/:id/checks/:nodeName/:instanceId", viewerHandlers.Append(acceptHandler(mimeTypeApplicationJSON)).ThenFunc(s.getCheckHandler))
+	s.router.Patch("/deployments/:id/checks/:nodeName/:instanceId", operatorHandlers.Append(contentTypeHandler(mimeTypeApplicationJSON)).ThenFunc(s.updateCheckHandler))
+	s.router.Get("/checks", viewerHandlers.Append(acceptHandler(mimeTypeApplicationJSON)).ThenFunc(s.listChecksHandler))
+
+	s.router.Post("/notifications", adminHandlers.Append(contentTypeHandler(mimeTypeApplicationJSON)).ThenFunc(s.newWebhookHandler))
+	s.router.Get("/notifications", adminHandlers.Append(acceptHandler(mimeTypeApplicationJSON)).ThenFunc(s.listWebhooksHandler))
+	s.router.Get("/notifications/:webhookId", adminHandlers.Append(acceptHandler(mimeTypeApplicationJSON)).ThenFunc(s.getWebhookHandler))
+	s.router.Put("/notifications/:webhookId", adminHandlers.Append(contentTypeHandler(mimeTypeApplicationJSON)).ThenFunc(s.updateWebhookHandler))
+	s.router.Delete("/notifications/:webhookId", adminHandlers.ThenFunc(s.deleteWebhookHandler))
+	s.router.Get("/notifications/:webhookId/dead_letters", adminHandlers.Append(acceptHandler(mimeTypeApplicationJSON)).ThenFunc(s.listDeadLettersHandler))
+	s.router.Delete("/notifications/:webhookId/dead_letters", adminHandlers.ThenFunc(s.deleteDeadLettersHandler))
+
+	s.router.Get("/registry/delegates", viewerHandlers.Append(acceptHandler(mimeTypeApplicationJSON)).ThenFunc(s.listRegistryDelegatesHandler))
+	s.router.Get("/registry/implementations", viewerHandlers.Append(acceptHandler(mimeTypeApplicationJSON)).ThenFunc(s.listRegistryImplementationsHandler))
+	s.router.Get("/registry/definitions", viewerHandlers.Append(acceptHandler(mimeTypeApplicationJSON)).ThenFunc(s.listRegistryDefinitionsHandler))
+	s.router.Get("/registry/vaults", viewerHandlers.Append(acceptHandler(mimeTypeApplicationJSON)).ThenFunc(s.listVaultsBuilderHandler))
+	s.router.Get("/registry/infra_usage_collectors", viewerHandlers.Append(acceptHandler(mimeTypeApplicationJSON)).ThenFunc(s.listInfraHandler))
+
+	s.router.Post("/infra_usage/:infraName/:locationName", operatorHandlers.Append(contentTypeHandler(mimeTypeApplicationJSON)).ThenFunc(s.postInfraUsageHandler))
+	s.router.Get("/infra_usage/:infraName/:locationName/tasks/:taskId", viewerHandlers.Append(acceptHandler(mimeTypeApplicationJSON)).ThenFunc(s.getTaskQueryHandler))
+	s.router.Delete("/infra_usage/:infraName/:locationName/tasks/:taskId", operatorHandlers.ThenFunc(s.deleteTaskQueryHandler))
+	s.router.Get("/infra_usage", viewerHandlers.Append(acceptHandler(mimeTypeApplicationJSON)).ThenFunc(s.listTaskQueryHandler))
+
+	s.router.Put("/hosts_pool/:location/:host", adminHandlers.Append(contentTypeHandler(mimeTypeApplicationJSON)).ThenFunc(s.newHostInPool))
+	s.router.Patch("/hosts_pool/:location/:host", adminHandlers.Append(contentTypeHandler(mimeTypeApplicationJSON)).ThenFunc(s.updateHostInPool))
+	s.router.Delete("/hosts_pool/:location/:host", adminHandlers.ThenFunc(s.deleteHostInPool))
+	s.router.Post("/hosts_pool/:location", adminHandlers.Append(contentTypeHandler(mimeTypeApplicationJSON)).ThenFunc(s.applyHostsPool))
+	s.router.Put("/hosts_pool/:location", adminHandlers.Append(contentTypeHandler(mimeTypeApplicationJSON)).ThenFunc(s.applyHostsPool))
+	s.router.Get("/hosts_pool/:location", viewerHandlers.Append(acceptHandler(mimeTypeApplicationJSON)).ThenFunc(s.listHostsInPool))
+	s.router.Get("/hosts_pool/:location/:host", viewerHandlers.Append(acceptHandler(mimeTypeApplicationJSON)).ThenFunc(s.getHostInPoolOrReservations))
+	// The router doesn't allow a static segment and a wildcard at the same place so reservations routes are
+	// registered using the host wildcard that is expected to be "reservations"
+	s.router.Put("/hosts_pool/:location/:host/:name", adminHandlers.Append(contentTypeHandler(mimeTypeApplicationJSON)).ThenFunc(s.newReservation))
+	s.router.Get("/hosts_pool/:location/:host/:name", viewerHandlers.Append(acceptHandler(mimeTypeApplicationJSON)).ThenFunc(s.getReservation))
+	s.router.Delete("/hosts_pool/:location/:host/:name", adminHandlers.ThenFunc(s.deleteReservation))
+	s.router.Get("/hosts_pool", viewerHandlers.Append(acceptHandler(mimeTypeApplicationJSON)).ThenFunc(s.listHostsPoolLocations))
+
+	s.router.Get(LOCATIONS, viewerHandlers.Append(acceptHandler("application/json")).ThenFunc(s.listLocationsHandler))
+	s.router.Get(LOCATIONURI, viewerHandlers.Append(acceptHandler("application/json")).ThenFunc(s.getLocationHandler))
+	s.router.Put(LOCATIONURI, adminHandlers.Append(contentTypeHandler("application/json")).ThenFunc(s.createLocationHandler))
+	s.router.Patch(LOCATIONURI, adminHandlers.Append(contentTypeHandler("application/json")).ThenFunc(s.updateLocationHandler))
+	s.router.Delete(LOCATIONURI, adminHandlers.ThenFunc(s.deleteLocationHandler))
 
 	if s.config.Telemetry.PrometheusEndpoint {
-		s.router.Get("/metrics", commonHandlers.Then(promhttp.Handler()))
+		s.router.Get("/metrics", viewerHandlers.Then(promhttp.Handler()))
 	}
 }
 
@@ -95,6 +95,13 @@ func (w *statusRecorderResponseWriter) WriteHeader(code int) {
 	w.ResponseWriter.WriteHeader(code)
 }
 
+// Flush implements the http.Flusher interface so streaming handlers can be wrapped by the telemetry handler
+func (w *statusRecorderResponseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
 func telemetryHandler(next http.Handler) http.Handler {
 
 	fn := func(w http.ResponseWriter, r *http.Request) {
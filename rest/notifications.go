@@ -0,0 +1,207 @@
+// Copyright 2021 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rest
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"path"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/pkg/errors"
+
+	"github.com/ystia/yorc/v4/log"
+	"github.com/ystia/yorc/v4/notifications"
+)
+
+func (s *Server) newWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	webhook, ok := readWebhookRequest(w, r)
+	if !ok {
+		return
+	}
+	id, err := notifications.RegisterWebhook(webhook)
+	if err != nil {
+		log.Panic(err)
+	}
+	w.Header().Set("Location", path.Join("/notifications", id))
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (s *Server) updateWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	params := ctx.Value(paramsLookupKey).(httprouter.Params)
+	webhookID := params.ByName("webhookId")
+
+	existing := getWebhook(w, r, webhookID)
+	if existing == nil {
+		return
+	}
+	if existing.Static {
+		writeError(w, r, newBadRequestMessage("Webhooks defined in the server configuration can't be updated"))
+		return
+	}
+	webhook, ok := readWebhookRequest(w, r)
+	if !ok {
+		return
+	}
+	webhook.ID = webhookID
+	if err := notifications.UpdateWebhook(webhook); err != nil {
+		log.Panic(err)
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) deleteWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	params := ctx.Value(paramsLookupKey).(httprouter.Params)
+	webhookID := params.ByName("webhookId")
+
+	webhook := getWebhook(w, r, webhookID)
+	if webhook == nil {
+		return
+	}
+	if webhook.Static {
+		writeError(w, r, newBadRequestMessage("Webhooks defined in the server configuration can't be deleted"))
+		return
+	}
+	if err := notifications.UnregisterWebhook(webhookID); err != nil {
+		log.Panic(err)
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) getWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	params := ctx.Value(paramsLookupKey).(httprouter.Params)
+
+	webhook := getWebhook(w, r, params.ByName("webhookId"))
+	if webhook == nil {
+		return
+	}
+	encodeJSONResponse(w, r, newWebhookRepresentation(webhook))
+}
+
+func (s *Server) listWebhooksHandler(w http.ResponseWriter, r *http.Request) {
+	webhooks, err := notifications.ListWebhooks()
+	if err != nil {
+		log.Panic(err)
+	}
+	webhooks = append(notifications.GetStaticWebhooks(), webhooks...)
+	if len(webhooks) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	col := WebhooksCollection{Webhooks: make([]Webhook, len(webhooks))}
+	for i, webhook := range webhooks {
+		col.Webhooks[i] = newWebhookRepresentation(webhook)
+	}
+	encodeJSONResponse(w, r, col)
+}
+
+func (s *Server) listDeadLettersHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	params := ctx.Value(paramsLookupKey).(httprouter.Params)
+	webhookID := params.ByName("webhookId")
+
+	if getWebhook(w, r, webhookID) == nil {
+		return
+	}
+	deadLetters, err := notifications.ListDeadLetters(webhookID)
+	if err != nil {
+		log.Panic(err)
+	}
+	if len(deadLetters) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	encodeJSONResponse(w, r, DeadLettersCollection{DeadLetters: deadLetters})
+}
+
+func (s *Server) deleteDeadLettersHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	params := ctx.Value(paramsLookupKey).(httprouter.Params)
+	webhookID := params.ByName("webhookId")
+
+	if getWebhook(w, r, webhookID) == nil {
+		return
+	}
+	if err := notifications.DeleteDeadLetters(webhookID); err != nil {
+		log.Panic(err)
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// getWebhook returns the given webhook if it is defined in the server configuration or registered using the REST API
+// otherwise it writes a not found error and returns nil
+func getWebhook(w http.ResponseWriter, r *http.Request, webhookID string) *notifications.Webhook {
+	for _, webhook := range notifications.GetStaticWebhooks() {
+		if webhook.ID == webhookID {
+			return webhook
+		}
+	}
+	webhook, err := notifications.GetWebhook(webhookID)
+	if err != nil {
+		log.Panic(err)
+	}
+	if webhook == nil {
+		writeError(w, r, errNotFound)
+	}
+	return webhook
+}
+
+// readWebhookRequest decodes and checks a webhook definition from the request body
+func readWebhookRequest(w http.ResponseWriter, r *http.Request) (*notifications.Webhook, bool) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		log.Panic(err)
+	}
+	var req WebhookRequest
+	err = json.Unmarshal(body, &req)
+	if err != nil {
+		writeError(w, r, newBadRequestError(err))
+		return nil, false
+	}
+	webhook := &notifications.Webhook{
+		Name:        req.Name,
+		URL:         req.URL,
+		Secret:      req.Secret,
+		Headers:     req.Headers,
+		Deployments: req.Deployments,
+		EventTypes:  req.EventTypes,
+		Statuses:    req.Statuses,
+	}
+	if err = webhook.Validate(); err != nil {
+		writeError(w, r, newBadRequestError(errors.Cause(err)))
+		return nil, false
+	}
+	return webhook, true
+}
+
+func newWebhookRepresentation(webhook *notifications.Webhook) Webhook {
+	res := Webhook{Webhook: *webhook, Signed: webhook.Secret != ""}
+	res.Secret = ""
+	if len(webhook.Headers) > 0 {
+		res.Headers = make(map[string]string, len(webhook.Headers))
+		for k := range webhook.Headers {
+			res.Headers[k] = "<redacted>"
+		}
+	}
+	res.Links = []AtomLink{
+		newAtomLink(LinkRelSelf, path.Join("/notifications", webhook.ID)),
+		newAtomLink(LinkRelDeadLetters, path.Join("/notifications", webhook.ID, "dead_letters")),
+	}
+	return res
+}
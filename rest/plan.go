@@ -0,0 +1,93 @@
+// Copyright 2021 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rest
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/ystia/yorc/v4/deployments"
+	"github.com/ystia/yorc/v4/events"
+	"github.com/ystia/yorc/v4/log"
+	"github.com/ystia/yorc/v4/tasks/workflow"
+)
+
+// isDryRun checks if the dryRun query parameter is set on the request
+func isDryRun(w http.ResponseWriter, r *http.Request) (bool, bool) {
+	dryRun, err := getBoolQueryParam(r, "dryRun")
+	if err != nil {
+		writeError(w, r, newBadRequestMessage("dryRun query parameter must be a boolean value"))
+		return false, false
+	}
+	return dryRun, true
+}
+
+// planDeployment returns the plan of the install workflow of a deployment definition without deploying it
+//
+// The definition is stored under the given unused deployment ID, with events and logs publication disabled,
+// then removed once the plan is computed so that no state is changed.
+func (s *Server) planDeployment(w http.ResponseWriter, r *http.Request, deploymentID string) {
+	log.Printf("Planning deployment %s", deploymentID)
+	ctx := r.Context()
+	if !checkBlockingOperationOnDeployment(ctx, deploymentID, w, r) {
+		return
+	}
+	events.MuteDeployment(deploymentID)
+	defer events.UnmuteDeployment(deploymentID)
+	if err := deployments.AddBlockingOperationOnDeploymentFlag(ctx, deploymentID); err != nil {
+		log.Panic(err)
+	}
+	defer s.removePlannedDeployment(deploymentID)
+
+	yamlFile, archiveErr := unzipArchiveGetTopology(s.config.WorkingDirectory, deploymentID, r)
+	if archiveErr != nil {
+		writeError(w, r, archiveErr)
+		return
+	}
+	if err := deployments.StoreDeploymentDefinition(ctx, deploymentID, yamlFile); err != nil {
+		if deployments.IsConstraintsViolationsError(err) {
+			writeError(w, r, newBadRequestError(err))
+			return
+		}
+		log.Panic(err)
+	}
+	s.planWorkflow(ctx, w, r, deploymentID, "install", workflow.PlanOptions{})
+}
+
+// removePlannedDeployment removes a deployment stored only to compute its plan, unlike a purge it doesn't publish any event
+func (s *Server) removePlannedDeployment(deploymentID string) {
+	ctx := context.Background()
+	if err := deployments.DeleteDeployment(ctx, deploymentID); err != nil {
+		log.Printf("[WARNING] Failed to remove deployment %q stored for a dry run: %v", deploymentID, err)
+	}
+	if err := deployments.RemoveBlockingOperationOnDeploymentFlag(ctx, deploymentID); err != nil {
+		log.Printf("[WARNING] Failed to remove blocking operation flag of deployment %q stored for a dry run: %v", deploymentID, err)
+	}
+	if err := os.RemoveAll(filepath.Join(s.config.WorkingDirectory, "deployments", deploymentID)); err != nil {
+		log.Printf("[WARNING] Failed to remove working directory of deployment %q stored for a dry run: %v", deploymentID, err)
+	}
+}
+
+func (s *Server) planWorkflow(ctx context.Context, w http.ResponseWriter, r *http.Request, deploymentID, workflowName string, opts workflow.PlanOptions) {
+	plan, err := workflow.BuildPlan(ctx, s.config, deploymentID, workflowName, opts)
+	if err != nil {
+		log.Debugf("Failed to plan workflow %q of deployment %q: %+v", workflowName, deploymentID, err)
+		writeError(w, r, newBadRequestError(err))
+		return
+	}
+	encodeJSONResponse(w, r, plan)
+}
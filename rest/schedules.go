@@ -0,0 +1,222 @@
+// Copyright 2021 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"path"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/pkg/errors"
+
+	"github.com/ystia/yorc/v4/deployments"
+	"github.com/ystia/yorc/v4/helper/collections"
+	"github.com/ystia/yorc/v4/log"
+	"github.com/ystia/yorc/v4/prov/scheduling"
+)
+
+func (s *Server) newWorkflowScheduleHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	params := ctx.Value(paramsLookupKey).(httprouter.Params)
+	deploymentID := params.ByName("id")
+
+	if !checkDeploymentExists(ctx, w, r, deploymentID) {
+		return
+	}
+	schedule, ok := readWorkflowScheduleRequest(ctx, w, r, deploymentID)
+	if !ok {
+		return
+	}
+	id, err := scheduling.RegisterWorkflowSchedule(schedule)
+	if err != nil {
+		log.Panic(err)
+	}
+	w.Header().Set("Location", path.Join("/deployments", deploymentID, "schedules", id))
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (s *Server) updateWorkflowScheduleHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	params := ctx.Value(paramsLookupKey).(httprouter.Params)
+	deploymentID := params.ByName("id")
+	scheduleID := params.ByName("scheduleId")
+
+	if !checkDeploymentExists(ctx, w, r, deploymentID) {
+		return
+	}
+	if getDeploymentWorkflowSchedule(w, r, deploymentID, scheduleID) == nil {
+		return
+	}
+	schedule, ok := readWorkflowScheduleRequest(ctx, w, r, deploymentID)
+	if !ok {
+		return
+	}
+	schedule.ID = scheduleID
+	err := scheduling.UpdateWorkflowSchedule(schedule)
+	if err != nil {
+		log.Panic(err)
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) deleteWorkflowScheduleHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	params := ctx.Value(paramsLookupKey).(httprouter.Params)
+	deploymentID := params.ByName("id")
+	scheduleID := params.ByName("scheduleId")
+
+	if getDeploymentWorkflowSchedule(w, r, deploymentID, scheduleID) == nil {
+		return
+	}
+	err := scheduling.UnregisterWorkflowSchedule(scheduleID)
+	if err != nil {
+		log.Panic(err)
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) getWorkflowScheduleHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	params := ctx.Value(paramsLookupKey).(httprouter.Params)
+	deploymentID := params.ByName("id")
+	scheduleID := params.ByName("scheduleId")
+
+	schedule := getDeploymentWorkflowSchedule(w, r, deploymentID, scheduleID)
+	if schedule == nil {
+		return
+	}
+	encodeJSONResponse(w, r, newWorkflowScheduleRepresentation(schedule, time.Now()))
+}
+
+func (s *Server) listWorkflowSchedulesHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	params := ctx.Value(paramsLookupKey).(httprouter.Params)
+	deploymentID := params.ByName("id")
+
+	if !checkDeploymentExists(ctx, w, r, deploymentID) {
+		return
+	}
+	schedules, err := scheduling.ListWorkflowSchedules(deploymentID)
+	if err != nil {
+		log.Panic(err)
+	}
+	if len(schedules) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	now := time.Now()
+	col := WorkflowSchedulesCollection{Schedules: make([]WorkflowSchedule, len(schedules))}
+	for i, schedule := range schedules {
+		col.Schedules[i] = newWorkflowScheduleRepresentation(schedule, now)
+	}
+	encodeJSONResponse(w, r, col)
+}
+
+func checkDeploymentExists(ctx context.Context, w http.ResponseWriter, r *http.Request, deploymentID string) bool {
+	dExits, err := deployments.DoesDeploymentExists(ctx, deploymentID)
+	if err != nil {
+		log.Panicf("%v", err)
+	}
+	if !dExits {
+		writeError(w, r, errNotFound)
+	}
+	return dExits
+}
+
+// getDeploymentWorkflowSchedule returns the given schedule if it exists and belongs to the given deployment
+// otherwise it writes a not found error and returns nil
+func getDeploymentWorkflowSchedule(w http.ResponseWriter, r *http.Request, deploymentID, scheduleID string) *scheduling.WorkflowSchedule {
+	schedule, err := scheduling.GetWorkflowSchedule(scheduleID)
+	if err != nil {
+		log.Panic(err)
+	}
+	if schedule == nil || schedule.DeploymentID != deploymentID {
+		writeError(w, r, errNotFound)
+		return nil
+	}
+	return schedule
+}
+
+// readWorkflowScheduleRequest decodes and checks a workflow schedule definition from the request body
+func readWorkflowScheduleRequest(ctx context.Context, w http.ResponseWriter, r *http.Request, deploymentID string) (*scheduling.WorkflowSchedule, bool) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		log.Panic(err)
+	}
+	var req WorkflowScheduleRequest
+	err = json.Unmarshal(body, &req)
+	if err != nil {
+		writeError(w, r, newBadRequestError(err))
+		return nil, false
+	}
+
+	workflows, err := deployments.GetWorkflows(ctx, deploymentID)
+	if err != nil {
+		log.Panic(err)
+	}
+	if !collections.ContainsString(workflows, req.WorkflowName) {
+		writeError(w, r, newBadRequestParameter("workflow_name", errors.Errorf("Workflow %q must exist", req.WorkflowName)))
+		return nil, false
+	}
+	if missingInput := getMissingWorkflowInput(ctx, deploymentID, req.WorkflowName, req.Inputs); missingInput != "" {
+		writeError(w, r, newBadRequestParameter("inputs", errors.Errorf("Missing value for required workflow input parameter %s", missingInput)))
+		return nil, false
+	}
+	if !checkWorkflowInputsConstraints(ctx, w, r, deploymentID, req.WorkflowName, req.Inputs) {
+		return nil, false
+	}
+
+	schedule := &scheduling.WorkflowSchedule{
+		DeploymentID:    deploymentID,
+		WorkflowName:    req.WorkflowName,
+		Cron:            req.Cron,
+		TimeZone:        req.TimeZone,
+		ContinueOnError: req.ContinueOnError,
+	}
+	if len(req.Inputs) > 0 {
+		schedule.Inputs = make(map[string]string, len(req.Inputs))
+		for k, v := range req.Inputs {
+			schedule.Inputs[k] = fmt.Sprintf("%v", v)
+		}
+	}
+	if err = schedule.Validate(); err != nil {
+		writeError(w, r, newBadRequestError(err))
+		return nil, false
+	}
+	return schedule, true
+}
+
+func newWorkflowScheduleRepresentation(schedule *scheduling.WorkflowSchedule, now time.Time) WorkflowSchedule {
+	res := WorkflowSchedule{WorkflowSchedule: *schedule}
+	next, err := schedule.NextRun(now)
+	if err != nil {
+		log.Printf("[WARN] Failed to compute next run of workflow schedule %q: %v", schedule.ID, err)
+	} else if !next.IsZero() {
+		res.NextRun = &next
+	}
+	res.Links = []AtomLink{
+		newAtomLink(LinkRelSelf, path.Join("/deployments", schedule.DeploymentID, "schedules", schedule.ID)),
+		newAtomLink(LinkRelWorkflow, path.Join("/deployments", schedule.DeploymentID, "workflows", schedule.WorkflowName)),
+	}
+	if schedule.LastTaskID != "" {
+		res.Links = append(res.Links, newAtomLink(LinkRelTask, path.Join("/deployments", schedule.DeploymentID, "tasks", schedule.LastTaskID)))
+	}
+	return res
+}
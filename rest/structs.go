@@ -19,10 +19,13 @@ package rest
 import (
 	"bytes"
 	"encoding/json"
+	"time"
 
 	"github.com/ystia/yorc/v4/config"
 	"github.com/ystia/yorc/v4/deployments/store"
+	"github.com/ystia/yorc/v4/notifications"
 	"github.com/ystia/yorc/v4/prov/hostspool"
+	"github.com/ystia/yorc/v4/prov/scheduling"
 	"github.com/ystia/yorc/v4/registry"
 	"github.com/ystia/yorc/v4/tosca"
 )
@@ -48,6 +51,10 @@ const (
 	LinkRelHost string = "host"
 	// LinkRelLocation defines the AtomLink Rel attribute for relationships of the "location"
 	LinkRelLocation string = "location"
+	// LinkRelSchedule defines the AtomLink Rel attribute for relationships of the "schedule"
+	LinkRelSchedule string = "schedule"
+	// LinkRelDeadLetters defines the AtomLink Rel attribute for relationships of the "dead_letters" (for notifications webhooks)
+	LinkRelDeadLetters string = "dead_letters"
 )
 
 const (
@@ -157,13 +164,15 @@ type OutputsCollection struct {
 
 // Task is the representation of a Yorc' task
 type Task struct {
-	ID           string            `json:"id"`
-	TargetID     string            `json:"target_id"`
-	Type         string            `json:"type"`
-	Status       string            `json:"status"`
-	ErrorMessage string            `json:"error_message,omitempty"`
-	ResultSet    json.RawMessage   `json:"result_set,omitempty"`
-	Outputs      map[string]string `json:"outputs,omitempty"`
+	ID             string            `json:"id"`
+	TargetID       string            `json:"target_id"`
+	Type           string            `json:"type"`
+	Status         string            `json:"status"`
+	ErrorMessage   string            `json:"error_message,omitempty"`
+	ResultSet      json.RawMessage   `json:"result_set,omitempty"`
+	Outputs        map[string]string `json:"outputs,omitempty"`
+	RollbackTaskID string            `json:"rollback_task_id,omitempty"`
+	RollbackOf     string            `json:"rollback_of,omitempty"`
 }
 
 // TasksCollection is the collection of task's links
@@ -221,6 +230,86 @@ type Workflow struct {
 	tosca.Workflow
 }
 
+// WorkflowScheduleRequest allows to define a recurring execution of a workflow
+type WorkflowScheduleRequest struct {
+	WorkflowName string `json:"workflow_name"`
+	// Cron is a standard 5 fields cron expression or a descriptor like @daily
+	Cron string `json:"cron"`
+	// TimeZone is the IANA time zone name in which the cron expression is evaluated, UTC by default
+	TimeZone        string                 `json:"time_zone,omitempty"`
+	ContinueOnError bool                   `json:"continue_on_error,omitempty"`
+	Inputs          map[string]interface{} `json:"inputs,omitempty"`
+}
+
+// WorkflowSchedule is the representation of a workflow schedule
+type WorkflowSchedule struct {
+	scheduling.WorkflowSchedule
+	// NextRun is not set if the schedule will never be triggered
+	NextRun *time.Time `json:"next_run,omitempty"`
+	Links   []AtomLink `json:"links"`
+}
+
+// WorkflowSchedulesCollection is a collection of workflow schedules
+type WorkflowSchedulesCollection struct {
+	Schedules []WorkflowSchedule `json:"schedules"`
+}
+
+// CheckReport is the representation of the report of the monitoring check of a node instance
+type CheckReport struct {
+	DeploymentID string `json:"deployment_id"`
+	NodeName     string `json:"node"`
+	Instance     string `json:"instance"`
+	Status       string `json:"status"`
+	Disabled     bool   `json:"disabled"`
+	// DisabledUntil is the optional date after which a disabled check is automatically enabled again
+	DisabledUntil *time.Time `json:"disabled_until,omitempty"`
+	Links         []AtomLink `json:"links"`
+}
+
+// CheckReportsCollection is a collection of monitoring checks reports
+type CheckReportsCollection struct {
+	Checks []CheckReport `json:"checks"`
+}
+
+// CheckRequest represents a request for temporarily disabling a monitoring check or for enabling it again.
+//
+// DisabledUntil is optional, when set a disabled check is automatically enabled again after this date.
+type CheckRequest struct {
+	Enabled       *bool      `json:"enabled"`
+	DisabledUntil *time.Time `json:"disabled_until,omitempty"`
+}
+
+// WebhookRequest allows to define a webhook notified of deployments events
+type WebhookRequest struct {
+	Name string `json:"name,omitempty"`
+	URL  string `json:"url"`
+	// Secret is used to sign payloads with HMAC-SHA256, it is never returned
+	Secret  string            `json:"secret,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+	// Deployments, EventTypes and Statuses filter notified events, an empty filter matches any value
+	Deployments []string `json:"deployments,omitempty"`
+	EventTypes  []string `json:"event_types,omitempty"`
+	Statuses    []string `json:"statuses,omitempty"`
+}
+
+// Webhook is the representation of a notifications webhook, its secret and headers values are not returned
+type Webhook struct {
+	notifications.Webhook
+	// Signed is true if payloads are signed using a secret
+	Signed bool       `json:"signed"`
+	Links  []AtomLink `json:"links"`
+}
+
+// WebhooksCollection is a collection of notifications webhooks
+type WebhooksCollection struct {
+	Webhooks []Webhook `json:"webhooks"`
+}
+
+// DeadLettersCollection is a collection of notifications that could not be delivered to a webhook
+type DeadLettersCollection struct {
+	DeadLetters []*notifications.DeadLetter `json:"dead_letters"`
+}
+
 // MapEntryOperation is an enumeration of valid values for a MapEntry.Op field
 /*
 ENUM(
@@ -274,8 +363,56 @@ type HostsPoolRequest struct {
 
 // HostRequest represents a request for creating or updating a host in the hosts pool
 type HostRequest struct {
-	Connection *hostspool.Connection `json:"connection,omitempty"`
-	Labels     []MapEntry            `json:"labels,omitempty"`
+	Connection  *hostspool.Connection `json:"connection,omitempty"`
+	Labels      []MapEntry            `json:"labels,omitempty"`
+	Maintenance *HostMaintenance      `json:"maintenance,omitempty"`
+	Lease       *AllocationLease      `json:"lease,omitempty"`
+}
+
+// AllocationLease represents a request for renewing the lease of allocations of a host of the hosts pool.
+//
+// Allocation is optional, if not set leases of all allocations of the host are renewed.
+// Duration is the new duration of the lease from now, as "2h" or "30m".
+type AllocationLease struct {
+	Allocation string `json:"allocation,omitempty"`
+	Duration   string `json:"duration"`
+}
+
+// ReservationRequest represents a request for reserving hosts of the hosts pool.
+//
+// Either Hosts or Count should be provided, Filters only apply to Count.
+// Either Expiry or Duration should be provided.
+type ReservationRequest struct {
+	Owner    string     `json:"owner"`
+	Hosts    []string   `json:"hosts,omitempty"`
+	Count    int        `json:"count,omitempty"`
+	Filters  []string   `json:"filters,omitempty"`
+	Expiry   *time.Time `json:"expiry,omitempty"`
+	Duration string     `json:"duration,omitempty"`
+}
+
+// Reservation is a reservation of hosts in the host pool representation
+//
+// Links are all of type LinkRelSelf.
+type Reservation struct {
+	hostspool.Reservation
+	Warnings []string   `json:"warnings,omitempty"`
+	Links    []AtomLink `json:"links"`
+}
+
+// ReservationsCollection is a collection of reservations of hosts in the host pool
+type ReservationsCollection struct {
+	Reservations []Reservation `json:"reservations"`
+}
+
+// HostMaintenance represents a request for putting a host of the hosts pool in maintenance or
+// for returning it to service.
+//
+// End is optional, when set the host automatically returns to service after this date.
+type HostMaintenance struct {
+	Enabled bool       `json:"enabled"`
+	Reason  string     `json:"reason,omitempty"`
+	End     *time.Time `json:"end,omitempty"`
 }
 
 // HostsPoolLocations represents the host pools locations handled by Yorc
@@ -17,15 +17,97 @@
 package rest
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 
+	"github.com/ystia/yorc/v4/deployments"
+	"github.com/ystia/yorc/v4/internal/operations"
 	"github.com/ystia/yorc/v4/log"
+	"github.com/ystia/yorc/v4/tasks"
 )
 
 // updateDeployment updates a deployment
+//
+// The updated CSAR replaces the current one, the previous overlay is kept aside until
+// the end of the update for tasks uninstalling removed nodes.
 func (s *Server) updateDeployment(w http.ResponseWriter, r *http.Request, id string) {
-	msg := fmt.Sprintf("Trying to update deployment %q on an open source version. Updates are supported only on premium versions.", id)
-	log.Printf("[ERROR]: %s", msg)
-	writeError(w, r, newForbiddenRequest(msg))
+	ctx := r.Context()
+	priority, ok := getTaskPriority(w, r, tasks.TaskTypeAddNodes)
+	if !ok {
+		return
+	}
+	hasLivingTask, livingTaskID, livingTaskStatus, err := tasks.TargetHasLivingTasks(id, []tasks.TaskType{tasks.TaskTypeQuery, tasks.TaskTypeAction})
+	if err != nil {
+		log.Panic(err)
+	}
+	if hasLivingTask {
+		writeError(w, r, newBadRequestError(tasks.NewAnotherLivingTaskAlreadyExistsError(livingTaskID, id, livingTaskStatus)))
+		return
+	}
+	hasBackup, err := operations.HasDeploymentOverlayBackup(id, s.config.WorkingDirectory)
+	if err != nil {
+		log.Panic(err)
+	}
+	if hasBackup {
+		writeError(w, r, newConflictRequest(fmt.Sprintf("Deployment %q can't be updated as a previous update is not completed", id)))
+		return
+	}
+
+	// Concurrent updates are prevented by atomically switching to the UPDATE_IN_PROGRESS status
+	status, ok, err := deployments.CheckAndSetDeploymentStatus(ctx, id, deployments.UPDATE_IN_PROGRESS, deployments.DEPLOYED, deployments.UPDATED, deployments.UPDATE_FAILURE)
+	if err != nil {
+		log.Panic(err)
+	}
+	if !ok {
+		writeError(w, r, newConflictRequest(fmt.Sprintf("Deployment %q can't be updated in status %q, it should be deployed first", id, status)))
+		return
+	}
+
+	log.Printf("Analyzing update of deployment %s\n", id)
+	if err = operations.BackupDeploymentOverlay(id, s.config.WorkingDirectory); err != nil {
+		s.resetDeploymentStatus(ctx, id, status)
+		log.Panic(err)
+	}
+	yamlFile, archiveErr := unzipArchiveGetTopology(s.config.WorkingDirectory, id, r)
+	if archiveErr != nil {
+		log.Printf("Error analyzing archive for update of deployment %s\n", id)
+		s.restoreDeploymentOverlay(id)
+		s.resetDeploymentStatus(ctx, id, status)
+		writeError(w, r, archiveErr)
+		return
+	}
+	update, err := deployments.ComputeTopologyUpdate(ctx, id, yamlFile)
+	if err != nil {
+		s.restoreDeploymentOverlay(id)
+		s.resetDeploymentStatus(ctx, id, status)
+		writeError(w, r, newBadRequestError(err))
+		return
+	}
+	log.Debugf("Update of deployment %q: %+v", id, update)
+
+	taskID, err := operations.StartDeploymentUpdate(ctx, s.tasksCollector, id, yamlFile, s.config.WorkingDirectory, update, priority)
+	if err != nil {
+		s.restoreDeploymentOverlay(id)
+		log.Panic(err)
+	}
+	if taskID == "" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	w.Header().Set("Location", fmt.Sprintf("/deployments/%s/tasks/%s", id, taskID))
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// resetDeploymentStatus puts back the status of a deployment which update was rejected
+func (s *Server) resetDeploymentStatus(ctx context.Context, id string, status deployments.DeploymentStatus) {
+	if err := deployments.SetDeploymentStatus(ctx, id, status); err != nil {
+		log.Printf("[WARNING] failed to reset the status of deployment %q to %q: %v", id, status, err)
+	}
+}
+
+func (s *Server) restoreDeploymentOverlay(id string) {
+	if err := operations.RestoreDeploymentOverlay(id, s.config.WorkingDirectory); err != nil {
+		log.Printf("[WARNING] failed to restore the overlay of deployment %q: %v", id, err)
+	}
 }
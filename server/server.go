@@ -30,6 +30,8 @@ import (
 	"github.com/ystia/yorc/v4/helper/consulutil"
 	"github.com/ystia/yorc/v4/locations"
 	"github.com/ystia/yorc/v4/log"
+	"github.com/ystia/yorc/v4/notifications"
+	"github.com/ystia/yorc/v4/prov/hostspool"
 	"github.com/ystia/yorc/v4/prov/monitoring"
 	"github.com/ystia/yorc/v4/prov/scheduling/scheduler"
 	"github.com/ystia/yorc/v4/rest"
@@ -150,6 +152,10 @@ func RunServer(configuration config.Configuration, shutdownCh chan struct{}) err
 	// Dispatcher needs
 	go workflow.NewDispatcher(configuration, shutdownCh, client, &wg).Run()
 
+	// Start notifications of events to webhooks
+	notifications.Start(configuration, client, shutdownCh)
+	defer notifications.Stop()
+
 	// Start monitoring
 	monitoring.Start(configuration, client)
 	defer monitoring.Stop()
@@ -158,6 +164,14 @@ func RunServer(configuration config.Configuration, shutdownCh chan struct{}) err
 	scheduler.Start(configuration, client)
 	defer scheduler.Stop()
 
+	// Start hosts pools health checks
+	hostspool.StartHealthChecks(configuration, client)
+	defer hostspool.StopHealthChecks()
+
+	// Start release of expired hosts pools allocations and reservations
+	hostspool.StartReaper(configuration, client)
+	defer hostspool.StopReaper()
+
 	signalCh := make(chan os.Signal, 4)
 	signal.Notify(signalCh, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
 	for {
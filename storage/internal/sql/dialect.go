@@ -0,0 +1,122 @@
+// Copyright 2021 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Dialect is the SQL database flavour used by a SQL store
+type Dialect string
+
+const (
+	// SQLite is the dialect of single-node stores backed by a local SQLite database file
+	SQLite Dialect = "sqlite"
+	// PostgreSQL is the dialect of stores backed by a PostgreSQL database shared by Yorc servers
+	PostgreSQL Dialect = "postgresql"
+)
+
+// dialectSpec holds the differences between supported databases
+type dialectSpec struct {
+	// driverName is the name of the database/sql driver
+	driverName string
+	// blobType is the column type used to store raw values
+	blobType string
+	// numberedPlaceholders is true if the driver expects $1, $2... instead of ? placeholders
+	numberedPlaceholders bool
+	// prefixOperator is the operator used to match keys against a pattern returned by prefixPattern
+	prefixOperator string
+	// prefixPattern returns a pattern matching all keys starting with the given prefix
+	prefixPattern func(prefix string) string
+	// childNameExpr is the format of the expression extracting the first path segment of a key
+	// starting at the character offset given as format argument
+	childNameExpr string
+	// extraSchema are dialect specific schema statements, {table} is replaced by the table name
+	extraSchema []string
+	// singleWriter is true if the database doesn't support concurrent writes from several connections
+	singleWriter bool
+	// buildTag is the build tag required to include the driver in Yorc, if any
+	buildTag string
+}
+
+var dialects = map[Dialect]dialectSpec{
+	SQLite: {
+		driverName: "sqlite3",
+		blobType:   "BLOB",
+		// LIKE is case insensitive in SQLite, GLOB is not
+		prefixOperator: "GLOB",
+		prefixPattern: func(prefix string) string {
+			r := strings.NewReplacer("[", "[[]", "*", "[*]", "?", "[?]")
+			return r.Replace(prefix) + "*"
+		},
+		childNameExpr: "CASE WHEN instr(substr(store_key, %[1]d), '/') > 0 THEN substr(store_key, %[1]d, instr(substr(store_key, %[1]d), '/') - 1) ELSE substr(store_key, %[1]d) END",
+		singleWriter:  true,
+		buildTag:      "sqlite",
+	},
+	PostgreSQL: {
+		driverName:           "postgres",
+		blobType:             "BYTEA",
+		numberedPlaceholders: true,
+		prefixOperator:       `LIKE`,
+		prefixPattern: func(prefix string) string {
+			r := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+			return r.Replace(prefix) + "%"
+		},
+		childNameExpr: "split_part(substr(store_key, %[1]d), '/', 1)",
+		// Allows to use an index for LIKE prefix queries whatever the database collation
+		extraSchema: []string{"CREATE INDEX IF NOT EXISTS {table}_key_prefix ON {table} (store_key text_pattern_ops)"},
+	},
+}
+
+func getDialectSpec(dialect Dialect) (dialectSpec, error) {
+	spec, ok := dialects[Dialect(strings.ToLower(string(dialect)))]
+	if !ok {
+		return spec, fmt.Errorf("unsupported SQL dialect %q", dialect)
+	}
+	return spec, nil
+}
+
+// prefixCondition returns the SQL condition matching keys starting with the pattern bound to the next placeholder
+func (d dialectSpec) prefixCondition() string {
+	cond := "store_key " + d.prefixOperator + " ?"
+	if d.prefixOperator == "LIKE" {
+		cond += ` ESCAPE '\'`
+	}
+	return cond
+}
+
+// rebind replaces ? placeholders of a query by the ones expected by the driver
+func (d dialectSpec) rebind(query string) string {
+	if !d.numberedPlaceholders {
+		return query
+	}
+	var b strings.Builder
+	n := 0
+	inLiteral := false
+	for _, c := range query {
+		switch {
+		case c == '\'':
+			inLiteral = !inLiteral
+		case c == '?' && !inLiteral:
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
@@ -0,0 +1,97 @@
+// Copyright 2021 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sql
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ystia/yorc/v4/config"
+	"github.com/ystia/yorc/v4/storage/store"
+)
+
+// postgreSQLDataSourceEnv is the environment variable giving the connection string of the database used by tests,
+// PostgreSQL tests are skipped if it is not set
+const postgreSQLDataSourceEnv = "YORC_TEST_POSTGRESQL_DATA_SOURCE"
+
+func TestRunPostgreSQLStoragePackageTests(t *testing.T) {
+	dataSource := os.Getenv(postgreSQLDataSourceEnv)
+	if dataSource == "" {
+		t.Skipf("%s is not set, skipping PostgreSQL store tests", postgreSQLDataSourceEnv)
+	}
+	cfg := store.SetupTestConfig(t)
+	defer func() {
+		os.RemoveAll(cfg.WorkingDirectory)
+	}()
+
+	t.Run("groupPostgreSQLStore", func(t *testing.T) {
+		t.Run("testPostgreSQLStore", func(t *testing.T) {
+			testPostgreSQLStore(t, cfg, dataSource)
+		})
+		t.Run("testPostgreSQLStoreTypes", func(t *testing.T) {
+			testPostgreSQLStoreTypes(t, cfg, dataSource)
+		})
+		t.Run("testPostgreSQLStoreWithEncryption", func(t *testing.T) {
+			testPostgreSQLStoreWithEncryption(t, cfg, dataSource)
+		})
+		t.Run("testPostgreSQLStoreKeysAndIndexes", func(t *testing.T) {
+			testPostgreSQLStoreKeysAndIndexes(t, cfg, dataSource)
+		})
+	})
+}
+
+// newTestPostgreSQLStore returns a store using a table dedicated to the current test,
+// this table is dropped at the end of the test
+func newTestPostgreSQLStore(t *testing.T, cfg config.Configuration, dataSource string, props config.DynamicMap, withEncryption bool) store.Store {
+	if props == nil {
+		props = config.DynamicMap{}
+	}
+	table := "yorc_test_" + strings.ToLower(strings.NewReplacer("/", "_", "-", "_").Replace(t.Name()))
+	props["data_source"] = dataSource
+	props["table_name"] = table
+	props["blocking_query_period"] = "50ms"
+	s, err := NewStore(cfg, "testStoreID", PostgreSQL, props, withEncryption)
+	require.NoError(t, err, "failed to instantiate new store")
+	t.Cleanup(func() {
+		db := s.(*sqlStore).db
+		db.Exec("DROP TABLE IF EXISTS " + table)
+		db.Exec("DROP TABLE IF EXISTS " + table + "_index")
+	})
+	return s
+}
+
+func testPostgreSQLStore(t *testing.T, cfg config.Configuration, dataSource string) {
+	store.CommonStoreTest(t, newTestPostgreSQLStore(t, cfg, dataSource, nil, false))
+}
+
+func testPostgreSQLStoreTypes(t *testing.T, cfg config.Configuration, dataSource string) {
+	store.CommonStoreTestAllTypes(t, newTestPostgreSQLStore(t, cfg, dataSource, nil, false))
+}
+
+func testPostgreSQLStoreWithEncryption(t *testing.T, cfg config.Configuration, dataSource string) {
+	props := config.DynamicMap{
+		"passphrase": "myverystrongpasswordo32bitlength",
+	}
+	store.CommonStoreTest(t, newTestPostgreSQLStore(t, cfg, dataSource, props, true))
+}
+
+func testPostgreSQLStoreKeysAndIndexes(t *testing.T, cfg config.Configuration, dataSource string) {
+	testStoreKeysAndIndexes(t, func(props config.DynamicMap) store.Store {
+		return newTestPostgreSQLStore(t, cfg, dataSource, props, false)
+	})
+}
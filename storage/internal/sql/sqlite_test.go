@@ -0,0 +1,82 @@
+// Copyright 2021 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build sqlite,cgo
+
+package sql
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ystia/yorc/v4/config"
+	"github.com/ystia/yorc/v4/storage/store"
+)
+
+func TestRunSQLiteStoragePackageTests(t *testing.T) {
+	cfg := store.SetupTestConfig(t)
+	defer func() {
+		os.RemoveAll(cfg.WorkingDirectory)
+	}()
+
+	t.Run("groupSQLiteStore", func(t *testing.T) {
+		t.Run("testSQLiteStore", func(t *testing.T) {
+			testSQLiteStore(t, cfg)
+		})
+		t.Run("testSQLiteStoreTypes", func(t *testing.T) {
+			testSQLiteStoreTypes(t, cfg)
+		})
+		t.Run("testSQLiteStoreWithEncryption", func(t *testing.T) {
+			testSQLiteStoreWithEncryption(t, cfg)
+		})
+		t.Run("testSQLiteStoreKeysAndIndexes", func(t *testing.T) {
+			testSQLiteStoreKeysAndIndexes(t, cfg)
+		})
+	})
+}
+
+func newTestSQLiteStore(t *testing.T, cfg config.Configuration, props config.DynamicMap, withEncryption bool) store.Store {
+	if props == nil {
+		props = config.DynamicMap{}
+	}
+	props["root_dir"] = path.Join(cfg.WorkingDirectory, t.Name())
+	sqlStore, err := NewStore(cfg, "testStoreID", SQLite, props, withEncryption)
+	require.NoError(t, err, "failed to instantiate new store")
+	return sqlStore
+}
+
+func testSQLiteStore(t *testing.T, cfg config.Configuration) {
+	store.CommonStoreTest(t, newTestSQLiteStore(t, cfg, config.DynamicMap{"blocking_query_period": "50ms"}, false))
+}
+
+func testSQLiteStoreTypes(t *testing.T, cfg config.Configuration) {
+	store.CommonStoreTestAllTypes(t, newTestSQLiteStore(t, cfg, nil, false))
+}
+
+func testSQLiteStoreWithEncryption(t *testing.T, cfg config.Configuration) {
+	props := config.DynamicMap{
+		"passphrase":            "myverystrongpasswordo32bitlength",
+		"blocking_query_period": "50ms",
+	}
+	store.CommonStoreTest(t, newTestSQLiteStore(t, cfg, props, true))
+}
+
+func testSQLiteStoreKeysAndIndexes(t *testing.T, cfg config.Configuration) {
+	testStoreKeysAndIndexes(t, func(props config.DynamicMap) store.Store {
+		return newTestSQLiteStore(t, cfg, props, false)
+	})
+}
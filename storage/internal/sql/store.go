@@ -0,0 +1,480 @@
+// Copyright 2021 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sql
+
+import (
+	"context"
+	dbsql "database/sql"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+
+	"github.com/ystia/yorc/v4/config"
+	"github.com/ystia/yorc/v4/helper/collections"
+	"github.com/ystia/yorc/v4/log"
+	"github.com/ystia/yorc/v4/storage/encoding"
+	"github.com/ystia/yorc/v4/storage/encryption"
+	"github.com/ystia/yorc/v4/storage/store"
+	"github.com/ystia/yorc/v4/storage/utils"
+)
+
+const defaultTableName = "yorc_store"
+
+const defaultMaxOpenConnections = 10
+
+// defaultBatchSize is the default maximum number of key-values stored in a single transaction
+const defaultBatchSize = 1000
+
+const defaultBlockingQueryTimeout = 5 * time.Minute
+
+const maxBlockingQueryTimeout = 10 * time.Minute
+
+// defaultBlockingQueryPeriod is the default interval between two lookups of the last index during blocking queries
+const defaultBlockingQueryPeriod = 500 * time.Millisecond
+
+var tableNameRegexp = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+type sqlStore struct {
+	id                  string
+	properties          config.DynamicMap
+	spec                dialectSpec
+	db                  *dbsql.DB
+	table               string
+	codec               encoding.Codec
+	withEncryption      bool
+	encryptor           *encryption.Encryptor
+	batchSize           int
+	blockingQueryPeriod time.Duration
+}
+
+// NewStore returns a new SQL store using the given dialect
+//
+// Values are stored in a single table, the index of the last modification is kept in a separate
+// table in order to support blocking queries.
+func NewStore(cfg config.Configuration, storeID string, dialect Dialect, properties config.DynamicMap, withEncryption bool) (store.Store, error) {
+	spec, err := getDialectSpec(dialect)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create SQL store with ID:%q", storeID)
+	}
+	if properties == nil {
+		properties = config.DynamicMap{}
+	}
+
+	s := &sqlStore{
+		id:                  storeID,
+		properties:          properties,
+		spec:                spec,
+		table:               properties.GetStringOrDefault("table_name", defaultTableName),
+		codec:               encoding.JSON,
+		withEncryption:      withEncryption,
+		batchSize:           properties.GetIntOrDefault("batch_size", defaultBatchSize),
+		blockingQueryPeriod: properties.GetDurationOrDefault("blocking_query_period", defaultBlockingQueryPeriod),
+	}
+	if !tableNameRegexp.MatchString(s.table) {
+		return nil, errors.Errorf("Invalid table name %q for SQL store with ID:%q", s.table, storeID)
+	}
+	if s.batchSize <= 0 {
+		s.batchSize = defaultBatchSize
+	}
+
+	if withEncryption {
+		err = s.buildEncryptor()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if !collections.ContainsString(dbsql.Drivers(), spec.driverName) {
+		msg := fmt.Sprintf("SQL driver %q is not available for store with ID:%q", spec.driverName, storeID)
+		if spec.buildTag != "" {
+			msg += fmt.Sprintf(", Yorc should be built with the %q build tag and cgo enabled", spec.buildTag)
+		}
+		return nil, errors.New(msg)
+	}
+
+	dataSource, err := s.getDataSource(cfg, dialect)
+	if err != nil {
+		return nil, err
+	}
+	s.db, err = dbsql.Open(spec.driverName, dataSource)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to open database of SQL store with ID:%q", storeID)
+	}
+	if spec.singleWriter {
+		s.db.SetMaxOpenConns(1)
+	} else {
+		s.db.SetMaxOpenConns(properties.GetIntOrDefault("max_open_connections", defaultMaxOpenConnections))
+	}
+
+	if err = s.initSchema(); err != nil {
+		s.db.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *sqlStore) buildEncryptor() error {
+	var err error
+	// Check a 32-bits passphrase key is provided
+	secretKey := s.properties.GetString("passphrase")
+	if secretKey == "" {
+		return errors.Errorf("Missing passphrase for SQL store encryption with ID:%q", s.id)
+	}
+	if len(secretKey) != 32 {
+		return errors.Errorf("The provided passphrase for SQL store encryption with ID:%q must be 32-bits length", s.id)
+	}
+	s.encryptor, err = encryption.NewEncryptor(hex.EncodeToString([]byte(secretKey)))
+	return err
+}
+
+// getDataSource returns the data source name given in properties,
+// SQLite stores default to a database file per store in the store root directory
+func (s *sqlStore) getDataSource(cfg config.Configuration, dialect Dialect) (string, error) {
+	dataSource := s.properties.GetString("data_source")
+	if dataSource != "" {
+		return dataSource, nil
+	}
+	if dialect != SQLite {
+		return "", errors.Errorf("Missing data_source property for SQL store with ID:%q", s.id)
+	}
+	rootDir := s.properties.GetStringOrDefault("root_dir", path.Join(cfg.WorkingDirectory, "store"))
+	if err := os.MkdirAll(rootDir, 0700); err != nil {
+		return "", errors.Wrapf(err, "failed to create directory for SQL store with ID:%q", s.id)
+	}
+	return filepath.Join(rootDir, s.id+".db"), nil
+}
+
+func (s *sqlStore) initSchema() error {
+	statements := []string{
+		"CREATE TABLE IF NOT EXISTS {table} (store_key TEXT PRIMARY KEY, store_value " + s.spec.blobType + " NOT NULL, modify_index BIGINT NOT NULL)",
+		"CREATE INDEX IF NOT EXISTS {table}_modify_index ON {table} (modify_index)",
+		"CREATE TABLE IF NOT EXISTS {table}_index (id INTEGER PRIMARY KEY, last_index BIGINT NOT NULL)",
+		"INSERT INTO {table}_index (id, last_index) VALUES (1, 0) ON CONFLICT (id) DO NOTHING",
+	}
+	statements = append(statements, s.spec.extraSchema...)
+	for _, stmt := range statements {
+		if _, err := s.db.Exec(s.query(stmt)); err != nil {
+			return errors.Wrapf(err, "failed to initialize schema of SQL store with ID:%q", s.id)
+		}
+	}
+	return nil
+}
+
+// query replaces the {table} pattern by the store table name and binds placeholders for the dialect
+func (s *sqlStore) query(q string) string {
+	return s.spec.rebind(strings.Replace(q, "{table}", s.table, -1))
+}
+
+// treeCondition returns the condition matching a key and all its sub-keys, with the related arguments
+func (s *sqlStore) treeCondition(k string) (string, []interface{}) {
+	return "(store_key = ? OR " + s.spec.prefixCondition() + ")", []interface{}{k, s.spec.prefixPattern(k + "/")}
+}
+
+func (s *sqlStore) withTx(f func(tx *dbsql.Tx) error) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return errors.Wrapf(err, "failed to start transaction on SQL store with ID:%q", s.id)
+	}
+	if err = f(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return errors.Wrapf(tx.Commit(), "failed to commit transaction on SQL store with ID:%q", s.id)
+}
+
+// nextIndex increments and returns the modification index, the index row stays locked until the end of the transaction
+// so modifications are committed in the order of their indexes
+func (s *sqlStore) nextIndex(tx *dbsql.Tx) (int64, error) {
+	_, err := tx.Exec(s.query("UPDATE {table}_index SET last_index = last_index + 1 WHERE id = 1"))
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to increment modification index of SQL store with ID:%q", s.id)
+	}
+	var index int64
+	err = tx.QueryRow(s.query("SELECT last_index FROM {table}_index WHERE id = 1")).Scan(&index)
+	return index, errors.Wrapf(err, "failed to get modification index of SQL store with ID:%q", s.id)
+}
+
+func (s *sqlStore) lastIndex() (uint64, error) {
+	var index int64
+	err := s.db.QueryRow(s.query("SELECT last_index FROM {table}_index WHERE id = 1")).Scan(&index)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to get modification index of SQL store with ID:%q", s.id)
+	}
+	return uint64(index), nil
+}
+
+// treeLastIndex returns the last modification index of a key and its sub-keys, found is false if none exists
+func (s *sqlStore) treeLastIndex(k string) (uint64, bool, error) {
+	cond, args := s.treeCondition(k)
+	var index dbsql.NullInt64
+	err := s.db.QueryRow(s.query("SELECT MAX(modify_index) FROM {table} WHERE "+cond), args...).Scan(&index)
+	if err != nil {
+		return 0, false, errors.Wrapf(err, "failed to get last index for key:%q", k)
+	}
+	return uint64(index.Int64), index.Valid, nil
+}
+
+func (s *sqlStore) encode(v interface{}) ([]byte, error) {
+	data, err := s.codec.Marshal(v)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to marshal value %+v due to error:%+v", v, err)
+	}
+	if s.withEncryption {
+		return s.encryptor.Encrypt(data)
+	}
+	return data, nil
+}
+
+func (s *sqlStore) decrypt(data []byte) ([]byte, error) {
+	if s.withEncryption {
+		return s.encryptor.Decrypt(data)
+	}
+	return data, nil
+}
+
+func (s *sqlStore) Set(ctx context.Context, k string, v interface{}) error {
+	if err := utils.CheckKeyAndValue(k, v); err != nil {
+		return err
+	}
+	return s.SetCollection(ctx, []store.KeyValueIn{{Key: k, Value: v}})
+}
+
+func (s *sqlStore) SetCollection(ctx context.Context, keyValues []store.KeyValueIn) error {
+	if len(keyValues) == 0 {
+		return nil
+	}
+	values := make([][]byte, len(keyValues))
+	for i, kv := range keyValues {
+		if err := utils.CheckKeyAndValue(kv.Key, kv.Value); err != nil {
+			return err
+		}
+		data, err := s.encode(kv.Value)
+		if err != nil {
+			return err
+		}
+		values[i] = data
+	}
+
+	upsert := s.query("INSERT INTO {table} (store_key, store_value, modify_index) VALUES (?, ?, ?) " +
+		"ON CONFLICT (store_key) DO UPDATE SET store_value = excluded.store_value, modify_index = excluded.modify_index")
+	// Large collections (like data migrations) are stored using several transactions
+	for start := 0; start < len(keyValues); start += s.batchSize {
+		end := start + s.batchSize
+		if end > len(keyValues) {
+			end = len(keyValues)
+		}
+		err := s.withTx(func(tx *dbsql.Tx) error {
+			index, err := s.nextIndex(tx)
+			if err != nil {
+				return err
+			}
+			stmt, err := tx.Prepare(upsert)
+			if err != nil {
+				return errors.Wrapf(err, "failed to prepare statement on SQL store with ID:%q", s.id)
+			}
+			defer stmt.Close()
+			for i := start; i < end; i++ {
+				_, err = stmt.Exec(keyValues[i].Key, values[i], index)
+				if err != nil {
+					return errors.Wrapf(err, "failed to store value for key:%q", keyValues[i].Key)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *sqlStore) Get(k string, v interface{}) (bool, error) {
+	if err := utils.CheckKeyAndValue(k, v); err != nil {
+		return false, err
+	}
+
+	var data []byte
+	err := s.db.QueryRow(s.query("SELECT store_value FROM {table} WHERE store_key = ?"), k).Scan(&data)
+	if err == dbsql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to get value for key:%q", k)
+	}
+	data, err = s.decrypt(data)
+	if err != nil {
+		return false, err
+	}
+	return true, errors.Wrapf(s.codec.Unmarshal(data, v), "failed to unmarshal data:%q", string(data))
+}
+
+func (s *sqlStore) Exist(k string) (bool, error) {
+	if err := utils.CheckKey(k); err != nil {
+		return false, err
+	}
+	var count int
+	err := s.db.QueryRow(s.query("SELECT COUNT(*) FROM {table} WHERE store_key = ?"), k).Scan(&count)
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to check existence of key:%q", k)
+	}
+	return count > 0, nil
+}
+
+func (s *sqlStore) Keys(k string) ([]string, error) {
+	prefix := k + "/"
+	// SQL substr() offsets are 1-based characters positions
+	childName := fmt.Sprintf(s.spec.childNameExpr, utf8.RuneCountInString(prefix)+1)
+	rows, err := s.db.Query(s.query("SELECT DISTINCT "+childName+" FROM {table} WHERE "+s.spec.prefixCondition()), s.spec.prefixPattern(prefix))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get sub-keys of key:%q", k)
+	}
+	defer rows.Close()
+
+	var result []string
+	for rows.Next() {
+		var name string
+		if err = rows.Scan(&name); err != nil {
+			return nil, errors.Wrapf(err, "failed to get sub-keys of key:%q", k)
+		}
+		if name != "" {
+			result = append(result, prefix+name)
+		}
+	}
+	return result, errors.Wrapf(rows.Err(), "failed to get sub-keys of key:%q", k)
+}
+
+func (s *sqlStore) Delete(ctx context.Context, k string, recursive bool) error {
+	if err := utils.CheckKey(k); err != nil {
+		return err
+	}
+
+	cond, args := "store_key = ?", []interface{}{k}
+	if recursive {
+		cond, args = s.treeCondition(k)
+	}
+	// Deletions get a new modification index so the last index of deleted keys keeps increasing
+	return s.withTx(func(tx *dbsql.Tx) error {
+		if _, err := s.nextIndex(tx); err != nil {
+			return err
+		}
+		_, err := tx.Exec(s.query("DELETE FROM {table} WHERE "+cond), args...)
+		return errors.Wrapf(err, "failed to delete key:%q", k)
+	})
+}
+
+func (s *sqlStore) GetLastModifyIndex(k string) (uint64, error) {
+	index, found, err := s.treeLastIndex(k)
+	if err != nil || found {
+		return index, err
+	}
+	// The key doesn't exist
+	return s.lastIndex()
+}
+
+func (s *sqlStore) List(ctx context.Context, k string, waitIndex uint64, timeout time.Duration) ([]store.KeyValueOut, uint64, error) {
+	if err := utils.CheckKey(k); err != nil {
+		return nil, 0, err
+	}
+
+	lastIndex, found, err := s.treeLastIndex(k)
+	if err != nil {
+		return nil, 0, err
+	}
+	if waitIndex > 0 && lastIndex <= waitIndex {
+		lastIndex, found, err = s.waitForIndex(ctx, k, waitIndex, timeout)
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+	if !found {
+		index, err := s.lastIndex()
+		return nil, index, err
+	}
+	if lastIndex <= waitIndex {
+		return make([]store.KeyValueOut, 0), waitIndex, nil
+	}
+
+	cond, args := s.treeCondition(k)
+	args = append(args, int64(waitIndex), int64(lastIndex))
+	rows, err := s.db.Query(s.query("SELECT store_key, store_value, modify_index FROM {table} WHERE "+cond+
+		" AND modify_index > ? AND modify_index <= ? ORDER BY modify_index, store_key"), args...)
+	if err != nil {
+		return nil, 0, errors.Wrapf(err, "failed to list values of key:%q", k)
+	}
+	defer rows.Close()
+
+	values := make([]store.KeyValueOut, 0)
+	for rows.Next() {
+		var key string
+		var data []byte
+		var index int64
+		if err = rows.Scan(&key, &data, &index); err != nil {
+			return nil, 0, errors.Wrapf(err, "failed to list values of key:%q", k)
+		}
+		data, err = s.decrypt(data)
+		if err != nil {
+			return nil, 0, err
+		}
+		var value map[string]interface{}
+		if err = s.codec.Unmarshal(data, &value); err != nil {
+			return nil, 0, errors.Wrapf(err, "failed to unmarshal stored value: %q", string(data))
+		}
+		values = append(values, store.KeyValueOut{
+			Key:             key,
+			LastModifyIndex: uint64(index),
+			Value:           value,
+			RawValue:        data,
+		})
+	}
+	return values, lastIndex, errors.Wrapf(rows.Err(), "failed to list values of key:%q", k)
+}
+
+// waitForIndex periodically looks up the last index of a key and its sub-keys until it's greater than waitIndex,
+// the timeout is reached or the context is cancelled
+func (s *sqlStore) waitForIndex(ctx context.Context, k string, waitIndex uint64, timeout time.Duration) (uint64, bool, error) {
+	// Default timeout to 5 minutes if not set as param or as config property
+	if timeout == 0 {
+		timeout = s.properties.GetDurationOrDefault("blocking_query_default_timeout", defaultBlockingQueryTimeout)
+	}
+	if timeout > maxBlockingQueryTimeout {
+		timeout = maxBlockingQueryTimeout
+	}
+	timeAfter := time.After(timeout)
+	ticker := time.NewTicker(s.blockingQueryPeriod)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			log.Debugf("Cancel signal has been received: the store List query is stopped")
+			return s.treeLastIndex(k)
+		case <-timeAfter:
+			log.Debugf("Timeout has been reached: the store List query is stopped")
+			return s.treeLastIndex(k)
+		case <-ticker.C:
+			index, found, err := s.treeLastIndex(k)
+			if err != nil || index > waitIndex {
+				return index, found, err
+			}
+		}
+	}
+}
@@ -0,0 +1,137 @@
+// Copyright 2021 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ystia/yorc/v4/config"
+	"github.com/ystia/yorc/v4/storage/store"
+)
+
+func TestDialectRebind(t *testing.T) {
+	query := `SELECT store_key FROM t WHERE store_key = ? OR store_key LIKE ? ESCAPE '\' AND modify_index > ?`
+	assert.Equal(t, query, dialects[SQLite].rebind(query))
+	assert.Equal(t, `SELECT store_key FROM t WHERE store_key = $1 OR store_key LIKE $2 ESCAPE '\' AND modify_index > $3`, dialects[PostgreSQL].rebind(query))
+	assert.Equal(t, `SELECT '?' FROM t WHERE a = $1`, dialects[PostgreSQL].rebind(`SELECT '?' FROM t WHERE a = ?`))
+}
+
+func TestDialectPrefixPattern(t *testing.T) {
+	tests := []struct {
+		name    string
+		dialect Dialect
+		prefix  string
+		want    string
+	}{
+		{"SQLiteSimple", SQLite, "_yorc/logs/", "_yorc/logs/*"},
+		{"SQLiteEscaped", SQLite, "dep[1]/*?/", "dep[[]1]/[*][?]/*"},
+		{"PostgreSQLSimple", PostgreSQL, "_yorc/logs/", `\_yorc/logs/%`},
+		{"PostgreSQLEscaped", PostgreSQL, `dep%\/`, `dep\%\\/%`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, dialects[tt.dialect].prefixPattern(tt.prefix))
+		})
+	}
+}
+
+func TestNewStoreErrors(t *testing.T) {
+	cfg := store.SetupTestConfig(t)
+	tests := []struct {
+		name           string
+		dialect        Dialect
+		properties     config.DynamicMap
+		withEncryption bool
+		errMsg         string
+	}{
+		{"UnknownDialect", Dialect("oracle"), nil, false, "unsupported SQL dialect"},
+		{"InvalidTableName", PostgreSQL, config.DynamicMap{"table_name": "store; DROP TABLE x"}, false, "Invalid table name"},
+		{"MissingPassphrase", PostgreSQL, nil, true, "Missing passphrase"},
+		{"InvalidPassphrase", PostgreSQL, config.DynamicMap{"passphrase": "tooshort"}, true, "must be 32-bits length"},
+		{"MissingDataSource", PostgreSQL, nil, false, "Missing data_source property"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewStore(cfg, "testStoreID", tt.dialect, tt.properties, tt.withEncryption)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+}
+
+// testStoreKeysAndIndexes checks SQL specific behaviors, newStore should open the same database on each call
+func testStoreKeysAndIndexes(t *testing.T, newStore func(props config.DynamicMap) store.Store) {
+	ctx := context.Background()
+	// A small batch size checks collections are stored using several transactions
+	s := newStore(config.DynamicMap{"batch_size": 2})
+
+	err := s.SetCollection(ctx, []store.KeyValueIn{
+		{Key: "_yorc/logs/dep/1", Value: map[string]string{"content": "one"}},
+		{Key: "_yorc/logs/dep/2", Value: map[string]string{"content": "two"}},
+		{Key: "_yorc/logs/dep/3", Value: map[string]string{"content": "three"}},
+		{Key: "_yorc/logs/dep2/1", Value: map[string]string{"content": "other"}},
+		{Key: "_yorc/logs/dep_x/1", Value: map[string]string{"content": "other"}},
+		{Key: "_yorc/logs/DEP/1", Value: map[string]string{"content": "other"}},
+	})
+	require.NoError(t, err)
+
+	keys, err := s.Keys("_yorc/logs")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"_yorc/logs/dep", "_yorc/logs/dep2", "_yorc/logs/dep_x", "_yorc/logs/DEP"}, keys)
+
+	// Prefixes match whole path segments and are case sensitive
+	kvs, index, err := s.List(ctx, "_yorc/logs/dep", 0, 0)
+	require.NoError(t, err)
+	require.Len(t, kvs, 3)
+	assert.Equal(t, "_yorc/logs/dep/1", kvs[0].Key)
+	assert.Equal(t, "two", kvs[1].Value["content"])
+	assert.True(t, kvs[0].LastModifyIndex < kvs[2].LastModifyIndex, "expecting values stored in different transactions to have different indexes")
+	assert.Equal(t, kvs[2].LastModifyIndex, index)
+
+	// Only values modified after the wait index are returned
+	err = s.Set(ctx, "_yorc/logs/dep/2", map[string]string{"content": "updated"})
+	require.NoError(t, err)
+	kvs, nextIndex, err := s.List(ctx, "_yorc/logs/dep", index, 0)
+	require.NoError(t, err)
+	require.Len(t, kvs, 1)
+	assert.Equal(t, "updated", kvs[0].Value["content"])
+	assert.True(t, nextIndex > index)
+
+	// A cancelled blocking query returns immediately without values
+	cancelledCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	kvs, lastIndex, err := s.List(cancelledCtx, "_yorc/logs/dep", nextIndex, 0)
+	require.NoError(t, err)
+	assert.Len(t, kvs, 0)
+	assert.Equal(t, nextIndex, lastIndex)
+
+	// Data is kept when the store is opened again
+	s = newStore(nil)
+	found, err := s.Exist("_yorc/logs/dep/2")
+	require.NoError(t, err)
+	assert.True(t, found)
+	err = s.Delete(ctx, "_yorc/logs/dep", true)
+	require.NoError(t, err)
+	keys, err = s.Keys("_yorc/logs")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"_yorc/logs/dep2", "_yorc/logs/dep_x", "_yorc/logs/DEP"}, keys)
+	lastModifyIndex, err := s.GetLastModifyIndex("_yorc/logs/dep")
+	require.NoError(t, err)
+	assert.True(t, lastModifyIndex > nextIndex, "expecting a deletion to bump the modification index")
+}
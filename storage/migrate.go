@@ -0,0 +1,581 @@
+// Copyright 2021 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/ystia/yorc/v4/config"
+	"github.com/ystia/yorc/v4/helper/collections"
+	"github.com/ystia/yorc/v4/helper/consulutil"
+	"github.com/ystia/yorc/v4/log"
+	"github.com/ystia/yorc/v4/storage/store"
+	"github.com/ystia/yorc/v4/storage/types"
+)
+
+const defaultMigrationBatchSize = 500
+
+// elasticListPageTimeout is the blocking query timeout used to retrieve the next page of documents from an elastic store
+const elasticListPageTimeout = time.Millisecond
+
+// MigrationOptions defines how data is migrated from a store to another one
+type MigrationOptions struct {
+	// From is the name of the source store
+	From string
+	// To is the name of the target store
+	To string
+	// Types are the store types of the data to migrate
+	Types []types.StoreType
+	// BatchSize is the number of key-values stored at once in the target store.
+	// A checkpoint is saved after each batch.
+	BatchSize int
+	// Restart ignores the checkpoints of a previous interrupted migration
+	Restart bool
+	// CutOver rewrites the stores configuration saved in Consul so that migrated types are provided by the target store
+	CutOver bool
+	// Progress is called after each batch if not nil
+	Progress func(MigrationProgress)
+}
+
+// MigrationProgress reports the progress of the migration of a store type
+type MigrationProgress struct {
+	Type types.StoreType
+	// Done is the number of key-values already in the target store, including the ones copied by a previous run
+	Done int
+	// PrefixesDone is the number of key prefixes already migrated
+	PrefixesDone int
+	// Prefixes is the number of key prefixes to migrate, data is read and verified one prefix at a time
+	Prefixes int
+}
+
+// MigrationReport is the result of the migration of a store type
+type MigrationReport struct {
+	Type types.StoreType
+	// Copied is the number of key-values copied by this run
+	Copied int
+	// Skipped is the number of key-values already copied by a previous run
+	Skipped    int
+	SourceKeys int
+	TargetKeys int
+	// SourceChecksum and TargetChecksum are computed from the checksums of each migrated key prefix
+	SourceChecksum string
+	TargetChecksum string
+}
+
+// migrationCheckpoints persists the last key copied for a store type in order to resume an interrupted migration
+//
+// As key prefixes are migrated one after the other, the last key also identifies the last prefix being migrated.
+type migrationCheckpoints interface {
+	get(storeType types.StoreType) (string, error)
+	save(storeType types.StoreType, lastKey string) error
+	clear(storeType types.StoreType) error
+}
+
+type consulMigrationCheckpoints struct {
+	prefix string
+}
+
+func newConsulMigrationCheckpoints(from, to string) *consulMigrationCheckpoints {
+	return &consulMigrationCheckpoints{prefix: path.Join(consulutil.YorcManagementPrefix, "storage_migrations", from, to)}
+}
+
+func (c *consulMigrationCheckpoints) get(storeType types.StoreType) (string, error) {
+	_, lastKey, err := consulutil.GetStringValue(path.Join(c.prefix, storeType.String()))
+	return lastKey, err
+}
+
+func (c *consulMigrationCheckpoints) save(storeType types.StoreType, lastKey string) error {
+	return consulutil.StoreConsulKeyAsString(path.Join(c.prefix, storeType.String()), lastKey)
+}
+
+func (c *consulMigrationCheckpoints) clear(storeType types.StoreType) error {
+	return consulutil.Delete(path.Join(c.prefix, storeType.String()), false)
+}
+
+type migration struct {
+	opts          MigrationOptions
+	source        store.Store
+	sourceConfig  config.Store
+	target        store.Store
+	targetConfig  config.Store
+	checkpoints   migrationCheckpoints
+	withChecksums bool
+}
+
+// Migrate copies data of the specified types from a configured store to another one.
+//
+// Stores are looked up by name in the stores configuration saved in Consul, in the Yorc configuration and in the default stores.
+// Data is read one key prefix at a time (deployment for logs and events, topology part of a deployment for deployments),
+// so the memory used by the migration is bounded by the size of the largest prefix.
+// Data of a prefix is copied by batches and a checkpoint is saved in Consul after each batch, so an interrupted migration
+// resumes where it stopped unless opts.Restart is set.
+// Once a prefix is copied, key counts and checksums of the source and target stores are compared and a mismatch is reported
+// as an error.
+// If opts.CutOver is set, the stores configuration saved in Consul is then rewritten so that the migrated types are provided
+// by the target store. Yorc servers have to be restarted to take it into account.
+func Migrate(ctx context.Context, cfg config.Configuration, opts MigrationOptions) ([]MigrationReport, error) {
+	if opts.From == "" || opts.To == "" {
+		return nil, errors.New("both source and target stores names are required")
+	}
+	if opts.From == opts.To {
+		return nil, errors.Errorf("source and target stores are the same: %q", opts.From)
+	}
+	if len(opts.Types) == 0 {
+		return nil, errors.New("at least one store type to migrate is required")
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = defaultMigrationBatchSize
+	}
+
+	cfgStores, err := getMigrationConfigStores(cfg)
+	if err != nil {
+		return nil, err
+	}
+	m := &migration{opts: opts, checkpoints: newConsulMigrationCheckpoints(opts.From, opts.To)}
+	m.sourceConfig, m.source, err = getMigrationStore(cfg, cfgStores, opts.From)
+	if err != nil {
+		return nil, err
+	}
+	m.targetConfig, m.target, err = getMigrationStore(cfg, cfgStores, opts.To)
+	if err != nil {
+		return nil, err
+	}
+	if err = m.checkStores(); err != nil {
+		return nil, err
+	}
+
+	reports := make([]MigrationReport, 0, len(opts.Types))
+	for _, storeType := range opts.Types {
+		report, err := m.migrateType(ctx, storeType)
+		if err != nil {
+			return reports, err
+		}
+		reports = append(reports, report)
+	}
+
+	if opts.CutOver {
+		err = cutOverConfigStores(cfg, cfgStores, m.targetConfig, opts.Types)
+	}
+	return reports, err
+}
+
+func isElasticStore(cfgStore config.Store) bool {
+	return strings.ToLower(cfgStore.Implementation) == elasticStoreImpl
+}
+
+func (m *migration) checkStores() error {
+	// Elastic documents ids are not store keys, so they can't be copied elsewhere
+	if isElasticStore(m.sourceConfig) {
+		return errors.Errorf("store %q can't be used as a migration source: %q implementation doesn't provide stored keys", m.sourceConfig.Name, elasticStoreImpl)
+	}
+	if isElasticStore(m.targetConfig) {
+		for _, storeType := range m.opts.Types {
+			if storeType == types.StoreTypeDeployment {
+				return errors.Errorf("store %q can't be used for type %q: %q implementation only supports %q and %q types",
+					m.targetConfig.Name, storeType, elasticStoreImpl, types.StoreTypeLog, types.StoreTypeEvent)
+			}
+		}
+	}
+	// Elastic stores enrich stored documents, checksums are meaningless in this case
+	m.withChecksums = !isElasticStore(m.targetConfig)
+	return nil
+}
+
+func (m *migration) migrateType(ctx context.Context, storeType types.StoreType) (MigrationReport, error) {
+	report := MigrationReport{Type: storeType}
+	log.Printf("Migrating data of type %q from store %q to store %q", storeType, m.opts.From, m.opts.To)
+
+	prefixes, err := storeTypePrefixes(m.source, storeType)
+	if err != nil {
+		return report, errors.Wrapf(err, "failed to list data of type %q from store %q", storeType, m.opts.From)
+	}
+	if err = m.checkTargetPrefixes(storeType, prefixes); err != nil {
+		return report, err
+	}
+
+	var lastKey string
+	if m.opts.Restart {
+		err = m.checkpoints.clear(storeType)
+	} else {
+		lastKey, err = m.checkpoints.get(storeType)
+	}
+	if err != nil {
+		return report, errors.Wrapf(err, "failed to handle migration checkpoint for type %q", storeType)
+	}
+	resumeIndex := -1
+	if lastKey != "" {
+		resumeIndex = checkpointPrefixIndex(prefixes, lastKey)
+		if resumeIndex < 0 {
+			log.Printf("Key %q of migration checkpoint of type %q no longer exists, all data is copied again", lastKey, storeType)
+		} else {
+			log.Printf("Resuming migration of type %q after key %q", storeType, lastKey)
+		}
+	}
+
+	sourceSums := sha256.New()
+	targetSums := sha256.New()
+	for i, prefix := range prefixes {
+		data, err := readPrefixData(ctx, m.source, m.sourceConfig, prefix)
+		if err != nil {
+			return report, errors.Wrapf(err, "failed to read data of type %q from store %q", storeType, m.opts.From)
+		}
+		switch {
+		case i < resumeIndex:
+			// Prefix fully copied by a previous run
+			report.Skipped += len(data)
+		case i == resumeIndex:
+			err = m.copyPrefix(ctx, storeType, data, lastKey, i, len(prefixes), &report)
+		default:
+			err = m.copyPrefix(ctx, storeType, data, "", i, len(prefixes), &report)
+		}
+		if err != nil {
+			return report, err
+		}
+		sourceSum, targetSum, err := m.verify(ctx, storeType, prefix, data, &report)
+		if err != nil {
+			return report, err
+		}
+		sourceSums.Write([]byte(prefix + "\n" + sourceSum + "\n"))
+		targetSums.Write([]byte(prefix + "\n" + targetSum + "\n"))
+	}
+	if m.withChecksums {
+		report.SourceChecksum = hex.EncodeToString(sourceSums.Sum(nil))
+		report.TargetChecksum = hex.EncodeToString(targetSums.Sum(nil))
+	} else {
+		log.Printf("Checksums of type %q are not verified as store %q is an %q store", storeType, m.opts.To, elasticStoreImpl)
+	}
+	return report, errors.Wrapf(m.checkpoints.clear(storeType), "failed to clear migration checkpoint for type %q", storeType)
+}
+
+// copyPrefix copies data of a key prefix by batches, keys lower or equal to skipAfter are considered as already copied
+func (m *migration) copyPrefix(ctx context.Context, storeType types.StoreType, data map[string]json.RawMessage, skipAfter string,
+	prefixIndex, prefixesNb int, report *MigrationReport) error {
+	batch := make([]store.KeyValueIn, 0, m.opts.BatchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := m.target.SetCollection(ctx, batch); err != nil {
+			return errors.Wrapf(err, "failed to store data of type %q in store %q", storeType, m.opts.To)
+		}
+		if err := m.checkpoints.save(storeType, batch[len(batch)-1].Key); err != nil {
+			return errors.Wrapf(err, "failed to save migration checkpoint for type %q", storeType)
+		}
+		report.Copied += len(batch)
+		batch = batch[:0]
+		if m.opts.Progress != nil {
+			m.opts.Progress(MigrationProgress{Type: storeType, Done: report.Copied + report.Skipped, PrefixesDone: prefixIndex, Prefixes: prefixesNb})
+		}
+		return nil
+	}
+	for _, k := range sortedKeys(data) {
+		if skipAfter != "" && k <= skipAfter {
+			report.Skipped++
+			continue
+		}
+		batch = append(batch, store.KeyValueIn{Key: k, Value: data[k]})
+		if len(batch) == m.opts.BatchSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	return flush()
+}
+
+// checkTargetPrefixes checks the target store doesn't contain data of the migrated type under other prefixes than the source one
+//
+// Elastic stores don't provide keys, so this is not checked for them.
+func (m *migration) checkTargetPrefixes(storeType types.StoreType, sourcePrefixes []string) error {
+	if isElasticStore(m.targetConfig) {
+		return nil
+	}
+	targetPrefixes, err := storeTypePrefixes(m.target, storeType)
+	if err != nil {
+		return errors.Wrapf(err, "failed to list data of type %q from store %q", storeType, m.opts.To)
+	}
+	for _, prefix := range targetPrefixes {
+		if !collections.ContainsString(sourcePrefixes, prefix) {
+			return errors.Errorf("target store %q contains data of type %q under %q which doesn't exist in source store %q", m.opts.To, storeType, prefix, m.opts.From)
+		}
+	}
+	return nil
+}
+
+// verify compares the data of a key prefix in the source and target stores and returns their checksums
+func (m *migration) verify(ctx context.Context, storeType types.StoreType, prefix string, sourceData map[string]json.RawMessage, report *MigrationReport) (string, string, error) {
+	targetData, err := readPrefixData(ctx, m.target, m.targetConfig, prefix)
+	if err != nil {
+		return "", "", errors.Wrapf(err, "failed to read data of type %q from store %q", storeType, m.opts.To)
+	}
+	report.SourceKeys += len(sourceData)
+	report.TargetKeys += len(targetData)
+	if len(sourceData) != len(targetData) {
+		return "", "", errors.Errorf("verification failed for type %q: %d keys under %q in source store %q but %d keys in target store %q",
+			storeType, len(sourceData), prefix, m.opts.From, len(targetData), m.opts.To)
+	}
+	if !m.withChecksums {
+		return "", "", nil
+	}
+	sourceChecksum, err := checksum(sourceData)
+	if err != nil {
+		return "", "", errors.Wrapf(err, "failed to compute checksum of type %q for store %q", storeType, m.opts.From)
+	}
+	targetChecksum, err := checksum(targetData)
+	if err != nil {
+		return "", "", errors.Wrapf(err, "failed to compute checksum of type %q for store %q", storeType, m.opts.To)
+	}
+	if sourceChecksum != targetChecksum {
+		return "", "", errors.Errorf("verification failed for type %q: checksum %s of %q in source store %q differs from checksum %s in target store %q",
+			storeType, sourceChecksum, prefix, m.opts.From, targetChecksum, m.opts.To)
+	}
+	return sourceChecksum, targetChecksum, nil
+}
+
+// checkpointPrefixIndex returns the index of the prefix containing the checkpoint key or -1 if not found
+func checkpointPrefixIndex(prefixes []string, lastKey string) int {
+	for i, prefix := range prefixes {
+		if lastKey == prefix || strings.HasPrefix(lastKey, prefix+"/") {
+			return i
+		}
+	}
+	return -1
+}
+
+// Returns the configuration of all known stores by name
+// Stores saved in Consul take precedence over the ones defined in configuration which take precedence over default ones
+func getMigrationConfigStores(cfg config.Configuration) (map[string]config.Store, error) {
+	cfgStores := make(map[string]config.Store)
+	for _, cfgStore := range getDefaultConfigStores(cfg) {
+		cfgStores[cfgStore.Name] = cfgStore
+	}
+	for _, cfgStore := range cfg.Storage.Stores {
+		if cfgStore.Name == "" {
+			continue
+		}
+		if cfgStore.Implementation == fileStoreWithCacheAndEncryptionImpl || cfgStore.Implementation == fileStoreWithCacheImpl || cfgStore.Implementation == fileStoreImpl {
+			cfgStore.Properties = completePropertiesWithDefault(cfg, cfgStore.Properties)
+		}
+		cfgStores[cfgStore.Name] = cfgStore
+	}
+
+	kvps, _, err := consulutil.GetKV().List(consulutil.StoresPrefix, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+	}
+	for _, kvp := range kvps {
+		cfgStore := config.Store{}
+		err = json.Unmarshal(kvp.Value, &cfgStore)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to unmarshal store with name:%q", path.Base(kvp.Key))
+		}
+		cfgStores[cfgStore.Name] = cfgStore
+	}
+	return cfgStores, nil
+}
+
+func getMigrationStore(cfg config.Configuration, cfgStores map[string]config.Store, name string) (config.Store, store.Store, error) {
+	cfgStore, ok := cfgStores[name]
+	if !ok {
+		return cfgStore, nil, errors.Errorf("unknown store with name:%q", name)
+	}
+	storeImpl, err := createStoreImpl(cfg, cfgStore)
+	if err != nil {
+		return cfgStore, nil, errors.Wrapf(err, "failed to create store with name:%q", name)
+	}
+	if storeImpl == nil {
+		return cfgStore, nil, errors.Errorf("unknown implementation %q for store with name:%q", cfgStore.Implementation, name)
+	}
+	return cfgStore, storeImpl, nil
+}
+
+// Returns the sorted key prefixes under which data of a given store type is stored
+func storeTypePrefixes(s store.Store, storeType types.StoreType) ([]string, error) {
+	var prefixes []string
+	switch storeType {
+	case types.StoreTypeLog:
+		return sortedStoreKeys(s, consulutil.LogsPrefix)
+	case types.StoreTypeEvent:
+		return sortedStoreKeys(s, consulutil.EventsPrefix)
+	case types.StoreTypeDeployment:
+		// Deployments root also contains data which are not handled by stores like instances, status or tasks
+		depKeys, err := sortedStoreKeys(s, consulutil.DeploymentKVPrefix)
+		if err != nil {
+			return nil, err
+		}
+		for _, depKey := range depKeys {
+			topoKeys, err := sortedStoreKeys(s, path.Join(depKey, "topology"))
+			if err != nil {
+				return nil, err
+			}
+			for _, topoKey := range topoKeys {
+				if path.Base(topoKey) != "instances" {
+					prefixes = append(prefixes, topoKey)
+				}
+			}
+		}
+		return append(prefixes, consulutil.CommonsTypesKVPrefix), nil
+	}
+	return nil, errors.Errorf("unsupported store type %q", storeType)
+}
+
+func sortedStoreKeys(s store.Store, k string) ([]string, error) {
+	keys, err := s.Keys(k)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// Reads all key-values stored under a given key prefix
+func readPrefixData(ctx context.Context, s store.Store, cfgStore config.Store, prefix string) (map[string]json.RawMessage, error) {
+	data := make(map[string]json.RawMessage)
+	return data, listStoreData(ctx, s, cfgStore, prefix, data)
+}
+
+func listStoreData(ctx context.Context, s store.Store, cfgStore config.Store, k string, data map[string]json.RawMessage) error {
+	kvs, lastIndex, err := s.List(ctx, k, 0, 0)
+	for {
+		if err != nil {
+			return err
+		}
+		for _, kv := range kvs {
+			data[kv.Key] = json.RawMessage(kv.RawValue)
+		}
+		// Only elastic stores return paginated results
+		if !isElasticStore(cfgStore) || len(kvs) == 0 {
+			return nil
+		}
+		var index uint64
+		kvs, index, err = s.List(ctx, k, lastIndex, elasticListPageTimeout)
+		if err == nil && index <= lastIndex {
+			return nil
+		}
+		lastIndex = index
+	}
+}
+
+func sortedKeys(data map[string]json.RawMessage) []string {
+	keys := make([]string, 0, len(data))
+	for k := range data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Computes a SHA-256 checksum of key-values which doesn't depend on JSON formatting of values
+func checksum(data map[string]json.RawMessage) (string, error) {
+	h := sha256.New()
+	for _, k := range sortedKeys(data) {
+		var v interface{}
+		decoder := json.NewDecoder(bytes.NewReader(data[k]))
+		decoder.UseNumber()
+		if err := decoder.Decode(&v); err != nil {
+			return "", errors.Wrapf(err, "failed to decode value of key %q", k)
+		}
+		canonical, err := json.Marshal(v)
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to encode value of key %q", k)
+		}
+		h.Write([]byte(k))
+		h.Write([]byte{'\n'})
+		h.Write(canonical)
+		h.Write([]byte{'\n'})
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Rewrites stores config saved in Consul so that migrated types are provided by the target store
+func cutOverConfigStores(cfg config.Configuration, cfgStores map[string]config.Store, target config.Store, storeTypes []types.StoreType) error {
+	consulClient, err := cfg.GetConsulClient()
+	if err != nil {
+		return err
+	}
+	lock, err := consulutil.AcquireLock(consulClient, ".lock_stores", 0)
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	kvps, _, err := consulClient.KV().List(consulutil.StoresPrefix, nil)
+	if err != nil {
+		return errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+	}
+	var savedStores []config.Store
+	if len(kvps) == 0 {
+		// Stores have never been loaded by a server
+		savedStores, err = checkAndBuildConfigStores(cfg)
+		if err != nil {
+			return err
+		}
+	}
+	for _, kvp := range kvps {
+		savedStores = append(savedStores, cfgStores[path.Base(kvp.Key)])
+	}
+
+	migrated := make([]string, 0, len(storeTypes))
+	for _, storeType := range storeTypes {
+		migrated = append(migrated, strings.ToLower(storeType.String()))
+	}
+	// The target store keeps the types it already provides, if any
+	target.Types = nil
+	for _, savedStore := range savedStores {
+		if savedStore.Name == target.Name {
+			target.Types = removeStoreTypes(savedStore.Types, migrated)
+			continue
+		}
+		savedStore.Types = removeStoreTypes(savedStore.Types, migrated)
+		storeKey := path.Join(consulutil.StoresPrefix, savedStore.Name)
+		if len(savedStore.Types) == 0 {
+			log.Printf("Removing store config with name:%q which no longer provides any type", savedStore.Name)
+			err = consulutil.Delete(storeKey, false)
+		} else {
+			err = consulutil.StoreConsulKeyWithJSONValue(storeKey, savedStore)
+		}
+		if err != nil {
+			return errors.Wrapf(err, "failed to save store %s in consul", savedStore.Name)
+		}
+	}
+	for _, storeType := range storeTypes {
+		target.Types = append(target.Types, storeType.String())
+	}
+	log.Printf("Store with name:%q now provides types %v. Yorc servers have to be restarted to use it.", target.Name, target.Types)
+	if cfg.Storage.Reset {
+		log.Printf("[WARNING] Storage reset is enabled in configuration: stores configuration has to be updated accordingly, otherwise it will be overwritten at next server start.")
+	}
+	return errors.Wrapf(consulutil.StoreConsulKeyWithJSONValue(path.Join(consulutil.StoresPrefix, target.Name), target), "failed to save store %s in consul", target.Name)
+}
+
+func removeStoreTypes(storeTypes []string, removed []string) []string {
+	result := make([]string, 0, len(storeTypes))
+	for _, storeType := range storeTypes {
+		if !collections.ContainsString(removed, strings.ToLower(storeType)) {
+			result = append(result, storeType)
+		}
+	}
+	return result
+}
@@ -0,0 +1,206 @@
+// Copyright 2021 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ystia/yorc/v4/config"
+	"github.com/ystia/yorc/v4/storage/store"
+	"github.com/ystia/yorc/v4/storage/types"
+)
+
+type memMigrationCheckpoints map[types.StoreType]string
+
+func (c memMigrationCheckpoints) get(storeType types.StoreType) (string, error) {
+	return c[storeType], nil
+}
+
+func (c memMigrationCheckpoints) save(storeType types.StoreType, lastKey string) error {
+	c[storeType] = lastKey
+	return nil
+}
+
+func (c memMigrationCheckpoints) clear(storeType types.StoreType) error {
+	delete(c, storeType)
+	return nil
+}
+
+func newTestMigrationFileStore(t *testing.T, cfg config.Configuration, name string) (config.Store, store.Store) {
+	cfgStore := config.Store{
+		Name:           name,
+		Implementation: fileStoreImpl,
+		Types:          []string{types.StoreTypeLog.String()},
+		Properties:     config.DynamicMap{"root_dir": path.Join(cfg.WorkingDirectory, t.Name(), name)},
+	}
+	s, err := createStoreImpl(cfg, cfgStore)
+	require.NoError(t, err)
+	return cfgStore, s
+}
+
+func TestMigrateStoreType(t *testing.T) {
+	cfg := store.SetupTestConfig(t)
+	defer os.RemoveAll(cfg.WorkingDirectory)
+	ctx := context.Background()
+
+	sourceConfig, source := newTestMigrationFileStore(t, cfg, "source")
+	targetConfig, target := newTestMigrationFileStore(t, cfg, "target")
+	keyValues := []store.KeyValueIn{
+		{Key: "_yorc/logs/dep1/1", Value: map[string]interface{}{"content": "log1", "level": 1}},
+		{Key: "_yorc/logs/dep1/2", Value: map[string]interface{}{"content": "log2", "level": 2}},
+		{Key: "_yorc/logs/dep2/1", Value: map[string]interface{}{"content": "log3", "level": 3}},
+	}
+	require.NoError(t, source.SetCollection(ctx, keyValues))
+
+	checkpoints := memMigrationCheckpoints{}
+	var progress []MigrationProgress
+	m := &migration{
+		opts: MigrationOptions{From: "source", To: "target", BatchSize: 2, Progress: func(p MigrationProgress) {
+			progress = append(progress, p)
+		}},
+		source:        source,
+		sourceConfig:  sourceConfig,
+		target:        target,
+		targetConfig:  targetConfig,
+		checkpoints:   checkpoints,
+		withChecksums: true,
+	}
+
+	// Simulate an interrupted migration: first key has already been copied
+	require.NoError(t, target.SetCollection(ctx, keyValues[:1]))
+	checkpoints[types.StoreTypeLog] = keyValues[0].Key
+
+	report, err := m.migrateType(ctx, types.StoreTypeLog)
+	require.NoError(t, err)
+	require.Equal(t, 2, report.Copied)
+	require.Equal(t, 1, report.Skipped)
+	require.Equal(t, 3, report.SourceKeys)
+	require.Equal(t, 3, report.TargetKeys)
+	require.NotEmpty(t, report.SourceChecksum)
+	require.Equal(t, report.SourceChecksum, report.TargetChecksum)
+	require.Equal(t, []MigrationProgress{
+		{Type: types.StoreTypeLog, Done: 2, PrefixesDone: 0, Prefixes: 2},
+		{Type: types.StoreTypeLog, Done: 3, PrefixesDone: 1, Prefixes: 2},
+	}, progress)
+	require.Empty(t, checkpoints, "checkpoint should be cleared once migration is done")
+
+	var value map[string]interface{}
+	found, err := target.Get("_yorc/logs/dep2/1", &value)
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, "log3", value["content"])
+
+	// A checkpoint in the last prefix skips previous prefixes
+	checkpoints[types.StoreTypeLog] = keyValues[2].Key
+	report, err = m.migrateType(ctx, types.StoreTypeLog)
+	require.NoError(t, err)
+	require.Equal(t, 0, report.Copied)
+	require.Equal(t, 3, report.Skipped)
+	require.Equal(t, report.SourceChecksum, report.TargetChecksum)
+
+	// Verification fails if target store differs from source
+	sourceData := map[string]json.RawMessage{
+		"_yorc/logs/dep2/1": json.RawMessage(`{"content":"modified","level":3}`),
+	}
+	_, _, err = m.verify(ctx, types.StoreTypeLog, "_yorc/logs/dep2", sourceData, &report)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "checksum")
+	delete(sourceData, "_yorc/logs/dep2/1")
+	_, _, err = m.verify(ctx, types.StoreTypeLog, "_yorc/logs/dep2", sourceData, &report)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "keys")
+
+	// Target store should not contain data missing from source
+	require.NoError(t, target.Set(ctx, "_yorc/logs/dep3/1", map[string]interface{}{"content": "other"}))
+	_, err = m.migrateType(ctx, types.StoreTypeLog)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "_yorc/logs/dep3")
+}
+
+func TestMigrationCheckStores(t *testing.T) {
+	fileStore := config.Store{Name: "file", Implementation: fileStoreImpl}
+	elasticStore := config.Store{Name: "es", Implementation: "Elastic"}
+
+	m := &migration{sourceConfig: elasticStore, targetConfig: fileStore, opts: MigrationOptions{Types: []types.StoreType{types.StoreTypeLog}}}
+	require.Error(t, m.checkStores(), "elastic store can't be a source")
+
+	m = &migration{sourceConfig: fileStore, targetConfig: elasticStore, opts: MigrationOptions{Types: []types.StoreType{types.StoreTypeLog, types.StoreTypeDeployment}}}
+	require.Error(t, m.checkStores(), "elastic store doesn't support deployments")
+
+	m = &migration{sourceConfig: fileStore, targetConfig: elasticStore, opts: MigrationOptions{Types: []types.StoreType{types.StoreTypeEvent}}}
+	require.NoError(t, m.checkStores())
+	require.False(t, m.withChecksums)
+
+	m = &migration{sourceConfig: fileStore, targetConfig: fileStore, opts: MigrationOptions{Types: []types.StoreType{types.StoreTypeDeployment}}}
+	require.NoError(t, m.checkStores())
+	require.True(t, m.withChecksums)
+}
+
+func TestMigrationChecksum(t *testing.T) {
+	sum1, err := checksum(map[string]json.RawMessage{
+		"a": json.RawMessage(`{"x": 1, "y": "z"}`),
+		"b": json.RawMessage(`12345678901234567890`),
+	})
+	require.NoError(t, err)
+	sum2, err := checksum(map[string]json.RawMessage{
+		"b": json.RawMessage(`12345678901234567890`),
+		"a": json.RawMessage(`{"y":"z","x":1}`),
+	})
+	require.NoError(t, err)
+	require.Equal(t, sum1, sum2, "checksum should not depend on JSON formatting")
+
+	sum3, err := checksum(map[string]json.RawMessage{
+		"a": json.RawMessage(`{"y":"z","x":1}`),
+		"c": json.RawMessage(`12345678901234567890`),
+	})
+	require.NoError(t, err)
+	require.NotEqual(t, sum1, sum3, "checksum should depend on keys")
+
+	_, err = checksum(map[string]json.RawMessage{"a": json.RawMessage(`{`)})
+	require.Error(t, err)
+}
+
+func TestRemoveStoreTypes(t *testing.T) {
+	require.Equal(t, []string{"Deployment"}, removeStoreTypes([]string{"Deployment", "Log", "event"}, []string{"log", "event"}))
+	require.Equal(t, []string{}, removeStoreTypes([]string{"Log"}, []string{"log"}))
+}
+
+func TestDeploymentStoreTypePrefixes(t *testing.T) {
+	cfg := store.SetupTestConfig(t)
+	defer os.RemoveAll(cfg.WorkingDirectory)
+	ctx := context.Background()
+
+	_, s := newTestMigrationFileStore(t, cfg, "deployments")
+	require.NoError(t, s.SetCollection(ctx, []store.KeyValueIn{
+		{Key: "_yorc/deployments/dep1/topology/nodes/Compute", Value: map[string]interface{}{"type": "tosca.nodes.Compute"}},
+		{Key: "_yorc/deployments/dep1/topology/types/tosca.nodes.Compute", Value: map[string]interface{}{"name": "tosca.nodes.Compute"}},
+		{Key: "_yorc/deployments/dep1/topology/instances/Compute/0", Value: map[string]interface{}{"state": "started"}},
+		{Key: "_yorc/commons_types/tosca-normative-types/1.0.0/types/tosca.nodes.Root", Value: map[string]interface{}{"name": "tosca.nodes.Root"}},
+	}))
+
+	prefixes, err := storeTypePrefixes(s, types.StoreTypeDeployment)
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"_yorc/deployments/dep1/topology/nodes",
+		"_yorc/deployments/dep1/topology/types",
+		"_yorc/commons_types",
+	}, prefixes)
+}
@@ -33,6 +33,7 @@ import (
 	"github.com/ystia/yorc/v4/storage/internal/consul"
 	"github.com/ystia/yorc/v4/storage/internal/elastic"
 	"github.com/ystia/yorc/v4/storage/internal/file"
+	"github.com/ystia/yorc/v4/storage/internal/sql"
 	"github.com/ystia/yorc/v4/storage/store"
 	"github.com/ystia/yorc/v4/storage/types"
 )
@@ -49,6 +50,14 @@ const fileStoreWithCacheImpl = "fileCache"
 
 const fileStoreWithCacheAndEncryptionImpl = "cipherFileCache"
 
+const sqliteStoreImpl = "sqlite"
+
+const sqliteStoreWithEncryptionImpl = "cipherSqlite"
+
+const postgresqlStoreImpl = "postgresql"
+
+const postgresqlStoreWithEncryptionImpl = "cipherPostgresql"
+
 const defaultRelativeRootDir = "store"
 
 const defaultBlockingQueryTimeout = "5m0s"
@@ -362,6 +371,16 @@ func createStoreImpl(cfg config.Configuration, configStore config.Store) (store.
 		if err != nil {
 			return nil, err
 		}
+	case strings.ToLower(sqliteStoreImpl), strings.ToLower(sqliteStoreWithEncryptionImpl):
+		storeImpl, err = sql.NewStore(cfg, configStore.Name, sql.SQLite, configStore.Properties, impl == strings.ToLower(sqliteStoreWithEncryptionImpl))
+		if err != nil {
+			return nil, err
+		}
+	case strings.ToLower(postgresqlStoreImpl), strings.ToLower(postgresqlStoreWithEncryptionImpl):
+		storeImpl, err = sql.NewStore(cfg, configStore.Name, sql.PostgreSQL, configStore.Properties, impl == strings.ToLower(postgresqlStoreWithEncryptionImpl))
+		if err != nil {
+			return nil, err
+		}
 	case strings.ToLower(consulStoreImpl):
 		storeImpl = consul.NewStore()
 	case strings.ToLower(elasticStoreImpl):
@@ -15,6 +15,7 @@
 package storage
 
 import (
+	"context"
 	"encoding/json"
 	"os"
 	"path"
@@ -53,12 +54,18 @@ func TestRunConsulStoragePackageTests(t *testing.T) {
 		t.Run("testLoadStoresWithMissingPassphraseForCipherFileCache", func(t *testing.T) {
 			testLoadStoresWithMissingPassphraseForCipherFileCache(t, srv, cfg)
 		})
+		t.Run("testLoadStoresWithMissingDataSourceForPostgresql", func(t *testing.T) {
+			testLoadStoresWithMissingDataSourceForPostgresql(t, srv, cfg)
+		})
 		t.Run("testLoadStoresWithMissingMandatoryParameters", func(t *testing.T) {
 			testLoadStoresWithMissingMandatoryParameters(t, srv, cfg)
 		})
 		t.Run("testLoadStoresWithGeneratedName", func(t *testing.T) {
 			testLoadStoresWithGeneratedName(t, srv, cfg)
 		})
+		t.Run("testMigrateWithCutOver", func(t *testing.T) {
+			testMigrateWithCutOver(t, srv, cfg)
+		})
 	})
 }
 
@@ -344,6 +351,32 @@ func testLoadStoresWithMissingPassphraseForCipherFileCache(t *testing.T, srv1 *t
 	require.Len(t, MapStores, 0)
 }
 
+func testLoadStoresWithMissingDataSourceForPostgresql(t *testing.T, srv1 *testutil.TestServer, cfg config.Configuration) {
+	// Reset once to allow reload config
+	once.Reset()
+
+	myStore := config.Store{
+		Name:           "myPostgresqlStore",
+		Implementation: "postgresql",
+		Types:          []string{"Log", "Event"},
+	}
+
+	cfg.Storage = config.Storage{
+		Reset:  true,
+		Stores: []config.Store{myStore},
+	}
+
+	err := LoadStores(cfg)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "Missing data_source property")
+
+	// Check stores config has been cleared in Consul
+	MapStores, err := consulutil.List(consulutil.StoresPrefix)
+	require.NoError(t, err)
+	require.NotNil(t, MapStores)
+	require.Len(t, MapStores, 0)
+}
+
 func testLoadStoresWithMissingMandatoryParameters(t *testing.T, srv1 *testutil.TestServer, cfg config.Configuration) {
 	tests := []struct {
 		name     string
@@ -441,3 +474,76 @@ func testLoadStoresWithGeneratedName(t *testing.T, srv1 *testutil.TestServer, cf
 		})
 	}
 }
+
+func testMigrateWithCutOver(t *testing.T, srv1 *testutil.TestServer, cfg config.Configuration) {
+	deploymentID := t.Name()
+	ctx := context.Background()
+
+	b, err := json.Marshal(map[string]string{"content": "content1"})
+	require.NoError(t, err)
+	logKeys := []string{path.Join(consulutil.LogsPrefix, deploymentID, "log0001"),
+		path.Join(consulutil.LogsPrefix, deploymentID, "log0002"),
+		path.Join(consulutil.LogsPrefix, deploymentID, "log0003")}
+	for _, key := range logKeys {
+		srv1.PopulateKV(t, map[string][]byte{key: b})
+	}
+
+	targetStore := config.Store{
+		Name:           "migrationTarget",
+		Implementation: "file",
+		Types:          []string{"Log"},
+		Properties:     config.DynamicMap{"root_dir": path.Join(cfg.WorkingDirectory, t.Name())},
+	}
+	// Stores config saved by servers only contains default stores
+	cfg.Storage = config.Storage{}
+	_, err = initConfigStores(cfg)
+	require.NoError(t, err)
+	cfg.Storage = config.Storage{Stores: []config.Store{targetStore}}
+
+	_, err = Migrate(ctx, cfg, MigrationOptions{From: "defaultConsulStore", To: "unknownStore", Types: []types.StoreType{types.StoreTypeLog}})
+	require.Error(t, err)
+
+	var progress []MigrationProgress
+	reports, err := Migrate(ctx, cfg, MigrationOptions{
+		From:      "defaultConsulStore",
+		To:        targetStore.Name,
+		Types:     []types.StoreType{types.StoreTypeLog},
+		BatchSize: 2,
+		CutOver:   true,
+		Progress: func(p MigrationProgress) {
+			progress = append(progress, p)
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, reports, 1)
+	require.Equal(t, 3, reports[0].Copied)
+	require.Equal(t, 3, reports[0].SourceKeys)
+	require.Equal(t, 3, reports[0].TargetKeys)
+	require.Equal(t, reports[0].SourceChecksum, reports[0].TargetChecksum)
+	require.Len(t, progress, 2)
+
+	// Checkpoint is cleared
+	exist, _, err := consulutil.GetStringValue(path.Join(consulutil.YorcManagementPrefix, "storage_migrations", "defaultConsulStore", targetStore.Name, "Log"))
+	require.NoError(t, err)
+	require.False(t, exist)
+
+	// Stores config now use the target store for logs
+	mapStores, err := consulutil.List(consulutil.StoresPrefix)
+	require.NoError(t, err)
+	require.Len(t, mapStores, 3)
+	for k, v := range mapStores {
+		s := new(config.Store)
+		require.NoError(t, json.Unmarshal(v, s))
+		switch path.Base(k) {
+		case "defaultConsulStore":
+			require.Equal(t, []string{"Event"}, s.Types)
+		case "defaultFileStoreWithCache":
+			require.Equal(t, []string{"Deployment"}, s.Types)
+		case targetStore.Name:
+			require.Equal(t, []string{"Log"}, s.Types)
+			require.Equal(t, targetStore.Implementation, s.Implementation)
+		default:
+			t.Errorf("unexpected key:%q", k)
+		}
+	}
+}
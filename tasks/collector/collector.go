@@ -55,7 +55,14 @@ func NewCollector(consulClient *api.Client) *Collector {
 //
 // The task id is returned.
 func (c *Collector) RegisterTaskWithData(targetID string, taskType tasks.TaskType, data map[string]string) (string, error) {
-	return c.registerTask(targetID, taskType, data)
+	return c.RegisterTaskWithPriority(targetID, taskType, data, tasks.DefaultTaskPriority(taskType))
+}
+
+// RegisterTaskWithPriority register a new Task of a given type with some data and a given priority
+//
+// The task id is returned.
+func (c *Collector) RegisterTaskWithPriority(targetID string, taskType tasks.TaskType, data map[string]string, priority tasks.TaskPriority) (string, error) {
+	return c.registerTask(targetID, taskType, data, priority)
 }
 
 // RegisterTask register a new Task of a given type.
@@ -126,7 +133,7 @@ func (c *Collector) ResumeTask(ctx context.Context, taskID string) error {
 	return nil
 }
 
-func (c *Collector) registerTask(targetID string, taskType tasks.TaskType, data map[string]string) (string, error) {
+func (c *Collector) registerTask(targetID string, taskType tasks.TaskType, data map[string]string, priority tasks.TaskPriority) (string, error) {
 	// First check if other tasks are running for this target before creating a new one except for Action tasks
 	if tasks.IsDeploymentRelatedTask(taskType) {
 
@@ -182,6 +189,11 @@ func (c *Collector) registerTask(targetID string, taskType tasks.TaskType, data
 			Key:   path.Join(taskPath, "type"),
 			Value: []byte(strconv.Itoa(int(taskType))),
 		},
+		&api.KVTxnOp{
+			Verb:  api.KVSet,
+			Key:   path.Join(taskPath, "priority"),
+			Value: []byte(strconv.Itoa(int(priority))),
+		},
 		&api.KVTxnOp{
 			Verb:  api.KVSet,
 			Key:   path.Join(taskPath, "creationDate"),
@@ -46,6 +46,9 @@ func TestRunConsulTasksPackageTests(t *testing.T) {
 		t.Run("TestGetTaskType", func(t *testing.T) {
 			testGetTaskType(t)
 		})
+		t.Run("TestGetTaskPriority", func(t *testing.T) {
+			testGetTaskPriority(t)
+		})
 		t.Run("TestGetTaskTarget", func(t *testing.T) {
 			testGetTaskTarget(t)
 		})
@@ -94,5 +97,8 @@ func TestRunConsulTasksPackageTests(t *testing.T) {
 		t.Run("TestCheckAndSetTaskErrorMessage", func(t *testing.T) {
 			testCheckAndSetTaskErrorMessage(t)
 		})
+		t.Run("testReserveRollback", func(t *testing.T) {
+			testReserveRollback(t)
+		})
 	})
 }
@@ -0,0 +1,71 @@
+// Copyright 2021 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tasks
+
+import (
+	"path"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/pkg/errors"
+
+	"github.com/ystia/yorc/v4/helper/consulutil"
+)
+
+const (
+	// TaskDataRollbackOnFailure is the name of the task data indicating that a workflow should be rolled back on failure
+	TaskDataRollbackOnFailure = "rollbackOnFailure"
+	// TaskDataRollbackTaskID is the name of the task data referencing the task rolling back a failed workflow task
+	TaskDataRollbackTaskID = "rollbackTaskID"
+	// TaskDataRollbackOf is the name of the task data referencing the failed workflow task rolled back by a task
+	TaskDataRollbackOf = "rollbackOf"
+)
+
+// GetRollbackTaskID returns the ID of the task rolling back the given task or an empty string if there is none
+func GetRollbackTaskID(taskID string) (string, error) {
+	return getOptionalTaskData(taskID, TaskDataRollbackTaskID)
+}
+
+// GetRolledBackTaskID returns the ID of the task rolled back by the given task or an empty string if the given
+// task is not a rollback task
+func GetRolledBackTaskID(taskID string) (string, error) {
+	return getOptionalTaskData(taskID, TaskDataRollbackOf)
+}
+
+// ReserveRollback atomically reserves the registration of the rollback of the given task
+//
+// It returns false if a rollback was already registered or is being registered for this task.
+// On success the rollback task ID is empty until it is set by SetTaskData.
+func ReserveRollback(taskID string) (bool, error) {
+	kvp := &api.KVPair{Key: path.Join(consulutil.TasksPrefix, taskID, "data", TaskDataRollbackTaskID), ModifyIndex: 0}
+	set, _, err := consulutil.GetKV().CAS(kvp, nil)
+	if err != nil {
+		return false, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+	}
+	return set, nil
+}
+
+// ReleaseRollback cancels a reservation made with ReserveRollback, allowing to register the rollback again
+func ReleaseRollback(taskID string) error {
+	_, err := consulutil.GetKV().Delete(path.Join(consulutil.TasksPrefix, taskID, "data", TaskDataRollbackTaskID), nil)
+	return errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+}
+
+func getOptionalTaskData(taskID, dataName string) (string, error) {
+	value, err := GetTaskData(taskID, dataName)
+	if IsTaskDataNotFoundError(err) {
+		return "", nil
+	}
+	return value, err
+}
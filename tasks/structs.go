@@ -47,9 +47,32 @@ CANCELED
 */
 type TaskStatus int
 
+// TaskPriority is an enumerated type for tasks priorities
+//
+// Task executions with a higher priority are dispatched to workers first.
+/*
+ENUM(
+LOW
+NORMAL
+HIGH
+)
+*/
+type TaskPriority int
+
 // IsDeploymentRelatedTask returns true if the task is related to a deployment
 //
 // Typically query and action tasks are not necessary related to a deployment.
 func IsDeploymentRelatedTask(tt TaskType) bool {
 	return !(tt == TaskTypeQuery || tt == TaskTypeAction)
 }
+
+// DefaultTaskPriority returns the priority of a task of the given type when not explicitly set at submission
+//
+// Short-lived tasks like scaling, actions and queries should not wait for long running deployments.
+func DefaultTaskPriority(tt TaskType) TaskPriority {
+	switch tt {
+	case TaskTypeScaleOut, TaskTypeScaleIn, TaskTypeAction, TaskTypeQuery, TaskTypeCustomCommand:
+		return TaskPriorityHIGH
+	}
+	return TaskPriorityNORMAL
+}
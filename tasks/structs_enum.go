@@ -21,6 +21,45 @@ import (
 	"fmt"
 )
 
+const (
+	// TaskPriorityLOW is a TaskPriority of type LOW
+	TaskPriorityLOW TaskPriority = iota
+	// TaskPriorityNORMAL is a TaskPriority of type NORMAL
+	TaskPriorityNORMAL
+	// TaskPriorityHIGH is a TaskPriority of type HIGH
+	TaskPriorityHIGH
+)
+
+const _TaskPriorityName = "LOWNORMALHIGH"
+
+var _TaskPriorityMap = map[TaskPriority]string{
+	0: _TaskPriorityName[0:3],
+	1: _TaskPriorityName[3:9],
+	2: _TaskPriorityName[9:13],
+}
+
+// String implements the Stringer interface.
+func (x TaskPriority) String() string {
+	if str, ok := _TaskPriorityMap[x]; ok {
+		return str
+	}
+	return fmt.Sprintf("TaskPriority(%d)", x)
+}
+
+var _TaskPriorityValue = map[string]TaskPriority{
+	_TaskPriorityName[0:3]:  0,
+	_TaskPriorityName[3:9]:  1,
+	_TaskPriorityName[9:13]: 2,
+}
+
+// ParseTaskPriority attempts to convert a string to a TaskPriority
+func ParseTaskPriority(name string) (TaskPriority, error) {
+	if x, ok := _TaskPriorityValue[name]; ok {
+		return x, nil
+	}
+	return TaskPriority(0), fmt.Errorf("%s is not a valid TaskPriority", name)
+}
+
 const (
 	// TaskStatusINITIAL is a TaskStatus of type INITIAL
 	TaskStatusINITIAL TaskStatus = iota
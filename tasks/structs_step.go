@@ -24,6 +24,7 @@ RUNNING
 DONE
 ERROR
 CANCELED
+TIMEOUT
 )
 */
 type TaskStepStatus int
@@ -32,4 +33,6 @@ type TaskStepStatus int
 type TaskStep struct {
 	Name   string `json:"name"`
 	Status string `json:"status"`
+	// Attempts is the number of times the step activities have been attempted when a retry policy applies
+	Attempts int `json:"attempts,omitempty"`
 }
@@ -33,9 +33,11 @@ const (
 	TaskStepStatusERROR
 	// TaskStepStatusCANCELED is a TaskStepStatus of type CANCELED
 	TaskStepStatusCANCELED
+	// TaskStepStatusTIMEOUT is a TaskStepStatus of type TIMEOUT
+	TaskStepStatusTIMEOUT
 )
 
-const _TaskStepStatusName = "INITIALRUNNINGDONEERRORCANCELED"
+const _TaskStepStatusName = "INITIALRUNNINGDONEERRORCANCELEDTIMEOUT"
 
 var _TaskStepStatusMap = map[TaskStepStatus]string{
 	0: _TaskStepStatusName[0:7],
@@ -43,6 +45,7 @@ var _TaskStepStatusMap = map[TaskStepStatus]string{
 	2: _TaskStepStatusName[14:18],
 	3: _TaskStepStatusName[18:23],
 	4: _TaskStepStatusName[23:31],
+	5: _TaskStepStatusName[31:38],
 }
 
 // String implements the Stringer interface.
@@ -64,6 +67,8 @@ var _TaskStepStatusValue = map[string]TaskStepStatus{
 	strings.ToLower(_TaskStepStatusName[18:23]): 3,
 	_TaskStepStatusName[23:31]:                  4,
 	strings.ToLower(_TaskStepStatusName[23:31]): 4,
+	_TaskStepStatusName[31:38]:                  5,
+	strings.ToLower(_TaskStepStatusName[31:38]): 5,
 }
 
 // ParseTaskStepStatus attempts to convert a string to a TaskStepStatus
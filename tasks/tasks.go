@@ -235,6 +235,31 @@ func GetTaskType(taskID string) (TaskType, error) {
 	return TaskType(typeInt), nil
 }
 
+// GetTaskPriority retrieves the TaskPriority of a task
+//
+// Tasks registered without priority get the default priority of their type.
+func GetTaskPriority(taskID string) (TaskPriority, error) {
+	exist, value, err := consulutil.GetStringValue(path.Join(consulutil.TasksPrefix, taskID, "priority"))
+	if err != nil {
+		return TaskPriorityNORMAL, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+	}
+	if !exist || value == "" {
+		taskType, err := GetTaskType(taskID)
+		if err != nil {
+			return TaskPriorityNORMAL, err
+		}
+		return DefaultTaskPriority(taskType), nil
+	}
+	priorityInt, err := strconv.Atoi(value)
+	if err != nil {
+		return TaskPriorityNORMAL, errors.Wrapf(err, "Invalid task priority:")
+	}
+	if priorityInt < int(TaskPriorityLOW) || priorityInt > int(TaskPriorityHIGH) {
+		return TaskPriorityNORMAL, errors.Errorf("Invalid priority for task with id %q: %q", taskID, value)
+	}
+	return TaskPriority(priorityInt), nil
+}
+
 // GetTaskTarget retrieves the targetID of a task
 func GetTaskTarget(taskID string) (string, error) {
 	exist, value, err := consulutil.GetStringValue(path.Join(consulutil.TasksPrefix, taskID, "targetId"))
@@ -485,12 +510,26 @@ func GetTaskRelatedSteps(taskID string) ([]TaskStep, error) {
 		return nil, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
 	}
 
+	attempts, err := consulutil.List(path.Join(consulutil.TasksPrefix, taskID, "stepsAttempts") + "/")
+	if err != nil {
+		return nil, errors.Wrap(err, consulutil.ConsulGenericErrMsg)
+	}
+
 	for key, value := range kvs {
-		steps = append(steps, TaskStep{Name: path.Base(key), Status: string(value)})
+		step := TaskStep{Name: path.Base(key), Status: string(value)}
+		if a, ok := attempts[path.Join(consulutil.TasksPrefix, taskID, "stepsAttempts", step.Name)]; ok {
+			step.Attempts, _ = strconv.Atoi(string(a))
+		}
+		steps = append(steps, step)
 	}
 	return steps, nil
 }
 
+// SetTaskStepAttempt stores the current attempt number of a task step
+func SetTaskStepAttempt(taskID, stepName string, attempt int) error {
+	return consulutil.StoreConsulKeyAsString(path.Join(consulutil.TasksPrefix, taskID, "stepsAttempts", stepName), strconv.Itoa(attempt))
+}
+
 // GetTaskStepStatus returns the step status of the related step name
 func GetTaskStepStatus(taskID, stepName string) (TaskStepStatus, error) {
 	exist, value, err := consulutil.GetStringValue(path.Join(consulutil.WorkflowsPrefix, taskID, stepName))
@@ -543,7 +582,7 @@ func CheckTaskStepStatusChange(before, after string) (bool, error) {
 		return false, err
 	}
 
-	if (stBefore != TaskStepStatusERROR && stBefore != TaskStepStatusDONE) || (stAfter != TaskStepStatusDONE && stAfter != TaskStepStatusINITIAL) {
+	if (stBefore != TaskStepStatusERROR && stBefore != TaskStepStatusTIMEOUT && stBefore != TaskStepStatusDONE) || (stAfter != TaskStepStatusDONE && stAfter != TaskStepStatusINITIAL) {
 		return false, nil
 	}
 	return true, nil
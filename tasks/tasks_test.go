@@ -110,6 +110,13 @@ func populateKV(t *testing.T, srv *testutil.TestServer) {
 		consulutil.TasksPrefix + "/t18/targetId": []byte("infra_usage:slurm"),
 		consulutil.TasksPrefix + "/t18/status":   []byte("2"),
 		consulutil.TasksPrefix + "/t18/type":     []byte("7"),
+
+		consulutil.TasksPrefix + "/t19/targetId": []byte("id3"),
+		consulutil.TasksPrefix + "/t19/type":     []byte("0"),
+		consulutil.TasksPrefix + "/t19/priority": []byte("2"),
+		consulutil.TasksPrefix + "/t20/targetId": []byte("id3"),
+		consulutil.TasksPrefix + "/t20/type":     []byte("0"),
+		consulutil.TasksPrefix + "/t20/priority": []byte("12"),
 	})
 }
 
@@ -222,6 +229,33 @@ func testGetTaskType(t *testing.T) {
 	}
 }
 
+func testGetTaskPriority(t *testing.T) {
+	tests := []struct {
+		name    string
+		taskID  string
+		want    TaskPriority
+		wantErr bool
+	}{
+		{"ExplicitPriority", "t19", TaskPriorityHIGH, false},
+		{"InvalidPriority", "t20", TaskPriorityNORMAL, true},
+		{"DefaultDeployPriority", "t1", TaskPriorityNORMAL, false},
+		{"DefaultScaleOutPriority", "t3", TaskPriorityHIGH, false},
+		{"TaskDoesntExist", "TaskDoesntExist", TaskPriorityNORMAL, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetTaskPriority(tt.taskID)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetTaskPriority() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("GetTaskPriority() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func testGetTaskTarget(t *testing.T) {
 	type args struct {
 		taskID string
@@ -542,6 +576,7 @@ func TestCheckTaskStepStatusChange(t *testing.T) {
 		wantErr bool
 	}{
 		{"ChangeOK", args{"error", "done"}, true, false},
+		{"TimeoutChangeOK", args{"timeout", "initial"}, true, false},
 		{"NotAllowed", args{"initial", "done"}, false, false},
 		{"NotAllowed", args{"initial", "running"}, false, false},
 		{"Error", args{"fake", "fake"}, false, true},
@@ -768,3 +803,31 @@ func testCheckAndSetTaskErrorMessage(t *testing.T) {
 		})
 	}
 }
+
+func testReserveRollback(t *testing.T) {
+	taskID := url.PathEscape(t.Name())
+	defer consulutil.Delete(path.Join(consulutil.TasksPrefix, taskID), true)
+
+	reserved, err := ReserveRollback(taskID)
+	require.NoError(t, err)
+	require.True(t, reserved)
+	rollbackTaskID, err := GetRollbackTaskID(taskID)
+	require.NoError(t, err)
+	require.Empty(t, rollbackTaskID)
+
+	// Only the first reservation succeeds
+	reserved, err = ReserveRollback(taskID)
+	require.NoError(t, err)
+	require.False(t, reserved)
+
+	require.NoError(t, SetTaskData(taskID, TaskDataRollbackTaskID, "rollbackTask"))
+	reserved, err = ReserveRollback(taskID)
+	require.NoError(t, err)
+	require.False(t, reserved)
+
+	// A released reservation can be made again
+	require.NoError(t, ReleaseRollback(taskID))
+	reserved, err = ReserveRollback(taskID)
+	require.NoError(t, err)
+	require.True(t, reserved)
+}
@@ -114,6 +114,7 @@ func buildStepFromWFStep(deploymentID, wfName, stepName string, wfSteps map[stri
 		TargetRelationship: wfStep.TargetRelationShip,
 		Target:             wfStep.Target,
 		Activities:         make([]Activity, 0, len(wfStep.Activities)),
+		Retry:              wfStep.Retry,
 	}
 
 	targetIsMandatory, err := buildStepActivities(s, wfStep)
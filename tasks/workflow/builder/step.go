@@ -14,6 +14,8 @@
 
 package builder
 
+import "github.com/ystia/yorc/v4/tosca"
+
 // Step represents the workflow step
 type Step struct {
 	Name               string
@@ -29,6 +31,7 @@ type Step struct {
 	Async              bool
 	IsOnFailurePath    bool
 	IsOnCancelPath     bool
+	Retry              *tosca.StepRetryPolicy
 }
 
 type visitStep struct {
@@ -15,7 +15,9 @@
 package workflow
 
 import (
+	"context"
 	"path"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -44,12 +46,17 @@ type Dispatcher struct {
 	cfg              config.Configuration
 	wg               *sync.WaitGroup
 	createWorkerFunc func(*Dispatcher)
+	// pendingCache holds the ordering information of pending executions retrieved on previous polls
+	pendingCache map[string]*cachedExecution
+	// locationTypes caches the type of locations, it is used to compute quotas
+	locationTypes *locationTypesCache
 }
 
 // NewDispatcher create a new Dispatcher with a given number of workers
 func NewDispatcher(cfg config.Configuration, shutdownCh chan struct{}, client *api.Client, wg *sync.WaitGroup) *Dispatcher {
 	pool := make(chan chan *taskExecution, cfg.WorkersNumber)
-	dispatcher := &Dispatcher{WorkerPool: pool, client: client, shutdownCh: shutdownCh, maxWorkers: cfg.WorkersNumber, cfg: cfg, wg: wg, createWorkerFunc: createWorker}
+	dispatcher := &Dispatcher{WorkerPool: pool, client: client, shutdownCh: shutdownCh, maxWorkers: cfg.WorkersNumber, cfg: cfg, wg: wg, createWorkerFunc: createWorker,
+		pendingCache: make(map[string]*cachedExecution), locationTypes: newLocationTypesCache(client, cfg)}
 	dispatcher.emitMetrics(client)
 	return dispatcher
 }
@@ -132,6 +139,142 @@ func (d *Dispatcher) deleteExecutionTree(execID string) {
 	}
 }
 
+// pendingExecution holds the information used to order executions before dispatching them
+type pendingExecution struct {
+	execID       string
+	targetID     string
+	priority     tasks.TaskPriority
+	creationDate time.Time
+	// round is the rank of this execution among executions of the same deployment with the same priority
+	round int
+}
+
+// cachedExecution is the ordering information of an execution kept between two polls of the dispatcher
+//
+// Task information never changes for a given execution so it is only read once.
+type cachedExecution struct {
+	pendingExecution
+	// hasExecCreationDate is true once the execution creation date replaced the task one
+	hasExecCreationDate bool
+}
+
+type pendingTaskInfo struct {
+	targetID     string
+	priority     tasks.TaskPriority
+	creationDate time.Time
+}
+
+// getPendingExecutions returns executions found in the given keys along with their ordering information
+//
+// Executions for which information could not be retrieved are returned with a normal priority, they will
+// be cleaned up when trying to process them.
+func (d *Dispatcher) getPendingExecutions(execKeys []string) []pendingExecution {
+	pending := make([]pendingExecution, 0, len(execKeys))
+	locked := make(map[string]bool)
+	tasksInfo := make(map[string]*pendingTaskInfo)
+	seen := make(map[string]bool, len(execKeys))
+	for _, execKey := range execKeys {
+		execID := path.Base(execKey)
+		// Ignore locks
+		if strings.HasPrefix(execID, executionLockPrefix) {
+			locked[strings.TrimPrefix(execID, executionLockPrefix)] = true
+			continue
+		}
+		seen[execID] = true
+		ce, ok := d.pendingCache[execID]
+		if !ok {
+			ce = &cachedExecution{pendingExecution: pendingExecution{execID: execID, priority: tasks.TaskPriorityNORMAL}}
+			taskID, err := getExecutionKeyValue(execID, "taskID")
+			if err == nil {
+				info, ok := tasksInfo[taskID]
+				if !ok {
+					info = getPendingTaskInfo(taskID)
+					tasksInfo[taskID] = info
+				}
+				ce.targetID = info.targetID
+				ce.priority = info.priority
+				ce.creationDate = info.creationDate
+				// Executions without task are not cached, they are removed when trying to process them
+				d.pendingCache[execID] = ce
+			}
+		}
+		// Execution creation date is set when it is processed for the first time
+		if !ce.hasExecCreationDate {
+			if execCreationDate, err := getExecutionKeyValue(execID, "creationDate"); err == nil {
+				if date, err := time.Parse(time.RFC3339Nano, execCreationDate); err == nil {
+					ce.creationDate = date
+					ce.hasExecCreationDate = true
+				}
+			}
+		}
+		pending = append(pending, ce.pendingExecution)
+	}
+	// Forget executions that are no longer pending
+	for execID := range d.pendingCache {
+		if !seen[execID] {
+			delete(d.pendingCache, execID)
+		}
+	}
+
+	queued := make(map[tasks.TaskPriority]int)
+	for _, pe := range pending {
+		// Executions with a processing lock are already handled by a worker
+		if !locked[pe.execID] {
+			queued[pe.priority]++
+		}
+	}
+	for _, p := range []tasks.TaskPriority{tasks.TaskPriorityLOW, tasks.TaskPriorityNORMAL, tasks.TaskPriorityHIGH} {
+		metrics.SetGaugeWithLabels([]string{"taskExecutions", "queued"}, float32(queued[p]), []metrics.Label{
+			metrics.Label{Name: "Priority", Value: p.String()},
+		})
+	}
+	return pending
+}
+
+func getPendingTaskInfo(taskID string) *pendingTaskInfo {
+	info := &pendingTaskInfo{priority: tasks.TaskPriorityNORMAL}
+	var err error
+	if info.targetID, err = tasks.GetTaskTarget(taskID); err != nil {
+		log.Debugf("Failed to get target of task %q: %v", taskID, err)
+	}
+	if info.priority, err = tasks.GetTaskPriority(taskID); err != nil {
+		log.Debugf("Failed to get priority of task %q: %v", taskID, err)
+	}
+	if info.creationDate, err = tasks.GetTaskCreationDate(taskID); err != nil {
+		log.Debugf("Failed to get creation date of task %q: %v", taskID, err)
+	}
+	return info
+}
+
+// orderPendingExecutions sorts executions by decreasing priority
+//
+// Executions having the same priority are interleaved across deployments, oldest first, so that a deployment
+// with a lot of pending steps does not starve others.
+func orderPendingExecutions(pending []pendingExecution) {
+	sort.SliceStable(pending, func(i, j int) bool {
+		return pending[i].creationDate.Before(pending[j].creationDate)
+	})
+	type roundKey struct {
+		targetID string
+		priority tasks.TaskPriority
+	}
+	rounds := make(map[roundKey]int)
+	for i := range pending {
+		k := roundKey{targetID: pending[i].targetID, priority: pending[i].priority}
+		pending[i].round = rounds[k]
+		rounds[k]++
+	}
+	sort.SliceStable(pending, func(i, j int) bool {
+		if pending[i].priority != pending[j].priority {
+			return pending[i].priority > pending[j].priority
+		}
+		if pending[i].round != pending[j].round {
+			return pending[i].round < pending[j].round
+		}
+		return pending[i].creationDate.Before(pending[j].creationDate)
+	})
+}
+
 func createWorker(d *Dispatcher) {
 	worker := newWorker(d.WorkerPool, d.shutdownCh, d.client, d.cfg)
 	worker.Start()
@@ -144,7 +287,12 @@ func (d *Dispatcher) Run() {
 		d.createWorkerFunc(d)
 	}
 	log.Printf("%d workers started", d.maxWorkers)
+	if len(d.cfg.Tasks.Dispatcher.MaxConcurrentStepsPerInfrastructure) > 0 {
+		go d.locationTypes.watch(d.shutdownCh)
+	}
 	var waitIndex uint64
+	// deferred is set when an execution could not be dispatched due to a quota
+	var deferred bool
 	kv := d.client.KV()
 	nodeName, err := d.client.Agent().NodeName()
 	if err != nil {
@@ -161,6 +309,9 @@ func (d *Dispatcher) Run() {
 			WaitIndex: waitIndex,
 			WaitTime:  d.cfg.Tasks.Dispatcher.LongPollWaitTime,
 		}
+		if deferred && q.WaitTime > waitTimeAfterDeferral {
+			q.WaitTime = waitTimeAfterDeferral
+		}
 		log.Debugf("Long polling Task Executions")
 		execKeys, rMeta, err := kv.Keys(consulutil.ExecutionsTaskPrefix+"/", "/", q)
 		if err != nil {
@@ -171,12 +322,12 @@ func (d *Dispatcher) Run() {
 		}
 		waitIndex = rMeta.LastIndex
 		log.Debugf("Got response new wait index is %d", waitIndex)
-		for _, execKey := range execKeys {
-			execID := path.Base(execKey)
-			// Ignore locks
-			if strings.HasPrefix(execID, executionLockPrefix) {
-				continue
-			}
+		pending := d.getPendingExecutions(execKeys)
+		orderPendingExecutions(pending)
+		deferred = false
+		for _, pe := range pending {
+			execID := pe.execID
+			execKey := path.Join(consulutil.ExecutionsTaskPrefix, execID)
 
 			log.Debugf("Try to acquire processing lock for task execution %s", execKey)
 			opts := &api.LockOptions{
@@ -255,8 +406,32 @@ func (d *Dispatcher) Run() {
 				lock.Destroy()
 				continue
 			}
+			t.priority = pe.priority
+			quotas, err := d.getStepQuotas(context.Background(), t)
+			if err != nil {
+				log.Printf("Failed to get quotas for Task Execution %q: %v", execID, err)
+				log.Debugf("%+v", err)
+				lock.Unlock()
+				lock.Destroy()
+				continue
+			}
+			acquired, err := d.acquireQuotas(t, nodeName, quotas)
+			if err != nil {
+				log.Print(err)
+				log.Debugf("%+v", err)
+			}
+			if !acquired {
+				deferred = true
+				lock.Unlock()
+				lock.Destroy()
+				continue
+			}
 			log.Printf("Processing Task Execution %q linked to deployment %q", execID, t.targetID)
 			t.lock = lock
+			metrics.MeasureSinceWithLabels([]string{"taskExecutions", "dispatchWait"}, pe.creationDate, []metrics.Label{
+				metrics.Label{Name: "Deployment", Value: t.targetID},
+				metrics.Label{Name: "Priority", Value: t.priority.String()},
+			})
 			log.Debugf("New Task Execution created %+v: pushing it to workers channel", t)
 			// try to obtain a worker TaskExecution channel until timeout
 			select {
@@ -264,14 +439,17 @@ func (d *Dispatcher) Run() {
 				taskChannel <- t
 			case <-leaderChan:
 				// lock lost
+				t.releaseQuotas()
 				continue
 			case <-d.shutdownCh:
+				t.releaseQuotas()
 				lock.Unlock()
 				lock.Destroy()
 				log.Printf("Dispatcher received shutdown signal. Exiting...")
 				return
 			case <-time.After(2 * time.Second):
 				log.Debugf("Release the lock for execID:%q to let another yorc instance worker take the execution", execID)
+				t.releaseQuotas()
 				lock.Unlock()
 				lock.Destroy()
 				time.Sleep(100 * time.Millisecond)
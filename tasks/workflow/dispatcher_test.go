@@ -31,6 +31,7 @@ import (
 
 	"github.com/ystia/yorc/v4/config"
 	"github.com/ystia/yorc/v4/helper/consulutil"
+	"github.com/ystia/yorc/v4/tasks"
 )
 
 func testDeleteExecutionTreeSamePrefix(t *testing.T, client *api.Client) {
@@ -52,6 +53,37 @@ func testDeleteExecutionTreeSamePrefix(t *testing.T, client *api.Client) {
 
 }
 
+func testGetPendingExecutionsCache(t *testing.T, client *api.Client) {
+	shutdownCh := make(chan struct{})
+	defer close(shutdownCh)
+
+	cfg := config.Configuration{WorkersNumber: 1}
+	dispatcher := NewDispatcher(cfg, shutdownCh, client, &sync.WaitGroup{})
+
+	consulutil.StoreConsulKeyAsString(path.Join(consulutil.TasksPrefix, "tCache", "targetId"), "dCache")
+	consulutil.StoreConsulKeyAsString(path.Join(consulutil.TasksPrefix, "tCache", "priority"), strconv.Itoa(int(tasks.TaskPriorityHIGH)))
+	createTaskExecutionKVWithKey(t, "testPendingCacheExec", "taskID", "tCache")
+	execKeys := []string{path.Join(consulutil.ExecutionsTaskPrefix, "testPendingCacheExec") + "/"}
+
+	pending := dispatcher.getPendingExecutions(execKeys)
+	require.Len(t, pending, 1)
+	assert.Equal(t, "dCache", pending[0].targetID)
+	assert.Equal(t, tasks.TaskPriorityHIGH, pending[0].priority)
+
+	// Task information is not read again on next polls
+	consulutil.StoreConsulKeyAsString(path.Join(consulutil.TasksPrefix, "tCache", "priority"), strconv.Itoa(int(tasks.TaskPriorityLOW)))
+	pending = dispatcher.getPendingExecutions(execKeys)
+	require.Len(t, pending, 1)
+	assert.Equal(t, tasks.TaskPriorityHIGH, pending[0].priority)
+
+	// Executions that are no longer pending are forgotten
+	dispatcher.getPendingExecutions(nil)
+	assert.Len(t, dispatcher.pendingCache, 0)
+	pending = dispatcher.getPendingExecutions(execKeys)
+	require.Len(t, pending, 1)
+	assert.Equal(t, tasks.TaskPriorityLOW, pending[0].priority)
+}
+
 type workerMock struct{}
 
 func createWorkerFuncMock(d *Dispatcher, tchan chan *taskExecution) {
@@ -124,3 +156,24 @@ func testDispatcherRun(t *testing.T, srv *testutil.TestServer, client *api.Clien
 		require.Fail(t, "timeout awaiting dispatcher to take execution")
 	}
 }
+
+func TestOrderPendingExecutions(t *testing.T) {
+	now := time.Now()
+	pending := []pendingExecution{
+		{execID: "install-1", targetID: "slow", priority: tasks.TaskPriorityNORMAL, creationDate: now.Add(-10 * time.Minute)},
+		{execID: "install-2", targetID: "slow", priority: tasks.TaskPriorityNORMAL, creationDate: now.Add(-9 * time.Minute)},
+		{execID: "install-3", targetID: "slow", priority: tasks.TaskPriorityNORMAL, creationDate: now.Add(-8 * time.Minute)},
+		{execID: "purge-1", targetID: "old", priority: tasks.TaskPriorityLOW, creationDate: now.Add(-20 * time.Minute)},
+		{execID: "deploy-1", targetID: "other", priority: tasks.TaskPriorityNORMAL, creationDate: now.Add(-2 * time.Minute)},
+		{execID: "deploy-2", targetID: "other", priority: tasks.TaskPriorityNORMAL, creationDate: now.Add(-1 * time.Minute)},
+		{execID: "scale-1", targetID: "quick", priority: tasks.TaskPriorityHIGH, creationDate: now},
+	}
+
+	orderPendingExecutions(pending)
+
+	var got []string
+	for _, pe := range pending {
+		got = append(got, pe.execID)
+	}
+	assert.Equal(t, []string{"scale-1", "install-1", "deploy-1", "install-2", "deploy-2", "install-3", "purge-1"}, got)
+}
@@ -0,0 +1,342 @@
+// Copyright 2021 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/ystia/yorc/v4/config"
+	"github.com/ystia/yorc/v4/deployments"
+	"github.com/ystia/yorc/v4/prov"
+	"github.com/ystia/yorc/v4/prov/operations"
+	"github.com/ystia/yorc/v4/registry"
+	"github.com/ystia/yorc/v4/tasks/workflow/builder"
+)
+
+// PlanOptions are the execution context of a planned workflow
+type PlanOptions struct {
+	// NodesInstances restricts the instances of the given nodes on which the workflow is planned.
+	// All instances of nodes not in this map are considered.
+	NodesInstances map[string][]string
+	// Inputs are the workflow inputs values
+	Inputs map[string]string
+}
+
+// Plan describes what a workflow execution would do
+type Plan struct {
+	DeploymentID string         `json:"deployment_id"`
+	WorkflowName string         `json:"workflow_name"`
+	Steps        []*PlannedStep `json:"steps"`
+}
+
+// PlannedStep describes a workflow step execution.
+type PlannedStep struct {
+	Name               string             `json:"name"`
+	Target             string             `json:"target,omitempty"`
+	TargetRelationship string             `json:"target_relationship,omitempty"`
+	OperationHost      string             `json:"operation_host,omitempty"`
+	Instances          []string           `json:"instances,omitempty"`
+	Async              bool               `json:"async,omitempty"`
+	OnFailurePath      bool               `json:"on_failure_path,omitempty"`
+	OnCancelPath       bool               `json:"on_cancel_path,omitempty"`
+	Previous           []string           `json:"previous,omitempty"`
+	Next               []string           `json:"next,omitempty"`
+	OnFailure          []string           `json:"on_failure,omitempty"`
+	OnCancel           []string           `json:"on_cancel,omitempty"`
+	Activities         []*PlannedActivity `json:"activities"`
+}
+
+// PlannedActivity describes a workflow step activity execution
+type PlannedActivity struct {
+	Type                   string                 `json:"type"`
+	Value                  string                 `json:"value"`
+	Executor               *PlannedExecutor       `json:"executor,omitempty"`
+	ImplementationArtifact string                 `json:"implementation_artifact,omitempty"`
+	Inputs                 []PlannedInput         `json:"inputs,omitempty"`
+	Resources              []prov.PlannedResource `json:"resources,omitempty"`
+	// Note gives additional information on how this activity would be handled
+	Note string `json:"note,omitempty"`
+	// Error is set if this activity would fail at execution time
+	Error string `json:"error,omitempty"`
+}
+
+// PlannedExecutor describes the executor selected to run an activity
+type PlannedExecutor struct {
+	// Match is the node type pattern or the implementation artifact that selected this executor
+	Match  string `json:"match"`
+	Origin string `json:"origin"`
+}
+
+// PlannedInput is the resolved value of an operation input for a given instance
+type PlannedInput struct {
+	Name     string `json:"name"`
+	Instance string `json:"instance,omitempty"`
+	Value    string `json:"value"`
+	IsSecret bool   `json:"is_secret,omitempty"`
+}
+
+const redactedSecretValue = "<secret value redacted>"
+
+// BuildPlan computes the execution plan of a workflow without executing it.
+//
+// Steps are returned in an order in which they could be executed. Activities that would fail
+// at execution time are reported in the plan rather than returned as an error.
+// No instance state is modified while building a plan.
+func BuildPlan(ctx context.Context, cfg config.Configuration, deploymentID, workflowName string, opts PlanOptions) (*Plan, error) {
+	steps, err := builder.BuildWorkFlow(ctx, deploymentID, workflowName)
+	if err != nil {
+		return nil, err
+	}
+	if steps == nil {
+		return nil, errors.Errorf("Can't plan workflow %q in deployment %q, workflow definition not found", workflowName, deploymentID)
+	}
+
+	plan := &Plan{DeploymentID: deploymentID, WorkflowName: workflowName}
+	for _, s := range orderSteps(steps) {
+		ps, err := planStep(ctx, cfg, deploymentID, workflowName, s, opts)
+		if err != nil {
+			return nil, err
+		}
+		plan.Steps = append(plan.Steps, ps)
+	}
+	return plan, nil
+}
+
+// orderSteps returns workflow steps in a topological order, steps at the same level are sorted by name
+func orderSteps(steps map[string]*builder.Step) []*builder.Step {
+	inDegree := make(map[string]int, len(steps))
+	for name := range steps {
+		inDegree[name] = 0
+	}
+	successors := func(s *builder.Step) []*builder.Step {
+		res := make([]*builder.Step, 0, len(s.Next)+len(s.OnFailure)+len(s.OnCancel))
+		res = append(res, s.Next...)
+		res = append(res, s.OnFailure...)
+		return append(res, s.OnCancel...)
+	}
+	for _, s := range steps {
+		for _, n := range successors(s) {
+			inDegree[n.Name]++
+		}
+	}
+
+	ordered := make([]*builder.Step, 0, len(steps))
+	var current []string
+	for name, d := range inDegree {
+		if d == 0 {
+			current = append(current, name)
+		}
+	}
+	for len(current) > 0 {
+		sort.Strings(current)
+		var next []string
+		for _, name := range current {
+			s := steps[name]
+			ordered = append(ordered, s)
+			for _, n := range successors(s) {
+				inDegree[n.Name]--
+				if inDegree[n.Name] == 0 {
+					next = append(next, n.Name)
+				}
+			}
+		}
+		current = next
+	}
+
+	if len(ordered) != len(steps) {
+		// Should not happen as cycles are not allowed in workflows but do not lose steps
+		var remaining []string
+		for name, d := range inDegree {
+			if d > 0 {
+				remaining = append(remaining, name)
+			}
+		}
+		sort.Strings(remaining)
+		for _, name := range remaining {
+			ordered = append(ordered, steps[name])
+		}
+	}
+	return ordered
+}
+
+func stepsNames(steps []*builder.Step) []string {
+	var names []string
+	for _, s := range steps {
+		names = append(names, s.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func planStep(ctx context.Context, cfg config.Configuration, deploymentID, workflowName string, s *builder.Step, opts PlanOptions) (*PlannedStep, error) {
+	ps := &PlannedStep{
+		Name:               s.Name,
+		Target:             s.Target,
+		TargetRelationship: s.TargetRelationship,
+		OperationHost:      s.OperationHost,
+		Async:              s.Async,
+		OnFailurePath:      s.IsOnFailurePath,
+		OnCancelPath:       s.IsOnCancelPath,
+		Previous:           stepsNames(s.Previous),
+		Next:               stepsNames(s.Next),
+		OnFailure:          stepsNames(s.OnFailure),
+		OnCancel:           stepsNames(s.OnCancel),
+		Activities:         make([]*PlannedActivity, 0, len(s.Activities)),
+	}
+	if s.Target != "" {
+		instances, err := getPlannedInstances(ctx, deploymentID, s.Target, opts)
+		if err != nil {
+			return nil, err
+		}
+		ps.Instances = instances
+	}
+
+	for _, activity := range s.Activities {
+		pa := &PlannedActivity{Type: activity.Type().String(), Value: activity.Value()}
+		var err error
+		switch activity.Type() {
+		case builder.ActivityTypeDelegate:
+			err = planDelegateActivity(ctx, cfg, deploymentID, s, pa)
+		case builder.ActivityTypeCallOperation:
+			err = planCallOperationActivity(ctx, cfg, deploymentID, workflowName, s, activity, ps.Instances, opts, pa)
+		case builder.ActivityTypeSetState:
+			pa.Note = fmt.Sprintf("Instances state would be set to %q", activity.Value())
+		case builder.ActivityTypeInline:
+			pa.Note = fmt.Sprintf("Workflow %q would be run", activity.Value())
+		}
+		if err != nil {
+			pa.Error = err.Error()
+		}
+		ps.Activities = append(ps.Activities, pa)
+	}
+	return ps, nil
+}
+
+func getPlannedInstances(ctx context.Context, deploymentID, nodeName string, opts PlanOptions) ([]string, error) {
+	if instances, ok := opts.NodesInstances[nodeName]; ok {
+		return instances, nil
+	}
+	return deployments.GetNodeInstancesIds(ctx, deploymentID, nodeName)
+}
+
+func getDelegateExecutorMatch(nodeType string) (*registry.DelegateMatch, error) {
+	reg := registry.GetRegistry()
+	exec, err := reg.GetDelegateExecutor(nodeType)
+	if err != nil {
+		return nil, err
+	}
+	match := &registry.DelegateMatch{Match: nodeType, Executor: exec}
+	for _, m := range reg.ListDelegateExecutors() {
+		if ok, _ := regexp.MatchString(m.Match, nodeType); ok {
+			match.Match = m.Match
+			match.Origin = m.Origin
+			break
+		}
+	}
+	return match, nil
+}
+
+func planDelegateActivity(ctx context.Context, cfg config.Configuration, deploymentID string, s *builder.Step, pa *PlannedActivity) error {
+	nodeType, err := deployments.GetNodeType(ctx, deploymentID, s.Target)
+	if err != nil {
+		return err
+	}
+	match, err := getDelegateExecutorMatch(nodeType)
+	if err != nil {
+		return err
+	}
+	pa.Executor = &PlannedExecutor{Match: match.Match, Origin: match.Origin}
+	if planner, ok := match.Executor.(prov.DelegatePlanner); ok {
+		pa.Resources, err = planner.PlanDelegate(ctx, cfg, deploymentID, s.Target, pa.Value)
+	}
+	return err
+}
+
+func planCallOperationActivity(ctx context.Context, cfg config.Configuration, deploymentID, workflowName string, s *builder.Step,
+	activity builder.Activity, instances []string, opts PlanOptions, pa *PlannedActivity) error {
+
+	lookup := func(inputName string) (string, bool, error) {
+		v, ok := opts.Inputs[inputName]
+		return v, ok, nil
+	}
+	inputParameters, err := getActivityInputParameters(ctx, activity, deploymentID, workflowName, s.Name, lookup)
+	if err != nil {
+		return err
+	}
+	op, err := operations.GetOperation(ctx, deploymentID, s.Target, activity.Value(), s.TargetRelationship, s.OperationHost, inputParameters)
+	if err != nil {
+		if deployments.IsOperationNotImplemented(err) {
+			pa.Note = "Operation not implemented, it would be skipped"
+			return nil
+		}
+		return err
+	}
+	pa.ImplementationArtifact = op.ImplementationArtifact
+
+	match, err := getOperationExecutorMatch(ctx, deploymentID, op.ImplementationArtifact)
+	if err != nil {
+		return err
+	}
+	pa.Executor = &PlannedExecutor{Match: match.Artifact, Origin: match.Origin}
+
+	var targetInstances []string
+	if op.RelOp.IsRelationshipOperation {
+		targetInstances, err = getPlannedInstances(ctx, deploymentID, op.RelOp.TargetNodeName, opts)
+		if err != nil {
+			return err
+		}
+	}
+	envInputs, _, err := operations.ResolveInputsWithInstances(ctx, deploymentID, s.Target, "", op, instances, targetInstances)
+	if err != nil {
+		return err
+	}
+	_, restricted := opts.NodesInstances[s.Target]
+	for _, input := range envInputs {
+		if restricted && !isPlannedInstance(s.Target, input.InstanceName, instances) {
+			continue
+		}
+		pi := PlannedInput{Name: input.Name, Instance: input.InstanceName, Value: input.Value, IsSecret: input.IsSecret}
+		if pi.IsSecret {
+			pi.Value = redactedSecretValue
+		}
+		pa.Inputs = append(pa.Inputs, pi)
+	}
+
+	if planner, ok := match.Executor.(prov.OperationPlanner); ok {
+		pa.Resources, err = planner.PlanOperation(ctx, cfg, deploymentID, s.Target, op)
+	}
+	return err
+}
+
+// isPlannedInstance checks if an input value related to an instance of the given node should be part of the plan.
+//
+// Values related to other nodes instances (relationships targets) are always kept.
+func isPlannedInstance(nodeName, instanceName string, instances []string) bool {
+	if !strings.HasPrefix(instanceName, operations.GetInstanceName(nodeName, "")) {
+		return true
+	}
+	for _, instance := range instances {
+		if operations.GetInstanceName(nodeName, instance) == instanceName {
+			return true
+		}
+	}
+	return false
+}
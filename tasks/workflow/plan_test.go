@@ -0,0 +1,53 @@
+// Copyright 2021 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workflow
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ystia/yorc/v4/tasks/workflow/builder"
+)
+
+func TestOrderSteps(t *testing.T) {
+	create := &builder.Step{Name: "create"}
+	configure := &builder.Step{Name: "configure"}
+	start := &builder.Step{Name: "start"}
+	init := &builder.Step{Name: "init"}
+	cleanup := &builder.Step{Name: "cleanup"}
+	other := &builder.Step{Name: "a_other"}
+
+	init.Next = []*builder.Step{create}
+	create.Next = []*builder.Step{configure}
+	create.OnFailure = []*builder.Step{cleanup}
+	configure.Next = []*builder.Step{start}
+	other.Next = []*builder.Step{start}
+
+	steps := map[string]*builder.Step{
+		"create":    create,
+		"configure": configure,
+		"start":     start,
+		"init":      init,
+		"cleanup":   cleanup,
+		"a_other":   other,
+	}
+
+	var names []string
+	for _, s := range orderSteps(steps) {
+		names = append(names, s.Name)
+	}
+	assert.Equal(t, []string{"a_other", "init", "create", "cleanup", "configure", "start"}, names)
+}
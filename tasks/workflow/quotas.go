@@ -0,0 +1,243 @@
+// Copyright 2021 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workflow
+
+import (
+	"context"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/armon/go-metrics"
+	"github.com/hashicorp/consul/api"
+	"github.com/pkg/errors"
+
+	"github.com/ystia/yorc/v4/config"
+	"github.com/ystia/yorc/v4/deployments"
+	"github.com/ystia/yorc/v4/helper/consulutil"
+	"github.com/ystia/yorc/v4/helper/metricsutil"
+	"github.com/ystia/yorc/v4/locations"
+	"github.com/ystia/yorc/v4/log"
+	"github.com/ystia/yorc/v4/tasks"
+	"github.com/ystia/yorc/v4/tosca"
+)
+
+const quotasPrefix = consulutil.YorcManagementPrefix + "/tasks_quotas"
+
+// waitTimeAfterDeferral is the long poll wait time used by the dispatcher when an execution was deferred
+// because of a quota, as releasing a semaphore slot does not update the executions tree
+const waitTimeAfterDeferral = 5 * time.Second
+
+// A quota limits the number of workflow steps running at the same time on a location or on a type of infrastructure
+//
+// Quotas are shared by all Yorc servers of the cluster using Consul semaphores, so limits should be
+// the same in all servers configurations.
+type quota struct {
+	// kind is either "locations" or "infrastructures"
+	kind  string
+	name  string
+	limit int
+}
+
+// getStepQuotas returns the quotas applying to the workflow step of a task execution
+func (d *Dispatcher) getStepQuotas(ctx context.Context, t *taskExecution) ([]quota, error) {
+	dispatcherCfg := d.cfg.Tasks.Dispatcher
+	if t.step == "" || (len(dispatcherCfg.MaxConcurrentStepsPerLocation) == 0 && len(dispatcherCfg.MaxConcurrentStepsPerInfrastructure) == 0) {
+		return nil, nil
+	}
+	workflowName, err := tasks.GetTaskData(t.taskID, "workflowName")
+	if err != nil {
+		return nil, err
+	}
+	locationName, err := getStepLocationName(ctx, t.targetID, workflowName, t.step)
+	if err != nil || locationName == "" {
+		return nil, err
+	}
+
+	var quotas []quota
+	if limit, ok := dispatcherCfg.MaxConcurrentStepsPerLocation[locationName]; ok {
+		quotas = append(quotas, quota{kind: "locations", name: locationName, limit: limit})
+	}
+	if len(dispatcherCfg.MaxConcurrentStepsPerInfrastructure) > 0 {
+		locationType, err := d.locationTypes.get(locationName)
+		if err != nil {
+			return nil, err
+		}
+		if limit, ok := dispatcherCfg.MaxConcurrentStepsPerInfrastructure[locationType]; ok && locationType != "" {
+			quotas = append(quotas, quota{kind: "infrastructures", name: locationType, limit: limit})
+		}
+	}
+	return quotas, nil
+}
+
+// locationTypesCache caches the type of locations to avoid reading all locations configurations each time
+// an execution is dispatched
+//
+// The cache is reloaded on the first lookup of an unknown location and is invalidated by watch each time
+// locations configurations change.
+type locationTypesCache struct {
+	client *api.Client
+	cfg    config.Configuration
+	lock   sync.Mutex
+	types  map[string]string
+}
+
+func newLocationTypesCache(client *api.Client, cfg config.Configuration) *locationTypesCache {
+	return &locationTypesCache{client: client, cfg: cfg}
+}
+
+// get returns the type of the given location or an empty string if it doesn't exist
+func (c *locationTypesCache) get(locationName string) (string, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if locationType, ok := c.types[locationName]; ok {
+		return locationType, nil
+	}
+	locationsConfig, err := locations.NewManager(c.client, c.cfg).GetLocations()
+	if err != nil {
+		return "", err
+	}
+	c.types = make(map[string]string, len(locationsConfig))
+	for _, locationConfig := range locationsConfig {
+		c.types[locationConfig.Name] = locationConfig.Type
+	}
+	return c.types[locationName], nil
+}
+
+func (c *locationTypesCache) invalidate() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.types = nil
+}
+
+// watch invalidates the cache each time locations configurations change, until shutdownCh is closed
+func (c *locationTypesCache) watch(shutdownCh chan struct{}) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-shutdownCh:
+		case <-ctx.Done():
+		}
+		cancel()
+	}()
+
+	var waitIndex uint64
+	for {
+		q := (&api.QueryOptions{WaitIndex: waitIndex}).WithContext(ctx)
+		_, rMeta, err := c.client.KV().Keys(consulutil.LocationsPrefix+"/", "/", q)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			log.Printf("[WARN] Failed to watch locations configurations: %v", err)
+			c.invalidate()
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(time.Second):
+			}
+			continue
+		}
+		if waitIndex != rMeta.LastIndex {
+			c.invalidate()
+		}
+		waitIndex = rMeta.LastIndex
+	}
+}
+
+// getStepLocationName returns the name of the location on which a workflow step runs
+//
+// The location is retrieved from the metadata of the step target node or of the nodes hosting it.
+// An empty string is returned if the step is not related to any location.
+func getStepLocationName(ctx context.Context, deploymentID, workflowName, stepName string) (string, error) {
+	wf, err := deployments.GetWorkflow(ctx, deploymentID, workflowName)
+	if err != nil {
+		return "", err
+	}
+	if wf == nil {
+		return "", nil
+	}
+	step, ok := wf.Steps[stepName]
+	if !ok || step == nil {
+		return "", nil
+	}
+	nodeName := step.Target
+	for nodeName != "" {
+		found, locationName, err := deployments.GetNodeMetadata(ctx, deploymentID, nodeName, tosca.MetadataLocationNameKey)
+		if err != nil {
+			return "", err
+		}
+		if found && locationName != "" {
+			return locationName, nil
+		}
+		nodeName, err = deployments.GetHostedOnNode(ctx, deploymentID, nodeName)
+		if err != nil {
+			return "", err
+		}
+	}
+	return "", nil
+}
+
+// acquireQuotas tries to get a slot for the given task execution in each quota
+//
+// If a quota is exhausted, slots already acquired are released and false is returned.
+func (d *Dispatcher) acquireQuotas(t *taskExecution, nodeName string, quotas []quota) (bool, error) {
+	for _, q := range quotas {
+		if q.limit <= 0 {
+			log.Debugf("Quota on %s %q is disabled by a limit of %d", q.kind, q.name, q.limit)
+			continue
+		}
+		sem, err := d.client.SemaphoreOpts(&api.SemaphoreOptions{
+			Prefix: path.Join(quotasPrefix, q.kind, q.name),
+			Limit:  q.limit,
+			Value:  []byte(t.id),
+			// the session is renewed while the semaphore is held and expires if this server dies
+			SessionName:       "DispatcherQuota-" + nodeName,
+			SessionTTL:        "10s",
+			SemaphoreTryOnce:  true,
+			SemaphoreWaitTime: d.cfg.Tasks.Dispatcher.LockWaitTime,
+		})
+		if err != nil {
+			t.releaseQuotas()
+			return false, errors.Wrapf(err, "failed to create semaphore for quota on %s %q", q.kind, q.name)
+		}
+		lostCh, err := sem.Acquire(d.shutdownCh)
+		if err != nil {
+			t.releaseQuotas()
+			return false, errors.Wrapf(err, "failed to acquire semaphore for quota on %s %q", q.kind, q.name)
+		}
+		if lostCh == nil {
+			log.Debugf("Quota of %d concurrent steps reached on %s %q, execution %q is deferred", q.limit, q.kind, q.name, t.id)
+			metrics.IncrCounterWithLabels(metricsutil.CleanupMetricKey([]string{"taskExecutions", "quotaDeferred"}), 1, []metrics.Label{
+				metrics.Label{Name: "Kind", Value: q.kind},
+				metrics.Label{Name: "Name", Value: q.name},
+			})
+			t.releaseQuotas()
+			return false, nil
+		}
+		t.quotas = append(t.quotas, sem)
+	}
+	return true, nil
+}
+
+// releaseQuotas frees the slots held by a task execution
+func (t *taskExecution) releaseQuotas() {
+	for _, sem := range t.quotas {
+		sem.Release()
+		sem.Destroy()
+	}
+	t.quotas = nil
+}
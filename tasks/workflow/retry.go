@@ -0,0 +1,318 @@
+// Copyright 2021 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workflow
+
+import (
+	"context"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/ystia/yorc/v4/config"
+	"github.com/ystia/yorc/v4/deployments"
+	"github.com/ystia/yorc/v4/tasks/workflow/builder"
+	"github.com/ystia/yorc/v4/tosca"
+)
+
+// retryMetadataPrefix is the prefix of the TOSCA metadata keys allowing to define a retry policy on
+// node types and node templates.
+//
+// Keys are either yorc.retry.<field> for all operations or yorc.retry.<operation>.<field> for a given operation
+const retryMetadataPrefix = "yorc.retry."
+
+const (
+	retryableErrorAny           = "any"
+	retryableErrorSSHConnection = "ssh_connection"
+	retryableErrorNetwork       = "network"
+	retryableErrorTimeout       = "timeout"
+)
+
+var sshConnectionErrorsPatterns = []string{
+	"ssh: handshake failed",
+	"ssh: unable to authenticate",
+	"failed to open connection",
+	"failed to create session",
+	"unable to create new session",
+	"unreachable",
+}
+
+var networkErrorsPatterns = []string{
+	"connection refused",
+	"connection reset by peer",
+	"no route to host",
+	"network is unreachable",
+	"broken pipe",
+	"no such host",
+}
+
+var timeoutErrorsPatterns = []string{
+	"timeout",
+	"timed out",
+	"deadline exceeded",
+}
+
+// retryPolicy is the resolved retry policy of a workflow step activity
+type retryPolicy struct {
+	maxAttempts       int
+	initialBackoff    time.Duration
+	maxBackoff        time.Duration
+	backoffMultiplier float64
+	retryableErrors   []string
+}
+
+func newRetryPolicyFromConfig(cfg config.RetryPolicy) *retryPolicy {
+	p := &retryPolicy{
+		maxAttempts:       cfg.MaxAttempts,
+		initialBackoff:    cfg.InitialBackoff,
+		maxBackoff:        cfg.MaxBackoff,
+		backoffMultiplier: cfg.BackoffMultiplier,
+		retryableErrors:   cfg.RetryableErrors,
+	}
+	if p.maxAttempts < 1 {
+		p.maxAttempts = 1
+	}
+	return p
+}
+
+// backoff returns the time to wait after the given failed attempt (starting at 1)
+func (p *retryPolicy) backoff(attempt int) time.Duration {
+	multiplier := p.backoffMultiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+	d := float64(p.initialBackoff) * math.Pow(multiplier, float64(attempt-1))
+	if p.maxBackoff > 0 && d > float64(p.maxBackoff) {
+		return p.maxBackoff
+	}
+	if d > math.MaxInt64 {
+		return time.Duration(math.MaxInt64)
+	}
+	return time.Duration(d)
+}
+
+// isRetryable checks if the given error belongs to one of the retryable errors classes of the policy
+func (p *retryPolicy) isRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	for _, class := range p.retryableErrors {
+		if errorMatchesClass(err, class) {
+			return true
+		}
+	}
+	return false
+}
+
+func errorMatchesClass(err error, class string) bool {
+	msg := strings.ToLower(err.Error())
+	switch class {
+	case retryableErrorAny:
+		return true
+	case retryableErrorSSHConnection:
+		return containsAny(msg, sshConnectionErrorsPatterns)
+	case retryableErrorNetwork:
+		var opErr *net.OpError
+		if errors.As(err, &opErr) {
+			return true
+		}
+		return containsAny(msg, networkErrorsPatterns)
+	case retryableErrorTimeout:
+		var netErr net.Error
+		if errors.As(err, &netErr) && netErr.Timeout() {
+			return true
+		}
+		if errors.Is(err, context.DeadlineExceeded) {
+			return true
+		}
+		return containsAny(msg, timeoutErrorsPatterns)
+	}
+	return false
+}
+
+func containsAny(s string, patterns []string) bool {
+	for _, p := range patterns {
+		if strings.Contains(s, p) {
+			return true
+		}
+	}
+	return false
+}
+
+func checkRetryableErrorsClasses(classes []string) error {
+	for _, c := range classes {
+		switch c {
+		case retryableErrorAny, retryableErrorSSHConnection, retryableErrorNetwork, retryableErrorTimeout:
+		default:
+			return errors.Errorf("unsupported retryable error class %q, supported classes are %q, %q, %q and %q",
+				c, retryableErrorAny, retryableErrorSSHConnection, retryableErrorNetwork, retryableErrorTimeout)
+		}
+	}
+	return nil
+}
+
+// setField overrides a field of the policy from its textual representation
+func (p *retryPolicy) setField(field, value string) error {
+	var err error
+	switch field {
+	case "max_attempts":
+		p.maxAttempts, err = strconv.Atoi(value)
+		if err == nil && p.maxAttempts < 1 {
+			err = errors.New("should be greater than 0")
+		}
+	case "initial_backoff":
+		p.initialBackoff, err = time.ParseDuration(value)
+	case "max_backoff":
+		p.maxBackoff, err = time.ParseDuration(value)
+	case "backoff_multiplier":
+		p.backoffMultiplier, err = strconv.ParseFloat(value, 64)
+	case "retryable_errors":
+		classes := make([]string, 0)
+		for _, c := range strings.Split(value, ",") {
+			if c = strings.TrimSpace(c); c != "" {
+				classes = append(classes, c)
+			}
+		}
+		if err = checkRetryableErrorsClasses(classes); err == nil {
+			p.retryableErrors = classes
+		}
+	default:
+		return errors.Errorf("unknown retry policy field %q", field)
+	}
+	return errors.Wrapf(err, "invalid retry policy %s value %q", field, value)
+}
+
+// applyMetadata overrides the policy using the retry metadata defined for all operations
+// and then the ones defined for the given operation
+func (p *retryPolicy) applyMetadata(metadataList []map[string]string, operationName string) error {
+	opPrefix := retryMetadataPrefix + strings.ToLower(operationName) + "."
+	for _, forOperation := range []bool{false, true} {
+		for _, metadata := range metadataList {
+			for k, v := range metadata {
+				if !strings.HasPrefix(k, retryMetadataPrefix) {
+					continue
+				}
+				var field string
+				if forOperation {
+					if !strings.HasPrefix(strings.ToLower(k), opPrefix) {
+						continue
+					}
+					field = k[len(opPrefix):]
+				} else {
+					field = k[len(retryMetadataPrefix):]
+					if strings.Contains(field, ".") {
+						continue
+					}
+				}
+				if err := p.setField(field, v); err != nil {
+					return errors.Wrapf(err, "metadata %q", k)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// applyStepPolicy overrides the policy using a retry policy defined on a workflow step
+func (p *retryPolicy) applyStepPolicy(sp *tosca.StepRetryPolicy) error {
+	if sp == nil {
+		return nil
+	}
+	if sp.MaxAttempts != 0 {
+		if err := p.setField("max_attempts", strconv.Itoa(sp.MaxAttempts)); err != nil {
+			return err
+		}
+	}
+	if sp.InitialBackoff != "" {
+		if err := p.setField("initial_backoff", sp.InitialBackoff); err != nil {
+			return err
+		}
+	}
+	if sp.MaxBackoff != "" {
+		if err := p.setField("max_backoff", sp.MaxBackoff); err != nil {
+			return err
+		}
+	}
+	if sp.BackoffMultiplier != 0 {
+		p.backoffMultiplier = sp.BackoffMultiplier
+	}
+	if sp.RetryableErrors != nil {
+		if err := checkRetryableErrorsClasses(sp.RetryableErrors); err != nil {
+			return err
+		}
+		p.retryableErrors = sp.RetryableErrors
+	}
+	return nil
+}
+
+// getRetryPolicy resolves the retry policy of a step activity
+//
+// Policies are merged in this order: the global configuration, the metadata of the node type hierarchy
+// (from the root type to the node type), the metadata of the node template and finally the step retry definition.
+// Only delegate and call operation activities could be retried.
+func (s *step) getRetryPolicy(ctx context.Context, cfg config.Configuration, deploymentID string, activity builder.Activity) (*retryPolicy, error) {
+	p := newRetryPolicyFromConfig(cfg.Tasks.StepsRetryPolicy)
+	if activity.Type() != builder.ActivityTypeDelegate && activity.Type() != builder.ActivityTypeCallOperation {
+		p.maxAttempts = 1
+		return p, nil
+	}
+
+	typesMetadata, nodeMetadata, err := getNodeMetadataHierarchy(ctx, deploymentID, s.Target)
+	if err != nil {
+		return nil, err
+	}
+	if err = p.applyMetadata(typesMetadata, activity.Value()); err != nil {
+		return nil, errors.Wrapf(err, "invalid retry policy for node %q", s.Target)
+	}
+	if err = p.applyMetadata([]map[string]string{nodeMetadata}, activity.Value()); err != nil {
+		return nil, errors.Wrapf(err, "invalid retry policy for node %q", s.Target)
+	}
+	if err = p.applyStepPolicy(s.Retry); err != nil {
+		return nil, errors.Wrapf(err, "invalid retry policy for step %q", s.Name)
+	}
+	return p, nil
+}
+
+// getNodeMetadataHierarchy returns the metadata of the node type hierarchy starting from the root type
+// and the node template metadata
+func getNodeMetadataHierarchy(ctx context.Context, deploymentID, nodeName string) ([]map[string]string, map[string]string, error) {
+	if nodeName == "" {
+		return nil, nil, nil
+	}
+	nodeType, err := deployments.GetNodeType(ctx, deploymentID, nodeName)
+	if err != nil {
+		return nil, nil, err
+	}
+	typesMetadata := make([]map[string]string, 0)
+	for typeName := nodeType; typeName != ""; {
+		metadata, err := deployments.GetTypeMetadata(ctx, deploymentID, typeName)
+		if err != nil {
+			return nil, nil, err
+		}
+		typesMetadata = append([]map[string]string{metadata}, typesMetadata...)
+		typeName, err = deployments.GetParentType(ctx, deploymentID, typeName)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	nodeMetadata, err := deployments.GetNodeMetadataMap(ctx, deploymentID, nodeName)
+	if err != nil {
+		return nil, nil, err
+	}
+	return typesMetadata, nodeMetadata, nil
+}
@@ -0,0 +1,103 @@
+// Copyright 2021 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workflow
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ystia/yorc/v4/config"
+	"github.com/ystia/yorc/v4/tosca"
+)
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	p := &retryPolicy{initialBackoff: 10 * time.Second, maxBackoff: time.Minute, backoffMultiplier: 2}
+	assert.Equal(t, 10*time.Second, p.backoff(1))
+	assert.Equal(t, 20*time.Second, p.backoff(2))
+	assert.Equal(t, 40*time.Second, p.backoff(3))
+	assert.Equal(t, time.Minute, p.backoff(4))
+	assert.Equal(t, time.Minute, p.backoff(100))
+
+	p.backoffMultiplier = 0
+	assert.Equal(t, 10*time.Second, p.backoff(3))
+}
+
+func TestRetryPolicyIsRetryable(t *testing.T) {
+	tests := []struct {
+		name    string
+		classes []string
+		err     error
+		want    bool
+	}{
+		{"NilError", []string{retryableErrorAny}, nil, false},
+		{"Any", []string{retryableErrorAny}, errors.New("whatever"), true},
+		{"NoClasses", nil, errors.New("connection refused"), false},
+		{"SSHHandshake", []string{retryableErrorSSHConnection}, errors.Wrap(errors.New("ssh: handshake failed: EOF"), "failed to open connection on 10.0.0.1:22"), true},
+		{"AnsibleUnreachable", []string{retryableErrorSSHConnection}, errors.New("fatal: [10.0.0.1]: UNREACHABLE!"), true},
+		{"SSHNotMatchingScriptError", []string{retryableErrorSSHConnection}, errors.New("exit status 1"), false},
+		{"NetOpError", []string{retryableErrorNetwork}, errors.Wrap(&net.OpError{Op: "dial", Err: errors.New("boom")}, "failed"), true},
+		{"NetworkMessage", []string{retryableErrorNetwork}, errors.New("dial tcp 10.0.0.1:443: connect: connection refused"), true},
+		{"DeadlineExceeded", []string{retryableErrorTimeout}, errors.Wrap(context.DeadlineExceeded, "operation failed"), true},
+		{"TimeoutMessage", []string{retryableErrorTimeout}, errors.New("i/o timeout"), true},
+		{"OtherClassesDoNotMatch", []string{retryableErrorTimeout, retryableErrorNetwork}, errors.New("script failed"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &retryPolicy{retryableErrors: tt.classes}
+			assert.Equal(t, tt.want, p.isRetryable(tt.err))
+		})
+	}
+}
+
+func TestRetryPolicyOverrides(t *testing.T) {
+	p := newRetryPolicyFromConfig(config.RetryPolicy{
+		MaxAttempts:       0,
+		InitialBackoff:    10 * time.Second,
+		MaxBackoff:        5 * time.Minute,
+		BackoffMultiplier: 2,
+		RetryableErrors:   []string{retryableErrorSSHConnection},
+	})
+	assert.Equal(t, 1, p.maxAttempts)
+
+	typesMetadata := []map[string]string{
+		{"yorc.retry.max_attempts": "3", "yorc.retry.standard.start.max_attempts": "4", "other": "value"},
+		{"yorc.retry.initial_backoff": "1s", "yorc.retry.standard.create.max_attempts": "10"},
+	}
+	err := p.applyMetadata(typesMetadata, "Standard.Start")
+	require.NoError(t, err)
+	assert.Equal(t, 4, p.maxAttempts)
+	assert.Equal(t, time.Second, p.initialBackoff)
+
+	err = p.applyMetadata([]map[string]string{{"yorc.retry.retryable_errors": "timeout, network"}}, "standard.start")
+	require.NoError(t, err)
+	assert.Equal(t, []string{retryableErrorTimeout, retryableErrorNetwork}, p.retryableErrors)
+
+	err = p.applyStepPolicy(&tosca.StepRetryPolicy{MaxAttempts: 2, MaxBackoff: "30s"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, p.maxAttempts)
+	assert.Equal(t, 30*time.Second, p.maxBackoff)
+	assert.Equal(t, time.Second, p.initialBackoff)
+
+	assert.Error(t, p.applyMetadata([]map[string]string{{"yorc.retry.max_attempts": "zero"}}, "standard.start"))
+	assert.Error(t, p.applyMetadata([]map[string]string{{"yorc.retry.unknown": "1"}}, "standard.start"))
+	assert.Error(t, p.applyMetadata([]map[string]string{{"yorc.retry.retryable_errors": "disk_full"}}, "standard.start"))
+	assert.Error(t, p.applyStepPolicy(&tosca.StepRetryPolicy{InitialBackoff: "10 minutes"}))
+}
@@ -0,0 +1,274 @@
+// Copyright 2021 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workflow
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/ystia/yorc/v4/deployments"
+	"github.com/ystia/yorc/v4/events"
+	"github.com/ystia/yorc/v4/log"
+	"github.com/ystia/yorc/v4/tasks"
+	"github.com/ystia/yorc/v4/tasks/collector"
+	"github.com/ystia/yorc/v4/tosca"
+)
+
+const (
+	// Name prefix of the workflows generated to rollback a task
+	rollbackWorkflowPrefix = "yorc_rollback_"
+	// Name prefix of the steps of a rollback workflow
+	rollbackStepPrefix = "rollback_"
+)
+
+// compensation describes how to revert an operation
+type compensation struct {
+	operation   string
+	beforeState string
+	afterState  string
+}
+
+// compensatingOperations maps lowercased operations to the operations reverting them
+var compensatingOperations = map[string]compensation{
+	"standard.create":      {operation: "standard.delete", beforeState: tosca.NodeStateDeleting.String(), afterState: tosca.NodeStateDeleted.String()},
+	"standard.start":       {operation: "standard.stop", beforeState: tosca.NodeStateStopping.String(), afterState: "stopped"},
+	"configure.add_target": {operation: "configure.remove_target"},
+	"configure.add_source": {operation: "configure.remove_source"},
+}
+
+// compensatingDelegates maps delegate operations to the delegate operations reverting them
+var compensatingDelegates = map[string]compensation{
+	"install": {operation: "uninstall", beforeState: tosca.NodeStateDeleting.String(), afterState: tosca.NodeStateDeleted.String()},
+}
+
+// shortOperationName returns the lowercased name of an operation using the short name of its interface
+func shortOperationName(operation string) string {
+	operation = strings.ToLower(operation)
+	switch {
+	case strings.HasPrefix(operation, tosca.StandardInterfaceName+"."):
+		return tosca.StandardInterfaceShortName + strings.TrimPrefix(operation, tosca.StandardInterfaceName)
+	case strings.HasPrefix(operation, tosca.ConfigureInterfaceName+"."):
+		return tosca.ConfigureInterfaceShortName + strings.TrimPrefix(operation, tosca.ConfigureInterfaceName)
+	}
+	return operation
+}
+
+// compensatingActivities returns the activities reverting the given workflow step or nil if nothing should be reverted
+func compensatingActivities(s *tosca.Step) []tosca.Activity {
+	activities := make([]tosca.Activity, 0)
+	// Revert activities in reverse order
+	for i := len(s.Activities) - 1; i >= 0; i-- {
+		a := s.Activities[i]
+		var c compensation
+		var ok bool
+		var activity tosca.Activity
+		switch {
+		case a.Delegate != nil:
+			c, ok = compensatingDelegates[strings.ToLower(a.Delegate.Workflow)]
+			activity.Delegate = &tosca.WorkflowActivity{Workflow: c.operation}
+		case a.CallOperation != nil:
+			// Relationship operations may be suffixed by a requirement and a target node name
+			opSlice := strings.SplitN(a.CallOperation.Operation, "/", 2)
+			c, ok = compensatingOperations[shortOperationName(opSlice[0])]
+			opSlice[0] = c.operation
+			activity.CallOperation = &tosca.OperationActivity{Operation: strings.Join(opSlice, "/")}
+		}
+		if !ok {
+			continue
+		}
+		if c.beforeState != "" {
+			activities = append(activities, tosca.Activity{SetState: c.beforeState})
+		}
+		activities = append(activities, activity)
+		if c.afterState != "" {
+			activities = append(activities, tosca.Activity{SetState: c.afterState})
+		}
+	}
+	if len(activities) == 0 {
+		return nil
+	}
+	return activities
+}
+
+// buildRollbackWorkflow computes a workflow reverting the steps of a workflow that are done
+//
+// Steps are reverted in the reverse order of the original workflow. A nil workflow is returned if there is nothing to revert.
+func buildRollbackWorkflow(wf *tosca.Workflow, doneSteps map[string]bool) *tosca.Workflow {
+	predecessors := make(map[string][]string)
+	for name, s := range wf.Steps {
+		for _, next := range s.OnSuccess {
+			predecessors[next] = append(predecessors[next], name)
+		}
+	}
+
+	rollbackWf := &tosca.Workflow{Steps: make(map[string]*tosca.Step)}
+	for name, s := range wf.Steps {
+		if !doneSteps[name] {
+			continue
+		}
+		activities := compensatingActivities(s)
+		if activities == nil {
+			continue
+		}
+		rollbackWf.Steps[rollbackStepPrefix+name] = &tosca.Step{
+			Target:             s.Target,
+			TargetRelationShip: s.TargetRelationShip,
+			OperationHost:      s.OperationHost,
+			Activities:         activities,
+		}
+	}
+	if len(rollbackWf.Steps) == 0 {
+		return nil
+	}
+
+	// A reverted step should be executed before the nearest reverted steps that preceded it in the original workflow
+	for name := range wf.Steps {
+		rs, ok := rollbackWf.Steps[rollbackStepPrefix+name]
+		if !ok {
+			continue
+		}
+		visited := make(map[string]bool)
+		toVisit := append([]string(nil), predecessors[name]...)
+		for len(toVisit) > 0 {
+			p := toVisit[0]
+			toVisit = toVisit[1:]
+			if visited[p] {
+				continue
+			}
+			visited[p] = true
+			if _, ok := rollbackWf.Steps[rollbackStepPrefix+p]; ok {
+				rs.OnSuccess = append(rs.OnSuccess, rollbackStepPrefix+p)
+				continue
+			}
+			toVisit = append(toVisit, predecessors[p]...)
+		}
+		sort.Strings(rs.OnSuccess)
+	}
+	return rollbackWf
+}
+
+// isRollbackRequested checks if a rollback was requested on failure of the given task
+func isRollbackRequested(taskID string) (bool, error) {
+	value, err := tasks.GetTaskData(taskID, tasks.TaskDataRollbackOnFailure)
+	if err != nil {
+		if tasks.IsTaskDataNotFoundError(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return strconv.ParseBool(value)
+}
+
+// registerRollbackIfRequested registers a task reverting the completed steps of a failed workflow task
+// if a rollback was requested at the task submission
+func (w *worker) registerRollbackIfRequested(ctx context.Context, deploymentID, taskID, workflowName string, taskStatus tasks.TaskStatus) {
+	if taskStatus != tasks.TaskStatusFAILED {
+		return
+	}
+	err := w.registerRollback(ctx, deploymentID, taskID, workflowName)
+	if err != nil {
+		events.WithContextOptionalFields(ctx).NewLogEntry(events.LogLevelERROR, deploymentID).Registerf("Failed to register rollback of workflow %q: %v", workflowName, err)
+	}
+}
+
+func (w *worker) registerRollback(ctx context.Context, deploymentID, taskID, workflowName string) (err error) {
+	requested, err := isRollbackRequested(taskID)
+	if err != nil || !requested {
+		return err
+	}
+	// The end of a task may be handled by several paths, only the first one registers the rollback
+	reserved, err := tasks.ReserveRollback(taskID)
+	if err != nil || !reserved {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			if releaseErr := tasks.ReleaseRollback(taskID); releaseErr != nil {
+				log.Printf("[ERROR] Failed to release rollback reservation of task %q: %v", taskID, releaseErr)
+			}
+		}
+	}()
+
+	wf, err := deployments.GetWorkflow(ctx, deploymentID, workflowName)
+	if err != nil {
+		return err
+	}
+	if wf == nil {
+		return errors.Errorf("workflow %q not found", workflowName)
+	}
+	steps, err := tasks.GetTaskRelatedSteps(taskID)
+	if err != nil {
+		return err
+	}
+	doneSteps := make(map[string]bool, len(steps))
+	for _, s := range steps {
+		doneSteps[s.Name] = strings.EqualFold(s.Status, tasks.TaskStepStatusDONE.String())
+	}
+
+	rollbackWf := buildRollbackWorkflow(wf, doneSteps)
+	if rollbackWf == nil {
+		events.WithContextOptionalFields(ctx).NewLogEntry(events.LogLevelINFO, deploymentID).Registerf("Nothing to rollback for workflow %q", workflowName)
+		return nil
+	}
+	rollbackWfName := rollbackWorkflowPrefix + taskID
+	err = deployments.StoreWorkflow(ctx, deploymentID, rollbackWfName, rollbackWf)
+	if err != nil {
+		return err
+	}
+
+	data := map[string]string{
+		taskDataWorkflowName:     rollbackWfName,
+		"continueOnError":        "true",
+		tasks.TaskDataRollbackOf: taskID,
+	}
+	// Keep the instances selection of the original task
+	taskData, err := tasks.GetAllTaskData(taskID)
+	if err != nil {
+		return err
+	}
+	for k, v := range taskData {
+		if strings.HasPrefix(k, "nodes/") {
+			data[k] = v
+		}
+	}
+	priority, err := tasks.GetTaskPriority(taskID)
+	if err != nil {
+		return err
+	}
+	rollbackTaskID, err := collector.NewCollector(w.consulClient).RegisterTaskWithPriority(deploymentID, tasks.TaskTypeCustomWorkflow, data, priority)
+	if err != nil {
+		return err
+	}
+	events.WithContextOptionalFields(ctx).NewLogEntry(events.LogLevelINFO, deploymentID).Registerf("Rollback of workflow %q registered with task ID %q", workflowName, rollbackTaskID)
+	return tasks.SetTaskData(taskID, tasks.TaskDataRollbackTaskID, rollbackTaskID)
+}
+
+// cleanupRollbackWorkflow deletes the workflow generated for a rollback task once it ended, whatever its status
+//
+// As a consequence rollback tasks can't be resumed.
+func cleanupRollbackWorkflow(ctx context.Context, deploymentID, workflowName string, taskStatus tasks.TaskStatus) error {
+	if !strings.HasPrefix(workflowName, rollbackWorkflowPrefix) {
+		return nil
+	}
+	switch taskStatus {
+	case tasks.TaskStatusDONE, tasks.TaskStatusFAILED, tasks.TaskStatusCANCELED:
+		return deployments.DeleteWorkflow(ctx, deploymentID, workflowName)
+	}
+	return nil
+}
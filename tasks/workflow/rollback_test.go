@@ -0,0 +1,88 @@
+// Copyright 2021 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workflow
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ystia/yorc/v4/tosca"
+)
+
+func TestCompensatingActivities(t *testing.T) {
+	tests := []struct {
+		name string
+		step *tosca.Step
+		want []tosca.Activity
+	}{
+		{"Delegate", &tosca.Step{Activities: []tosca.Activity{{Delegate: &tosca.WorkflowActivity{Workflow: "install"}}}},
+			[]tosca.Activity{{SetState: "deleting"}, {Delegate: &tosca.WorkflowActivity{Workflow: "uninstall"}}, {SetState: "deleted"}}},
+		{"Create", &tosca.Step{Activities: []tosca.Activity{{SetState: "creating"}, {CallOperation: &tosca.OperationActivity{Operation: "Standard.create"}}, {SetState: "created"}}},
+			[]tosca.Activity{{SetState: "deleting"}, {CallOperation: &tosca.OperationActivity{Operation: "standard.delete"}}, {SetState: "deleted"}}},
+		{"FullyQualifiedStart", &tosca.Step{Activities: []tosca.Activity{{CallOperation: &tosca.OperationActivity{Operation: "tosca.interfaces.node.lifecycle.Standard.start"}}}},
+			[]tosca.Activity{{SetState: "stopping"}, {CallOperation: &tosca.OperationActivity{Operation: "standard.stop"}}, {SetState: "stopped"}}},
+		{"RelationshipOperation", &tosca.Step{Activities: []tosca.Activity{{CallOperation: &tosca.OperationActivity{Operation: "configure.add_target/host/Compute"}}}},
+			[]tosca.Activity{{CallOperation: &tosca.OperationActivity{Operation: "configure.remove_target/host/Compute"}}}},
+		{"ReverseOrder", &tosca.Step{Activities: []tosca.Activity{{CallOperation: &tosca.OperationActivity{Operation: "standard.create"}}, {CallOperation: &tosca.OperationActivity{Operation: "standard.start"}}}},
+			[]tosca.Activity{{SetState: "stopping"}, {CallOperation: &tosca.OperationActivity{Operation: "standard.stop"}}, {SetState: "stopped"},
+				{SetState: "deleting"}, {CallOperation: &tosca.OperationActivity{Operation: "standard.delete"}}, {SetState: "deleted"}}},
+		{"NothingToRevert", &tosca.Step{Activities: []tosca.Activity{{SetState: "configured"}, {CallOperation: &tosca.OperationActivity{Operation: "standard.configure"}}}}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, compensatingActivities(tt.step))
+		})
+	}
+}
+
+func TestBuildRollbackWorkflow(t *testing.T) {
+	wf := &tosca.Workflow{Steps: map[string]*tosca.Step{
+		"Compute_install": {Target: "Compute", OnSuccess: []string{"Soft_creating"},
+			Activities: []tosca.Activity{{Delegate: &tosca.WorkflowActivity{Workflow: "install"}}}},
+		"Soft_creating": {Target: "Soft", OnSuccess: []string{"Soft_create"},
+			Activities: []tosca.Activity{{SetState: "creating"}}},
+		"Soft_create": {Target: "Soft", OnSuccess: []string{"Soft_configure"},
+			Activities: []tosca.Activity{{CallOperation: &tosca.OperationActivity{Operation: "Standard.create"}}}},
+		"Soft_configure": {Target: "Soft", OnSuccess: []string{"Soft_start"},
+			Activities: []tosca.Activity{{CallOperation: &tosca.OperationActivity{Operation: "Standard.configure"}}}},
+		"Soft_start": {Target: "Soft",
+			Activities: []tosca.Activity{{CallOperation: &tosca.OperationActivity{Operation: "Standard.start"}}}},
+	}}
+
+	t.Run("NothingDone", func(t *testing.T) {
+		assert.Nil(t, buildRollbackWorkflow(wf, map[string]bool{"Compute_install": false}))
+	})
+
+	t.Run("PartiallyDone", func(t *testing.T) {
+		rollbackWf := buildRollbackWorkflow(wf, map[string]bool{"Compute_install": true, "Soft_creating": true, "Soft_create": true, "Soft_configure": true, "Soft_start": false})
+		require.NotNil(t, rollbackWf)
+		require.Len(t, rollbackWf.Steps, 2)
+		require.Contains(t, rollbackWf.Steps, "rollback_Compute_install")
+		require.Contains(t, rollbackWf.Steps, "rollback_Soft_create")
+		assert.Equal(t, "Compute", rollbackWf.Steps["rollback_Compute_install"].Target)
+		assert.Len(t, rollbackWf.Steps["rollback_Compute_install"].OnSuccess, 0)
+		// Predecessors without compensation are traversed
+		assert.Equal(t, []string{"rollback_Compute_install"}, rollbackWf.Steps["rollback_Soft_create"].OnSuccess)
+	})
+
+	t.Run("AllDone", func(t *testing.T) {
+		rollbackWf := buildRollbackWorkflow(wf, map[string]bool{"Compute_install": true, "Soft_creating": true, "Soft_create": true, "Soft_configure": true, "Soft_start": true})
+		require.NotNil(t, rollbackWf)
+		require.Len(t, rollbackWf.Steps, 3)
+		assert.Equal(t, []string{"rollback_Soft_create"}, rollbackWf.Steps["rollback_Soft_start"].OnSuccess)
+	})
+}
@@ -208,12 +208,16 @@ func (s *step) run(ctx context.Context, cfg config.Configuration, deploymentID s
 					hook(ctx, cfg, s.t.taskID, deploymentID, s.Target, activity)
 				}
 			}()
-			err := s.runActivity(ctx, cfg, deploymentID, workflowName, bypassErrors, w, activity)
+			err := s.runActivityWithRetries(ctx, cfg, deploymentID, workflowName, bypassErrors, w, activity)
 			if err != nil {
 				setNodeStatus(ctx, s.t.taskID, deploymentID, s.Target, tosca.NodeStateError.String())
 				events.WithContextOptionalFields(ctx).NewLogEntry(events.LogLevelDEBUG, deploymentID).Registerf("TaskStep %q: error details: %+v", s.Name, err)
 				// Set step in error but continue if needed
-				s.setStatus(tasks.TaskStepStatusERROR)
+				stepStatus := stepStatusForError(err)
+				if stepStatus == tasks.TaskStepStatusTIMEOUT {
+					events.WithContextOptionalFields(ctx).NewLogEntry(events.LogLevelERROR, deploymentID).Registerf("TaskStep %q: %v", s.Name, err)
+				}
+				s.setStatus(stepStatus)
 				if !bypassErrors {
 					if len(s.OnFailure) > 0 {
 						// Do not end the workflow as there is a on failure branch
@@ -224,7 +228,7 @@ func (s *step) run(ctx context.Context, cfg config.Configuration, deploymentID s
 					}
 					// only set generic error message here.
 					// Task status is handled in task execution final function
-					tasks.CheckAndSetTaskErrorMessage(s.t.taskID, fmt.Sprintf("Workflow %q step %q failed.", workflowName, s.Name), false)
+					tasks.CheckAndSetTaskErrorMessage(s.t.taskID, fmt.Sprintf("Workflow %q step %q %s.", workflowName, s.Name, stepFailureReason(stepStatus)), false)
 
 					err2 := s.registerOnCancelOrFailureSteps(ctx, workflowName, s.OnFailure)
 					if err2 != nil {
@@ -247,6 +251,40 @@ func (s *step) run(ctx context.Context, cfg config.Configuration, deploymentID s
 	return nil
 }
 
+// runActivityWithRetries runs an activity and retries it on failure according to its retry policy
+func (s *step) runActivityWithRetries(ctx context.Context, cfg config.Configuration, deploymentID, workflowName string, bypassErrors bool, w *worker, activity builder.Activity) error {
+	policy, err := s.getRetryPolicy(ctx, cfg, deploymentID, activity)
+	if err != nil {
+		return err
+	}
+	for attempt := 1; ; attempt++ {
+		if policy.maxAttempts > 1 {
+			err = tasks.SetTaskStepAttempt(s.t.taskID, s.Name, attempt)
+			if err != nil {
+				return err
+			}
+		}
+		err = s.runActivity(ctx, cfg, deploymentID, workflowName, bypassErrors, w, activity)
+		if err == nil || attempt >= policy.maxAttempts || ctx.Err() != nil || !policy.isRetryable(err) {
+			return err
+		}
+		backoff := policy.backoff(attempt)
+		events.WithContextOptionalFields(ctx).NewLogEntry(events.LogLevelWARN, deploymentID).Registerf(
+			"TaskStep %q: attempt %d/%d of activity %q failed: %v, retrying in %s", s.Name, attempt, policy.maxAttempts, activity.Value(), err, backoff)
+		metrics.IncrCounterWithLabels(metricsutil.CleanupMetricKey([]string{"taskExecutions", "stepRetries"}), 1, []metrics.Label{
+			metrics.Label{Name: "Deployment", Value: deploymentID},
+			metrics.Label{Name: "Node", Value: s.Target},
+		})
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
 func (s *step) runActivity(wfCtx context.Context, cfg config.Configuration, deploymentID, workflowName string, bypassErrors bool, w *worker, activity builder.Activity) error {
 	// Get activity related instances
 	instances, err := tasks.GetInstances(wfCtx, s.t.taskID, deploymentID, s.Target)
@@ -266,6 +304,10 @@ func (s *step) runActivity(wfCtx context.Context, cfg config.Configuration, depl
 			return err
 		}
 		delegateOp := activity.Value()
+		timeout, err := s.getActivityTimeout(wfCtx, cfg, deploymentID, activity, nil)
+		if err != nil {
+			return err
+		}
 		wfCtx = events.AddLogOptionalFields(wfCtx, events.LogOptionalFields{events.InterfaceName: "delegate", events.OperationName: delegateOp})
 		for _, instanceName := range instances {
 			eventInfo.OperationName = fmt.Sprintf("delegate.%s", delegateOp)
@@ -281,13 +323,15 @@ func (s *step) runActivity(wfCtx context.Context, cfg config.Configuration, depl
 
 		err = func() error {
 			defer metrics.MeasureSinceWithLabels(metricsutil.CleanupMetricKey([]string{"executor", "delegate", "duration"}), time.Now(), executorDelegateLabels)
-			return provisioner.ExecDelegate(wfCtx, cfg, s.t.taskID, deploymentID, s.Target, delegateOp)
+			opCtx, cancelOp := withActivityTimeout(wfCtx, timeout)
+			defer cancelOp()
+			return checkActivityTimeout(opCtx, timeout, provisioner.ExecDelegate(opCtx, cfg, s.t.taskID, deploymentID, s.Target, delegateOp))
 		}()
 
 		if err != nil {
 			metrics.IncrCounterWithLabels(metricsutil.CleanupMetricKey([]string{"executor", "delegate", "failures"}), 1, executorDelegateLabels)
 			for _, instanceName := range instances {
-				s.publishInstanceRelatedEvents(wfCtx, deploymentID, instanceName, eventInfo, tasks.TaskStepStatusERROR)
+				s.publishInstanceRelatedEvents(wfCtx, deploymentID, instanceName, eventInfo, stepStatusForError(err))
 			}
 			return err
 		}
@@ -320,6 +364,10 @@ func (s *step) runActivity(wfCtx context.Context, cfg config.Configuration, depl
 		if err != nil {
 			return err
 		}
+		timeout, err := s.getActivityTimeout(wfCtx, cfg, deploymentID, activity, &op)
+		if err != nil {
+			return err
+		}
 		wfCtx = operations.SetOperationLogFields(wfCtx, op)
 		for _, instanceName := range instances {
 			// Check for specific info about relationships
@@ -340,9 +388,12 @@ func (s *step) runActivity(wfCtx context.Context, cfg config.Configuration, depl
 		if s.Async {
 			err = func() error {
 				defer metrics.MeasureSinceWithLabels(metricsutil.CleanupMetricKey([]string{"executor", "operation", "duration"}), time.Now(), executorOperationLabels)
-				action, timeInterval, err := exec.ExecAsyncOperation(wfCtx, cfg, s.t.taskID, deploymentID, s.Target, op, s.Name)
+				// Timeout only applies to the submission of asynchronous operations
+				opCtx, cancelOp := withActivityTimeout(wfCtx, timeout)
+				defer cancelOp()
+				action, timeInterval, err := exec.ExecAsyncOperation(opCtx, cfg, s.t.taskID, deploymentID, s.Target, op, s.Name)
 				if err != nil {
-					return err
+					return checkActivityTimeout(opCtx, timeout, err)
 				}
 				action.AsyncOperation.DeploymentID = deploymentID
 				action.AsyncOperation.TaskID = s.t.taskID
@@ -370,13 +421,15 @@ func (s *step) runActivity(wfCtx context.Context, cfg config.Configuration, depl
 		} else {
 			err = func() error {
 				defer metrics.MeasureSinceWithLabels(metricsutil.CleanupMetricKey([]string{"executor", "operation", "duration"}), time.Now(), executorOperationLabels)
-				return exec.ExecOperation(wfCtx, cfg, s.t.taskID, deploymentID, s.Target, op)
+				opCtx, cancelOp := withActivityTimeout(wfCtx, timeout)
+				defer cancelOp()
+				return checkActivityTimeout(opCtx, timeout, exec.ExecOperation(opCtx, cfg, s.t.taskID, deploymentID, s.Target, op))
 			}()
 		}
 		if err != nil {
 			metrics.IncrCounterWithLabels(metricsutil.CleanupMetricKey([]string{"executor", "operation", "failures"}), 1, executorOperationLabels)
 			for _, instanceName := range instances {
-				s.publishInstanceRelatedEvents(wfCtx, deploymentID, instanceName, eventInfo, tasks.TaskStepStatusERROR)
+				s.publishInstanceRelatedEvents(wfCtx, deploymentID, instanceName, eventInfo, stepStatusForError(err))
 			}
 			return err
 		}
@@ -393,8 +446,29 @@ func (s *step) runActivity(wfCtx context.Context, cfg config.Configuration, depl
 	return nil
 }
 
+// workflowInputLookup returns the value of a workflow input provided in the execution context.
+//
+// found is false if no value was provided for this input.
+type workflowInputLookup func(inputName string) (value string, found bool, err error)
+
 func (s *step) getActivityInputParameters(ctx context.Context, activity builder.Activity,
 	deploymentID, workflowName string) (map[string]tosca.ParameterDefinition, error) {
+	return getActivityInputParameters(ctx, activity, deploymentID, workflowName, s.Name, s.lookupTaskInput)
+}
+
+func (s *step) lookupTaskInput(inputName string) (string, bool, error) {
+	inputValue, err := tasks.GetTaskInput(s.t.taskID, inputName)
+	if err != nil {
+		if tasks.IsTaskDataNotFoundError(err) {
+			return "", false, nil
+		}
+		return "", false, err
+	}
+	return inputValue, true, nil
+}
+
+func getActivityInputParameters(ctx context.Context, activity builder.Activity,
+	deploymentID, workflowName, stepName string, lookup workflowInputLookup) (map[string]tosca.ParameterDefinition, error) {
 
 	// Getting activity input parameters first
 	result := make(map[string]tosca.ParameterDefinition)
@@ -422,7 +496,7 @@ func (s *step) getActivityInputParameters(ctx context.Context, activity builder.
 			continue
 		}
 
-		valueAssign, err := s.getWorkflowInputValue(ctx, deploymentID, workflowName, inputName, propDef)
+		valueAssign, err := getWorkflowInputValue(ctx, deploymentID, workflowName, stepName, inputName, propDef, lookup)
 		if err != nil {
 			return result, err
 		}
@@ -445,17 +519,16 @@ func (s *step) getActivityInputParameters(ctx context.Context, activity builder.
 	return result, err
 }
 
-func (s *step) getWorkflowInputValue(ctx context.Context, deploymentID, workflowName, inputName string,
-	propDef tosca.PropertyDefinition) (*tosca.ValueAssignment, error) {
+func getWorkflowInputValue(ctx context.Context, deploymentID, workflowName, stepName, inputName string,
+	propDef tosca.PropertyDefinition, lookup workflowInputLookup) (*tosca.ValueAssignment, error) {
 
 	var valueAssign *tosca.ValueAssignment
-	inputValue, err := tasks.GetTaskInput(s.t.taskID, inputName)
+	inputValue, found, err := lookup(inputName)
 	if err != nil {
-		if !tasks.IsTaskDataNotFoundError(err) {
-			return valueAssign, err
-		}
-
-		// No input value in task, defining an input parameter if this property
+		return valueAssign, err
+	}
+	if !found {
+		// No input value in execution context, defining an input parameter if this property
 		// has a default value or is defined in the topology
 		if propDef.Default == nil {
 			// No default value, and no input in this execution context
@@ -466,8 +539,8 @@ func (s *step) getWorkflowInputValue(ctx context.Context, deploymentID, workflow
 				return valueAssign, err
 			}
 			if propDef.Required != nil && *propDef.Required && valueAssign == nil {
-				return valueAssign, errors.Errorf("Missing required value for input %q in step:%q workflow:%q, deploymentID:%q, taskID:%q",
-					inputName, s.Name, workflowName, deploymentID, s.t.taskID)
+				return valueAssign, errors.Errorf("Missing required value for input %q in step:%q workflow:%q, deploymentID:%q",
+					inputName, stepName, workflowName, deploymentID)
 			}
 		}
 	} else {
@@ -582,7 +655,7 @@ func (s *step) checkIfPreviousOfNextStepAreDone(ctx context.Context, nextStep *s
 		}
 		if stepStatus == tasks.TaskStepStatusDONE {
 			cpt++
-		} else if stepStatus == tasks.TaskStepStatusCANCELED || stepStatus == tasks.TaskStepStatusERROR {
+		} else if stepStatus == tasks.TaskStepStatusCANCELED || stepStatus == tasks.TaskStepStatusERROR || stepStatus == tasks.TaskStepStatusTIMEOUT {
 			return false, errors.Errorf("An error has been detected on other step:%q for workflow:%q, deploymentID:%q, taskID:%q. No more steps will be executed", step.Name, workflowName, s.t.targetID, s.t.taskID)
 		}
 	}
@@ -35,8 +35,10 @@ type taskExecution struct {
 	taskID       string
 	targetID     string
 	taskType     tasks.TaskType
+	priority     tasks.TaskPriority
 	creationDate time.Time
 	lock         *api.Lock
+	quotas       []*api.Semaphore
 	cc           *api.Client
 	step         string
 	// finalFunction is function a function called at the end of the taskExecution if no other taskExecution are running
@@ -0,0 +1,141 @@
+// Copyright 2021 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/ystia/yorc/v4/config"
+	"github.com/ystia/yorc/v4/deployments"
+	"github.com/ystia/yorc/v4/prov"
+	"github.com/ystia/yorc/v4/tasks"
+	"github.com/ystia/yorc/v4/tasks/workflow/builder"
+)
+
+// timeoutMetadataKey is the TOSCA metadata key allowing to define an operation timeout on node types
+// and node templates.
+//
+// The timeout of a given operation could be defined using the yorc.timeout.<operation> key
+const timeoutMetadataKey = "yorc.timeout"
+
+// activityTimeoutError is returned when an activity did not finish in time
+type activityTimeoutError struct {
+	timeout time.Duration
+	cause   error
+}
+
+func (e activityTimeoutError) Error() string {
+	return fmt.Sprintf("operation timed out after %s: %v", e.timeout, e.cause)
+}
+
+func (e activityTimeoutError) Cause() error {
+	return e.cause
+}
+
+func (e activityTimeoutError) Unwrap() error {
+	return e.cause
+}
+
+// isActivityTimeoutError checks if a given error is due to an activity timeout
+func isActivityTimeoutError(err error) bool {
+	var timeoutErr activityTimeoutError
+	return errors.As(err, &timeoutErr)
+}
+
+// stepStatusForError returns the step status corresponding to a failed activity
+func stepStatusForError(err error) tasks.TaskStepStatus {
+	if isActivityTimeoutError(err) {
+		return tasks.TaskStepStatusTIMEOUT
+	}
+	return tasks.TaskStepStatusERROR
+}
+
+// withActivityTimeout returns a context that is cancelled after the given timeout, a zero timeout means no timeout
+func withActivityTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// checkActivityTimeout turns an activity error into an activityTimeoutError if the activity context deadline was exceeded
+func checkActivityTimeout(activityCtx context.Context, timeout time.Duration, err error) error {
+	if err != nil && timeout > 0 && activityCtx.Err() == context.DeadlineExceeded {
+		return activityTimeoutError{timeout: timeout, cause: err}
+	}
+	return err
+}
+
+// getActivityTimeout resolves the timeout of a step activity
+//
+// Timeouts are resolved in this order: the global configuration, the metadata of the node type hierarchy
+// (from the root type to the node type), the metadata of the node template and finally the timeout defined in
+// the operation implementation. For each metadata set the yorc.timeout.<operation> key wins over the yorc.timeout one.
+func (s *step) getActivityTimeout(ctx context.Context, cfg config.Configuration, deploymentID string, activity builder.Activity, op *prov.Operation) (time.Duration, error) {
+	timeout := cfg.Tasks.OperationsTimeout
+
+	typesMetadata, nodeMetadata, err := getNodeMetadataHierarchy(ctx, deploymentID, s.Target)
+	if err != nil {
+		return 0, err
+	}
+	opKey := timeoutMetadataKey + "." + strings.ToLower(activity.Value())
+	for _, metadataList := range [][]map[string]string{typesMetadata, {nodeMetadata}} {
+		for _, key := range []string{timeoutMetadataKey, opKey} {
+			for _, metadata := range metadataList {
+				value, ok := getMetadataValueIgnoreCase(metadata, key)
+				if !ok {
+					continue
+				}
+				timeout, err = time.ParseDuration(value)
+				if err != nil {
+					return 0, errors.Wrapf(err, "invalid metadata %q value %q for node %q", key, value, s.Target)
+				}
+			}
+		}
+	}
+
+	if op != nil {
+		impl, err := deployments.GetOperationImplementation(ctx, deploymentID, op.ImplementedInNodeTemplate, op.ImplementedInType, op.Name)
+		if err != nil {
+			return 0, err
+		}
+		if impl != nil && impl.Timeout > 0 {
+			timeout = time.Duration(impl.Timeout) * time.Second
+		}
+	}
+	return timeout, nil
+}
+
+func getMetadataValueIgnoreCase(metadata map[string]string, key string) (string, bool) {
+	for k, v := range metadata {
+		if strings.ToLower(k) == key && v != "" {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// stepFailureReason returns a human readable reason of a step failure for a given step status
+func stepFailureReason(status tasks.TaskStepStatus) string {
+	if status == tasks.TaskStepStatusTIMEOUT {
+		return "timed out"
+	}
+	return "failed"
+}
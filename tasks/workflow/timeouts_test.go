@@ -0,0 +1,56 @@
+// Copyright 2021 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workflow
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ystia/yorc/v4/tasks"
+)
+
+func TestCheckActivityTimeout(t *testing.T) {
+	assert.NoError(t, checkActivityTimeout(context.Background(), time.Second, nil))
+
+	ctx, cancel := withActivityTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	<-ctx.Done()
+	err := checkActivityTimeout(ctx, time.Millisecond, errors.New("signal: killed"))
+	assert.True(t, isActivityTimeoutError(err))
+	assert.True(t, isActivityTimeoutError(errors.Wrap(err, "wrapped")))
+	assert.Equal(t, tasks.TaskStepStatusTIMEOUT, stepStatusForError(err))
+	assert.Equal(t, "timed out", stepFailureReason(stepStatusForError(err)))
+	assert.True(t, (&retryPolicy{retryableErrors: []string{retryableErrorTimeout}}).isRetryable(err))
+
+	ctx, cancel = withActivityTimeout(context.Background(), 0)
+	cancel()
+	err = checkActivityTimeout(ctx, 0, errors.New("context canceled"))
+	assert.False(t, isActivityTimeoutError(err))
+	assert.Equal(t, tasks.TaskStepStatusERROR, stepStatusForError(err))
+	assert.Equal(t, "failed", stepFailureReason(stepStatusForError(err)))
+}
+
+func TestGetMetadataValueIgnoreCase(t *testing.T) {
+	metadata := map[string]string{"yorc.timeout.Standard.Create": "10m", "yorc.timeout": ""}
+	v, ok := getMetadataValueIgnoreCase(metadata, "yorc.timeout.standard.create")
+	assert.True(t, ok)
+	assert.Equal(t, "10m", v)
+	_, ok = getMetadataValueIgnoreCase(metadata, "yorc.timeout")
+	assert.False(t, ok)
+}
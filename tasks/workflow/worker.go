@@ -107,11 +107,26 @@ func setNodeStatus(ctx context.Context, taskID, deploymentID, nodeName, status s
 }
 
 func getOperationExecutor(ctx context.Context, deploymentID, artifact string) (prov.OperationExecutor, error) {
+	match, err := getOperationExecutorMatch(ctx, deploymentID, artifact)
+	if err != nil {
+		return nil, err
+	}
+	return match.Executor, nil
+}
+
+func getOperationExecutorMatch(ctx context.Context, deploymentID, artifact string) (*registry.OperationExecMatch, error) {
 	reg := registry.GetRegistry()
 
 	exec, originalErr := reg.GetOperationExecutor(artifact)
 	if originalErr == nil {
-		return exec, nil
+		match := &registry.OperationExecMatch{Artifact: artifact, Executor: exec}
+		for _, m := range reg.ListOperationExecutors() {
+			if m.Artifact == artifact {
+				match.Origin = m.Origin
+				break
+			}
+		}
+		return match, nil
 	}
 	// Try to get an executor for artifact parent type but return the original error if we do not found any executors
 	parentArt, err := deployments.GetParentType(ctx, deploymentID, artifact)
@@ -119,9 +134,9 @@ func getOperationExecutor(ctx context.Context, deploymentID, artifact string) (p
 		return nil, err
 	}
 	if parentArt != "" {
-		exec, err := getOperationExecutor(ctx, deploymentID, parentArt)
+		match, err := getOperationExecutorMatch(ctx, deploymentID, parentArt)
 		if err == nil {
-			return exec, nil
+			return match, nil
 		}
 	}
 	return nil, originalErr
@@ -166,6 +181,7 @@ func (w *worker) handleExecution(t *taskExecution) {
 		metrics.Label{Name: "TaskID", Value: t.taskID},
 		metrics.Label{Name: "Deployment", Value: t.targetID},
 		metrics.Label{Name: "Type", Value: t.taskType.String()},
+		metrics.Label{Name: "Priority", Value: t.priority.String()},
 	}
 	metrics.MeasureSinceWithLabels([]string{"taskExecution", "wait"}, t.creationDate, taskExecutionLabels)
 	defer func(t *taskExecution, start time.Time, taskExecutionLabels []metrics.Label) {
@@ -181,6 +197,8 @@ func (w *worker) handleExecution(t *taskExecution) {
 			metrics.IncrCounterWithLabels(metricsutil.CleanupMetricKey([]string{"taskExecution", "total"}), 1, taskExecutionLabels)
 		}
 		// clean-up
+		// quotas are released first so that dispatchers woken up by the execution deletion could use them
+		t.releaseQuotas()
 		t.delete()
 		if err != nil {
 			log.Printf("%+v", err)
@@ -355,11 +373,17 @@ func (w *worker) endAction(ctx context.Context, t *taskExecution, action *prov.A
 		w.consulClient.KV().Delete(path.Join(consulutil.TasksPrefix, action.AsyncOperation.TaskID, ".runningExecutions", action.ID), nil)
 		if e <= 1 {
 			log.Debugf("endAction %q, updating task %q status", action.ID, action.AsyncOperation.TaskID)
-			_, err := updateTaskStatusAccordingToWorkflowStatus(ctx, action.AsyncOperation.DeploymentID, action.AsyncOperation.TaskID, action.AsyncOperation.WorkflowName)
+			taskStatus, err := updateTaskStatusAccordingToWorkflowStatus(ctx, action.AsyncOperation.DeploymentID, action.AsyncOperation.TaskID, action.AsyncOperation.WorkflowName)
 			if err != nil {
 				err = errors.Wrapf(err, "failed to update task %q status according to workflow %s status for deployment %q", action.AsyncOperation.TaskID, action.AsyncOperation.WorkflowName, action.AsyncOperation.DeploymentID)
 				log.Printf("%v", err)
 				log.Debugf("%+v", err)
+				return
+			}
+			w.registerRollbackIfRequested(ctx, action.AsyncOperation.DeploymentID, action.AsyncOperation.TaskID, action.AsyncOperation.WorkflowName, taskStatus)
+			err = cleanupRollbackWorkflow(ctx, action.AsyncOperation.DeploymentID, action.AsyncOperation.WorkflowName, taskStatus)
+			if err != nil {
+				log.Printf("Failed to cleanup rollback workflow %q of task %q: %v", action.AsyncOperation.WorkflowName, action.AsyncOperation.TaskID, err)
 			}
 		}
 
@@ -576,7 +600,12 @@ func (w *worker) makeWorkflowFinalFunction(ctx context.Context, deploymentID, ta
 			return err
 		}
 
-		return deployments.SetDeploymentStatus(ctx, deploymentID, wfStatus)
+		err = deployments.SetDeploymentStatus(ctx, deploymentID, wfStatus)
+		if err != nil {
+			return err
+		}
+		w.registerRollbackIfRequested(ctx, deploymentID, taskID, wfName, taskStatus)
+		return nil
 	}
 }
 
@@ -740,7 +769,8 @@ func (w *worker) runCustomWorkflow(ctx context.Context, t *taskExecution, wfName
 			return err
 		}
 
-		return err
+		w.registerRollbackIfRequested(ctx, t.targetID, t.taskID, wfName, taskStatus)
+		return cleanupRollbackWorkflow(ctx, t.targetID, wfName, taskStatus)
 	}
 
 	return w.runWorkflowStep(ctx, t, wfName, bypassErrors)
@@ -18,9 +18,66 @@ package workflow
 
 import (
 	"context"
-	"github.com/pkg/errors"
+
+	"github.com/ystia/yorc/v4/deployments"
+	"github.com/ystia/yorc/v4/events"
+	iop "github.com/ystia/yorc/v4/internal/operations"
+	"github.com/ystia/yorc/v4/tasks"
+	"github.com/ystia/yorc/v4/tasks/collector"
 )
 
 func (w *worker) runAddRemoveNodes(ctx context.Context, t *taskExecution, wfName string) error {
-	return errors.New("runAddRemoveNodes is only supported only in premium versions")
+	err := deployments.SetDeploymentStatus(ctx, t.targetID, deployments.UPDATE_IN_PROGRESS)
+	if err != nil {
+		return err
+	}
+	if t.taskType == tasks.TaskTypeAddNodes {
+		classicFinalFn := w.makeWorkflowFinalFunction(ctx, t.targetID, t.taskID, wfName, deployments.UPDATED, deployments.UPDATE_FAILURE)
+		t.finalFunction = func() error {
+			err := classicFinalFn()
+			if err != nil {
+				return err
+			}
+			return iop.RemoveDeploymentOverlayBackup(t.targetID, w.cfg.WorkingDirectory)
+		}
+		return w.runWorkflowStep(ctx, t, wfName, false)
+	}
+
+	t.finalFunction = func() error {
+		taskStatus, err := updateTaskStatusAccordingToWorkflowStatus(ctx, t.targetID, t.taskID, wfName)
+		if err != nil {
+			return err
+		}
+		if taskStatus != tasks.TaskStatusDONE {
+			// The updated definition is not applied, put back the previous CSAR to stay consistent with the stored topology
+			err = iop.RestoreDeploymentOverlay(t.targetID, w.cfg.WorkingDirectory)
+			if err != nil {
+				events.WithContextOptionalFields(ctx).NewLogEntry(events.LogLevelERROR, t.targetID).Registerf("%v", err)
+			}
+			return deployments.SetDeploymentStatus(ctx, t.targetID, deployments.UPDATE_FAILURE)
+		}
+		return w.applyDeploymentUpdate(ctx, t)
+	}
+	return w.runWorkflowStep(ctx, t, wfName, false)
+}
+
+// applyDeploymentUpdate stores the updated definition once removed nodes are uninstalled
+// and registers a new task to install added nodes
+func (w *worker) applyDeploymentUpdate(ctx context.Context, t *taskExecution) error {
+	defPath, err := tasks.GetTaskData(t.taskID, tasks.TaskDataUpdateDefinition)
+	if err != nil {
+		return err
+	}
+	priority, err := tasks.GetTaskPriority(t.taskID)
+	if err != nil {
+		return err
+	}
+	taskID, err := iop.ApplyDeploymentUpdate(ctx, collector.NewCollector(w.consulClient), t.targetID, defPath, w.cfg.WorkingDirectory, priority)
+	if err != nil {
+		return err
+	}
+	if taskID != "" {
+		events.WithContextOptionalFields(ctx).NewLogEntry(events.LogLevelINFO, t.targetID).Registerf("Installation of nodes added by the update registered with task ID %q", taskID)
+	}
+	return nil
 }
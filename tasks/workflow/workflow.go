@@ -148,11 +148,9 @@ func updateParentWorkflowStepAndRegisterNextSteps(ctx context.Context, t *taskEx
 		return errors.Errorf("Found no parent task ID in task %s data for parent workflow %s", t.taskID, wfName)
 	}
 
-	stepStatus := tasks.TaskStepStatusDONE
-	if taskStatus == tasks.TaskStatusFAILED {
-		stepStatus = tasks.TaskStepStatusERROR
-	} else if taskStatus == tasks.TaskStatusCANCELED {
-		stepStatus = tasks.TaskStepStatusCANCELED
+	stepStatus, err := parentStepStatus(t.taskID, taskStatus)
+	if err != nil {
+		return err
 	}
 	err = tasks.UpdateTaskStepWithStatus(parentTaskID, parentStepName, stepStatus)
 	if err != nil {
@@ -163,7 +161,7 @@ func updateParentWorkflowStepAndRegisterNextSteps(ctx context.Context, t *taskEx
 		return err
 	}
 
-	if stepStatus == tasks.TaskStepStatusERROR {
+	if stepStatus == tasks.TaskStepStatusERROR || stepStatus == tasks.TaskStepStatusTIMEOUT {
 		// Check the option continue on error
 		continueOnError, err := checkByPassErrors(t, wfName)
 		if err != nil || !continueOnError {
@@ -177,6 +175,29 @@ func updateParentWorkflowStepAndRegisterNextSteps(ctx context.Context, t *taskEx
 
 }
 
+// parentStepStatus returns the status of a parent workflow step according to the status of the inline workflow task.
+//
+// A failed inline workflow having a timed out step sets the parent step in timeout, in error otherwise.
+func parentStepStatus(taskID string, taskStatus tasks.TaskStatus) (tasks.TaskStepStatus, error) {
+	switch taskStatus {
+	case tasks.TaskStatusCANCELED:
+		return tasks.TaskStepStatusCANCELED, nil
+	case tasks.TaskStatusFAILED:
+		steps, err := tasks.GetTaskRelatedSteps(taskID)
+		if err != nil {
+			return tasks.TaskStepStatusERROR, err
+		}
+		for _, step := range steps {
+			status, err := tasks.ParseTaskStepStatus(step.Status)
+			if err == nil && status == tasks.TaskStepStatusTIMEOUT {
+				return tasks.TaskStepStatusTIMEOUT, nil
+			}
+		}
+		return tasks.TaskStepStatusERROR, nil
+	}
+	return tasks.TaskStepStatusDONE, nil
+}
+
 func registerParentStepNextSteps(ctx context.Context, t *taskExecution, parentTaskID, wfName, stepName string) error {
 
 	// Get the deployment ID
@@ -306,6 +306,14 @@ func testInlineWorkflow(t *testing.T, srv1 *testutil.TestServer, cc *api.Client)
 	stepStatus, err = tasks.GetTaskStepStatus(taskID, stepName)
 	require.NoError(t, err, "Failed to get task step %s status for workflow %s after failure", stepName, wfName)
 	require.Equal(t, tasks.TaskStepStatusERROR.String(), stepStatus.String(), "Expected step %s to be on error for workflow %s", stepName, wfName)
+	// Check status update when a step of the child workflow timed out
+	err = tasks.UpdateTaskStepWithStatus(childTaskID, childTaskExec.step, tasks.TaskStepStatusTIMEOUT)
+	require.NoError(t, err)
+	err = updateParentWorkflowStepAndRegisterNextSteps(ctx, childTaskExec, inlineWfName, tasks.TaskStatusFAILED)
+	require.NoError(t, err, "Unexpected failure updating parent step status when child workflow timed out")
+	stepStatus, err = tasks.GetTaskStepStatus(taskID, stepName)
+	require.NoError(t, err, "Failed to get task step %s status for workflow %s after timeout", stepName, wfName)
+	require.Equal(t, tasks.TaskStepStatusTIMEOUT.String(), stepStatus.String(), "Expected step %s to be in timeout for workflow %s", stepName, wfName)
 	// Check status update when child workflow was canceled
 	err = updateParentWorkflowStepAndRegisterNextSteps(ctx, childTaskExec, inlineWfName, tasks.TaskStatusCANCELED)
 	require.NoError(t, err, "Unexpected failure updating parent step status when child workflow failed")
@@ -0,0 +1,434 @@
+// Copyright 2021 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tosca
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+// Constraint operators defined by TOSCA
+const (
+	ConstraintEqual          = "equal"
+	ConstraintGreaterThan    = "greater_than"
+	ConstraintGreaterOrEqual = "greater_or_equal"
+	ConstraintLessThan       = "less_than"
+	ConstraintLessOrEqual    = "less_or_equal"
+	ConstraintInRange        = "in_range"
+	ConstraintValidValues    = "valid_values"
+	ConstraintLength         = "length"
+	ConstraintMinLength      = "min_length"
+	ConstraintMaxLength      = "max_length"
+	ConstraintPattern        = "pattern"
+)
+
+// unbounded may be used as a bound of an in_range constraint
+const unbounded = "UNBOUNDED"
+
+// constraintOperators gives the number of values expected by each operator, -1 means a non-empty list of values
+var constraintOperators = map[string]int{
+	ConstraintEqual:          1,
+	ConstraintGreaterThan:    1,
+	ConstraintGreaterOrEqual: 1,
+	ConstraintLessThan:       1,
+	ConstraintLessOrEqual:    1,
+	ConstraintInRange:        2,
+	ConstraintValidValues:    -1,
+	ConstraintLength:         1,
+	ConstraintMinLength:      1,
+	ConstraintMaxLength:      1,
+	ConstraintPattern:        1,
+}
+
+// A ConstraintClause is the representation of a TOSCA Constraint Clause
+//
+// Values are stored using their string representation and are converted according to the data type
+// of the constrained value when validating it.
+//
+// See https://docs.oasis-open.org/tosca/TOSCA-Simple-Profile-YAML/v1.3/cos01/TOSCA-Simple-Profile-YAML-v1.3-cos01.html#DEFN_ELEMENT_CONSTRAINTS_CLAUSE
+// for more details
+type ConstraintClause struct {
+	Operator string   `json:"operator"`
+	Values   []string `json:"values"`
+}
+
+// UnmarshalYAML unmarshals a yaml into a ConstraintClause
+func (c *ConstraintClause) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var m map[string]interface{}
+	if err := unmarshal(&m); err != nil {
+		return errors.Wrap(err, "a constraint clause should be a map with a single operator")
+	}
+	if len(m) != 1 {
+		return errors.Errorf("a constraint clause should define exactly one operator, got %d", len(m))
+	}
+	for op, v := range m {
+		nbValues, ok := constraintOperators[op]
+		if !ok {
+			return errors.Errorf("unsupported constraint operator %q", op)
+		}
+		c.Operator = op
+		c.Values = nil
+		if l, isList := v.([]interface{}); isList {
+			for _, lv := range l {
+				c.Values = append(c.Values, literalString(lv))
+			}
+		} else if v != nil {
+			c.Values = []string{literalString(v)}
+		}
+		if (nbValues < 0 && len(c.Values) == 0) || (nbValues > 0 && len(c.Values) != nbValues) {
+			return errors.Errorf("invalid values for constraint operator %q: %v", op, v)
+		}
+		if op == ConstraintPattern {
+			if _, err := regexp.Compile(c.Values[0]); err != nil {
+				return errors.Wrapf(err, "invalid pattern constraint %q", c.Values[0])
+			}
+		}
+	}
+	return nil
+}
+
+// String returns a textual representation of the constraint clause
+func (c ConstraintClause) String() string {
+	if len(c.Values) == 1 && constraintOperators[c.Operator] == 1 {
+		return fmt.Sprintf("%s: %s", c.Operator, c.Values[0])
+	}
+	return fmt.Sprintf("%s: [%s]", c.Operator, strings.Join(c.Values, ", "))
+}
+
+// Validate checks that a value satisfies the constraint clause
+//
+// dataType is the primitive TOSCA type used to compare values (string, integer, float, boolean, timestamp, version,
+// range or scalar-unit.*), other types are compared as strings. The value is either a literal, a list or a map
+// (as in a ValueAssignment). Except for ranges which bounds are checked, comparison constraints only apply to
+// literals and are ignored for lists and maps.
+func (c ConstraintClause) Validate(dataType string, value interface{}) error {
+	switch c.Operator {
+	case ConstraintLength, ConstraintMinLength, ConstraintMaxLength:
+		return c.validateLength(value)
+	}
+
+	var literal string
+	switch v := value.(type) {
+	case []interface{}:
+		if dataType == "range" {
+			return c.validateRange(v)
+		}
+		return nil
+	case map[string]interface{}, map[interface{}]interface{}:
+		return nil
+	default:
+		literal = literalString(v)
+	}
+
+	switch c.Operator {
+	case ConstraintPattern:
+		matched, err := regexp.MatchString("^(?:"+c.Values[0]+")$", literal)
+		if err != nil {
+			return errors.Wrapf(err, "invalid pattern constraint %q", c.Values[0])
+		}
+		if !matched {
+			return errors.Errorf("value %q does not match pattern %q", literal, c.Values[0])
+		}
+		return nil
+	case ConstraintValidValues:
+		for _, valid := range c.Values {
+			cmp, err := compareValues(dataType, literal, valid)
+			if err != nil {
+				return err
+			}
+			if cmp == 0 {
+				return nil
+			}
+		}
+		return errors.Errorf("value %q is not one of the valid values [%s]", literal, strings.Join(c.Values, ", "))
+	case ConstraintInRange:
+		if c.Values[0] != unbounded {
+			cmp, err := compareValues(dataType, literal, c.Values[0])
+			if err != nil {
+				return err
+			}
+			if cmp < 0 {
+				return errors.Errorf("value %q is not in range [%s, %s]", literal, c.Values[0], c.Values[1])
+			}
+		}
+		if c.Values[1] != unbounded {
+			cmp, err := compareValues(dataType, literal, c.Values[1])
+			if err != nil {
+				return err
+			}
+			if cmp > 0 {
+				return errors.Errorf("value %q is not in range [%s, %s]", literal, c.Values[0], c.Values[1])
+			}
+		}
+		return nil
+	}
+
+	cmp, err := compareValues(dataType, literal, c.Values[0])
+	if err != nil {
+		return err
+	}
+	var ok bool
+	var expected string
+	switch c.Operator {
+	case ConstraintEqual:
+		ok, expected = cmp == 0, "equal to"
+	case ConstraintGreaterThan:
+		ok, expected = cmp > 0, "greater than"
+	case ConstraintGreaterOrEqual:
+		ok, expected = cmp >= 0, "greater than or equal to"
+	case ConstraintLessThan:
+		ok, expected = cmp < 0, "less than"
+	case ConstraintLessOrEqual:
+		ok, expected = cmp <= 0, "less than or equal to"
+	default:
+		return errors.Errorf("unsupported constraint operator %q", c.Operator)
+	}
+	if !ok {
+		return errors.Errorf("value %q should be %s %q", literal, expected, c.Values[0])
+	}
+	return nil
+}
+
+// literalString returns the string representation of a literal value
+//
+// Floats are not formatted using an exponent so that numbers decoded from JSON, which are always floats,
+// can still be parsed as integers.
+func literalString(v interface{}) string {
+	switch n := v.(type) {
+	case string:
+		return n
+	case float64:
+		return strconv.FormatFloat(n, 'f', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(n), 'f', -1, 32)
+	}
+	return fmt.Sprint(v)
+}
+
+// validateRange checks that both bounds of a range satisfy the constraint clause
+func (c ConstraintClause) validateRange(bounds []interface{}) error {
+	if len(bounds) != 2 {
+		return errors.Errorf("value %v is not a valid range", bounds)
+	}
+	for _, b := range bounds {
+		bound := literalString(b)
+		if bound == unbounded {
+			continue
+		}
+		if err := c.Validate("integer", bound); err != nil {
+			return errors.Wrapf(err, "invalid range %v", bounds)
+		}
+	}
+	return nil
+}
+
+func (c ConstraintClause) validateLength(value interface{}) error {
+	expected, err := strconv.Atoi(c.Values[0])
+	if err != nil {
+		return errors.Errorf("invalid length in constraint %q", c)
+	}
+	var length int
+	switch v := value.(type) {
+	case []interface{}:
+		length = len(v)
+	case map[string]interface{}:
+		length = len(v)
+	case map[interface{}]interface{}:
+		length = len(v)
+	case string:
+		length = utf8.RuneCountInString(v)
+	default:
+		length = utf8.RuneCountInString(literalString(v))
+	}
+	switch {
+	case c.Operator == ConstraintLength && length != expected:
+		return errors.Errorf("length of value %v is %d, expecting %d", value, length, expected)
+	case c.Operator == ConstraintMinLength && length < expected:
+		return errors.Errorf("length of value %v is %d, expecting at least %d", value, length, expected)
+	case c.Operator == ConstraintMaxLength && length > expected:
+		return errors.Errorf("length of value %v is %d, expecting at most %d", value, length, expected)
+	}
+	return nil
+}
+
+// compareValues compares two literals according to the given primitive data type
+//
+// The result will be 0 if a==b, -1 if a < b, and +1 if a > b.
+func compareValues(dataType, a, b string) (int, error) {
+	switch dataType {
+	case "integer":
+		ia, err := strconv.ParseInt(strings.TrimSpace(a), 10, 64)
+		if err != nil {
+			return 0, errors.Errorf("value %q is not a valid integer", a)
+		}
+		ib, err := strconv.ParseInt(strings.TrimSpace(b), 10, 64)
+		if err != nil {
+			return 0, errors.Errorf("constraint value %q is not a valid integer", b)
+		}
+		return compareFloats(float64(ia), float64(ib)), nil
+	case "float":
+		fa, err := strconv.ParseFloat(strings.TrimSpace(a), 64)
+		if err != nil {
+			return 0, errors.Errorf("value %q is not a valid float", a)
+		}
+		fb, err := strconv.ParseFloat(strings.TrimSpace(b), 64)
+		if err != nil {
+			return 0, errors.Errorf("constraint value %q is not a valid float", b)
+		}
+		return compareFloats(fa, fb), nil
+	case "boolean":
+		ba, err := strconv.ParseBool(strings.TrimSpace(a))
+		if err != nil {
+			return 0, errors.Errorf("value %q is not a valid boolean", a)
+		}
+		bb, err := strconv.ParseBool(strings.TrimSpace(b))
+		if err != nil {
+			return 0, errors.Errorf("constraint value %q is not a valid boolean", b)
+		}
+		if ba == bb {
+			return 0, nil
+		}
+		if bb {
+			return -1, nil
+		}
+		return 1, nil
+	case "timestamp":
+		ta, err := parseTimestamp(a)
+		if err != nil {
+			return 0, errors.Errorf("value %q is not a valid timestamp", a)
+		}
+		tb, err := parseTimestamp(b)
+		if err != nil {
+			return 0, errors.Errorf("constraint value %q is not a valid timestamp", b)
+		}
+		return compareFloats(float64(ta.UnixNano()), float64(tb.UnixNano())), nil
+	case "version":
+		return compareVersions(a, b), nil
+	case "scalar-unit.size", "scalar-unit.time", "scalar-unit.frequency", "scalar-unit.bitrate":
+		sb, multiplier, err := parseScalarUnit(dataType, b)
+		if err != nil {
+			return 0, errors.Wrap(err, "invalid constraint value")
+		}
+		// Values without unit are allowed by Yorc (the default unit depending on the infrastructure),
+		// they are considered as expressed in the unit of the constraint
+		if f, err := strconv.ParseFloat(strings.TrimSpace(a), 64); err == nil {
+			return compareFloats(f*multiplier, sb), nil
+		}
+		sa, _, err := parseScalarUnit(dataType, a)
+		if err != nil {
+			return 0, err
+		}
+		return compareFloats(sa, sb), nil
+	}
+	return strings.Compare(a, b), nil
+}
+
+func compareFloats(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func parseTimestamp(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		t, err = time.Parse("2006-01-02", s)
+	}
+	return t, err
+}
+
+// compareVersions compares TOSCA versions (<major>.<minor>[.<fix>[.<qualifier>[-<build>]]])
+//
+// Numeric parts are compared numerically, other parts lexically and a missing part is lower than any other value.
+func compareVersions(a, b string) int {
+	split := func(r rune) bool { return r == '.' || r == '-' }
+	pa := strings.FieldsFunc(strings.TrimSpace(a), split)
+	pb := strings.FieldsFunc(strings.TrimSpace(b), split)
+	for i := 0; i < len(pa) || i < len(pb); i++ {
+		switch {
+		case i >= len(pa):
+			return -1
+		case i >= len(pb):
+			return 1
+		}
+		na, errA := strconv.ParseUint(pa[i], 10, 64)
+		nb, errB := strconv.ParseUint(pb[i], 10, 64)
+		var cmp int
+		if errA == nil && errB == nil {
+			cmp = compareFloats(float64(na), float64(nb))
+		} else {
+			cmp = strings.Compare(pa[i], pb[i])
+		}
+		if cmp != 0 {
+			return cmp
+		}
+	}
+	return 0
+}
+
+var scalarUnitRegexp = regexp.MustCompile(`^\s*([-+]?[0-9]*\.?[0-9]+(?:[eE][-+]?[0-9]+)?)\s*([a-zA-Z]+)\s*$`)
+
+// scalarUnits gives the multiplier to the base unit of each scalar-unit type
+var scalarUnits = map[string]map[string]float64{
+	"scalar-unit.size": {
+		"b": 1, "kb": 1e3, "kib": 1 << 10, "mb": 1e6, "mib": 1 << 20,
+		"gb": 1e9, "gib": 1 << 30, "tb": 1e12, "tib": 1 << 40,
+	},
+	"scalar-unit.time": {
+		"d": 86400, "h": 3600, "m": 60, "s": 1, "ms": 1e-3, "us": 1e-6, "ns": 1e-9,
+	},
+	"scalar-unit.frequency": {
+		"hz": 1, "khz": 1e3, "mhz": 1e6, "ghz": 1e9,
+	},
+	// bitrate units are case sensitive as bps and Bps are different units
+	"scalar-unit.bitrate": {
+		"bps": 1, "Kbps": 1e3, "Kibps": 1 << 10, "Mbps": 1e6, "Mibps": 1 << 20,
+		"Gbps": 1e9, "Gibps": 1 << 30, "Tbps": 1e12, "Tibps": 1 << 40,
+		"Bps": 8, "KBps": 8e3, "KiBps": 8 << 10, "MBps": 8e6, "MiBps": 8 << 20,
+		"GBps": 8e9, "GiBps": 8 << 30, "TBps": 8e12, "TiBps": 8 << 40,
+	},
+}
+
+// parseScalarUnit converts a scalar-unit value into its base unit, it also returns the multiplier of its unit
+func parseScalarUnit(dataType, value string) (float64, float64, error) {
+	m := scalarUnitRegexp.FindStringSubmatch(value)
+	if m == nil {
+		return 0, 0, errors.Errorf("value %q is not a valid %s", value, dataType)
+	}
+	unit := m[2]
+	if dataType != "scalar-unit.bitrate" {
+		unit = strings.ToLower(unit)
+	}
+	multiplier, ok := scalarUnits[dataType][unit]
+	if !ok {
+		return 0, 0, errors.Errorf("unknown unit %q for %s value %q", m[2], dataType, value)
+	}
+	f, err := strconv.ParseFloat(m[1], 64)
+	if err != nil {
+		return 0, 0, errors.Errorf("value %q is not a valid %s", value, dataType)
+	}
+	return f * multiplier, multiplier, nil
+}
@@ -0,0 +1,146 @@
+// Copyright 2021 Bull S.A.S. Atos Technologies - Bull, Rue Jean Jaures, B.P.68, 78340, Les Clayes-sous-Bois, France.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tosca
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	yaml "gopkg.in/yaml.v2"
+)
+
+func TestConstraintClauseUnmarshalYAML(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		data    string
+		want    ConstraintClause
+		wantErr bool
+	}{
+		{"Equal", "equal: 2.1-SNAPSHOT", ConstraintClause{ConstraintEqual, []string{"2.1-SNAPSHOT"}}, false},
+		{"EqualBool", "equal: true", ConstraintClause{ConstraintEqual, []string{"true"}}, false},
+		{"InRange", "in_range: [ 1, 65535 ]", ConstraintClause{ConstraintInRange, []string{"1", "65535"}}, false},
+		{"InRangeUnbounded", "in_range: [ 1, UNBOUNDED ]", ConstraintClause{ConstraintInRange, []string{"1", "UNBOUNDED"}}, false},
+		{"ValidValues", "valid_values: [ udp, tcp ]", ConstraintClause{ConstraintValidValues, []string{"udp", "tcp"}}, false},
+		{"ScalarUnit", "greater_or_equal: 0.1 GHz", ConstraintClause{ConstraintGreaterOrEqual, []string{"0.1 GHz"}}, false},
+		{"Pattern", `pattern: "^[a-z]+$"`, ConstraintClause{ConstraintPattern, []string{"^[a-z]+$"}}, false},
+		{"UnknownOperator", "between: [1, 2]", ConstraintClause{}, true},
+		{"SeveralOperators", "{min_length: 1, max_length: 2}", ConstraintClause{}, true},
+		{"InRangeOneValue", "in_range: [ 1 ]", ConstraintClause{}, true},
+		{"EmptyValidValues", "valid_values: []", ConstraintClause{}, true},
+		{"InvalidPattern", `pattern: "[a-z"`, ConstraintClause{}, true},
+		{"NotAMap", "- equal: 1", ConstraintClause{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var c ConstraintClause
+			err := yaml.Unmarshal([]byte(tt.data), &c)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, c)
+		})
+	}
+}
+
+func TestConstraintClauseJSONRoundTrip(t *testing.T) {
+	t.Parallel()
+	var p PropertyDefinition
+	err := yaml.Unmarshal([]byte(`
+type: integer
+constraints:
+  - in_range: [ 1, 65535 ]
+entry_schema:
+  type: string
+  constraints:
+    - max_length: 8
+`), &p)
+	require.NoError(t, err)
+	b, err := json.Marshal(p)
+	require.NoError(t, err)
+	var res PropertyDefinition
+	require.NoError(t, json.Unmarshal(b, &res))
+	assert.Equal(t, p, res)
+	assert.Equal(t, []ConstraintClause{{ConstraintInRange, []string{"1", "65535"}}}, res.Constraints)
+	assert.Equal(t, []ConstraintClause{{ConstraintMaxLength, []string{"8"}}}, res.EntrySchema.Constraints)
+}
+
+func TestConstraintClauseValidate(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name       string
+		constraint ConstraintClause
+		dataType   string
+		value      interface{}
+		wantErr    bool
+	}{
+		{"EqualString", ConstraintClause{ConstraintEqual, []string{"PUBLIC"}}, "string", "PUBLIC", false},
+		{"NotEqualString", ConstraintClause{ConstraintEqual, []string{"PUBLIC"}}, "string", "PRIVATE", true},
+		{"EqualBoolean", ConstraintClause{ConstraintEqual, []string{"true"}}, "boolean", "True", false},
+		{"NotEqualBoolean", ConstraintClause{ConstraintEqual, []string{"true"}}, "boolean", "false", true},
+		{"EqualVersion", ConstraintClause{ConstraintEqual, []string{"2.1-SNAPSHOT"}}, "version", "2.1-SNAPSHOT", false},
+		{"GreaterThanInteger", ConstraintClause{ConstraintGreaterThan, []string{"9"}}, "integer", "10", false},
+		{"NotGreaterThanInteger", ConstraintClause{ConstraintGreaterThan, []string{"10"}}, "integer", "10", true},
+		{"GreaterOrEqualFloat", ConstraintClause{ConstraintGreaterOrEqual, []string{"0.5"}}, "float", 0.5, false},
+		{"LessThanFloat", ConstraintClause{ConstraintLessThan, []string{"1.5"}}, "float", "1.6", true},
+		{"LessOrEqualVersion", ConstraintClause{ConstraintLessOrEqual, []string{"1.10.0"}}, "version", "1.9.3", false},
+		{"NotLessOrEqualVersion", ConstraintClause{ConstraintLessOrEqual, []string{"1.10.0"}}, "version", "1.10.0.1", true},
+		{"GreaterThanTimestamp", ConstraintClause{ConstraintGreaterThan, []string{"2021-01-01T00:00:00Z"}}, "timestamp", "2021-06-15T10:30:00+02:00", false},
+		{"InvalidInteger", ConstraintClause{ConstraintGreaterThan, []string{"9"}}, "integer", "ten", true},
+		{"InRange", ConstraintClause{ConstraintInRange, []string{"1", "65535"}}, "integer", "8080", false},
+		{"InRangeJSONNumber", ConstraintClause{ConstraintInRange, []string{"1", "65535"}}, "integer", float64(8080), false},
+		{"InRangeLargeJSONNumber", ConstraintClause{ConstraintInRange, []string{"1", "UNBOUNDED"}}, "integer", float64(1000000), false},
+		{"GreaterThanLargeJSONNumber", ConstraintClause{ConstraintGreaterThan, []string{"1000000"}}, "integer", float64(123456789012), false},
+		{"ValidValuesLargeJSONNumber", ConstraintClause{ConstraintValidValues, []string{"1000000", "2000000"}}, "integer", float64(2000000), false},
+		{"MaxLengthLargeJSONNumber", ConstraintClause{ConstraintMaxLength, []string{"7"}}, "integer", float64(1000000), false},
+		{"RangeLargeJSONNumbers", ConstraintClause{ConstraintInRange, []string{"1", "UNBOUNDED"}}, "range", []interface{}{float64(1000000), float64(2000000)}, false},
+		{"OutOfRange", ConstraintClause{ConstraintInRange, []string{"1", "65535"}}, "integer", "70000", true},
+		{"InRangeUnbounded", ConstraintClause{ConstraintInRange, []string{"1", "UNBOUNDED"}}, "integer", "70000", false},
+		{"RangeInRange", ConstraintClause{ConstraintInRange, []string{"1", "65535"}}, "range", []interface{}{8000, "UNBOUNDED"}, false},
+		{"RangeOutOfRange", ConstraintClause{ConstraintInRange, []string{"1", "65535"}}, "range", []interface{}{0, 100}, true},
+		{"ValidValues", ConstraintClause{ConstraintValidValues, []string{"udp", "tcp"}}, "string", "tcp", false},
+		{"InvalidValue", ConstraintClause{ConstraintValidValues, []string{"udp", "tcp"}}, "string", "TCP", true},
+		{"ValidValuesInteger", ConstraintClause{ConstraintValidValues, []string{"4", "6"}}, "integer", 6, false},
+		{"Length", ConstraintClause{ConstraintLength, []string{"3"}}, "string", "abc", false},
+		{"WrongLength", ConstraintClause{ConstraintLength, []string{"3"}}, "string", "abcd", true},
+		{"MinLengthList", ConstraintClause{ConstraintMinLength, []string{"1"}}, "list", []interface{}{}, true},
+		{"MaxLengthMap", ConstraintClause{ConstraintMaxLength, []string{"1"}}, "map", map[string]interface{}{"a": 1}, false},
+		{"MaxLengthUnicode", ConstraintClause{ConstraintMaxLength, []string{"3"}}, "string", "été", false},
+		{"Pattern", ConstraintClause{ConstraintPattern, []string{"[a-z]+"}}, "string", "abc", false},
+		{"PatternMatchesWholeValue", ConstraintClause{ConstraintPattern, []string{"[a-z]+"}}, "string", "abc1", true},
+		{"ComparisonIgnoredOnMaps", ConstraintClause{ConstraintGreaterOrEqual, []string{"0"}}, "yorc.datatypes.Complex", map[string]interface{}{"a": 1}, false},
+		{"ScalarUnitSize", ConstraintClause{ConstraintGreaterOrEqual, []string{"1 GB"}}, "scalar-unit.size", "1024 MiB", false},
+		{"ScalarUnitSizeTooSmall", ConstraintClause{ConstraintGreaterOrEqual, []string{"1 GB"}}, "scalar-unit.size", "512MB", true},
+		{"ScalarUnitSizeWithoutUnit", ConstraintClause{ConstraintGreaterOrEqual, []string{"1 MB"}}, "scalar-unit.size", "2048", false},
+		{"ScalarUnitSizeUnknownUnit", ConstraintClause{ConstraintGreaterOrEqual, []string{"1 GB"}}, "scalar-unit.size", "10 Abc", true},
+		{"ScalarUnitTime", ConstraintClause{ConstraintLessThan, []string{"1 h"}}, "scalar-unit.time", "59 m", false},
+		{"ScalarUnitFrequency", ConstraintClause{ConstraintGreaterOrEqual, []string{"0.1 GHz"}}, "scalar-unit.frequency", "50 MHz", true},
+		{"ScalarUnitBitrate", ConstraintClause{ConstraintGreaterThan, []string{"1 Mbps"}}, "scalar-unit.bitrate", "1 MBps", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.constraint.Validate(tt.dataType, tt.value)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -22,6 +22,8 @@ type Implementation struct {
 	Dependencies  []string           `yaml:"dependencies,omitempty" json:"dependencies,omitempty"`
 	Artifact      ArtifactDefinition `yaml:",inline" json:"artifact,omitempty"`
 	OperationHost string             `yaml:"operation_host,omitempty" json:"operation_host,omitempty"`
+	// Timeout is the operation timeout in seconds
+	Timeout int `yaml:"timeout,omitempty" json:"timeout,omitempty"`
 }
 
 // UnmarshalYAML unmarshals a yaml into an Implementation
@@ -38,12 +40,14 @@ func (i *Implementation) UnmarshalYAML(unmarshal func(interface{}) error) error
 		Dependencies  []string           `yaml:"dependencies,omitempty"`
 		Artifact      ArtifactDefinition `yaml:",inline"`
 		OperationHost string             `yaml:"operation_host,omitempty"`
+		Timeout       int                `yaml:"timeout,omitempty"`
 	}
 	if err = unmarshal(&str); err == nil {
 		i.Primary = str.Primary
 		i.Dependencies = str.Dependencies
 		i.Artifact = str.Artifact
 		i.OperationHost = str.OperationHost
+		i.Timeout = str.Timeout
 		return nil
 	}
 
@@ -18,27 +18,28 @@ package tosca
 //
 // See https://docs.oasis-open.org/tosca/TOSCA-Simple-Profile-YAML/v1.3/cos01/TOSCA-Simple-Profile-YAML-v1.3-cos01.html#DEFN_ELEMENT_PARAMETER_DEF for more details
 type ParameterDefinition struct {
-	Type        string           `yaml:"type,omitempty" json:"type,omitempty"`
-	Description string           `yaml:"description,omitempty" json:"description,omitempty"`
-	Required    *bool            `yaml:"required,omitempty" json:"required,omitempty"`
-	Default     *ValueAssignment `yaml:"default,omitempty" json:"default,omitempty"`
-	Status      string           `yaml:"status,omitempty" json:"status,omitempty"`
-	//Constraints []ConstraintClause `yaml:"constraints,omitempty"`
-	EntrySchema EntrySchema      `yaml:"entry_schema,omitempty" json:"entry_schema,omitempty"`
-	Value       *ValueAssignment `yaml:"value,omitempty" json:"value,omitempty"`
+	Type        string             `yaml:"type,omitempty" json:"type,omitempty"`
+	Description string             `yaml:"description,omitempty" json:"description,omitempty"`
+	Required    *bool              `yaml:"required,omitempty" json:"required,omitempty"`
+	Default     *ValueAssignment   `yaml:"default,omitempty" json:"default,omitempty"`
+	Status      string             `yaml:"status,omitempty" json:"status,omitempty"`
+	Constraints []ConstraintClause `yaml:"constraints,omitempty" json:"constraints,omitempty"`
+	EntrySchema EntrySchema        `yaml:"entry_schema,omitempty" json:"entry_schema,omitempty"`
+	Value       *ValueAssignment   `yaml:"value,omitempty" json:"value,omitempty"`
 }
 
 // UnmarshalYAML unmarshals a yaml into a ParameterDefinition
 func (p *ParameterDefinition) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	var err error
 	var str struct {
-		Type        string           `yaml:"type,omitempty" json:"type,omitempty"`
-		Description string           `yaml:"description,omitempty" json:"description,omitempty"`
-		Required    *bool            `yaml:"required,omitempty" json:"required,omitempty"`
-		Default     *ValueAssignment `yaml:"default,omitempty" json:"default,omitempty"`
-		Status      string           `yaml:"status,omitempty" json:"status,omitempty"`
-		EntrySchema EntrySchema      `yaml:"entry_schema,omitempty" json:"entry_schema,omitempty"`
-		Value       *ValueAssignment `yaml:"value,omitempty" json:"value,omitempty"`
+		Type        string             `yaml:"type,omitempty" json:"type,omitempty"`
+		Description string             `yaml:"description,omitempty" json:"description,omitempty"`
+		Required    *bool              `yaml:"required,omitempty" json:"required,omitempty"`
+		Default     *ValueAssignment   `yaml:"default,omitempty" json:"default,omitempty"`
+		Status      string             `yaml:"status,omitempty" json:"status,omitempty"`
+		Constraints []ConstraintClause `yaml:"constraints,omitempty" json:"constraints,omitempty"`
+		EntrySchema EntrySchema        `yaml:"entry_schema,omitempty" json:"entry_schema,omitempty"`
+		Value       *ValueAssignment   `yaml:"value,omitempty" json:"value,omitempty"`
 	}
 	if err = unmarshal(&str); err == nil {
 		p.Type = str.Type
@@ -46,6 +47,7 @@ func (p *ParameterDefinition) UnmarshalYAML(unmarshal func(interface{}) error) e
 		p.Required = str.Required
 		p.Default = str.Default
 		p.Status = str.Status
+		p.Constraints = str.Constraints
 		p.EntrySchema = str.EntrySchema
 		p.Value = str.Value
 		return nil
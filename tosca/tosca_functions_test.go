@@ -40,6 +40,10 @@ func TestFunctionParsing(t *testing.T) {
 		{"TestGetPropertyFunction", inputs{yml: "get_property: [SELF, ip_address]"}, false},
 		{"TestConcatFunction", inputs{yml: "concat: [get_property: [SELF, ip_address], get_attribute: [SELF, port]]"}, false},
 		{"TestGetInputFunction", inputs{yml: "get_input: ip_address"}, false},
+		{"TestTokenFunction", inputs{yml: "token: [get_attribute: [SELF, endpoint], \":\", 1]"}, false},
+		{"TestConcatTokenFunction", inputs{yml: "concat: [port-, token: [get_input: url, \":\", 1]]"}, false},
+		{"TestGetNodesOfTypeFunction", inputs{yml: "get_nodes_of_type: tosca.nodes.Compute"}, false},
+		{"TestGetArtifactFunction", inputs{yml: "get_artifact: [SELF, scripts, /tmp/scripts, false]"}, false},
 		{"TestConcatFunctionQuoting", inputs{yml: `concat: ["http://", get_property: [SELF, ip_address], get_attribute: [SELF, port], "\"ff\""]`}, false},
 	}
 
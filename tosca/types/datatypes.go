@@ -21,6 +21,10 @@ type Credential struct {
 	Token     string            `mapstructure:"token" json:"token"`
 	Keys      map[string]string `mapstructure:"keys" json:"keys"`
 	User      string            `mapstructure:"user" json:"user"`
+	// Certificates are optional OpenSSH certificates associated to keys having the same name
+	Certificates map[string]string `mapstructure:"certificates" json:"certificates,omitempty"`
+	// VaultSSHSigningPath is an optional Vault path used to sign keys and get fresh OpenSSH certificates
+	VaultSSHSigningPath string `mapstructure:"vault_ssh_signing_path" json:"vault_ssh_signing_path,omitempty"`
 }
 
 // ProvisioningBastion is a representation of yorc.datatypes.ProvisioningBastion.
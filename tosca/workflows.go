@@ -35,7 +35,19 @@ type Step struct {
 	OperationHost      string     `yaml:"operation_host,omitempty" json:"operation_host,omitempty"`
 
 	// Non standard
-	OnCancel []string `yaml:"on_cancel,omitempty" json:"on_cancel,omitempty"`
+	OnCancel []string         `yaml:"on_cancel,omitempty" json:"on_cancel,omitempty"`
+	Retry    *StepRetryPolicy `yaml:"retry,omitempty" json:"retry,omitempty"`
+}
+
+// StepRetryPolicy defines how a workflow step activity is retried on failure
+//
+// This is a non standard extension, durations are expressed using the Go duration format (e.g. 10s, 2m).
+type StepRetryPolicy struct {
+	MaxAttempts       int      `yaml:"max_attempts,omitempty" json:"max_attempts,omitempty"`
+	InitialBackoff    string   `yaml:"initial_backoff,omitempty" json:"initial_backoff,omitempty"`
+	MaxBackoff        string   `yaml:"max_backoff,omitempty" json:"max_backoff,omitempty"`
+	BackoffMultiplier float64  `yaml:"backoff_multiplier,omitempty" json:"backoff_multiplier,omitempty"`
+	RetryableErrors   []string `yaml:"retryable_errors,omitempty" json:"retryable_errors,omitempty"`
 }
 
 // An Activity is the representation of a TOSCA Workflow Step Activity
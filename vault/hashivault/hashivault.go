@@ -157,6 +157,28 @@ func (vc *vaultClient) GetSecret(id string, options ...string) (vault.Secret, er
 	return secret, nil
 }
 
+func (vc *vaultClient) SignSSHPublicKey(signingPath, publicKey string, options ...string) (string, error) {
+	data := map[string]interface{}{"public_key": publicKey}
+	for _, o := range options {
+		optsList := strings.SplitN(o, "=", 2)
+		if len(optsList) == 2 {
+			data[optsList[0]] = optsList[1]
+		}
+	}
+	s, err := vc.vClient.Logical().Write(signingPath, data)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to sign SSH public key using %q", signingPath)
+	}
+	if s == nil || s.Data == nil {
+		return "", errors.Errorf("no signed SSH key returned by %q", signingPath)
+	}
+	signedKey, ok := s.Data["signed_key"].(string)
+	if !ok || signedKey == "" {
+		return "", errors.Errorf("no signed SSH key returned by %q", signingPath)
+	}
+	return signedKey, nil
+}
+
 func (vc *vaultClient) startRenewing() {
 	go func() {
 		renewer, err := vc.vClient.NewRenewer(&api.RenewerInput{
@@ -34,6 +34,18 @@ type Client interface {
 	Shutdown() error
 }
 
+// SSHCertificateSigner is an optional interface that Vault clients may implement to
+// sign SSH public keys and issue short-lived OpenSSH user certificates.
+type SSHCertificateSigner interface {
+	// SignSSHPublicKey signs a public key given in the authorized_keys format using the given signing path
+	// (for instance "ssh-client-signer/sign/my-role" for HashiCorp Vault) and returns the resulting
+	// certificate in the authorized_keys format.
+	//
+	// Options are given in the "key=value" form. It is up to the Vault client implementation to choose
+	// to honor them.
+	SignSSHPublicKey(signingPath, publicKey string, options ...string) (string, error)
+}
+
 // A Secret is a resolved secret instance.
 //
 // Based on the Vault implementation it could be the resolved secret like a string for instance